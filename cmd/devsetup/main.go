@@ -9,14 +9,53 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/rkinnovate/dev-setup/configs"
+	"github.com/rkinnovate/dev-setup/internal/api"
+	"github.com/rkinnovate/dev-setup/internal/audit"
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/checkcache"
 	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/configsync"
+	"github.com/rkinnovate/dev-setup/internal/deferred"
+	"github.com/rkinnovate/dev-setup/internal/doctor"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+	"github.com/rkinnovate/dev-setup/internal/drift"
+	"github.com/rkinnovate/dev-setup/internal/export"
+	"github.com/rkinnovate/dev-setup/internal/fleet"
+	"github.com/rkinnovate/dev-setup/internal/generations"
+	"github.com/rkinnovate/dev-setup/internal/github"
+	"github.com/rkinnovate/dev-setup/internal/hooks"
+	"github.com/rkinnovate/dev-setup/internal/i18n"
 	"github.com/rkinnovate/dev-setup/internal/installer"
+	"github.com/rkinnovate/dev-setup/internal/lint"
+	"github.com/rkinnovate/dev-setup/internal/metrics"
+	"github.com/rkinnovate/dev-setup/internal/pathmgr"
+	"github.com/rkinnovate/dev-setup/internal/plan"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+	"github.com/rkinnovate/dev-setup/internal/reconcile"
+	"github.com/rkinnovate/dev-setup/internal/rosetta"
+	"github.com/rkinnovate/dev-setup/internal/runlog"
+	"github.com/rkinnovate/dev-setup/internal/selfcheck"
+	"github.com/rkinnovate/dev-setup/internal/services"
 	"github.com/rkinnovate/dev-setup/internal/setup"
+	"github.com/rkinnovate/dev-setup/internal/share"
 	"github.com/rkinnovate/dev-setup/internal/status"
+	"github.com/rkinnovate/dev-setup/internal/support"
 	"github.com/rkinnovate/dev-setup/internal/ui"
 	"github.com/rkinnovate/dev-setup/internal/updater"
 	"github.com/rkinnovate/dev-setup/internal/verify"
@@ -26,11 +65,15 @@ import (
 func init() {
 	// Set the embedded filesystem in the config package
 	config.SetEmbeddedFS(configs.ConfigFS)
+	config.SetEmbeddedCommit(gitCommit)
 }
 
 // version is set during build via -ldflags
 var version = "2.0.0"
 
+// gitCommit is the commit the binary (and its embedded configs) was built from, set via -ldflags
+var gitCommit = "unknown"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "devsetup",
@@ -51,6 +94,13 @@ Commands:
   status   Show current environment status
   update   Update devsetup binary`,
 	Version: version,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		capture.SetVerbose(verbose)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		notifyUpdate(cmd)
+	},
 }
 
 // installCmd represents the install command
@@ -64,14 +114,83 @@ Features:
 - Parallel: Tools in same parallel_group install concurrently
 - Dependencies: Respects depends_on relationships
 - State tracking: Saves installation state to ~/.local/share/devsetup/state.json
+- Remote scripts: Downloaded and checksum-verified before running (never piped)
+
+Use --inspect-scripts to review each remote installer script before it runs.
+
+Command output goes to ~/.local/share/devsetup/logs/install-<tool>.log; a
+failed tool shows the lines that explain it and the log path. --verbose also
+streams every command's output to the terminal.
+
+Debugging partial setups:
+  --stages 1,2            Run only these install stages (--dry-run lists them)
+  --skip-tasks a,b        Leave out these tools
+  --only-group homebrew   Run only tools in this parallel_group
+
+Onboarding events: use --from-peers to first copy Homebrew downloads from a
+machine on the LAN running 'devsetup share serve'.
+
+On Apple Silicon, tools marked rosetta: true need Rosetta 2; install offers to
+add it before the first stage (--yes installs it without asking).
+
+When Homebrew can't be installed (blocked downloads, a broken release),
+--without-brew installs the tools that declare a direct: install (git from
+the Command Line Tools, node from its tarball, the editor from its DMG) and
+defers the rest. The next 'devsetup install' replaces the direct installs
+with Homebrew's and installs what was deferred.
+
+With a post_stage section in tools.yaml (from: 3, defer_until: "18:00"), an
+install started before that time runs the earlier stages and schedules the
+rest: a LaunchAgent runs 'install --deferred' at defer_until and removes
+itself once they are installed. --now installs every stage right away.
+
+When an install fails or is interrupted, the next install resumes: tools it
+already finished are skipped (for a day, and while their tools.yaml entry is
+unchanged) until a full install succeeds. --force reruns every tool.
+
+Exit codes:
+  0  every tool is installed (or was already)
+  1  a required tool failed and later stages did not run
+  2  the install finished, but optional tools failed
+
+When more than one stage runs, install ends with a table of stages
+(duration, tools ok/failed/skipped/cancelled).
+
+Only one install runs at a time. If another is running (another terminal,
+the local API), install stops with a hint; --wait follows the other install's
+progress until it ends and then runs. 'devsetup wait' only follows.
 
 After installation completes, run 'devsetup setup' to configure tools.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		inspectScripts, _ := cmd.Flags().GetBool("inspect-scripts")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		stages, _ := cmd.Flags().GetString("stages")
+		skipTasks, _ := cmd.Flags().GetString("skip-tasks")
+		onlyGroup, _ := cmd.Flags().GetString("only-group")
+		fromPeers, _ := cmd.Flags().GetBool("from-peers")
+		withoutBrew, _ := cmd.Flags().GetBool("without-brew")
+		force, _ := cmd.Flags().GetBool("force")
+		deferredRun, _ := cmd.Flags().GetBool("deferred")
+		runNow, _ := cmd.Flags().GetBool("now")
 
 		// Initialize UI
 		progressUI := ui.NewProgressUI()
 		progressUI.PrintBanner()
+		setPromptTimeout(cmd, progressUI)
+
+		if !dryRun {
+			waitForInstall(cmd, progressUI)
+		}
+
+		applyTeamDefaults(progressUI)
+
+		if fromPeers && !dryRun {
+			// Best effort: anything not copied from a peer is downloaded by brew as usual
+			if err := pullFromPeers(progressUI, ""); err != nil {
+				progressUI.Warning("⚠️  Peer download sharing skipped: %v", err)
+			}
+		}
 
 		// Load configurations
 		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
@@ -80,6 +199,24 @@ After installation completes, run 'devsetup setup' to configure tools.`,
 			os.Exit(1)
 		}
 
+		// Setup config is only used for --skip-tasks names and history; a broken setup.yaml must not fail install
+		setupConfig, _ := config.LoadSetupConfig("configs/setup.yaml")
+		reportOverrides(progressUI, toolsConfig.Overrides)
+		warnConfigDrift(progressUI)
+
+		filter, err := config.NewRunFilter(stages, skipTasks, onlyGroup)
+		if err == nil {
+			err = filter.Validate(toolsConfig, setupConfig)
+		}
+		if err == nil && deferredRun && stages != "" {
+			err = fmt.Errorf("--deferred runs the post_stage stages; it can't be combined with --stages")
+		}
+		if err != nil {
+			progressUI.Error("❌ Invalid filter: %v", err)
+			os.Exit(1)
+		}
+		filter, deferUntil := postStageFilter(progressUI, toolsConfig, filter, deferredRun, runNow)
+
 		// Load state
 		state, err := config.LoadState()
 		if err != nil {
@@ -87,17 +224,64 @@ After installation completes, run 'devsetup setup' to configure tools.`,
 			os.Exit(1)
 		}
 
-		// Create installer
-		toolInstaller := installer.NewToolInstaller(toolsConfig, state, progressUI, dryRun, version)
+		// Load policies (denylist is enforced during install)
+		policyConfig, err := config.LoadPolicyConfig("configs/policies.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load policy config: %v", err)
+			os.Exit(1)
+		}
+
+		// Create installer (install metrics come from its task results, so the recorder doesn't wrap the UI)
+		_, rec := startMetrics(cmd, progressUI, "install", dryRun)
+		var stageUI ui.UI = progressUI
+		var run *runlog.Recorder
+		if !dryRun {
+			stageUI, run = beginRun(progressUI, "install", stageUI)
+		}
+		toolInstaller := installer.NewToolInstaller(toolsConfig, state, stageUI, dryRun, version)
+		toolInstaller.SetPolicies(policyConfig)
+		toolInstaller.SetInspectScripts(inspectScripts)
+		toolInstaller.SetAssumeYes(assumeYes)
+		toolInstaller.SetFilter(filter)
+		toolInstaller.SetCheckCache(openCheckCache(cmd, toolsConfig))
+		toolInstaller.SetHooks(hooks.NewDispatcher(loadHooksConfig(progressUI), progressUI))
+		toolInstaller.SetWithoutHomebrew(withoutBrew)
+		toolInstaller.SetForce(force)
 
 		// Install all tools
-		if err := toolInstaller.InstallAll(); err != nil {
+		tasks, err := toolInstaller.InstallAll()
+		run.End(tasks, err)
+		finishMetrics(cmd, progressUI, rec, tasks, err)
+		installer.PrintSummary(progressUI, toolInstaller.Results(), tasks)
+		progressUI.Summary()
+		prompt.Recap(progressUI)
+		if err != nil {
 			progressUI.Error("❌ Installation failed: %v", err)
 			progressUI.Info("Run 'devsetup doctor' to diagnose issues")
-			os.Exit(1)
+			if !withoutBrew {
+				suggestWithoutBrew(progressUI, toolsConfig, toolInstaller.Failures())
+			}
+			printOwners(progressUI, toolInstaller.Failures())
+			progressUI.Info("Need help? 'devsetup support bundle' collects logs for a ticket")
+			os.Exit(installer.ExitFailed)
+		}
+
+		if !dryRun {
+			recordGeneration(progressUI, "install", toolsConfig, setupConfig, state)
+			finishPostStage(progressUI, toolsConfig, deferUntil, deferredRun || filter.Empty())
+		} else if !deferUntil.IsZero() {
+			progressUI.Info("🌙 Would schedule stages %s for %s", postStageAfter(toolsConfig), deferUntil.Format("15:04"))
+		}
+		if withoutBrew {
+			progressUI.Info("Once Homebrew installs, run 'devsetup install' to finish the deferred tools")
 		}
 
 		progressUI.Info("Next step: Run 'devsetup setup' to configure tools")
+		if code := installer.ExitCode(toolInstaller.Results(), nil); code != installer.ExitOK {
+			progressUI.Warning("⚠️  Optional tools failed; exiting with code %d", code)
+			printOwners(progressUI, toolInstaller.Failures())
+			os.Exit(code)
+		}
 	},
 }
 
@@ -119,12 +303,19 @@ This command may prompt you for:
 - Git configuration (name, email)
 - Shell preferences
 
+Use --skip-tasks a,b to leave out tasks while debugging; tasks depending on
+them run as if they were already configured.
+
 Run 'devsetup setup --help' for options.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		skipTasks, _ := cmd.Flags().GetString("skip-tasks")
 
 		// Initialize UI
 		progressUI := ui.NewProgressUI()
+		setPromptTimeout(cmd, progressUI)
+
+		teamDefaults := applyTeamDefaults(progressUI)
 
 		// Load configurations
 		setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
@@ -133,6 +324,13 @@ Run 'devsetup setup --help' for options.`,
 			os.Exit(1)
 		}
 
+		// Tools config resolves tool:<name> dependencies of setup tasks
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+
 		// Load state
 		state, err := config.LoadState()
 		if err != nil {
@@ -140,15 +338,46 @@ Run 'devsetup setup --help' for options.`,
 			os.Exit(1)
 		}
 
+		reportOverrides(progressUI, setupConfig.Overrides)
+		warnConfigDrift(progressUI)
+
+		filter, _ := config.NewRunFilter("", skipTasks, "")
+		if err := filter.Validate(toolsConfig, setupConfig); err != nil {
+			progressUI.Error("❌ Invalid filter: %v", err)
+			os.Exit(1)
+		}
+
 		// Create setup executor
-		setupExecutor := setup.NewSetupExecutor(setupConfig, state, progressUI, dryRun)
+		stageUI, rec := startMetrics(cmd, progressUI, "setup", dryRun)
+		setupExecutor := setup.NewSetupExecutor(filter.FilterSetup(setupConfig), toolsConfig, state, stageUI, dryRun)
+		setupExecutor.SetHooks(hooks.NewDispatcher(loadHooksConfig(progressUI), progressUI))
+		setupExecutor.SetAnswers(config.MergeAnswers(teamDefaults.Answers))
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		setupExecutor.SetAssumeYes(assumeYes)
+
+		// Policies back builtin tasks (homebrew_policy); tasks needing them fail if unavailable
+		if policyConfig, err := config.LoadPolicyConfig("configs/policies.yaml"); err == nil {
+			setupExecutor.SetPolicies(policyConfig)
+		} else {
+			progressUI.Warning("⚠️  Failed to load policies: %v", err)
+		}
 
 		// Execute all setup tasks
-		if err := setupExecutor.SetupAll(); err != nil {
+		err = setupExecutor.SetupAll()
+		finishMetrics(cmd, progressUI, rec, nil, err)
+		progressUI.Summary()
+		prompt.Recap(progressUI)
+		if err != nil {
 			progressUI.Error("❌ Setup failed: %v", err)
+			printOwners(progressUI, setupExecutor.Failures())
+			progressUI.Info("Need help? 'devsetup support bundle' collects logs for a ticket")
 			os.Exit(1)
 		}
 
+		if !dryRun {
+			recordGeneration(progressUI, "setup", toolsConfig, setupConfig, state)
+		}
+
 		progressUI.Info("Next step: Run 'devsetup verify' to check everything works")
 	},
 }
@@ -167,13 +396,54 @@ Checks:
 
 This command provides accurate verification without false positives.
 
+Modes:
+  --quick  State and file existence checks only (no shell commands)
+  --deep   Adds version, checksum, submodule commit, and config drift checks
+
+Checks run in parallel; each check is time-boxed.
+
+Use --policy to also check organization compliance policies (policies.yaml):
+FileVault, firewall, screen lock timeout, minimum macOS version.
+
+Output formats (--format):
+  text   Colored terminal output (default)
+  table  Aligned table with per-check durations and remediation hints
+  json   Machine-readable JSON
+  junit  JUnit XML for CI dashboards
+
+Every run is added to the drift history ('devsetup drift history').
+
 Exit codes:
   0 - All checks passed
   1 - One or more checks failed`,
 	Run: func(cmd *cobra.Command, args []string) {
+		quick, _ := cmd.Flags().GetBool("quick")
+		deep, _ := cmd.Flags().GetBool("deep")
+		formatFlag, _ := cmd.Flags().GetString("format")
+		checkPolicy, _ := cmd.Flags().GetBool("policy")
+		reportURL, _ := cmd.Flags().GetString("report-url")
+
 		// Initialize UI
 		progressUI := ui.NewProgressUI()
 
+		if quick && deep {
+			progressUI.Error("❌ --quick and --deep cannot be combined")
+			os.Exit(1)
+		}
+
+		format, err := verify.ParseFormat(formatFlag)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		mode := verify.ModeStandard
+		if quick {
+			mode = verify.ModeQuick
+		} else if deep {
+			mode = verify.ModeDeep
+		}
+
 		// Load configurations
 		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
 		if err != nil {
@@ -195,15 +465,72 @@ Exit codes:
 		}
 
 		// Create verifier
-		verifier := verify.NewVerifier(toolsConfig, setupConfig, state, progressUI)
+		verifier := verify.NewVerifier(toolsConfig, setupConfig, state, progressUI, mode)
+		verifier.SetCheckCache(openCheckCache(cmd, toolsConfig))
+
+		if checkPolicy {
+			policyConfig, err := config.LoadPolicyConfig("configs/policies.yaml")
+			if err != nil {
+				progressUI.Error("❌ Failed to load policy config: %v", err)
+				os.Exit(1)
+			}
+			verifier.SetPolicies(policyConfig)
+		}
+		exceptions := loadExceptions(progressUI)
+		verifier.SetExceptions(exceptions)
+
+		// Hook warnings must not mix with machine-readable output
+		var hooksUI ui.UI = progressUI
+		if format != verify.FormatText {
+			hooksUI = nil
+		}
+		verifier.SetHooks(hooks.NewDispatcher(loadHooksConfig(progressUI), hooksUI))
+
+		// Machine-readable formats bypass the UI entirely
+		if format != verify.FormatText {
+			result := verifier.Run()
+			verifier.FireHooks(result)
+			if err := recordDriftHistory(toolsConfig, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			if err := verify.WriteReport(os.Stdout, result, format); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if reportURL != "" {
+				if err := pushVerifyReport(reportURL, toolsConfig, setupConfig, state, exceptions, result); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+			if len(result.Errors) > 0 {
+				os.Exit(1)
+			}
+			return
+		}
 
 		// Verify all
 		result, err := verifier.VerifyAll()
+		if histErr := recordDriftHistory(toolsConfig, result); histErr != nil {
+			progressUI.Warning("⚠️  %v", histErr)
+		}
+		if reportURL != "" {
+			if pushErr := pushVerifyReport(reportURL, toolsConfig, setupConfig, state, exceptions, result); pushErr != nil {
+				progressUI.Warning("⚠️  %v", pushErr)
+			} else {
+				progressUI.Info("📤 Reported results to %s", reportURL)
+			}
+		}
 		if err != nil {
 			progressUI.Info("")
 			progressUI.Info("Summary:")
 			progressUI.Info("  Tools: %d OK, %d failed", result.ToolsOK, result.ToolsFailed)
 			progressUI.Info("  Setup: %d OK, %d failed", result.SetupOK, result.SetupFailed)
+			if mode == verify.ModeDeep {
+				progressUI.Info("  Drift: %d OK, %d failed", result.DeepOK, result.DeepFailed)
+			}
+			if checkPolicy {
+				progressUI.Info("  Policy: %d OK, %d failed", result.PolicyOK, result.PolicyFailed)
+			}
 			os.Exit(1)
 		}
 
@@ -224,11 +551,41 @@ Shows:
 - Overall completion percentage
 - Next steps to complete setup
 
-This command reads from state.json and provides accurate status reporting.`,
+This command reads from state.json and provides accurate status reporting.
+
+Menu bar companion (xbar / SwiftBar):
+  devsetup status --menubar                   Print status in plugin format
+  devsetup status --menubar-plugin <dir>      Install the plugin into <dir>
+    xbar:     ~/Library/Application Support/xbar/plugins
+    SwiftBar: your SwiftBar plugin folder`,
 	Run: func(cmd *cobra.Command, args []string) {
+		menubar, _ := cmd.Flags().GetBool("menubar")
+		pluginDir, _ := cmd.Flags().GetString("menubar-plugin")
+
 		// Initialize UI
 		progressUI := ui.NewProgressUI()
 
+		if pluginDir != "" {
+			executable, err := os.Executable()
+			if err != nil {
+				progressUI.Error("❌ Failed to locate devsetup binary: %v", err)
+				os.Exit(1)
+			}
+			workDir, err := os.Getwd()
+			if err != nil {
+				progressUI.Error("❌ Failed to get working directory: %v", err)
+				os.Exit(1)
+			}
+			path, err := status.InstallMenubarPlugin(pluginDir, executable, workDir)
+			if err != nil {
+				progressUI.Error("❌ %v", err)
+				os.Exit(1)
+			}
+			progressUI.Success("✅ Menu bar plugin installed: %s", path)
+			progressUI.Info("   Refresh xbar/SwiftBar to show it")
+			return
+		}
+
 		// Load configurations
 		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
 		if err != nil {
@@ -251,6 +608,16 @@ This command reads from state.json and provides accurate status reporting.`,
 
 		// Create reporter
 		reporter := status.NewReporter(toolsConfig, setupConfig, state, progressUI)
+		reporter.SetCheckCache(openCheckCache(cmd, toolsConfig))
+		reporter.SetExceptions(loadExceptions(progressUI))
+
+		if menubar {
+			executable, _ := os.Executable()
+			if err := reporter.WriteMenubar(os.Stdout, executable); err != nil {
+				os.Exit(1)
+			}
+			return
+		}
 
 		// Show status
 		reporter.ShowStatus()
@@ -265,18 +632,32 @@ var updateCmd = &cobra.Command{
 
 This command:
 - Checks GitHub releases for newer versions
+- Shows the new version's release notes
 - Downloads the appropriate binary for your architecture
 - Verifies SHA256 checksum
+- Updates shell completions and the man page when the release ships them
 - Atomically replaces current binary
 - Creates backup of old version
 
-Use --check to only check for updates without installing.`,
+Use --check to only check for updates without installing.
+
+Other commands mention a new release once it's out (checked at most once a
+day). Use --skip <version> to stop hearing about one release, or --snooze
+<7d|2w|12h> to pause the notice (--snooze 0 resumes it). Set
+DEVSETUP_NO_UPDATE_CHECK=1 to turn the notice off.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		checkOnly, _ := cmd.Flags().GetBool("check")
+		skip, _ := cmd.Flags().GetString("skip")
+		snooze, _ := cmd.Flags().GetString("snooze")
 
 		// Initialize UI
 		progressUI := ui.NewProgressUI()
 
+		if skip != "" || snooze != "" {
+			setUpdatePrefs(progressUI, skip, snooze)
+			return
+		}
+
 		// Create updater
 		upd := updater.NewUpdater(version)
 
@@ -290,7 +671,12 @@ Use --check to only check for updates without installing.`,
 
 			if release != nil {
 				progressUI.Info("🎉 New version available: %s", release.TagName)
-				progressUI.Info("Run 'devsetup update' to install")
+				printReleaseNotes(progressUI, release, releaseNotesLines)
+				if inst, err := updater.DetectInstallation(); err == nil && inst.Method == updater.MethodHomebrew {
+					progressUI.Info("Run '%s' to install", inst.UpgradeCommand())
+				} else {
+					progressUI.Info("Run 'devsetup update' to install")
+				}
 			} else {
 				progressUI.Success("✅ You're running the latest version (%s)", version)
 			}
@@ -309,58 +695,2902 @@ Use --check to only check for updates without installing.`,
 			return
 		}
 
+		progressUI.Info("🎉 New version available: %s", release.TagName)
+		printReleaseNotes(progressUI, release, releaseNotesLines)
+
+		inst, err := updater.DetectInstallation()
+		if err != nil {
+			progressUI.Error("❌ Update failed: %v", err)
+			os.Exit(1)
+		}
+		switch inst.Method {
+		case updater.MethodHomebrew:
+			progressUI.Info("🍺 %s is installed with Homebrew; update it there so brew stays in sync:", inst.Path)
+			progressUI.Info("   %s", inst.UpgradeCommand())
+			return
+		case updater.MethodSystem:
+			progressUI.Info("🔐 %s is in a system directory; sudo will ask for your password to replace it", inst.Path)
+		}
+
 		progressUI.Info("📦 Updating to version %s...", release.TagName)
+		upd.SetProgress(func(done, total int64) {
+			if total > 0 {
+				progressUI.PrintProgress(int(done/1024), int(total/1024), "KB")
+			}
+		})
 
 		// Perform update
-		if err := upd.Update(release); err != nil {
+		result, err := upd.Update(release)
+		if err != nil {
 			progressUI.Error("❌ Update failed: %v", err)
 			os.Exit(1)
 		}
 
+		if state, err := config.LoadState(); err == nil {
+			config.RecordUpdateCheck(state, "", time.Now())
+			_ = config.SaveState(state)
+		}
+
 		progressUI.Success("✅ Update complete!")
+		for _, path := range result.SupportFiles {
+			progressUI.Info("  ✓ %s", path)
+		}
+		for _, warning := range result.Warnings {
+			progressUI.Warning("⚠️  Not updated: %s", warning)
+		}
 		progressUI.Info("Please restart your terminal or run 'devsetup --version' to verify")
 	},
 }
 
-// doctorCmd represents the doctor command
-var doctorCmd = &cobra.Command{
-	Use:   "doctor",
-	Short: "Run diagnostics",
-	Long: `Run diagnostic checks to identify environment issues.
+// changelogCmd shows release notes of past releases
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [version]",
+	Short: "Show release notes",
+	Long: `Show the release notes of devsetup releases, rendered for the terminal.
 
-Checks:
-- Homebrew installation and health
-- Required tools accessibility
-- Configuration file validity
-- State file integrity
-- Common path issues
+With a version (1.4.0 or v1.4.0), shows that release's full notes.
+Without one, shows the most recent releases (--limit), each cut to a
+few lines with a link to the full notes.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, _ := cmd.Flags().GetInt("limit")
 
-This command helps troubleshoot installation problems.`,
+		progressUI := ui.NewProgressUI()
+		upd := updater.NewUpdater(version)
+
+		if len(args) == 1 {
+			release, err := upd.Release(args[0])
+			if err != nil {
+				progressUI.Error("❌ Failed to fetch release notes: %v", err)
+				os.Exit(1)
+			}
+			printRelease(progressUI, release, 0)
+			return
+		}
+
+		releases, err := upd.Releases(limit)
+		if err != nil {
+			progressUI.Error("❌ Failed to fetch releases: %v", err)
+			os.Exit(1)
+		}
+		if len(releases) == 0 {
+			progressUI.Info("No releases published yet")
+			return
+		}
+		for i := range releases {
+			printRelease(progressUI, &releases[i], releaseNotesLines)
+		}
+	},
+}
+
+// containerizeCmd generates a devcontainer from tools.yaml
+var containerizeCmd = &cobra.Command{
+	Use:   "containerize",
+	Short: "Generate a devcontainer (Dockerfile + devcontainer.json) from tools.yaml",
+	Long: `Write a Dockerfile and devcontainer.json that build the tools.yaml
+environment in a container, for machines where tools can't be installed
+on the host.
+
+The image is Homebrew on Linux (` + export.ContainerImage + `), so formulas
+install with the same 'brew install' lines as on the Mac. Casks and
+script/archive installs are macOS-specific and are listed as skipped.
+Declared services' ports are forwarded, but services aren't started.
+
+Versions recorded on this machine are written as comments.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+		force, _ := cmd.Flags().GetBool("force")
+
 		progressUI := ui.NewProgressUI()
-		progressUI.Info("🔧 Running diagnostics...")
-		progressUI.Info("")
-		progressUI.Warning("⚠️  Doctor command not yet fully implemented")
-		progressUI.Info("For now, try:")
-		progressUI.Info("  • devsetup verify - Check installation status")
-		progressUI.Info("  • devsetup status - Show what's installed")
-		progressUI.Info("  • brew doctor - Check Homebrew health")
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		state, err := config.LoadState()
+		if err != nil {
+			progressUI.Warning("⚠️  Ignoring state (no host versions): %v", err)
+			state = nil
+		}
+		items, err := export.Classify(toolsConfig, state)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		files, skipped := export.Container(items)
+		if err := writeExportFiles(out, files, force); err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		for _, s := range skipped {
+			progressUI.Info("  - %s skipped: %s", s.Name, s.Reason)
+		}
+		progressUI.Success("🐳 Wrote %s and %s", filepath.Join(out, "Dockerfile"), filepath.Join(out, "devcontainer.json"))
+		progressUI.Info("Open the folder in VS Code and choose \"Reopen in Container\", or: docker build %s", out)
 	},
 }
 
-func main() {
-	// Add flags
-	installCmd.Flags().Bool("dry-run", false, "Show what would be installed without installing")
-	setupCmd.Flags().Bool("dry-run", false, "Show what would be configured without configuring")
-	updateCmd.Flags().Bool("check", false, "Check for updates without installing")
+// exportCmd translates tools.yaml into a Brewfile or Nix flake
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export tools.yaml as a Brewfile or Nix flake devShell, or an onboarding checklist",
+	Long: `Translate tools.yaml into another provisioning format, keeping
+tools.yaml the single source of truth.
 
-	// Add commands
-	rootCmd.AddCommand(installCmd)
-	rootCmd.AddCommand(setupCmd)
-	rootCmd.AddCommand(verifyCmd)
-	rootCmd.AddCommand(statusCmd)
-	rootCmd.AddCommand(updateCmd)
-	rootCmd.AddCommand(doctorCmd)
+Formats:
+  brewbundle  Brewfile with every formula and cask ('brew bundle')
+  nix         flake.nix with a default devShell of the nixpkgs equivalents
+  checklist   onboarding-checklist.md: every tool, setup task, and manual
+              step as a markdown checkbox with this machine's status, for
+              onboarding buddies and team leads
+
+Tools that the format can't express (shell commands, scripts, casks for
+nix) are listed as skipped. Versions recorded on this machine are
+written as comments.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		out, _ := cmd.Flags().GetString("out")
+		force, _ := cmd.Flags().GetBool("force")
+
+		progressUI := ui.NewProgressUI()
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		state, err := config.LoadState()
+		if err != nil {
+			progressUI.Warning("⚠️  Ignoring state (no host versions): %v", err)
+			state = nil
+		}
+		items, err := export.Classify(toolsConfig, state)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		var name, content string
+		var skipped []export.Skipped
+		switch format {
+		case "brewbundle":
+			name = "Brewfile"
+			content, skipped = export.Brewfile(items)
+		case "nix":
+			name = "flake.nix"
+			content, skipped = export.NixFlake(items)
+		case "checklist":
+			setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
+			if err != nil {
+				progressUI.Error("❌ Failed to load setup config: %v", err)
+				os.Exit(1)
+			}
+			if state == nil {
+				state = &config.State{}
+			}
+			reporter := status.NewReporter(toolsConfig, setupConfig, state, progressUI)
+			reporter.SetCheckCache(openCheckCache(cmd, toolsConfig))
+			name = "onboarding-checklist.md"
+			content = reporter.Checklist(time.Now())
+		default:
+			progressUI.Error("❌ Unknown format %q (expected nix, brewbundle, or checklist)", format)
+			os.Exit(1)
+		}
+
+		if err := writeExportFiles(out, map[string]string{name: content}, force); err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		for _, s := range skipped {
+			progressUI.Info("  - %s skipped: %s", s.Name, s.Reason)
+		}
+		progressUI.Success("📤 Wrote %s", filepath.Join(out, name))
+	},
+}
+
+// waitCmd follows an install running elsewhere
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Wait for a running install to finish, showing its progress",
+	Long: `Attach to an install running in another terminal or through the local
+API ('devsetup serve --api'), show its progress from the start, and return
+when it ends. Use it in CI and scripts to block until the machine is ready.
+
+Exit codes:
+  0  the install succeeded, or no install was ever recorded
+  1  the install failed, exited without finishing, or --timeout passed
+
+When no install is running, wait returns at once with the result of the
+last one.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		progressUI := ui.NewProgressUI()
+
+		run, err := runlog.Current()
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		switch {
+		case run == nil:
+			progressUI.Info("No install is running")
+			return
+		case !run.Running():
+			if !reportRun(progressUI, run, nil) {
+				os.Exit(1)
+			}
+			return
+		}
+
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		final, err := followRun(ctx, progressUI, run)
+		if errors.Is(err, context.DeadlineExceeded) {
+			progressUI.Error("❌ Install still running after %s", timeout)
+			os.Exit(1)
+		}
+		progressUI.Summary()
+		if !reportRun(progressUI, final, err) {
+			os.Exit(1)
+		}
+	},
+}
+
+// pathCmd regenerates the managed PATH file
+var pathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Regenerate the managed PATH file from tools.yaml path entries",
+	Long: `Tools declare the directories they need on PATH (path: in tools.yaml).
+devsetup writes them, in install order and without duplicates, to
+~/.local/share/devsetup/path.sh, which the managed block in ~/.zshrc
+sources (setup task managed-path adds the block).
+
+{brew_prefix} is /opt/homebrew on Apple Silicon and /usr/local on Intel.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		entries, err := pathmgr.Write(toolsConfig)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		for _, e := range entries {
+			progressUI.Info("  %-30s (%s)", e.Dir, e.Tool)
+		}
+		progressUI.Success("✅ Wrote %s (%d entries); open a new shell to pick it up", pathmgr.FilePath(), len(entries))
+	},
+}
+
+// uninstallCmd removes installed tools
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall <tool>...",
+	Short: "Uninstall tools and remove them from state",
+	Long: `Remove tools installed by devsetup.
+
+The uninstall command comes from the tool's uninstall: field in tools.yaml,
+or is inferred from how it installs:
+  type: brew / cask       brew uninstall [--cask] <package>
+  type: go                remove the binary from GOBIN (or GOPATH/bin)
+  type: cargo             cargo uninstall <package>
+  type: npm               npm uninstall -g <package>
+  type: archive           remove the binaries from the archive target
+  brew install commands   brew uninstall the same formulae and casks
+
+Script installs and other commands need an explicit uninstall: field.
+Tools that installed tools depend on are kept unless --force is given.
+The tool stays in tools.yaml; remove it there too or the next install
+brings it back.
+
+--deprecated removes every installed tool marked deprecated: in tools.yaml
+(install no longer installs those, so they stay gone).`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if deprecated, _ := cmd.Flags().GetBool("deprecated"); deprecated {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		deprecated, _ := cmd.Flags().GetBool("deprecated")
+
+		progressUI := ui.NewProgressUI()
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		if deprecated {
+			for _, tool := range toolsConfig.DeprecatedTools() {
+				args = append(args, tool.Name)
+			}
+			if len(args) == 0 {
+				progressUI.Success("✅ No tools are deprecated")
+				return
+			}
+		}
+		if !uninstallTools(cmd, progressUI, toolsConfig, args, force, dryRun) {
+			os.Exit(1)
+		}
+	},
+}
+
+// dotfilesCmd lists the backups setup keeps of shell dotfiles before changing them
+var dotfilesCmd = &cobra.Command{
+	Use:   "dotfiles",
+	Short: "List backups of shell dotfiles changed by setup",
+	Long: `Setup shows a diff and asks before changing .zshrc/.zprofile (or applies
+it with --yes), and keeps a timestamped copy of the previous file under
+~/.local/share/devsetup/backups/dotfiles.
+
+  devsetup dotfiles                         # list backups
+  devsetup dotfiles restore .zshrc          # newest backup
+  devsetup dotfiles restore .zshrc --at 20260101-120000
+  devsetup dotfiles restore ~/.ssh/config   # files in subdirectories by path`,
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		backups, err := dotfiles.Backups("")
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		if len(backups) == 0 {
+			progressUI.Info("No dotfile backups yet")
+			return
+		}
+		for _, b := range backups {
+			progressUI.Info("  %-12s %s  (--at %s)", b.Name, b.Time.Format(time.DateTime), b.Time.Format("20060102-150405"))
+		}
+	},
+}
+
+// dotfilesRestoreCmd puts a dotfile backup back in place
+var dotfilesRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Restore a shell dotfile from its backup (the current file is backed up first)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+		at, _ := cmd.Flags().GetString("at")
+
+		backups, err := dotfiles.Backups(dotfiles.Name(args[0]))
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		var chosen *dotfiles.Backup
+		for i, b := range backups {
+			if at == "" || b.Time.Format("20060102-150405") == at {
+				chosen = &backups[i]
+				break
+			}
+		}
+		if chosen == nil {
+			progressUI.Error("❌ No backup of %s found (see 'devsetup dotfiles')", args[0])
+			os.Exit(1)
+		}
+
+		path, err := dotfiles.Restore(*chosen)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		progressUI.Success("✅ Restored %s from %s", path, chosen.Time.Format(time.DateTime))
+	},
+}
+
+// manualCmd lists the manual setup steps and whether each is done
+var manualCmd = &cobra.Command{
+	Use:   "manual",
+	Short: "List manual onboarding steps (VPN access, invites) and which are still pending",
+	Long: `Some onboarding steps can't be automated. setup.yaml declares them as
+manual tasks; setup shows their instructions and asks whether they're done.
+
+  devsetup manual                  # list manual steps with their instructions
+  devsetup manual done vpn-access  # mark a step done (its verify command must pass)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+		setupConfig, state := loadManualSteps(progressUI)
+
+		shown := 0
+		for _, task := range setupConfig.SetupTasks {
+			if task.Manual == nil {
+				continue
+			}
+			shown++
+			switch {
+			case !state.Acknowledged[task.Name].IsZero():
+				progressUI.Success("  ✓ %s (done %s)", task.Name, state.Acknowledged[task.Name].Format(time.DateOnly))
+				continue
+			case task.Manual.Verify != "" && setup.VerifyManual(task, setupConfig.ShellFor(task)) == nil:
+				progressUI.Success("  ✓ %s (verified)", task.Name)
+				continue
+			case config.IsTaskConfigured(state, task.Name):
+				progressUI.Success("  ✓ %s", task.Name)
+				continue
+			}
+			progressUI.Warning("  ☐ %s", task.Name)
+			for _, line := range setup.ManualInstructions(task) {
+				progressUI.Info("      %s", line)
+			}
+		}
+		if shown == 0 {
+			progressUI.Info("No manual steps in setup.yaml")
+		}
+	},
+}
+
+// manualDoneCmd acknowledges manual steps
+var manualDoneCmd = &cobra.Command{
+	Use:   "done <task>...",
+	Short: "Mark manual steps as done",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+		setupConfig, state := loadManualSteps(progressUI)
+
+		failed := false
+		for _, name := range args {
+			task := setupConfig.Task(name)
+			if task == nil || task.Manual == nil {
+				progressUI.Error("❌ %s is not a manual step in setup.yaml (see 'devsetup manual')", name)
+				failed = true
+				continue
+			}
+			if err := setup.VerifyManual(*task, setupConfig.ShellFor(*task)); err != nil {
+				progressUI.Error("❌ %s: %v", name, err)
+				failed = true
+				continue
+			}
+			config.AcknowledgeTask(state, name)
+			progressUI.Success("✅ %s marked done", name)
+		}
+		if err := config.SaveState(state); err != nil {
+			progressUI.Error("❌ Failed to save state: %v", err)
+			os.Exit(1)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// loadManualSteps loads setup.yaml and state for the manual commands, exiting on errors
+func loadManualSteps(progressUI ui.UI) (*config.SetupConfig, *config.State) {
+	setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
+	if err != nil {
+		progressUI.Error("❌ Failed to load setup config: %v", err)
+		os.Exit(1)
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		progressUI.Error("❌ Failed to load state: %v", err)
+		os.Exit(1)
+	}
+	return setupConfig, state
+}
+
+// configCmd groups commands that inspect the configs themselves
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check tools.yaml and setup.yaml without running them",
+}
+
+// configLintCmd reports best-practice violations in the configs
+var configLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Flag config anti-patterns (missing checks and timeouts, hand-written typed installs)",
+	Long: `Check tools.yaml and setup.yaml against best-practice rules. Nothing is
+executed, so lint runs in CI on any OS.
+
+Rules:
+  idempotency                 tools without check/creates/unless; setup steps without creates (mkdir -p and the like are fine)
+  timeout                     command and script installs, remote/local setup commands without a timeout
+  required-after-optional     required tools and tasks depending on optional ones (run without them)
+  typed-install               commands like 'brew install jq' that a typed install (type: brew) replaces
+  unreferenced                groups no tool uses; optional, undescribed tools nothing depends on
+
+Exit codes:
+  0 - No findings
+  1 - Findings (or the configs don't load)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		disabled, _ := cmd.Flags().GetStringSlice("disable")
+		progressUI := ui.NewProgressUI()
+
+		for _, name := range disabled {
+			if !slices.Contains(lint.Rules, name) {
+				progressUI.Error("❌ Unknown rule %q (rules: %s)", name, strings.Join(lint.Rules, ", "))
+				os.Exit(1)
+			}
+		}
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load setup config: %v", err)
+			os.Exit(1)
+		}
+
+		shown := 0
+		for _, f := range lint.Run(toolsConfig, setupConfig) {
+			if slices.Contains(disabled, f.Rule) {
+				continue
+			}
+			shown++
+			progressUI.Warning("⚠ %s [%s] %s: %s", f.Location, f.Rule, f.Subject, f.Message)
+		}
+		if shown > 0 {
+			progressUI.Info("")
+			progressUI.Error("❌ %d finding(s); --disable <rule> turns a rule off", shown)
+			os.Exit(1)
+		}
+		progressUI.Success("✅ No lint findings")
+	},
+}
+
+// configRenderCmd prints a config as a described machine would load it
+var configRenderCmd = &cobra.Command{
+	Use:   "render <file>",
+	Short: "Print a fully resolved tools.yaml or setup.yaml for the machine in --vars",
+	Long: `Resolve tools.yaml or setup.yaml for the machine a vars file describes and
+print it as YAML: team profiles applied, canary rollouts decided, typed installs
+expanded into commands, and $HOME, ~/, {brew_prefix}, and prompt {value}
+filled in. Nothing is executed and your own configs.d is not read, so the output
+is the same on every machine; diff it in PR CI to review a config change.
+
+vars.yaml (every key optional):
+  facts: {user: alice, host: alice-mbp, bucket: 42, home: /Users/alice, brew_prefix: /opt/homebrew}
+  profiles: [mobile]              # defaults.yaml profiles, applied in order
+  answers: {GEMINI_API_KEY: x}    # prompt answers by env_var (secret ones print as ***)
+  env: {WORKSPACE: /Users/alice/src}
+
+Without --vars: user dev, host ci, bucket 99 (only full rollouts), no profiles.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		varsPath, _ := cmd.Flags().GetString("vars")
+		progressUI := ui.NewProgressUI()
+
+		vars, err := config.LoadRenderVars(varsPath)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		out, err := config.Render(args[0], vars)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(out)
+	},
+}
+
+// rolloutCmd shows where this machine stands in canary rollouts
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Show this machine's rollout bucket and which canary tools and tasks it gets",
+	Long: `Tools and setup tasks with a rollout: block reach only part of the fleet:
+machines whose bucket (0-99, from a random ID kept in the state dir) is
+below percent, plus the listed users and hosts. Everything else treats a
+held-back tool as if it weren't declared.
+
+  rollout:
+    percent: 10
+    users: [alice]
+    hosts: ["qa-*"]`,
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load setup config: %v", err)
+			os.Exit(1)
+		}
+
+		machine := config.CurrentMachine()
+		progressUI.Info("🧪 %s@%s, bucket %d (machine ID: %s)", machine.User, machine.Host, machine.Bucket, config.MachineIDPath())
+		progressUI.Info("")
+
+		included := 0
+		for _, tool := range toolsConfig.Tools {
+			if tool.Rollout != nil {
+				progressUI.Success("  ✓ %-20s tool (%s)", tool.Name, tool.Rollout)
+				included++
+			}
+		}
+		for _, task := range setupConfig.SetupTasks {
+			if task.Rollout != nil {
+				progressUI.Success("  ✓ %-20s task (%s)", task.Name, task.Rollout)
+				included++
+			}
+		}
+		for _, name := range toolsConfig.HeldBack {
+			progressUI.Warning("  ⏸ %-20s tool (held back)", name)
+		}
+		for _, name := range setupConfig.HeldBack {
+			progressUI.Warning("  ⏸ %-20s task (held back)", name)
+		}
+		if included+len(toolsConfig.HeldBack)+len(setupConfig.HeldBack) == 0 {
+			progressUI.Info("No tools or tasks are in a rollout")
+		}
+	},
+}
+
+// syncCmd shows the personal settings sync target and the files it carries
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync personal overlays, exceptions, and prompt answers between your machines",
+	Long: `Keep personal devsetup settings in step across machines through a private
+gist or git repo you own.
+
+Synced: ~/.config/devsetup/configs.d overlays (not team profiles),
+exceptions.yaml, and non-secret prompt answers (answers.yaml).
+Never synced: state, team overlays, secret prompt answers. A push is
+refused if any synced file contains a secret.
+
+  devsetup sync init <gist-id|repo-url>
+  devsetup sync push     # this machine → remote
+  devsetup sync pull     # remote → this machine`,
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		remote, err := configsync.LoadRemote()
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		progressUI.Info("Remote: %s", remote.URL)
+		files := configsync.SyncedFiles(config.GetUserConfigDir())
+		if len(files) == 0 {
+			progressUI.Info("No personal settings to sync yet")
+			return
+		}
+		for _, file := range files {
+			progressUI.Info("  %s", file)
+		}
+	},
+}
+
+// syncInitCmd configures the sync target
+var syncInitCmd = &cobra.Command{
+	Use:   "init <gist-id|repo-url|owner/repo>",
+	Short: "Set the private gist or repo to sync through",
+	Long: `Set the private gist or git repo personal settings sync through.
+
+owner/repo is a repo on the release host of configs/releases.yaml
+(github.com unless a GitLab mirror is configured).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		remote := &configsync.Remote{URL: configsync.ResolveURL(updater.CloneURL(args[0]))}
+		if err := configsync.SaveRemote(remote); err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		progressUI.Success("✅ Syncing through %s (keep it private)", remote.URL)
+	},
+}
+
+// syncPushCmd uploads this machine's personal settings
+var syncPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload this machine's personal settings",
+	Run: func(cmd *cobra.Command, args []string) {
+		runSync(configsync.Push, "Pushed")
+	},
+}
+
+// syncPullCmd downloads personal settings pushed from another machine
+var syncPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Download personal settings pushed from another machine",
+	Run: func(cmd *cobra.Command, args []string) {
+		runSync(configsync.Pull, "Pulled")
+	},
+}
+
+// servicesCmd lists the brew services declared in tools.yaml
+var servicesCmd = &cobra.Command{
+	Use:   "services",
+	Short: "List, start, and stop brew services declared in tools.yaml",
+	Long: `Show the brew services (postgresql, redis, ...) declared by tools in
+tools.yaml with 'service:', whether they are running, and whether their
+port accepts connections.
+
+Install starts declared services automatically unless they are marked
+'manual: true'; use 'devsetup services start|stop [tool...]' to control
+them afterwards.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		tools := serviceTools(progressUI, nil)
+		if len(tools) == 0 {
+			progressUI.Info("No services declared in tools.yaml")
+			return
+		}
+		for _, tool := range tools {
+			name := tool.Service.ServiceName(tool.Name)
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			ok, msg := services.Check(ctx, name, tool.Service.Port)
+			cancel()
+			if ok {
+				progressUI.Success("✓ %-20s %s", name, msg)
+			} else {
+				progressUI.Error("✗ %-20s %s", name, msg)
+			}
+		}
+	},
+}
+
+// servicesStartCmd starts declared brew services
+var servicesStartCmd = &cobra.Command{
+	Use:   "start [tool...]",
+	Short: "Start declared services and wait for their ports (default: all)",
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		failed := false
+		for _, tool := range serviceTools(progressUI, args) {
+			svc := tool.Service
+			name := svc.ServiceName(tool.Name)
+			ctx, cancel := context.WithTimeout(context.Background(), svc.Timeout()+time.Minute)
+			err := services.Ensure(ctx, name, svc.Port, svc.Timeout())
+			cancel()
+			if err != nil {
+				progressUI.Error("❌ %s: %v", name, err)
+				failed = true
+				continue
+			}
+			progressUI.Success("✅ %s running", name)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// servicesStopCmd stops declared brew services
+var servicesStopCmd = &cobra.Command{
+	Use:   "stop [tool...]",
+	Short: "Stop declared services (default: all)",
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		failed := false
+		for _, tool := range serviceTools(progressUI, args) {
+			name := tool.Service.ServiceName(tool.Name)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			err := services.Stop(ctx, name)
+			cancel()
+			if err != nil {
+				progressUI.Error("❌ %s: %v", name, err)
+				failed = true
+				continue
+			}
+			progressUI.Success("⏹  %s stopped", name)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// pinCmd represents the pin command group
+var pinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Manage local deviations from pinned tool versions",
+}
+
+// pinOverrideCmd records a temporary, sanctioned version override for a tool
+var pinOverrideCmd = &cobra.Command{
+	Use:   "override <tool>",
+	Short: "Temporarily accept a different version of a tool",
+	Long: `Record that a tool is sanctioned to run a different version than the
+one devsetup installed, until a date.
+
+The override is written to ~/.config/devsetup/exceptions.yaml. Until it
+expires, 'devsetup verify --deep' checks the tool against the override
+version and reports it as excepted, and 'devsetup status' lists it as
+overridden. After the expiry date verify warns and checks the tool normally.
+
+The version defaults to the one installed now.
+
+Example:
+  devsetup pin override node --until 2026-08-01 --reason "deploy blocker"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		until, _ := cmd.Flags().GetString("until")
+		reason, _ := cmd.Flags().GetString("reason")
+		pinVersion, _ := cmd.Flags().GetString("version")
+
+		progressUI := ui.NewProgressUI()
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		var tool config.Tool
+		for _, t := range toolsConfig.Tools {
+			if t.Name == args[0] {
+				tool = t
+			}
+		}
+		if tool.Name == "" {
+			progressUI.Error("❌ Unknown tool %q (not in tools.yaml)", args[0])
+			os.Exit(1)
+		}
+
+		if pinVersion == "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			pinVersion, _ = installer.GetToolInfo(ctx, tool, toolsConfig.ShellFor(tool))
+			cancel()
+			if pinVersion == "unknown" {
+				progressUI.Error("❌ Can't detect the installed %s version; pass --version", tool.Name)
+				os.Exit(1)
+			}
+		}
+
+		override := config.Exception{Name: tool.Name, Version: pinVersion, Reason: reason, Expires: until}
+		if !override.Active(time.Now()) {
+			progressUI.Error("❌ --until must be today or a later YYYY-MM-DD date, got %q", until)
+			os.Exit(1)
+		}
+
+		exceptions := loadExceptions(progressUI)
+		exceptions.SetOverride(override)
+		if err := config.SaveExceptions(exceptions); err != nil {
+			progressUI.Error("❌ Failed to save override: %v", err)
+			os.Exit(1)
+		}
+		progressUI.Success("📌 %s override %s recorded (%s)", tool.Name, pinVersion, override.Describe())
+		progressUI.Info("Verify and status treat it as sanctioned until it expires (%s)", config.ExceptionsPath())
+	},
+}
+
+// selfCmd represents the self command group
+var selfCmd = &cobra.Command{
+	Use:   "self",
+	Short: "Inspect devsetup itself",
+}
+
+// selfCheckCmd verifies the integrity of the devsetup installation
+var selfCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Verify devsetup's own integrity",
+	Long: `Check that devsetup itself is healthy before debugging the machine.
+
+Checks:
+- The binary matches the SHA256 published with its release
+- Embedded configs parse and validate
+- State files (~/.local/share/devsetup/*.json) are readable
+- State, log, download cache, and config directories are writable
+
+Release lookups that can't run (dev builds, offline) are warnings.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		results := selfcheck.Run(version)
+		for _, r := range results {
+			switch r.Status {
+			case selfcheck.StatusOK:
+				progressUI.Success("✅ %s: %s", r.Name, r.Detail)
+			case selfcheck.StatusWarning:
+				progressUI.Warning("⚠️  %s: %s", r.Name, r.Detail)
+			default:
+				progressUI.Error("❌ %s: %s", r.Name, r.Detail)
+			}
+		}
+
+		if selfcheck.Failed(results) {
+			os.Exit(1)
+		}
+	},
+}
+
+// supportCmd represents the support command group
+var supportCmd = &cobra.Command{
+	Use:   "support",
+	Short: "Collect information for help requests",
+}
+
+// supportBundleCmd writes a support bundle zip
+var supportBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Write a zip of logs, state, and machine facts for a help ticket",
+	Long: `Gather what support asks for first into one zip to attach to a ticket:
+
+- facts.json: devsetup version, macOS version, architecture, shell, Homebrew
+- configs.json: SHA256 and source (disk, embedded, overlay) of each config
+- last-failure.json: which tools/tasks failed in the last failed run, with log paths
+- state/*.json: state files from ~/.local/share/devsetup
+- logs/*.log: command logs from the last 7 days (last 1MB of each)
+
+Secrets are masked and your home directory is replaced with ~. Review the
+zip before sharing it outside your organization.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+
+		progressUI := ui.NewProgressUI()
+
+		if out == "" {
+			out = fmt.Sprintf("devsetup-support-%s.zip", time.Now().Format("20060102-150405"))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		names, err := support.WriteBundle(ctx, out, version)
+		if err != nil {
+			progressUI.Error("❌ Failed to write support bundle: %v", err)
+			os.Exit(1)
+		}
+		progressUI.Success("📦 Wrote %s (%d files)", out, len(names))
+		progressUI.Info("Attach it to your help ticket")
+	},
+}
+
+// reportIssueCmd opens a pre-filled GitHub issue for an installer problem
+var reportIssueCmd = &cobra.Command{
+	Use:   "report-issue",
+	Short: "Open a pre-filled GitHub issue for a devsetup problem",
+	Long: `Open a new GitHub issue pre-filled with the last failure summary,
+the devsetup version, macOS version, and Homebrew version.
+
+A support bundle is written first (unless --no-bundle); drag it into the
+issue to attach it. Uses 'gh issue create --web' when gh is installed,
+otherwise opens the new-issue URL in your browser. Nothing is submitted
+until you press "Submit" on GitHub.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		noBundle, _ := cmd.Flags().GetBool("no-bundle")
+		printURL, _ := cmd.Flags().GetBool("print-url")
+
+		progressUI := ui.NewProgressUI()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		failure, err := support.LastFailure()
+		if err != nil {
+			progressUI.Warning("⚠️  Ignoring failure summary: %v", err)
+		}
+
+		bundlePath := ""
+		if !noBundle {
+			path := fmt.Sprintf("devsetup-support-%s.zip", time.Now().Format("20060102-150405"))
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+			if _, err := support.WriteBundle(ctx, path, version); err != nil {
+				progressUI.Warning("⚠️  Support bundle skipped: %v", err)
+			} else {
+				progressUI.Success("📦 Wrote %s; drag it into the issue to attach it", path)
+				bundlePath = path
+			}
+		}
+
+		title := support.IssueTitle(failure)
+		body := support.IssueBody(support.CollectFacts(ctx, version), failure, bundlePath)
+		issueURL := support.IssueURL(repo, title, body)
+
+		if printURL {
+			fmt.Println(issueURL)
+			return
+		}
+
+		if _, err := exec.LookPath("gh"); err == nil {
+			ghCmd := exec.Command("gh", "issue", "create", "--repo", repo, "--title", title, "--body-file", "-", "--web")
+			ghCmd.Stdin = strings.NewReader(body)
+			ghCmd.Stdout, ghCmd.Stderr = os.Stdout, os.Stderr
+			if err := ghCmd.Run(); err == nil {
+				return
+			}
+			progressUI.Warning("⚠️  gh issue create failed; falling back to the browser")
+		}
+		if err := exec.Command("open", issueURL).Run(); err != nil {
+			progressUI.Info("Open this URL to file the issue:")
+			fmt.Println(issueURL)
+		}
+	},
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Run diagnostics",
+	Long: `Run diagnostic checks to identify environment issues.
+
+Checks:
+- Compliance policies (FileVault, firewall, screen lock, macOS version)
+- Denied software (unapproved apps, EOL runtimes from policies.yaml)
+- Homebrew hardening (analytics, redirects, auto-update, directory ownership)
+- Homebrew health (brew doctor, missing dependencies, prefix permissions,
+  shallow homebrew-core, tap integrity)
+- Version managers (nvm/fnm/volta, pyenv/uv/conda side by side; standard
+  from policies.yaml version_managers)
+- macOS compatibility (minimum version; installed tools an OS upgrade would break)
+- Gatekeeper quarantine (installed tools whose binary or app is still blocked)
+- Shell startup (interactive zsh against the policies.yaml shell_startup budget;
+  over budget, the slowest ~/.zshrc lines, marking those devsetup added)
+
+Use --fix for guided remediation: each available fix is shown and
+applied only after you confirm it.
+
+This command helps troubleshoot installation problems.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fix, _ := cmd.Flags().GetBool("fix")
+
+		progressUI := ui.NewProgressUI()
+
+		// Policy checks are skipped (not fatal) when policies can't be loaded
+		policyConfig, err := config.LoadPolicyConfig("configs/policies.yaml")
+		if err != nil {
+			progressUI.Warning("⚠️  Skipping policy checks: %v", err)
+			policyConfig = nil
+		}
+
+		d := doctor.NewDoctor(policyConfig, progressUI, fix)
+
+		// macOS compatibility checks are skipped (not fatal) when tools.yaml or state can't be loaded
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Warning("⚠️  Skipping macOS compatibility checks: %v", err)
+		} else if state, err := config.LoadState(); err != nil {
+			progressUI.Warning("⚠️  Skipping macOS compatibility checks: %v", err)
+		} else {
+			d.SetTools(toolsConfig, state)
+		}
+		if setupConfig, err := config.LoadSetupConfig("configs/setup.yaml"); err == nil {
+			d.SetSetup(setupConfig)
+		}
+
+		if _, err := d.Run(); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "List remote scripts the configuration would execute",
+	Long: `List every remote script that 'devsetup install' and 'devsetup setup' would run.
+
+Statuses:
+  pinned    Script install with a SHA256 checksum (changes fail the install)
+  unpinned  Script install without a checksum
+  inline    Fetched and piped to a shell inside a command (cannot be pinned)
+
+Use --fetch to download each script and show its current SHA256, which
+can be copied into script.checksum to pin it.
+
+Exit codes:
+  0 - Audit completed
+  1 - A pinned script no longer matches its checksum (with --fetch)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fetch, _ := cmd.Flags().GetBool("fetch")
+
+		// Initialize UI
+		progressUI := ui.NewProgressUI()
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+
+		setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load setup config: %v", err)
+			os.Exit(1)
+		}
+
+		scripts := audit.RemoteScripts(toolsConfig, setupConfig)
+		if len(scripts) == 0 {
+			progressUI.Success("✅ No remote scripts in configuration")
+			return
+		}
+
+		mismatches := 0
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		header := "SOURCE\tSTATUS\tURL"
+		if fetch {
+			header += "\tCURRENT SHA256"
+		}
+		_, _ = fmt.Fprintln(tw, header)
+
+		for _, s := range scripts {
+			line := fmt.Sprintf("%s\t%s\t%s", s.Source, s.Status, s.URL)
+			if fetch {
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				sum, err := audit.FetchChecksum(ctx, s.URL)
+				cancel()
+				switch {
+				case err != nil:
+					line += "\terror: " + err.Error()
+				case s.Status == audit.StatusPinned && sum != s.Checksum:
+					line += "\t" + sum + " (MISMATCH)"
+					mismatches++
+				default:
+					line += "\t" + sum
+				}
+			}
+			_, _ = fmt.Fprintln(tw, line)
+		}
+		_ = tw.Flush()
+
+		if mismatches > 0 {
+			progressUI.Error("❌ %d pinned script(s) changed upstream", mismatches)
+			os.Exit(1)
+		}
+	},
+}
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve machine status as JSON over HTTP",
+	Long: `Run a local HTTP endpoint with this machine's setup status.
+
+Endpoints:
+  GET /status                          Progress, remaining items, and drift
+  GET /verify?mode=quick|standard|deep Status plus verify results (default quick;
+                                       standard and deep need the API bearer token)
+  GET /healthz                         Liveness check
+
+Requests must address the server by IP, localhost, or this machine's host
+name; other Host headers are refused (DNS rebinding protection).
+
+With --api, authenticated control endpoints are added (see 'API' below).
+
+With --report-url, quick verify results (including compliance policies) are
+also pushed to a central team dashboard every --report-interval. Set
+DEVSETUP_REPORT_TOKEN to send it as a bearer token.
+
+API (--api; send "Authorization: Bearer <token>"):
+  POST /api/v1/install?dry_run=true        Install tools
+  POST /api/v1/verify?mode=quick&policy=true
+  POST /api/v1/doctor                      Diagnostics (no fixes)
+  GET  /api/v1/status                      Same document as /status
+  POST endpoints stream NDJSON progress events ending with a "done" event
+  that carries ok, result, and error. One operation runs at a time (409 if busy).
+  The token is DEVSETUP_API_TOKEN or ~/.local/share/devsetup/api-token.
+
+The server listens on 127.0.0.1 by default; stop it with Ctrl+C.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, _ := cmd.Flags().GetString("addr")
+		reportURL, _ := cmd.Flags().GetString("report-url")
+		interval, _ := cmd.Flags().GetDuration("report-interval")
+		enableAPI, _ := cmd.Flags().GetBool("api")
+
+		// Initialize UI
+		progressUI := ui.NewProgressUI()
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+
+		setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load setup config: %v", err)
+			os.Exit(1)
+		}
+
+		server := fleet.NewServer(toolsConfig, setupConfig, version)
+		policyConfig, err := config.LoadPolicyConfig("configs/policies.yaml")
+		if err == nil {
+			server.SetPolicies(policyConfig)
+		}
+
+		// The token also guards /verify's standard and deep modes, which run every check command
+		token, tokenPath, err := api.LoadOrCreateToken()
+		if err != nil {
+			if enableAPI {
+				progressUI.Error("❌ Failed to set up API token: %v", err)
+				os.Exit(1)
+			}
+			progressUI.Warning("⚠️  No API token (%v); /verify serves quick mode only", err)
+		}
+		server.SetToken(token)
+
+		if enableAPI {
+			apiServer := api.NewServer(toolsConfig, setupConfig, policyConfig, version, token)
+			apiServer.SetStatusHandler(server.StatusHandler())
+			apiServer.SetHooks(loadHooksConfig(progressUI))
+			server.Mount(api.Prefix, apiServer.Handler())
+			if tokenPath != "" {
+				progressUI.Info("🔑 API enabled; token in %s", tokenPath)
+			} else {
+				progressUI.Info("🔑 API enabled; token from DEVSETUP_API_TOKEN")
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if reportURL != "" {
+			if interval < time.Minute {
+				progressUI.Error("❌ --report-interval must be at least 1m")
+				os.Exit(1)
+			}
+			progressUI.Info("📤 Reporting to %s every %s", reportURL, interval)
+			go server.ReportPeriodically(ctx, reportURL, os.Getenv("DEVSETUP_REPORT_TOKEN"), interval, func(err error) {
+				progressUI.Warning("⚠️  Report failed: %v", err)
+			})
+		}
+
+		if addr == "" {
+			addr = fleet.DefaultAddr
+		}
+		progressUI.Info("🌐 Serving status on http://%s/status", addr)
+		if err := server.ListenAndServe(ctx, addr); err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show what install and setup would change on this machine",
+	Long: `Resolve tools.yaml and setup.yaml against this machine and print the
+execution plan: tools to install (by stage), setup tasks to configure, and
+estimates. Nothing is changed.
+
+Estimates are upper bounds: time sums the longest install timeout per stage,
+and only archive downloads are sized (Homebrew and script installs are not).
+
+Use --out to save the plan; 'devsetup apply --plan <file>' then installs and
+configures exactly the planned items, and refuses if the configs changed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+
+		progressUI := ui.NewProgressUI()
+
+		// Team profiles are resolved now so the plan (and its config hash) includes them
+		applyTeamDefaults(progressUI)
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load setup config: %v", err)
+			os.Exit(1)
+		}
+		state, err := config.LoadState()
+		if err != nil {
+			progressUI.Error("❌ Failed to load state: %v", err)
+			os.Exit(1)
+		}
+		reportOverrides(progressUI, toolsConfig.Overrides)
+		warnConfigDrift(progressUI)
+
+		checks := openCheckCache(cmd, toolsConfig)
+		p, err := plan.Build(context.Background(), toolsConfig, setupConfig, state, checks, version)
+		_ = checks.Save()
+		if err != nil {
+			progressUI.Error("❌ Failed to build plan: %v", err)
+			os.Exit(1)
+		}
+
+		progressUI.Info("📋 Plan: %d to install, %d to configure, %d unchanged", len(p.Installs), len(p.Setup), p.Unchanged)
+		for _, s := range p.Installs {
+			progressUI.Info("  + [%s] %s: %s", stageLabel(s), s.Name, s.Detail)
+		}
+		for _, s := range p.Setup {
+			progressUI.Info("  ~ %s: %s", s.Name, s.Detail)
+		}
+		if p.Empty() {
+			progressUI.Success("✅ Nothing to do; this machine matches the configs")
+		} else {
+			progressUI.Info("⏱️  Estimated install time: up to %s", p.EstimatedTime)
+			progressUI.Info("📦 Known downloads: %.1f MB (%d install(s) not sized)", float64(p.DownloadBytes)/(1<<20), p.UnknownSizes)
+		}
+		if len(p.Rosetta) > 0 && rosetta.AppleSilicon(context.Background()) {
+			if rosetta.Missing(context.Background()) {
+				progressUI.Warning("🧬 Intel-only: %s; install will offer to add Rosetta 2 first", strings.Join(p.Rosetta, ", "))
+			} else {
+				progressUI.Info("🧬 Intel-only (run under Rosetta 2): %s", strings.Join(p.Rosetta, ", "))
+			}
+		}
+
+		if out != "" {
+			if err := p.Save(out); err != nil {
+				progressUI.Error("❌ %v", err)
+				os.Exit(1)
+			}
+			progressUI.Info("💾 Saved plan to %s; run 'devsetup apply --plan %s'", out, out)
+		}
+	},
+}
+
+// planApproveCmd records an approval in a saved plan
+var planApproveCmd = &cobra.Command{
+	Use:   "approve <plan-file>",
+	Short: "Approve a saved plan for change-managed machines",
+	Long: `Sign a plan file saved by 'devsetup plan --out' with your SSH key.
+
+The approval stores your identity (git user.email) and an SSH signature
+(ssh-keygen -Y sign) over a hash of the plan. apply checks the signature
+against the approver keys in policies.yaml, and refuses an approved plan that
+was edited afterwards or whose configs changed since. With
+change_management.require_plan_approval, apply refuses unapproved plans and,
+unless allow_self_approval is set, plans approved by the person applying them.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		key, _ := cmd.Flags().GetString("key")
+
+		progressUI := ui.NewProgressUI()
+		ctx := context.Background()
+
+		p, err := plan.Load(args[0])
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		by := plan.Identity(ctx)
+		if by == "" {
+			progressUI.Error("❌ Could not determine your identity; set git config user.email")
+			os.Exit(1)
+		}
+		policyConfig, err := config.LoadPolicyConfig("configs/policies.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load policy config: %v", err)
+			os.Exit(1)
+		}
+		if cm := policyConfig.ChangeManagement; cm != nil && len(cm.Approvers) > 0 && !cm.IsApprover(by) {
+			progressUI.Error("❌ %s is not an allowed plan approver (policies.yaml change_management.approvers)", by)
+			os.Exit(1)
+		}
+
+		if key == "" {
+			home, _ := os.UserHomeDir()
+			key = filepath.Join(home, ".ssh", "id_ed25519")
+		}
+		if err := p.Approve(ctx, by, key); err != nil {
+			progressUI.Error("❌ Failed to approve plan: %v", err)
+			os.Exit(1)
+		}
+		// Catch a key that doesn't match the one in policies.yaml now rather than at apply time
+		if cm := policyConfig.ChangeManagement; cm != nil && len(cm.Approvers) > 0 {
+			if err := p.CheckApproval(ctx, &config.ChangeManagementPolicy{Approvers: cm.Approvers}, ""); err != nil {
+				progressUI.Error("❌ %v", err)
+				os.Exit(1)
+			}
+		}
+		if err := p.Save(args[0]); err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		progressUI.Success("✅ Approved plan (%d to install, %d to configure) as %s", len(p.Installs), len(p.Setup), by)
+		progressUI.Info("   Plan hash: %s", p.Approval.PlanHash)
+	},
+}
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Execute a plan saved by 'devsetup plan --out'",
+	Long: `Install and configure exactly the tools and setup tasks in a saved plan.
+
+Declared items not in the plan are left alone. apply refuses to run when
+tools.yaml, setup.yaml, or configs.d overlays changed since the plan was made;
+run 'devsetup plan' again in that case.
+
+Approved plans (see 'devsetup plan approve') are also refused when edited
+after approval. policies.yaml can require approval:
+
+  change_management:
+    require_plan_approval: true
+    approvers:
+      - identity: lead@example.com
+        key: ssh-ed25519 AAAA...
+
+Exit codes are those of 'devsetup install' (2: optional tools failed).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		planPath, _ := cmd.Flags().GetString("plan")
+
+		progressUI := ui.NewProgressUI()
+		setPromptTimeout(cmd, progressUI)
+
+		p, err := plan.Load(planPath)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+		setupConfig, err := config.LoadSetupConfig("configs/setup.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load setup config: %v", err)
+			os.Exit(1)
+		}
+		policyConfig, err := config.LoadPolicyConfig("configs/policies.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load policy config: %v", err)
+			os.Exit(1)
+		}
+
+		if err := p.CheckApproval(context.Background(), policyConfig.ChangeManagement, plan.Identity(context.Background())); err != nil {
+			progressUI.Error("❌ Plan refused: %v", err)
+			os.Exit(1)
+		}
+		if hash, err := plan.ConfigHash(toolsConfig, setupConfig); err != nil || hash != p.ConfigHash {
+			if p.Approval != nil {
+				progressUI.Error("❌ Configs changed since %s approved the plan; make and approve a new plan", p.Approval.By)
+			} else {
+				progressUI.Error("❌ Configs changed since the plan was made (%s); run 'devsetup plan' again", p.CreatedAt.Local().Format(time.RFC822))
+			}
+			os.Exit(1)
+		}
+		if p.Approval != nil {
+			progressUI.Info("🔏 Approved by %s at %s", p.Approval.By, p.Approval.At.Local().Format(time.RFC822))
+		}
+		if p.Empty() {
+			progressUI.Success("✅ Plan has nothing to do")
+			return
+		}
+		if len(p.Installs) > 0 {
+			waitForInstall(cmd, progressUI)
+		}
+
+		state, err := config.LoadState()
+		if err != nil {
+			progressUI.Error("❌ Failed to load state: %v", err)
+			os.Exit(1)
+		}
+		dispatcher := hooks.NewDispatcher(loadHooksConfig(progressUI), progressUI)
+		filter := p.Filter(toolsConfig, setupConfig)
+
+		progressUI.Info("📋 Applying plan: %d to install, %d to configure", len(p.Installs), len(p.Setup))
+		exitCode := installer.ExitOK
+		if len(p.Installs) > 0 {
+			_, rec := startMetrics(cmd, progressUI, "install", false)
+			stageUI, run := beginRun(progressUI, "apply", progressUI)
+			toolInstaller := installer.NewToolInstaller(toolsConfig, state, stageUI, false, version)
+			toolInstaller.SetPolicies(policyConfig)
+			assumeYes, _ := cmd.Flags().GetBool("yes")
+			toolInstaller.SetAssumeYes(assumeYes)
+			toolInstaller.SetFilter(filter)
+			toolInstaller.SetHooks(dispatcher)
+			tasks, err := toolInstaller.InstallAll()
+			run.End(tasks, err)
+			finishMetrics(cmd, progressUI, rec, tasks, err)
+			installer.PrintSummary(progressUI, toolInstaller.Results(), tasks)
+			if err != nil {
+				progressUI.Summary()
+				prompt.Recap(progressUI)
+				progressUI.Error("❌ Installation failed: %v", err)
+				printOwners(progressUI, toolInstaller.Failures())
+				os.Exit(installer.ExitFailed)
+			}
+			exitCode = installer.ExitCode(toolInstaller.Results(), nil)
+		}
+
+		if len(p.Setup) > 0 {
+			// Team profiles were resolved by plan; only the default answers are needed here
+			teamDefaults, err := config.LoadTeamDefaults("configs/defaults.yaml")
+			if err != nil {
+				progressUI.Error("❌ Failed to load team defaults: %v", err)
+				os.Exit(1)
+			}
+			stageUI, rec := startMetrics(cmd, progressUI, "setup", false)
+			setupExecutor := setup.NewSetupExecutor(filter.FilterSetup(setupConfig), toolsConfig, state, stageUI, false)
+			setupExecutor.SetHooks(dispatcher)
+			setupExecutor.SetAnswers(config.MergeAnswers(teamDefaults.Answers))
+			assumeYes, _ := cmd.Flags().GetBool("yes")
+			setupExecutor.SetAssumeYes(assumeYes)
+			setupExecutor.SetPolicies(policyConfig)
+			err = setupExecutor.SetupAll()
+			finishMetrics(cmd, progressUI, rec, nil, err)
+			if err != nil {
+				progressUI.Summary()
+				prompt.Recap(progressUI)
+				progressUI.Error("❌ Setup failed: %v", err)
+				printOwners(progressUI, setupExecutor.Failures())
+				os.Exit(1)
+			}
+		}
+
+		progressUI.Summary()
+		prompt.Recap(progressUI)
+		recordGeneration(progressUI, "apply", toolsConfig, setupConfig, state)
+		if exitCode != installer.ExitOK {
+			progressUI.Warning("⚠️  Optional tools failed; exiting with code %d", exitCode)
+			os.Exit(exitCode)
+		}
+	},
+}
+
+// shareCmd represents the share command group
+var shareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "Share Homebrew downloads with other Macs on the LAN",
+	Long: `Share the Homebrew download cache between Macs onboarding at the same time.
+
+One machine that has already installed runs 'devsetup share serve'; the
+others run 'devsetup share pull' (or 'devsetup install --from-peers'), which
+finds it via Bonjour and copies the downloads they lack into their own
+Homebrew cache. Every file is checked against the server's SHA256 before it
+is kept, and brew verifies bottle checksums again when installing.
+
+Intended for trusted networks (office LAN, onboarding room).`,
+}
+
+// shareServeCmd serves this machine's Homebrew downloads
+var shareServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve this machine's Homebrew downloads on the LAN",
+	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetInt("port")
+
+		progressUI := ui.NewProgressUI()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		dir, err := share.DownloadsDir(ctx)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		hostname, _ := os.Hostname()
+		name := "devsetup on " + strings.TrimSuffix(hostname, ".local")
+		progressUI.Info("🔢 Checksumming %s...", dir)
+		err = share.Serve(ctx, dir, port, name, func(files int) {
+			progressUI.Success("📡 Sharing %d download(s) on port %d as %q; stop with Ctrl+C", files, port, name)
+		})
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// sharePullCmd copies Homebrew downloads from a peer
+var sharePullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Copy missing Homebrew downloads from a peer",
+	Long: `Copy the Homebrew downloads this machine lacks from a peer running
+'devsetup share serve'. Without --peer, peers are discovered via Bonjour.
+
+Only files whose checksum Homebrew records for a tools.yaml package (or one
+of its dependencies) are copied; anything else the peer offers is skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		peer, _ := cmd.Flags().GetString("peer")
+
+		progressUI := ui.NewProgressUI()
+		if err := pullFromPeers(progressUI, peer); err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// pullFromPeers copies missing Homebrew downloads from a peer into the local cache
+// What: Uses peer if given, otherwise the first peer found via Bonjour; copies only downloads whose checksum
+// brew records for the brew packages in tools.yaml
+// Why: Shared by 'share pull' and 'install --from-peers'
+// Params: progressUI - UI, peer - peer base URL ("" discovers one)
+// Returns: Error if no peer is found or the copy fails
+func pullFromPeers(progressUI ui.UI, peer string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dir, err := share.DownloadsDir(ctx)
+	if err != nil {
+		return err
+	}
+
+	if peer == "" {
+		progressUI.Info("🔎 Looking for peers sharing Homebrew downloads...")
+		peers, err := share.Discover(ctx, 3*time.Second)
+		if err != nil {
+			return err
+		}
+		if len(peers) == 0 {
+			return fmt.Errorf("no peers found (is 'devsetup share serve' running on the LAN?)")
+		}
+		peer = peers[0]
+	}
+
+	toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to load tools config: %w", err)
+	}
+	var formulas, casks []string
+	for _, tool := range toolsConfig.Tools {
+		f, c := tool.Install.BrewPackages()
+		formulas, casks = append(formulas, f...), append(casks, c...)
+	}
+	trusted, err := share.Checksums(ctx, formulas, casks)
+	if err != nil {
+		return err
+	}
+
+	progressUI.Info("📥 Copying downloads from %s...", peer)
+	result, err := share.Pull(ctx, strings.TrimSuffix(peer, "/"), dir, trusted)
+	if err != nil {
+		return err
+	}
+	progressUI.Success("✅ Copied %d download(s) (%.1f MB) from %s", result.Files, float64(result.Bytes)/(1<<20), peer)
+	if result.Untrusted > 0 {
+		progressUI.Info("Skipped %d download(s) Homebrew has no checksum for (other packages, or casks without sha256)", result.Untrusted)
+	}
+	return nil
+}
+
+// recordGeneration records a generation after a successful run
+// What: Stores configs and state as the next generation; prints its number when something changed
+// Why: Shared by install and setup; failures only warn because the run itself succeeded
+// Params: progressUI - UI, command - "install" or "setup", toolsConfig/setupConfig/state - what was applied
+func recordGeneration(progressUI ui.UI, command string, toolsConfig *config.ToolsConfig, setupConfig *config.SetupConfig, state *config.State) {
+	gen, err := generations.Record(command, version, toolsConfig, setupConfig, state)
+	if err != nil {
+		progressUI.Warning("⚠️  Failed to record generation: %v", err)
+		return
+	}
+	if gen != nil {
+		progressUI.Info("📚 Recorded generation %d (see 'devsetup generations list')", gen.Number)
+	}
+}
+
+// recordDriftHistory adds a verify run to the drift history, with the generation it was verified against
+// Returns: Error if the history can't be written
+func recordDriftHistory(toolsConfig *config.ToolsConfig, result *verify.VerifyResult) error {
+	gen := 0
+	if all, err := generations.List(); err == nil && len(all) > 0 {
+		gen = all[len(all)-1].Number
+	}
+	if err := drift.Record(drift.FromResult(result, toolsConfig, time.Now(), gen)); err != nil {
+		return fmt.Errorf("failed to record drift history: %w", err)
+	}
+	return nil
+}
+
+// generationsCmd represents the generations command group
+var generationsCmd = &cobra.Command{
+	Use:   "generations",
+	Short: "Show and roll back environment history",
+	Long: `Every successful install or setup run that changes the environment is
+recorded as a numbered generation: the resolved tools.yaml and setup.yaml,
+installed tool versions, and configured tasks.
+
+Generations are stored in ~/.local/share/devsetup/generations.`,
+}
+
+// generationsListCmd lists recorded generations
+var generationsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded generations",
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		all, err := generations.List()
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		if len(all) == 0 {
+			progressUI.Info("No generations recorded yet (run 'devsetup install')")
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "GEN\tDATE\tCOMMAND\tTOOLS\tTASKS\tCHANGES")
+		for i, gen := range all {
+			summary := "initial"
+			if i > 0 {
+				changes, err := generations.Diff(all[i-1], gen)
+				if err != nil {
+					summary = "error: " + err.Error()
+				} else {
+					summary = fmt.Sprintf("%d", len(changes.Lines()))
+				}
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%s\n", gen.Number, gen.Time.Local().Format("2006-01-02 15:04"),
+				gen.Command, len(gen.Installed), len(gen.Configured), summary)
+		}
+		_ = tw.Flush()
+	},
+}
+
+// generationsDiffCmd shows changes between generations
+var generationsDiffCmd = &cobra.Command{
+	Use:   "diff [from] [to]",
+	Short: "Show changes between generations",
+	Long: `Show what changed between two generations.
+
+  devsetup generations diff          Latest vs the one before it
+  devsetup generations diff 5        Generation 4 vs 5
+  devsetup generations diff 2 5      Generation 2 vs 5`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		progressUI := ui.NewProgressUI()
+
+		all, err := generations.List()
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		if len(all) == 0 {
+			progressUI.Info("No generations recorded yet")
+			return
+		}
+
+		to := all[len(all)-1].Number
+		from := to - 1
+		var numbers []int
+		for _, arg := range args {
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				progressUI.Error("❌ Invalid generation %q", arg)
+				os.Exit(1)
+			}
+			numbers = append(numbers, n)
+		}
+		switch len(numbers) {
+		case 1:
+			from, to = numbers[0]-1, numbers[0]
+		case 2:
+			from, to = numbers[0], numbers[1]
+		}
+
+		fromGen, err := generations.Get(from)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		toGen, err := generations.Get(to)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		changes, err := generations.Diff(fromGen, toGen)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		progressUI.Info("Generation %d → %d", from, to)
+		if changes.Empty() {
+			progressUI.Info("  (no changes)")
+			return
+		}
+		for _, line := range changes.Lines() {
+			switch line[0] {
+			case '+':
+				progressUI.Success("  %s", line)
+			case '-':
+				progressUI.Error("  %s", line)
+			default:
+				progressUI.Warning("  %s", line)
+			}
+		}
+	},
+}
+
+// generationsRollbackCmd restores a generation's configs
+var generationsRollbackCmd = &cobra.Command{
+	Use:   "rollback <n>",
+	Short: "Restore tools.yaml and setup.yaml from a generation",
+	Long: `Write the resolved tools.yaml and setup.yaml of generation <n> back to
+configs/ (current files are kept as *.bak-<timestamp>).
+
+Rollback restores declarations only. Run 'devsetup install' and
+'devsetup setup' afterwards. Tools added since generation <n> are listed
+but stay installed; with --uninstall they are removed using their
+uninstall command (see 'devsetup uninstall --help').`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		uninstall, _ := cmd.Flags().GetBool("uninstall")
+
+		progressUI := ui.NewProgressUI()
+
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			progressUI.Error("❌ Invalid generation %q", args[0])
+			os.Exit(1)
+		}
+		gen, err := generations.Get(n)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		// Tools added since the generation are looked up in the current
+		// tools.yaml, before rollback replaces it
+		var added []string
+		if all, err := generations.List(); err == nil && len(all) > 0 {
+			if changes, err := generations.Diff(gen, all[len(all)-1]); err == nil {
+				added = changes.ToolsAdded
+			}
+		}
+		var current *config.ToolsConfig
+		if uninstall && len(added) > 0 {
+			if current, err = config.LoadToolsConfig("configs/tools.yaml"); err != nil {
+				progressUI.Error("❌ Failed to load tools config: %v", err)
+				os.Exit(1)
+			}
+		}
+
+		written, err := generations.Rollback(gen, "configs")
+		if err != nil {
+			progressUI.Error("❌ Rollback failed: %v", err)
+			os.Exit(1)
+		}
+		for _, path := range written {
+			progressUI.Success("✅ Restored %s from generation %d", path, n)
+		}
+
+		switch {
+		case len(added) == 0:
+		case uninstall:
+			progressUI.Info("🗑️  Uninstalling tools added since generation %d: %s", n, strings.Join(added, ", "))
+			if !uninstallTools(cmd, progressUI, current, added, false, false) {
+				progressUI.Warning("⚠️  Some tools could not be uninstalled; see 'devsetup uninstall --help'")
+			}
+		default:
+			progressUI.Warning("⚠️  Tools added since generation %d stay installed: %s (use --uninstall to remove them)", n, strings.Join(added, ", "))
+		}
+		progressUI.Info("Next step: Run 'devsetup install' and 'devsetup setup'")
+	},
+}
+
+// driftCmd represents the drift command group
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Show how installed tools drifted from what was installed",
+}
+
+// driftHistoryCmd shows drift episodes recorded by verify
+var driftHistoryCmd = &cobra.Command{
+	Use:   "history [tool...]",
+	Short: "Show when tools drifted, for how long, and after which lock updates",
+	Long: `Every 'devsetup verify' run is recorded in
+~/.local/share/devsetup/drift/history.jsonl (kept for a year). This command
+reads the deep runs ('devsetup verify --deep') and lists each drift episode:
+when a tool's version or binary stopped matching what install recorded, how
+long until a deep run found it back in line, and the last generation that
+changed the tool's tools.yaml entry before it drifted (the lock update).
+
+Drift that starts right after a lock update usually means the machine hasn't
+run install since; drift with no recent update means something outside
+devsetup changed the tool (brew upgrade, a self-updating app).
+
+Examples:
+  devsetup drift history
+  devsetup drift history node --since 720h
+  devsetup drift history --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		since, _ := cmd.Flags().GetDuration("since")
+		format, _ := cmd.Flags().GetString("format")
+
+		progressUI := ui.NewProgressUI()
+		if format != "text" && format != "json" {
+			progressUI.Error("❌ Unknown format %q (expected text or json)", format)
+			os.Exit(1)
+		}
+
+		now := time.Now()
+		from := now.Add(-since)
+		runs, err := drift.Load(from)
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+		gens, err := generations.List()
+		if err != nil {
+			progressUI.Warning("⚠️  Lock updates unavailable: %v", err)
+		}
+
+		report := drift.NewReport(runs, gens, args, from, now)
+		if format == "json" {
+			if err := report.WriteJSON(os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		progressUI.Info("📈 Drift history since %s: %d verify run(s), %d deep", from.Local().Format("2006-01-02"), report.Runs, report.DeepRuns)
+		progressUI.Info("")
+		switch {
+		case report.DeepRuns == 0:
+			progressUI.Info("No deep runs recorded; drift is only detected by 'devsetup verify --deep'")
+			return
+		case len(report.Episodes) == 0:
+			progressUI.Success("✅ No drift in %d deep run(s)", report.DeepRuns)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "TOOL\tDRIFTED\tFOR\tDETAIL\tAFTER LOCK UPDATE")
+		for _, e := range report.Episodes {
+			lasted := driftDuration(e.Duration(now))
+			if e.Ongoing() {
+				lasted += " (ongoing)"
+			} else if e.Removed {
+				lasted += " (tool removed)"
+			}
+			after := "none recorded"
+			if e.After != nil {
+				after = fmt.Sprintf("gen %d (%s, %s before)", e.After.Generation, e.After.Time.Local().Format("2006-01-02"), driftDuration(e.Start.Sub(e.After.Time)))
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Tool, e.Start.Local().Format("2006-01-02 15:04"), lasted, e.Detail, after)
+		}
+		_ = tw.Flush()
+		progressUI.Info("")
+		progressUI.Info("Trend: %d episode(s) started in the last 30 days, %d in the 30 days before", report.Recent, report.Previous)
+	},
+}
+
+// driftDuration formats an episode length in days and hours
+// Example: 76h → "3d 4h"; 20m → "<1h"
+func driftDuration(d time.Duration) string {
+	days, hours := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour)
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return "<1h"
+}
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Compare installed Homebrew packages with tools.yaml",
+	Long: `Find Homebrew packages installed on this machine but not declared in
+tools.yaml, and declared packages that are not installed.
+
+For each undeclared package choose:
+  a  Adopt: append a tool entry to configs/tools.yaml
+  r  Remove: brew uninstall it
+  i  Ignore: add it to ~/.config/devsetup/reconcile-ignore.yaml
+  s  Skip for now
+
+Formulae are compared using 'brew leaves --installed-on-request', so
+dependencies of declared tools are not reported. Use --list to only report.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		listOnly, _ := cmd.Flags().GetBool("list")
+
+		progressUI := ui.NewProgressUI()
+
+		toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+		if err != nil {
+			progressUI.Error("❌ Failed to load tools config: %v", err)
+			os.Exit(1)
+		}
+
+		ignore, err := reconcile.LoadIgnore()
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		result, err := reconcile.Find(ctx, toolsConfig, ignore)
+		cancel()
+		if err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+
+		if len(result.Missing) > 0 {
+			progressUI.Warning("Declared but not installed (%d):", len(result.Missing))
+			for _, pkg := range result.Missing {
+				progressUI.Info("  • %s", pkg)
+			}
+			progressUI.Info("  Run 'devsetup install' to install them")
+			progressUI.Info("")
+		}
+
+		if len(result.Extra) == 0 {
+			progressUI.Success("✅ No undeclared Homebrew packages")
+			return
+		}
+
+		progressUI.Warning("Installed but not in tools.yaml (%d):", len(result.Extra))
+		if listOnly {
+			for _, pkg := range result.Extra {
+				progressUI.Info("  • %s", pkg)
+			}
+			return
+		}
+
+		hasHomebrew := false
+		for _, tool := range toolsConfig.Tools {
+			hasHomebrew = hasHomebrew || tool.Name == "homebrew"
+		}
+
+		for _, pkg := range result.Extra {
+			fmt.Printf("  %s — [a]dopt / [r]emove / [i]gnore / [s]kip? ", pkg)
+			answer, err := prompt.Ask(prompt.Question{Task: pkg.Name, Label: "adopt, remove, or ignore", Default: "s"})
+			if errors.Is(err, prompt.ErrDeferred) {
+				return
+			}
+			if err != nil {
+				fmt.Println()
+				return
+			}
+
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "a", "adopt":
+				if err := reconcile.Adopt("configs/tools.yaml", pkg, hasHomebrew); err != nil {
+					progressUI.Error("    ❌ %v", err)
+				} else {
+					progressUI.Success("    ✅ Added %s to configs/tools.yaml", pkg.Name)
+				}
+			case "r", "remove":
+				c := exec.Command("sh", "-c", pkg.UninstallCommand())
+				c.Stdout, c.Stderr = os.Stdout, os.Stderr
+				if err := c.Run(); err != nil {
+					progressUI.Error("    ❌ %s failed: %v", pkg.UninstallCommand(), err)
+				} else {
+					progressUI.Success("    ✅ Removed %s", pkg.Name)
+				}
+			case "i", "ignore":
+				if err := ignore.Add(pkg); err != nil {
+					progressUI.Error("    ❌ %v", err)
+				} else {
+					progressUI.Info("    Ignored (%s)", reconcile.IgnorePath())
+				}
+			default:
+				progressUI.Info("    Skipped")
+			}
+		}
+	},
+}
+
+// startMetrics begins recording a stage when --metrics-dir (default $DEVSETUP_METRICS_DIR) is set
+// Why: Shared build machines export run metrics for node_exporter's textfile collector
+// Params: cmd - command with the metrics-dir flag, progressUI - terminal UI, stage - install or setup, dryRun - disables recording
+// Returns: UI for the engine (the recorder, or progressUI when disabled) and the recorder (nil when disabled)
+func startMetrics(cmd *cobra.Command, progressUI ui.UI, stage string, dryRun bool) (ui.UI, *metrics.Recorder) {
+	if dryRun || metricsDir(cmd) == "" {
+		return progressUI, nil
+	}
+	rec := metrics.NewRecorder(progressUI)
+	rec.BeginStage(stage)
+	return rec, rec
+}
+
+// finishMetrics ends the stage and writes its textfile (no-op for a nil recorder)
+// Params: tasks - install's task results, which replace UI-call timings (nil for setup, timed through the recorder)
+// Edge cases: Write failures only warn; metrics never fail a run
+func finishMetrics(cmd *cobra.Command, progressUI ui.UI, rec *metrics.Recorder, tasks []installer.TaskResult, err error) {
+	if rec == nil {
+		return
+	}
+	if tasks != nil {
+		rec.SetTasks(taskMetrics(tasks))
+	}
+	rec.EndStage(err)
+	if _, werr := rec.WriteTextfile(metricsDir(cmd), version); werr != nil {
+		progressUI.Warning("⚠️  Failed to write metrics: %v", werr)
+	}
+}
+
+// taskMetrics converts install task results to metrics
+// Example: {Name: "jq", Outcome: "ok", Duration: 2s} → {Name: "jq", Result: "success", Duration: 2s}
+func taskMetrics(tasks []installer.TaskResult) []metrics.TaskMetric {
+	results := map[string]string{
+		installer.OutcomeOK:        metrics.ResultSuccess,
+		installer.OutcomeFailed:    metrics.ResultFailed,
+		installer.OutcomeSkipped:   metrics.ResultSkipped,
+		installer.OutcomeCancelled: metrics.ResultCancelled,
+	}
+	out := make([]metrics.TaskMetric, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, metrics.TaskMetric{Name: t.Name, Result: results[t.Outcome], Duration: t.Duration})
+	}
+	return out
+}
+
+// stageLabel describes a planned install's stage
+// Example: "stage 3, homebrew-casks, after 1" for a declared group that waits only for stage 1
+func stageLabel(s plan.Step) string {
+	label := fmt.Sprintf("stage %d", s.Stage)
+	if s.Group != "" {
+		label += ", " + s.Group
+	}
+	switch {
+	case !s.Independent || s.Stage == 1:
+	case len(s.After) == 0:
+		label += ", right away"
+	default:
+		after := make([]string, 0, len(s.After))
+		for _, stage := range s.After {
+			after = append(after, strconv.Itoa(stage))
+		}
+		label += ", after " + strings.Join(after, ", ")
+	}
+	return label
+}
+
+// setPromptTimeout applies --prompt-timeout
+// What: Exits on an invalid value, like the other flag checks
+// Params: cmd - command with the prompt-timeout flag, progressUI - UI for the error
+func setPromptTimeout(cmd *cobra.Command, progressUI ui.UI) {
+	value, _ := cmd.Flags().GetString("prompt-timeout")
+	timeout, err := prompt.ParseTimeout(value)
+	if err != nil {
+		progressUI.Error("❌ %v", err)
+		os.Exit(1)
+	}
+	prompt.SetTimeout(timeout)
+}
+
+// defaultPromptTimeout returns $DEVSETUP_PROMPT_TIMEOUT, or prompt.DefaultTimeout when unset
+func defaultPromptTimeout() string {
+	if value := os.Getenv("DEVSETUP_PROMPT_TIMEOUT"); value != "" {
+		return value
+	}
+	return prompt.DefaultTimeout.String()
+}
+
+// metricsDir returns the node_exporter textfile directory ("" = metrics disabled)
+func metricsDir(cmd *cobra.Command) string {
+	dir, _ := cmd.Flags().GetString("metrics-dir")
+	return dir
+}
+
+// loadHooksConfig loads configs/hooks.yaml
+// What: Exits on an invalid hooks file, like the other config loaders
+// Why: Shared by install, setup, and verify
+// Params: progressUI - UI for load errors
+// Returns: Hooks config
+func loadHooksConfig(progressUI ui.UI) *config.HooksConfig {
+	hooksConfig, err := config.LoadHooksConfig("configs/hooks.yaml")
+	if err != nil {
+		progressUI.Error("❌ Failed to load hooks config: %v", err)
+		os.Exit(1)
+	}
+	return hooksConfig
+}
+
+// applyTeamDefaults assigns team profiles and returns the team defaults
+// What: Looks up the user's GitHub teams and writes their profiles into configs.d (before configs are loaded)
+// Why: Shared by install and setup; status, verify, and serve then see the same overlays without calling GitHub
+// Params: progressUI - UI for output
+// Returns: Team defaults (default prompt answers), never nil
+// Edge cases: Without gh (first install) or a GitHub login, previously written team overlays are kept as-is
+func applyTeamDefaults(progressUI ui.UI) *config.TeamDefaults {
+	defaults, err := config.LoadTeamDefaults("configs/defaults.yaml")
+	if err != nil {
+		progressUI.Error("❌ Failed to load team defaults: %v", err)
+		os.Exit(1)
+	}
+	if len(defaults.Assignments) == 0 {
+		return defaults
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	teams, err := github.Teams(ctx)
+	if err != nil {
+		progressUI.Warning("⚠️  Team profiles not refreshed: %v", err)
+		return defaults
+	}
+
+	profiles := defaults.ProfilesFor(teams)
+	if _, err := defaults.WriteProfileOverlays(profiles); err != nil {
+		progressUI.Warning("⚠️  Failed to write team profiles: %v", err)
+		return defaults
+	}
+	if len(profiles) > 0 {
+		progressUI.Info("👥 Team profiles: %s", strings.Join(profiles, ", "))
+	}
+	return defaults
+}
+
+// reportOverrides mentions configs.d overlays applied to a loaded config
+// Why: A locally patched config should never be a surprise when debugging
+// Params: progressUI - UI for output, files - applied overlay files
+func reportOverrides(progressUI ui.UI, files []string) {
+	if len(files) > 0 {
+		progressUI.Info("📝 Local overrides applied: %s", strings.Join(files, ", "))
+	}
+}
+
+// setUpdatePrefs records update --skip and --snooze in state.json
+// Params: skip - version to stop announcing (""= unchanged), snooze - how long to pause the notice (""= unchanged, "0" = resume)
+func setUpdatePrefs(progressUI *ui.ProgressUI, skip, snooze string) {
+	var d time.Duration
+	if snooze != "" {
+		var err error
+		if d, err = config.ParseSnooze(snooze); err != nil {
+			progressUI.Error("❌ %v", err)
+			os.Exit(1)
+		}
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		progressUI.Error("❌ Failed to load state: %v", err)
+		os.Exit(1)
+	}
+
+	if skip != "" {
+		config.SkipVersion(state, skip)
+		progressUI.Success("✅ Skipping %s; later releases are still announced", skip)
+	}
+	switch {
+	case snooze == "":
+	case d == 0:
+		config.SnoozeUpdates(state, time.Time{})
+		progressUI.Success("✅ Update notices resumed")
+	default:
+		until := time.Now().Add(d)
+		config.SnoozeUpdates(state, until)
+		progressUI.Success("✅ Update notices snoozed until %s", until.Format("2006-01-02 15:04"))
+	}
+
+	if err := config.SaveState(state); err != nil {
+		progressUI.Error("❌ Failed to save state: %v", err)
+		os.Exit(1)
+	}
+}
+
+// notifyUpdate mentions a newer devsetup release after a command finishes
+// What: Asks GitHub at most once a day (cached in state.json) and prints one line to stderr unless the release is skipped or notices are snoozed
+// Why: Fixes reach people who never run update --check, without nagging those who chose to wait
+// Edge cases: Silent for unstamped builds, CI, piped stderr, DEVSETUP_NO_UPDATE_CHECK, and when GitHub can't be reached
+func notifyUpdate(cmd *cobra.Command) {
+	switch cmd.Name() {
+	case "update", "changelog", "serve", "completion", "help", "__complete":
+		return
+	}
+	current, _, _ := strings.Cut(version, "+")
+	if !strings.HasPrefix(current, "v") || os.Getenv("DEVSETUP_NO_UPDATE_CHECK") != "" || os.Getenv("CI") != "" {
+		return
+	}
+	if info, err := os.Stderr.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if config.UpdateCheckDue(state, now) {
+		upd := updater.NewUpdater(version)
+		upd.SetTimeout(3 * time.Second)
+		release, err := upd.CheckForUpdate()
+		if err != nil {
+			return // Offline: ask again after the next command
+		}
+		latest := ""
+		if release != nil {
+			latest = release.TagName
+		}
+		config.RecordUpdateCheck(state, latest, now)
+		_ = config.SaveState(state)
+	}
+
+	latest := state.Updates.Latest
+	if !updater.IsNewerVersion(latest, current) || !config.UpdateNoticeDue(state, latest, now) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\n💡 devsetup %s is available (you have %s). Run 'devsetup update', or quiet this with 'devsetup update --skip %s' or '--snooze 7d'\n", latest, current, latest)
+}
+
+// releaseNotesLines is how many lines of release notes update and changelog show per release
+const releaseNotesLines = 15
+
+// printReleaseNotes prints a release's rendered notes, cut at maxLines (0 = all)
+// Why: Shows what an update brings before it's installed
+func printReleaseNotes(progressUI *ui.ProgressUI, release *updater.ReleaseInfo, maxLines int) {
+	fmt.Println()
+	fmt.Print(updater.RenderReleaseNotes(release, maxLines, progressUI.Interactive()))
+	fmt.Println()
+}
+
+// printRelease prints a release heading ("v1.4.0 — 2026-03-02") and its notes
+func printRelease(progressUI *ui.ProgressUI, release *updater.ReleaseInfo, maxLines int) {
+	heading := release.TagName
+	if release.Name != "" && release.Name != release.TagName {
+		heading += " (" + release.Name + ")"
+	}
+	if !release.CreatedAt.IsZero() {
+		heading += " — " + release.CreatedAt.Format("2006-01-02")
+	}
+	progressUI.Info("📦 %s", heading)
+	printReleaseNotes(progressUI, release, maxLines)
+}
+
+// warnConfigDrift warns when a configs/ checkout has drifted from the configs built into the binary
+// Why: The checkout wins over the embedded configs, so a stale binary or checkout changes what gets installed
+func warnConfigDrift(progressUI ui.UI) {
+	drift, err := config.DetectConfigDrift("configs")
+	if err != nil || !drift.Significant() {
+		return
+	}
+	checkout, built := drift.Commit, drift.EmbeddedCommit
+	if checkout == "" {
+		checkout = "not a git checkout"
+	}
+	if built == "" {
+		built = "unstamped build"
+	}
+	progressUI.Warning("⚠️  configs/ (%s) differs from the configs built into devsetup (%s): %s", checkout, built, drift)
+	progressUI.Warning("   Update devsetup (devsetup update) or check out the matching ref of the config repo")
+}
+
+// openCheckCache opens the tool check result cache
+// What: Honors check_cache_ttl from tools.yaml; --no-cache disables it for this run
+// Why: Shared by install, verify, and status
+// Params: cmd - command with a --no-cache flag, toolsConfig - loaded tools config
+// Returns: Cache, or nil when caching is disabled
+func openCheckCache(cmd *cobra.Command, toolsConfig *config.ToolsConfig) *checkcache.Cache {
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+		return nil
+	}
+	return checkcache.Open(toolsConfig.CheckCacheTTL())
+}
+
+// writeExportFiles writes generated files into a directory
+// What: Creates dir, refuses to overwrite existing files unless force
+// Params: dir - output directory, files - name → content, force - overwrite existing files
+// Returns: Error naming the first file that exists (without force) or can't be written
+func writeExportFiles(dir string, files map[string]string, force bool) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !force {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", filepath.Join(dir, name))
+			}
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(files[name]), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSync runs a sync push or pull against the configured remote and reports the files changed
+// Params: fn - configsync.Push or configsync.Pull, verb - past tense for the summary ("Pushed")
+func runSync(fn func(context.Context, *configsync.Remote) (*configsync.Result, error), verb string) {
+	progressUI := ui.NewProgressUI()
+
+	remote, err := configsync.LoadRemote()
+	if err != nil {
+		progressUI.Error("❌ %v", err)
+		os.Exit(1)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	res, err := fn(ctx, remote)
+	if err != nil {
+		progressUI.Error("❌ Sync failed: %v", err)
+		os.Exit(1)
+	}
+	if !res.Changed() {
+		progressUI.Success("✅ Already in sync")
+		return
+	}
+	for _, file := range res.Written {
+		progressUI.Info("  ↻ %s", file)
+	}
+	for _, file := range res.Removed {
+		progressUI.Info("  - %s", file)
+	}
+	progressUI.Success("✅ %s %d change(s)", verb, len(res.Written)+len(res.Removed))
+}
+
+// serviceTools returns the tools in tools.yaml that declare a service
+// What: All service tools, or only the named ones; exits on unknown names or tools without a service
+// Params: progressUI - UI for errors, names - tool names to select (empty = all)
+// Returns: Selected tools in tools.yaml order
+func serviceTools(progressUI ui.UI, names []string) []config.Tool {
+	toolsConfig, err := config.LoadToolsConfig("configs/tools.yaml")
+	if err != nil {
+		progressUI.Error("❌ Failed to load tools config: %v", err)
+		os.Exit(1)
+	}
+
+	byName := make(map[string]config.Tool)
+	var tools []config.Tool
+	for _, tool := range toolsConfig.Tools {
+		if tool.Service != nil {
+			byName[tool.Name] = tool
+			tools = append(tools, tool)
+		}
+	}
+	if len(names) == 0 {
+		return tools
+	}
+
+	var selected []config.Tool
+	for _, name := range names {
+		tool, ok := byName[name]
+		if !ok {
+			progressUI.Error("❌ %s declares no service in tools.yaml", name)
+			os.Exit(1)
+		}
+		selected = append(selected, tool)
+	}
+	return selected
+}
+
+// uninstallTools uninstalls tools by name
+// What: Looks each name up in tools.yaml and runs its uninstall, reporting every result
+// Why: Shared by uninstall and generations rollback --uninstall
+// Params: cmd - command with a --no-cache flag, progressUI - UI for output, toolsConfig - tools to look names up in, names - tools to remove, force - ignore installed dependents, dryRun - only print commands
+// Returns: False if any tool is unknown or failed to uninstall
+// Edge cases: Tools not recorded as installed are skipped, so rerunning after a partial failure is safe
+func uninstallTools(cmd *cobra.Command, progressUI ui.UI, toolsConfig *config.ToolsConfig, names []string, force, dryRun bool) bool {
+	state, err := config.LoadState()
+	if err != nil {
+		progressUI.Error("❌ Failed to load state: %v", err)
+		return false
+	}
+
+	byName := make(map[string]config.Tool)
+	for _, tool := range toolsConfig.Tools {
+		byName[tool.Name] = tool
+	}
+
+	toolInstaller := installer.NewToolInstaller(toolsConfig, state, progressUI, dryRun, version)
+	toolInstaller.SetCheckCache(openCheckCache(cmd, toolsConfig))
+
+	ok := true
+	for _, name := range names {
+		tool, found := byName[name]
+		switch {
+		case !found:
+			progressUI.Error("❌ %s is not in tools.yaml", name)
+			ok = false
+			continue
+		case !config.IsToolInstalled(state, name):
+			progressUI.Info("⏭️  %s is not installed", name)
+			continue
+		}
+
+		progressUI.Info("🗑️  Uninstalling %s", name)
+		timeout := tool.Install.Timeout
+		if timeout == 0 {
+			timeout = 5 * time.Minute
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := toolInstaller.Uninstall(ctx, tool, force)
+		cancel()
+		switch {
+		case err != nil:
+			progressUI.Error("❌ %s: %v", name, err)
+			ok = false
+		case !dryRun:
+			progressUI.Success("✅ Uninstalled %s", name)
+		}
+	}
+	return ok
+}
+
+// waitForInstall handles an install that is already running before this one starts
+// What: Without --wait, exits with a hint; with --wait, follows the running install live until it ends
+// Why: Two installs at once race on state.json and on brew's own lock
+// Params: cmd - command (reads its --wait flag, if any), progressUI - UI for progress and errors
+func waitForInstall(cmd *cobra.Command, progressUI ui.UI) {
+	run, err := runlog.Current()
+	if err != nil || !run.Running() {
+		return
+	}
+	if wait, _ := cmd.Flags().GetBool("wait"); !wait {
+		progressUI.Error("❌ %v", &runlog.BusyError{Run: run})
+		progressUI.Info("Run 'devsetup wait' to follow it, or use --wait to start after it")
+		os.Exit(1)
+	}
+	final, err := followRun(context.Background(), progressUI, run)
+	reportRun(progressUI, final, err)
+	progressUI.Info("")
+}
+
+// followRun shows a running install's progress until it ends
+// Params: ctx - stops following, progressUI - UI events are rendered on, run - running install
+// Returns: The finished run and Follow's error
+func followRun(ctx context.Context, progressUI ui.UI, run *runlog.Run) (*runlog.Run, error) {
+	progressUI.Info("⏳ Waiting for %s started at %s (pid %d)", run.Command, run.Started.Local().Format("15:04:05"), run.PID)
+	progressUI.Info("")
+	return runlog.Follow(ctx, run, func(event ui.Event) {
+		runlog.Render(progressUI, event)
+	})
+}
+
+// reportRun prints how an install ended
+// Params: progressUI - UI for output, run - finished (or abandoned) run, err - error from following it
+// Returns: True if the install succeeded
+func reportRun(progressUI ui.UI, run *runlog.Run, err error) bool {
+	switch {
+	case err != nil:
+		progressUI.Error("❌ %v", err)
+		return false
+	case run.Finished.IsZero():
+		progressUI.Error("❌ The last install (%s, pid %d) exited without finishing", run.Command, run.PID)
+		return false
+	case !run.OK:
+		progressUI.Error("❌ The %s started at %s failed: %s", run.Command, run.Started.Local().Format("15:04:05"), run.Error)
+		for _, task := range run.Tasks {
+			if task.Outcome != installer.OutcomeFailed {
+				continue
+			}
+			if task.Owner != "" {
+				progressUI.Info("  ✗ %s: %s — ask %s", task.Name, task.Error, task.Owner)
+			} else {
+				progressUI.Info("  ✗ %s: %s", task.Name, task.Error)
+			}
+		}
+		return false
+	}
+	progressUI.Success("✅ The %s started at %s finished in %s", run.Command, run.Started.Local().Format("15:04:05"), run.Finished.Sub(run.Started).Round(time.Second))
+	return true
+}
+
+// printOwners names who to ask about the tools and tasks that failed
+// Why: Internal tools are maintained by their own teams; users shouldn't default to asking the platform team
+// Params: progressUI - output, failures - this run's failures (Failures of the installer or setup executor)
+// Edge cases: Prints nothing when no failed tool or task declares an owner
+func printOwners(progressUI ui.UI, failures []support.Failure) {
+	contacts := support.Contacts(failures)
+	if len(contacts) == 0 {
+		return
+	}
+	progressUI.Info("Who to ask:")
+	for _, line := range contacts {
+		progressUI.Info("  %s", line)
+	}
+}
+
+// suggestWithoutBrew points at --without-brew when Homebrew itself failed to install
+// Why: Everything else waits on Homebrew; the direct installs get a developer coding in the meantime
+// Params: progressUI - output, toolsConfig - loaded tools config, failures - the installer's failures
+func suggestWithoutBrew(progressUI ui.UI, toolsConfig *config.ToolsConfig, failures []support.Failure) {
+	for _, failure := range failures {
+		for _, tool := range toolsConfig.Tools {
+			if tool.Name == failure.Name && tool.Install.IsScript() && tool.Install.Script.IsHomebrew() {
+				progressUI.Info("Homebrew blocked? 'devsetup install --without-brew' installs git, node, and the editor directly")
+				return
+			}
+		}
+	}
+}
+
+// beginRun records an install so 'devsetup wait' can follow it
+// Params: progressUI - UI for errors, command - what is installing, inner - UI the installer would use
+// Returns: UI to give the installer and the recorder to End (nil when recording failed, which only warns)
+// Edge cases: Exits when another install started since waitForInstall checked
+func beginRun(progressUI ui.UI, command string, inner ui.UI) (ui.UI, *runlog.Recorder) {
+	rec, err := runlog.Begin(command, inner)
+	var busy *runlog.BusyError
+	switch {
+	case errors.As(err, &busy):
+		progressUI.Error("❌ %v", err)
+		os.Exit(1)
+	case err != nil:
+		progressUI.Warning("⚠️  'devsetup wait' won't see this install: %v", err)
+		return inner, nil
+	}
+	return rec, rec
+}
+
+// loadExceptions loads the per-user verify exceptions file
+// What: Exits on a malformed file so a typo can't silently turn exceptions off
+// Why: Shared by verify, status, and verify --report-url
+// Params: progressUI - UI for load errors
+// Returns: Exceptions (empty when the file doesn't exist)
+func loadExceptions(progressUI ui.UI) *config.Exceptions {
+	exceptions, err := config.LoadExceptions()
+	if err != nil {
+		progressUI.Error("❌ Failed to load exceptions: %v", err)
+		os.Exit(1)
+	}
+	return exceptions
+}
+
+// pushVerifyReport sends verify results to a team dashboard
+// What: Builds a fleet report (machine, status snapshot, verify JSON) and POSTs it
+// Why: Shared by verify --report-url; serve uses fleet.Server.ReportPeriodically
+// Params: url - dashboard endpoint, toolsConfig/setupConfig/state/exceptions - loaded configs and state, result - verify result
+// Returns: Error if the report can't be built or sent
+func pushVerifyReport(url string, toolsConfig *config.ToolsConfig, setupConfig *config.SetupConfig, state *config.State, exceptions *config.Exceptions, result *verify.VerifyResult) error {
+	reporter := status.NewReporter(toolsConfig, setupConfig, state, nil)
+	reporter.SetExceptions(exceptions)
+	report, err := fleet.NewReport(version, reporter.Snapshot(), result)
+	if err != nil {
+		return err
+	}
+	return fleet.Push(context.Background(), url, os.Getenv("DEVSETUP_REPORT_TOKEN"), report)
+}
+
+// postStageFilter narrows an install to the stages the tools.yaml post_stage section lets run now
+// What: A --deferred run gets the deferred stages; a plain install before defer_until gets the ones before them
+// Why: Polish installs wait for the quiet time instead of competing with the developer's first-day work
+// Params: progressUI - output, toolsConfig - loaded tools config, filter - flag filter, deferredRun/runNow - flags
+// Returns: Filter to install with, and the time to schedule the deferred stages for (zero = nothing deferred)
+// Edge cases: Filtered runs (--stages, --skip-tasks, --only-group) are never deferred; --deferred with nothing
+// deferred removes a stale agent and exits
+func postStageFilter(progressUI ui.UI, toolsConfig *config.ToolsConfig, filter *config.RunFilter, deferredRun, runNow bool) (*config.RunFilter, time.Time) {
+	post := toolsConfig.PostStage
+	after := postStageAfter(toolsConfig)
+	if deferredRun {
+		if after == "" {
+			progressUI.Info("Nothing deferred: tools.yaml has no post_stage stages")
+			if err := deferred.Cancel(context.Background()); err != nil {
+				progressUI.Warning("⚠️  %v", err)
+			}
+			os.Exit(0)
+		}
+		progressUI.Info("🌙 Installing the deferred stages %s", after)
+		return stageFilter(progressUI, after, filter), time.Time{}
+	}
+	if after == "" || runNow || !filter.Empty() {
+		return filter, time.Time{}
+	}
+	until, wait := post.Deferred(time.Now())
+	if !wait {
+		return filter, time.Time{}
+	}
+	progressUI.Info("🌙 Stages %s wait until %s (post_stage); --now installs them now", after, until.Format("15:04"))
+	progressUI.Info("")
+	return stageFilter(progressUI, post.Before(), filter), until
+}
+
+// postStageAfter returns the --stages value of the post_stage stages (empty when none are deferred)
+func postStageAfter(toolsConfig *config.ToolsConfig) string {
+	if toolsConfig.PostStage == nil {
+		return ""
+	}
+	stages, err := installer.Stages(toolsConfig)
+	if err != nil {
+		return ""
+	}
+	return toolsConfig.PostStage.After(len(stages))
+}
+
+// stageFilter returns filter with its stages replaced
+func stageFilter(progressUI ui.UI, stages string, filter *config.RunFilter) *config.RunFilter {
+	narrowed, err := config.NewRunFilter(stages, "", "")
+	if err != nil {
+		progressUI.Error("❌ Invalid filter: %v", err)
+		os.Exit(1)
+	}
+	if filter != nil {
+		narrowed.SkipTasks, narrowed.OnlyGroups = filter.SkipTasks, filter.OnlyGroups
+	}
+	return narrowed
+}
+
+// finishPostStage schedules the deferred stages after an install held them back, or removes the agent once they ran
+// Params: progressUI - output, toolsConfig - loaded tools config, until - postStageFilter's time, ranDeferred -
+// whether this install ran the post_stage stages (--deferred or a full install)
+// Edge cases: Scheduling failures only warn, with the command to run by hand
+func finishPostStage(progressUI ui.UI, toolsConfig *config.ToolsConfig, until time.Time, ranDeferred bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if until.IsZero() {
+		if ranDeferred && deferred.Pending() {
+			if err := deferred.Cancel(ctx); err != nil {
+				progressUI.Warning("⚠️  %v", err)
+			}
+		}
+		return
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		var dir string
+		if dir, err = os.Getwd(); err == nil {
+			err = deferred.Schedule(ctx, deferred.Job{Executable: exe, Dir: dir, At: until, Path: os.Getenv("PATH")})
+		}
+	}
+	if err != nil {
+		progressUI.Warning("⚠️  Couldn't schedule the deferred stages: %v", err)
+		progressUI.Info("Run 'devsetup install --deferred' after %s to install them", until.Format("15:04"))
+		return
+	}
+	progressUI.Info("🌙 Stages %s will install at %s (log: %s); 'devsetup install --now' installs them now",
+		postStageAfter(toolsConfig), until.Format("15:04"), deferred.LogPath())
+}
+
+func main() {
+	// Add flags
+	rootCmd.PersistentFlags().Bool("verbose", false, "Stream every command's output (by default a failed task shows only its relevant lines and log)")
+	installCmd.Flags().Bool("dry-run", false, "Show what would be installed without installing")
+	installCmd.Flags().Bool("inspect-scripts", false, "Show each remote installer script and confirm before running it")
+	installCmd.Flags().String("stages", "", "Comma-separated install stages to run (e.g. 1,2)")
+	installCmd.Flags().String("skip-tasks", "", "Comma-separated tools to leave out")
+	installCmd.Flags().String("only-group", "", "Comma-separated parallel groups to run (e.g. homebrew-cli)")
+	installCmd.Flags().Bool("from-peers", false, "First copy Homebrew downloads from a peer running 'devsetup share serve'")
+	installCmd.Flags().Bool("without-brew", false, "Install tools with a direct: install without Homebrew and defer the rest")
+	installCmd.Flags().Bool("force", false, "Rerun every tool instead of resuming after the ones an interrupted install finished")
+	installCmd.Flags().Bool("now", false, "Install the post_stage stages now instead of at defer_until")
+	installCmd.Flags().Bool("deferred", false, "Install only the post_stage stages (run by the deferred-install LaunchAgent)")
+	setupCmd.Flags().Bool("dry-run", false, "Show what would be configured without configuring")
+	setupCmd.Flags().String("skip-tasks", "", "Comma-separated setup tasks to leave out")
+	installCmd.Flags().Bool("yes", false, "Install Rosetta 2 and quit apps blocking their upgrade without asking")
+	setupCmd.Flags().Bool("yes", false, "Apply .zshrc/.zprofile changes without asking (diffs are still shown)")
+	applyCmd.Flags().Bool("yes", false, "Install Rosetta 2, quit apps blocking their upgrade, and apply .zshrc/.zprofile changes without asking (diffs are still shown)")
+	dotfilesRestoreCmd.Flags().String("at", "", "Backup timestamp to restore (YYYYMMDD-HHMMSS, default: newest)")
+	updateCmd.Flags().Bool("check", false, "Check for updates without installing")
+	updateCmd.Flags().String("skip", "", "Stop announcing this version (e.g. v0.6.0); later releases are still announced")
+	updateCmd.Flags().String("snooze", "", "Pause new-version notices for a while (7d, 2w, 12h; 0 resumes them)")
+	changelogCmd.Flags().Int("limit", 5, "Number of recent releases to show")
+	verifyCmd.Flags().Bool("quick", false, "Only check state and file existence (fast, no shell commands)")
+	verifyCmd.Flags().Bool("deep", false, "Also check versions, checksums, submodule commits, and config drift")
+	verifyCmd.Flags().String("format", "text", "Output format: text, table, json, junit")
+	verifyCmd.Flags().Bool("policy", false, "Also check organization compliance policies")
+	for _, c := range []*cobra.Command{installCmd, verifyCmd, statusCmd, planCmd} {
+		c.Flags().Bool("no-cache", false, "Run every tool check instead of reusing recent results")
+	}
+	for _, c := range []*cobra.Command{installCmd, setupCmd, applyCmd} {
+		c.Flags().String("metrics-dir", os.Getenv("DEVSETUP_METRICS_DIR"), "Write run metrics for node_exporter's textfile collector to this directory")
+		c.Flags().String("prompt-timeout", defaultPromptTimeout(), "How long a prompt waits before taking its default (0 waits forever; default $DEVSETUP_PROMPT_TIMEOUT)")
+	}
+	verifyCmd.Flags().String("report-url", os.Getenv("DEVSETUP_REPORT_URL"), "Push results to a team dashboard URL (token via DEVSETUP_REPORT_TOKEN)")
+	statusCmd.Flags().Bool("menubar", false, "Print status in xbar/SwiftBar plugin format")
+	statusCmd.Flags().String("menubar-plugin", "", "Install the xbar/SwiftBar plugin script into this directory")
+	doctorCmd.Flags().Bool("fix", false, "Offer guided fixes for detected issues")
+	auditCmd.Flags().Bool("fetch", false, "Download each script and show its current SHA256")
+	configLintCmd.Flags().StringSlice("disable", nil, "Rules to skip (comma-separated)")
+	configRenderCmd.Flags().String("vars", "", "vars.yaml describing the machine (facts, profiles, answers, env)")
+	serveCmd.Flags().String("addr", fleet.DefaultAddr, "Listen address")
+	serveCmd.Flags().String("report-url", os.Getenv("DEVSETUP_REPORT_URL"), "Also push quick verify results to this team dashboard URL")
+	serveCmd.Flags().Duration("report-interval", time.Hour, "Time between dashboard reports")
+	reconcileCmd.Flags().Bool("list", false, "Only report differences; don't prompt")
+	serveCmd.Flags().Bool("api", false, "Enable authenticated install/verify/doctor API under /api/v1/")
+	planCmd.Flags().String("out", "", "Save the plan to this file for 'devsetup apply --plan'")
+	applyCmd.Flags().String("plan", "", "Plan file written by 'devsetup plan --out'")
+	planApproveCmd.Flags().String("key", "", "SSH key to sign with: private key, or public key held in ssh-agent (default ~/.ssh/id_ed25519)")
+	_ = applyCmd.MarkFlagRequired("plan")
+	shareServeCmd.Flags().Int("port", share.DefaultPort, "Port to serve downloads on")
+	reportIssueCmd.Flags().String("repo", updater.GitHubOwner+"/"+updater.GitHubRepo, "GitHub repository (owner/name) to file the issue in")
+	reportIssueCmd.Flags().Bool("no-bundle", false, "Don't write a support bundle")
+	reportIssueCmd.Flags().Bool("print-url", false, "Print the pre-filled issue URL instead of opening it")
+	containerizeCmd.Flags().String("out", ".devcontainer", "Directory to write Dockerfile and devcontainer.json to")
+	containerizeCmd.Flags().Bool("force", false, "Overwrite existing files")
+	exportCmd.Flags().String("format", "", "Export format: nix, brewbundle, or checklist")
+	exportCmd.Flags().String("out", ".", "Directory to write Brewfile or flake.nix to")
+	exportCmd.Flags().Bool("force", false, "Overwrite an existing file")
+	_ = exportCmd.MarkFlagRequired("format")
+	pinOverrideCmd.Flags().String("until", "", "Last day the override applies (YYYY-MM-DD)")
+	pinOverrideCmd.Flags().String("reason", "", "Why the deviation is needed (shown by verify)")
+	pinOverrideCmd.Flags().String("version", "", "Sanctioned version (default: the installed version)")
+	_ = pinOverrideCmd.MarkFlagRequired("until")
+	_ = pinOverrideCmd.MarkFlagRequired("reason")
+	supportBundleCmd.Flags().String("out", "", "Zip file to write (default devsetup-support-<timestamp>.zip)")
+	installCmd.Flags().Bool("wait", false, "If another install is running, show its progress and start after it ends")
+	waitCmd.Flags().Duration("timeout", 0, "Give up (exit 1) if the install is still running after this long (0 = no limit)")
+	uninstallCmd.Flags().Bool("force", false, "Uninstall even if installed tools depend on it")
+	uninstallCmd.Flags().Bool("dry-run", false, "Show the uninstall commands without running them")
+	uninstallCmd.Flags().Bool("deprecated", false, "Uninstall every installed tool deprecated in tools.yaml")
+	generationsRollbackCmd.Flags().Bool("uninstall", false, "Also uninstall tools added since the generation")
+	driftHistoryCmd.Flags().Duration("since", 90*24*time.Hour, "How far back to read the history")
+	driftHistoryCmd.Flags().String("format", "text", "Output format: text or json")
+	sharePullCmd.Flags().String("peer", "", "Peer URL (e.g. http://alices-mbp.local:7781); discovered via Bonjour if empty")
+
+	// Add commands
+	rootCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(setupCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(updateCmd)
+	rootCmd.AddCommand(changelogCmd)
+	rootCmd.AddCommand(uninstallCmd)
+	rootCmd.AddCommand(waitCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(serveCmd)
+	generationsCmd.AddCommand(generationsListCmd, generationsDiffCmd, generationsRollbackCmd)
+	rootCmd.AddCommand(generationsCmd)
+	driftCmd.AddCommand(driftHistoryCmd)
+	rootCmd.AddCommand(driftCmd)
+	rootCmd.AddCommand(reconcileCmd)
+	planCmd.AddCommand(planApproveCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(applyCmd)
+	shareCmd.AddCommand(shareServeCmd, sharePullCmd)
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(containerizeCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(pathCmd)
+	dotfilesCmd.AddCommand(dotfilesRestoreCmd)
+	rootCmd.AddCommand(dotfilesCmd)
+	manualCmd.AddCommand(manualDoneCmd)
+	rootCmd.AddCommand(manualCmd)
+	rootCmd.AddCommand(rolloutCmd)
+	configCmd.AddCommand(configLintCmd, configRenderCmd)
+	rootCmd.AddCommand(configCmd)
+	syncCmd.AddCommand(syncInitCmd, syncPushCmd, syncPullCmd)
+	rootCmd.AddCommand(syncCmd)
+	servicesCmd.AddCommand(servicesStartCmd, servicesStopCmd)
+	rootCmd.AddCommand(servicesCmd)
+	pinCmd.AddCommand(pinOverrideCmd)
+	rootCmd.AddCommand(pinCmd)
+	selfCmd.AddCommand(selfCheckCmd)
+	rootCmd.AddCommand(selfCmd)
+	supportCmd.AddCommand(supportBundleCmd)
+	rootCmd.AddCommand(supportCmd)
+	rootCmd.AddCommand(reportIssueCmd)
+
+	// Release source: configs/releases.yaml (GitHub unless a mirror is configured)
+	if rc, err := config.LoadReleasesConfig("configs/releases.yaml"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using GitHub releases\n", err)
+	} else {
+		updater.SetSource(rc)
+	}
+
+	// Output language: DEVSETUP_LANG, ~/.config/devsetup/locale.yaml, or the system locale
+	if err := i18n.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using English\n", err)
+	}
 
 	// Execute
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,120 @@
+// File: internal/aitools/aitools.go
+// Purpose: Credentials and standard settings for AI coding assistant CLIs (Claude Code, GitHub Copilot CLI)
+// Problem: API keys were exported in ~/.zshrc in plain text, and nothing told a user their key had been revoked
+//          until the CLI failed mid-task
+// Role: Knows where each CLI keeps its settings, renders the settings and shell lines that read the key from the
+//       keychain, and checks a key against the service without printing it
+// Usage: err := aitools.CheckKey(ctx, config.AIClaude, key); settings := aitools.ManagedSettings(ai)
+// Design choices: Claude reads its key through settings.json's apiKeyHelper (a keychain read), Copilot through an
+//                 env export evaluated by the shell, so no key is ever written to a file; checks hit a read-only
+//                 endpoint (list models, current user) and report only the HTTP status
+// Assumptions: Copilot CLI takes a fine-grained token with the Copilot Requests permission from COPILOT_GITHUB_TOKEN
+
+package aitools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/keychain"
+)
+
+// ErrUnauthorized is returned by CheckKey when the service rejects the key
+var ErrUnauthorized = errors.New("the key was rejected")
+
+// API endpoints the keys are checked against (variables so tests can point them at a local server)
+var (
+	anthropicAPI = "https://api.anthropic.com"
+	githubAPI    = "https://api.github.com"
+)
+
+// SettingsPath returns a tool's settings file
+// Returns: ~/.claude/settings.json for claude, ~/.copilot/config.json for copilot
+func SettingsPath(tool string) string {
+	home, _ := os.UserHomeDir()
+	if tool == config.AICopilot {
+		return filepath.Join(home, ".copilot", "config.json")
+	}
+	return filepath.Join(home, ".claude", "settings.json")
+}
+
+// ManagedSettings returns the settings devsetup manages in SettingsPath
+// What: The task's settings, plus apiKeyHelper reading the key from the keychain for claude
+func ManagedSettings(ai *config.AIAssistantConfig) map[string]any {
+	settings := maps.Clone(ai.Settings)
+	if ai.Tool == config.AIClaude {
+		if settings == nil {
+			settings = map[string]any{}
+		}
+		settings["apiKeyHelper"] = keychain.ReadCommand(ai.KeychainService())
+	}
+	return settings
+}
+
+// BlockName names the ~/.zshrc block holding a tool's env exports (see dotfiles.ReplaceBlock)
+func BlockName(ai *config.AIAssistantConfig) string {
+	return "ai_" + ai.Tool
+}
+
+// EnvLines returns the ~/.zshrc exports a tool needs (none for claude, which uses apiKeyHelper)
+// Example: copilot → export COPILOT_GITHUB_TOKEN="$(security find-generic-password -s 'devsetup.copilot-api-key' -w 2>/dev/null)"
+func EnvLines(ai *config.AIAssistantConfig) []string {
+	if ai.Tool == config.AICopilot {
+		return []string{`export COPILOT_GITHUB_TOKEN="` + keychain.ShellRead(ai.KeychainService()) + `"`}
+	}
+	return nil
+}
+
+// CheckKey checks the service accepts a key
+// What: claude - GET /v1/models on the Anthropic API; copilot - GET /user on the GitHub API
+// Params: ctx - bounds the request, tool - claude or copilot, key - API key or token
+// Returns: nil when accepted, ErrUnauthorized on 401/403, other errors otherwise (never containing the key)
+func CheckKey(ctx context.Context, tool, key string) error {
+	var req *http.Request
+	var err error
+	switch tool {
+	case config.AIClaude:
+		if req, err = http.NewRequestWithContext(ctx, http.MethodGet, anthropicAPI+"/v1/models?limit=1", nil); err != nil {
+			return err
+		}
+		req.Header.Set("x-api-key", key)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	case config.AICopilot:
+		if req, err = http.NewRequestWithContext(ctx, http.MethodGet, githubAPI+"/user", nil); err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	default:
+		return fmt.Errorf("unknown AI tool %q", tool)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s unreachable: %w", req.URL.Host, err)
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// Check checks the key stored in the keychain
+// Returns: Error if the key is missing from the keychain or CheckKey fails
+func Check(ctx context.Context, ai *config.AIAssistantConfig) error {
+	key, err := keychain.Get(ai.KeychainService())
+	if err != nil {
+		return fmt.Errorf("%s key: %w", ai.Tool, err)
+	}
+	return CheckKey(ctx, ai.Tool, key)
+}
@@ -0,0 +1,71 @@
+package aitools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestCheckKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/models" && r.Header.Get("x-api-key") == "sk-good" && r.Header.Get("anthropic-version") != "":
+		case r.URL.Path == "/user" && r.Header.Get("Authorization") == "Bearer ghp-good":
+		case r.URL.Path == "/user" && r.Header.Get("Authorization") == "Bearer ghp-down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+	anthropicAPI, githubAPI = srv.URL, srv.URL
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		tool, key string
+		want      error
+	}{
+		{config.AIClaude, "sk-good", nil},
+		{config.AIClaude, "sk-revoked", ErrUnauthorized},
+		{config.AICopilot, "ghp-good", nil},
+		{config.AICopilot, "sk-good", ErrUnauthorized},
+	} {
+		if err := CheckKey(ctx, tt.tool, tt.key); !errors.Is(err, tt.want) {
+			t.Errorf("CheckKey(%s, %s) = %v, want %v", tt.tool, tt.key, err, tt.want)
+		}
+	}
+	if err := CheckKey(ctx, config.AICopilot, "ghp-down"); err == nil || errors.Is(err, ErrUnauthorized) {
+		t.Errorf("a server error isn't a rejected key, got %v", err)
+	}
+}
+
+func TestManagedSettings(t *testing.T) {
+	team := map[string]any{"cleanupPeriodDays": 30}
+	claude := &config.AIAssistantConfig{Tool: config.AIClaude, Settings: team}
+	want := map[string]any{
+		"cleanupPeriodDays": 30,
+		"apiKeyHelper":      "security find-generic-password -s 'devsetup.claude-api-key' -w",
+	}
+	if got := ManagedSettings(claude); !reflect.DeepEqual(got, want) {
+		t.Errorf("ManagedSettings = %v, want %v", got, want)
+	}
+	if _, ok := team["apiKeyHelper"]; ok {
+		t.Error("ManagedSettings must not modify the task's settings")
+	}
+	if EnvLines(claude) != nil {
+		t.Error("claude reads its key through apiKeyHelper, not the environment")
+	}
+
+	copilot := &config.AIAssistantConfig{Tool: config.AICopilot, Keychain: "corp.copilot"}
+	if got := ManagedSettings(copilot); len(got) != 0 {
+		t.Errorf("copilot without settings manages nothing, got %v", got)
+	}
+	if got := EnvLines(copilot); len(got) != 1 || got[0] != `export COPILOT_GITHUB_TOKEN="$(security find-generic-password -s 'corp.copilot' -w 2>/dev/null)"` {
+		t.Errorf("EnvLines = %v", got)
+	}
+}
@@ -0,0 +1,246 @@
+// File: internal/api/api.go
+// Purpose: Local HTTP API for driving devsetup programmatically
+// Problem: Internal tooling (onboarding portal, IT scripts) had to scrape CLI output to run installs and read results
+// Role: Exposes install, verify, doctor, and status as authenticated endpoints with streamed progress
+// Usage: srv := api.NewServer(toolsCfg, setupCfg, policies, version, token); fleetServer.Mount("/api/", srv.Handler())
+// Design choices: REST + NDJSON progress stream (one ui.Event per line, final "done" event carries the result);
+//                 one operation at a time (409 when busy); bearer token required because a localhost
+//                 port is reachable from any local process and browser page
+// Assumptions: Interactive setup (prompts) stays CLI-only; command output of installers still goes to the server's stdout
+
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/doctor"
+	"github.com/rkinnovate/dev-setup/internal/hooks"
+	"github.com/rkinnovate/dev-setup/internal/installer"
+	"github.com/rkinnovate/dev-setup/internal/runlog"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+	"github.com/rkinnovate/dev-setup/internal/verify"
+)
+
+// Prefix is the URL prefix all API routes live under
+const Prefix = "/api/v1/"
+
+// operation runs one API operation, reporting progress through eventUI
+// Returns: Result payload for the final event and the operation error
+type operation func(r *http.Request, eventUI ui.UI) (interface{}, error)
+
+// Server handles API requests
+type Server struct {
+	toolsConfig *config.ToolsConfig
+	setupConfig *config.SetupConfig
+	policies    *config.PolicyConfig
+	version     string
+	token       string
+	hooks       *config.HooksConfig
+	busy        sync.Mutex
+	status      http.Handler
+}
+
+// NewServer creates an API server
+// Params: toolsConfig, setupConfig - loaded configs, policies - policy config (may be nil), version - devsetup version, token - bearer token clients must send
+// Returns: Server
+func NewServer(toolsConfig *config.ToolsConfig, setupConfig *config.SetupConfig, policies *config.PolicyConfig, version, token string) *Server {
+	return &Server{
+		toolsConfig: toolsConfig,
+		setupConfig: setupConfig,
+		policies:    policies,
+		version:     version,
+		token:       token,
+	}
+}
+
+// SetHooks fires configured lifecycle hooks for API-driven operations
+// Params: hooksConfig - loaded hooks config (nil disables hooks)
+func (s *Server) SetHooks(hooksConfig *config.HooksConfig) {
+	s.hooks = hooksConfig
+}
+
+// SetStatusHandler serves GET /api/v1/status with an existing status handler
+// Why: Reuses the fleet /status document instead of defining a second schema
+// Params: h - handler producing status JSON
+func (s *Server) SetStatusHandler(h http.Handler) {
+	s.status = h
+}
+
+// Handler returns the authenticated API routes
+// What: POST install, verify, doctor (NDJSON streams); GET status
+// Returns: http.Handler to mount at Prefix
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+Prefix+"install", s.stream(s.install))
+	mux.HandleFunc("POST "+Prefix+"verify", s.stream(s.verify))
+	mux.HandleFunc("POST "+Prefix+"doctor", s.stream(s.doctor))
+	if s.status != nil {
+		mux.Handle("GET "+Prefix+"status", s.status)
+	}
+	return s.authorize(mux)
+}
+
+// authorize rejects requests without the bearer token
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Authorized(r, s.token) {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Authorized reports whether a request carries the bearer token
+// Why: Shared with devsetup serve's /verify, whose standard and deep modes run check commands too
+// Params: r - request, token - expected token (empty authorizes nothing)
+// Edge cases: The header must use the Bearer scheme; a bare token is rejected
+func Authorized(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// stream wraps an operation as an NDJSON progress stream
+// What: Serializes operations, streams UI events, ends with a "done" event holding ok/result/error
+// Why: Clients follow progress live instead of waiting for one large response
+func (s *Server) stream(op operation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.busy.TryLock() {
+			http.Error(w, `{"error":"another operation is running"}`, http.StatusConflict)
+			return
+		}
+		defer s.busy.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+
+		var flush func()
+		if f, ok := w.(http.Flusher); ok {
+			flush = f.Flush
+		}
+		eventUI := ui.NewEventUI(w, flush)
+
+		result, err := op(r, eventUI)
+		eventUI.Summary()
+		ok := err == nil
+		done := ui.Event{Type: ui.EventDone, OK: &ok, Result: result}
+		if err != nil {
+			done.Message = err.Error()
+		}
+		eventUI.Emit(done)
+	}
+}
+
+// install runs the tool installer (?dry_run=true for a dry run)
+func (s *Server) install(r *http.Request, eventUI ui.UI) (interface{}, error) {
+	state, err := config.LoadState()
+	if err != nil {
+		return nil, err
+	}
+
+	// Real installs are recorded so 'devsetup wait' can follow them from a terminal
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	var run *runlog.Recorder
+	if !dryRun {
+		if run, err = runlog.Begin("api install", eventUI); err != nil {
+			return nil, err
+		}
+		eventUI = run
+	}
+
+	toolInstaller := installer.NewToolInstaller(s.toolsConfig, state, eventUI, dryRun, s.version)
+	if s.policies != nil {
+		toolInstaller.SetPolicies(s.policies)
+	}
+	toolInstaller.SetHooks(hooks.NewDispatcher(s.hooks, eventUI))
+	tasks, err := toolInstaller.InstallAll()
+	run.End(tasks, err)
+	return tasks, err
+}
+
+// verify runs verification (?mode=quick|standard|deep, ?policy=true) and returns the JSON report
+func (s *Server) verify(r *http.Request, eventUI ui.UI) (interface{}, error) {
+	mode := verify.Mode(r.URL.Query().Get("mode"))
+	switch mode {
+	case "", verify.ModeQuick, verify.ModeStandard, verify.ModeDeep:
+	default:
+		return nil, fmt.Errorf("mode must be quick, standard, or deep")
+	}
+
+	state, err := config.LoadState()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := verify.NewVerifier(s.toolsConfig, s.setupConfig, state, eventUI, mode)
+	if r.URL.Query().Get("policy") == "true" && s.policies != nil {
+		verifier.SetPolicies(s.policies)
+	}
+	verifier.SetHooks(hooks.NewDispatcher(s.hooks, eventUI))
+	if exceptions, err := config.LoadExceptions(); err != nil {
+		eventUI.Warning("⚠️  Ignoring exceptions: %v", err)
+	} else {
+		verifier.SetExceptions(exceptions)
+	}
+
+	result, verifyErr := verifier.VerifyAll()
+	var buf bytes.Buffer
+	if err := verify.WriteReport(&buf, result, verify.FormatJSON); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(buf.Bytes()), verifyErr
+}
+
+// doctor runs diagnostics without fixes and returns the findings
+func (s *Server) doctor(r *http.Request, eventUI ui.UI) (interface{}, error) {
+	d := doctor.NewDoctor(s.policies, eventUI, false)
+	if state, err := config.LoadState(); err == nil {
+		d.SetTools(s.toolsConfig, state)
+	}
+	return d.Run()
+}
+
+// LoadOrCreateToken returns the API token
+// What: Uses DEVSETUP_API_TOKEN if set, otherwise reads or creates <state dir>/api-token (0600)
+// Why: Local clients (portal agent, scripts) read the token file; other users on the machine can't
+// Returns: Token, token file path (empty when taken from the environment), error if the file can't be created
+func LoadOrCreateToken() (string, string, error) {
+	if token := os.Getenv("DEVSETUP_API_TOKEN"); token != "" {
+		return token, "", nil
+	}
+
+	path := filepath.Join(config.GetStateDir(), "api-token")
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, path, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", "", err
+	}
+	return token, path, nil
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	tools := &config.ToolsConfig{Tools: []config.Tool{{Name: "present", Check: "true"}}}
+	srv := httptest.NewServer(NewServer(tools, &config.SetupConfig{}, nil, "test", "secret").Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRequiresToken(t *testing.T) {
+	srv := newTestServer(t)
+
+	for _, header := range []string{"", "Bearer wrong", "secret"} {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+Prefix+"verify", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want 401", header, resp.StatusCode)
+		}
+	}
+}
+
+func TestVerifyStreamsEventsAndResult(t *testing.T) {
+	srv := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+Prefix+"verify?mode=standard", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var events []ui.Event
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var e ui.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid event line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, e)
+	}
+
+	if len(events) < 2 {
+		t.Fatalf("expected progress events plus done, got %d", len(events))
+	}
+	done := events[len(events)-1]
+	if done.Type != ui.EventDone || done.OK == nil || !*done.OK {
+		t.Errorf("final event = %+v, want successful done", done)
+	}
+	if done.Result == nil {
+		t.Error("done event should carry the verify report")
+	}
+}
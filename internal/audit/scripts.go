@@ -0,0 +1,125 @@
+// File: internal/audit/scripts.go
+// Purpose: Inventory of remote scripts the configuration would execute
+// Problem: Nobody could tell which third-party scripts `devsetup install/setup` runs, or whether they're pinned
+// Role: Scans tools.yaml and setup.yaml for script installs and inline curl|sh commands
+// Usage: scripts := audit.RemoteScripts(toolsCfg, setupCfg); sum, err := audit.FetchChecksum(ctx, url)
+// Design choices: Script installs report their pin; inline fetch-and-run commands are flagged as unpinnable
+// Assumptions: Inline scripts are recognizable by curl/wget plus a shell in the same command
+
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/download"
+)
+
+// Script statuses
+const (
+	StatusPinned   = "pinned"
+	StatusUnpinned = "unpinned"
+	StatusInline   = "inline"
+)
+
+// urlPattern matches http(s) URLs inside shell commands
+var urlPattern = regexp.MustCompile(`https?://[^\s"'()]+`)
+
+// inlineRunPattern matches commands that fetch and execute in one go
+var inlineRunPattern = regexp.MustCompile(`(curl|wget)[^|;&]*\|\s*(sudo\s+)?(ba|z)?sh\b|(ba|z)?sh\s+-c\s+["']?\$\((curl|wget)`)
+
+// RemoteScript is a remote script the configuration would execute
+// What: Where it's declared, its URL, and how it is protected
+// Why: Audit output and pin verification
+type RemoteScript struct {
+	// Source is "tools.yaml: <tool>" or "setup.yaml: <task>"
+	Source string
+
+	// URL is the script location
+	URL string
+
+	// Status is pinned, unpinned, or inline (piped from curl; cannot be pinned)
+	Status string
+
+	// Checksum is the pinned SHA256 (pinned only)
+	Checksum string
+}
+
+// RemoteScripts lists remote scripts referenced by the configuration
+// What: Script-type tool installs plus inline curl|sh commands in tools and setup tasks
+// Why: `devsetup audit` shows exactly what third-party code a run would execute
+// Params: tools - tools configuration, setup - setup configuration (either may be nil)
+// Returns: Scripts in declaration order
+func RemoteScripts(tools *config.ToolsConfig, setup *config.SetupConfig) []RemoteScript {
+	var scripts []RemoteScript
+
+	if tools != nil {
+		for _, tool := range tools.Tools {
+			source := "tools.yaml: " + tool.Name
+			if tool.Install.IsScript() {
+				s := RemoteScript{Source: source, URL: tool.Install.Script.URL, Status: StatusUnpinned}
+				if tool.Install.Script.Checksum != "" {
+					s.Status = StatusPinned
+					s.Checksum = strings.TrimPrefix(tool.Install.Script.Checksum, "sha256:")
+				}
+				scripts = append(scripts, s)
+				continue
+			}
+			scripts = append(scripts, inlineScripts(source, tool.Install.Command)...)
+		}
+	}
+
+	if setup != nil {
+		for _, task := range setup.SetupTasks {
+			source := "setup.yaml: " + task.Name
+			var commands []string
+			if task.Remote != nil {
+				commands = append(commands, task.Remote.Command)
+			}
+			if task.Local != nil {
+				commands = append(commands, task.Local.Command)
+			}
+			commands = append(commands, task.Install...)
+			for _, step := range task.Steps {
+				commands = append(commands, step.Command)
+			}
+			for _, command := range commands {
+				scripts = append(scripts, inlineScripts(source, command)...)
+			}
+		}
+	}
+
+	return scripts
+}
+
+// inlineScripts extracts URLs from a command that fetches and runs a script
+// Params: source - declaration label, command - shell command
+// Returns: One inline RemoteScript per URL, nil if the command doesn't fetch-and-run
+func inlineScripts(source, command string) []RemoteScript {
+	if !inlineRunPattern.MatchString(command) {
+		return nil
+	}
+
+	var scripts []RemoteScript
+	for _, url := range urlPattern.FindAllString(command, -1) {
+		scripts = append(scripts, RemoteScript{Source: source, URL: url, Status: StatusInline})
+	}
+	return scripts
+}
+
+// FetchChecksum downloads a script and returns its current SHA256
+// Params: ctx - timeout/cancellation, url - script URL
+// Returns: Hex SHA256 of the current content
+// Example: sum, err := FetchChecksum(ctx, s.URL)
+func FetchChecksum(ctx context.Context, url string) (string, error) {
+	hash := sha256.New()
+	if err := download.New(nil).Copy(ctx, hash, url); err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
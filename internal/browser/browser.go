@@ -0,0 +1,116 @@
+// File: internal/browser/browser.go
+// Purpose: Managed browser policies and required extensions for Chrome, Edge, and Brave
+// Problem: SSO allowlists and the internal-tool extensions were set up by hand from a wiki page, so new machines
+//          failed SSO in the browser until someone noticed, and extensions were missing or outdated
+// Role: Turns a setup task's browser block into a configuration profile for the browser's preference domain, and
+//       checks whether an extension is installed in any of the browser's user profiles
+// Usage: data, err := browser.Mobileconfig(cfg); ok := browser.ExtensionInstalled("chrome", id)
+// Design choices: Policies go in a configuration profile (managed preferences) because Chromium browsers ignore
+//                 mandatory policies written with `defaults write`; extensions are added to the
+//                 ExtensionInstallForcelist policy so the browser installs and updates them itself; the profile is
+//                 generated deterministically, so an unchanged task produces a byte-identical file
+// Assumptions: The user approves the profile in System Settings (macOS 11+); the browser is installed separately
+
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// webStoreUpdateURL is the Chrome Web Store update service (Edge and Brave accept it too)
+const webStoreUpdateURL = "https://clients2.google.com/service/update2/crx"
+
+// forcelistPolicy is the policy listing force-installed extensions as "id;update_url"
+const forcelistPolicy = "ExtensionInstallForcelist"
+
+// Name returns the browser's display name
+// Example: Name("edge") → "Microsoft Edge"
+func Name(browser string) string {
+	switch browser {
+	case config.BrowserChrome:
+		return "Google Chrome"
+	case config.BrowserEdge:
+		return "Microsoft Edge"
+	case config.BrowserBrave:
+		return "Brave"
+	}
+	return browser
+}
+
+// Domain returns the preference domain the browser reads policies from
+// Example: Domain("chrome") → "com.google.Chrome"
+func Domain(browser string) string {
+	switch browser {
+	case config.BrowserEdge:
+		return "com.microsoft.Edge"
+	case config.BrowserBrave:
+		return "com.brave.Browser"
+	}
+	return "com.google.Chrome"
+}
+
+// DataDir returns the browser's user data directory (one subdirectory per browser profile)
+// Returns: e.g. ~/Library/Application Support/Google/Chrome
+func DataDir(browser string) string {
+	home, _ := os.UserHomeDir()
+	support := filepath.Join(home, "Library", "Application Support")
+	switch browser {
+	case config.BrowserEdge:
+		return filepath.Join(support, "Microsoft Edge")
+	case config.BrowserBrave:
+		return filepath.Join(support, "BraveSoftware", "Brave-Browser")
+	}
+	return filepath.Join(support, "Google", "Chrome")
+}
+
+// Policies returns the policies a browser block sets, with its extensions added to the force-install list
+// What: Copies the block's policies and appends "id;update_url" for each extension not already listed
+// Why: Extensions are declared by ID in setup.yaml; the browser only understands the forcelist policy
+// Params: cfg - browser block (not modified)
+// Returns: Policy name → value
+// Edge cases: A forcelist in policies is kept and extended; an extension listed there already isn't added twice
+func Policies(cfg *config.BrowserConfig) map[string]any {
+	policies := make(map[string]any, len(cfg.Policies)+1)
+	for name, value := range cfg.Policies {
+		policies[name] = value
+	}
+	if len(cfg.Extensions) == 0 {
+		return policies
+	}
+
+	var forcelist []any
+	if existing, ok := policies[forcelistPolicy].([]any); ok {
+		forcelist = append(forcelist, existing...)
+	}
+	listed := func(id string) bool {
+		return slices.ContainsFunc(forcelist, func(entry any) bool {
+			s, ok := entry.(string)
+			return ok && (s == id || strings.HasPrefix(s, id+";"))
+		})
+	}
+	for _, ext := range cfg.Extensions {
+		if listed(ext.ID) {
+			continue
+		}
+		update := ext.UpdateURL
+		if update == "" {
+			update = webStoreUpdateURL
+		}
+		forcelist = append(forcelist, ext.ID+";"+update)
+	}
+	policies[forcelistPolicy] = forcelist
+	return policies
+}
+
+// ExtensionInstalled reports whether an extension is installed in any of the browser's user profiles
+// Params: browser - chrome, edge, or brave; id - extension ID
+// Returns: True if <data dir>/<profile>/Extensions/<id> exists for some profile
+func ExtensionInstalled(browser, id string) bool {
+	matches, _ := filepath.Glob(filepath.Join(DataDir(browser), "*", "Extensions", id))
+	return len(matches) > 0
+}
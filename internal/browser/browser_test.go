@@ -0,0 +1,85 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+const onePassword = "aeblfdkhhhdcdjpifhhbdiojplfjncoa"
+
+func TestPoliciesForcelist(t *testing.T) {
+	cfg := &config.BrowserConfig{
+		Browser: config.BrowserChrome,
+		Policies: map[string]any{
+			"AuthServerAllowlist":       "*.corp.example.com",
+			"ExtensionInstallForcelist": []any{onePassword + ";https://example.com/update"},
+		},
+		Extensions: []config.BrowserExtension{
+			{ID: onePassword},
+			{ID: "cjpalhdlnbpafiamejdnhcphjbkeiagm", Name: "uBlock Origin"},
+		},
+	}
+	got := Policies(cfg)["ExtensionInstallForcelist"]
+	want := []any{onePassword + ";https://example.com/update", "cjpalhdlnbpafiamejdnhcphjbkeiagm;" + webStoreUpdateURL}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("forcelist = %v, want %v", got, want)
+	}
+	if len(cfg.Policies["ExtensionInstallForcelist"].([]any)) != 1 {
+		t.Error("Policies must not modify the task's policies")
+	}
+}
+
+func TestMobileconfig(t *testing.T) {
+	cfg := &config.BrowserConfig{
+		Browser:    config.BrowserEdge,
+		Policies:   map[string]any{"AuthServerAllowlist": "*.corp.example.com", "HomepageLocation": "https://intranet/?a=1&b=2", "PasswordManagerEnabled": false},
+		Extensions: []config.BrowserExtension{{ID: onePassword}},
+	}
+	first, err := Mobileconfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := Mobileconfig(cfg)
+	if string(first) != string(second) {
+		t.Error("Mobileconfig should be deterministic")
+	}
+
+	for _, want := range []string{
+		"<string>com.microsoft.Edge</string>",
+		"<string>com.rkinnovate.devsetup.edge</string>",
+		"<key>PasswordManagerEnabled</key>\n\t\t\t<false/>",
+		"https://intranet/?a=1&amp;b=2",
+		"<string>" + onePassword + ";" + webStoreUpdateURL + "</string>",
+	} {
+		if !strings.Contains(string(first), want) {
+			t.Errorf("profile lacks %q:\n%s", want, first)
+		}
+	}
+
+	cfg.Policies = map[string]any{"PayloadType": "x"}
+	if _, err := Mobileconfig(cfg); err == nil {
+		t.Error("Payload* policies should be rejected")
+	}
+}
+
+func TestExtensionInstalled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if ExtensionInstalled(config.BrowserChrome, onePassword) {
+		t.Fatal("no extension should be installed in an empty home")
+	}
+	dir := filepath.Join(DataDir(config.BrowserChrome), "Profile 1", "Extensions", onePassword, "8.10.0_0")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !ExtensionInstalled(config.BrowserChrome, onePassword) {
+		t.Error("extension in Profile 1 should count as installed")
+	}
+	if ExtensionInstalled(config.BrowserBrave, onePassword) {
+		t.Error("Chrome's extension should not count for Brave")
+	}
+}
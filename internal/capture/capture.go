@@ -0,0 +1,325 @@
+// File: internal/capture/capture.go
+// Purpose: Bounded capture of install and setup command output
+// Problem: Failures only said "exit status 1" (the reason had scrolled by between parallel installs), and buffering
+//          whole outputs of verbose installers (Xcode, flutter precache) would hold hundreds of MB in memory
+// Role: Tees command output to a per-task log file on disk and keeps only a small in-memory tail for error messages,
+//       from which a failure's few relevant lines (error/fatal/denied lines, the last stderr lines) are picked out;
+//       the console only sees that excerpt and the log path unless --verbose echoes the output as it runs
+// Usage: out := capture.Open("install-jq"); defer out.Close(); out.Attach(cmd, os.Stdout, os.Stderr);
+//        err = out.Wrap(cmd.Run())
+// Design choices: The full output lives in the log file (path carried by the error) rather than in memory; the tail
+//                 is capped by bytes, so memory stays constant however much a command prints; everything passes
+//                 through redact before reaching the terminal, the file, or the tail; the excerpt is a plain
+//                 heuristic (a regexp and the end of stderr), good enough to name the cause without reading the log
+// Assumptions: A log holds the latest run (truncated on first use per process, then appended, so multi-step tasks keep
+//              every step); <state dir>/logs is private to the user
+
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/redact"
+)
+
+// TailLines is how many trailing output lines error messages carry
+const TailLines = 20
+
+// maxTailBytes caps the in-memory tail
+const maxTailBytes = 16 << 10
+
+// Excerpt limits
+const (
+	// maxMatchLines is how many error-looking lines an excerpt keeps (the last ones)
+	maxMatchLines = 5
+	// stderrLines is how many trailing stderr lines an excerpt keeps
+	stderrLines = 3
+	// maxLineLength truncates long excerpt lines (minified JSON, progress bars)
+	maxLineLength = 200
+)
+
+// relevantPattern matches output lines that usually name the cause of a failure
+var relevantPattern = regexp.MustCompile(`(?i)error|fatal|denied`)
+
+// opened records logs already started by this process (later opens append)
+var opened sync.Map
+
+// verbose makes Attach echo command output to the terminal (--verbose)
+var verbose atomic.Bool
+
+// SetVerbose turns echoing of command output on or off
+// Why: Outside --verbose a failed task shows its excerpt and log path instead of its whole interleaved output
+func SetVerbose(on bool) {
+	verbose.Store(on)
+}
+
+// Output is the captured output of one command
+// What: Log file on disk plus the last maxTailBytes in memory
+// Why: Errors can quote the end of the output and point to the full log
+type Output struct {
+	mu   sync.Mutex
+	file *os.File
+	path string
+	tail []byte
+	errs []byte
+	last time.Time
+}
+
+// CommandError is a command failure with its captured output
+type CommandError struct {
+	// Err is the underlying error (usually *exec.ExitError)
+	Err error
+
+	// Tail is the last TailLines lines of output
+	Tail string
+
+	// Excerpt is the few output lines most likely to explain the failure (see Excerpt)
+	Excerpt []string
+
+	// LogPath is the file holding the full output ("" if it couldn't be written)
+	LogPath string
+}
+
+// Error returns the failure with its most relevant output line and log location
+func (e *CommandError) Error() string {
+	msg := e.Err.Error()
+	if line := e.headline(); line != "" {
+		msg += ": " + line
+	}
+	if e.LogPath != "" {
+		msg += fmt.Sprintf(" (full output: %s)", e.LogPath)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// OutputExcerpt returns the excerpt lines
+// Why: Lets ui show them under a failed task without importing capture
+func (e *CommandError) OutputExcerpt() []string {
+	return e.Excerpt
+}
+
+// headline picks the one line Error quotes: the last error-looking excerpt line, else the last output line
+func (e *CommandError) headline() string {
+	for i := len(e.Excerpt) - 1; i >= 0; i-- {
+		if relevantPattern.MatchString(e.Excerpt[i]) {
+			return e.Excerpt[i]
+		}
+	}
+	return lastLine(e.Tail)
+}
+
+// Dir returns the command log directory
+// Returns: <state dir>/logs
+func Dir() string {
+	return filepath.Join(config.GetStateDir(), "logs")
+}
+
+// Open starts capturing output for a named command
+// Params: name - log name (e.g. "install-jq"); path separators are replaced
+// Returns: Output; if the log file can't be created only the tail is kept
+// Edge cases: The first Open of a name in a process truncates the log; later ones append
+func Open(name string) *Output {
+	name = strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(name)
+	o := &Output{last: time.Now()}
+	if err := os.MkdirAll(Dir(), 0700); err != nil {
+		return o
+	}
+
+	path := filepath.Join(Dir(), name+".log")
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if _, seen := opened.LoadOrStore(path, true); !seen {
+		flags |= os.O_TRUNC
+	}
+	if f, err := os.OpenFile(path, flags, 0600); err == nil {
+		o.file, o.path = f, path
+	}
+	return o
+}
+
+// Attach routes a command's stdout and stderr through the capture
+// Params: cmd - command not yet started, stdout/stderr - where output is also echoed in verbose mode (nil = never echoed)
+// Edge cases: Outside verbose mode output only reaches the log and the tail; Wrap's error points to the log
+func (o *Output) Attach(cmd *exec.Cmd, stdout, stderr io.Writer) {
+	if !verbose.Load() {
+		stdout, stderr = nil, nil
+	}
+	cmd.Stdout = redact.NewWriter(o.tee(stdout, o))
+	cmd.Stderr = redact.NewWriter(o.tee(stderr, stderrWriter{o}))
+}
+
+// Write appends to the log file and the bounded tail
+func (o *Output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file != nil {
+		_, _ = o.file.Write(p)
+	}
+	o.last = time.Now()
+	o.tail = keepLast(append(o.tail, p...), maxTailBytes)
+	return len(p), nil
+}
+
+// stderrWriter is the capture as seen by a command's stderr: output is also kept in a stderr-only tail
+type stderrWriter struct {
+	o *Output
+}
+
+// Write records p as output and as stderr
+func (w stderrWriter) Write(p []byte) (int, error) {
+	n, err := w.o.Write(p)
+	w.o.mu.Lock()
+	w.o.errs = keepLast(append(w.o.errs, p...), maxTailBytes/4)
+	w.o.mu.Unlock()
+	return n, err
+}
+
+// Tail returns the last TailLines lines written
+func (o *Output) Tail() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	lines := strings.Split(strings.TrimRight(string(o.tail), "\n"), "\n")
+	if len(lines) > TailLines {
+		lines = lines[len(lines)-TailLines:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Excerpt returns the few lines most likely to explain a failure
+// What: The last maxMatchLines lines matching error|fatal|denied, then the last stderrLines lines of stderr, without repeats
+// Why: A failed task shows these on the console instead of the whole output, and points to the log for the rest
+// Returns: Trimmed lines (long ones truncated), else the last stderrLines lines of output; nil if nothing was printed
+// Edge cases: Only the in-memory tail is searched, so an error printed long before a flood of output is missed
+func (o *Output) Excerpt() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var lines []string
+	seen := map[string]bool{}
+	add := func(line string) {
+		if line = strings.TrimSpace(line); line != "" && !seen[line] {
+			seen[line] = true
+			if len(line) > maxLineLength {
+				line = line[:maxLineLength] + "..."
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	var matches []string
+	for _, line := range nonEmptyLines(string(o.tail)) {
+		if relevantPattern.MatchString(line) {
+			matches = append(matches, line)
+		}
+	}
+	for _, line := range lastN(matches, maxMatchLines) {
+		add(line)
+	}
+	for _, line := range lastN(nonEmptyLines(string(o.errs)), stderrLines) {
+		add(line)
+	}
+	if len(lines) == 0 {
+		for _, line := range lastN(nonEmptyLines(string(o.tail)), stderrLines) {
+			add(line)
+		}
+	}
+	return lines
+}
+
+// LastOutput returns when output last arrived (Open time if none yet)
+// Why: Hung detection looks for commands that went silent
+func (o *Output) LastOutput() time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.last
+}
+
+// Path returns the log file path ("" if none)
+func (o *Output) Path() string {
+	return o.path
+}
+
+// Wrap attaches the captured output to a command error
+// Params: err - result of cmd.Run (nil passes through)
+// Returns: *CommandError for a failure, nil otherwise
+func (o *Output) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ce *CommandError
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &CommandError{Err: err, Tail: o.Tail(), Excerpt: o.Excerpt(), LogPath: o.path}
+}
+
+// Close closes the log file
+func (o *Output) Close() error {
+	if o.file == nil {
+		return nil
+	}
+	return o.file.Close()
+}
+
+// tee returns a writer feeding both echo (if set) and the capture through w
+func (o *Output) tee(echo, w io.Writer) io.Writer {
+	if echo == nil {
+		return w
+	}
+	return io.MultiWriter(echo, w)
+}
+
+// keepLast drops all but the last n bytes of b, reusing its storage
+func keepLast(b []byte, n int) []byte {
+	if over := len(b) - n; over > 0 {
+		return append(b[:0], b[over:]...)
+	}
+	return b
+}
+
+// nonEmptyLines splits s into lines, dropping blank ones (a cut-off first line of a tail is kept)
+func nonEmptyLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		// Progress output redraws with \r; only the final state of a line matters
+		line = strings.TrimRight(line, "\r")
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// lastN returns the last n elements of s
+func lastN(s []string, n int) []string {
+	if len(s) > n {
+		return s[len(s)-n:]
+	}
+	return s
+}
+
+// lastLine returns the last non-empty line of s, trimmed
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,107 @@
+package capture
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCaptureTailAndLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	out := Open("install-noisy")
+	// One pipe for both streams: output order across two pipes isn't deterministic
+	cmd := exec.Command("sh", "-c", `exec 2>&1; i=0; while [ $i -lt 5000 ]; do echo "line $i"; i=$((i+1)); done; echo "fatal: boom" >&2; exit 3`)
+	out.Attach(cmd, nil, nil)
+	err := out.Wrap(cmd.Run())
+	out.Close()
+
+	ce, ok := err.(*CommandError)
+	if !ok {
+		t.Fatalf("Wrap() = %T %v, want *CommandError", err, err)
+	}
+	if !strings.Contains(ce.Error(), "fatal: boom") || !strings.Contains(ce.Error(), ce.LogPath) {
+		t.Errorf("Error() = %q", ce.Error())
+	}
+	if n := strings.Count(ce.Tail, "\n") + 1; n != TailLines {
+		t.Errorf("tail has %d lines, want %d", n, TailLines)
+	}
+	if len(out.tail) > maxTailBytes {
+		t.Errorf("tail buffer %d bytes exceeds %d", len(out.tail), maxTailBytes)
+	}
+
+	data, _ := os.ReadFile(ce.LogPath)
+	if !strings.HasPrefix(string(data), "line 0\n") || !strings.Contains(string(data), "line 4999\n") {
+		t.Errorf("log file is missing output (%d bytes)", len(data))
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	out := Open("install-excerpt")
+	// Written directly: output order across two pipes isn't deterministic
+	stdout, stderr := out, stderrWriter{out}
+	fmt.Fprintln(stdout, "==> Downloading")
+	fmt.Fprintln(stdout, "curl: (22) error: 404 Not Found")
+	for i := range 50 {
+		fmt.Fprintf(stdout, "progress %d\n", i)
+	}
+	fmt.Fprintln(stderr, "mkdir: /opt/x: Permission denied")
+	fmt.Fprintln(stderr, "hint: run as admin")
+	err := out.Wrap(errors.New("exit status 1"))
+	out.Close()
+
+	ce := err.(*CommandError)
+	want := []string{"curl: (22) error: 404 Not Found", "mkdir: /opt/x: Permission denied", "hint: run as admin"}
+	if strings.Join(ce.Excerpt, "|") != strings.Join(want, "|") {
+		t.Errorf("Excerpt = %q, want %q", ce.Excerpt, want)
+	}
+	// The last error-looking line beats the last line
+	if !strings.Contains(ce.Error(), "Permission denied") || strings.Contains(ce.Error(), "hint:") {
+		t.Errorf("Error() = %q", ce.Error())
+	}
+}
+
+func TestExcerptWithoutMatches(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	out := Open("install-quiet")
+	cmd := exec.Command("sh", "-c", `printf 'one\r\ntwo\n\nthree 10%%\rthree 100%%\n'; exit 1`)
+	out.Attach(cmd, nil, nil)
+	excerpt := out.Wrap(cmd.Run()).(*CommandError).Excerpt
+	out.Close()
+
+	want := []string{"one", "two", "three 100%"}
+	if strings.Join(excerpt, "|") != strings.Join(want, "|") {
+		t.Errorf("Excerpt = %q, want %q", excerpt, want)
+	}
+}
+
+func TestAttachEchoesOnlyWhenVerbose(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer SetVerbose(false)
+
+	for _, on := range []bool{false, true} {
+		SetVerbose(on)
+		var echo bytes.Buffer
+		out := Open("install-echo")
+		cmd := exec.Command("sh", "-c", `echo out; echo err >&2`)
+		out.Attach(cmd, &echo, &echo)
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+		out.Close()
+
+		if got := echo.Len() > 0; got != on {
+			t.Errorf("verbose=%v: echo got %q", on, echo.String())
+		}
+		if tail := out.Tail(); !strings.Contains(tail, "out") || !strings.Contains(tail, "err") {
+			t.Errorf("verbose=%v: tail = %q, want both streams", on, tail)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// File: internal/checkcache/checkcache.go
+// Purpose: Short-lived cache of tool check command results
+// Problem: install, status, and verify shelled out once per tool on every run; 80-tool configs took ~30s
+// Role: Runs check commands through a TTL cache persisted in the state directory
+// Usage: cache := checkcache.Open(toolsCfg.CheckCacheTTL()); ok := cache.Passes(ctx, shell, tool.Check); _ = cache.Save()
+// Design choices: Keyed by the shell and exact check command so edited checks (or a changed shell:) never hit stale
+//                 entries; both outcomes are cached; timed-out checks are not; a nil *Cache runs every command
+//                 (caching disabled);
+//                 best-effort persistence - an unreadable cache file is treated as empty
+// Assumptions: Installs invalidate the entries they affect; the TTL bounds staleness from changes made outside devsetup
+
+package checkcache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// entry is one cached check result
+type entry struct {
+	Passed    bool      `json:"passed"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Cache holds check results for a TTL
+// What: Shell and command → result map guarded by a mutex (checks run in parallel)
+// Why: Repeated status/verify runs answer from disk instead of shelling out
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	entries map[string]entry
+	dirty   bool
+}
+
+// Path returns the cache file location
+// Returns: <state dir>/check-cache.json
+func Path() string {
+	return filepath.Join(config.GetStateDir(), "check-cache.json")
+}
+
+// Open loads the cache file
+// What: Reads cached results, dropping entries older than ttl
+// Why: Single constructor used by install, status, and verify
+// Params: ttl - how long a result stays valid (<= 0 disables caching)
+// Returns: Cache, or nil when caching is disabled
+// Example: cache := checkcache.Open(5 * time.Minute)
+func Open(ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	c := &Cache{path: Path(), ttl: ttl, entries: map[string]entry{}}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	var stored map[string]entry
+	if json.Unmarshal(data, &stored) != nil {
+		return c
+	}
+
+	now := time.Now()
+	for command, e := range stored {
+		if now.Sub(e.CheckedAt) < ttl {
+			c.entries[command] = e
+		} else {
+			c.dirty = true
+		}
+	}
+	return c
+}
+
+// Passes reports whether a check command exits 0, using a cached result when fresh
+// Params: ctx - bounds the command, shell - shell the command runs under (config.ShellFor), command - shell check command
+// Returns: true if the command passes (or passed within the TTL)
+// Edge cases: Results of commands stopped by ctx (timeout, cancellation) are not cached
+func (c *Cache) Passes(ctx context.Context, shell, command string) bool {
+	k := key(shell, command)
+	if c != nil {
+		c.mu.Lock()
+		e, ok := c.entries[k]
+		c.mu.Unlock()
+		if ok && time.Since(e.CheckedAt) < c.ttl {
+			return e.Passed
+		}
+	}
+
+	passed := exec.CommandContext(ctx, shell, "-c", command).Run() == nil
+	if c != nil && ctx.Err() == nil {
+		c.mu.Lock()
+		c.entries[k] = entry{Passed: passed, CheckedAt: time.Now()}
+		c.dirty = true
+		c.mu.Unlock()
+	}
+	return passed
+}
+
+// Invalidate forgets the result for a command
+// What: Called after installing a tool so the next check runs for real
+// Params: shell - shell the command runs under, command - shell check command
+func (c *Cache) Invalidate(shell, command string) {
+	if c == nil {
+		return
+	}
+	k := key(shell, command)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[k]; ok {
+		delete(c.entries, k)
+		c.dirty = true
+	}
+}
+
+// Save writes the cache file if anything changed
+// Returns: Error if the file can't be written (callers treat the cache as best-effort)
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// key identifies a check by the shell it runs under and its command
+// Example: key("zsh", "whence -p jq") → "zsh -c whence -p jq"
+func key(shell, command string) string {
+	return shell + " -c " + command
+}
@@ -0,0 +1,49 @@
+package checkcache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheReusesAndInvalidates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "installed")
+	check := "test -f " + marker
+	ctx := context.Background()
+
+	cache := Open(time.Minute)
+	if cache.Passes(ctx, "sh", check) {
+		t.Fatal("check should fail before the marker exists")
+	}
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Passes(ctx, "sh", check) {
+		t.Error("fresh cached failure should be reused")
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A new process sees the persisted result until it is invalidated
+	reopened := Open(time.Minute)
+	if reopened.Passes(ctx, "sh", check) {
+		t.Error("persisted result should be reused")
+	}
+	// The same command under another shell is a different check
+	if !reopened.Passes(ctx, "bash", check) {
+		t.Error("result cached for sh should not answer for bash")
+	}
+	reopened.Invalidate("sh", check)
+	if !reopened.Passes(ctx, "sh", check) {
+		t.Error("invalidated check should run again")
+	}
+
+	var disabled *Cache
+	if Open(0) != nil || !disabled.Passes(ctx, "sh", check) {
+		t.Error("disabled cache should run the command")
+	}
+}
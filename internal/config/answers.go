@@ -0,0 +1,89 @@
+// File: internal/config/answers.go
+// Purpose: Per-user prompt answers remembered across runs and machines
+// Problem: Developers with two Macs typed the same non-secret prompt answers on both, and again after every reset
+// Role: Stores non-secret answers in ~/.config/devsetup/answers.yaml and layers them over team default answers
+// Usage: config.SaveAnswer("GIT_EMAIL", v); answers := config.MergeAnswers(teamDefaults.Answers)
+// Design choices: Plain YAML beside exceptions.yaml so 'devsetup sync' can carry it; answers are still offered as
+//                 defaults, never applied silently
+// Assumptions: Callers never pass secret prompt values (executePrompt only records non-secret prompts)
+
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnswersPath returns the personal answers file location
+// Returns: ~/.config/devsetup/answers.yaml
+func AnswersPath() string {
+	return filepath.Join(GetUserConfigDir(), "answers.yaml")
+}
+
+// LoadAnswers reads the personal prompt answers
+// Returns: Answers keyed by prompt env_var (empty if the file doesn't exist), error if it is malformed
+func LoadAnswers() (map[string]string, error) {
+	answers := map[string]string{}
+	data, err := os.ReadFile(AnswersPath())
+	if os.IsNotExist(err) {
+		return answers, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &answers); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", AnswersPath(), err)
+	}
+	return answers, nil
+}
+
+// SaveAnswer records one non-secret prompt answer
+// Params: envVar - prompt env_var, value - answer given
+// Returns: Error if the file can't be read or written
+func SaveAnswer(envVar, value string) error {
+	answers, err := LoadAnswers()
+	if err != nil {
+		return err
+	}
+	if answers[envVar] == value {
+		return nil
+	}
+	answers[envVar] = value
+
+	data, err := yaml.Marshal(answers)
+	if err != nil {
+		return fmt.Errorf("failed to serialize answers: %w", err)
+	}
+	if err := os.MkdirAll(GetUserConfigDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := AnswersPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write answers: %w", err)
+	}
+	if err := os.Rename(tmp, AnswersPath()); err != nil {
+		return fmt.Errorf("failed to write answers: %w", err)
+	}
+	return nil
+}
+
+// MergeAnswers layers personal answers over team default answers
+// Params: team - defaults.yaml answers (may be nil)
+// Returns: Combined answers; a personal answer wins over the team default for the same env_var
+// Edge cases: An unreadable answers file falls back to the team defaults alone
+func MergeAnswers(team map[string]string) map[string]string {
+	merged := maps.Clone(team)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	personal, err := LoadAnswers()
+	if err != nil {
+		return merged
+	}
+	maps.Copy(merged, personal)
+	return merged
+}
@@ -0,0 +1,19 @@
+package config
+
+import "testing"
+
+func TestMergeAnswers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := SaveAnswer("GIT_EMAIL", "me@example.com"); err != nil {
+		t.Fatalf("SaveAnswer: %v", err)
+	}
+	team := map[string]string{"GIT_EMAIL": "dev@example.com", "AWS_REGION": "us-east-1"}
+	merged := MergeAnswers(team)
+	if merged["GIT_EMAIL"] != "me@example.com" || merged["AWS_REGION"] != "us-east-1" {
+		t.Errorf("MergeAnswers = %v, want personal answer over team default", merged)
+	}
+	if team["GIT_EMAIL"] != "dev@example.com" {
+		t.Error("MergeAnswers modified the team answers")
+	}
+}
@@ -0,0 +1,75 @@
+// File: internal/config/deprecation.go
+// Purpose: Deprecated tools in tools.yaml (deprecated: true, replaced_by:)
+// Problem: Retiring a tool meant deleting it from tools.yaml, after which new machines stopped getting it but every
+//          existing machine kept it forever, with nothing telling its owner to move to the replacement
+// Role: Marks a tool as on its way out: install no longer installs it on machines that lack it, verify warns on
+//       machines that still have it, and `devsetup uninstall --deprecated` removes it
+// Usage: - name: yarn-classic / deprecated: true / replaced_by: pnpm
+// Design choices: The tool stays declared until the fleet has moved, so verify and uninstall still know how to find
+//                 and remove it; a deprecated tool still present is a warning, never a verify failure
+// Assumptions: Deprecated tools are optional and nothing current depends on them (enforced by validation)
+
+package config
+
+import "fmt"
+
+// DeprecationNote describes a deprecated tool for messages
+// Returns: "deprecated" or "deprecated, use <replacement>"; "" when the tool isn't deprecated
+func (t Tool) DeprecationNote() string {
+	switch {
+	case !t.Deprecated:
+		return ""
+	case t.ReplacedBy != "":
+		return "deprecated, use " + t.ReplacedBy
+	}
+	return "deprecated"
+}
+
+// DeprecatedTools returns the tools marked deprecated, in tools.yaml order
+func (tc *ToolsConfig) DeprecatedTools() []Tool {
+	var tools []Tool
+	for _, tool := range tc.Tools {
+		if tool.Deprecated {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// validateDeprecations checks deprecated and replaced_by
+// Returns: Error for a required deprecated tool, a replaced_by that isn't a current tool, or a current tool depending on a deprecated one
+// Edge cases: The dependency rule exists because new machines never get a deprecated tool
+func (tc *ToolsConfig) validateDeprecations() error {
+	byName := make(map[string]Tool, len(tc.Tools))
+	for _, tool := range tc.Tools {
+		byName[tool.Name] = tool
+	}
+
+	for _, tool := range tc.Tools {
+		if tool.ReplacedBy != "" {
+			replacement, ok := byName[tool.ReplacedBy]
+			switch {
+			case !tool.Deprecated:
+				return fmt.Errorf("tool %s: replaced_by needs deprecated: true", tool.Name)
+			case tool.ReplacedBy == tool.Name:
+				return fmt.Errorf("tool %s: replaced_by names the tool itself", tool.Name)
+			case !ok:
+				return fmt.Errorf("tool %s: replaced_by names unknown tool %s", tool.Name, tool.ReplacedBy)
+			case replacement.Deprecated:
+				return fmt.Errorf("tool %s: replacement %s is deprecated too", tool.Name, tool.ReplacedBy)
+			}
+		}
+		if !tool.Deprecated {
+			for _, dep := range tc.Dependencies(tool) {
+				if byName[dep].Deprecated {
+					return fmt.Errorf("tool %s depends on deprecated tool %s", tool.Name, dep)
+				}
+			}
+			continue
+		}
+		if tool.Required {
+			return fmt.Errorf("tool %s: a deprecated tool can't be required", tool.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDeprecations(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		tools []Tool
+		err   string
+	}{
+		{"replaced", []Tool{{Name: "yarn", Deprecated: true, ReplacedBy: "pnpm"}, {Name: "pnpm"}}, ""},
+		{"required", []Tool{{Name: "yarn", Deprecated: true, Required: true}}, "can't be required"},
+		{"not deprecated", []Tool{{Name: "yarn", ReplacedBy: "pnpm"}, {Name: "pnpm"}}, "needs deprecated"},
+		{"unknown replacement", []Tool{{Name: "yarn", Deprecated: true, ReplacedBy: "bun"}}, "unknown tool bun"},
+		{"dependent", []Tool{{Name: "yarn", Deprecated: true}, {Name: "lerna", DependsOn: []string{"yarn"}}}, "depends on deprecated tool yarn"},
+	} {
+		err := (&ToolsConfig{Tools: tc.tools}).validateDeprecations()
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s: validateDeprecations() = %v, want %q", tc.name, err, tc.err)
+		}
+	}
+
+	if note := (Tool{Deprecated: true, ReplacedBy: "pnpm"}).DeprecationNote(); note != "deprecated, use pnpm" {
+		t.Errorf("DeprecationNote() = %q", note)
+	}
+}
@@ -0,0 +1,249 @@
+// File: internal/config/direct.go
+// Purpose: Direct installs (no Homebrew) for the degraded `devsetup install --without-brew` path
+// Problem: When brew install was blocked (proxy rules, a broken Homebrew release, a locked-down new laptop), the
+//          homebrew tool failed and, being required, stopped the run: a new developer had no git, node, or editor
+//          until Homebrew worked again
+// Role: Declares per tool how it installs without Homebrew (direct:), adds the dmg and clt install types and
+//       per-architecture download URLs, and derives the reduced tools config --without-brew runs
+// Usage: tc, direct, deferred := WithoutHomebrew(toolsConfig); url, checksum := tool.Install.DMG.DownloadFor(runtime.GOARCH)
+// Design choices: A tool opts in with its own direct: block rather than devsetup guessing a download per formula;
+//                 tools that need Homebrew and have no direct install are deferred, not failed, along with
+//                 everything depending on them; direct installs keep the tool's scheduling (group, class,
+//                 timeout) unless they set their own, so they run alongside the rest as before
+// Assumptions: Direct installs place files under the user's home (no sudo), except clt, which uses softwareupdate
+
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Direct install types
+const (
+	// InstallTypeDMG copies an app bundle out of a downloaded disk image
+	InstallTypeDMG = "dmg"
+	// InstallTypeCLT installs the Xcode Command Line Tools (git, make, clang) with softwareupdate
+	InstallTypeCLT = "clt"
+)
+
+// DMGInstall describes a disk image holding an app bundle
+// What: Download (per-architecture URL), checksum, the .app to copy, and CLI links into ~/.local/bin
+// Why: Editors ship as DMGs; without Homebrew casks the app is copied out by hand otherwise
+type DMGInstall struct {
+	// URL of the .dmg; {arch} is replaced with the Mac's architecture (see Arch)
+	URL string `yaml:"url"`
+
+	// Checksum is the expected SHA256 ("sha256:<hex>" or bare hex); empty skips verification
+	Checksum string `yaml:"checksum"`
+
+	// Checksums are per-architecture checksums (arm64, amd64), used instead of Checksum
+	Checksums map[string]string `yaml:"checksums"`
+
+	// Arch names each architecture in the URL when the vendor doesn't say arm64/amd64 (e.g. amd64: x86_64)
+	Arch map[string]string `yaml:"arch"`
+
+	// App is the bundle inside the image (e.g. "Zed.app")
+	App string `yaml:"app"`
+
+	// Target is the directory the app is copied into (default ~/Applications, writable without admin rights)
+	Target string `yaml:"target"`
+
+	// Links map command names to executables inside the app, linked into ~/.local/bin (e.g. zed: Contents/MacOS/cli)
+	Links map[string]string `yaml:"links"`
+}
+
+// DownloadFor returns the URL and checksum for an architecture
+// Params: goarch - runtime.GOARCH
+func (d *DMGInstall) DownloadFor(goarch string) (url, checksum string) {
+	return archDownload(d.URL, d.Checksum, d.Arch, d.Checksums, goarch)
+}
+
+// DownloadFor returns the URL and checksum for an architecture
+// Params: goarch - runtime.GOARCH
+func (a *ArchiveInstall) DownloadFor(goarch string) (url, checksum string) {
+	return archDownload(a.URL, a.Checksum, a.Arch, a.Checksums, goarch)
+}
+
+// archDownload expands {arch} and picks the architecture's checksum
+// Example: archDownload("https://x/node-darwin-{arch}.tar.gz", "", {amd64: x64}, nil, "amd64") → "https://x/node-darwin-x64.tar.gz", ""
+func archDownload(url, checksum string, names, checksums map[string]string, goarch string) (string, string) {
+	name := goarch
+	if n, ok := names[goarch]; ok {
+		name = n
+	}
+	if c, ok := checksums[goarch]; ok {
+		checksum = c
+	}
+	return strings.ReplaceAll(url, "{arch}", name), checksum
+}
+
+// UsesHomebrew reports whether the install needs Homebrew
+// Returns: True for brew and cask installs, commands running brew, and the Homebrew installer itself
+func (ti ToolInstall) UsesHomebrew() bool {
+	switch {
+	case ti.Type == InstallTypeBrew || ti.Type == InstallTypeCask:
+		return true
+	case ti.IsScript():
+		return ti.Script.IsHomebrew()
+	case ti.Type == "" || ti.Type == InstallTypeCommand:
+		for _, segment := range strings.FieldsFunc(ti.Command, func(r rune) bool { return r == '&' || r == ';' || r == '|' }) {
+			if fields := strings.Fields(segment); len(fields) > 0 && fields[0] == "brew" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Placed returns the files and directories the install puts in place, with ~ expanded
+// What: Archive binaries (and the kept archive dir), the copied app and its links; nothing for commands, scripts, and clt
+// Why: Replacing a direct install with Homebrew's removes exactly these; managed PATH adds the dirs of those present
+func (ti ToolInstall) Placed() []string {
+	var placed []string
+	switch {
+	case ti.IsArchive() && ti.Archive != nil:
+		target := ExpandHome(defaultString(ti.Archive.Target, "~/.local/bin"))
+		for _, binary := range ti.Archive.Binaries {
+			placed = append(placed, path.Join(target, path.Base(binary)))
+		}
+		if ti.Archive.Dir != "" {
+			placed = append(placed, ExpandHome(ti.Archive.Dir))
+		}
+	case ti.Type == InstallTypeDMG && ti.DMG != nil:
+		placed = append(placed, path.Join(ExpandHome(defaultString(ti.DMG.Target, "~/Applications")), ti.DMG.App))
+		for name := range ti.DMG.Links {
+			placed = append(placed, path.Join(ExpandHome("~/.local/bin"), name))
+		}
+	}
+	return placed
+}
+
+// validateSource checks the fields an install type needs besides its package
+// Returns: Error without the tool name (callers prefix it), nil for types without source fields
+func (ti ToolInstall) validateSource() error {
+	switch ti.Type {
+	case InstallTypeArchive:
+		archive := ti.Archive
+		if archive == nil || archive.URL == "" {
+			return fmt.Errorf("archive.url is required for archive_install")
+		}
+		if len(archive.Binaries) == 0 {
+			return fmt.Errorf("archive.binaries is required for archive_install")
+		}
+	case InstallTypeScript:
+		if ti.Script == nil || ti.Script.URL == "" {
+			return fmt.Errorf("script.url is required for script")
+		}
+	case InstallTypeDMG:
+		if ti.DMG == nil || ti.DMG.URL == "" || !strings.HasSuffix(ti.DMG.App, ".app") {
+			return fmt.Errorf("dmg.url and dmg.app (a .app bundle) are required for dmg")
+		}
+	}
+	return nil
+}
+
+// validateDirect checks a tool's direct: install
+func (t *Tool) validateDirect() error {
+	d := t.Direct
+	switch d.Type {
+	case "", InstallTypeCommand, InstallTypeArchive, InstallTypeScript, InstallTypeDMG, InstallTypeCLT:
+	default:
+		return fmt.Errorf("tool %s: direct install can't be type %s (use command, archive_install, script, dmg, or clt)", t.Name, d.Type)
+	}
+	if d.UsesHomebrew() {
+		return fmt.Errorf("tool %s: direct install must not use Homebrew", t.Name)
+	}
+	if (d.Type == "" || d.Type == InstallTypeCommand) && strings.TrimSpace(d.Command) == "" {
+		return fmt.Errorf("tool %s: direct.command is required", t.Name)
+	}
+	if d.Class != "" && !isSchedulerClass(d.Class) {
+		return fmt.Errorf("invalid direct class for tool %s: %s", t.Name, d.Class)
+	}
+	if err := d.validateSource(); err != nil {
+		return fmt.Errorf("tool %s: direct: %w", t.Name, err)
+	}
+	return nil
+}
+
+// WithoutHomebrew derives the tools config for a run while Homebrew is unavailable
+// What: Tools needing Homebrew switch to their direct: install; those without one are deferred, together with every
+// tool depending on a deferred tool (a direct install brings its own files, so its dependencies don't hold it back)
+// Why: Critical tools get installed now; the rest waits for a normal install once brew works
+// Params: tc - loaded tools config (not modified)
+// Returns: Reduced config (same settings, fewer tools), direct tool names, and deferred tool names (both in declaration order)
+// Example: WithoutHomebrew(tc) → node switched to its tarball, python (brew, no direct:) deferred
+func WithoutHomebrew(tc *ToolsConfig) (reduced *ToolsConfig, direct, deferred []string) {
+	byName := make(map[string]Tool, len(tc.Tools))
+	for _, tool := range tc.Tools {
+		byName[tool.Name] = tool
+	}
+
+	isDeferred := make(map[string]bool)
+	var visit func(name string, visiting map[string]bool) bool
+	visit = func(name string, visiting map[string]bool) bool {
+		if d, ok := isDeferred[name]; ok {
+			return d
+		}
+		if visiting[name] {
+			return false // Cycles are rejected by Validate
+		}
+		visiting[name] = true
+
+		tool := byName[name]
+		d := tool.Install.UsesHomebrew() && tool.Direct == nil
+		if !tool.Install.UsesHomebrew() {
+			for _, dep := range tool.DependsOn {
+				d = d || visit(dep, visiting)
+			}
+		}
+		isDeferred[name] = d
+		return d
+	}
+
+	copied := *tc
+	copied.Tools = nil
+	for _, tool := range tc.Tools {
+		if visit(tool.Name, map[string]bool{}) {
+			deferred = append(deferred, tool.Name)
+			continue
+		}
+		if tool.Install.UsesHomebrew() {
+			tool.Install = tool.Direct.inheritScheduling(tool.Install)
+			// Cleanup and services are written for the Homebrew install
+			tool.OnFailure = nil
+			tool.Service = nil
+			direct = append(direct, tool.Name)
+
+			var deps []string
+			for _, dep := range tool.DependsOn {
+				if !visit(dep, map[string]bool{}) {
+					deps = append(deps, dep)
+				}
+			}
+			tool.DependsOn = deps
+		}
+		copied.Tools = append(copied.Tools, tool)
+	}
+	return &copied, direct, deferred
+}
+
+// inheritScheduling returns the direct install with the regular install's group, class, and timeout where it sets none
+func (ti *ToolInstall) inheritScheduling(regular ToolInstall) ToolInstall {
+	install := *ti
+	install.ParallelGroup = defaultString(install.ParallelGroup, regular.ParallelGroup)
+	install.Class = defaultString(install.Class, regular.Class)
+	if install.Timeout == 0 {
+		install.Timeout = regular.Timeout
+	}
+	return install
+}
+
+// defaultString returns s, or def when s is empty
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithoutHomebrew(t *testing.T) {
+	tc := &ToolsConfig{Tools: []Tool{
+		{Name: "homebrew", Install: ToolInstall{Type: InstallTypeScript, Script: &ScriptInstall{URL: "https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh"}}},
+		{Name: "git", DependsOn: []string{"homebrew"}, Install: ToolInstall{Type: InstallTypeBrew, Command: "brew install git"},
+			Direct: &ToolInstall{Type: InstallTypeCLT}},
+		{Name: "node", DependsOn: []string{"homebrew"}, OnFailure: &OnFailure{Command: "brew uninstall node"},
+			Install: ToolInstall{Command: "brew install node", ParallelGroup: "homebrew-cli", Class: "download"},
+			Direct:  &ToolInstall{Type: InstallTypeArchive, Archive: &ArchiveInstall{URL: "https://nodejs.org/node-{arch}.tar.gz", Binaries: []string{"bin/node"}}}},
+		{Name: "python", DependsOn: []string{"homebrew"}, Install: ToolInstall{Command: "brew install python"}},
+		{Name: "uv", DependsOn: []string{"python"}, Install: ToolInstall{Command: "curl -LsSf https://astral.sh/uv/install.sh | sh"}},
+		{Name: "pnpm", DependsOn: []string{"node"}, Install: ToolInstall{Command: "npm install -g pnpm"}},
+		{Name: "rustup", Install: ToolInstall{Type: InstallTypeScript, Script: &ScriptInstall{URL: "https://sh.rustup.rs"}}},
+	}}
+
+	reduced, direct, deferred := WithoutHomebrew(tc)
+	if want := []string{"git", "node"}; !reflect.DeepEqual(direct, want) {
+		t.Errorf("direct = %v, want %v", direct, want)
+	}
+	if want := []string{"homebrew", "python", "uv"}; !reflect.DeepEqual(deferred, want) {
+		t.Errorf("deferred = %v, want %v", deferred, want)
+	}
+	var names []string
+	for _, tool := range reduced.Tools {
+		names = append(names, tool.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"git", "node", "pnpm", "rustup"}) {
+		t.Fatalf("reduced tools = %v", names)
+	}
+
+	node := reduced.Tools[1]
+	if !node.Install.IsArchive() || node.Install.ParallelGroup != "homebrew-cli" || node.Install.Class != "download" {
+		t.Errorf("node install = %+v, want the archive with the brew install's group and class", node.Install)
+	}
+	if len(node.DependsOn) != 0 || node.OnFailure != nil {
+		t.Errorf("node keeps Homebrew dependency or cleanup: %v, %v", node.DependsOn, node.OnFailure)
+	}
+	if len(tc.Tools) != 7 || tc.Tools[2].Install.Command != "brew install node" {
+		t.Error("WithoutHomebrew modified the loaded config")
+	}
+	if err := reduced.Validate(); err != nil {
+		t.Errorf("reduced config invalid: %v", err)
+	}
+
+	if url, _ := node.Install.Archive.DownloadFor("amd64"); url != "https://nodejs.org/node-amd64.tar.gz" {
+		t.Errorf("DownloadFor(amd64) = %q", url)
+	}
+}
+
+func TestValidateDirect(t *testing.T) {
+	tests := []struct {
+		direct ToolInstall
+		want   string
+	}{
+		{ToolInstall{Type: InstallTypeCask}, "can't be type cask"},
+		{ToolInstall{Command: "brew install zed"}, "must not use Homebrew"},
+		{ToolInstall{Type: InstallTypeDMG, DMG: &DMGInstall{URL: "https://zed.dev/Zed.dmg", App: "Zed"}}, "dmg.app"},
+		{ToolInstall{Type: InstallTypeArchive, Archive: &ArchiveInstall{URL: "https://x/a.tgz"}}, "direct: archive.binaries is required"},
+	}
+	for _, tt := range tests {
+		tc := &ToolsConfig{Tools: []Tool{{Name: "zed", Install: ToolInstall{Command: "brew install --cask zed"}, Direct: &tt.direct}}}
+		if err := tc.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.direct, err, tt.want)
+		}
+	}
+
+	dmg := &DMGInstall{URL: "https://zed.dev/Zed-{arch}.dmg", Arch: map[string]string{"arm64": "aarch64"}, App: "Zed.app",
+		Checksums: map[string]string{"arm64": "sha256:ab"}}
+	if url, sum := dmg.DownloadFor("arm64"); url != "https://zed.dev/Zed-aarch64.dmg" || sum != "sha256:ab" {
+		t.Errorf("DownloadFor(arm64) = %q, %q", url, sum)
+	}
+}
@@ -11,6 +11,10 @@ package config
 import (
 	"embed"
 	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Global variable to hold the embedded filesystem
@@ -47,3 +51,75 @@ func readEmbeddedFile(path string) ([]byte, error) {
 
 	return data, nil
 }
+
+// ReadConfigFile reads a config file the way the loaders do
+// What: Filesystem first, then the embedded copy
+// Why: Support bundles record which copy (and which content hash) a run used
+// Params: path - config path (e.g. "configs/tools.yaml")
+// Returns: File contents, whether they came from the binary, and an error if neither exists
+func ReadConfigFile(path string) ([]byte, bool, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		return data, false, nil
+	}
+	data, err := readEmbeddedFile(path)
+	return data, err == nil, err
+}
+
+// validator is a typed config that can check itself
+type validator interface {
+	Validate() error
+}
+
+// embeddedValidators create the typed config for each embedded file that has one
+var embeddedValidators = map[string]func() validator{
+	"tools.yaml":    func() validator { return &ToolsConfig{} },
+	"setup.yaml":    func() validator { return &SetupConfig{} },
+	"policies.yaml": func() validator { return &PolicyConfig{} },
+	"hooks.yaml":    func() validator { return &HooksConfig{} },
+	"defaults.yaml": func() validator { return &TeamDefaults{} },
+	"releases.yaml": func() validator { return &ReleasesConfig{} },
+}
+
+// CheckEmbedded parses and validates every embedded config file
+// What: Unmarshals each embedded *.yaml into its typed config and runs Validate, ignoring files on disk and overlays
+// Why: devsetup self check confirms the binary itself carries usable configs
+// Returns: Error per file name (nil = valid); required files missing from the binary are reported too
+// Edge cases: YAML files without a typed config are only checked for syntax
+func CheckEmbedded() map[string]error {
+	results := make(map[string]error)
+	for _, name := range []string{"tools.yaml", "setup.yaml"} {
+		results[name] = fmt.Errorf("not embedded")
+	}
+
+	entries, err := embeddedFS.ReadDir(".")
+	if err != nil {
+		return results
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		data, err := embeddedFS.ReadFile(name)
+		if err != nil {
+			results[name] = err
+			continue
+		}
+
+		newConfig, typed := embeddedValidators[name]
+		if !typed {
+			var doc any
+			results[name] = yaml.Unmarshal(data, &doc)
+			continue
+		}
+		cfg := newConfig()
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			results[name] = fmt.Errorf("parse: %w", err)
+		} else if err := cfg.Validate(); err != nil {
+			results[name] = fmt.Errorf("invalid: %w", err)
+		} else {
+			results[name] = nil
+		}
+	}
+	return results
+}
@@ -0,0 +1,230 @@
+// File: internal/config/exceptions_config.go
+// Purpose: Data models for the per-user verify exceptions file
+// Problem: Developers legitimately deviate (extra tools, a newer node for a deploy blocker) and verify reported it as drift
+// Role: Lists sanctioned deviations honored by verify and status drift detection
+// Usage: ex, err := LoadExceptions(); verifier.SetExceptions(ex); ex.SetOverride(e); SaveExceptions(ex)
+// Design choices: Lives in ~/.config/devsetup (user-edited, never in the repo); every entry can expire so
+//                 deviations are temporary by default; expired entries are ignored and reported
+// Assumptions: Dates are calendar days in local time; an entry is valid through the end of its expiry day
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exceptionDateLayout is the expires date format
+const exceptionDateLayout = "2006-01-02"
+
+// Exceptions represents the per-user exceptions file
+// What: Tools/tasks to skip in verify and pinned local version overrides
+// Why: Lets teams tell sanctioned deviations apart from drift
+type Exceptions struct {
+	// SkipVerify lists tools or setup tasks verify should not check
+	SkipVerify []Exception `yaml:"skip_verify,omitempty"`
+
+	// Overrides pin a tool to a locally sanctioned version instead of the recorded one
+	Overrides []Exception `yaml:"overrides,omitempty"`
+}
+
+// Exception is one sanctioned deviation
+// What: Tool or task name, optional override version, reason, and optional expiry date
+// Why: The reason and expiry make every deviation reviewable
+type Exception struct {
+	// Name is the tool or setup task name
+	Name string `yaml:"name"`
+
+	// Version is the sanctioned version (overrides only)
+	Version string `yaml:"version,omitempty"`
+
+	// Reason explains the deviation (shown in verify output)
+	Reason string `yaml:"reason"`
+
+	// Expires is the last day the exception applies (YYYY-MM-DD, empty = never)
+	Expires string `yaml:"expires,omitempty"`
+}
+
+// ExceptionsPath returns the exceptions file location
+// Returns: ~/.config/devsetup/exceptions.yaml
+func ExceptionsPath() string {
+	return filepath.Join(GetUserConfigDir(), "exceptions.yaml")
+}
+
+// LoadExceptions reads the per-user exceptions file
+// What: Parses and validates ~/.config/devsetup/exceptions.yaml
+// Why: Verify and status honor sanctioned deviations
+// Returns: Exceptions (empty if the file doesn't exist), error if it is malformed
+// Example: ex, err := LoadExceptions()
+func LoadExceptions() (*Exceptions, error) {
+	ex := &Exceptions{}
+	data, err := os.ReadFile(ExceptionsPath())
+	if os.IsNotExist(err) {
+		return ex, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exceptions: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, ex); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ExceptionsPath(), err)
+	}
+	if err := ex.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ExceptionsPath(), err)
+	}
+	return ex, nil
+}
+
+// SaveExceptions writes the per-user exceptions file
+// What: Validates and atomically replaces ~/.config/devsetup/exceptions.yaml
+// Why: 'devsetup pin override' records deviations without hand-editing YAML
+// Params: ex - exceptions to save
+// Returns: Error if ex is invalid or the file can't be written
+// Edge cases: Comments in a hand-edited file are not preserved
+func SaveExceptions(ex *Exceptions) error {
+	if err := ex.Validate(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(ex)
+	if err != nil {
+		return fmt.Errorf("failed to serialize exceptions: %w", err)
+	}
+	if err := os.MkdirAll(GetUserConfigDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := ExceptionsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write exceptions: %w", err)
+	}
+	if err := os.Rename(tmp, ExceptionsPath()); err != nil {
+		return fmt.Errorf("failed to write exceptions: %w", err)
+	}
+	return nil
+}
+
+// SetOverride adds a version override, replacing any earlier override for the same tool
+// Params: e - override entry (name and version required)
+func (ex *Exceptions) SetOverride(e Exception) {
+	for i := range ex.Overrides {
+		if ex.Overrides[i].Name == e.Name {
+			ex.Overrides[i] = e
+			return
+		}
+	}
+	ex.Overrides = append(ex.Overrides, e)
+}
+
+// Validate checks names, override versions, and expiry dates
+// Returns: Error describing the first invalid entry, nil if valid
+func (ex *Exceptions) Validate() error {
+	for i, e := range ex.SkipVerify {
+		if e.Name == "" {
+			return fmt.Errorf("skip_verify[%d]: name is required", i)
+		}
+		if _, err := e.expiry(); err != nil {
+			return fmt.Errorf("skip_verify %s: %w", e.Name, err)
+		}
+	}
+	for i, e := range ex.Overrides {
+		if e.Name == "" {
+			return fmt.Errorf("overrides[%d]: name is required", i)
+		}
+		if e.Version == "" {
+			return fmt.Errorf("override %s: version is required", e.Name)
+		}
+		if _, err := e.expiry(); err != nil {
+			return fmt.Errorf("override %s: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// Skip returns the active skip_verify entry for a tool or task
+// Params: name - tool or setup task name, now - evaluation time
+// Returns: Entry and true if name is skipped and not expired (nil receiver skips nothing)
+func (ex *Exceptions) Skip(name string, now time.Time) (Exception, bool) {
+	if ex == nil {
+		return Exception{}, false
+	}
+	return findActive(ex.SkipVerify, name, now)
+}
+
+// Override returns the active version override for a tool
+// Params: name - tool name, now - evaluation time
+// Returns: Entry and true if an unexpired override exists (nil receiver overrides nothing)
+func (ex *Exceptions) Override(name string, now time.Time) (Exception, bool) {
+	if ex == nil {
+		return Exception{}, false
+	}
+	return findActive(ex.Overrides, name, now)
+}
+
+// Expired returns entries whose expiry date has passed
+// What: Expired skip_verify and override entries, in file order
+// Why: Verify reminds the user to renew or delete them; they no longer apply
+// Params: now - evaluation time
+// Returns: Expired entries (nil receiver returns none)
+func (ex *Exceptions) Expired(now time.Time) []Exception {
+	if ex == nil {
+		return nil
+	}
+	var expired []Exception
+	for _, list := range [][]Exception{ex.SkipVerify, ex.Overrides} {
+		for _, e := range list {
+			if !e.Active(now) {
+				expired = append(expired, e)
+			}
+		}
+	}
+	return expired
+}
+
+// Active reports whether the exception still applies at now
+// Edge cases: Unparseable dates count as expired (LoadExceptions rejects them up front)
+func (e Exception) Active(now time.Time) bool {
+	end, err := e.expiry()
+	if err != nil {
+		return false
+	}
+	return end.IsZero() || now.Before(end)
+}
+
+// Describe formats the reason and expiry for display
+// Returns: e.g. "deploy blocker, until 2026-08-01"
+func (e Exception) Describe() string {
+	desc := e.Reason
+	if desc == "" {
+		desc = "no reason given"
+	}
+	if e.Expires != "" {
+		desc += ", until " + e.Expires
+	}
+	return desc
+}
+
+// expiry parses Expires as the instant after the last valid day
+// Returns: Zero time if no expiry, error if the date is malformed
+func (e Exception) expiry() (time.Time, error) {
+	if e.Expires == "" {
+		return time.Time{}, nil
+	}
+	day, err := time.ParseInLocation(exceptionDateLayout, e.Expires, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expires %q is not a YYYY-MM-DD date", e.Expires)
+	}
+	return day.AddDate(0, 0, 1), nil
+}
+
+// findActive returns the first unexpired entry for name
+func findActive(list []Exception, name string, now time.Time) (Exception, bool) {
+	for _, e := range list {
+		if e.Name == name && e.Active(now) {
+			return e, true
+		}
+	}
+	return Exception{}, false
+}
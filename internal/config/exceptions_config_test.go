@@ -0,0 +1,82 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExceptionExpiry(t *testing.T) {
+	ex := &Exceptions{
+		SkipVerify: []Exception{
+			{Name: "docker", Reason: "using colima", Expires: "2026-08-01"},
+			{Name: "git"},
+		},
+		Overrides: []Exception{{Name: "node", Version: "22.1.0", Expires: "2026-07-01"}},
+	}
+	if err := ex.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	lastDay := time.Date(2026, 8, 1, 23, 59, 0, 0, time.Local)
+	if _, ok := ex.Skip("docker", lastDay); !ok {
+		t.Error("exception should apply through the end of its expiry day")
+	}
+	if _, ok := ex.Skip("docker", lastDay.Add(time.Hour)); ok {
+		t.Error("exception should not apply after its expiry day")
+	}
+	if _, ok := ex.Skip("git", lastDay.AddDate(10, 0, 0)); !ok {
+		t.Error("exception without expires should never expire")
+	}
+	if _, ok := ex.Override("node", lastDay); ok {
+		t.Error("expired override should not apply")
+	}
+
+	expired := ex.Expired(lastDay)
+	if len(expired) != 1 || expired[0].Name != "node" {
+		t.Errorf("Expired = %+v, want [node]", expired)
+	}
+
+	var none *Exceptions
+	if _, ok := none.Skip("docker", lastDay); ok {
+		t.Error("nil exceptions should skip nothing")
+	}
+}
+
+func TestExceptionsValidate(t *testing.T) {
+	bad := []*Exceptions{
+		{SkipVerify: []Exception{{Reason: "no name"}}},
+		{SkipVerify: []Exception{{Name: "x", Expires: "next week"}}},
+		{Overrides: []Exception{{Name: "node"}}},
+	}
+	for i, ex := range bad {
+		if err := ex.Validate(); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestSetOverrideAndSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ex := &Exceptions{Overrides: []Exception{{Name: "node", Version: "v20.0.0"}}}
+	ex.SetOverride(Exception{Name: "node", Version: "v22.1.0", Reason: "deploy blocker", Expires: "2026-08-01"})
+	ex.SetOverride(Exception{Name: "go", Version: "go1.23.0"})
+	if len(ex.Overrides) != 2 || ex.Overrides[0].Version != "v22.1.0" {
+		t.Fatalf("SetOverride should replace node and append go, got %+v", ex.Overrides)
+	}
+
+	if err := SaveExceptions(ex); err != nil {
+		t.Fatalf("SaveExceptions: %v", err)
+	}
+	loaded, err := LoadExceptions()
+	if err != nil {
+		t.Fatalf("LoadExceptions: %v", err)
+	}
+	if e, ok := loaded.Override("node", time.Date(2026, 8, 1, 12, 0, 0, 0, time.Local)); !ok || e.Reason != "deploy blocker" {
+		t.Errorf("saved override not loaded back: %+v", loaded.Overrides)
+	}
+
+	if err := SaveExceptions(&Exceptions{Overrides: []Exception{{Name: "node"}}}); err == nil {
+		t.Error("SaveExceptions should reject an override without a version")
+	}
+}
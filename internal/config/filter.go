@@ -0,0 +1,176 @@
+// File: internal/config/filter.go
+// Purpose: Run filters that narrow install/setup to part of the loaded configs
+// Problem: Debugging a partial setup meant editing tools.yaml/setup.yaml to comment entries out
+// Role: Backs the --stages, --skip-tasks, and --only-group flags
+// Usage: f, err := NewRunFilter(stages, skip, groups); installer.SetFilter(f); setupCfg = f.FilterSetup(setupCfg)
+// Design choices: Stages are install batch numbers computed from the full config, so numbering doesn't shift when
+//                 other filters are added; filtered tools are simply not run within their batch; setup filtering
+//                 returns a copy so the loaded config stays intact (generations record the real files)
+// Assumptions: Names are validated against the config so a typo fails loudly instead of filtering nothing
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RunFilter selects which tools and tasks a run executes
+// What: Stage numbers, names to skip, and parallel groups to keep
+// Why: Lets developers rerun one slice of the environment while debugging
+type RunFilter struct {
+	// Stages are 1-based install batch numbers to run (empty = all)
+	Stages []int
+
+	// SkipTasks are tool or setup task names to leave out
+	SkipTasks []string
+
+	// OnlyGroups are parallel_group names to keep (empty = all); ungrouped tools are dropped when set
+	OnlyGroups []string
+}
+
+// NewRunFilter builds a filter from comma-separated flag values
+// Params: stages - e.g. "1,2", skipTasks - e.g. "docker,flutter", onlyGroups - e.g. "homebrew-cli"
+// Returns: Filter and error if a stage is not a positive integer
+// Example: f, err := NewRunFilter("1,2", "", "homebrew-cli")
+func NewRunFilter(stages, skipTasks, onlyGroups string) (*RunFilter, error) {
+	f := &RunFilter{
+		SkipTasks:  splitList(skipTasks),
+		OnlyGroups: splitList(onlyGroups),
+	}
+	for _, s := range splitList(stages) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid stage %q (expected a positive number)", s)
+		}
+		f.Stages = append(f.Stages, n)
+	}
+	sort.Ints(f.Stages)
+	return f, nil
+}
+
+// Empty reports whether the filter keeps everything (nil filter is empty)
+func (f *RunFilter) Empty() bool {
+	return f == nil || (len(f.Stages) == 0 && len(f.SkipTasks) == 0 && len(f.OnlyGroups) == 0)
+}
+
+// IncludesStage reports whether an install batch should run
+// Params: stage - 1-based batch number
+func (f *RunFilter) IncludesStage(stage int) bool {
+	if f == nil || len(f.Stages) == 0 {
+		return true
+	}
+	for _, s := range f.Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludesTool reports whether a tool passes --skip-tasks and --only-group
+// Params: tool - tool declaration
+// Returns: true if the tool should run (nil filter includes everything)
+func (f *RunFilter) IncludesTool(tool Tool) bool {
+	if f == nil {
+		return true
+	}
+	for _, name := range f.SkipTasks {
+		if name == tool.Name {
+			return false
+		}
+	}
+	if len(f.OnlyGroups) == 0 {
+		return true
+	}
+	for _, g := range f.OnlyGroups {
+		if g == tool.Install.ParallelGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that every skipped name and group exists
+// What: Skip names must name a tool or setup task; groups must be a parallel_group in tools.yaml
+// Why: A typo would otherwise silently filter nothing
+// Params: tools - tools config, setup - setup config (either may be nil)
+// Returns: Error naming the first unknown entry
+func (f *RunFilter) Validate(tools *ToolsConfig, setup *SetupConfig) error {
+	if f.Empty() {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	groups := make(map[string]bool)
+	if tools != nil {
+		for _, tool := range tools.Tools {
+			names[tool.Name] = true
+			if tool.Install.ParallelGroup != "" {
+				groups[tool.Install.ParallelGroup] = true
+			}
+		}
+	}
+	if setup != nil {
+		for _, task := range setup.SetupTasks {
+			names[task.Name] = true
+		}
+	}
+
+	for _, name := range f.SkipTasks {
+		if !names[name] {
+			return fmt.Errorf("unknown tool or task %q in --skip-tasks", name)
+		}
+	}
+	for _, g := range f.OnlyGroups {
+		if !groups[g] {
+			return fmt.Errorf("unknown parallel_group %q in --only-group", g)
+		}
+	}
+	return nil
+}
+
+// FilterSetup applies --skip-tasks to a setup config
+// What: Returns a copy without skipped tasks
+// Why: The executor then orders and runs only what is left
+// Params: cfg - loaded setup config
+// Returns: Filtered copy (cfg itself when nothing is skipped)
+// Edge cases: depends_on entries on skipped tasks are dropped (assumed already configured)
+func (f *RunFilter) FilterSetup(cfg *SetupConfig) *SetupConfig {
+	if f == nil || len(f.SkipTasks) == 0 {
+		return cfg
+	}
+
+	skip := make(map[string]bool, len(f.SkipTasks))
+	for _, name := range f.SkipTasks {
+		skip[name] = true
+	}
+
+	kept := make([]SetupTask, 0, len(cfg.SetupTasks))
+	for _, task := range cfg.SetupTasks {
+		if skip[task.Name] {
+			continue
+		}
+		for _, name := range f.SkipTasks {
+			task.DependsOn, task.dependsOnLines = withoutDependency(task.DependsOn, task.dependsOnLines, NodeTask, NodeID(NodeTask, name))
+		}
+		kept = append(kept, task)
+	}
+
+	filtered := *cfg
+	filtered.SetupTasks = kept
+	return &filtered
+}
+
+// splitList splits a comma-separated flag value, dropping blanks
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,48 @@
+package config
+
+import "testing"
+
+func TestRunFilter(t *testing.T) {
+	tools := &ToolsConfig{Tools: []Tool{
+		{Name: "git", Install: ToolInstall{ParallelGroup: "homebrew-cli"}},
+		{Name: "zed", Install: ToolInstall{ParallelGroup: "homebrew-casks"}},
+		{Name: "homebrew"},
+	}}
+	setup := &SetupConfig{SetupTasks: []SetupTask{
+		{Name: "git-config"},
+		{Name: "gh-auth", DependsOn: []string{"git-config", "tool:git"}},
+	}}
+
+	f, err := NewRunFilter("2, 1", "zed,git-config", "homebrew-cli,homebrew-casks")
+	if err != nil {
+		t.Fatalf("NewRunFilter: %v", err)
+	}
+	if err := f.Validate(tools, setup); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !f.IncludesStage(1) || f.IncludesStage(3) {
+		t.Errorf("stages = %v", f.Stages)
+	}
+	if !f.IncludesTool(tools.Tools[0]) || f.IncludesTool(tools.Tools[1]) || f.IncludesTool(tools.Tools[2]) {
+		t.Error("IncludesTool should keep git only (zed skipped, homebrew ungrouped)")
+	}
+
+	filtered := f.FilterSetup(setup)
+	if len(filtered.SetupTasks) != 1 || filtered.SetupTasks[0].Name != "gh-auth" {
+		t.Fatalf("FilterSetup = %+v", filtered.SetupTasks)
+	}
+	if deps := filtered.SetupTasks[0].DependsOn; len(deps) != 1 || deps[0] != "tool:git" {
+		t.Errorf("skipped dependency not dropped: %v", deps)
+	}
+	if len(setup.SetupTasks[1].DependsOn) != 2 {
+		t.Error("FilterSetup modified the original config")
+	}
+
+	if _, err := NewRunFilter("0", "", ""); err == nil {
+		t.Error("stage 0 should be rejected")
+	}
+	bad, _ := NewRunFilter("", "gti", "")
+	if err := bad.Validate(tools, setup); err == nil {
+		t.Error("unknown skip name should be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupDependencies(t *testing.T) {
+	tool := func(name, group string, deps ...string) Tool {
+		return Tool{Name: name, Install: ToolInstall{ParallelGroup: group}, DependsOn: deps}
+	}
+	tc := &ToolsConfig{
+		Tools: []Tool{
+			tool("homebrew", ""),
+			tool("git", "cli", "homebrew"),
+			tool("gh", "cli", "homebrew"),
+			tool("zed", "casks", "homebrew"),
+		},
+		Groups: []ParallelGroup{{Name: "casks", DependsOn: []string{"cli"}}},
+		Shell:  ShellSh,
+	}
+
+	if got := tc.Dependencies(tc.Tools[3]); !reflect.DeepEqual(got, []string{"homebrew", "git", "gh"}) {
+		t.Errorf("Dependencies(zed) = %v", got)
+	}
+	if got := tc.Dependencies(tc.Tools[1]); !reflect.DeepEqual(got, []string{"homebrew"}) {
+		t.Errorf("Dependencies(git) = %v, want only its depends_on (cli is undeclared)", got)
+	}
+	if err := tc.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	// Group dependencies that close a loop with tool depends_on are cycles
+	tc.Tools[1].DependsOn = append(tc.Tools[1].DependsOn, "zed")
+	if err := tc.Validate(); err == nil {
+		t.Error("Validate() accepted a cycle through a group dependency")
+	}
+	tc.Tools[1].DependsOn = []string{"homebrew"}
+
+	for _, groups := range [][]ParallelGroup{
+		{{Name: "casks", DependsOn: []string{"casks"}}},
+		{{Name: "casks", DependsOn: []string{"typo"}}},
+		{{Name: "casks"}, {Name: "casks"}},
+		{{DependsOn: []string{"cli"}}},
+	} {
+		tc.Groups = groups
+		if err := tc.Validate(); err == nil || !strings.HasPrefix(err.Error(), "groups:") {
+			t.Errorf("Validate(%+v) = %v, want a groups error", groups, err)
+		}
+	}
+}
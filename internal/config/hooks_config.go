@@ -0,0 +1,113 @@
+// File: internal/config/hooks_config.go
+// Purpose: Data models for hooks.yaml (lifecycle event hooks)
+// Problem: Teams wanted ticketing/chat integration on failures and drift without modifying the binary
+// Role: Maps lifecycle events to actions (shell command or webhook POST)
+// Usage: Loaded by install, setup, and verify via LoadHooksConfig; dispatched by internal/hooks
+// Design choices: Flat list of event → action entries; one action per entry (command or webhook) keeps validation simple
+// Assumptions: Hook failures never fail the run; they are reported as warnings
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lifecycle events hooks can subscribe to
+const (
+	// EventStageComplete fires when install or setup finishes successfully (data: stage)
+	EventStageComplete = "stage_complete"
+	// EventStageFailed fires when install or setup stops with an error (data: stage, error)
+	EventStageFailed = "stage_failed"
+	// EventTaskFailed fires when a tool install or setup task fails (data: kind, name, error, required)
+	EventTaskFailed = "task_failed"
+	// EventVerifyFailed fires when verify finds failing checks (data: mode, failed, total)
+	EventVerifyFailed = "verify_failed"
+	// EventVerifyDrift fires when deep verify detects drift (data: drifted)
+	EventVerifyDrift = "verify_drift"
+)
+
+// HooksConfig represents the complete hooks.yaml file
+// What: Event hooks evaluated during install, setup, and verify
+// Why: Integrations live in config, not code
+type HooksConfig struct {
+	// Hooks are the registered event actions
+	Hooks []Hook `yaml:"hooks"`
+}
+
+// Hook maps one event to one action
+// What: Event name plus either a shell command or a webhook URL
+// Why: Shell commands cover local integrations; webhooks cover chat/ticketing
+type Hook struct {
+	// Event is the lifecycle event name (stage_complete, task_failed, ...)
+	Event string `yaml:"event"`
+
+	// Command runs via sh -c with DEVSETUP_EVENT and DEVSETUP_<KEY> environment variables
+	Command string `yaml:"command"`
+
+	// Webhook receives a JSON POST: {"event", "time", "hostname", "data"}
+	Webhook string `yaml:"webhook"`
+
+	// Headers are extra webhook headers; values are environment-expanded (e.g. "Bearer ${TOKEN}")
+	Headers map[string]string `yaml:"headers"`
+
+	// Timeout bounds the action (default 10s)
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// LoadHooksConfig loads and parses hooks.yaml
+// What: Reads hooks.yaml from filesystem or embedded, parses into HooksConfig
+// Why: Main entry point for loading event hooks
+// Params: path - path to hooks.yaml (e.g., "configs/hooks.yaml")
+// Returns: Parsed HooksConfig and error if any
+// Example: cfg, err := LoadHooksConfig("configs/hooks.yaml")
+// Edge cases: Falls back to embedded if file not found on disk
+func LoadHooksConfig(path string) (*HooksConfig, error) {
+	// Try filesystem first (development)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Fall back to embedded (production)
+		data, err = readEmbeddedFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hooks config: %w", err)
+		}
+	}
+
+	var config HooksConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks config: %w", err)
+	}
+
+	// Validate
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid hooks config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Validate checks if the hooks configuration is valid
+// What: Validates event names and that each hook has exactly one action
+// Why: A typo in an event name would otherwise silently never fire
+// Returns: Error describing validation failure, nil if valid
+func (hc *HooksConfig) Validate() error {
+	for i, h := range hc.Hooks {
+		switch h.Event {
+		case EventStageComplete, EventStageFailed, EventTaskFailed, EventVerifyFailed, EventVerifyDrift:
+		default:
+			return fmt.Errorf("hook %d: unknown event %q", i+1, h.Event)
+		}
+
+		if (h.Command == "") == (h.Webhook == "") {
+			return fmt.Errorf("hook %d (%s): exactly one of command or webhook is required", i+1, h.Event)
+		}
+
+		if h.Timeout < 0 {
+			return fmt.Errorf("hook %d (%s): timeout must be positive", i+1, h.Event)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// File: internal/config/macos_config.go
+// Purpose: Supported macOS range for the tool set (tools.yaml macos section)
+// Problem: Installs on too-old macOS failed halfway through, and OS upgrades silently broke pinned casks
+// Role: Minimum macOS version gate for install and known-incompatible list for doctor's upgrade advisory
+// Usage: if !toolsCfg.MacOS.Supports(current) { ... }; for _, e := range toolsCfg.MacOS.BrokenOn(current) { ... }
+// Design choices: Incompatibilities are keyed by tool name and the first macOS version that breaks the tool,
+//                 so one list answers both "broken now" and "broken by the next upgrade"
+// Assumptions: Versions compare numerically (version.Compare); "15" covers every 15.x release
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/rkinnovate/dev-setup/internal/version"
+)
+
+// MacOSRequirements represents the macos section of tools.yaml
+// What: Minimum macOS version and tools known to break on newer releases
+// Why: Install blocks below the minimum; doctor warns before an upgrade breaks installed tools
+type MacOSRequirements struct {
+	// MinVersion is the oldest macOS the tool set supports (e.g. "14.0")
+	MinVersion string `yaml:"min_version"`
+
+	// Incompatible lists tools that stop working from a macOS release on
+	Incompatible []MacOSIncompatibility `yaml:"incompatible"`
+}
+
+// MacOSIncompatibility is a tool known to break on a macOS release
+type MacOSIncompatibility struct {
+	// Tool is the tools.yaml tool name
+	Tool string `yaml:"tool"`
+
+	// Since is the first macOS version the tool breaks on (e.g. "26")
+	Since string `yaml:"since"`
+
+	// Reason explains the breakage or the workaround
+	Reason string `yaml:"reason"`
+}
+
+// Validate checks that every incompatibility names a tool and a version
+// Returns: Error describing the first invalid entry, nil if valid
+func (m *MacOSRequirements) Validate() error {
+	if m == nil {
+		return nil
+	}
+	for i, e := range m.Incompatible {
+		if e.Tool == "" {
+			return fmt.Errorf("macos.incompatible[%d]: tool is required", i)
+		}
+		if e.Since == "" {
+			return fmt.Errorf("macos.incompatible %s: since is required", e.Tool)
+		}
+	}
+	return nil
+}
+
+// Supports reports whether the running macOS meets the minimum
+// Params: current - running macOS version (e.g. "14.6.1")
+// Returns: true if no minimum is set (nil receiver included) or current >= min_version
+func (m *MacOSRequirements) Supports(current string) bool {
+	if m == nil || m.MinVersion == "" {
+		return true
+	}
+	return version.Compare(current, m.MinVersion) >= 0
+}
+
+// BrokenOn returns incompatibilities that already apply to a macOS version
+// Params: current - running macOS version
+// Returns: Entries whose since version is <= current (nil receiver returns none)
+func (m *MacOSRequirements) BrokenOn(current string) []MacOSIncompatibility {
+	if m == nil {
+		return nil
+	}
+	var broken []MacOSIncompatibility
+	for _, e := range m.Incompatible {
+		if version.Compare(current, e.Since) >= 0 {
+			broken = append(broken, e)
+		}
+	}
+	return broken
+}
+
+// Upcoming returns incompatibilities an OS upgrade from current would trigger
+// Params: current - running macOS version
+// Returns: Entries whose since version is newer than current (nil receiver returns none)
+func (m *MacOSRequirements) Upcoming(current string) []MacOSIncompatibility {
+	if m == nil {
+		return nil
+	}
+	var upcoming []MacOSIncompatibility
+	for _, e := range m.Incompatible {
+		if version.Compare(current, e.Since) < 0 {
+			upcoming = append(upcoming, e)
+		}
+	}
+	return upcoming
+}
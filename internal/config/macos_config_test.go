@@ -0,0 +1,46 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMacOSRequirements(t *testing.T) {
+	m := &MacOSRequirements{
+		MinVersion: "14.0",
+		Incompatible: []MacOSIncompatibility{
+			{Tool: "virtualbox", Since: "15"},
+			{Tool: "old-vpn", Since: "14.4"},
+		},
+	}
+
+	if m.Supports("13.6.1") {
+		t.Error("13.6.1 should be below 14.0")
+	}
+	if !m.Supports("14.10") {
+		t.Error("14.10 should meet 14.0")
+	}
+
+	tools := func(entries []MacOSIncompatibility) []string {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Tool)
+		}
+		return names
+	}
+	if got := tools(m.BrokenOn("14.6")); !reflect.DeepEqual(got, []string{"old-vpn"}) {
+		t.Errorf("BrokenOn(14.6) = %v", got)
+	}
+	if got := tools(m.Upcoming("14.6")); !reflect.DeepEqual(got, []string{"virtualbox"}) {
+		t.Errorf("Upcoming(14.6) = %v", got)
+	}
+
+	var none *MacOSRequirements
+	if !none.Supports("10.0") || none.BrokenOn("99") != nil {
+		t.Error("nil requirements should gate nothing")
+	}
+
+	if err := (&MacOSRequirements{Incompatible: []MacOSIncompatibility{{Tool: "x"}}}).Validate(); err == nil {
+		t.Error("expected error for missing since")
+	}
+}
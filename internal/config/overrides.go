@@ -0,0 +1,239 @@
+// File: internal/config/overrides.go
+// Purpose: Per-user overlays applied to tools.yaml and setup.yaml at load time
+// Problem: Tweaking the embedded configs (a longer timeout, one extra tool, skipping a task) meant rebuilding the binary
+// Role: Reads ~/.config/devsetup/configs.d/*.yaml and merges them into the loaded configs before validation
+// Usage: Applied automatically by LoadToolsConfig and LoadSetupConfig; applied files are listed in Overrides
+// Design choices: Small patch vocabulary (add, disable, timeouts) instead of a generic deep merge so results stay
+//                 predictable; files apply in lexical order (10-team.yaml before 20-me.yaml); "add" with an existing
+//                 name replaces that entry; disabling an entry drops it from other entries' depends_on
+// Assumptions: Overlay files are user-edited; an unknown name is an error so typos don't silently do nothing
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigOverride is one configs.d overlay file
+// What: Patches for tools.yaml and setup.yaml
+// Why: Local tweaks survive binary updates and never touch the shipped configs
+type ConfigOverride struct {
+	// Tools patches tools.yaml
+	Tools ToolOverrides `yaml:"tools"`
+
+	// Setup patches setup.yaml
+	Setup SetupOverrides `yaml:"setup"`
+
+	// Path is the overlay file (for error messages)
+	Path string `yaml:"-"`
+}
+
+// ToolOverrides patches the tools list
+type ToolOverrides struct {
+	// Add appends tools, or replaces a tool with the same name
+	Add []Tool `yaml:"add"`
+
+	// Disable removes tools by name
+	Disable []string `yaml:"disable"`
+
+	// Timeouts sets install.timeout by tool name
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+}
+
+// SetupOverrides patches the setup task list
+type SetupOverrides struct {
+	// Add appends setup tasks, or replaces a task with the same name
+	Add []SetupTask `yaml:"add"`
+
+	// Disable removes setup tasks by name
+	Disable []string `yaml:"disable"`
+
+	// Timeouts sets remote/local command timeouts by task name
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+}
+
+// OverridesDir returns the overlay directory
+// Returns: ~/.config/devsetup/configs.d
+func OverridesDir() string {
+	return filepath.Join(GetUserConfigDir(), "configs.d")
+}
+
+// LoadOverrides reads all overlay files
+// What: Parses configs.d/*.yaml (and *.yml) in lexical order
+// Why: Shared by the tools and setup loaders
+// Returns: Overlays (none if the directory doesn't exist), error if a file can't be parsed
+func LoadOverrides() ([]ConfigOverride, error) {
+	dir := OverridesDir()
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	overrides := make([]ConfigOverride, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read override %s: %w", file, err)
+		}
+		override := ConfigOverride{Path: file}
+		if err := yaml.Unmarshal(data, &override); err != nil {
+			return nil, fmt.Errorf("failed to parse override %s: %w", file, err)
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+// applyOverrides patches the tools list with every overlay in order
+// Params: overrides - loaded overlays
+// Returns: Error naming the overlay file if it references an unknown tool
+func (tc *ToolsConfig) applyOverrides(overrides []ConfigOverride) error {
+	for _, o := range overrides {
+		patch := o.Tools
+		if len(patch.Add) == 0 && len(patch.Disable) == 0 && len(patch.Timeouts) == 0 {
+			continue
+		}
+
+		for _, tool := range patch.Add {
+			if i := tc.toolIndex(tool.Name); i >= 0 {
+				tc.Tools[i] = tool
+			} else {
+				tc.Tools = append(tc.Tools, tool)
+			}
+		}
+
+		for _, name := range patch.Disable {
+			i := tc.toolIndex(name)
+			if i < 0 {
+				return fmt.Errorf("%s: cannot disable unknown tool %q", o.Path, name)
+			}
+			tc.Tools = append(tc.Tools[:i], tc.Tools[i+1:]...)
+			for j := range tc.Tools {
+				tc.Tools[j].DependsOn, tc.Tools[j].dependsOnLines = withoutDependency(tc.Tools[j].DependsOn, tc.Tools[j].dependsOnLines, NodeTool, NodeID(NodeTool, name))
+			}
+		}
+
+		for name, timeout := range patch.Timeouts {
+			i := tc.toolIndex(name)
+			if i < 0 {
+				return fmt.Errorf("%s: cannot set timeout of unknown tool %q", o.Path, name)
+			}
+			tc.Tools[i].Install.Timeout = timeout
+		}
+
+		tc.Overrides = append(tc.Overrides, o.Path)
+	}
+	return nil
+}
+
+// applyOverrides patches the setup task list with every overlay in order
+// Params: overrides - loaded overlays
+// Returns: Error naming the overlay file if it references an unknown task
+// Edge cases: Tools disabled by an overlay are also dropped from "tool:<name>" task dependencies
+func (sc *SetupConfig) applyOverrides(overrides []ConfigOverride) error {
+	for _, o := range overrides {
+		patch := o.Setup
+
+		for _, name := range o.Tools.Disable {
+			for j := range sc.SetupTasks {
+				sc.SetupTasks[j].DependsOn, sc.SetupTasks[j].dependsOnLines = withoutDependency(sc.SetupTasks[j].DependsOn, sc.SetupTasks[j].dependsOnLines, NodeTask, NodeID(NodeTool, name))
+			}
+		}
+
+		if len(patch.Add) == 0 && len(patch.Disable) == 0 && len(patch.Timeouts) == 0 {
+			continue
+		}
+
+		for _, task := range patch.Add {
+			if i := sc.taskIndex(task.Name); i >= 0 {
+				sc.SetupTasks[i] = task
+			} else {
+				sc.SetupTasks = append(sc.SetupTasks, task)
+			}
+		}
+
+		for _, name := range patch.Disable {
+			i := sc.taskIndex(name)
+			if i < 0 {
+				return fmt.Errorf("%s: cannot disable unknown setup task %q", o.Path, name)
+			}
+			sc.SetupTasks = append(sc.SetupTasks[:i], sc.SetupTasks[i+1:]...)
+			for j := range sc.SetupTasks {
+				sc.SetupTasks[j].DependsOn, sc.SetupTasks[j].dependsOnLines = withoutDependency(sc.SetupTasks[j].DependsOn, sc.SetupTasks[j].dependsOnLines, NodeTask, NodeID(NodeTask, name))
+			}
+		}
+
+		for name, timeout := range patch.Timeouts {
+			i := sc.taskIndex(name)
+			if i < 0 {
+				return fmt.Errorf("%s: cannot set timeout of unknown setup task %q", o.Path, name)
+			}
+			task := &sc.SetupTasks[i]
+			if task.Remote == nil && task.Local == nil {
+				return fmt.Errorf("%s: setup task %q has no remote/local command to time out", o.Path, name)
+			}
+			if task.Remote != nil {
+				remote := *task.Remote
+				remote.Timeout = timeout
+				task.Remote = &remote
+			}
+			if task.Local != nil {
+				local := *task.Local
+				local.Timeout = timeout
+				task.Local = &local
+			}
+		}
+
+		sc.Overrides = append(sc.Overrides, o.Path)
+	}
+	return nil
+}
+
+// toolIndex returns the index of the named tool, or -1
+func (tc *ToolsConfig) toolIndex(name string) int {
+	for i, tool := range tc.Tools {
+		if tool.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// taskIndex returns the index of the named setup task, or -1
+func (sc *SetupConfig) taskIndex(name string) int {
+	for i, task := range sc.SetupTasks {
+		if task.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// withoutDependency drops depends_on entries resolving to a removed node
+// Params: deps - depends_on entries, lines - their source lines, declaringKind - kind bare names resolve to, removed - node ID
+// Returns: Remaining entries and their lines
+func withoutDependency(deps []string, lines []int, declaringKind, removed string) ([]string, []int) {
+	var keptDeps []string
+	var keptLines []int
+	for i, dep := range deps {
+		if ParseDependency(dep, declaringKind) == removed {
+			continue
+		}
+		keptDeps = append(keptDeps, dep)
+		if i < len(lines) {
+			keptLines = append(keptLines, lines[i])
+		}
+	}
+	return keptDeps, keptLines
+}
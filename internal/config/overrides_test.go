@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadAppliesOverrides(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := t.TempDir()
+	toolsPath := filepath.Join(dir, "tools.yaml")
+	setupPath := filepath.Join(dir, "setup.yaml")
+	writeFile(t, toolsPath, `tools:
+  - name: homebrew
+    check: command -v brew
+    install: {command: install-brew}
+  - name: git
+    check: command -v git
+    install: {command: brew install git, timeout: 60s}
+    depends_on: [homebrew]
+`)
+	writeFile(t, setupPath, `setup_tasks:
+  - name: git-config
+    strategy: local_only
+    local: {command: git config --global init.defaultBranch main}
+    depends_on: ["tool:homebrew"]
+  - name: gh-auth
+    strategy: local_only
+    local: {command: gh auth status, timeout: 10s}
+    depends_on: [git-config]
+`)
+	writeFile(t, filepath.Join(home, ".config", "devsetup", "configs.d", "10-me.yaml"), `tools:
+  disable: [homebrew]
+  timeouts: {git: 5m}
+  add:
+    - name: jq
+      check: command -v jq
+      install: {command: brew install jq}
+setup:
+  disable: [git-config]
+  timeouts: {gh-auth: 1m}
+`)
+
+	tools, err := LoadToolsConfig(toolsPath)
+	if err != nil {
+		t.Fatalf("LoadToolsConfig: %v", err)
+	}
+	if len(tools.Tools) != 2 || tools.Tools[0].Name != "git" || tools.Tools[1].Name != "jq" {
+		t.Fatalf("tools = %+v", tools.Tools)
+	}
+	if len(tools.Tools[0].DependsOn) != 0 || tools.Tools[0].Install.Timeout != 5*time.Minute {
+		t.Errorf("git not patched: %+v", tools.Tools[0])
+	}
+	if len(tools.Overrides) != 1 {
+		t.Errorf("Overrides = %v", tools.Overrides)
+	}
+
+	setup, err := LoadSetupConfig(setupPath)
+	if err != nil {
+		t.Fatalf("LoadSetupConfig: %v", err)
+	}
+	if len(setup.SetupTasks) != 1 || len(setup.SetupTasks[0].DependsOn) != 0 || setup.SetupTasks[0].Local.Timeout != time.Minute {
+		t.Errorf("setup not patched: %+v", setup.SetupTasks)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
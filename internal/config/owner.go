@@ -0,0 +1,74 @@
+// File: internal/config/owner.go
+// Purpose: Owning team and Slack channel of a tool or setup task (owner: in tools.yaml and setup.yaml)
+// Problem: Every failure pointed users at the platform team, even for internal tools another team maintains,
+//          so questions were forwarded by hand and fixes waited on the wrong people
+// Role: Declares who to ask per tool/task, with a file-level default; failure summaries, report-issue, and
+//       wait name the owners of what failed
+// Usage: owner: {team: web-platform, slack: "#web-platform"} on a tool or task, or at the top of the file
+// Design choices: A tool's own owner wins over the file default; with neither, nothing is shown and the generic
+//                 support hint stands; the channel is only displayed, never contacted
+// Assumptions: Slack channels are written #channel and people @handle
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Owner names the team that maintains a tool or task and where to reach it
+type Owner struct {
+	// Team is the owning team's name
+	Team string `yaml:"team"`
+
+	// Slack is the channel (#channel) or person (@handle) to ask
+	Slack string `yaml:"slack"`
+}
+
+// String formats the owner for messages
+// Returns: "#channel (team)", "#channel", or "team"; "" for a nil owner
+func (o *Owner) String() string {
+	switch {
+	case o == nil:
+		return ""
+	case o.Slack != "" && o.Team != "":
+		return fmt.Sprintf("%s (%s)", o.Slack, o.Team)
+	case o.Slack != "":
+		return o.Slack
+	}
+	return o.Team
+}
+
+// OwnerOf returns who to ask about a tool
+// Returns: The tool's owner, else the tools.yaml default; nil if neither is set (tc may be nil)
+func (tc *ToolsConfig) OwnerOf(tool Tool) *Owner {
+	if tool.Owner != nil || tc == nil {
+		return tool.Owner
+	}
+	return tc.Owner
+}
+
+// OwnerOf returns who to ask about a setup task
+// Returns: The task's owner, else the setup.yaml default; nil if neither is set (sc may be nil)
+func (sc *SetupConfig) OwnerOf(task SetupTask) *Owner {
+	if task.Owner != nil || sc == nil {
+		return task.Owner
+	}
+	return sc.Owner
+}
+
+// validateOwner checks an owner declaration
+// Params: where - what declares it, for the error (e.g. "tool jq"), o - owner (nil is valid)
+// Returns: Error if neither team nor slack is set, or slack isn't a #channel or @handle
+func validateOwner(where string, o *Owner) error {
+	if o == nil {
+		return nil
+	}
+	if o.Team == "" && o.Slack == "" {
+		return fmt.Errorf("%s: owner needs a team or slack", where)
+	}
+	if o.Slack != "" && !strings.HasPrefix(o.Slack, "#") && !strings.HasPrefix(o.Slack, "@") {
+		return fmt.Errorf("%s: owner slack %q must be a #channel or @handle", where, o.Slack)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import "testing"
+
+func TestOwnerOf(t *testing.T) {
+	platform := &Owner{Team: "platform", Slack: "#platform-help"}
+	web := &Owner{Team: "web-platform", Slack: "#web-platform"}
+	tc := &ToolsConfig{Owner: platform}
+
+	if got := tc.OwnerOf(Tool{Name: "node", Owner: web}).String(); got != "#web-platform (web-platform)" {
+		t.Errorf("own owner = %q", got)
+	}
+	if got := tc.OwnerOf(Tool{Name: "jq"}).String(); got != "#platform-help (platform)" {
+		t.Errorf("default owner = %q", got)
+	}
+	var none *ToolsConfig
+	if got := none.OwnerOf(Tool{Name: "jq"}); got != nil {
+		t.Errorf("nil config = %v, want nil", got)
+	}
+	if got := (&SetupConfig{}).OwnerOf(SetupTask{Name: "vpn", Owner: &Owner{Slack: "@alice"}}).String(); got != "@alice" {
+		t.Errorf("task owner = %q", got)
+	}
+}
+
+func TestValidateOwner(t *testing.T) {
+	for _, tc := range []struct {
+		owner *Owner
+		ok    bool
+	}{
+		{nil, true},
+		{&Owner{Team: "web-platform"}, true},
+		{&Owner{Slack: "@alice"}, true},
+		{&Owner{}, false},
+		{&Owner{Team: "web", Slack: "web-platform"}, false},
+	} {
+		if err := validateOwner("tool node", tc.owner); (err == nil) != tc.ok {
+			t.Errorf("validateOwner(%+v) = %v, want ok=%t", tc.owner, err, tc.ok)
+		}
+	}
+}
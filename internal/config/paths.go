@@ -0,0 +1,34 @@
+// File: internal/config/paths.go
+// Purpose: Expansion of the ~ and $VAR paths configs are written with
+// Problem: Each package that read a path from the configs carried its own copy of the expansion, and the copies
+//          disagreed (some expanded $VARS, some a bare ~)
+// Role: The one expansion installer, setup, verify, and status use for config paths
+// Usage: dir := config.ExpandPath(archive.Dir); zshrc := config.ExpandHome("~/.zshrc")
+// Design choices: $VARS expand before ~ so "$DEV_HOME" can itself start with ~; only a leading ~ is expanded,
+//                 never ~user
+// Assumptions: The home directory is known; if it isn't, paths are returned unchanged
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandHome expands a leading ~ or ~/ to the home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// ExpandPath expands $VAR and ${VAR} references, then a leading ~
+func ExpandPath(path string) string {
+	return ExpandHome(os.ExpandEnv(path))
+}
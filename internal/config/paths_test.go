@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HOME", "/Users/dev")
+	t.Setenv("DEV_HOME", "~/src")
+
+	for in, want := range map[string]string{
+		"~":             "/Users/dev",
+		"~/.zshrc":      "/Users/dev/.zshrc",
+		"$DEV_HOME/app": "/Users/dev/src/app",
+		"${HOME}/bin":   "/Users/dev/bin",
+		"/opt/~/tools":  "/opt/~/tools",
+		"~other/.zshrc": "~other/.zshrc",
+		"relative/path": "relative/path",
+	} {
+		if got := ExpandPath(in); got != want {
+			t.Errorf("ExpandPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := ExpandHome("$HOME/.zshrc"); got != "$HOME/.zshrc" {
+		t.Errorf("ExpandHome expanded a variable: %q", got)
+	}
+}
@@ -0,0 +1,400 @@
+// File: internal/config/policy_config.go
+// Purpose: Data models for policies.yaml (organization security baselines)
+// Problem: Security baselines (disk encryption, firewall, screen lock, OS version) were checked by hand
+// Role: Provides Go structs for compliance policies evaluated by verify --policy and doctor
+// Usage: Loaded by verify, doctor, and install commands via LoadPolicyConfig
+// Design choices: Typed built-in policy kinds plus a generic command kind; glob-based software denylist
+// Assumptions: macOS host; built-in kinds map to macOS system commands
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy types understood by the policy evaluator
+const (
+	PolicyFileVault  = "filevault"
+	PolicyFirewall   = "firewall"
+	PolicyScreenLock = "screen_lock"
+	PolicyOSVersion  = "os_version"
+	PolicyCommand    = "command"
+)
+
+// Policy severities
+const (
+	SeverityRequired    = "required"
+	SeverityRecommended = "recommended"
+)
+
+// Denied software kinds
+const (
+	DeniedFormula = "formula"
+	DeniedCask    = "cask"
+	DeniedCommand = "command"
+)
+
+// PolicyConfig represents the complete policies.yaml file
+// What: Compliance policies and software denylist for the organization's security baseline
+// Why: Platform/security team declares baselines once; every laptop is checked the same way
+type PolicyConfig struct {
+	// Policies are the compliance checks to evaluate
+	Policies []Policy `yaml:"policies"`
+
+	// Denied lists software that must not be installed
+	Denied []DeniedEntry `yaml:"denied"`
+
+	// Allowed lists names exempt from denied patterns (e.g. the corporate VPN)
+	Allowed []string `yaml:"allowed"`
+
+	// Homebrew is the org Homebrew hardening baseline (nil = not managed)
+	Homebrew *HomebrewPolicy `yaml:"homebrew"`
+
+	// ChangeManagement gates devsetup apply on approved plans (nil = approval optional)
+	ChangeManagement *ChangeManagementPolicy `yaml:"change_management"`
+
+	// VersionManagers maps a language (node, python) to the org-standard version manager
+	VersionManagers map[string]string `yaml:"version_managers"`
+
+	// ShellStartup is the interactive zsh startup budget doctor checks (nil = DefaultShellStartupBudget)
+	ShellStartup *ShellStartupPolicy `yaml:"shell_startup"`
+
+	// Quarantine sets what installs may do with quarantined downloads (nil = keep, tools may choose)
+	Quarantine *QuarantinePolicy `yaml:"quarantine"`
+}
+
+// DefaultShellStartupBudget is the zsh startup budget when policies.yaml sets none
+const DefaultShellStartupBudget = 500 * time.Millisecond
+
+// ShellStartupPolicy represents the interactive shell startup budget
+// What: How long `zsh -i -c exit` may take before doctor flags it
+// Why: Every onboarding snippet added to .zshrc slows each new terminal tab a little
+type ShellStartupPolicy struct {
+	// Budget is the longest acceptable startup
+	Budget time.Duration `yaml:"budget"`
+}
+
+// ShellStartupBudget returns the zsh startup budget
+// Returns: shell_startup.budget, or DefaultShellStartupBudget when unset
+func (pc *PolicyConfig) ShellStartupBudget() time.Duration {
+	if pc == nil || pc.ShellStartup == nil || pc.ShellStartup.Budget <= 0 {
+		return DefaultShellStartupBudget
+	}
+	return pc.ShellStartup.Budget
+}
+
+// VersionManagerChoices lists the managers doctor recognizes per language
+// Why: "homebrew" means the runtime comes straight from brew with no version manager
+var VersionManagerChoices = map[string][]string{
+	"node":   {"homebrew", "nvm", "fnm", "volta"},
+	"python": {"homebrew", "uv", "pyenv", "conda"},
+}
+
+// ChangeManagementPolicy represents plan approval rules
+// What: Whether apply needs an approved plan, and who may approve
+// Why: Production-adjacent workstations change only through reviewed, approved plans
+type ChangeManagementPolicy struct {
+	// RequirePlanApproval makes apply refuse plans without a valid approval
+	RequirePlanApproval bool `yaml:"require_plan_approval"`
+
+	// Approvers are the people allowed to approve, with the SSH keys their approvals must be signed with
+	Approvers []PlanApprover `yaml:"approvers"`
+
+	// AllowSelfApproval lets the person applying a plan also approve it
+	AllowSelfApproval bool `yaml:"allow_self_approval"`
+}
+
+// PlanApprover represents one allowed plan approver
+type PlanApprover struct {
+	// Identity is the approver's git user.email
+	Identity string `yaml:"identity"`
+
+	// Key is the approver's SSH public key ("ssh-ed25519 AAAA...")
+	Key string `yaml:"key"`
+}
+
+// IsApprover reports whether an identity may approve plans
+// Params: who - approver identity (case-insensitive)
+// Returns: true if Approvers lists who (nil policy or no approvers allows nobody)
+func (cm *ChangeManagementPolicy) IsApprover(who string) bool {
+	if cm == nil {
+		return false
+	}
+	for _, a := range cm.Approvers {
+		if strings.EqualFold(a.Identity, who) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedSigners renders Approvers in ssh-keygen's allowed_signers format
+// What: One "identity namespaces=... key" line per approver (identity lowercased), for ssh-keygen -Y verify
+// Params: namespace - signature namespace the keys are valid for
+func (cm *ChangeManagementPolicy) AllowedSigners(namespace string) string {
+	if cm == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, a := range cm.Approvers {
+		fmt.Fprintf(&b, "%s namespaces=%q %s\n", strings.ToLower(a.Identity), namespace, strings.TrimSpace(a.Key))
+	}
+	return b.String()
+}
+
+// HomebrewPolicy represents the org Homebrew hardening baseline
+// What: Analytics, redirect, auto-update, and prefix ownership settings
+// Why: Applied by the homebrew_policy setup builtin and checked by doctor
+type HomebrewPolicy struct {
+	// Analytics is whether Homebrew analytics may stay enabled (nil = not managed)
+	Analytics *bool `yaml:"analytics"`
+
+	// NoInsecureRedirect sets HOMEBREW_NO_INSECURE_REDIRECT (refuse HTTPS → HTTP redirects)
+	NoInsecureRedirect bool `yaml:"no_insecure_redirect"`
+
+	// AutoUpdateInterval sets HOMEBREW_AUTO_UPDATE_SECS (0 = not managed)
+	AutoUpdateInterval time.Duration `yaml:"auto_update_interval"`
+
+	// PrefixOwnership requires Homebrew directories to be owned and writable by the user
+	PrefixOwnership bool `yaml:"prefix_ownership"`
+
+	// Severity is required (doctor error) or recommended (doctor warning)
+	Severity string `yaml:"severity"`
+}
+
+// IsRequired reports whether a failing Homebrew setting is an error
+// Returns: true unless severity is explicitly "recommended"
+func (hp HomebrewPolicy) IsRequired() bool {
+	return hp.Severity != SeverityRecommended
+}
+
+// DeniedEntry represents software forbidden by org policy
+// What: Glob pattern for a formula, cask, or command with the reason it's denied
+// Why: Unapproved VPNs and EOL runtimes must be flagged and never installed by devsetup
+type DeniedEntry struct {
+	// Name is a glob pattern (e.g. "node@1[0-6]", "*vpn*")
+	Name string `yaml:"name"`
+
+	// Kind is formula, cask, or command
+	Kind string `yaml:"kind"`
+
+	// Reason is shown to users when the entry matches
+	Reason string `yaml:"reason"`
+}
+
+// Policy represents a single compliance policy
+// What: Named check of a given type with type-specific parameters
+// Why: Each baseline item needs a check and, where possible, a guided fix
+type Policy struct {
+	// Name is the unique identifier for this policy
+	Name string `yaml:"name"`
+
+	// Description is human-readable description
+	Description string `yaml:"description"`
+
+	// Type selects the built-in check (filevault, firewall, screen_lock, os_version, command)
+	Type string `yaml:"type"`
+
+	// Severity is required (fails verify) or recommended (warning only)
+	Severity string `yaml:"severity"`
+
+	// MaxIdle is the maximum allowed idle time before screen lock (screen_lock)
+	MaxIdle time.Duration `yaml:"max_idle"`
+
+	// MinVersion is the minimum macOS version (os_version)
+	MinVersion string `yaml:"min_version"`
+
+	// Check is a shell command that exits 0 when compliant (command)
+	Check string `yaml:"check"`
+
+	// Fix is a shell command doctor may run to remediate (command)
+	Fix string `yaml:"fix"`
+
+	// Guidance is shown to the user when the policy can't be fixed automatically
+	Guidance string `yaml:"guidance"`
+}
+
+// LoadPolicyConfig loads and parses policies.yaml
+// What: Reads policies.yaml from filesystem or embedded, parses into PolicyConfig
+// Why: Main entry point for loading compliance policies
+// Params: path - path to policies.yaml (e.g., "configs/policies.yaml")
+// Returns: Parsed PolicyConfig and error if any
+// Example: cfg, err := LoadPolicyConfig("configs/policies.yaml")
+// Edge cases: Falls back to embedded if file not found on disk
+func LoadPolicyConfig(path string) (*PolicyConfig, error) {
+	// Try filesystem first (development)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Fall back to embedded (production)
+		data, err = readEmbeddedFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy config: %w", err)
+		}
+	}
+
+	var config PolicyConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse policy config: %w", err)
+	}
+
+	// Validate
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Validate checks if the policy configuration is valid
+// What: Validates names are unique, types are known, and type parameters are present
+// Why: Catch configuration errors before running compliance checks
+// Returns: Error describing validation failure, nil if valid
+func (pc *PolicyConfig) Validate() error {
+	names := make(map[string]bool)
+	for _, p := range pc.Policies {
+		if names[p.Name] {
+			return fmt.Errorf("duplicate policy name: %s", p.Name)
+		}
+		names[p.Name] = true
+
+		if p.Severity != "" && p.Severity != SeverityRequired && p.Severity != SeverityRecommended {
+			return fmt.Errorf("invalid severity for policy %s: %s", p.Name, p.Severity)
+		}
+
+		switch p.Type {
+		case PolicyFileVault, PolicyFirewall:
+		case PolicyScreenLock:
+			if p.MaxIdle <= 0 {
+				return fmt.Errorf("policy %s: max_idle is required for screen_lock", p.Name)
+			}
+		case PolicyOSVersion:
+			if p.MinVersion == "" {
+				return fmt.Errorf("policy %s: min_version is required for os_version", p.Name)
+			}
+		case PolicyCommand:
+			if p.Check == "" {
+				return fmt.Errorf("policy %s: check is required for command", p.Name)
+			}
+		default:
+			return fmt.Errorf("unknown type for policy %s: %s", p.Name, p.Type)
+		}
+	}
+
+	if hp := pc.Homebrew; hp != nil {
+		if hp.Severity != "" && hp.Severity != SeverityRequired && hp.Severity != SeverityRecommended {
+			return fmt.Errorf("invalid severity for homebrew: %s", hp.Severity)
+		}
+		if hp.AutoUpdateInterval < 0 || (hp.AutoUpdateInterval > 0 && hp.AutoUpdateInterval < time.Second) {
+			return fmt.Errorf("homebrew: auto_update_interval must be at least 1s")
+		}
+	}
+
+	if cm := pc.ChangeManagement; cm != nil {
+		for _, a := range cm.Approvers {
+			if a.Identity == "" || len(strings.Fields(a.Key)) < 2 {
+				return fmt.Errorf("change_management: approvers need an identity and an SSH public key")
+			}
+		}
+		if cm.RequirePlanApproval && len(cm.Approvers) == 0 {
+			return fmt.Errorf("change_management: require_plan_approval needs approvers to verify approvals against")
+		}
+	}
+
+	if ss := pc.ShellStartup; ss != nil && ss.Budget < 0 {
+		return fmt.Errorf("shell_startup: budget must not be negative")
+	}
+
+	if q := pc.Quarantine; q != nil {
+		if err := validateQuarantine("quarantine.default", q.Default); err != nil {
+			return err
+		}
+		for _, action := range q.Allow {
+			if err := validateQuarantine("quarantine.allow", action); err != nil {
+				return err
+			}
+		}
+	}
+
+	for lang, manager := range pc.VersionManagers {
+		choices, ok := VersionManagerChoices[lang]
+		if !ok {
+			return fmt.Errorf("version_managers: unknown language %s", lang)
+		}
+		if !slices.Contains(choices, manager) {
+			return fmt.Errorf("version_managers: unknown %s manager %s (choose from %s)", lang, manager, strings.Join(choices, ", "))
+		}
+	}
+
+	for _, d := range pc.Denied {
+		if d.Kind != DeniedFormula && d.Kind != DeniedCask && d.Kind != DeniedCommand {
+			return fmt.Errorf("invalid kind for denied entry %s: %s", d.Name, d.Kind)
+		}
+		if _, err := path.Match(d.Name, ""); err != nil {
+			return fmt.Errorf("invalid pattern for denied entry %s: %w", d.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// IsDenied checks a package name against the denylist
+// What: Matches name against denied patterns of the given kind, honoring the allowlist
+// Why: Shared by install (refuse), verify and doctor (flag presence)
+// Params: kind - formula, cask, or command; name - package or command name
+// Returns: Matching entry, or nil if the name is not denied
+// Example: if entry := cfg.IsDenied(DeniedCask, "openvpn-connect"); entry != nil { ... }
+func (pc *PolicyConfig) IsDenied(kind, name string) *DeniedEntry {
+	for _, allowed := range pc.Allowed {
+		if allowed == name {
+			return nil
+		}
+	}
+
+	for i := range pc.Denied {
+		d := &pc.Denied[i]
+		if d.Kind != kind {
+			continue
+		}
+		if ok, _ := path.Match(d.Name, name); ok {
+			return d
+		}
+	}
+	return nil
+}
+
+// DeniedTool checks whether installing a tool would add denied software
+// What: Checks the tool name (command kind) and brew packages in its install command
+// Why: Install must refuse denied software even when a user config requests it
+// Params: tool - Tool about to be installed
+// Returns: Matching entry and the offending package name, or nil
+func (pc *PolicyConfig) DeniedTool(tool Tool) (*DeniedEntry, string) {
+	if entry := pc.IsDenied(DeniedCommand, tool.Name); entry != nil {
+		return entry, tool.Name
+	}
+
+	formulas, casks := tool.Install.BrewPackages()
+	for _, f := range formulas {
+		if entry := pc.IsDenied(DeniedFormula, f); entry != nil {
+			return entry, f
+		}
+	}
+	for _, c := range casks {
+		if entry := pc.IsDenied(DeniedCask, c); entry != nil {
+			return entry, c
+		}
+	}
+	return nil, ""
+}
+
+// IsRequired reports whether a failing policy should fail verification
+// Returns: true unless severity is explicitly "recommended"
+func (p Policy) IsRequired() bool {
+	return p.Severity != SeverityRecommended
+}
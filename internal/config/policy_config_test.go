@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestDeniedTool(t *testing.T) {
+	pc := &PolicyConfig{
+		Denied: []DeniedEntry{
+			{Name: "node@1[0-6]", Kind: DeniedFormula, Reason: "EOL Node.js"},
+			{Name: "*vpn*", Kind: DeniedCask, Reason: "unapproved VPN"},
+			{Name: "telnet", Kind: DeniedCommand, Reason: "plaintext protocol"},
+		},
+		Allowed: []string{"corp-vpn"},
+	}
+
+	tests := []struct {
+		name    string
+		tool    Tool
+		wantPkg string
+	}{
+		{"denied command name", Tool{Name: "telnet"}, "telnet"},
+		{"denied formula in install", Tool{Name: "node", Install: ToolInstall{Command: "brew install --quiet node@14"}}, "node@14"},
+		{"denied cask after another step", Tool{Name: "vpn", Install: ToolInstall{Command: "brew update && brew install --cask free-vpn"}}, "free-vpn"},
+		{"allowlisted cask", Tool{Name: "vpn", Install: ToolInstall{Command: "brew install --cask corp-vpn"}}, ""},
+		{"formula outside the pattern", Tool{Name: "node", Install: ToolInstall{Command: "brew install node@20"}}, ""},
+		{"cask pattern only matches casks", Tool{Name: "vpn", Install: ToolInstall{Command: "brew install vpn-tools"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, pkg := pc.DeniedTool(tt.tool)
+			if pkg != tt.wantPkg || (entry != nil) != (tt.wantPkg != "") {
+				t.Errorf("DeniedTool() = %v, %q; want %q", entry, pkg, tt.wantPkg)
+			}
+		})
+	}
+}
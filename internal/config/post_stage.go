@@ -0,0 +1,94 @@
+// File: internal/config/post_stage.go
+// Purpose: Time-of-day deferral of the last install stages (tools.yaml post_stage section)
+// Problem: Bandwidth-heavy polish installs (IDEs, simulators, large casks) competed with a developer's first-day
+//          work when they ran right after the tools needed to get started
+// Role: Tells install which stages wait for the quiet time and when that is; install runs the stages before
+//       them and schedules the rest (see internal/deferred)
+// Usage: if until, ok := toolsCfg.PostStage.Deferred(time.Now()); ok { ... NewRunFilter(toolsCfg.PostStage.Before(), ...) }
+// Design choices: Stages are the install batch numbers --stages uses, so the deferred run is an ordinary
+//                 `install --stages`; an install started after defer_until runs everything at once
+// Assumptions: The quiet time is a local wall-clock time on the same day (no windows across midnight)
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PostStage represents the post_stage section of tools.yaml
+// What: First install stage to defer and the time of day deferred stages run at
+// Why: Polish installs move to the evening instead of slowing the morning
+type PostStage struct {
+	// From is the first deferred install stage (1-based, as in --stages); it and every later stage wait
+	From int `yaml:"from"`
+
+	// DeferUntil is the local time of day the deferred stages run at ("18:00")
+	DeferUntil string `yaml:"defer_until"`
+}
+
+// Validate checks the stage number and the time of day
+// Returns: Error describing the invalid field, nil if valid (nil receiver included)
+func (p *PostStage) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.From < 2 {
+		return fmt.Errorf("post_stage: from must be 2 or later (stage 1 always runs)")
+	}
+	if _, _, err := p.clock(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Deferred reports whether deferred stages have to wait
+// Params: now - current time
+// Returns: Today's defer_until time, and true if now is before it (false for a nil receiver)
+func (p *PostStage) Deferred(now time.Time) (time.Time, bool) {
+	if p == nil {
+		return time.Time{}, false
+	}
+	hour, minute, err := p.clock()
+	if err != nil {
+		return time.Time{}, false
+	}
+	until := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	return until, now.Before(until)
+}
+
+// Before returns the --stages value of the stages that don't wait
+// Example: from: 3 → "1,2"
+func (p *PostStage) Before() string {
+	return stageRange(1, p.From-1)
+}
+
+// After returns the --stages value of the deferred stages
+// Params: total - number of install stages (len(installer.Stages(...)))
+// Returns: e.g. "3,4,5" for from: 3 of 5; empty when from is past the last stage
+func (p *PostStage) After(total int) string {
+	return stageRange(p.From, total)
+}
+
+// clock parses defer_until
+// Returns: Hour and minute, error unless it is HH:MM on a 24-hour clock
+func (p *PostStage) clock() (int, int, error) {
+	hh, mm, ok := strings.Cut(p.DeferUntil, ":")
+	hour, errH := strconv.Atoi(hh)
+	minute, errM := strconv.Atoi(mm)
+	if !ok || errH != nil || errM != nil || len(mm) != 2 || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("post_stage: defer_until %q must be a time of day like \"18:00\"", p.DeferUntil)
+	}
+	return hour, minute, nil
+}
+
+// stageRange returns "first,...,last" (empty when last < first)
+func stageRange(first, last int) string {
+	var stages []string
+	for n := first; n <= last; n++ {
+		stages = append(stages, strconv.Itoa(n))
+	}
+	return strings.Join(stages, ",")
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPostStageValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		stage   *PostStage
+		wantErr bool
+	}{
+		{"evening", &PostStage{From: 3, DeferUntil: "18:00"}, false},
+		{"midnight", &PostStage{From: 2, DeferUntil: "00:00"}, false},
+		{"last minute", &PostStage{From: 2, DeferUntil: "23:59"}, false},
+		{"unset", nil, false},
+		{"first stage", &PostStage{From: 1, DeferUntil: "18:00"}, true},
+		{"12-hour clock", &PostStage{From: 3, DeferUntil: "6pm"}, true},
+		{"hour 24", &PostStage{From: 3, DeferUntil: "24:00"}, true},
+		{"minute 60", &PostStage{From: 3, DeferUntil: "18:60"}, true},
+		{"one-digit minute", &PostStage{From: 3, DeferUntil: "18:0"}, true},
+		{"negative hour", &PostStage{From: 3, DeferUntil: "-1:00"}, true},
+		{"seconds", &PostStage{From: 3, DeferUntil: "18:00:00"}, true},
+		{"empty", &PostStage{From: 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.stage.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostStageDeferred(t *testing.T) {
+	day := func(hour, minute int) time.Time { return time.Date(2026, 10, 19, hour, minute, 0, 0, time.Local) }
+	evening := &PostStage{From: 3, DeferUntil: "18:00"}
+	tests := []struct {
+		name      string
+		stage     *PostStage
+		now       time.Time
+		wantUntil time.Time
+		want      bool
+	}{
+		{"morning", evening, day(9, 30), day(18, 0), true},
+		{"a minute before", evening, day(17, 59), day(18, 0), true},
+		{"at the quiet time", evening, day(18, 0), day(18, 0), false},
+		{"after the quiet time", evening, day(18, 30), day(18, 0), false},
+		{"minutes", &PostStage{From: 2, DeferUntil: "07:45"}, day(7, 44), day(7, 45), true},
+		{"unset", nil, day(9, 30), time.Time{}, false},
+		{"invalid time", &PostStage{From: 3, DeferUntil: "6pm"}, day(9, 30), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, ok := tt.stage.Deferred(tt.now)
+			if ok != tt.want || !until.Equal(tt.wantUntil) {
+				t.Errorf("Deferred(%s) = %v, %v; want %v, %v", tt.now.Format("15:04"), until, ok, tt.wantUntil, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostStageRanges(t *testing.T) {
+	tests := []struct {
+		from, total int
+		before      string
+		after       string
+	}{
+		{from: 3, total: 5, before: "1,2", after: "3,4,5"},
+		{from: 2, total: 2, before: "1", after: "2"},
+		{from: 5, total: 5, before: "1,2,3,4", after: "5"},
+		{from: 3, total: 2, before: "1,2", after: ""},
+	}
+	for _, tt := range tests {
+		p := &PostStage{From: tt.from, DeferUntil: "18:00"}
+		if before, after := p.Before(), p.After(tt.total); before != tt.before || after != tt.after {
+			t.Errorf("from %d of %d: Before, After = %q, %q; want %q, %q", tt.from, tt.total, before, after, tt.before, tt.after)
+		}
+	}
+}
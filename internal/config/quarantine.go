@@ -0,0 +1,72 @@
+// File: internal/config/quarantine.go
+// Purpose: Quarantine (Gatekeeper) settings for tools.yaml and the org policy that bounds them
+// Problem: Binaries and apps downloaded by installs carry com.apple.quarantine, so their first run stops on a
+//          Gatekeeper prompt (or is blocked outright) in the middle of someone's onboarding
+// Role: Declares what an install does with quarantined files (keep, clear, approve) per tool, with policies.yaml
+//       choosing the default and which actions are allowed at all
+// Usage: action, err := policies.QuarantineAction(tool.Quarantine)
+// Design choices: Keeping quarantine is the default (Gatekeeper stays in charge unless someone chose otherwise);
+//                 security decides which actions exist, tools.yaml authors only pick among them
+// Assumptions: Actions are carried out by the quarantine package on macOS
+
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Quarantine actions for installed files
+const (
+	// QuarantineKeep leaves the quarantine attribute alone (Gatekeeper checks on first run)
+	QuarantineKeep = "keep"
+	// QuarantineClear removes com.apple.quarantine from installed files
+	QuarantineClear = "clear"
+	// QuarantineApprove adds installed files to Gatekeeper's allowed list with spctl
+	QuarantineApprove = "approve"
+)
+
+// QuarantineActions lists the valid actions
+var QuarantineActions = []string{QuarantineKeep, QuarantineClear, QuarantineApprove}
+
+// QuarantinePolicy represents the org rules for quarantined installs
+// What: Default action and the actions tools may request
+// Why: Clearing quarantine bypasses a macOS protection, so security decides whether installs may do it
+type QuarantinePolicy struct {
+	// Default is the action for tools that don't set quarantine (empty = keep)
+	Default string `yaml:"default"`
+
+	// Allow lists the actions tools may request (empty = all)
+	Allow []string `yaml:"allow"`
+}
+
+// QuarantineAction resolves what to do with a tool's quarantined files
+// Params: requested - the tool's quarantine setting ("" = policy default)
+// Returns: The action; error if the policy doesn't allow the requested action
+// Edge cases: A nil policy config keeps quarantine unless the tool asks otherwise; keep is always allowed
+func (pc *PolicyConfig) QuarantineAction(requested string) (string, error) {
+	var policy QuarantinePolicy
+	if pc != nil && pc.Quarantine != nil {
+		policy = *pc.Quarantine
+	}
+	action := requested
+	if action == "" {
+		action = policy.Default
+	}
+	if action == "" || action == QuarantineKeep {
+		return QuarantineKeep, nil
+	}
+	if len(policy.Allow) > 0 && !slices.Contains(policy.Allow, action) {
+		return QuarantineKeep, fmt.Errorf("quarantine %s is not allowed by org policy (allowed: %v)", action, policy.Allow)
+	}
+	return action, nil
+}
+
+// validateQuarantine checks a quarantine action name
+// Params: what - where it is set (for the error), action - the value ("" allowed)
+func validateQuarantine(what, action string) error {
+	if action != "" && !slices.Contains(QuarantineActions, action) {
+		return fmt.Errorf("%s: invalid quarantine %s (expected keep, clear, or approve)", what, action)
+	}
+	return nil
+}
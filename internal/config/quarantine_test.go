@@ -0,0 +1,29 @@
+package config
+
+import "testing"
+
+func TestQuarantineAction(t *testing.T) {
+	var none *PolicyConfig
+	if action, err := none.QuarantineAction(""); err != nil || action != QuarantineKeep {
+		t.Errorf("no policy, unset = %s, %v; want keep", action, err)
+	}
+	if action, err := none.QuarantineAction(QuarantineClear); err != nil || action != QuarantineClear {
+		t.Errorf("no policy, clear = %s, %v; want clear", action, err)
+	}
+
+	pc := &PolicyConfig{Quarantine: &QuarantinePolicy{Default: QuarantineApprove, Allow: []string{QuarantineApprove}}}
+	if action, err := pc.QuarantineAction(""); err != nil || action != QuarantineApprove {
+		t.Errorf("policy default = %s, %v; want approve", action, err)
+	}
+	if action, err := pc.QuarantineAction(QuarantineClear); err == nil || action != QuarantineKeep {
+		t.Errorf("disallowed clear = %s, %v; want keep with error", action, err)
+	}
+	if action, err := pc.QuarantineAction(QuarantineKeep); err != nil || action != QuarantineKeep {
+		t.Errorf("keep = %s, %v; want keep (always allowed)", action, err)
+	}
+
+	pc.Quarantine.Allow = []string{"wipe"}
+	if err := pc.Validate(); err == nil {
+		t.Error("Validate() accepted an unknown quarantine action")
+	}
+}
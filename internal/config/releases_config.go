@@ -0,0 +1,128 @@
+// File: internal/config/releases_config.go
+// Purpose: Data model for releases.yaml (where devsetup finds its own releases)
+// Problem: The updater only knew github.com/rkinnovate/dev-setup, so teams mirroring releases on an internal GitLab
+//          or a plain HTTPS file server had to patch the binary to update from the mirror
+// Role: Names the release provider (GitHub, GitLab, or a static releases.json index), its base URL and repo, and
+//       the environment variable holding an access token; used by update, changelog, the new-version notice,
+//       self check, and sync init's owner/repo shorthand
+// Usage: rc, err := LoadReleasesConfig("configs/releases.yaml"); updater.SetSource(rc)
+// Design choices: One source per binary rather than a fallback chain, so a mirror can't be silently bypassed; the
+//                 token is read from the environment at request time and never stored in the file
+// Assumptions: GitLab is v15.4+ (releases/permalink/latest); index files use the GitHub release JSON shape
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Release providers
+const (
+	// ProviderGitHub reads releases from the GitHub REST API
+	ProviderGitHub = "github"
+
+	// ProviderGitLab reads releases from the GitLab REST API (gitlab.com or self-hosted)
+	ProviderGitLab = "gitlab"
+
+	// ProviderIndex reads releases from a static JSON file (GitHub release objects) on any HTTPS server
+	ProviderIndex = "index"
+)
+
+// ReleasesConfig represents releases.yaml
+// What: Provider, base URL, repository, and token variable of the release source
+// Why: Mirrors are a config change, not a rebuild
+type ReleasesConfig struct {
+	// Provider is github, gitlab, or index (default github)
+	Provider string `yaml:"provider"`
+
+	// URL is the API base (github: https://api.github.com), the GitLab instance, or the index file
+	URL string `yaml:"url"`
+
+	// Repo is owner/name (GitHub) or group/project (GitLab); unused by index
+	Repo string `yaml:"repo"`
+
+	// TokenEnv names the environment variable with an access token for private mirrors (optional)
+	TokenEnv string `yaml:"token_env"`
+}
+
+// DefaultReleasesConfig returns the public GitHub source
+// Returns: github.com/rkinnovate/dev-setup through api.github.com
+func DefaultReleasesConfig() *ReleasesConfig {
+	return &ReleasesConfig{Provider: ProviderGitHub, URL: "https://api.github.com", Repo: "rkinnovate/dev-setup"}
+}
+
+// LoadReleasesConfig loads and parses releases.yaml
+// What: Reads releases.yaml from filesystem or embedded, fills defaults, validates
+// Why: Main entry point for choosing the release source
+// Params: path - path to releases.yaml (e.g., "configs/releases.yaml")
+// Returns: Parsed ReleasesConfig and error if any
+// Example: rc, err := LoadReleasesConfig("configs/releases.yaml")
+// Edge cases: Missing everywhere → DefaultReleasesConfig; a GitHub source without url uses api.github.com
+func LoadReleasesConfig(path string) (*ReleasesConfig, error) {
+	// Try filesystem first (development)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Fall back to embedded (production)
+		data, err = readEmbeddedFile(path)
+		if err != nil {
+			return DefaultReleasesConfig(), nil
+		}
+	}
+
+	var config ReleasesConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse releases config: %w", err)
+	}
+	if config.Provider == "" {
+		config.Provider = ProviderGitHub
+	}
+	if config.Provider == ProviderGitHub && config.URL == "" {
+		config.URL = DefaultReleasesConfig().URL
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid releases config: %w", err)
+	}
+	return &config, nil
+}
+
+// Validate checks if the releases configuration is valid
+// What: Known provider, absolute http(s) URL, owner/name repo where the provider needs one
+// Why: A typo would otherwise surface as a confusing 404 on the next update
+// Returns: Error describing validation failure, nil if valid
+func (rc *ReleasesConfig) Validate() error {
+	switch rc.Provider {
+	case ProviderGitHub, ProviderGitLab, ProviderIndex, "":
+	default:
+		return fmt.Errorf("unknown provider %q (use github, gitlab, or index)", rc.Provider)
+	}
+
+	if rc.URL != "" || rc.Provider != ProviderGitHub && rc.Provider != "" {
+		u, err := url.Parse(rc.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%s: url %q must be an absolute http(s) URL", rc.Provider, rc.URL)
+		}
+	}
+
+	if rc.Provider != ProviderIndex {
+		owner, name, ok := strings.Cut(rc.Repo, "/")
+		if !ok || owner == "" || name == "" || strings.HasSuffix(name, "/") {
+			return fmt.Errorf("%s: repo %q must be owner/name", rc.Provider, rc.Repo)
+		}
+	}
+	return nil
+}
+
+// Token returns the access token from TokenEnv
+// Returns: Token, "" when TokenEnv is unset or empty
+func (rc *ReleasesConfig) Token() string {
+	if rc.TokenEnv == "" {
+		return ""
+	}
+	return os.Getenv(rc.TokenEnv)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadReleasesConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	rc, err := LoadReleasesConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil || rc.Provider != ProviderGitHub || rc.Repo != "rkinnovate/dev-setup" {
+		t.Errorf("missing file = %+v, %v; want GitHub default", rc, err)
+	}
+
+	path := filepath.Join(dir, "releases.yaml")
+	writeFile(t, path, "repo: team/dev-setup\n")
+	if rc, err := LoadReleasesConfig(path); err != nil || rc.Provider != ProviderGitHub || rc.URL != "https://api.github.com" {
+		t.Errorf("provider defaults = %+v, %v", rc, err)
+	}
+
+	for yaml, want := range map[string]string{
+		"provider: bitbucket\nrepo: a/b\n":                             "unknown provider",
+		"provider: gitlab\nrepo: a/b\n":                                "absolute http(s) URL",
+		"provider: gitlab\nurl: https://gitlab.example.com\nrepo: b\n": "owner/name",
+		"provider: index\nurl: releases.json\n":                        "absolute http(s) URL",
+	} {
+		writeFile(t, path, yaml)
+		if _, err := LoadReleasesConfig(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadReleasesConfig(%q) = %v, want %q", yaml, err, want)
+		}
+	}
+
+	writeFile(t, path, "provider: index\nurl: https://dl.example.com/devsetup/releases.json\ntoken_env: DL_TOKEN\n")
+	t.Setenv("DL_TOKEN", "abc")
+	if rc, err := LoadReleasesConfig(path); err != nil || rc.Token() != "abc" {
+		t.Errorf("index source = %+v, %v", rc, err)
+	}
+}
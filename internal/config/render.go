@@ -0,0 +1,362 @@
+// File: internal/config/render.go
+// Purpose: Fully resolved preview of tools.yaml or setup.yaml for a described machine (devsetup config render)
+// Problem: What a config change does only showed up when a Mac ran it: overlays from team profiles, canary
+//          rollouts, typed installs, and $HOME/{brew_prefix}/{value} expansion all happen at load or run time
+// Role: Loads a config the way a machine described by a vars file would, expands what devsetup expands, and
+//       prints the result as YAML, so PR CI can diff it or assert on it without executing anything
+// Usage: out, err := config.Render("configs/setup.yaml", vars) with vars from LoadRenderVars("vars.yaml")
+// Design choices: Hermetic: the user's configs.d, hostname, and machine ID are never read, only the vars file, so
+//                 a render is the same on every runner; shell commands are printed verbatim because the shell, not
+//                 devsetup, expands them; unknown $VARS stay as ${VAR} so gaps in the vars file are visible;
+//                 empty fields are left out so the output reads like the source
+// Assumptions: defaults.yaml (team profiles) sits next to the rendered file or is embedded
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenderVars describes the machine a config is rendered for (vars.yaml)
+type RenderVars struct {
+	// Facts identify the machine
+	Facts RenderFacts `yaml:"facts"`
+
+	// Profiles are defaults.yaml profiles applied as overlays, in order
+	Profiles []string `yaml:"profiles"`
+
+	// Answers are prompt answers keyed by the prompt's env_var
+	Answers map[string]string `yaml:"answers"`
+
+	// Env are environment variables for $VAR expansion (HOME and USER come from facts)
+	Env map[string]string `yaml:"env"`
+}
+
+// RenderFacts are the machine facts a render depends on
+type RenderFacts struct {
+	// User is the login name (rollout users, $USER); default "dev"
+	User string `yaml:"user"`
+
+	// Host is the hostname (rollout hosts); default "ci"
+	Host string `yaml:"host"`
+
+	// Bucket places the machine for percentage rollouts, 0-99; default 99 (only full rollouts)
+	Bucket *int `yaml:"bucket"`
+
+	// Home is the home directory ($HOME, ~/); default /Users/<user>
+	Home string `yaml:"home"`
+
+	// BrewPrefix replaces {brew_prefix}; default /opt/homebrew
+	BrewPrefix string `yaml:"brew_prefix"`
+}
+
+// LoadRenderVars reads a vars file
+// Params: path - vars.yaml, or "" for the defaults
+// Returns: Vars with defaults filled in, error if the file can't be read or the bucket is out of range
+func LoadRenderVars(path string) (*RenderVars, error) {
+	vars := &RenderVars{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vars: %w", err)
+		}
+		if err := yaml.Unmarshal(data, vars); err != nil {
+			return nil, fmt.Errorf("failed to parse vars %s: %w", path, err)
+		}
+	}
+	if err := vars.setDefaults(); err != nil {
+		return nil, fmt.Errorf("invalid vars %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// setDefaults fills in unset facts
+func (v *RenderVars) setDefaults() error {
+	f := &v.Facts
+	if f.User == "" {
+		f.User = "dev"
+	}
+	if f.Host == "" {
+		f.Host = "ci"
+	}
+	if f.Bucket == nil {
+		last := 99
+		f.Bucket = &last
+	}
+	if *f.Bucket < 0 || *f.Bucket > 99 {
+		return fmt.Errorf("bucket %d out of range (0-99)", *f.Bucket)
+	}
+	if f.Home == "" {
+		f.Home = "/Users/" + f.User
+	}
+	if f.BrewPrefix == "" {
+		f.BrewPrefix = "/opt/homebrew"
+	}
+	return nil
+}
+
+// machine returns the rollout identity the facts describe
+func (v *RenderVars) machine() Machine {
+	return Machine{User: v.Facts.User, Host: strings.TrimSuffix(strings.ToLower(v.Facts.Host), ".local"), Bucket: *v.Facts.Bucket}
+}
+
+// Render resolves a tools.yaml or setup.yaml for the machine vars describe
+// What: Applies the vars' profiles, rollouts, and typed installs, validates, expands paths and prompt formats, and marshals
+// Why: Config authors see (and CI can diff) what a change does on a given machine without running it
+// Params: path - config file (read from disk, else embedded), vars - LoadRenderVars result
+// Returns: YAML document headed by a comment naming the machine and anything held back; error if the config is invalid
+// Example: out, _ := Render("configs/tools.yaml", vars)
+// Edge cases: The file's kind comes from its top-level key (tools or setup_tasks), not its name
+func Render(path string, vars *RenderVars) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if data, err = readEmbeddedFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	var top map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &top); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	defaults, err := LoadTeamDefaults(filepath.Join(filepath.Dir(path), "defaults.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	overlays, err := defaults.Overlays(vars.Profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		resolved any
+		heldBack []string
+	)
+	switch {
+	case hasKey(top, "tools"):
+		var tc ToolsConfig
+		if err := yaml.Unmarshal(data, &tc); err != nil {
+			return nil, fmt.Errorf("failed to parse tools config: %w", err)
+		}
+		tc.Path = path
+		if err := tc.resolve(overlays, vars.machine); err != nil {
+			return nil, err
+		}
+		vars.expandTools(&tc)
+		resolved, heldBack = &tc, tc.HeldBack
+	case hasKey(top, "setup_tasks"):
+		var sc SetupConfig
+		if err := yaml.Unmarshal(data, &sc); err != nil {
+			return nil, fmt.Errorf("failed to parse setup config: %w", err)
+		}
+		sc.Path = path
+		if err := sc.resolve(overlays, vars.machine); err != nil {
+			return nil, err
+		}
+		vars.expandSetup(&sc)
+		resolved, heldBack = &sc, sc.HeldBack
+	default:
+		return nil, fmt.Errorf("%s: neither tools.yaml (tools:) nor setup.yaml (setup_tasks:)", path)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(resolved); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", path, err)
+	}
+	pruneEmpty(&node)
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", path, err)
+	}
+
+	f := vars.Facts
+	header := fmt.Sprintf("# %s rendered for %s@%s (bucket %d, home %s)\n", path, f.User, f.Host, *f.Bucket, f.Home)
+	if len(vars.Profiles) > 0 {
+		header += "# profiles: " + strings.Join(vars.Profiles, ", ") + "\n"
+	}
+	if len(heldBack) > 0 {
+		header += "# held back (rollout): " + strings.Join(heldBack, ", ") + "\n"
+	}
+	return append([]byte(header), out.Bytes()...), nil
+}
+
+// Overlays returns named profiles as overlays
+// Params: names - profile names, applied in this order
+// Returns: Overlays whose Path names the profile, error for an unknown profile
+func (d *TeamDefaults) Overlays(names []string) ([]ConfigOverride, error) {
+	overlays := make([]ConfigOverride, 0, len(names))
+	for _, name := range names {
+		node, ok := d.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %s (not in defaults.yaml)", name)
+		}
+		override := ConfigOverride{Path: "profile " + name}
+		if err := node.Decode(&override); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		overlays = append(overlays, override)
+	}
+	return overlays, nil
+}
+
+// expandTools expands the fields install expands: creates, archive targets, and PATH entries
+func (v *RenderVars) expandTools(tc *ToolsConfig) {
+	for i := range tc.Tools {
+		tool := &tc.Tools[i]
+		tool.Creates = v.expandPath(tool.Creates)
+		if tool.Install.Archive != nil {
+			tool.Install.Archive.Target = v.expandPath(tool.Install.Archive.Target)
+		}
+		for j, dir := range tool.Path {
+			tool.Path[j] = v.expandPath(strings.ReplaceAll(dir, "{brew_prefix}", v.Facts.BrewPrefix))
+		}
+	}
+}
+
+// expandSetup expands the fields setup and verify expand: paths, and {value} in prompt formats
+// Edge cases: Answers to secret prompts are shown as ***
+func (v *RenderVars) expandSetup(sc *SetupConfig) {
+	for i := range sc.SetupTasks {
+		task := &sc.SetupTasks[i]
+		for j := range task.Steps {
+			step := &task.Steps[j]
+			step.Creates = v.expandPath(step.Creates)
+			if step.EditToml != nil {
+				step.EditToml.File = v.expandPath(step.EditToml.File)
+			}
+		}
+		if p := task.Prompt; p != nil {
+			p.AddTo = v.expandPath(p.AddTo)
+			if answer, ok := v.Answers[p.EnvVar]; ok {
+				if p.Secret {
+					answer = "***"
+				}
+				p.Format = strings.ReplaceAll(p.Format, "{value}", answer)
+			}
+		}
+		for j := range task.Profiles {
+			task.Profiles[j].Path = v.expandPath(task.Profiles[j].Path)
+		}
+		if pg := task.Postgres; pg != nil {
+			pg.Seed = v.expandPath(pg.Seed)
+		}
+		if tls := task.DevTLS; tls != nil {
+			tls.Dir = v.expandPath(tls.Dir)
+		}
+		for j := range task.GitIdentities {
+			v.expandIdentity(&task.GitIdentities[j])
+		}
+		if gh := task.GitHooks; gh != nil {
+			gh.Config = v.expandPath(gh.Config)
+			for j, repo := range gh.Repos {
+				gh.Repos[j] = v.expandPath(repo)
+			}
+		}
+		if wc := task.WarmCaches; wc != nil {
+			for j, repo := range wc.Repos {
+				wc.Repos[j] = v.expandPath(repo)
+			}
+		}
+		for j := range task.Verify {
+			check := &task.Verify[j]
+			check.FileExists = v.expandPath(check.FileExists)
+			if check.FileContains != nil {
+				check.FileContains.Path = v.expandPath(check.FileContains.Path)
+			}
+			if check.TomlValue != nil {
+				check.TomlValue.File = v.expandPath(check.TomlValue.File)
+			}
+			if check.TLSCert != nil {
+				check.TLSCert.File = v.expandPath(check.TLSCert.File)
+			}
+			if check.JSONValue != nil {
+				check.JSONValue.File = v.expandPath(check.JSONValue.File)
+			}
+			if check.GitHook != nil {
+				check.GitHook.Repo = v.expandPath(check.GitHook.Repo)
+			}
+			if check.GitIdentity != nil {
+				v.expandIdentity(check.GitIdentity)
+			}
+		}
+	}
+}
+
+// expandIdentity expands a git identity's key and directory paths
+func (v *RenderVars) expandIdentity(id *GitIdentity) {
+	id.Key = v.expandPath(id.Key)
+	for k, dir := range id.Dirs {
+		id.Dirs[k] = v.expandPath(dir)
+	}
+}
+
+// expandPath expands $VARS and a leading ~ the way the executors do, with the vars' facts and env
+func (v *RenderVars) expandPath(path string) string {
+	path = os.Expand(path, func(name string) string {
+		switch {
+		case name == "HOME":
+			return v.Facts.Home
+		case name == "USER":
+			return v.Facts.User
+		}
+		if value, ok := v.Env[name]; ok {
+			return value
+		}
+		return "${" + name + "}"
+	})
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = v.Facts.Home + path[1:]
+	}
+	return path
+}
+
+// hasKey reports whether a top-level key is present
+func hasKey(top map[string]yaml.Node, key string) bool {
+	_, ok := top[key]
+	return ok
+}
+
+// pruneEmpty drops mapping entries whose value is null, "", false, 0, 0s, or an empty mapping or sequence
+func pruneEmpty(node *yaml.Node) {
+	for _, child := range node.Content {
+		pruneEmpty(child)
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	kept := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !isEmpty(node.Content[i+1]) {
+			kept = append(kept, node.Content[i], node.Content[i+1])
+		}
+	}
+	node.Content = kept
+}
+
+// isEmpty reports whether a node holds a zero value
+func isEmpty(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!null":
+			return true
+		case "!!bool":
+			return node.Value == "false"
+		case "!!int":
+			return node.Value == "0"
+		case "!!str":
+			return node.Value == "" || node.Value == "0s"
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	// configs.d of the running user must not leak into a render
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".config", "devsetup", "configs.d", "10-me.yaml"), "tools:\n  disable: [jq]\n")
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "tools.yaml"), `tools:
+  - name: jq
+    check: command -v jq
+    install: {type: brew, package: {name: jq}}
+    path: ["{brew_prefix}/bin"]
+  - name: zed
+    check: command -v zed
+    install: {command: brew install --cask zed}
+    rollout: {percent: 10}
+`)
+	writeFile(t, filepath.Join(dir, "setup.yaml"), `setup_tasks:
+  - name: api-key
+    prompt:
+      message: "Key?"
+      env_var: API_KEY
+      add_to: $HOME/.zshrc
+      format: 'export API_KEY="{value}"'
+      secret: true
+  - name: workspace
+    strategy: local_only
+    install: ["mkdir -p $WORKSPACE"]
+    verify:
+      - file_exists: $WORKSPACE/.keep
+`)
+	writeFile(t, filepath.Join(dir, "defaults.yaml"), `profiles:
+  mobile:
+    tools:
+      add:
+        - name: pod
+          check: command -v pod
+          install: {command: brew install cocoapods}
+`)
+	varsPath := filepath.Join(dir, "vars.yaml")
+	writeFile(t, varsPath, `facts: {user: alice, bucket: 50}
+profiles: [mobile]
+answers: {API_KEY: hunter2}
+`)
+	vars, err := LoadRenderVars(varsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Render(filepath.Join(dir, "tools.yaml"), vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := string(out)
+	for _, want := range []string{"rendered for alice@ci (bucket 50, home /Users/alice)", "held back (rollout): zed", "name: jq", "command: brew install jq", "/opt/homebrew/bin", "name: pod"} {
+		if !strings.Contains(tools, want) {
+			t.Errorf("tools render missing %q:\n%s", want, tools)
+		}
+	}
+	if strings.Contains(tools, "required: false") {
+		t.Errorf("empty fields not pruned:\n%s", tools)
+	}
+
+	out, err = Render(filepath.Join(dir, "setup.yaml"), vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup := string(out)
+	for _, want := range []string{"add_to: /Users/alice/.zshrc", `export API_KEY="***"`, "file_exists: ${WORKSPACE}/.keep", "mkdir -p $WORKSPACE"} {
+		if !strings.Contains(setup, want) {
+			t.Errorf("setup render missing %q:\n%s", want, setup)
+		}
+	}
+
+	vars.Profiles = []string{"web"}
+	if _, err := Render(filepath.Join(dir, "tools.yaml"), vars); err == nil || !strings.Contains(err.Error(), "unknown profile web") {
+		t.Errorf("Render() with unknown profile = %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+// File: internal/config/resolver.go
+// Purpose: Unified dependency resolution across tools.yaml and setup.yaml
+// Problem: Tools and setup tasks had separate depends_on worlds, so tasks got configured before their tool was installed
+// Role: Builds one dependency graph of tools and setup tasks and orders it globally
+// Usage: graph, err := BuildDependencyGraph(toolsCfg, setupCfg); nodes, err := graph.Order()
+// Design choices: Node IDs are "tool:<name>" / "task:<name>"; bare names resolve within their own file; deterministic Kahn's sort;
+//                 cycles reported as a path with file:line of each offending depends_on entry
+// Assumptions: Tools only depend on tools (install runs before setup); setup tasks may depend on either
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Node kinds in the dependency graph
+const (
+	NodeTool = "tool"
+	NodeTask = "task"
+)
+
+// DependencyNode is a tool or setup task in the unified graph
+// What: Kind, name, and resolved dependency IDs of one graph node
+// Why: Lets setup tasks declare "tool:gh" and be ordered after the tool
+type DependencyNode struct {
+	// ID is "tool:<name>" or "task:<name>"
+	ID string
+
+	// Kind is NodeTool or NodeTask
+	Kind string
+
+	// Name is the tool or task name
+	Name string
+
+	// DependsOn are resolved node IDs this node requires
+	DependsOn []string
+
+	// locations are "file:line" of each depends_on entry, parallel to DependsOn
+	locations []string
+}
+
+// CycleError reports a circular dependency
+// What: The cycle as a path of names plus where each edge is declared
+// Why: "circular dependency detected" alone left config authors hunting through YAML
+type CycleError struct {
+	// Path is the cycle with the first element repeated at the end (a → b → a)
+	Path []string
+
+	// Locations describe each edge, e.g. "configs/tools.yaml:42: a depends_on b"
+	Locations []string
+}
+
+// Error formats the cycle path followed by one location per line
+func (e *CycleError) Error() string {
+	msg := "circular dependency detected: " + strings.Join(e.Path, " → ")
+	for _, loc := range e.Locations {
+		msg += "\n  " + loc
+	}
+	return msg
+}
+
+// annotate fills Locations using a per-edge lookup
+// Params: locate - returns "file:line" for the edge from → to, or empty if unknown
+func (e *CycleError) annotate(locate func(from, to string) string) {
+	for i := 0; i+1 < len(e.Path); i++ {
+		from, to := e.Path[i], e.Path[i+1]
+		if loc := locate(from, to); loc != "" {
+			e.Locations = append(e.Locations, fmt.Sprintf("%s: %s depends_on %s", loc, from, to))
+		}
+	}
+}
+
+// DependencyGraph is the combined graph of tools and setup tasks
+// What: Nodes in declaration order (tools first, then tasks) keyed by ID
+// Why: Single place to compute a global order and validate cross-file references
+type DependencyGraph struct {
+	nodes map[string]*DependencyNode
+	ids   []string
+}
+
+// NodeID builds a graph node ID
+// Params: kind - NodeTool or NodeTask, name - tool or task name
+// Returns: ID such as "tool:gh"
+func NodeID(kind, name string) string {
+	return kind + ":" + name
+}
+
+// ParseDependency resolves a depends_on entry to a node ID
+// What: Accepts "tool:x", "task:x", or bare "x" (bare resolves to defaultKind)
+// Why: Existing configs use bare names; cross-file references need an explicit prefix
+// Params: dep - depends_on entry, defaultKind - kind of the declaring node
+// Returns: Node ID
+// Example: ParseDependency("tool:gh", NodeTask) → "tool:gh"; ParseDependency("x", NodeTask) → "task:x"
+func ParseDependency(dep, defaultKind string) string {
+	if kind, name, ok := strings.Cut(dep, ":"); ok && (kind == NodeTool || kind == NodeTask) {
+		return NodeID(kind, name)
+	}
+	return NodeID(defaultKind, dep)
+}
+
+// BuildDependencyGraph builds the unified graph from both configs
+// What: Creates one node per tool and setup task, resolving depends_on entries to node IDs
+// Why: Global ordering and cross-file validation ("gh-auth depends on tool:gh")
+// Params: tools - tools configuration, setup - setup configuration (either may be nil)
+// Returns: Graph and error if a dependency references an unknown node or a tool depends on a task
+// Example: graph, err := BuildDependencyGraph(toolsCfg, setupCfg)
+func BuildDependencyGraph(tools *ToolsConfig, setup *SetupConfig) (*DependencyGraph, error) {
+	g := &DependencyGraph{nodes: make(map[string]*DependencyNode)}
+
+	if tools != nil {
+		for _, tool := range tools.Tools {
+			node := &DependencyNode{ID: NodeID(NodeTool, tool.Name), Kind: NodeTool, Name: tool.Name}
+			for i, dep := range tool.DependsOn {
+				node.DependsOn = append(node.DependsOn, ParseDependency(dep, NodeTool))
+				node.locations = append(node.locations, edgeLocation(tools.Path, tool.Line, tool.dependsOnLines, i))
+			}
+			g.add(node)
+		}
+	}
+
+	if setup != nil {
+		for _, task := range setup.SetupTasks {
+			node := &DependencyNode{ID: NodeID(NodeTask, task.Name), Kind: NodeTask, Name: task.Name}
+			for i, dep := range task.DependsOn {
+				// A tool held back by its rollout isn't declared on this machine; the task runs without it
+				if id := ParseDependency(dep, NodeTask); tools != nil && strings.HasPrefix(id, NodeTool+":") && slices.Contains(tools.HeldBack, strings.TrimPrefix(id, NodeTool+":")) {
+					continue
+				}
+				node.DependsOn = append(node.DependsOn, ParseDependency(dep, NodeTask))
+				node.locations = append(node.locations, edgeLocation(setup.Path, task.Line, task.dependsOnLines, i))
+			}
+			g.add(node)
+		}
+	}
+
+	for _, id := range g.ids {
+		node := g.nodes[id]
+		for _, dep := range node.DependsOn {
+			if _, ok := g.nodes[dep]; !ok {
+				return nil, fmt.Errorf("%s %s depends on unknown %s", node.Kind, node.Name, dep)
+			}
+			if node.Kind == NodeTool && strings.HasPrefix(dep, NodeTask+":") {
+				return nil, fmt.Errorf("tool %s cannot depend on setup task %s (install runs before setup)", node.Name, dep)
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// add inserts a node preserving declaration order
+func (g *DependencyGraph) add(node *DependencyNode) {
+	g.nodes[node.ID] = node
+	g.ids = append(g.ids, node.ID)
+}
+
+// Node returns a node by ID
+// Params: id - node ID
+// Returns: Node and true if found
+func (g *DependencyGraph) Node(id string) (*DependencyNode, bool) {
+	node, ok := g.nodes[id]
+	return node, ok
+}
+
+// Order returns all nodes in global dependency order
+// What: Topologically sorts tools and tasks together
+// Why: Guarantees every prerequisite (tool or task) precedes its dependents
+// Returns: Ordered nodes, *CycleError on circular dependency
+func (g *DependencyGraph) Order() ([]*DependencyNode, error) {
+	deps := make(map[string][]string, len(g.ids))
+	for _, id := range g.ids {
+		deps[id] = g.nodes[id].DependsOn
+	}
+
+	ordered, err := topologicalSort(g.ids, deps)
+	var cycle *CycleError
+	if errors.As(err, &cycle) {
+		cycle.annotate(func(from, to string) string {
+			node := g.nodes[from]
+			for i, dep := range node.DependsOn {
+				if dep == to {
+					return node.locations[i]
+				}
+			}
+			return ""
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*DependencyNode, 0, len(ordered))
+	for _, id := range ordered {
+		result = append(result, g.nodes[id])
+	}
+	return result, nil
+}
+
+// topologicalSort orders ids so dependencies come first
+// What: Kahn's algorithm; ties broken by declaration order so output is stable across runs
+// Why: Shared by tool install order and the unified graph
+// Params: ids - node IDs in declaration order, deps - dependencies per ID (all must be in ids)
+// Returns: Ordered IDs, *CycleError (path only, no locations) if a cycle exists
+func topologicalSort(ids []string, deps map[string][]string) ([]string, error) {
+	position := make(map[string]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+
+	inDegree := make(map[string]int, len(ids))
+	dependents := make(map[string][]string)
+	for _, id := range ids {
+		for _, dep := range deps[id] {
+			dependents[dep] = append(dependents[dep], id)
+			inDegree[id]++
+		}
+	}
+
+	// ready holds zero in-degree nodes, kept sorted by declaration position
+	var ready []string
+	for _, id := range ids {
+		if inDegree[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	ordered := make([]string, 0, len(ids))
+	for len(ready) > 0 {
+		current := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, current)
+
+		for _, dependent := range dependents[current] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				ready = insertByPosition(ready, dependent, position)
+			}
+		}
+	}
+
+	if len(ordered) != len(ids) {
+		return nil, &CycleError{Path: findCycle(ids, deps, inDegree)}
+	}
+	return ordered, nil
+}
+
+// findCycle extracts one cycle from the nodes Kahn's sort could not order
+// What: Walks unresolved dependencies from the first unordered node until a node repeats
+// Why: Every unordered node has an unordered dependency, so the walk must loop
+// Params: ids - node IDs in declaration order, deps - dependencies per ID, inDegree - remaining in-degrees after the sort
+// Returns: Cycle path with the first node repeated at the end
+func findCycle(ids []string, deps map[string][]string, inDegree map[string]int) []string {
+	var current string
+	for _, id := range ids {
+		if inDegree[id] > 0 {
+			current = id
+			break
+		}
+	}
+
+	var path []string
+	index := make(map[string]int)
+	for {
+		if i, seen := index[current]; seen {
+			return append(path[i:], current)
+		}
+		index[current] = len(path)
+		path = append(path, current)
+
+		for _, dep := range deps[current] {
+			if inDegree[dep] > 0 {
+				current = dep
+				break
+			}
+		}
+	}
+}
+
+// declarationLines reads line numbers from a tool or task mapping node
+// What: Line of the mapping itself and of each depends_on entry
+// Why: Cycle errors point at the exact YAML line to edit
+// Params: node - mapping node being decoded
+// Returns: Declaration line and depends_on entry lines
+func declarationLines(node *yaml.Node) (int, []int) {
+	var depLines []int
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "depends_on" {
+			for _, item := range node.Content[i+1].Content {
+				depLines = append(depLines, item.Line)
+			}
+		}
+	}
+	return node.Line, depLines
+}
+
+// edgeLocation formats where the i-th depends_on entry is declared
+// Params: file - config path (may be empty), line - declaration line, depLines - depends_on entry lines, i - entry index
+// Returns: "file:line", "line N" without a file, or empty when no line is known
+func edgeLocation(file string, line int, depLines []int, i int) string {
+	if i < len(depLines) {
+		line = depLines[i]
+	}
+	switch {
+	case line == 0:
+		return ""
+	case file == "":
+		return fmt.Sprintf("line %d", line)
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// insertByPosition inserts id into a slice sorted by declaration position
+func insertByPosition(ready []string, id string, position map[string]int) []string {
+	i := 0
+	for i < len(ready) && position[ready[i]] < position[id] {
+		i++
+	}
+	ready = append(ready, "")
+	copy(ready[i+1:], ready[i:])
+	ready[i] = id
+	return ready
+}
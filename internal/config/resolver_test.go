@@ -0,0 +1,103 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDependencyGraphOrder(t *testing.T) {
+	tools := &ToolsConfig{Tools: []Tool{
+		{Name: "brew"},
+		{Name: "gh", DependsOn: []string{"brew"}},
+		{Name: "git", DependsOn: []string{"brew"}},
+	}}
+	setup := &SetupConfig{SetupTasks: []SetupTask{
+		{Name: "gh-auth", DependsOn: []string{"tool:gh", "git-config"}},
+		{Name: "git-config", DependsOn: []string{"tool:git"}},
+	}}
+
+	graph, err := BuildDependencyGraph(tools, setup)
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph: %v", err)
+	}
+	nodes, err := graph.Order()
+	if err != nil {
+		t.Fatalf("Order: %v", err)
+	}
+
+	var got []string
+	for _, n := range nodes {
+		got = append(got, n.ID)
+	}
+	want := []string{"tool:brew", "tool:gh", "tool:git", "task:git-config", "task:gh-auth"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Order() = %v, want %v", got, want)
+	}
+}
+
+func TestDependencyGraphErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		tools []Tool
+		tasks []SetupTask
+		want  string
+	}{
+		{
+			name:  "unknown tool",
+			tasks: []SetupTask{{Name: "gh-auth", DependsOn: []string{"tool:gh"}}},
+			want:  "unknown tool:gh",
+		},
+		{
+			name:  "tool depends on task",
+			tools: []Tool{{Name: "gh", DependsOn: []string{"task:gh-auth"}}},
+			tasks: []SetupTask{{Name: "gh-auth"}},
+			want:  "cannot depend on setup task",
+		},
+		{
+			name:  "cycle",
+			tasks: []SetupTask{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}},
+			want:  "circular dependency detected: task:a → task:b → task:a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := BuildDependencyGraph(&ToolsConfig{Tools: tt.tools}, &SetupConfig{SetupTasks: tt.tasks})
+			if err == nil {
+				_, err = graph.Order()
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCycleErrorLocations(t *testing.T) {
+	data := `tools:
+  - name: a
+    depends_on: [c]
+  - name: b
+    depends_on:
+      - a
+  - name: c
+    depends_on: [b]
+`
+	var cfg ToolsConfig
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	cfg.Path = "tools.yaml"
+
+	_, err := cfg.GetInstallOrder()
+	want := "circular dependency detected: a → c → b → a\n" +
+		"  tools.yaml:3: a depends_on c\n" +
+		"  tools.yaml:8: c depends_on b\n" +
+		"  tools.yaml:6: b depends_on a"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
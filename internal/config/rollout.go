@@ -0,0 +1,265 @@
+// File: internal/config/rollout.go
+// Purpose: Canary rollout of tools and setup tasks (rollout: percent, users, hosts)
+// Problem: A new tool in tools.yaml reached every machine on its next install, so a broken install script or a bad
+//          version hit the whole company at once; there was no way to try it on a few machines first
+// Role: At load time, tools and tasks whose rollout doesn't include this machine are held back, as if they weren't
+//       declared; install, setup, verify, and status then agree on what this machine should have
+// Usage: rollout: {percent: 10, users: [alice], hosts: ["qa-*"]} on a tool or setup task; `devsetup rollout` shows
+//        this machine's bucket and what is held back
+// Design choices: A machine's bucket (0-99) comes from a random ID stored once in the state dir, not the hostname,
+//                 so it is evenly spread and survives renames; the bucket is the same for every rollout, so raising
+//                 percent only adds machines and a 10% canary is always the same 10%; dependents of a held-back tool
+//                 or task are held back too, rather than installed without their dependency
+// Assumptions: USER and the hostname identify the user and machine; host patterns use path.Match globs and match
+//              with or without a trailing .local
+
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Rollout limits a tool or task to part of the fleet
+type Rollout struct {
+	// Percent of machines (by bucket) that get it, 0-100
+	Percent int `yaml:"percent"`
+
+	// Users always get it (login names)
+	Users []string `yaml:"users"`
+
+	// Hosts always get it (hostnames or globs like "qa-*")
+	Hosts []string `yaml:"hosts"`
+}
+
+// Machine identifies this machine for rollouts
+type Machine struct {
+	// User is the login name
+	User string
+
+	// Host is the hostname, lowercase, without .local
+	Host string
+
+	// Bucket places the machine in 0-99 for percentage rollouts
+	Bucket int
+}
+
+// CurrentMachine returns this machine's rollout identity
+// What: USER (or the current user), the short hostname, and the bucket of the stored machine ID
+// Edge cases: If the machine ID can't be stored, the bucket falls back to a hash of the hostname
+func CurrentMachine() Machine {
+	m := Machine{User: os.Getenv("USER")}
+	if m.User == "" {
+		if u, err := user.Current(); err == nil {
+			m.User = u.Username
+		}
+	}
+	host, _ := os.Hostname()
+	m.Host = strings.TrimSuffix(strings.ToLower(host), ".local")
+
+	id, err := machineID()
+	if err != nil {
+		id = m.Host
+	}
+	m.Bucket = bucket(id)
+	return m
+}
+
+// MachineIDPath returns where the machine ID is stored
+// Returns: <state dir>/machine-id
+func MachineIDPath() string {
+	return filepath.Join(GetStateDir(), "machine-id")
+}
+
+// machineID returns the stored machine ID, creating it on first use
+func machineID() (string, error) {
+	if data, err := os.ReadFile(MachineIDPath()); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(raw)
+	if err := os.MkdirAll(GetStateDir(), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(MachineIDPath(), []byte(id+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// bucket maps an ID to 0-99
+func bucket(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % 100)
+}
+
+// Includes reports whether a machine gets what the rollout gates
+// Returns: True for a nil rollout, a listed user or host, or a bucket below percent
+func (r *Rollout) Includes(m Machine) bool {
+	if r == nil || slices.Contains(r.Users, m.User) || m.Bucket < r.Percent {
+		return true
+	}
+	for _, pattern := range r.Hosts {
+		pattern = strings.TrimSuffix(strings.ToLower(pattern), ".local")
+		if ok, _ := path.Match(pattern, m.Host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the rollout for display
+// Example: {Percent: 10, Users: [alice]} → "10%; users alice"
+func (r *Rollout) String() string {
+	var parts []string
+	if r.Percent > 0 {
+		parts = append(parts, fmt.Sprintf("%d%%", r.Percent))
+	}
+	if len(r.Users) > 0 {
+		parts = append(parts, "users "+strings.Join(r.Users, ", "))
+	}
+	if len(r.Hosts) > 0 {
+		parts = append(parts, "hosts "+strings.Join(r.Hosts, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// validate checks a rollout block
+// Params: where - what declares it, for the error (e.g. "tool zed")
+// Returns: Error for a percent outside 0-100, a bad host glob, or a block that includes no machine
+func (r *Rollout) validate(where string) error {
+	if r == nil {
+		return nil
+	}
+	if r.Percent < 0 || r.Percent > 100 {
+		return fmt.Errorf("%s: rollout percent %d out of range (0-100)", where, r.Percent)
+	}
+	for _, pattern := range r.Hosts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: invalid rollout host %q", where, pattern)
+		}
+	}
+	if r.Percent == 0 && len(r.Users) == 0 && len(r.Hosts) == 0 {
+		return fmt.Errorf("%s: rollout needs a percent, users, or hosts", where)
+	}
+	return nil
+}
+
+// applyRollout holds back the tools this machine isn't in the rollout of
+// What: Drops tools whose rollout excludes this machine, then tools depending on a dropped one, and lists them in HeldBack
+// Params: machine - identifies this machine (CurrentMachine), called only if a tool has a rollout
+// Returns: Error for an invalid rollout block
+func (tc *ToolsConfig) applyRollout(machine func() Machine) error {
+	held := make(map[string]bool)
+	var m *Machine
+	for _, tool := range tc.Tools {
+		if tool.Rollout == nil {
+			continue
+		}
+		if err := tool.Rollout.validate("tool " + tool.Name); err != nil {
+			return err
+		}
+		if m == nil {
+			current := machine()
+			m = &current
+		}
+		if !tool.Rollout.Includes(*m) {
+			held[tool.Name] = true
+		}
+	}
+	if len(held) == 0 {
+		return nil
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, tool := range tc.Tools {
+			if held[tool.Name] {
+				continue
+			}
+			for _, dep := range tc.Dependencies(tool) {
+				if held[dep] {
+					held[tool.Name], changed = true, true
+					break
+				}
+			}
+		}
+	}
+
+	kept := tc.Tools[:0]
+	for _, tool := range tc.Tools {
+		if held[tool.Name] {
+			tc.HeldBack = append(tc.HeldBack, tool.Name)
+			continue
+		}
+		kept = append(kept, tool)
+	}
+	tc.Tools = kept
+	return nil
+}
+
+// applyRollout holds back the setup tasks this machine isn't in the rollout of
+// What: Drops tasks whose rollout excludes this machine, then tasks depending on a dropped task, and lists them in HeldBack
+// Params: machine - identifies this machine (CurrentMachine), called only if a task has a rollout
+// Returns: Error for an invalid rollout block
+// Edge cases: A task depending on a held-back tool loses that dependency (see BuildDependencyGraph); give it the same rollout
+func (sc *SetupConfig) applyRollout(machine func() Machine) error {
+	held := make(map[string]bool)
+	var m *Machine
+	for _, task := range sc.SetupTasks {
+		if task.Rollout == nil {
+			continue
+		}
+		if err := task.Rollout.validate("task " + task.Name); err != nil {
+			return err
+		}
+		if m == nil {
+			current := machine()
+			m = &current
+		}
+		if !task.Rollout.Includes(*m) {
+			held[task.Name] = true
+		}
+	}
+	if len(held) == 0 {
+		return nil
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, task := range sc.SetupTasks {
+			if held[task.Name] {
+				continue
+			}
+			for _, dep := range task.DependsOn {
+				if id := ParseDependency(dep, NodeTask); strings.HasPrefix(id, NodeTask+":") && held[strings.TrimPrefix(id, NodeTask+":")] {
+					held[task.Name], changed = true, true
+					break
+				}
+			}
+		}
+	}
+
+	kept := sc.SetupTasks[:0]
+	for _, task := range sc.SetupTasks {
+		if held[task.Name] {
+			sc.HeldBack = append(sc.HeldBack, task.Name)
+			continue
+		}
+		kept = append(kept, task)
+	}
+	sc.SetupTasks = kept
+	return nil
+}
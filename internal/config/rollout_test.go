@@ -0,0 +1,63 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRolloutIncludes(t *testing.T) {
+	m := Machine{User: "bob", Host: "qa-mbp-7", Bucket: 42}
+	for _, tc := range []struct {
+		rollout *Rollout
+		want    bool
+	}{
+		{nil, true},
+		{&Rollout{Percent: 43}, true},
+		{&Rollout{Percent: 42}, false},
+		{&Rollout{Percent: 10, Users: []string{"bob"}}, true},
+		{&Rollout{Hosts: []string{"QA-*.local"}}, true},
+		{&Rollout{Users: []string{"alice"}, Hosts: []string{"dev-*"}}, false},
+	} {
+		if got := tc.rollout.Includes(m); got != tc.want {
+			t.Errorf("%+v.Includes() = %t, want %t", tc.rollout, got, tc.want)
+		}
+	}
+}
+
+func TestApplyRolloutHoldsBackDependents(t *testing.T) {
+	tc := &ToolsConfig{Tools: []Tool{
+		{Name: "jq"},
+		{Name: "zed", Rollout: &Rollout{Percent: 10}},
+		{Name: "zed-plugins", DependsOn: []string{"zed"}},
+	}}
+	calls := 0
+	machine := func() Machine { calls++; return Machine{Bucket: 50} }
+	if err := tc.applyRollout(machine); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tc.HeldBack, []string{"zed", "zed-plugins"}) || len(tc.Tools) != 1 || calls != 1 {
+		t.Errorf("HeldBack = %v, tools = %v, machine calls = %d", tc.HeldBack, tc.Tools, calls)
+	}
+
+	sc := &SetupConfig{SetupTasks: []SetupTask{{Name: "zed-settings", DependsOn: []string{"tool:zed"}}}}
+	graph, err := BuildDependencyGraph(tc, sc)
+	if err != nil {
+		t.Fatalf("task depending on a held-back tool: %v", err)
+	}
+	if node, _ := graph.Node(NodeID(NodeTask, "zed-settings")); len(node.DependsOn) != 0 {
+		t.Errorf("zed-settings still depends on %v", node.DependsOn)
+	}
+
+	bad := &ToolsConfig{Tools: []Tool{{Name: "zed", Rollout: &Rollout{}}}}
+	if err := bad.applyRollout(machine); err == nil {
+		t.Error("empty rollout accepted")
+	}
+}
+
+func TestMachineIDStable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	first := CurrentMachine()
+	if second := CurrentMachine(); second.Bucket != first.Bucket || first.Bucket < 0 || first.Bucket > 99 {
+		t.Errorf("buckets %d, %d; want one stable bucket in 0-99", first.Bucket, second.Bucket)
+	}
+}
@@ -9,19 +9,46 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Builtin tasks implemented by devsetup
+const (
+	// BuiltinHomebrewPolicy applies the policies.yaml homebrew baseline
+	BuiltinHomebrewPolicy = "homebrew_policy"
+	// BuiltinManagedPath writes the generated PATH file and sources it from the ~/.zshrc managed block
+	BuiltinManagedPath = "managed_path"
+)
+
 // SetupConfig represents the complete setup.yaml file
 // What: List of configuration tasks to run after tools are installed
 // Why: Tools need configuration (API keys, dotfiles, etc) after installation
 type SetupConfig struct {
 	// SetupTasks are the list of configuration tasks
 	SetupTasks []SetupTask `yaml:"setup_tasks"`
+
+	// Shell is the default shell for task commands: sh (default), bash, or zsh
+	Shell string `yaml:"shell"`
+
+	// Owner is who to ask about tasks that don't name their own owner (nil = the generic support hint)
+	Owner *Owner `yaml:"owner"`
+
+	// HeldBack lists tasks left out because this machine isn't in their rollout
+	HeldBack []string `yaml:"-"`
+
+	// Path is the file this config was loaded from (for error locations)
+	Path string `yaml:"-"`
+
+	// Overrides are the configs.d overlay files applied at load time
+	Overrides []string `yaml:"-"`
 }
 
 // SetupTask represents a single configuration task
@@ -55,10 +82,58 @@ type SetupTask struct {
 	// Prompt for interactive user input
 	Prompt *PromptConfig `yaml:"prompt"`
 
+	// Builtin runs a task implemented by devsetup itself (homebrew_policy)
+	Builtin string `yaml:"builtin"`
+
+	// Personalize applies machine preferences (timezone, locale, keyboard, Finder)
+	Personalize *PersonalizeConfig `yaml:"personalize"`
+
+	// Profiles are .mobileconfig files to install (Wi-Fi, VPN, printers)
+	Profiles []ProfileConfig `yaml:"profiles"`
+
+	// GitHubAccess is a preflight for required GitHub org/team access (SSO included)
+	GitHubAccess *GitHubAccessConfig `yaml:"github_access"`
+
+	// Postgres creates a local role and database and seeds it once
+	Postgres *PostgresConfig `yaml:"postgres"`
+
+	// WarmCaches pre-populates package manager caches from the team's main repos
+	WarmCaches *WarmCachesConfig `yaml:"warm_caches"`
+
+	// GitIdentities sets up per-directory git identities with their own SSH keys and host aliases
+	GitIdentities []GitIdentity `yaml:"git_identities"`
+
+	// AIAssistant stores an AI CLI's key in the keychain and writes its standard settings (Claude, Copilot)
+	AIAssistant *AIAssistantConfig `yaml:"ai_assistant"`
+
+	// GitHooks registers the team's standard git hooks (lefthook or pre-commit) globally or per checkout
+	GitHooks *GitHooksConfig `yaml:"git_hooks"`
+
+	// Browser installs a configuration profile with browser policies and force-installed extensions
+	Browser *BrowserConfig `yaml:"browser"`
+
+	// RemoteCache writes a monorepo build tool's remote cache config and keeps its token in the keychain
+	RemoteCache *RemoteCacheConfig `yaml:"remote_cache"`
+
+	// DevTLS trusts a local CA (mkcert) and issues certificates for development domains
+	DevTLS *DevTLSConfig `yaml:"dev_tls"`
+
+	// JetBrains installs required plugins into a JetBrains IDE (IntelliJ IDEA, Android Studio, ...)
+	JetBrains *JetBrainsConfig `yaml:"jetbrains"`
+
+	// EditorSettings merges team defaults into the user's Zed or VS Code settings and keybindings
+	EditorSettings *EditorSettingsConfig `yaml:"editor_settings"`
+
+	// Manual is a step the user does by hand (request VPN access, accept an invite); setup shows it and asks
+	Manual *ManualConfig `yaml:"manual"`
+
 	// Verify contains verification checks
 	Verify []VerifyCheck `yaml:"verify"`
 
-	// DependsOn lists tasks that must complete first
+	// OnFailure is a cleanup command run when the task fails (nil = none)
+	OnFailure *OnFailure `yaml:"on_failure"`
+
+	// DependsOn lists tasks (bare name or "task:x") or tools ("tool:x") that must complete first
 	DependsOn []string `yaml:"depends_on"`
 
 	// Interactive indicates if this task requires user interaction
@@ -66,6 +141,46 @@ type SetupTask struct {
 
 	// Optional indicates if this task can be skipped on failure
 	Optional bool `yaml:"optional"`
+
+	// Shell runs the task's commands: sh, bash, or zsh (default: setup.yaml shell)
+	Shell string `yaml:"shell"`
+
+	// Owner is the team maintaining this task, named when it fails (default: setup.yaml owner)
+	Owner *Owner `yaml:"owner"`
+
+	// Rollout limits the task to some machines (nil = every machine)
+	Rollout *Rollout `yaml:"rollout"`
+
+	// Line is where this task is declared in its config file (0 if not loaded from YAML)
+	Line int `yaml:"-"`
+
+	// dependsOnLines are the lines of each depends_on entry, parallel to DependsOn
+	dependsOnLines []int
+}
+
+// UnmarshalYAML decodes a task and records its source lines
+// Why: Dependency errors report file:line of the offending entry
+func (t *SetupTask) UnmarshalYAML(node *yaml.Node) error {
+	type plain SetupTask
+	if err := node.Decode((*plain)(t)); err != nil {
+		return err
+	}
+	t.Line, t.dependsOnLines = declarationLines(node)
+	return nil
+}
+
+// ManualConfig describes a step devsetup can't automate
+// What: Instructions shown by setup, status, and `devsetup manual`, plus an optional command confirming it's done
+// Why: Onboarding steps like requesting VPN access were tracked outside devsetup, so nobody saw what was still open
+type ManualConfig struct {
+	// Instructions tell the user what to do (may span several lines)
+	Instructions string `yaml:"instructions"`
+
+	// URL is where the step is done (request form, invite page), shown with the instructions
+	URL string `yaml:"url"`
+
+	// Verify is a shell command that succeeds once the step is done (e.g. reaching a VPN-only host)
+	Verify string `yaml:"verify"`
 }
 
 // CommandConfig contains command execution details
@@ -145,6 +260,484 @@ type PromptConfig struct {
 
 	// SkipIfSet skips prompt if env var already set
 	SkipIfSet bool `yaml:"skip_if_set"`
+
+	// Secret hides input and masks the value in all output
+	Secret bool `yaml:"secret"`
+}
+
+// PersonalizeConfig defines machine personalization
+// What: Timezone, locale, typing behavior, and Finder preferences; unset fields are left alone
+// Why: IT scripted these separately for every new laptop
+type PersonalizeConfig struct {
+	// Timezone is an IANA zone name (e.g. "America/New_York"); setting it needs sudo
+	Timezone string `yaml:"timezone"`
+
+	// Locale is the AppleLocale (e.g. "en_US")
+	Locale string `yaml:"locale"`
+
+	// TextReplacement false turns off autocorrect, smart quotes/dashes, and auto-capitalization
+	TextReplacement *bool `yaml:"text_replacement"`
+
+	// KeyRepeat is the key repeat interval in 15ms units (lower is faster; 2 is the fastest UI setting)
+	KeyRepeat int `yaml:"key_repeat"`
+
+	// InitialKeyRepeat is the delay before repeat starts in 15ms units (15 is the shortest UI setting)
+	InitialKeyRepeat int `yaml:"initial_key_repeat"`
+
+	// Finder sets Finder preferences
+	Finder *FinderConfig `yaml:"finder"`
+}
+
+// ProfileConfig defines a configuration profile to install
+// What: .mobileconfig file and the identifier it installs
+// Why: The identifier proves installation (including profiles pushed by MDM)
+type ProfileConfig struct {
+	// Path is the .mobileconfig file (relative to the working directory; $HOME is expanded)
+	Path string `yaml:"path"`
+
+	// Identifier is the PayloadIdentifier of the profile (e.g. "com.corp.wifi")
+	Identifier string `yaml:"identifier"`
+
+	// Description is shown while installing
+	Description string `yaml:"description"`
+}
+
+// GitHubAccessConfig defines required GitHub access
+// What: Orgs and teams the developer must belong to, with an SSO-authorized gh token
+// Why: Tasks cloning private repositories depend on this preflight instead of failing mid-clone
+type GitHubAccessConfig struct {
+	// Orgs are required org logins (e.g. "rkinnovate")
+	Orgs []string `yaml:"orgs"`
+
+	// Teams are required teams as "org/team-slug"
+	Teams []string `yaml:"teams"`
+}
+
+// PostgresConfig defines a local Postgres database bootstrap
+// What: Role and database to create if missing, and a seed script run only while a check query finds no data
+// Why: Replaces the createuser/createdb/seed steps developers ran by hand after setup
+type PostgresConfig struct {
+	// Database is the database to create
+	Database string `yaml:"database"`
+
+	// User is the login role to create and make owner ("" = owned by the OS user)
+	User string `yaml:"user"`
+
+	// Password for User; ${VAR} is expanded so secrets can stay out of setup.yaml
+	Password string `yaml:"password"`
+
+	// Seed is a SQL file run against Database as User (relative to the working directory; $HOME is expanded)
+	Seed string `yaml:"seed"`
+
+	// SeedCheck is a query; the seed is skipped when it returns a row (e.g. "SELECT 1 FROM users LIMIT 1")
+	SeedCheck string `yaml:"seed_check"`
+
+	// Timeout bounds the whole bootstrap, seed included (default 5m)
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Cache warmers (warm_caches.only)
+const (
+	WarmerPnpm      = "pnpm"
+	WarmerUv        = "uv"
+	WarmerCocoapods = "cocoapods"
+	WarmerGradle    = "gradle"
+)
+
+// WarmCachesConfig defines package manager cache warm-up for repositories
+// What: Repos whose lockfiles drive pnpm fetch, uv sync, pod install, and the Gradle wrapper download
+// Why: A new machine's first build otherwise spends 20 minutes downloading dependencies
+type WarmCachesConfig struct {
+	// Repos are checkout directories ($HOME expanded); repos not cloned yet are skipped
+	Repos []string `yaml:"repos"`
+
+	// Only restricts warming to these package managers (pnpm, uv, cocoapods, gradle; default: every one detected)
+	Only []string `yaml:"only"`
+
+	// Timeout bounds each warmer (default 20m)
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Git hook managers supported by git_hooks tasks
+const (
+	HookManagerLefthook  = "lefthook"
+	HookManagerPreCommit = "pre-commit"
+)
+
+// DefaultGitHooks are the hooks git_hooks registers when the task lists none
+var DefaultGitHooks = []string{"pre-commit", "commit-msg"}
+
+// GitHooksConfig defines the team's standard git hooks
+// What: Hook scripts running the team's hook config with lefthook or pre-commit, registered as core.hooksPath
+// globally or in each listed checkout
+// Why: Hooks were installed per repo by whoever remembered `lefthook install`, so lint and commit-message rules
+// were enforced on some machines and not others
+type GitHooksConfig struct {
+	// Manager is lefthook or pre-commit
+	Manager string `yaml:"manager"`
+
+	// Config is the standard hook config (lefthook.yml or .pre-commit-config.yaml; $HOME expanded)
+	Config string `yaml:"config"`
+
+	// Hooks are the git hooks to register (default pre-commit, commit-msg)
+	Hooks []string `yaml:"hooks"`
+
+	// Repos are checkouts to register the hooks in ($HOME expanded; empty = global core.hooksPath); repos not
+	// cloned yet are skipped
+	Repos []string `yaml:"repos"`
+}
+
+// HookNames returns the hooks to register
+func (g *GitHooksConfig) HookNames() []string {
+	if len(g.Hooks) == 0 {
+		return DefaultGitHooks
+	}
+	return g.Hooks
+}
+
+// isGitHook reports whether s is a client-side git hook the hook managers run
+func isGitHook(s string) bool {
+	switch s {
+	case "pre-commit", "prepare-commit-msg", "commit-msg", "post-commit", "pre-push", "post-checkout", "post-merge", "pre-rebase":
+		return true
+	}
+	return false
+}
+
+// GitIdentity is one git identity (e.g. personal and work GitHub accounts)
+// What: user.name/email and an SSH key for repositories under some directories, reached through an SSH host alias
+// Why: One global user.email and ~/.ssh/id_ed25519 meant work commits under personal addresses, and pushes to the
+// second account failing with "permission denied"
+type GitIdentity struct {
+	// Name labels the identity (work, personal); it names the include file, the default key, and the default alias
+	Name string `yaml:"name"`
+
+	// UserName is git's user.name (asked for when empty and not set up yet)
+	UserName string `yaml:"user_name"`
+
+	// Email is git's user.email (asked for when empty and not set up yet)
+	Email string `yaml:"email"`
+
+	// Dirs are directories whose repositories use this identity (includeIf gitdir:; $HOME expanded). Empty makes it
+	// the default identity, also used for plain git@<host> outside those directories
+	Dirs []string `yaml:"dirs"`
+
+	// Host is the git server (default github.com)
+	Host string `yaml:"host"`
+
+	// Alias is the SSH host alias (default <host's first label>-<name>, e.g. github-work)
+	Alias string `yaml:"alias"`
+
+	// Key is the SSH private key (default ~/.ssh/id_ed25519_<name>; generated when missing)
+	Key string `yaml:"key"`
+
+	// Login is the account the key must authenticate as (empty = any account)
+	Login string `yaml:"login"`
+}
+
+// GitHost returns the git server
+func (g *GitIdentity) GitHost() string {
+	if g.Host != "" {
+		return g.Host
+	}
+	return "github.com"
+}
+
+// SSHAlias returns the SSH host alias
+// Example: SSHAlias() → "github-work" for name work on github.com
+func (g *GitIdentity) SSHAlias() string {
+	if g.Alias != "" {
+		return g.Alias
+	}
+	host, _, _ := strings.Cut(g.GitHost(), ".")
+	return host + "-" + g.Name
+}
+
+// KeyPath returns the SSH private key path (before $HOME expansion)
+func (g *GitIdentity) KeyPath() string {
+	if g.Key != "" {
+		return g.Key
+	}
+	return "~/.ssh/id_ed25519_" + g.Name
+}
+
+// AI assistant CLIs supported by ai_assistant tasks
+const (
+	AIClaude  = "claude"
+	AICopilot = "copilot"
+)
+
+// AIAssistantConfig defines an AI coding assistant CLI's credentials and standard settings
+// What: API key (Claude) or GitHub token (Copilot) in the login keychain, read by the CLI at run time, plus team
+// settings merged into the CLI's config file
+// Why: Keys were pasted into ~/.zshrc exports, where every backup, dotfile sync, and screen share could see them
+type AIAssistantConfig struct {
+	// Tool is claude or copilot
+	Tool string `yaml:"tool"`
+
+	// Keychain is the keychain item holding the key (default devsetup.<tool>-api-key)
+	Keychain string `yaml:"keychain"`
+
+	// Prompt is the question asked when the key isn't in the keychain (default "<tool> API key")
+	Prompt string `yaml:"prompt"`
+
+	// Settings are managed keys merged into ~/.claude/settings.json or ~/.copilot/config.json
+	Settings map[string]any `yaml:"settings"`
+}
+
+// KeychainService returns the keychain item name for the key
+// Example: KeychainService() → "devsetup.claude-api-key"
+func (a *AIAssistantConfig) KeychainService() string {
+	if a.Keychain != "" {
+		return a.Keychain
+	}
+	return "devsetup." + a.Tool + "-api-key"
+}
+
+// Editors supported by editor_settings
+const (
+	EditorZed    = "zed"
+	EditorVSCode = "vscode"
+)
+
+// EditorSettingsConfig defines team defaults for an editor
+// What: Settings merged into the user's settings.json and keybindings added to their keybindings file
+// Why: Format-on-save, rulers, and team shortcuts were set by hand per machine, or not at all
+type EditorSettingsConfig struct {
+	// Editor is zed or vscode
+	Editor string `yaml:"editor"`
+
+	// Settings are managed keys; objects merge into the user's objects, anything else replaces the user's value
+	Settings map[string]any `yaml:"settings"`
+
+	// Keybindings are entries added to keymap.json (Zed) or keybindings.json (VS Code) unless already present
+	Keybindings []any `yaml:"keybindings"`
+}
+
+// DefaultRenewBefore is how long before expiry dev_tls reissues a certificate
+const DefaultRenewBefore = 30 * 24 * time.Hour
+
+// DevTLSConfig defines local HTTPS for development domains
+// What: mkcert's local CA installed and trusted, one certificate covering the domains, optional /etc/hosts entries
+// Why: Every web team scripted this differently (or served plain HTTP and hit secure-cookie bugs)
+type DevTLSConfig struct {
+	// Domains the certificate covers ("app.test", "*.app.test", "localhost")
+	Domains []string `yaml:"domains"`
+
+	// Dir holds the certificate and key (default ~/.local/share/devsetup/certs; $HOME expanded)
+	Dir string `yaml:"dir"`
+
+	// Name is the file name base: <name>.pem and <name>-key.pem (default "dev")
+	Name string `yaml:"name"`
+
+	// RenewBefore reissues the certificate when it expires sooner than this (default 720h)
+	RenewBefore time.Duration `yaml:"renew_before"`
+
+	// Hosts points the domains (wildcards excluded) at 127.0.0.1 in a managed /etc/hosts block (needs sudo)
+	Hosts bool `yaml:"hosts"`
+}
+
+// CertFiles returns the certificate and key paths (before $HOME expansion)
+// Example: CertFiles() → "~/.local/share/devsetup/certs/dev.pem", "~/.local/share/devsetup/certs/dev-key.pem"
+func (d *DevTLSConfig) CertFiles() (cert, key string) {
+	dir := d.Dir
+	if dir == "" {
+		dir = "~/.local/share/devsetup/certs"
+	}
+	name := d.Name
+	if name == "" {
+		name = "dev"
+	}
+	return dir + "/" + name + ".pem", dir + "/" + name + "-key.pem"
+}
+
+// Monorepo build tools supported by remote_cache tasks
+const (
+	BuildToolBazel = "bazel"
+	BuildToolNx    = "nx"
+	BuildToolTurbo = "turbo"
+)
+
+// DefaultTurboAPI is Vercel's remote cache, used by turbo when remote_cache.url is empty
+const DefaultTurboAPI = "https://vercel.com/api"
+
+// RemoteCacheConfig defines a monorepo build tool's remote cache
+// What: The cache endpoint, written where the tool reads it (~/.bazelrc, or env exports in ~/.zshrc), with the
+// access token kept in the login keychain
+// Why: A missing or expired cache token doesn't fail builds, it just makes every build a cold one
+type RemoteCacheConfig struct {
+	// Tool is bazel, nx, or turbo
+	Tool string `yaml:"tool"`
+
+	// URL is the cache endpoint (bazel: https:// or grpcs://; nx: self-hosted cache server; turbo: default Vercel)
+	URL string `yaml:"url"`
+
+	// Team is the turbo team slug or ID (team_...)
+	Team string `yaml:"team"`
+
+	// Keychain is the keychain item holding the token (default devsetup.<tool>-remote-cache)
+	Keychain string `yaml:"keychain"`
+
+	// Prompt is the question asked when the token isn't in the keychain (default "<tool> remote cache token")
+	Prompt string `yaml:"prompt"`
+}
+
+// Endpoint returns the cache URL without a trailing slash
+// Example: Endpoint() → "https://vercel.com/api" for turbo without url
+func (r *RemoteCacheConfig) Endpoint() string {
+	if r.URL == "" && r.Tool == BuildToolTurbo {
+		return DefaultTurboAPI
+	}
+	return strings.TrimRight(r.URL, "/")
+}
+
+// KeychainService returns the keychain item name for the token
+// Example: KeychainService() → "devsetup.turbo-remote-cache"
+func (r *RemoteCacheConfig) KeychainService() string {
+	if r.Keychain != "" {
+		return r.Keychain
+	}
+	return "devsetup." + r.Tool + "-remote-cache"
+}
+
+// validate checks a remote cache definition
+// Params: where - prefix for error messages ("task x")
+func (r *RemoteCacheConfig) validate(where string) error {
+	switch r.Tool {
+	case BuildToolBazel, BuildToolNx, BuildToolTurbo:
+	default:
+		return fmt.Errorf("%s: unknown remote_cache tool %q (expected bazel, nx, or turbo)", where, r.Tool)
+	}
+	if r.URL == "" && r.Tool != BuildToolTurbo {
+		return fmt.Errorf("%s: remote_cache.url is required for %s", where, r.Tool)
+	}
+	if r.URL != "" {
+		u, err := url.Parse(r.URL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("%s: invalid remote_cache.url %q", where, r.URL)
+		}
+		schemes := []string{"http", "https"}
+		if r.Tool == BuildToolBazel {
+			schemes = append(schemes, "grpc", "grpcs")
+		}
+		if !slices.Contains(schemes, u.Scheme) {
+			return fmt.Errorf("%s: remote_cache.url for %s must use %s", where, r.Tool, strings.Join(schemes, ", "))
+		}
+	}
+	if r.Tool == BuildToolTurbo && r.Team == "" {
+		return fmt.Errorf("%s: remote_cache.team is required for turbo", where)
+	}
+	return nil
+}
+
+// JetBrains IDEs supported by jetbrains tasks
+const (
+	IDEIntelliJ      = "idea"
+	IDEIntelliJCE    = "idea-ce"
+	IDEAndroidStudio = "studio"
+	IDEGoLand        = "goland"
+	IDEPyCharm       = "pycharm"
+	IDEWebStorm      = "webstorm"
+)
+
+// JetBrainsConfig defines plugins a JetBrains IDE must have
+// What: Plugin IDs installed with the IDE's installPlugins command when missing
+// Why: Teams standardizing on IntelliJ/Android Studio listed required plugins in the onboarding doc
+type JetBrainsConfig struct {
+	// IDE is idea, idea-ce, studio, goland, pycharm, or webstorm
+	IDE string `yaml:"ide"`
+
+	// Plugins are plugin IDs from the JetBrains Marketplace page (e.g. "org.jetbrains.kotlin")
+	Plugins []string `yaml:"plugins"`
+
+	// Timeout bounds the plugin install (default 10m)
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// isJetBrainsIDE reports whether s is a supported JetBrains IDE
+func isJetBrainsIDE(s string) bool {
+	switch s {
+	case IDEIntelliJ, IDEIntelliJCE, IDEAndroidStudio, IDEGoLand, IDEPyCharm, IDEWebStorm:
+		return true
+	}
+	return false
+}
+
+// Browsers supported by browser tasks
+const (
+	BrowserChrome = "chrome"
+	BrowserEdge   = "edge"
+	BrowserBrave  = "brave"
+)
+
+// BrowserConfig defines managed policies and required extensions for a browser
+// What: Policies and extensions written as a configuration profile for the browser's preference domain
+// Why: SSO (Kerberos/negotiate allowlists) and internal-tool extensions were set up by hand from a wiki page
+type BrowserConfig struct {
+	// Browser is chrome, edge, or brave
+	Browser string `yaml:"browser"`
+
+	// Identifier is the profile's PayloadIdentifier (default com.rkinnovate.devsetup.<browser>)
+	Identifier string `yaml:"identifier"`
+
+	// Policies are the browser's enterprise policies by name (e.g. AuthServerAllowlist: "*.corp.example.com")
+	Policies map[string]any `yaml:"policies"`
+
+	// Extensions are force-installed (added to the ExtensionInstallForcelist policy)
+	Extensions []BrowserExtension `yaml:"extensions"`
+}
+
+// ProfileIdentifier returns the identifier of the task's configuration profile
+func (b *BrowserConfig) ProfileIdentifier() string {
+	if b.Identifier != "" {
+		return b.Identifier
+	}
+	return "com.rkinnovate.devsetup." + b.Browser
+}
+
+// isBrowser reports whether s is a supported browser
+func isBrowser(s string) bool {
+	return s == BrowserChrome || s == BrowserEdge || s == BrowserBrave
+}
+
+// isExtensionID reports whether s looks like an extension ID (32 letters a-p)
+func isExtensionID(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+	for _, c := range s {
+		if c < 'a' || c > 'p' {
+			return false
+		}
+	}
+	return true
+}
+
+// BrowserExtension is an extension the browser must have
+type BrowserExtension struct {
+	// ID is the 32-letter extension ID from the web store URL
+	ID string `yaml:"id"`
+
+	// Name is shown in setup output (default: the ID)
+	Name string `yaml:"name"`
+
+	// UpdateURL is the extension's update service (default: the Chrome Web Store)
+	UpdateURL string `yaml:"update_url"`
+}
+
+// FinderConfig defines Finder preferences (nil fields are left alone)
+type FinderConfig struct {
+	// ShowExtensions shows all file name extensions
+	ShowExtensions *bool `yaml:"show_extensions"`
+
+	// ShowHidden shows hidden (dot) files
+	ShowHidden *bool `yaml:"show_hidden"`
+
+	// ShowPathBar shows the path bar at the bottom of windows
+	ShowPathBar *bool `yaml:"show_path_bar"`
+
+	// ShowStatusBar shows the status bar at the bottom of windows
+	ShowStatusBar *bool `yaml:"show_status_bar"`
 }
 
 // VerifyCheck represents a verification check
@@ -166,6 +759,36 @@ type VerifyCheck struct {
 	// TomlValue checks TOML value
 	TomlValue *TomlValueCheck `yaml:"toml_value"`
 
+	// Profile checks a configuration profile with this identifier is installed
+	Profile string `yaml:"profile"`
+
+	// PostgresQuery passes when a query returns a row
+	PostgresQuery *PostgresQueryCheck `yaml:"postgres_query"`
+
+	// JSONValue checks a key in a JSON (or JSONC) settings file
+	JSONValue *JSONValueCheck `yaml:"json_value"`
+
+	// BrowserExtension checks an extension is installed in a browser profile
+	BrowserExtension *BrowserExtensionCheck `yaml:"browser_extension"`
+
+	// JetBrainsPlugin checks a plugin is installed in a JetBrains IDE
+	JetBrainsPlugin *JetBrainsPluginCheck `yaml:"jetbrains_plugin"`
+
+	// TLSCert checks a certificate file is valid, covers domains, and isn't about to expire
+	TLSCert *TLSCertCheck `yaml:"tls_cert"`
+
+	// RemoteCache passes when the cache answers a request made with the keychain token
+	RemoteCache *RemoteCacheConfig `yaml:"remote_cache"`
+
+	// GitIdentity passes when repositories in the identity's directories use it and its SSH alias authenticates
+	GitIdentity *GitIdentity `yaml:"git_identity"`
+
+	// AIAuth passes when the AI service accepts the key stored in the keychain
+	AIAuth *AIAssistantConfig `yaml:"ai_auth"`
+
+	// GitHook runs a git hook the way git does and passes when devsetup's hook script handled it
+	GitHook *GitHookCheck `yaml:"git_hook"`
+
 	// Description of what this check verifies
 	Description string `yaml:"description"`
 }
@@ -184,6 +807,79 @@ type FileContainsCheck struct {
 	Description string `yaml:"description"`
 }
 
+// PostgresQueryCheck checks a local Postgres query returns a row
+// What: Runs the query with psql as the OS user
+// Why: Confirms a database exists and was seeded
+type PostgresQueryCheck struct {
+	// Database to connect to
+	Database string `yaml:"database"`
+
+	// Query must return at least one row (e.g. "SELECT 1 FROM users LIMIT 1")
+	Query string `yaml:"query"`
+}
+
+// JSONValueCheck checks a JSON settings file has an expected value
+// What: Looks up Key (literal, else dotted path through nested objects) and compares it to Equals
+// Why: Confirms editor settings survived the user's own edits
+type JSONValueCheck struct {
+	// File is the settings file ($HOME expanded)
+	File string `yaml:"file"`
+
+	// Key is the setting ("editor.formatOnSave", or "terminal.font_size" for a nested object)
+	Key string `yaml:"key"`
+
+	// Equals is the expected value (nil = the key only has to exist)
+	Equals any `yaml:"equals"`
+}
+
+// BrowserExtensionCheck checks a browser has an extension installed
+// What: Looks for the extension's directory in any of the browser's user profiles
+// Why: A force-install policy only takes effect once the browser has fetched the extension
+type BrowserExtensionCheck struct {
+	// Browser is chrome, edge, or brave
+	Browser string `yaml:"browser"`
+
+	// ID is the extension ID
+	ID string `yaml:"id"`
+}
+
+// JetBrainsPluginCheck checks a JetBrains IDE has a plugin installed
+// What: Reads the plugin IDs from the plugins directory of the IDE's newest configuration
+// Why: Plugins can be disabled or removed from the IDE without devsetup noticing
+type JetBrainsPluginCheck struct {
+	// IDE is idea, idea-ce, studio, goland, pycharm, or webstorm
+	IDE string `yaml:"ide"`
+
+	// ID is the plugin ID
+	ID string `yaml:"id"`
+}
+
+// TLSCertCheck checks a development certificate
+// What: Parses the PEM certificate and checks its validity period, domains, and (when present) mkcert's CA signature
+// Why: Expired or mismatched dev certificates show up as confusing browser errors weeks after setup
+type TLSCertCheck struct {
+	// File is the PEM certificate ($HOME expanded)
+	File string `yaml:"file"`
+
+	// Domains the certificate must cover (empty = any)
+	Domains []string `yaml:"domains"`
+
+	// MinValid fails the check when the certificate expires sooner than this (default: only expired certificates fail)
+	MinValid time.Duration `yaml:"min_valid"`
+}
+
+// GitHookCheck checks git runs the hook script a git_hooks task registered
+// What: Runs `git hook run` in a checkout (or a scratch repo for global hooks) with the script in probe mode, which
+// only validates the hook config with the manager
+// Why: core.hooksPath can be overridden later (husky, another tool's installer) and hooks silently stop running
+type GitHookCheck struct {
+	// Repo is the checkout to check ($HOME expanded; empty = global hooks)
+	Repo string `yaml:"repo"`
+
+	// Hook is the git hook (default pre-commit)
+	Hook string `yaml:"hook"`
+}
+
 // TomlValueCheck checks TOML file has expected value
 // What: Verify TOML key has expected value
 // Why: Validate TOML configuration edits
@@ -226,20 +922,58 @@ func LoadSetupConfig(path string) (*SetupConfig, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse setup config: %w", err)
 	}
+	config.Path = path
 
-	// Validate
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid setup config: %w", err)
+	// Per-user overlays (~/.config/devsetup/configs.d)
+	overrides, err := LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.resolve(overrides, CurrentMachine); err != nil {
+		return nil, err
 	}
-
 	return &config, nil
 }
 
+// resolve turns a parsed setup.yaml into what setup runs
+// What: Applies overlays, holds back canary tasks, then validates
+// Why: Shared by LoadSetupConfig and config render
+// Params: overrides - overlays in order, machine - identifies the machine for rollouts
+// Returns: Error naming the overlay or the invalid entry
+func (sc *SetupConfig) resolve(overrides []ConfigOverride, machine func() Machine) error {
+	if err := sc.applyOverrides(overrides); err != nil {
+		return err
+	}
+	if err := sc.applyRollout(machine); err != nil {
+		return fmt.Errorf("invalid setup config: %w", err)
+	}
+	if err := sc.Validate(); err != nil {
+		return fmt.Errorf("invalid setup config: %w", err)
+	}
+	return nil
+}
+
+// Task returns the named setup task
+// Returns: The task, nil if setup.yaml has no task by that name
+func (sc *SetupConfig) Task(name string) *SetupTask {
+	if i := sc.taskIndex(name); i >= 0 {
+		return &sc.SetupTasks[i]
+	}
+	return nil
+}
+
 // Validate checks if the setup configuration is valid
 // What: Validates task names are unique, dependencies exist, strategies valid
 // Why: Catch configuration errors early before setup starts
 // Returns: Error describing validation failure, nil if valid
 func (sc *SetupConfig) Validate() error {
+	if !isShell(sc.Shell) {
+		return fmt.Errorf("invalid shell: %s (expected sh, bash, or zsh)", sc.Shell)
+	}
+	if err := validateOwner("setup.yaml", sc.Owner); err != nil {
+		return err
+	}
+
 	names := make(map[string]bool)
 	for _, task := range sc.SetupTasks {
 		// Check unique names
@@ -248,13 +982,208 @@ func (sc *SetupConfig) Validate() error {
 		}
 		names[task.Name] = true
 
+		if !isShell(task.Shell) {
+			return fmt.Errorf("invalid shell for task %s: %s (expected sh, bash, or zsh)", task.Name, task.Shell)
+		}
+
 		// Validate strategy
 		if task.Strategy != "" && task.Strategy != "remote_first" && task.Strategy != "local_only" {
 			return fmt.Errorf("invalid strategy for task %s: %s", task.Name, task.Strategy)
 		}
 
-		// Validate dependencies exist
+		if task.Builtin != "" && task.Builtin != BuiltinHomebrewPolicy && task.Builtin != BuiltinManagedPath {
+			return fmt.Errorf("unknown builtin for task %s: %s", task.Name, task.Builtin)
+		}
+
+		if p := task.Personalize; p != nil {
+			if p.Timezone != "" {
+				if _, err := time.LoadLocation(p.Timezone); err != nil {
+					return fmt.Errorf("task %s: unknown timezone %q", task.Name, p.Timezone)
+				}
+			}
+			if p.KeyRepeat < 0 || p.InitialKeyRepeat < 0 {
+				return fmt.Errorf("task %s: key repeat values must not be negative", task.Name)
+			}
+		}
+
+		if ga := task.GitHubAccess; ga != nil {
+			if len(ga.Orgs) == 0 && len(ga.Teams) == 0 {
+				return fmt.Errorf("task %s: github_access needs orgs or teams", task.Name)
+			}
+			for _, team := range ga.Teams {
+				if !strings.Contains(team, "/") {
+					return fmt.Errorf("task %s: team %q must be written as org/team", task.Name, team)
+				}
+			}
+		}
+
+		if pg := task.Postgres; pg != nil {
+			if pg.Database == "" {
+				return fmt.Errorf("task %s: postgres.database is required", task.Name)
+			}
+			if (pg.Seed == "") != (pg.SeedCheck == "") {
+				return fmt.Errorf("task %s: postgres seed and seed_check must be set together", task.Name)
+			}
+			if pg.Timeout < 0 {
+				return fmt.Errorf("task %s: postgres.timeout must not be negative", task.Name)
+			}
+		}
+		if wc := task.WarmCaches; wc != nil {
+			if len(wc.Repos) == 0 {
+				return fmt.Errorf("task %s: warm_caches.repos is required", task.Name)
+			}
+			for _, kind := range wc.Only {
+				switch kind {
+				case WarmerPnpm, WarmerUv, WarmerCocoapods, WarmerGradle:
+				default:
+					return fmt.Errorf("task %s: unknown warm_caches warmer %q (expected pnpm, uv, cocoapods, or gradle)", task.Name, kind)
+				}
+			}
+			if wc.Timeout < 0 {
+				return fmt.Errorf("task %s: warm_caches.timeout must not be negative", task.Name)
+			}
+		}
+		if err := validateOwner("task "+task.Name, task.Owner); err != nil {
+			return err
+		}
+		if b := task.Browser; b != nil {
+			if !isBrowser(b.Browser) {
+				return fmt.Errorf("task %s: unknown browser %q (expected chrome, edge, or brave)", task.Name, b.Browser)
+			}
+			if len(b.Policies) == 0 && len(b.Extensions) == 0 {
+				return fmt.Errorf("task %s: browser needs policies or extensions", task.Name)
+			}
+			for _, ext := range b.Extensions {
+				if !isExtensionID(ext.ID) {
+					return fmt.Errorf("task %s: invalid extension id %q (32 letters a-p)", task.Name, ext.ID)
+				}
+			}
+		}
+		if rc := task.RemoteCache; rc != nil {
+			if err := rc.validate("task " + task.Name); err != nil {
+				return err
+			}
+		}
+		defaults := 0
+		for i, id := range task.GitIdentities {
+			if id.Name == "" || strings.ContainsAny(id.Name, "/ ") {
+				return fmt.Errorf("task %s: git_identities[%d] needs a name without spaces or slashes", task.Name, i)
+			}
+			if len(id.Dirs) == 0 {
+				defaults++
+			}
+			for _, other := range task.GitIdentities[:i] {
+				if other.Name == id.Name || other.SSHAlias() == id.SSHAlias() {
+					return fmt.Errorf("task %s: git identities %s and %s share a name or SSH alias", task.Name, other.Name, id.Name)
+				}
+			}
+		}
+		if defaults > 1 {
+			return fmt.Errorf("task %s: only one git identity can leave dirs empty (the default identity)", task.Name)
+		}
+		if ai := task.AIAssistant; ai != nil && ai.Tool != AIClaude && ai.Tool != AICopilot {
+			return fmt.Errorf("task %s: unknown ai_assistant tool %q (expected claude or copilot)", task.Name, ai.Tool)
+		}
+		if gh := task.GitHooks; gh != nil {
+			if gh.Manager != HookManagerLefthook && gh.Manager != HookManagerPreCommit {
+				return fmt.Errorf("task %s: unknown hook manager %q (expected lefthook or pre-commit)", task.Name, gh.Manager)
+			}
+			if gh.Config == "" {
+				return fmt.Errorf("task %s: git_hooks.config is required", task.Name)
+			}
+			for _, hook := range gh.Hooks {
+				if !isGitHook(hook) {
+					return fmt.Errorf("task %s: unsupported git hook %q", task.Name, hook)
+				}
+			}
+		}
+		if tls := task.DevTLS; tls != nil {
+			if len(tls.Domains) == 0 {
+				return fmt.Errorf("task %s: dev_tls.domains is required", task.Name)
+			}
+			for _, domain := range tls.Domains {
+				if domain == "" || strings.ContainsAny(domain, " /") || strings.Contains(strings.TrimPrefix(domain, "*."), "*") {
+					return fmt.Errorf("task %s: invalid dev_tls domain %q", task.Name, domain)
+				}
+			}
+			if strings.Contains(tls.Name, "/") {
+				return fmt.Errorf("task %s: dev_tls.name must be a file name", task.Name)
+			}
+			if tls.RenewBefore < 0 {
+				return fmt.Errorf("task %s: dev_tls.renew_before must not be negative", task.Name)
+			}
+		}
+		if jb := task.JetBrains; jb != nil {
+			if !isJetBrainsIDE(jb.IDE) {
+				return fmt.Errorf("task %s: unknown IDE %q (expected idea, idea-ce, studio, goland, pycharm, or webstorm)", task.Name, jb.IDE)
+			}
+			if len(jb.Plugins) == 0 {
+				return fmt.Errorf("task %s: jetbrains.plugins is required", task.Name)
+			}
+			if jb.Timeout < 0 {
+				return fmt.Errorf("task %s: jetbrains.timeout must not be negative", task.Name)
+			}
+		}
+		if es := task.EditorSettings; es != nil {
+			if es.Editor != EditorZed && es.Editor != EditorVSCode {
+				return fmt.Errorf("task %s: unknown editor %q (expected zed or vscode)", task.Name, es.Editor)
+			}
+			if len(es.Settings) == 0 && len(es.Keybindings) == 0 {
+				return fmt.Errorf("task %s: editor_settings needs settings or keybindings", task.Name)
+			}
+		}
+		if m := task.Manual; m != nil && strings.TrimSpace(m.Instructions) == "" {
+			return fmt.Errorf("task %s: manual.instructions is required", task.Name)
+		}
+		if task.OnFailure != nil {
+			if err := task.OnFailure.validate("task " + task.Name); err != nil {
+				return err
+			}
+		}
+		for _, vc := range task.Verify {
+			if q := vc.PostgresQuery; q != nil && (q.Database == "" || q.Query == "") {
+				return fmt.Errorf("task %s: postgres_query needs database and query", task.Name)
+			}
+			if j := vc.JSONValue; j != nil && (j.File == "" || j.Key == "") {
+				return fmt.Errorf("task %s: json_value needs file and key", task.Name)
+			}
+			if be := vc.BrowserExtension; be != nil && (!isBrowser(be.Browser) || !isExtensionID(be.ID)) {
+				return fmt.Errorf("task %s: browser_extension needs a browser (chrome, edge, or brave) and an extension id", task.Name)
+			}
+			if tc := vc.TLSCert; tc != nil && (tc.File == "" || tc.MinValid < 0) {
+				return fmt.Errorf("task %s: tls_cert needs a file (and a non-negative min_valid)", task.Name)
+			}
+			if rc := vc.RemoteCache; rc != nil {
+				if err := rc.validate("task " + task.Name + " verify"); err != nil {
+					return err
+				}
+			}
+			if id := vc.GitIdentity; id != nil && id.Name == "" {
+				return fmt.Errorf("task %s: git_identity needs a name", task.Name)
+			}
+			if ai := vc.AIAuth; ai != nil && ai.Tool != AIClaude && ai.Tool != AICopilot {
+				return fmt.Errorf("task %s: ai_auth: unknown tool %q (expected claude or copilot)", task.Name, ai.Tool)
+			}
+			if gh := vc.GitHook; gh != nil && gh.Hook != "" && !isGitHook(gh.Hook) {
+				return fmt.Errorf("task %s: git_hook: unsupported git hook %q", task.Name, gh.Hook)
+			}
+			if jp := vc.JetBrainsPlugin; jp != nil && (!isJetBrainsIDE(jp.IDE) || jp.ID == "") {
+				return fmt.Errorf("task %s: jetbrains_plugin needs an ide (idea, idea-ce, studio, goland, pycharm, or webstorm) and a plugin id", task.Name)
+			}
+		}
+
+		for i, p := range task.Profiles {
+			if p.Path == "" || p.Identifier == "" {
+				return fmt.Errorf("task %s: profiles[%d] needs path and identifier", task.Name, i)
+			}
+		}
+
+		// Validate dependencies exist (tool: references are resolved by BuildDependencyGraph)
 		for _, dep := range task.DependsOn {
+			if strings.HasPrefix(dep, NodeTool+":") {
+				continue
+			}
+			dep = strings.TrimPrefix(dep, NodeTask+":")
 			found := false
 			for _, t := range sc.SetupTasks {
 				if t.Name == dep {
@@ -268,5 +1197,39 @@ func (sc *SetupConfig) Validate() error {
 		}
 	}
 
-	return nil
+	// Detect task cycles at load time (tools never depend on tasks, so cycles stay within setup.yaml)
+	return sc.checkCycles()
+}
+
+// checkCycles reports circular task dependencies with file:line locations
+// Returns: *CycleError if tasks depend on each other in a loop, nil otherwise
+func (sc *SetupConfig) checkCycles() error {
+	ids := make([]string, 0, len(sc.SetupTasks))
+	deps := make(map[string][]string, len(sc.SetupTasks))
+	tasks := make(map[string]SetupTask, len(sc.SetupTasks))
+	for _, task := range sc.SetupTasks {
+		ids = append(ids, task.Name)
+		tasks[task.Name] = task
+		for _, dep := range task.DependsOn {
+			if strings.HasPrefix(dep, NodeTool+":") {
+				continue
+			}
+			deps[task.Name] = append(deps[task.Name], strings.TrimPrefix(dep, NodeTask+":"))
+		}
+	}
+
+	_, err := topologicalSort(ids, deps)
+	var cycle *CycleError
+	if errors.As(err, &cycle) {
+		cycle.annotate(func(from, to string) string {
+			task := tasks[from]
+			for i, dep := range task.DependsOn {
+				if strings.TrimPrefix(dep, NodeTask+":") == to {
+					return edgeLocation(sc.Path, task.Line, task.dependsOnLines, i)
+				}
+			}
+			return ""
+		})
+	}
+	return err
 }
@@ -0,0 +1,85 @@
+// File: internal/config/shell.go
+// Purpose: Shell selection for install and setup commands
+// Problem: Every command ran under sh, so zsh-specific installer snippets (setopt, ${(s:...)}) failed or needed
+//          wrapping in zsh -c '...'
+// Role: Known shells, per-config defaults with per-tool/per-task overrides, and a preflight that they exist
+// Usage: shell := toolsCfg.ShellFor(tool); exec.Command(shell, "-c", tool.Install.Command); CheckShells(shells)
+// Design choices: A closed set (sh, bash, zsh) so a typo fails at load time; sh stays the default because it is the
+//                 only shell whose behavior every snippet in the org configs already assumes
+// Assumptions: Shells are resolved on PATH (macOS ships /bin/zsh and bash 3.2)
+
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Shells commands can run under
+const (
+	ShellSh   = "sh"
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+)
+
+// ShellQuote single-quotes a value for sh, bash, or zsh
+// Example: ShellQuote("~/My Projects") → '~/My Projects'
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isShell reports whether s is a known shell ("" means inherit the default)
+func isShell(s string) bool {
+	switch s {
+	case "", ShellSh, ShellBash, ShellZsh:
+		return true
+	}
+	return false
+}
+
+// ShellFor returns the shell a tool's commands (check, unless, version, install, on_failure, uninstall) run under
+// Returns: tool shell, else tools.yaml shell, else sh
+func (tc *ToolsConfig) ShellFor(tool Tool) string {
+	return firstShell(tool.Shell, tc.Shell)
+}
+
+// ShellFor returns the shell a setup task's commands run under
+// Returns: task shell, else setup.yaml shell, else sh
+func (sc *SetupConfig) ShellFor(task SetupTask) string {
+	return firstShell(task.Shell, sc.Shell)
+}
+
+// CheckShells verifies shells are installed
+// What: Preflight run before install/setup so a missing shell fails up front instead of mid-run
+// Params: shells - shell names (duplicates allowed)
+// Returns: Error listing the shells not found on PATH
+func CheckShells(shells []string) error {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, s := range shells {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		if _, err := exec.LookPath(s); err != nil {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("shell(s) not found on PATH: %v", missing)
+	}
+	return nil
+}
+
+// firstShell returns the first non-empty shell, defaulting to sh
+func firstShell(shells ...string) string {
+	for _, s := range shells {
+		if s != "" {
+			return s
+		}
+	}
+	return ShellSh
+}
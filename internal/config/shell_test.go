@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestShellFor(t *testing.T) {
+	tc := &ToolsConfig{Shell: ShellBash}
+	if got := tc.ShellFor(Tool{Name: "a"}); got != ShellBash {
+		t.Errorf("default shell = %s, want bash", got)
+	}
+	if got := tc.ShellFor(Tool{Name: "a", Shell: ShellZsh}); got != ShellZsh {
+		t.Errorf("tool shell = %s, want zsh", got)
+	}
+	if got := (&SetupConfig{}).ShellFor(SetupTask{Name: "b"}); got != ShellSh {
+		t.Errorf("unset shell = %s, want sh", got)
+	}
+
+	bad := &SetupConfig{SetupTasks: []SetupTask{{Name: "b", Shell: "fish"}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate() accepted shell: fish")
+	}
+}
+
+func TestCheckShells(t *testing.T) {
+	if err := CheckShells([]string{ShellSh, ShellSh}); err != nil {
+		t.Errorf("CheckShells(sh) = %v", err)
+	}
+	if err := CheckShells([]string{"no-such-shell-devsetup"}); err == nil {
+		t.Error("CheckShells() accepted a missing shell")
+	}
+}
@@ -0,0 +1,206 @@
+// File: internal/config/stamp.go
+// Purpose: Git commit stamp of the embedded configs and drift detection against a configs/ checkout
+// Problem: A configs/ checkout on disk overrides the configs built into the binary; when the checkout tracks a
+//          newer (or older) ref than the binary was built from, tools appear or vanish with no hint why, and a
+//          binary too old for the checkout's schema fails in confusing ways
+// Role: Records the commit the embedded configs came from (set from -ldflags at build time) and compares the
+//       checkout's tools and tasks with the embedded ones, so commands can warn before acting on a mismatch
+// Usage: config.SetEmbeddedCommit(gitCommit); drift, _ := config.DetectConfigDrift("configs"); if drift.Significant() {...}
+// Design choices: Drift counts tools and tasks, not lines: entries only on one side always count, edits to shared
+//                 entries only past driftChangedThreshold, since editing a checkout is how configs are developed;
+//                 the same commit on both sides is never drift, whatever uncommitted edits the checkout has
+// Assumptions: The checkout is a git work tree of the config repo; the stamp is the short SHA from `git rev-parse`
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// driftChangedThreshold is how many shared entries must differ before edits alone count as drift
+const driftChangedThreshold = 5
+
+// embeddedCommit is the git commit the embedded configs were built from ("" for unstamped builds)
+var embeddedCommit string
+
+// SetEmbeddedCommit records the commit the embedded configs were built from
+// Params: sha - commit from -ldflags (main.gitCommit); "unknown" and "" mean unstamped
+func SetEmbeddedCommit(sha string) {
+	if sha == "unknown" {
+		sha = ""
+	}
+	embeddedCommit = sha
+}
+
+// EmbeddedCommit returns the commit the embedded configs were built from
+// Returns: Short SHA, "" for unstamped (go run / go build) binaries
+func EmbeddedCommit() string {
+	return embeddedCommit
+}
+
+// ConfigDrift is how a configs/ checkout differs from the embedded configs
+type ConfigDrift struct {
+	// Dir is the checkout (e.g. "configs")
+	Dir string
+
+	// Commit is the checkout's HEAD ("" if not a git work tree)
+	Commit string
+
+	// EmbeddedCommit is the commit the binary's configs came from ("" if unstamped)
+	EmbeddedCommit string
+
+	// Added are entries only in the checkout (e.g. "tool zed")
+	Added []string
+
+	// Removed are entries only in the binary
+	Removed []string
+
+	// Changed counts entries in both that differ
+	Changed int
+}
+
+// Significant reports whether the drift is worth a warning
+// Returns: True if an entry exists on one side only, or at least driftChangedThreshold shared entries differ
+func (d *ConfigDrift) Significant() bool {
+	return d != nil && (len(d.Added) > 0 || len(d.Removed) > 0 || d.Changed >= driftChangedThreshold)
+}
+
+// String summarizes the drift for a warning
+// Example: "2 only in configs/ (tool zed, task x), 1 only in the binary (tool yarn), 5 changed"
+func (d *ConfigDrift) String() string {
+	var parts []string
+	if len(d.Added) > 0 {
+		parts = append(parts, fmt.Sprintf("%d only in %s/ (%s)", len(d.Added), d.Dir, strings.Join(d.Added, ", ")))
+	}
+	if len(d.Removed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d only in the binary (%s)", len(d.Removed), strings.Join(d.Removed, ", ")))
+	}
+	if d.Changed > 0 {
+		parts = append(parts, fmt.Sprintf("%d changed", d.Changed))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DetectConfigDrift compares a configs/ checkout with the embedded configs
+// What: Diffs tools.yaml and setup.yaml entries by name, as parsed (no overlays or rollouts)
+// Why: Install, setup, and plan warn before acting on configs the binary wasn't built with
+// Params: dir - checkout directory (e.g. "configs")
+// Returns: nil when the checkout has neither file (the embedded configs are used) or is at the embedded commit; error if a file doesn't parse
+func DetectConfigDrift(dir string) (*ConfigDrift, error) {
+	drift := &ConfigDrift{Dir: dir, EmbeddedCommit: embeddedCommit, Commit: checkoutCommit(dir)}
+	if sameCommit(drift.Commit, drift.EmbeddedCommit) {
+		return nil, nil
+	}
+
+	found := false
+	for _, file := range []struct {
+		name  string
+		kind  string
+		parse func([]byte) (map[string][]byte, error)
+	}{
+		{"tools.yaml", NodeTool, toolEntries},
+		{"setup.yaml", NodeTask, taskEntries},
+	} {
+		local, err := os.ReadFile(filepath.Join(dir, file.name))
+		if err != nil {
+			continue
+		}
+		embedded, err := readEmbeddedFile(file.name)
+		if err != nil {
+			continue
+		}
+		found = true
+
+		mine, err := file.parse(local)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, file.name), err)
+		}
+		theirs, err := file.parse(embedded)
+		if err != nil {
+			return nil, fmt.Errorf("embedded %s: %w", file.name, err)
+		}
+		drift.compare(file.kind, mine, theirs)
+	}
+	if !found {
+		return nil, nil
+	}
+	sort.Strings(drift.Added)
+	sort.Strings(drift.Removed)
+	return drift, nil
+}
+
+// compare adds one file's differences
+// Params: kind - NodeTool or NodeTask (label prefix), mine - checkout entries, theirs - embedded entries
+func (d *ConfigDrift) compare(kind string, mine, theirs map[string][]byte) {
+	for name, entry := range mine {
+		other, ok := theirs[name]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, kind+" "+name)
+		case !bytes.Equal(entry, other):
+			d.Changed++
+		}
+	}
+	for name := range theirs {
+		if _, ok := mine[name]; !ok {
+			d.Removed = append(d.Removed, kind+" "+name)
+		}
+	}
+}
+
+// toolEntries parses tools.yaml into name → normalized entry
+func toolEntries(data []byte) (map[string][]byte, error) {
+	var tc ToolsConfig
+	if err := yaml.Unmarshal(data, &tc); err != nil {
+		return nil, err
+	}
+	entries := make(map[string][]byte, len(tc.Tools))
+	for _, tool := range tc.Tools {
+		out, err := yaml.Marshal(tool)
+		if err != nil {
+			return nil, err
+		}
+		entries[tool.Name] = out
+	}
+	return entries, nil
+}
+
+// taskEntries parses setup.yaml into name → normalized entry
+func taskEntries(data []byte) (map[string][]byte, error) {
+	var sc SetupConfig
+	if err := yaml.Unmarshal(data, &sc); err != nil {
+		return nil, err
+	}
+	entries := make(map[string][]byte, len(sc.SetupTasks))
+	for _, task := range sc.SetupTasks {
+		out, err := yaml.Marshal(task)
+		if err != nil {
+			return nil, err
+		}
+		entries[task.Name] = out
+	}
+	return entries, nil
+}
+
+// sameCommit reports whether two short SHAs (possibly of different lengths) name the same commit
+func sameCommit(a, b string) bool {
+	return a != "" && b != "" && (strings.HasPrefix(a, b) || strings.HasPrefix(b, a))
+}
+
+// checkoutCommit returns the short HEAD of the git work tree holding dir
+// Returns: Short SHA, "" if dir isn't in a git work tree or git is missing
+func checkoutCommit(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
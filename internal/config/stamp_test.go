@@ -0,0 +1,33 @@
+package config
+
+import "testing"
+
+func TestConfigDrift(t *testing.T) {
+	d := &ConfigDrift{Dir: "configs"}
+	d.compare(NodeTool,
+		map[string][]byte{"git": []byte("a"), "zed": []byte("z"), "jq": []byte("new")},
+		map[string][]byte{"git": []byte("a"), "yarn": []byte("y"), "jq": []byte("old")})
+	if got, want := d.String(), "1 only in configs/ (tool zed), 1 only in the binary (tool yarn), 1 changed"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if !d.Significant() {
+		t.Error("added and removed entries should be significant")
+	}
+
+	edits := &ConfigDrift{Changed: driftChangedThreshold - 1}
+	if edits.Significant() {
+		t.Error("a few edited entries should not be significant")
+	}
+	if (*ConfigDrift)(nil).Significant() {
+		t.Error("nil drift should not be significant")
+	}
+
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{{"abc1234", "abc1234", true}, {"abc1234", "abc12345", true}, {"abc1234", "def5678", false}, {"", "", false}} {
+		if got := sameCommit(tc.a, tc.b); got != tc.want {
+			t.Errorf("sameCommit(%q, %q) = %v", tc.a, tc.b, got)
+		}
+	}
+}
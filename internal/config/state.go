@@ -9,8 +9,10 @@
 package config
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -26,6 +28,13 @@ type State struct {
 	// Configured maps setup task name to completion status
 	Configured map[string]bool `json:"configured"`
 
+	// CompletedTasks maps tool name to its completion in an install run that hasn't finished yet (cleared when
+	// a full install succeeds), so the next install resumes after the tools already done
+	CompletedTasks map[string]CompletedTask `json:"completed_tasks,omitempty"`
+
+	// Acknowledged maps manual setup task name to when the user marked it done
+	Acknowledged map[string]time.Time `json:"acknowledged,omitempty"`
+
 	// LastInstall timestamp
 	LastInstall time.Time `json:"last_install"`
 
@@ -34,6 +43,9 @@ type State struct {
 
 	// Version of devsetup that created this state
 	Version string `json:"version"`
+
+	// Updates are update notification preferences and the last update check
+	Updates *UpdatePrefs `json:"updates,omitempty"`
 }
 
 // ToolState represents state of an installed tool
@@ -48,6 +60,26 @@ type ToolState struct {
 
 	// InstalledAt timestamp
 	InstalledAt time.Time `json:"installed_at"`
+
+	// Checksum is the SHA256 of the executable at install time (for drift detection)
+	Checksum string `json:"checksum,omitempty"`
+
+	// Direct marks a tool installed without Homebrew (`install --without-brew`); the next normal install replaces it
+	Direct bool `json:"direct,omitempty"`
+
+	// DirectPaths are the files the direct install placed, removed once Homebrew's install replaces them
+	DirectPaths []string `json:"direct_paths,omitempty"`
+}
+
+// CompletedTask records a tool finished by an install run that failed or was interrupted
+// What: When the tool finished and the digest of its tools.yaml entry at the time
+// Why: A resumed install skips the tool only while its declaration is unchanged
+type CompletedTask struct {
+	// At is when the tool was installed (or found installed)
+	At time.Time `json:"at"`
+
+	// Spec is the SHA256 of the tool's declaration (see installer.toolSpec)
+	Spec string `json:"spec"`
 }
 
 // GetStateDir returns the directory for state storage
@@ -63,6 +95,18 @@ func GetStateDir() string {
 	return filepath.Join(home, ".local", "share", "devsetup")
 }
 
+// GetUserConfigDir returns the directory for per-user devsetup settings
+// What: Returns ~/.config/devsetup path
+// Why: User-edited files (ignore lists, exceptions) live apart from machine-written state
+// Returns: Absolute path to the user config directory
+func GetUserConfigDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "/tmp/devsetup-config"
+	}
+	return filepath.Join(home, ".config", "devsetup")
+}
+
 // GetStatePath returns the full path to state.json
 // What: Returns full path to state file
 // Why: Single source of truth for state file location
@@ -117,7 +161,8 @@ func LoadState() (*State, error) {
 // Params: state - State object to save
 // Returns: Error if save fails, nil if successful
 // Example: err := SaveState(state)
-// Edge cases: Creates state directory if it doesn't exist
+// Edge cases: Creates state directory if it doesn't exist; writes a temp file and renames it so a run killed
+// mid-save (terminal closed) never leaves a truncated state.json
 func SaveState(state *State) error {
 	stateDir := GetStateDir()
 
@@ -132,9 +177,13 @@ func SaveState(state *State) error {
 		return fmt.Errorf("failed to serialize state: %w", err)
 	}
 
-	// Write to file
+	// Write to a temp file, then atomically replace state.json
 	statePath := GetStatePath()
-	if err := os.WriteFile(statePath, data, 0644); err != nil {
+	tmp := statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp, statePath); err != nil {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
 
@@ -151,14 +200,62 @@ func MarkToolInstalled(state *State, name, version, path string) {
 		state.Installed = make(map[string]ToolState)
 	}
 
+	// Checksum is best-effort: tools without a resolvable binary have none
+	checksum := ""
+	if filepath.IsAbs(path) {
+		checksum, _ = FileChecksum(path)
+	}
+
 	state.Installed[name] = ToolState{
 		Version:     version,
 		Path:        path,
 		InstalledAt: time.Now(),
+		Checksum:    checksum,
 	}
 	state.LastInstall = time.Now()
 }
 
+// MarkToolDirect marks a recorded tool as installed without Homebrew
+// Params: state - State to update, name - tool name (already recorded by MarkToolInstalled), paths - files the install placed
+// Edge cases: Unrecorded tools are ignored
+func MarkToolDirect(state *State, name string, paths []string) {
+	tool, ok := state.Installed[name]
+	if !ok {
+		return
+	}
+	tool.Direct = true
+	tool.DirectPaths = paths
+	state.Installed[name] = tool
+}
+
+// MarkToolUninstalled removes a tool from the state
+// Params: state - State to update, name - tool name
+func MarkToolUninstalled(state *State, name string) {
+	delete(state.Installed, name)
+	delete(state.CompletedTasks, name)
+}
+
+// FileChecksum computes the SHA256 checksum of a file
+// What: Hashes file contents and returns hex-encoded digest
+// Why: Detect binaries replaced outside devsetup (deep verify drift check)
+// Params: path - absolute path to file
+// Returns: Hex SHA256 string and error if file can't be read
+// Example: sum, err := FileChecksum("/opt/homebrew/bin/git")
+func FileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
 // MarkTaskConfigured marks a setup task as completed
 // What: Records that a setup task was successfully completed
 // Why: Track configuration for status reporting and skip on re-run
@@ -173,6 +270,35 @@ func MarkTaskConfigured(state *State, name string) {
 	state.LastSetup = time.Now()
 }
 
+// MarkTaskCompleted records that an install run finished a tool
+// Params: state - State to update, name - tool name, spec - digest of the tool's declaration
+// Example: MarkTaskCompleted(state, "git", spec)
+func MarkTaskCompleted(state *State, name, spec string) {
+	if state.CompletedTasks == nil {
+		state.CompletedTasks = make(map[string]CompletedTask)
+	}
+	state.CompletedTasks[name] = CompletedTask{At: time.Now(), Spec: spec}
+}
+
+// ClearCompletedTasks forgets the tools finished by an earlier install run
+// What: Called when an install run completes, or when --force starts one over
+func ClearCompletedTasks(state *State) {
+	state.CompletedTasks = nil
+}
+
+// AcknowledgeTask records that the user did a manual setup task
+// What: Marks the task configured and remembers when it was acknowledged
+// Why: Manual steps are done outside devsetup; the acknowledgment is all there is to track them by
+// Params: state - State to update, name - manual task name
+// Example: AcknowledgeTask(state, "vpn-access")
+func AcknowledgeTask(state *State, name string) {
+	if state.Acknowledged == nil {
+		state.Acknowledged = make(map[string]time.Time)
+	}
+	state.Acknowledged[name] = time.Now()
+	MarkTaskConfigured(state, name)
+}
+
 // IsToolInstalled checks if a tool is in the state
 // What: Checks if tool name exists in installed map
 // Why: Quick check for tool installation status
@@ -0,0 +1,249 @@
+// File: internal/config/strategy.go
+// Purpose: Typed install strategies (brew, cask, go, cargo, npm) for tools.yaml
+// Problem: Every tool was a hand-written shell command, so common installs were copy-pasted with small mistakes
+//          (missing --cask, no --locked, npm without -g) and each author invented their own check and version probe
+// Role: Expands install.type + install.package into the install command, and fills in a default check and
+//       version_command when the tool doesn't declare its own
+// Usage: Applied by LoadToolsConfig after overlays; the rest of devsetup keeps reading install.command
+// Design choices: Strategies expand to the same commands authors wrote by hand, so brew package detection, policy
+//                 denylists, exports, and plans work unchanged; explicit check/version_command always win;
+//                 values are shell-quoted only when they need it, so generated commands stay readable
+// Assumptions: The package manager (brew, go, cargo, npm) is installed by an earlier tool the strategy depends on
+
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Typed install strategies
+const (
+	// InstallTypeBrew installs a Homebrew formula
+	InstallTypeBrew = "brew"
+	// InstallTypeCask installs a Homebrew cask
+	InstallTypeCask = "cask"
+	// InstallTypeGo installs a Go command with go install
+	InstallTypeGo = "go"
+	// InstallTypeCargo installs a Rust crate with cargo install
+	InstallTypeCargo = "cargo"
+	// InstallTypeNpm installs a global npm package
+	InstallTypeNpm = "npm"
+)
+
+// goMajorSuffix matches the /vN element of a Go module path
+var goMajorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// shellSafe matches values that need no quoting in sh, bash, or zsh
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9@%+=:,./_-]+$`)
+
+// PackageInstall is the package a typed strategy installs
+// What: One package for brew, cask, go, cargo, or npm installs
+// Why: Structured fields replace hand-written install, check, and version commands
+type PackageInstall struct {
+	// Name is the formula, cask token, Go package path, crate, or npm package
+	Name string `yaml:"name"`
+
+	// Version is the go/cargo/npm version ("" = latest); brew and cask install the current version
+	Version string `yaml:"version"`
+
+	// Tap is the Homebrew tap the formula or cask comes from (brew/cask only)
+	Tap string `yaml:"tap"`
+
+	// Bin is the command the package provides, when it isn't derived from the name (e.g. claude for @anthropic-ai/claude-code)
+	Bin string `yaml:"bin"`
+
+	// Args are extra flags passed to the package manager's install command
+	Args []string `yaml:"args"`
+}
+
+// IsStrategy reports whether the install uses a typed package strategy
+func (ti ToolInstall) IsStrategy() bool {
+	switch ti.Type {
+	case InstallTypeBrew, InstallTypeCask, InstallTypeGo, InstallTypeCargo, InstallTypeNpm:
+		return true
+	}
+	return false
+}
+
+// StrategyCommand renders the install command for a typed strategy
+// Returns: Shell command, "" when the install isn't a typed strategy
+// Example: {type: cask, package: {name: zed}} → "brew install --cask zed"
+func (ti ToolInstall) StrategyCommand() string {
+	pkg := ti.Package
+	if !ti.IsStrategy() || pkg == nil {
+		return ""
+	}
+	args := quoteAll(pkg.Args)
+	switch ti.Type {
+	case InstallTypeBrew:
+		return join("brew install", args, quote(pkg.qualified()))
+	case InstallTypeCask:
+		return join("brew install --cask", args, quote(pkg.qualified()))
+	case InstallTypeGo:
+		version := pkg.Version
+		if version == "" {
+			version = "latest"
+		}
+		return join("go install", args, quote(pkg.Name+"@"+version))
+	case InstallTypeCargo:
+		cmd := "cargo install --locked"
+		if pkg.Version != "" {
+			cmd += " --version " + quote(pkg.Version)
+		}
+		return join(cmd, args, quote(pkg.Name))
+	default:
+		spec := pkg.Name
+		if pkg.Version != "" {
+			spec += "@" + pkg.Version
+		}
+		return join("npm install -g", args, quote(spec))
+	}
+}
+
+// StrategyCheck renders the default already-installed check for a typed strategy
+// Returns: Shell command exiting 0 when installed ("" when the install isn't a typed strategy)
+// Edge cases: A declared bin is checked on PATH for every strategy; brew and cask otherwise ask brew itself
+func (ti ToolInstall) StrategyCheck() string {
+	pkg := ti.Package
+	if !ti.IsStrategy() || pkg == nil {
+		return ""
+	}
+	switch {
+	case pkg.Bin != "":
+		return "command -v " + quote(pkg.Bin)
+	case ti.Type == InstallTypeBrew:
+		return "brew list --formula " + quote(pkg.Name) + " >/dev/null 2>&1"
+	case ti.Type == InstallTypeCask:
+		return "brew list --cask " + quote(pkg.Name) + " >/dev/null 2>&1"
+	}
+	return "command -v " + quote(pkg.binary(ti.Type))
+}
+
+// StrategyVersionCommand renders the default version probe for a typed strategy
+// Returns: Shell command whose output contains the installed version ("" to fall back to <tool> --version)
+func (ti ToolInstall) StrategyVersionCommand() string {
+	pkg := ti.Package
+	if !ti.IsStrategy() || pkg == nil {
+		return ""
+	}
+	switch ti.Type {
+	case InstallTypeBrew:
+		return "brew list --formula --versions " + quote(pkg.Name)
+	case InstallTypeCask:
+		return "brew list --cask --versions " + quote(pkg.Name)
+	case InstallTypeCargo:
+		return "cargo install --list | grep " + quote("^"+pkg.Name+" v")
+	case InstallTypeNpm:
+		return "npm ls -g --depth=0 " + quote(pkg.Name)
+	}
+	return quote(pkg.binary(ti.Type)) + " --version"
+}
+
+// Binary returns the command a tool provides on PATH
+// Returns: package.bin, the name derived from a go/cargo/npm package, or the tool name
+func (t Tool) Binary() string {
+	switch {
+	case t.Install.Package != nil && t.Install.Package.Bin != "":
+		return t.Install.Package.Bin
+	case t.Install.Package != nil && (t.Install.Type == InstallTypeGo || t.Install.Type == InstallTypeCargo || t.Install.Type == InstallTypeNpm):
+		return t.Install.Package.binary(t.Install.Type)
+	}
+	return t.Name
+}
+
+// resolveStrategies expands typed strategies into install commands, checks, and version commands
+// What: For each typed tool, sets install.command and fills check/version_command when they are empty
+// Why: Everything downstream (installer, policies, exports, plans) reads install.command
+// Returns: Error naming the tool when a strategy is incomplete or also has a hand-written command
+func (tc *ToolsConfig) resolveStrategies() error {
+	for i := range tc.Tools {
+		tool := &tc.Tools[i]
+		if !tool.Install.IsStrategy() {
+			if tool.Install.Package != nil {
+				return fmt.Errorf("tool %s: install.package needs a package type (brew, cask, go, cargo, or npm)", tool.Name)
+			}
+			continue
+		}
+		if err := tool.Install.validateStrategy(); err != nil {
+			return fmt.Errorf("tool %s: %w", tool.Name, err)
+		}
+		command := tool.Install.StrategyCommand()
+		if tool.Install.Command != "" && tool.Install.Command != command {
+			return fmt.Errorf("tool %s: install.command can't be combined with type %s (the command is generated)", tool.Name, tool.Install.Type)
+		}
+		tool.Install.Command = command
+		if tool.Check == "" {
+			tool.Check = tool.Install.StrategyCheck()
+		}
+		if tool.VersionCommand == "" {
+			tool.VersionCommand = tool.Install.StrategyVersionCommand()
+		}
+	}
+	return nil
+}
+
+// validateStrategy checks a typed strategy's package fields
+func (ti ToolInstall) validateStrategy() error {
+	pkg := ti.Package
+	if pkg == nil || pkg.Name == "" {
+		return fmt.Errorf("package.name is required for %s installs", ti.Type)
+	}
+	brew := ti.Type == InstallTypeBrew || ti.Type == InstallTypeCask
+	if brew && pkg.Version != "" {
+		return fmt.Errorf("package.version is not supported for %s installs (Homebrew installs the current version)", ti.Type)
+	}
+	if !brew && pkg.Tap != "" {
+		return fmt.Errorf("package.tap is only valid for brew and cask installs")
+	}
+	return nil
+}
+
+// qualified returns the formula or cask name with its tap ("user/tap/name")
+func (p *PackageInstall) qualified() string {
+	if p.Tap == "" {
+		return p.Name
+	}
+	return strings.TrimSuffix(p.Tap, "/") + "/" + p.Name
+}
+
+// binary returns the command a go/cargo/npm package provides
+// Example: "github.com/golangci/golangci-lint/v2/cmd/golangci-lint" → "golangci-lint"; "@biomejs/biome" → "biome"
+func (p *PackageInstall) binary(installType string) string {
+	if p.Bin != "" {
+		return p.Bin
+	}
+	name := p.Name
+	if installType == InstallTypeGo {
+		name = strings.TrimSuffix(name, "/...")
+		if base := path.Base(name); goMajorSuffix.MatchString(base) {
+			name = path.Dir(name)
+		}
+	}
+	return path.Base(name)
+}
+
+// join joins a command with its flags and final argument
+func join(cmd string, args []string, last string) string {
+	parts := append([]string{cmd}, args...)
+	return strings.Join(append(parts, last), " ")
+}
+
+// quoteAll quotes each value for the shell
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quote(v)
+	}
+	return quoted
+}
+
+// quote single-quotes a value for the shell unless it is plainly safe
+func quote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return ShellQuote(s)
+}
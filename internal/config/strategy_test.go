@@ -0,0 +1,70 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveStrategies(t *testing.T) {
+	tc := &ToolsConfig{Tools: []Tool{
+		{Name: "git", Install: ToolInstall{Type: InstallTypeBrew, Package: &PackageInstall{Name: "git"}}},
+		{Name: "zed", Check: "command -v zed", Install: ToolInstall{Type: InstallTypeCask, Package: &PackageInstall{Name: "zed", Args: []string{"--no-quarantine"}}}},
+		{Name: "lint", Install: ToolInstall{Type: InstallTypeGo, Package: &PackageInstall{Name: "github.com/golangci/golangci-lint/v2/cmd/golangci-lint", Version: "v2.1.0"}}},
+		{Name: "gopls", Install: ToolInstall{Type: InstallTypeGo, Package: &PackageInstall{Name: "golang.org/x/tools/gopls/v2"}}},
+		{Name: "rg", Install: ToolInstall{Type: InstallTypeCargo, Package: &PackageInstall{Name: "ripgrep", Bin: "rg", Version: "14.1.0"}}},
+		{Name: "claude", Install: ToolInstall{Type: InstallTypeNpm, Package: &PackageInstall{Name: "@anthropic-ai/claude-code", Version: "^1.0", Bin: "claude"}}},
+	}}
+	if err := tc.resolveStrategies(); err != nil {
+		t.Fatalf("resolveStrategies: %v", err)
+	}
+
+	tests := []struct {
+		command, check, versionCommand string
+	}{
+		{"brew install git", "brew list --formula git >/dev/null 2>&1", "brew list --formula --versions git"},
+		{"brew install --cask --no-quarantine zed", "command -v zed", "brew list --cask --versions zed"},
+		{"go install github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.1.0", "command -v golangci-lint", "golangci-lint --version"},
+		{"go install golang.org/x/tools/gopls/v2@latest", "command -v gopls", "gopls --version"},
+		{"cargo install --locked --version 14.1.0 ripgrep", "command -v rg", "cargo install --list | grep '^ripgrep v'"},
+		{"npm install -g '@anthropic-ai/claude-code@^1.0'", "command -v claude", "npm ls -g --depth=0 @anthropic-ai/claude-code"},
+	}
+	for i, tt := range tests {
+		tool := tc.Tools[i]
+		if tool.Install.Command != tt.command {
+			t.Errorf("%s command = %q, want %q", tool.Name, tool.Install.Command, tt.command)
+		}
+		if tool.Check != tt.check {
+			t.Errorf("%s check = %q, want %q", tool.Name, tool.Check, tt.check)
+		}
+		if tool.VersionCommand != tt.versionCommand {
+			t.Errorf("%s version_command = %q, want %q", tool.Name, tool.VersionCommand, tt.versionCommand)
+		}
+	}
+
+	// Resolving twice (e.g. a config validated again) is a no-op
+	if err := tc.resolveStrategies(); err != nil {
+		t.Errorf("second resolve: %v", err)
+	}
+	if f, _ := tc.Tools[0].Install.BrewPackages(); len(f) != 1 || f[0] != "git" {
+		t.Errorf("BrewPackages() = %v, want [git]", f)
+	}
+}
+
+func TestResolveStrategiesErrors(t *testing.T) {
+	tests := []struct {
+		install ToolInstall
+		want    string
+	}{
+		{ToolInstall{Type: InstallTypeBrew}, "package.name is required"},
+		{ToolInstall{Type: InstallTypeBrew, Command: "brew install other", Package: &PackageInstall{Name: "git"}}, "can't be combined"},
+		{ToolInstall{Type: InstallTypeCask, Package: &PackageInstall{Name: "zed", Version: "1.0"}}, "package.version is not supported"},
+		{ToolInstall{Type: InstallTypeNpm, Package: &PackageInstall{Name: "x", Tap: "a/b"}}, "package.tap is only valid"},
+		{ToolInstall{Command: "true", Package: &PackageInstall{Name: "x"}}, "needs a package type"},
+	}
+	for _, tt := range tests {
+		tc := &ToolsConfig{Tools: []Tool{{Name: "t", Install: tt.install}}}
+		if err := tc.resolveStrategies(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("resolveStrategies(%+v) = %v, want error containing %q", tt.install, err, tt.want)
+		}
+	}
+}
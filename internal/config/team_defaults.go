@@ -0,0 +1,163 @@
+// File: internal/config/team_defaults.go
+// Purpose: Data models for defaults.yaml (team-shared answers and profiles)
+// Problem: Every developer answered the same prompts and hand-picked the same extra tools as the rest of their team
+// Role: Default prompt answers plus named profiles (extra tools/tasks) assigned by GitHub team
+// Usage: d, err := LoadTeamDefaults("configs/defaults.yaml"); names := d.ProfilesFor(teams); d.WriteProfileOverlays(names)
+// Design choices: A profile is a configs.d overlay (same add/disable/timeouts vocabulary); assigned profiles are
+//                 written into configs.d as 00-team-<name>.yaml so every command (status, verify, serve) sees them
+//                 without querying GitHub; profiles are kept as raw YAML so the written overlay is what the org wrote
+// Assumptions: GitHub teams model departments; team slugs are "org/team" as reported by `gh api user/teams`
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TeamOverlayPrefix marks configs.d overlays written from team profiles
+const TeamOverlayPrefix = "00-team-"
+
+// TeamDefaults represents the complete defaults.yaml file
+// What: Default answers, named profiles, and team → profile assignments
+// Why: Decisions made once per team instead of once per laptop
+type TeamDefaults struct {
+	// Answers are default prompt answers keyed by the prompt's env_var
+	Answers map[string]string `yaml:"answers"`
+
+	// Profiles are named overlays (tools/setup add, disable, timeouts)
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+
+	// Assignments map GitHub teams to profiles
+	Assignments []TeamAssignment `yaml:"assignments"`
+}
+
+// TeamAssignment assigns profiles to members of a GitHub team
+type TeamAssignment struct {
+	// Team is the GitHub team as "org/team-slug"
+	Team string `yaml:"team"`
+
+	// Profiles are profile names applied to the team's members
+	Profiles []string `yaml:"profiles"`
+}
+
+// LoadTeamDefaults loads and parses defaults.yaml
+// What: Reads defaults.yaml from filesystem or embedded, parses and validates it
+// Why: Install and setup apply team profiles and answers before loading tools/setup configs
+// Params: path - path to defaults.yaml (e.g., "configs/defaults.yaml")
+// Returns: Parsed TeamDefaults (empty if no defaults.yaml exists) and error if it is invalid
+// Example: d, err := LoadTeamDefaults("configs/defaults.yaml")
+func LoadTeamDefaults(path string) (*TeamDefaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		data, err = readEmbeddedFile(path)
+		if err != nil {
+			return &TeamDefaults{}, nil
+		}
+	}
+
+	var defaults TeamDefaults
+	if err := yaml.Unmarshal(data, &defaults); err != nil {
+		return nil, fmt.Errorf("failed to parse team defaults: %w", err)
+	}
+	if err := defaults.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid team defaults: %w", err)
+	}
+	return &defaults, nil
+}
+
+// Validate checks profiles parse as overlays and assignments reference known profiles
+// Returns: Error describing the first invalid entry, nil if valid
+func (d *TeamDefaults) Validate() error {
+	for name, node := range d.Profiles {
+		if strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid profile name: %s", name)
+		}
+		var override ConfigOverride
+		if err := node.Decode(&override); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	for _, a := range d.Assignments {
+		if !strings.Contains(a.Team, "/") {
+			return fmt.Errorf("team %q must be written as org/team", a.Team)
+		}
+		for _, p := range a.Profiles {
+			if _, ok := d.Profiles[p]; !ok {
+				return fmt.Errorf("team %s: unknown profile %s", a.Team, p)
+			}
+		}
+	}
+	return nil
+}
+
+// ProfilesFor returns the profiles assigned to a set of teams
+// Params: teams - the user's teams as "org/team" (case-insensitive)
+// Returns: Sorted, de-duplicated profile names
+func (d *TeamDefaults) ProfilesFor(teams []string) []string {
+	member := make(map[string]bool, len(teams))
+	for _, t := range teams {
+		member[strings.ToLower(t)] = true
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, a := range d.Assignments {
+		if !member[strings.ToLower(a.Team)] {
+			continue
+		}
+		for _, p := range a.Profiles {
+			if !seen[p] {
+				seen[p] = true
+				names = append(names, p)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteProfileOverlays materializes assigned profiles in configs.d
+// What: Writes 00-team-<name>.yaml per profile and removes team overlays no longer assigned
+// Why: The regular overlay loader then applies them for every command
+// Params: names - assigned profile names (ProfilesFor)
+// Returns: Paths written, error if configs.d can't be updated
+// Edge cases: 00-team- files sort first, so personal overlays can still override team profiles
+func (d *TeamDefaults) WriteProfileOverlays(names []string) ([]string, error) {
+	dir := OverridesDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	keep := make(map[string]bool, len(names))
+	var written []string
+	for _, name := range names {
+		node := d.Profiles[name]
+		data, err := yaml.Marshal(&node)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		path := filepath.Join(dir, TeamOverlayPrefix+name+".yaml")
+		header := fmt.Sprintf("# Team profile %q from defaults.yaml - managed by devsetup, changes are overwritten\n", name)
+		if err := os.WriteFile(path, append([]byte(header), data...), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		keep[path] = true
+		written = append(written, path)
+	}
+
+	stale, _ := filepath.Glob(filepath.Join(dir, TeamOverlayPrefix+"*.yaml"))
+	for _, path := range stale {
+		if !keep[path] {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestTeamDefaultsProfileOverlays(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var d TeamDefaults
+	if err := yaml.Unmarshal([]byte(`
+profiles:
+  mobile:
+    setup:
+      disable: [gemini-api-key]
+  web:
+    tools:
+      disable: [zed]
+assignments:
+  - team: RKInnovate/mobile
+    profiles: [mobile]
+  - team: rkinnovate/web
+    profiles: [web, mobile]
+`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := d.ProfilesFor([]string{"rkinnovate/mobile"}); !reflect.DeepEqual(got, []string{"mobile"}) {
+		t.Errorf("ProfilesFor(mobile) = %v", got)
+	}
+	if got := d.ProfilesFor([]string{"rkinnovate/web", "rkinnovate/mobile"}); !reflect.DeepEqual(got, []string{"mobile", "web"}) {
+		t.Errorf("ProfilesFor(web, mobile) = %v", got)
+	}
+
+	if _, err := d.WriteProfileOverlays([]string{"mobile", "web"}); err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := LoadOverrides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 2 || !reflect.DeepEqual(overrides[0].Setup.Disable, []string{"gemini-api-key"}) {
+		t.Fatalf("unexpected overlays: %+v", overrides)
+	}
+
+	// Leaving a team removes its overlay but keeps personal ones
+	writeFile(t, filepath.Join(OverridesDir(), "50-me.yaml"), "tools:\n  disable: [uv]\n")
+	if _, err := d.WriteProfileOverlays([]string{"mobile"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(OverridesDir(), "00-team-web.yaml")); !os.IsNotExist(err) {
+		t.Error("stale team overlay was not removed")
+	}
+	if _, err := os.Stat(filepath.Join(OverridesDir(), "50-me.yaml")); err != nil {
+		t.Error("personal overlay was removed")
+	}
+
+	bad := TeamDefaults{Assignments: []TeamAssignment{{Team: "rkinnovate/x", Profiles: []string{"missing"}}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
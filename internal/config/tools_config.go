@@ -9,10 +9,15 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"runtime"
+	"strings"
 	"time"
 
+	"github.com/rkinnovate/dev-setup/internal/version"
 	"gopkg.in/yaml.v3"
 )
 
@@ -22,6 +27,117 @@ import (
 type ToolsConfig struct {
 	// Tools are the list of tools to install
 	Tools []Tool `yaml:"tools"`
+
+	// Groups declare parallel_groups that may run alongside other stages, and what they wait for (see groups.go)
+	Groups []ParallelGroup `yaml:"groups"`
+
+	// Concurrency caps how many installs of each class run at once (defaults: DefaultClassLimits)
+	Concurrency map[string]int `yaml:"concurrency"`
+
+	// CheckCache is how long check command results are reused (nil = DefaultCheckCacheTTL, 0 = disabled)
+	CheckCache *time.Duration `yaml:"check_cache_ttl"`
+
+	// MacOS is the supported macOS range and known-incompatible tools (nil = no gating)
+	MacOS *MacOSRequirements `yaml:"macos"`
+
+	// PostStage defers the last install stages to a quiet time of day (nil = every stage runs at once)
+	PostStage *PostStage `yaml:"post_stage"`
+
+	// HomebrewCache lists office-local Homebrew artifact caches (nil = always upstream)
+	HomebrewCache *HomebrewCacheConfig `yaml:"homebrew_cache"`
+
+	// Shell is the default shell for install commands: sh (default), bash, or zsh
+	Shell string `yaml:"shell"`
+
+	// Owner is who to ask about tools that don't name their own owner (nil = the generic support hint)
+	Owner *Owner `yaml:"owner"`
+
+	// HeldBack lists tools left out because this machine isn't in their rollout
+	HeldBack []string `yaml:"-"`
+
+	// Path is the file this config was loaded from (for error locations)
+	Path string `yaml:"-"`
+
+	// Overrides are the configs.d overlay files applied at load time
+	Overrides []string `yaml:"-"`
+}
+
+// HomebrewCacheConfig describes office-local Homebrew artifact caches
+// What: Candidate HOMEBREW_ARTIFACT_DOMAIN values probed before installing
+// Why: Bottles come from a LAN cache when one is reachable, which slashes install time for office onboarding
+type HomebrewCacheConfig struct {
+	// ArtifactDomains are cache URLs probed in order; the first reachable one is used (".local" hosts resolve via mDNS)
+	ArtifactDomains []string `yaml:"artifact_domains"`
+
+	// Discover browses Bonjour for caches advertising _homebrew-cache._tcp when no listed domain is reachable;
+	// only caches on an allowed host (see AllowedHosts) are used
+	Discover bool `yaml:"discover"`
+
+	// DiscoverHosts are extra hostnames a Bonjour-discovered cache may resolve to
+	DiscoverHosts []string `yaml:"discover_hosts"`
+
+	// ProbeTimeout bounds each reachability probe (default 2s)
+	ProbeTimeout time.Duration `yaml:"probe_timeout"`
+}
+
+// AllowedHosts returns the hostnames a Bonjour-discovered cache may use
+// What: The hosts of artifact_domains plus discover_hosts, lowercased and without a trailing dot
+// Why: Bonjour answers are unauthenticated, so discovery only picks among caches the config already trusts
+func (c *HomebrewCacheConfig) AllowedHosts() []string {
+	var hosts []string
+	for _, domain := range c.ArtifactDomains {
+		if u, err := url.Parse(domain); err == nil && u.Hostname() != "" {
+			hosts = append(hosts, strings.TrimSuffix(strings.ToLower(u.Hostname()), "."))
+		}
+	}
+	for _, host := range c.DiscoverHosts {
+		hosts = append(hosts, strings.TrimSuffix(strings.ToLower(host), "."))
+	}
+	return hosts
+}
+
+// Scheduler classes describe which resource an install mostly consumes
+const (
+	ClassDownload    = "download"
+	ClassCPU         = "cpu"
+	ClassDisk        = "disk"
+	ClassInteractive = "interactive"
+)
+
+// DefaultCheckCacheTTL is how long check results are reused when tools.yaml sets no check_cache_ttl
+const DefaultCheckCacheTTL = 5 * time.Minute
+
+// CheckCacheTTL returns the effective check result cache TTL
+// Returns: check_cache_ttl from tools.yaml, DefaultCheckCacheTTL when unset, 0 when caching is disabled
+func (tc *ToolsConfig) CheckCacheTTL() time.Duration {
+	if tc.CheckCache == nil {
+		return DefaultCheckCacheTTL
+	}
+	return *tc.CheckCache
+}
+
+// DefaultClassLimits returns the per-class concurrency used when tools.yaml sets none
+// What: Downloads share the network, compiles share CPUs, interactive installs run one at a time
+// Why: One giant compile shouldn't starve quick installs; downloads shouldn't all hit the network at once
+// Returns: Limit per class
+func DefaultClassLimits() map[string]int {
+	return map[string]int{
+		ClassDownload:    4,
+		ClassCPU:         max(runtime.NumCPU()/2, 1),
+		ClassDisk:        2,
+		ClassInteractive: 1,
+	}
+}
+
+// ClassLimits returns effective per-class limits
+// What: DefaultClassLimits overlaid with the concurrency block from tools.yaml
+// Returns: Limit per class
+func (tc *ToolsConfig) ClassLimits() map[string]int {
+	limits := DefaultClassLimits()
+	for class, limit := range tc.Concurrency {
+		limits[class] = limit
+	}
+	return limits
 }
 
 // Tool represents a single tool installation definition
@@ -37,28 +153,334 @@ type Tool struct {
 	// Check is shell command that returns 0 if tool is already installed
 	Check string `yaml:"check"`
 
+	// Creates is a file or directory the install produces; when it exists the install is skipped without running check
+	Creates string `yaml:"creates"`
+
+	// Unless is a shell command; when it exits 0 the install is skipped (like check, for tools without a binary)
+	Unless string `yaml:"unless"`
+
+	// Shell runs the install command: sh, bash, or zsh (default: tools.yaml shell)
+	Shell string `yaml:"shell"`
+
 	// Install contains installation details
 	Install ToolInstall `yaml:"install"`
 
+	// Direct installs the tool without Homebrew (`install --without-brew`) when Install needs it (nil = wait for brew)
+	Direct *ToolInstall `yaml:"direct"`
+
 	// DependsOn lists tools that must be installed first
 	DependsOn []string `yaml:"depends_on"`
 
 	// Required indicates if installation should fail if this tool fails
 	Required bool `yaml:"required"`
+
+	// Service declares a brew service the tool provides (nil = none)
+	Service *ToolService `yaml:"service"`
+
+	// VersionPolicy is how deep verify treats version changes: pinned (default), minimum, or any (self-updating apps)
+	VersionPolicy string `yaml:"version_policy"`
+
+	// VersionCommand prints the tool's version when `<name> --version` doesn't (e.g. "psql --version")
+	VersionCommand string `yaml:"version_command"`
+
+	// VersionPattern extracts the version from that output (regex; first capture group, else the whole match)
+	VersionPattern string `yaml:"version_pattern"`
+
+	// OnFailure is a cleanup command run when the install fails (nil = none)
+	OnFailure *OnFailure `yaml:"on_failure"`
+
+	// Uninstall removes the tool; empty infers it from the install (see UninstallCommand)
+	Uninstall string `yaml:"uninstall"`
+
+	// Path lists directories the tool needs on PATH, in priority order ({brew_prefix} and ~/ are expanded)
+	Path []string `yaml:"path"`
+
+	// Rosetta marks a tool that ships only Intel (x86_64) binaries and needs Rosetta 2 on Apple Silicon
+	Rosetta bool `yaml:"rosetta"`
+
+	// Apps are Mac apps (bundle names without .app) that must not run while the tool installs or upgrades;
+	// cask installs find their apps from brew when unset
+	Apps []string `yaml:"apps"`
+
+	// Quarantine is what install does with the tool's quarantined files: keep, clear, or approve
+	// ("" = the policies.yaml quarantine default)
+	Quarantine string `yaml:"quarantine"`
+
+	// Owner is the team maintaining this tool, named when it fails (default: tools.yaml owner)
+	Owner *Owner `yaml:"owner"`
+
+	// Deprecated retires the tool: install skips it where it isn't installed yet, verify warns where it is
+	Deprecated bool `yaml:"deprecated"`
+
+	// ReplacedBy names the tool to use instead of a deprecated one
+	ReplacedBy string `yaml:"replaced_by"`
+
+	// Rollout limits the tool to some machines (nil = every machine)
+	Rollout *Rollout `yaml:"rollout"`
+
+	// Line is where this tool is declared in its config file (0 if not loaded from YAML)
+	Line int `yaml:"-"`
+
+	// dependsOnLines are the lines of each depends_on entry, parallel to DependsOn
+	dependsOnLines []int
 }
 
+// UnmarshalYAML decodes a tool and records its source lines
+// Why: Dependency errors report file:line of the offending entry
+func (t *Tool) UnmarshalYAML(node *yaml.Node) error {
+	type plain Tool
+	if err := node.Decode((*plain)(t)); err != nil {
+		return err
+	}
+	t.Line, t.dependsOnLines = declarationLines(node)
+	return nil
+}
+
+// Install types
+const (
+	// InstallTypeCommand runs install.command in a shell (default)
+	InstallTypeCommand = "command"
+	// InstallTypeArchive downloads an archive and installs binaries from it
+	InstallTypeArchive = "archive_install"
+	// InstallTypeScript downloads a remote installer script, verifies it, then runs it
+	InstallTypeScript = "script"
+)
+
 // ToolInstall contains installation command details
-// What: How to install the tool (command, timeout, parallel group)
+// What: How to install the tool (command or archive, timeout, parallel group)
 // Why: Need flexibility for different installation methods and parallelism
 type ToolInstall struct {
+	// Type is "command" (default), "archive_install", "script", "dmg", "clt", or a package strategy (brew, cask, go, cargo, npm)
+	Type string `yaml:"type"`
+
 	// Command is the shell command to run
 	Command string `yaml:"command"`
 
+	// Archive describes the download for archive_install
+	Archive *ArchiveInstall `yaml:"archive"`
+
+	// Script describes the remote installer for script
+	Script *ScriptInstall `yaml:"script"`
+
+	// DMG describes the disk image for dmg (see direct.go)
+	DMG *DMGInstall `yaml:"dmg"`
+
+	// Package is what a brew, cask, go, cargo, or npm install installs (see strategy.go)
+	Package *PackageInstall `yaml:"package"`
+
 	// ParallelGroup identifies tools that can install concurrently
 	ParallelGroup string `yaml:"parallel_group"`
 
+	// Class is the scheduler class (download, cpu, disk, interactive); empty means unlimited
+	Class string `yaml:"class"`
+
 	// Timeout is maximum time allowed for installation
 	Timeout time.Duration `yaml:"timeout"`
+
+	// ExpectedDuration is how long the install normally takes; enables hung detection (0 = off)
+	ExpectedDuration time.Duration `yaml:"expected_duration"`
+
+	// OnHang is what happens to a hung install: warn (default), kill, or retry (kill and run once more)
+	OnHang string `yaml:"on_hang"`
+}
+
+// DefaultCleanupTimeout bounds an on_failure command when it sets no timeout
+const DefaultCleanupTimeout = 2 * time.Minute
+
+// OnFailure is a cleanup command for a tool or setup task that failed
+// What: Shell command run once the tool or task has failed for good (after any retry)
+// Why: Removes partial downloads and half-finished brew installs that doctor otherwise finds later
+type OnFailure struct {
+	// Command runs in the tool's or task's shell; DEVSETUP_FAILED_NAME and DEVSETUP_FAILED_ERROR describe the failure
+	Command string `yaml:"command"`
+
+	// Timeout bounds the command (default 2m)
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// CleanupTimeout returns the effective cleanup timeout
+// Returns: timeout, or DefaultCleanupTimeout when unset
+func (o *OnFailure) CleanupTimeout() time.Duration {
+	if o.Timeout > 0 {
+		return o.Timeout
+	}
+	return DefaultCleanupTimeout
+}
+
+// Env returns the variables describing the failure to the cleanup command
+// Params: name - failed tool or task, cause - its error
+// Returns: DEVSETUP_FAILED_NAME and DEVSETUP_FAILED_ERROR assignments
+func (o *OnFailure) Env(name string, cause error) []string {
+	return []string{"DEVSETUP_FAILED_NAME=" + name, "DEVSETUP_FAILED_ERROR=" + cause.Error()}
+}
+
+// validate checks the command is set and the timeout is not negative
+// Params: owner - "tool x" or "task x" for error messages
+func (o *OnFailure) validate(owner string) error {
+	if strings.TrimSpace(o.Command) == "" {
+		return fmt.Errorf("%s: on_failure.command is required", owner)
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("%s: on_failure.timeout must not be negative", owner)
+	}
+	return nil
+}
+
+// DefaultServiceStartTimeout is how long install waits for a service's port when start_timeout is unset
+const DefaultServiceStartTimeout = 30 * time.Second
+
+// ToolService describes a Homebrew service (brew services) provided by a tool
+// What: Service name, health-check port, and whether install starts it
+// Why: postgresql/redis are only useful once running; verify and status report whether they are
+type ToolService struct {
+	// Name is the brew formula to run as a service (default: the tool name)
+	Name string `yaml:"name"`
+
+	// Port is the TCP port the service listens on; install waits for it and verify checks it (0 = no port check)
+	Port int `yaml:"port"`
+
+	// Manual leaves the service stopped on install (start it with 'devsetup services start'); verify skips it
+	Manual bool `yaml:"manual"`
+
+	// StartTimeout is how long install waits for the port after starting (default 30s)
+	StartTimeout time.Duration `yaml:"start_timeout"`
+}
+
+// ServiceName returns the brew service name for a tool
+// Params: tool - name of the tool declaring the service
+// Returns: service.name, or the tool name when unset
+func (s *ToolService) ServiceName(tool string) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return tool
+}
+
+// Timeout returns the effective start timeout
+// Returns: start_timeout, or DefaultServiceStartTimeout when unset
+func (s *ToolService) Timeout() time.Duration {
+	if s.StartTimeout > 0 {
+		return s.StartTimeout
+	}
+	return DefaultServiceStartTimeout
+}
+
+// Version policies (version_policy)
+const (
+	// VersionPolicyPinned fails deep verify when the version differs from the recorded one
+	VersionPolicyPinned = "pinned"
+	// VersionPolicyMinimum fails only when the version is older than the recorded one
+	VersionPolicyMinimum = "minimum"
+	// VersionPolicyAny never checks the version (apps that update themselves, e.g. Chrome, Docker)
+	VersionPolicyAny = "any"
+)
+
+// Hung install actions (install.on_hang)
+const (
+	OnHangWarn  = "warn"
+	OnHangKill  = "kill"
+	OnHangRetry = "retry"
+)
+
+// ArchiveInstall describes a download → extract → install-binaries installation
+// What: Declarative replacement for fragile curl | tar | mv | chmod pipelines
+// Why: Checksums are enforced and downloads resume instead of restarting
+type ArchiveInstall struct {
+	// URL of the archive (.tar.gz, .tgz, .tar, or .zip); {arch} is replaced with the Mac's architecture (see Arch)
+	URL string `yaml:"url"`
+
+	// Checksum is the expected SHA256 ("sha256:<hex>" or bare hex); empty skips verification with a warning
+	Checksum string `yaml:"checksum"`
+
+	// Checksums are per-architecture checksums (arm64, amd64), used instead of Checksum
+	Checksums map[string]string `yaml:"checksums"`
+
+	// Arch names each architecture in the URL when the vendor doesn't say arm64/amd64 (e.g. amd64: x64)
+	Arch map[string]string `yaml:"arch"`
+
+	// StripComponents drops leading path elements from archive entries (like tar --strip-components)
+	StripComponents int `yaml:"strip_components"`
+
+	// Target is the directory binaries are installed into (default ~/.local/bin)
+	Target string `yaml:"target"`
+
+	// Binaries are paths inside the archive (after stripping) to install as executables
+	Binaries []string `yaml:"binaries"`
+
+	// Dir keeps the whole extracted tree there, with binaries linked into target instead of copied (runtimes
+	// like node whose binaries need their lib directory)
+	Dir string `yaml:"dir"`
+}
+
+// ScriptInstall describes a remote installer script
+// What: Script URL with an optional SHA256 pin, run only after download (never piped from curl)
+// Why: Remote install scripts used to run blindly; a pin makes any upstream change fail loudly
+type ScriptInstall struct {
+	// URL of the installer script
+	URL string `yaml:"url"`
+
+	// Checksum is the pinned SHA256 ("sha256:<hex>" or bare hex); empty means unpinned
+	Checksum string `yaml:"checksum"`
+
+	// Shell runs the script (default /bin/bash)
+	Shell string `yaml:"shell"`
+
+	// Args are passed to the script
+	Args []string `yaml:"args"`
+}
+
+// homebrewInstallerRepo identifies the official Homebrew installer script URL
+const homebrewInstallerRepo = "Homebrew/install"
+
+// IsHomebrew reports whether the script is the official Homebrew installer
+// Why: Installing Homebrew itself goes through the installer's bootstrap (non-interactive, retried, shellenv activated)
+func (s *ScriptInstall) IsHomebrew() bool {
+	return s != nil && strings.Contains(s.URL, homebrewInstallerRepo)
+}
+
+// IsScript reports whether the tool installs via a remote script
+func (ti ToolInstall) IsScript() bool {
+	return ti.Type == InstallTypeScript
+}
+
+// IsArchive reports whether the tool installs from an archive
+func (ti ToolInstall) IsArchive() bool {
+	return ti.Type == InstallTypeArchive
+}
+
+// BrewPackages extracts Homebrew packages from the install command
+// What: Parses `brew install [--cask] a b` segments (split on && and ;)
+// Why: Policy and reconciliation features need to know which brew packages a tool adds
+// Returns: Formula names and cask names
+// Example: "brew install --cask zed" → nil, ["zed"]
+func (ti ToolInstall) BrewPackages() (formulas, casks []string) {
+	segments := strings.FieldsFunc(ti.Command, func(r rune) bool { return r == '&' || r == ';' })
+	for _, segment := range segments {
+		fields := strings.Fields(segment)
+		if len(fields) < 3 || fields[0] != "brew" || fields[1] != "install" {
+			continue
+		}
+
+		cask := false
+		var names []string
+		for _, f := range fields[2:] {
+			switch {
+			case f == "--cask":
+				cask = true
+			case strings.HasPrefix(f, "-"):
+				// Other flags (e.g. --quiet) don't affect package names
+			default:
+				names = append(names, f)
+			}
+		}
+
+		if cask {
+			casks = append(casks, names...)
+		} else {
+			formulas = append(formulas, names...)
+		}
+	}
+	return formulas, casks
 }
 
 // LoadToolsConfig loads and parses tools.yaml
@@ -83,20 +505,80 @@ func LoadToolsConfig(path string) (*ToolsConfig, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse tools config: %w", err)
 	}
+	config.Path = path
 
-	// Validate
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid tools config: %w", err)
+	// Per-user overlays (~/.config/devsetup/configs.d)
+	overrides, err := LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.resolve(overrides, CurrentMachine); err != nil {
+		return nil, err
 	}
-
 	return &config, nil
 }
 
+// resolve turns a parsed tools.yaml into what install runs
+// What: Applies overlays, holds back canary tools, expands typed installs, then validates
+// Why: Shared by LoadToolsConfig and config render, so a render shows exactly what a load produces
+// Params: overrides - overlays in order, machine - identifies the machine for rollouts
+// Returns: Error naming the overlay or the invalid entry
+func (tc *ToolsConfig) resolve(overrides []ConfigOverride, machine func() Machine) error {
+	if err := tc.applyOverrides(overrides); err != nil {
+		return err
+	}
+	if err := tc.applyRollout(machine); err != nil {
+		return fmt.Errorf("invalid tools config: %w", err)
+	}
+	if err := tc.resolveStrategies(); err != nil {
+		return fmt.Errorf("invalid tools config: %w", err)
+	}
+	if err := tc.Validate(); err != nil {
+		return fmt.Errorf("invalid tools config: %w", err)
+	}
+	return nil
+}
+
 // Validate checks if the tools configuration is valid
 // What: Validates tool names are unique, dependencies exist, no cycles
 // Why: Catch configuration errors early before installation starts
 // Returns: Error describing validation failure, nil if valid
 func (tc *ToolsConfig) Validate() error {
+	for class, limit := range tc.Concurrency {
+		if !isSchedulerClass(class) {
+			return fmt.Errorf("unknown class in concurrency: %s", class)
+		}
+		if limit < 1 {
+			return fmt.Errorf("concurrency for class %s must be at least 1", class)
+		}
+	}
+	if tc.CheckCache != nil && *tc.CheckCache < 0 {
+		return fmt.Errorf("check_cache_ttl must not be negative")
+	}
+	if err := tc.MacOS.Validate(); err != nil {
+		return err
+	}
+	if err := tc.PostStage.Validate(); err != nil {
+		return err
+	}
+	if hc := tc.HomebrewCache; hc != nil {
+		for _, domain := range hc.ArtifactDomains {
+			if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+				return fmt.Errorf("homebrew_cache: artifact domain %q must be an http(s) URL", domain)
+			}
+		}
+		if hc.ProbeTimeout < 0 {
+			return fmt.Errorf("homebrew_cache: probe_timeout must not be negative")
+		}
+	}
+
+	if !isShell(tc.Shell) {
+		return fmt.Errorf("invalid shell: %s (expected sh, bash, or zsh)", tc.Shell)
+	}
+	if err := validateOwner("tools.yaml", tc.Owner); err != nil {
+		return err
+	}
+
 	names := make(map[string]bool)
 	for _, tool := range tc.Tools {
 		// Check unique names
@@ -105,6 +587,76 @@ func (tc *ToolsConfig) Validate() error {
 		}
 		names[tool.Name] = true
 
+		if !isShell(tool.Shell) {
+			return fmt.Errorf("invalid shell for tool %s: %s (expected sh, bash, or zsh)", tool.Name, tool.Shell)
+		}
+
+		switch tool.Install.OnHang {
+		case "", OnHangWarn, OnHangKill, OnHangRetry:
+		default:
+			return fmt.Errorf("invalid on_hang for tool %s: %s (expected warn, kill, or retry)", tool.Name, tool.Install.OnHang)
+		}
+		if tool.Install.ExpectedDuration < 0 {
+			return fmt.Errorf("tool %s: expected_duration must not be negative", tool.Name)
+		}
+		if tool.Install.OnHang != "" && tool.Install.ExpectedDuration == 0 {
+			return fmt.Errorf("tool %s: on_hang needs expected_duration", tool.Name)
+		}
+
+		if svc := tool.Service; svc != nil {
+			if svc.Port < 0 || svc.Port > 65535 {
+				return fmt.Errorf("tool %s: service port %d out of range", tool.Name, svc.Port)
+			}
+			if svc.StartTimeout < 0 {
+				return fmt.Errorf("tool %s: service start_timeout must not be negative", tool.Name)
+			}
+		}
+
+		switch tool.VersionPolicy {
+		case "", VersionPolicyPinned, VersionPolicyMinimum, VersionPolicyAny:
+		default:
+			return fmt.Errorf("invalid version_policy for tool %s: %s (expected pinned, minimum, or any)", tool.Name, tool.VersionPolicy)
+		}
+		if err := version.ValidatePattern(tool.VersionPattern); err != nil {
+			return fmt.Errorf("tool %s: invalid version_pattern: %w", tool.Name, err)
+		}
+
+		if tool.OnFailure != nil {
+			if err := tool.OnFailure.validate("tool " + tool.Name); err != nil {
+				return err
+			}
+		}
+
+		if err := validateQuarantine("tool "+tool.Name, tool.Quarantine); err != nil {
+			return err
+		}
+		if err := validateOwner("tool "+tool.Name, tool.Owner); err != nil {
+			return err
+		}
+
+		if tool.Install.Class != "" && !isSchedulerClass(tool.Install.Class) {
+			return fmt.Errorf("invalid class for tool %s: %s", tool.Name, tool.Install.Class)
+		}
+
+		switch tool.Install.Type {
+		case "", InstallTypeCommand, InstallTypeCLT:
+		case InstallTypeArchive, InstallTypeScript, InstallTypeDMG:
+			if err := tool.Install.validateSource(); err != nil {
+				return fmt.Errorf("tool %s: %w", tool.Name, err)
+			}
+		case InstallTypeBrew, InstallTypeCask, InstallTypeGo, InstallTypeCargo, InstallTypeNpm:
+			if err := tool.Install.validateStrategy(); err != nil {
+				return fmt.Errorf("tool %s: %w", tool.Name, err)
+			}
+		default:
+			return fmt.Errorf("invalid install type for tool %s: %s", tool.Name, tool.Install.Type)
+		}
+		if tool.Direct != nil {
+			if err := tool.validateDirect(); err != nil {
+				return err
+			}
+		}
+
 		// Validate dependencies exist
 		for _, dep := range tool.DependsOn {
 			if !names[dep] {
@@ -123,62 +675,63 @@ func (tc *ToolsConfig) Validate() error {
 		}
 	}
 
+	if err := tc.validateGroups(); err != nil {
+		return err
+	}
+	if err := tc.validateDeprecations(); err != nil {
+		return err
+	}
+
+	// Detect cycles at load time
+	if _, err := tc.GetInstallOrder(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// isSchedulerClass reports whether class is a known scheduler class
+func isSchedulerClass(class string) bool {
+	switch class {
+	case ClassDownload, ClassCPU, ClassDisk, ClassInteractive:
+		return true
+	}
+	return false
+}
+
 // GetInstallOrder returns tools in dependency order
-// What: Topologically sorts tools based on depends_on relationships
+// What: Topologically sorts tools based on depends_on relationships (group depends_on included)
 // Why: Must install dependencies before dependents
-// Returns: Ordered slice of tools, error if circular dependency detected
+// Returns: Ordered slice of tools, *CycleError with path and file:line if circular dependency detected
 func (tc *ToolsConfig) GetInstallOrder() ([]Tool, error) {
-	// Build dependency graph
-	graph := make(map[string][]string)
-	inDegree := make(map[string]int)
-
+	ids := make([]string, 0, len(tc.Tools))
+	deps := make(map[string][]string, len(tc.Tools))
+	nameToTool := make(map[string]Tool, len(tc.Tools))
 	for _, tool := range tc.Tools {
-		if _, exists := inDegree[tool.Name]; !exists {
-			inDegree[tool.Name] = 0
-		}
-		for _, dep := range tool.DependsOn {
-			graph[dep] = append(graph[dep], tool.Name)
-			inDegree[tool.Name]++
-		}
-	}
-
-	// Topological sort using Kahn's algorithm
-	var queue []string
-	for name, degree := range inDegree {
-		if degree == 0 {
-			queue = append(queue, name)
-		}
+		ids = append(ids, tool.Name)
+		deps[tool.Name] = tc.Dependencies(tool)
+		nameToTool[tool.Name] = tool
 	}
 
-	var ordered []string
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-		ordered = append(ordered, current)
-
-		for _, dependent := range graph[current] {
-			inDegree[dependent]--
-			if inDegree[dependent] == 0 {
-				queue = append(queue, dependent)
+	// Shared deterministic Kahn's sort (see resolver.go)
+	ordered, err := topologicalSort(ids, deps)
+	var cycle *CycleError
+	if errors.As(err, &cycle) {
+		cycle.annotate(func(from, to string) string {
+			tool := nameToTool[from]
+			for i, dep := range tool.DependsOn {
+				if dep == to {
+					return edgeLocation(tc.Path, tool.Line, tool.dependsOnLines, i)
+				}
 			}
-		}
+			return ""
+		})
 	}
-
-	// Check for cycles
-	if len(ordered) != len(tc.Tools) {
-		return nil, fmt.Errorf("circular dependency detected in tools")
-	}
-
-	// Convert names back to Tool objects in order
-	nameToTool := make(map[string]Tool)
-	for _, tool := range tc.Tools {
-		nameToTool[tool.Name] = tool
+	if err != nil {
+		return nil, err
 	}
 
-	var result []Tool
+	result := make([]Tool, 0, len(ordered))
 	for _, name := range ordered {
 		result = append(result, nameToTool[name])
 	}
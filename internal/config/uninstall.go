@@ -0,0 +1,95 @@
+// File: internal/config/uninstall.go
+// Purpose: Uninstall commands inferred from how a tool installs
+// Problem: Removing a tool (devsetup uninstall, generations rollback --uninstall) needed a hand-written uninstall
+//          command per tool, which nobody wrote, so rolled-back tools stayed installed
+// Role: Derives the uninstall command from the install strategy, archive target, or a brew-only install command;
+//       a tool's uninstall field overrides the inference
+// Usage: cmd := tool.UninstallCommand(); if cmd == "" { /* can't infer; ask for uninstall: */ }
+// Design choices: Only installs whose effect is fully known are inferred (package managers, archive binaries,
+//                 commands made only of brew installs); script installs and arbitrary commands return "" rather
+//                 than a guess that removes too little or too much
+// Assumptions: Archive binaries are still where install put them; go installs went to GOBIN or GOPATH/bin
+
+package config
+
+import (
+	"path"
+	"strings"
+)
+
+// UninstallCommand returns the shell command that removes the tool
+// What: The uninstall field if set, else inferred from install: brew/cask uninstall, go binary removal, cargo uninstall, npm uninstall -g, or rm of archive binaries
+// Why: Uninstall and rollback work for every typed tool without per-tool config
+// Returns: Shell command, "" when it can't be inferred (script installs, commands that aren't only brew installs)
+// Example: {type: npm, package: {name: "@biomejs/biome"}} → "npm uninstall -g @biomejs/biome"
+func (t Tool) UninstallCommand() string {
+	if t.Uninstall != "" {
+		return t.Uninstall
+	}
+
+	install := t.Install
+	pkg := install.Package
+	switch {
+	case install.IsStrategy() && pkg != nil:
+		switch install.Type {
+		case InstallTypeBrew:
+			return "brew uninstall " + quote(pkg.Name)
+		case InstallTypeCask:
+			return "brew uninstall --cask " + quote(pkg.Name)
+		case InstallTypeGo:
+			return `gobin="$(go env GOBIN)"; rm -f "${gobin:-$(go env GOPATH)/bin}/"` + quote(pkg.binary(install.Type))
+		case InstallTypeCargo:
+			return "cargo uninstall " + quote(pkg.Name)
+		case InstallTypeNpm:
+			return "npm uninstall -g " + quote(pkg.Name)
+		}
+	case install.IsArchive() && install.Archive != nil:
+		return archiveUninstall(install.Archive)
+	case install.Type == "" || install.Type == InstallTypeCommand:
+		return brewUninstall(install)
+	}
+	return ""
+}
+
+// archiveUninstall removes the binaries an archive install copied to its target
+func archiveUninstall(archive *ArchiveInstall) string {
+	target := archive.Target
+	if target == "" {
+		target = "~/.local/bin"
+	}
+	target = strings.TrimSuffix(target, "/")
+
+	files := make([]string, len(archive.Binaries))
+	for i, binary := range archive.Binaries {
+		name := path.Base(binary)
+		if rest, ok := strings.CutPrefix(target, "~/"); ok {
+			files[i] = `"$HOME"/` + quote(rest+"/"+name)
+		} else {
+			files[i] = quote(target + "/" + name)
+		}
+	}
+	return "rm -f " + strings.Join(files, " ")
+}
+
+// brewUninstall infers the uninstall of a command made only of `brew install` steps
+// Returns: brew uninstall command(s), "" if any step is something else
+func brewUninstall(install ToolInstall) string {
+	var steps []string
+	for _, step := range strings.FieldsFunc(install.Command, func(r rune) bool { return r == '&' || r == ';' || r == '\n' }) {
+		fields := strings.Fields(step)
+		if len(fields) == 0 || (len(fields) > 1 && fields[0] == "brew" && (fields[1] == "tap" || fields[1] == "update")) {
+			continue // taps and updates have nothing to undo
+		}
+		formulas, casks := ToolInstall{Command: step}.BrewPackages()
+		if len(formulas) == 0 && len(casks) == 0 {
+			return ""
+		}
+		if len(formulas) > 0 {
+			steps = append(steps, "brew uninstall "+strings.Join(quoteAll(formulas), " "))
+		}
+		if len(casks) > 0 {
+			steps = append(steps, "brew uninstall --cask "+strings.Join(quoteAll(casks), " "))
+		}
+	}
+	return strings.Join(steps, " && ")
+}
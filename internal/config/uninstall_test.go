@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestUninstallCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		tool Tool
+		want string
+	}{
+		{"brew", Tool{Install: ToolInstall{Type: InstallTypeBrew, Package: &PackageInstall{Name: "git"}}}, "brew uninstall git"},
+		{"cask", Tool{Install: ToolInstall{Type: InstallTypeCask, Package: &PackageInstall{Name: "zed"}}}, "brew uninstall --cask zed"},
+		{"go", Tool{Install: ToolInstall{Type: InstallTypeGo, Package: &PackageInstall{Name: "golang.org/x/tools/gopls/v2"}}}, `gobin="$(go env GOBIN)"; rm -f "${gobin:-$(go env GOPATH)/bin}/"gopls`},
+		{"cargo", Tool{Install: ToolInstall{Type: InstallTypeCargo, Package: &PackageInstall{Name: "ripgrep", Bin: "rg"}}}, "cargo uninstall ripgrep"},
+		{"npm", Tool{Install: ToolInstall{Type: InstallTypeNpm, Package: &PackageInstall{Name: "@biomejs/biome"}}}, "npm uninstall -g @biomejs/biome"},
+		{"archive default target", Tool{Install: ToolInstall{Type: InstallTypeArchive, Archive: &ArchiveInstall{Binaries: []string{"bin/tool", "helper"}}}}, `rm -f "$HOME"/.local/bin/tool "$HOME"/.local/bin/helper`},
+		{"archive absolute target", Tool{Install: ToolInstall{Type: InstallTypeArchive, Archive: &ArchiveInstall{Target: "/usr/local/bin/", Binaries: []string{"tool"}}}}, "rm -f /usr/local/bin/tool"},
+		{"brew command", Tool{Install: ToolInstall{Command: "brew tap hashicorp/tap && brew install hashicorp/tap/terraform && brew install --cask docker"}}, "brew uninstall hashicorp/tap/terraform && brew uninstall --cask docker"},
+		{"mixed command", Tool{Install: ToolInstall{Command: "brew install node && npm install -g pnpm"}}, ""},
+		{"script", Tool{Install: ToolInstall{Type: InstallTypeScript, Script: &ScriptInstall{URL: "https://example.com/install.sh"}}}, ""},
+		{"override", Tool{Uninstall: "rm -rf ~/.tool", Install: ToolInstall{Type: InstallTypeBrew, Package: &PackageInstall{Name: "tool"}}}, "rm -rf ~/.tool"},
+	}
+	for _, tt := range tests {
+		if got := tt.tool.UninstallCommand(); got != tt.want {
+			t.Errorf("%s: UninstallCommand() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// File: internal/config/update_prefs.go
+// Purpose: Update notification preferences (skipped versions, snooze) and the cached last update check
+// Problem: The new-version notice came back on every command with no way to say "not this one" or "not now",
+//          so users who had to stay on a version learned to ignore it
+// Role: Persists `devsetup update --skip` and `--snooze` in state.json and decides whether the update notice
+//       shown after commands should mention a version
+// Usage: SkipVersion(state, "v0.6.0"); SnoozeUpdates(state, time.Now().Add(d)); if UpdateNoticeDue(state, v, now) {...}
+// Design choices: A skip names one version, so the next release is announced again; a snooze covers every version
+//                 until it ends; the latest version is cached with the check time so GitHub is asked at most once
+//                 per updateCheckInterval
+// Assumptions: Versions are release tags ("v0.6.0"); a bare "0.6.0" means the same tag
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// updateCheckInterval is how long a cached update check is trusted
+const updateCheckInterval = 24 * time.Hour
+
+// UpdatePrefs are the user's update notification preferences
+type UpdatePrefs struct {
+	// Skipped are versions the update notice stays quiet about (devsetup update --skip)
+	Skipped []string `json:"skipped,omitempty"`
+
+	// SnoozedUntil silences the update notice until then (devsetup update --snooze)
+	SnoozedUntil time.Time `json:"snoozed_until"`
+
+	// LastCheck is when GitHub was last asked for the latest release
+	LastCheck time.Time `json:"last_check"`
+
+	// Latest is the newer version the last check found ("" if none)
+	Latest string `json:"latest,omitempty"`
+}
+
+// SkipVersion stops the update notice for one version
+// Params: state - State to update, version - release tag ("v0.6.0" or "0.6.0")
+func SkipVersion(state *State, version string) {
+	prefs := updatePrefs(state)
+	version = releaseTag(version)
+	if !slices.Contains(prefs.Skipped, version) {
+		prefs.Skipped = append(prefs.Skipped, version)
+	}
+}
+
+// SnoozeUpdates silences the update notice until a time
+// Params: state - State to update, until - end of the snooze (zero clears it)
+func SnoozeUpdates(state *State, until time.Time) {
+	updatePrefs(state).SnoozedUntil = until
+}
+
+// RecordUpdateCheck caches the result of an update check
+// Params: state - State to update, latest - newer version found ("" if current), now - check time
+func RecordUpdateCheck(state *State, latest string, now time.Time) {
+	prefs := updatePrefs(state)
+	prefs.Latest, prefs.LastCheck = latest, now
+}
+
+// UpdateCheckDue reports whether the cached update check is too old to trust
+// Params: state - State to check, now - current time
+// Returns: True if GitHub was never asked or was asked more than updateCheckInterval ago
+func UpdateCheckDue(state *State, now time.Time) bool {
+	return state.Updates == nil || now.Sub(state.Updates.LastCheck) >= updateCheckInterval
+}
+
+// UpdateNoticeDue reports whether the update notice should mention a version
+// Params: state - State with preferences, version - newer release tag, now - current time
+// Returns: False when version is empty, skipped, or the notice is snoozed
+func UpdateNoticeDue(state *State, version string, now time.Time) bool {
+	if version == "" {
+		return false
+	}
+	prefs := state.Updates
+	if prefs == nil {
+		return true
+	}
+	return !slices.Contains(prefs.Skipped, releaseTag(version)) && !now.Before(prefs.SnoozedUntil)
+}
+
+// ParseSnooze parses a --snooze value
+// Params: s - days ("7d"), weeks ("2w"), a Go duration ("12h"), or "0" to end a snooze
+// Returns: Snooze length, error for negative or malformed values
+// Example: ParseSnooze("7d") → 168h
+func ParseSnooze(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid snooze %q (use 7d, 2w, 12h, or 0 to end the snooze)", s)
+}
+
+// updatePrefs returns state's update preferences, creating them on first use
+func updatePrefs(state *State) *UpdatePrefs {
+	if state.Updates == nil {
+		state.Updates = &UpdatePrefs{}
+	}
+	return state.Updates
+}
+
+// releaseTag normalizes "0.6.0" to "v0.6.0"
+func releaseTag(version string) string {
+	if version != "" && !strings.HasPrefix(version, "v") {
+		return "v" + version
+	}
+	return version
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateNoticeDue(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	state := &State{}
+
+	if !UpdateNoticeDue(state, "v0.6.0", now) || UpdateNoticeDue(state, "", now) {
+		t.Fatal("UpdateNoticeDue() without preferences should announce any version")
+	}
+	if !UpdateCheckDue(state, now) {
+		t.Error("UpdateCheckDue() before any check = false")
+	}
+
+	SkipVersion(state, "0.6.0")
+	SkipVersion(state, "v0.6.0")
+	if len(state.Updates.Skipped) != 1 {
+		t.Errorf("Skipped = %v, want one entry", state.Updates.Skipped)
+	}
+	if UpdateNoticeDue(state, "v0.6.0", now) {
+		t.Error("skipped version announced")
+	}
+	if !UpdateNoticeDue(state, "v0.6.1", now) {
+		t.Error("version after a skipped one not announced")
+	}
+
+	SnoozeUpdates(state, now.Add(7*24*time.Hour))
+	if UpdateNoticeDue(state, "v0.6.1", now.Add(6*24*time.Hour)) {
+		t.Error("announced during snooze")
+	}
+	if !UpdateNoticeDue(state, "v0.6.1", now.Add(7*24*time.Hour)) {
+		t.Error("not announced after snooze ended")
+	}
+
+	RecordUpdateCheck(state, "v0.6.1", now)
+	if UpdateCheckDue(state, now.Add(time.Hour)) || !UpdateCheckDue(state, now.Add(25*time.Hour)) {
+		t.Error("UpdateCheckDue() doesn't follow updateCheckInterval")
+	}
+}
+
+func TestParseSnooze(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"7d", 7 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"12h", 12 * time.Hour, true},
+		{"0", 0, true},
+		{"-1d", 0, false},
+		{"soon", 0, false},
+		{"d", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseSnooze(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseSnooze(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// File: internal/configsync/configsync.go
+// Purpose: Syncs personal devsetup settings between a user's machines (devsetup sync)
+// Problem: Developers with two Macs kept their configs.d overlays, exceptions, and prompt answers in step by hand,
+//          and the second machine silently drifted from the first
+// Role: Mirrors an allowlist of files from ~/.config/devsetup into a private gist or git repo the user configures,
+//       and back onto another machine
+// Usage: remote, err := configsync.LoadRemote(); res, err := configsync.Push(ctx, remote); res, err := configsync.Pull(ctx, remote)
+// Design choices: Gists are git repos, so one git-based implementation covers both; only an allowlist is synced
+//                 (personal overlays, exceptions.yaml, answers.yaml) and never state.json, team overlays, or
+//                 sync.yaml itself; every file is scanned for secrets (registered values and token patterns) and
+//                 a push containing one is refused rather than masked
+// Assumptions: git is installed and can authenticate to the remote (gh auth setup-git for gists); the user keeps
+//              the gist/repo private
+
+package configsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/redact"
+	"gopkg.in/yaml.v3"
+)
+
+// gistIDPattern matches a bare gist ID (hex), accepted in place of a clone URL
+var gistIDPattern = regexp.MustCompile(`^[0-9a-f]{20,32}$`)
+
+// Remote is the user's sync target (~/.config/devsetup/sync.yaml)
+type Remote struct {
+	// URL is the git clone URL of the private gist or repo
+	URL string `yaml:"url"`
+}
+
+// Result reports what a push or pull changed
+type Result struct {
+	// Written are the synced files created or updated (relative to the config dir)
+	Written []string
+
+	// Removed are the synced files deleted
+	Removed []string
+}
+
+// Changed reports whether anything was written or removed
+func (r *Result) Changed() bool {
+	return len(r.Written)+len(r.Removed) > 0
+}
+
+// RemotePath returns the sync settings location
+// Returns: ~/.config/devsetup/sync.yaml
+func RemotePath() string {
+	return filepath.Join(config.GetUserConfigDir(), "sync.yaml")
+}
+
+// checkoutDir is the local clone of the remote
+func checkoutDir() string {
+	return filepath.Join(config.GetStateDir(), "sync")
+}
+
+// ResolveURL turns a gist ID, gist URL, or repo URL into a git clone URL
+// Params: target - bare gist ID, https://gist.github.com/<user>/<id>, or any git URL
+// Returns: Clone URL
+// Example: ResolveURL("aa5a315d61ae9438b18d") → "https://gist.github.com/aa5a315d61ae9438b18d.git"
+func ResolveURL(target string) string {
+	target = strings.TrimSpace(target)
+	if gistIDPattern.MatchString(target) {
+		return "https://gist.github.com/" + target + ".git"
+	}
+	if rest, ok := strings.CutPrefix(target, "https://gist.github.com/"); ok && !strings.HasSuffix(rest, ".git") {
+		return "https://gist.github.com/" + filepath.Base(rest) + ".git"
+	}
+	return target
+}
+
+// LoadRemote reads the configured sync target
+// Returns: Remote, error if sync isn't configured ('devsetup sync init') or the file is malformed
+func LoadRemote() (*Remote, error) {
+	data, err := os.ReadFile(RemotePath())
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("sync is not configured (run 'devsetup sync init <gist-id|repo-url>')")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync settings: %w", err)
+	}
+	r := &Remote{}
+	if err := yaml.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", RemotePath(), err)
+	}
+	if r.URL == "" {
+		return nil, fmt.Errorf("%s has no url", RemotePath())
+	}
+	return r, nil
+}
+
+// SaveRemote stores the sync target and drops any clone of a previous target
+// Params: r - sync target
+// Returns: Error if the settings can't be written
+func SaveRemote(r *Remote) error {
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to serialize sync settings: %w", err)
+	}
+	if err := os.MkdirAll(config.GetUserConfigDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(RemotePath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write sync settings: %w", err)
+	}
+	return os.RemoveAll(checkoutDir())
+}
+
+// SyncedFiles lists the files sync carries, relative to dir
+// What: exceptions.yaml, answers.yaml, and personal configs.d overlays that exist in dir
+// Why: An allowlist keeps machine state, team-managed overlays, and anything unexpected out of the remote
+// Params: dir - ~/.config/devsetup or a checkout with the same layout
+// Returns: Sorted relative paths
+// Edge cases: Team profile overlays (00-team-*) are skipped; each machine regenerates them from defaults.yaml
+func SyncedFiles(dir string) []string {
+	var files []string
+	for _, name := range []string{filepath.Base(config.AnswersPath()), filepath.Base(config.ExceptionsPath())} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			files = append(files, name)
+		}
+	}
+	overlays, _ := filepath.Glob(filepath.Join(dir, "configs.d", "*.yaml"))
+	for _, path := range overlays {
+		if !strings.HasPrefix(filepath.Base(path), config.TeamOverlayPrefix) {
+			files = append(files, filepath.Join("configs.d", filepath.Base(path)))
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+// FindSecrets returns the synced files that contain a secret
+// Params: dir - directory the files are relative to, files - SyncedFiles output
+// Returns: Files containing a registered secret value or a known token format
+func FindSecrets(dir string, files []string) []string {
+	var found []string
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err == nil && redact.String(string(data)) != string(data) {
+			found = append(found, file)
+		}
+	}
+	return found
+}
+
+// Push uploads this machine's settings
+// What: Updates the clone, copies the synced files into it, commits, and pushes
+// Why: The other machine pulls this machine's overlays, exceptions, and answers
+// Params: ctx - bounds git, remote - sync target
+// Returns: Files changed on the remote, error if a file contains a secret or git fails
+// Edge cases: Local edits win; remote-only files are kept unless deleted here since the last sync; an earlier unpushed commit is pushed too
+func Push(ctx context.Context, remote *Remote) (*Result, error) {
+	local := config.GetUserConfigDir()
+	files := SyncedFiles(local)
+	if secrets := FindSecrets(local, files); len(secrets) > 0 {
+		return nil, fmt.Errorf("refusing to sync files containing secrets: %s", strings.Join(secrets, ", "))
+	}
+
+	base := SyncedFiles(checkoutDir())
+	dir, err := checkout(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	res, err := mirror(local, dir, base)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	if _, err := git(ctx, dir, "add", "-A"); err != nil {
+		return nil, err
+	}
+	if status, _ := git(ctx, dir, "status", "--porcelain"); status != "" {
+		// The clone is devsetup's own; don't depend on the user having a git identity yet
+		if email, _ := git(ctx, dir, "config", "user.email"); email == "" {
+			git(ctx, dir, "config", "user.name", "devsetup")
+			git(ctx, dir, "config", "user.email", "devsetup@"+host)
+		}
+		if _, err := git(ctx, dir, "commit", "-q", "-m", "devsetup sync from "+host); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := git(ctx, dir, "rev-parse", "-q", "--verify", "HEAD"); err != nil {
+		return res, nil // nothing synced yet
+	}
+	if _, err := git(ctx, dir, "push", "-q", "origin", "HEAD"); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Pull downloads settings pushed from another machine
+// What: Updates the clone and copies its synced files into ~/.config/devsetup
+// Why: A second machine picks up overlays, exceptions, and answers without copying files by hand
+// Params: ctx - bounds git, remote - sync target
+// Returns: Local files written or removed, error if git fails
+// Edge cases: Remote versions win; local-only files are kept; files are removed locally only if another machine deleted them since the last sync
+func Pull(ctx context.Context, remote *Remote) (*Result, error) {
+	base := SyncedFiles(checkoutDir())
+	dir, err := checkout(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return mirror(dir, config.GetUserConfigDir(), base)
+}
+
+// checkout clones the remote, or fast-forwards an existing clone
+func checkout(ctx context.Context, remote *Remote) (string, error) {
+	dir := checkoutDir()
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return "", fmt.Errorf("failed to create state directory: %w", err)
+		}
+		if _, err := git(ctx, "", "clone", "-q", remote.URL, dir); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+	// A new, empty remote has no branch to pull yet
+	if out, _ := git(ctx, dir, "ls-remote", "--heads", "origin"); out == "" {
+		return dir, nil
+	}
+	if _, err := git(ctx, dir, "pull", "-q", "--rebase"); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// mirror copies src's synced files to dst and removes those src deleted since the last sync
+// Params: src, dst - directories with the config dir layout, base - synced files at the last sync (the clone before updating)
+// Returns: Files written or removed in dst
+func mirror(src, dst string, base []string) (*Result, error) {
+	res := &Result{}
+	want := SyncedFiles(src)
+	for _, file := range want {
+		data, err := os.ReadFile(filepath.Join(src, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		target := filepath.Join(dst, file)
+		if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, data) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		res.Written = append(res.Written, file)
+	}
+	for _, file := range SyncedFiles(dst) {
+		if slices.Contains(base, file) && !slices.Contains(want, file) {
+			if err := os.Remove(filepath.Join(dst, file)); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", file, err)
+			}
+			res.Removed = append(res.Removed, file)
+		}
+	}
+	return res, nil
+}
+
+// git runs a git command, in dir unless dir is empty
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	sub := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w (%s)", sub, err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
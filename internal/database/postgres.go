@@ -0,0 +1,130 @@
+// File: internal/database/postgres.go
+// Purpose: Idempotent Postgres bootstrap for local development (roles, databases, seed scripts)
+// Problem: After devsetup started postgresql, every developer still ran createuser/createdb and a seed script by
+//          hand from a wiki page, and re-running the script on a seeded database failed or duplicated rows
+// Role: Creates a role and database only if missing and runs a seed file only when a check query finds no data;
+//       used by setup tasks with `postgres:` and the postgres_query verify check
+// Usage: created, err := database.EnsureRole(ctx, admin, "app", "secret"); seeded, err := database.Seeded(ctx, conn, "app_dev", q)
+// Design choices: Shells out to psql (installed with postgresql) rather than linking a driver; identifiers and
+//                 literals are quoted here so config values can't inject SQL; passwords travel via PGPASSWORD
+// Assumptions: psql is on PATH; connection defaults (socket, PGHOST/PGPORT) are psql's; the admin connection is the
+//              OS user, which Homebrew's postgresql makes a superuser
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// AdminDatabase is the maintenance database admin queries connect to
+const AdminDatabase = "postgres"
+
+// Conn identifies who psql connects as
+type Conn struct {
+	// User is the role to connect as ("" = OS user)
+	User string
+
+	// Password for User ("" = none, e.g. trust auth)
+	Password string
+}
+
+// Query runs a SQL statement and returns its unaligned, tuples-only output
+// Params: ctx - context, conn - connection role, db - database name, sql - statement
+// Returns: Trimmed output, error with psql's message if the statement fails
+func Query(ctx context.Context, conn Conn, db, sql string) (string, error) {
+	return conn.psql(ctx, db, "-c", sql)
+}
+
+// RunFile executes a SQL file, stopping at the first error
+// Params: ctx - context, conn - connection role, db - database name, path - SQL file
+// Returns: Error with psql's message if any statement fails
+func RunFile(ctx context.Context, conn Conn, db, path string) error {
+	_, err := conn.psql(ctx, db, "-f", path)
+	return err
+}
+
+// EnsureRole creates a login role unless it exists
+// Params: ctx - context, admin - superuser connection, user - role name, password - login password ("" = none)
+// Returns: True if the role was created, error if the lookup or creation fails
+// Edge cases: An existing role's password is left alone
+func EnsureRole(ctx context.Context, admin Conn, user, password string) (bool, error) {
+	exists, err := Query(ctx, admin, AdminDatabase, "SELECT 1 FROM pg_roles WHERE rolname = "+QuoteLiteral(user))
+	if err != nil || exists != "" {
+		return false, err
+	}
+	stmt := "CREATE ROLE " + QuoteIdent(user) + " LOGIN"
+	if password != "" {
+		stmt += " PASSWORD " + QuoteLiteral(password)
+	}
+	_, err = Query(ctx, admin, AdminDatabase, stmt)
+	return err == nil, err
+}
+
+// EnsureDatabase creates a database unless it exists
+// Params: ctx - context, admin - superuser connection, name - database name, owner - owning role ("" = admin)
+// Returns: True if the database was created, error if the lookup or creation fails
+func EnsureDatabase(ctx context.Context, admin Conn, name, owner string) (bool, error) {
+	exists, err := Query(ctx, admin, AdminDatabase, "SELECT 1 FROM pg_database WHERE datname = "+QuoteLiteral(name))
+	if err != nil || exists != "" {
+		return false, err
+	}
+	stmt := "CREATE DATABASE " + QuoteIdent(name)
+	if owner != "" {
+		stmt += " OWNER " + QuoteIdent(owner)
+	}
+	_, err = Query(ctx, admin, AdminDatabase, stmt)
+	return err == nil, err
+}
+
+// Seeded reports whether a check query finds data
+// Params: ctx - context, conn - connection role, db - database, check - query such as "SELECT 1 FROM users LIMIT 1"
+// Returns: True if the query returned a row, error if it fails for another reason than a missing table
+// Edge cases: A missing relation (seed creates the schema) counts as not seeded
+func Seeded(ctx context.Context, conn Conn, db, check string) (bool, error) {
+	out, err := Query(ctx, conn, db, check)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return false, nil
+		}
+		return false, err
+	}
+	return out != "", nil
+}
+
+// QuoteIdent quotes a SQL identifier, doubling embedded double quotes
+func QuoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// QuoteLiteral quotes a SQL string literal, doubling embedded single quotes
+func QuoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// psql runs psql quietly with ON_ERROR_STOP, returning trimmed stdout
+func (c Conn) psql(ctx context.Context, db string, args ...string) (string, error) {
+	base := []string{"-X", "-q", "-t", "-A", "-v", "ON_ERROR_STOP=1", "-d", db}
+	if c.User != "" {
+		base = append(base, "-U", c.User)
+	}
+	cmd := exec.CommandContext(ctx, "psql", append(base, args...)...)
+	cmd.Env = os.Environ()
+	if c.Password != "" {
+		cmd.Env = append(cmd.Env, "PGPASSWORD="+c.Password)
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("psql %s: %s", db, msg)
+		}
+		return "", fmt.Errorf("psql %s: %w", db, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
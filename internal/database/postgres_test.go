@@ -0,0 +1,12 @@
+package database
+
+import "testing"
+
+func TestQuoting(t *testing.T) {
+	if got := QuoteIdent(`app"; DROP TABLE x; --`); got != `"app""; DROP TABLE x; --"` {
+		t.Errorf("QuoteIdent = %s", got)
+	}
+	if got := QuoteLiteral("it's"); got != "'it''s'" {
+		t.Errorf("QuoteLiteral = %s", got)
+	}
+}
@@ -0,0 +1,147 @@
+// File: internal/deferred/deferred.go
+// Purpose: LaunchAgent that runs the deferred install stages at the tools.yaml post_stage time
+// Problem: post_stage moves polish installs to the evening, but nothing would run them unless the developer
+//          remembered to type `devsetup install` again at 18:00
+// Role: install writes and loads the agent after running the early stages; the agent runs
+//       `devsetup install --deferred`, which removes it once the deferred stages succeed
+// Usage: err := deferred.Schedule(ctx, deferred.Job{Executable: exe, Dir: cwd, At: until});
+//        deferred.Cancel(ctx) after the deferred stages installed; deferred.Pending() for hints
+// Design choices: A daily StartCalendarInterval rather than a one-shot date, so a failed or slept-through run is
+//                 retried the next evening (launchd also runs a missed interval on wake); the agent keeps the
+//                 working directory and PATH of the install that scheduled it, since configs/ is read from the
+//                 working directory and launchd's default PATH has no Homebrew
+// Assumptions: macOS with launchctl; one deferred install per user at a time
+
+package deferred
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// Label is the LaunchAgent label
+const Label = "com.rkinnovate.devsetup.deferred"
+
+// Job is a scheduled deferred install
+type Job struct {
+	// Executable is the devsetup binary to run
+	Executable string
+
+	// Dir is the working directory (the one holding configs/)
+	Dir string
+
+	// At is the time of day the agent fires (date is ignored)
+	At time.Time
+
+	// Path is the PATH the install runs with
+	Path string
+}
+
+// PlistPath returns ~/Library/LaunchAgents/<Label>.plist
+func PlistPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "LaunchAgents", Label+".plist")
+}
+
+// LogPath returns where the agent's output goes (~/.local/share/devsetup/deferred.log)
+func LogPath() string {
+	return filepath.Join(config.GetStateDir(), "deferred.log")
+}
+
+// Args returns the command line the agent runs
+// What: install --deferred, waiting for an install that is still running and answering confirmations with yes
+func (j Job) Args() []string {
+	return []string{j.Executable, "install", "--deferred", "--wait", "--yes"}
+}
+
+// Plist returns the LaunchAgent property list for a job
+func Plist(j Job) string {
+	var b strings.Builder
+	str := func(s string) string {
+		var esc strings.Builder
+		_ = xml.EscapeText(&esc, []byte(s))
+		return "<string>" + esc.String() + "</string>"
+	}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t%s\n", str(Label))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range j.Args() {
+		fmt.Fprintf(&b, "\t\t%s\n", str(arg))
+	}
+	b.WriteString("\t</array>\n")
+	fmt.Fprintf(&b, "\t<key>WorkingDirectory</key>\n\t%s\n", str(j.Dir))
+	fmt.Fprintf(&b, "\t<key>EnvironmentVariables</key>\n\t<dict>\n\t\t<key>PATH</key>\n\t\t%s\n\t</dict>\n", str(j.Path))
+	fmt.Fprintf(&b, "\t<key>StartCalendarInterval</key>\n\t<dict>\n\t\t<key>Hour</key>\n\t\t<integer>%d</integer>\n\t\t<key>Minute</key>\n\t\t<integer>%d</integer>\n\t</dict>\n", j.At.Hour(), j.At.Minute())
+	fmt.Fprintf(&b, "\t<key>StandardOutPath</key>\n\t%s\n\t<key>StandardErrorPath</key>\n\t%s\n", str(LogPath()), str(LogPath()))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// Schedule writes the LaunchAgent and loads it, replacing a previous one
+// Params: ctx - context for launchctl, j - job to run
+// Returns: Error if the plist can't be written or launchctl refuses it
+func Schedule(ctx context.Context, j Job) error {
+	path := PlistPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(LogPath()), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(LogPath()), err)
+	}
+	if err := os.WriteFile(path, []byte(Plist(j)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	_ = launchctl(ctx, "bootout", domain(), path)
+	if err := launchctl(ctx, "bootstrap", domain(), path); err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// Cancel unloads and removes the LaunchAgent
+// Returns: Error if the plist can't be removed (a missing agent is not an error)
+func Cancel(ctx context.Context) error {
+	path := PlistPath()
+	if !Pending() {
+		return nil
+	}
+	_ = launchctl(ctx, "bootout", domain(), path)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// Pending reports whether a deferred install is scheduled
+func Pending() bool {
+	_, err := os.Stat(PlistPath())
+	return err == nil
+}
+
+// domain returns the launchctl domain of the current user's GUI session
+func domain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+// launchctl runs one launchctl command
+// Returns: Error with launchctl's output if it fails
+func launchctl(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
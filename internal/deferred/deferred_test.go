@@ -0,0 +1,149 @@
+package deferred
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlist(t *testing.T) {
+	t.Setenv("HOME", "/Users/dev")
+	tests := []struct {
+		name string
+		job  Job
+		want []string
+	}{
+		{
+			name: "evening",
+			job: Job{
+				Executable: "/Users/dev/.local/bin/devsetup",
+				Dir:        "/Users/dev/R&D/dev-setup",
+				At:         time.Date(2026, 10, 19, 18, 5, 0, 0, time.Local),
+				Path:       "/opt/homebrew/bin:/usr/bin:/bin",
+			},
+			want: []string{
+				"<string>" + Label + "</string>",
+				"<string>/Users/dev/.local/bin/devsetup</string>\n\t\t<string>install</string>\n\t\t<string>--deferred</string>\n\t\t<string>--wait</string>\n\t\t<string>--yes</string>\n\t</array>",
+				"<key>WorkingDirectory</key>\n\t<string>/Users/dev/R&amp;D/dev-setup</string>",
+				"<key>Hour</key>\n\t\t<integer>18</integer>\n\t\t<key>Minute</key>\n\t\t<integer>5</integer>",
+				"<key>PATH</key>\n\t\t<string>/opt/homebrew/bin:/usr/bin:/bin</string>",
+				"<key>StandardOutPath</key>\n\t<string>/Users/dev/.local/share/devsetup/deferred.log</string>",
+			},
+		},
+		{
+			name: "midnight, markup in paths",
+			job: Job{
+				Executable: "/opt/<bin>/devsetup",
+				Dir:        `/tmp/"quoted"`,
+				At:         time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			},
+			want: []string{
+				"<string>/opt/&lt;bin&gt;/devsetup</string>",
+				"<string>/tmp/&#34;quoted&#34;</string>",
+				"<key>Hour</key>\n\t\t<integer>0</integer>\n\t\t<key>Minute</key>\n\t\t<integer>0</integer>",
+				"<key>PATH</key>\n\t\t<string></string>",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plist := Plist(tt.job)
+			for _, want := range tt.want {
+				if !strings.Contains(plist, want) {
+					t.Errorf("plist missing %q:\n%s", want, plist)
+				}
+			}
+
+			// launchd rejects a plist that isn't well-formed XML
+			dec := xml.NewDecoder(strings.NewReader(plist))
+			dec.Strict = true
+			for {
+				if _, err := dec.Token(); err != nil {
+					if !errors.Is(err, io.EOF) {
+						t.Errorf("plist is not well-formed: %v\n%s", err, plist)
+					}
+					break
+				}
+			}
+		})
+	}
+}
+
+// stubLaunchctl puts a launchctl on PATH that logs its arguments and fails bootstrap when failBootstrap is set
+func stubLaunchctl(t *testing.T, failBootstrap bool) string {
+	t.Helper()
+	bin := t.TempDir()
+	log := filepath.Join(t.TempDir(), "launchctl")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$1 $2\" >> '%s'\n", log)
+	if failBootstrap {
+		script += "[ \"$1\" = bootstrap ] && { echo 'Bootstrap failed: 5: Input/output error'; exit 5; }\n"
+	}
+	if err := os.WriteFile(filepath.Join(bin, "launchctl"), []byte(script+"exit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestScheduleAndCancel(t *testing.T) {
+	domain := fmt.Sprintf("gui/%d", os.Getuid())
+	job := Job{Executable: "/usr/local/bin/devsetup", Dir: "/src", At: time.Date(2026, 10, 19, 18, 0, 0, 0, time.Local)}
+	tests := []struct {
+		name          string
+		failBootstrap bool
+		scheduled     bool
+		wantErr       string
+		wantCalls     string
+	}{
+		{name: "scheduled", scheduled: true, wantCalls: "bootout " + domain + "\nbootstrap " + domain + "\n"},
+		{name: "bootstrap refused", failBootstrap: true, wantErr: "Input/output error", wantCalls: "bootout " + domain + "\nbootstrap " + domain + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			log := stubLaunchctl(t, tt.failBootstrap)
+
+			err := Schedule(context.Background(), job)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Schedule() = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Schedule() = %v, want %q", err, tt.wantErr)
+			}
+			if calls, _ := os.ReadFile(log); string(calls) != tt.wantCalls {
+				t.Errorf("launchctl calls = %q, want %q", calls, tt.wantCalls)
+			}
+			if Pending() != tt.scheduled {
+				t.Fatalf("Pending() = %v after Schedule, want %v", Pending(), tt.scheduled)
+			}
+			if tt.scheduled {
+				if data, _ := os.ReadFile(PlistPath()); string(data) != Plist(job) {
+					t.Errorf("written plist differs from Plist(job):\n%s", data)
+				}
+			}
+
+			// Cancel unloads a scheduled agent and leaves launchctl alone otherwise
+			_ = os.Remove(log)
+			if err := Cancel(context.Background()); err != nil {
+				t.Fatalf("Cancel() = %v", err)
+			}
+			if Pending() {
+				t.Error("Pending() after Cancel")
+			}
+			wantCancel := ""
+			if tt.scheduled {
+				wantCancel = "bootout " + domain + "\n"
+			}
+			if calls, _ := os.ReadFile(log); string(calls) != wantCancel {
+				t.Errorf("Cancel() launchctl calls = %q, want %q", calls, wantCancel)
+			}
+		})
+	}
+}
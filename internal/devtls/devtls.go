@@ -0,0 +1,169 @@
+// File: internal/devtls/devtls.go
+// Purpose: Local HTTPS for development domains (mkcert CA, certificates, /etc/hosts entries)
+// Problem: Every web team re-invented "trust a local CA, issue a cert for app.test, point it at localhost", and
+//          expired or mismatched certificates surfaced later as browser errors nobody connected to setup
+// Role: Locates mkcert's CA, checks a certificate's validity, domains, and signer, issues certificates with mkcert,
+//       and maintains a managed block of development domains in /etc/hosts
+// Usage: if err := devtls.Check(cert, domains, renew, time.Now()); err != nil { devtls.Issue(ctx, cert, key, domains) }
+// Design choices: mkcert does the CA and trust-store work (system keychain, Firefox NSS) so devsetup doesn't touch
+//                 keychains itself; certificates are checked with crypto/x509 so verify needs no mkcert; the hosts
+//                 block is replaced as a whole between markers, leaving the rest of /etc/hosts alone
+// Assumptions: mkcert is installed (tools.yaml); writing /etc/hosts uses sudo
+
+package devtls
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+)
+
+// HostsFile is the system hosts file
+const HostsFile = "/etc/hosts"
+
+// hostsBlock names the managed /etc/hosts block (see dotfiles.ReplaceBlock)
+const hostsBlock = "dev_tls"
+
+// CARoot returns mkcert's CA directory
+// Returns: $CAROOT if set, else ~/Library/Application Support/mkcert (mkcert's macOS default)
+func CARoot() string {
+	if root := os.Getenv("CAROOT"); root != "" {
+		return root
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "Application Support", "mkcert")
+}
+
+// Load reads the first certificate of a PEM file
+// Returns: Certificate, error if the file is missing or holds no certificate
+func Load(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s holds no PEM certificate", path)
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// Check reports why a certificate isn't fit for use, nil if it is
+// What: Validity period (with minValid to spare), coverage of every domain, and a chain to mkcert's CA when that
+// CA exists on this machine
+// Params: path - PEM certificate, domains - names it must cover, minValid - required remaining validity, now - current time
+// Returns: Error describing the first problem found
+// Example: Check("~/certs/dev.pem", []string{"app.test"}, 720*time.Hour, time.Now()) → "expires on 2025-03-01 ..."
+func Check(path string, domains []string, minValid time.Duration, now time.Time) error {
+	cert, err := Load(path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case now.After(cert.NotAfter):
+		return fmt.Errorf("expired on %s", cert.NotAfter.Format("2006-01-02"))
+	case now.Before(cert.NotBefore):
+		return fmt.Errorf("not valid until %s", cert.NotBefore.Format("2006-01-02"))
+	case cert.NotAfter.Sub(now) < minValid:
+		return fmt.Errorf("expires on %s (renewal is due %s before expiry)", cert.NotAfter.Format("2006-01-02"), minValid)
+	}
+	for _, domain := range domains {
+		if !covers(cert, domain) {
+			return fmt.Errorf("doesn't cover %s", domain)
+		}
+	}
+
+	root, err := Load(filepath.Join(CARoot(), "rootCA.pem"))
+	if err != nil {
+		return nil // No local CA to check against (e.g. a certificate from elsewhere)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(root)
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: pool, CurrentTime: now, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}); err != nil {
+		return fmt.Errorf("not signed by this machine's mkcert CA: %w", err)
+	}
+	return nil
+}
+
+// covers reports whether a certificate is valid for a domain
+// Edge cases: A wildcard domain must be in the certificate as is; other names may match a wildcard entry or an IP SAN
+func covers(cert *x509.Certificate, domain string) bool {
+	if strings.HasPrefix(domain, "*.") {
+		return slices.Contains(cert.DNSNames, domain)
+	}
+	return cert.VerifyHostname(domain) == nil
+}
+
+// Issue creates a certificate and key for domains with mkcert
+// Params: ctx - bounds mkcert, cert/key - output files (their directory is created), domains - names to cover
+// Returns: Error if mkcert fails; output goes to ~/.local/share/devsetup/logs/setup-mkcert.log
+func Issue(ctx context.Context, cert, key string, domains []string) error {
+	if err := os.MkdirAll(filepath.Dir(cert), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(cert), err)
+	}
+	out := capture.Open("setup-mkcert")
+	defer out.Close()
+
+	cmd := exec.CommandContext(ctx, "mkcert", append([]string{"-cert-file", cert, "-key-file", key}, domains...)...)
+	cmd.Env = os.Environ()
+	out.Attach(cmd, nil, nil)
+	return out.Wrap(cmd.Run())
+}
+
+// HostDomains returns the domains that can be listed in /etc/hosts (no wildcards, IP addresses, or localhost)
+func HostDomains(domains []string) []string {
+	var hosts []string
+	for _, domain := range domains {
+		if strings.Contains(domain, "*") || net.ParseIP(domain) != nil || domain == "localhost" || slices.Contains(hosts, domain) {
+			continue
+		}
+		hosts = append(hosts, domain)
+	}
+	return hosts
+}
+
+// HostsBlock returns hosts file content with the managed block pointing domains at 127.0.0.1
+// Params: content - current /etc/hosts, domains - names for the block (see HostDomains; empty removes the block)
+// Returns: New content; equal to content when the block is already up to date
+func HostsBlock(content string, domains []string) string {
+	var lines []string
+	if len(domains) > 0 {
+		lines = []string{"127.0.0.1\t" + strings.Join(domains, " ")}
+	}
+	return dotfiles.ReplaceBlock(content, hostsBlock, lines)
+}
+
+// WriteHosts replaces /etc/hosts with sudo, keeping the previous file as /etc/hosts.devsetup-backup
+// Params: ctx - bounds the sudo commands (sudo asks for a password on the terminal), content - new hosts file
+// Returns: Error if sudo or the copy fails; the resolver cache is flushed after a successful write
+func WriteHosts(ctx context.Context, content string) error {
+	if out, err := exec.CommandContext(ctx, "sudo", "cp", HostsFile, HostsFile+".devsetup-backup").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to back up %s: %w: %s", HostsFile, err, strings.TrimSpace(string(out)))
+	}
+	tee := exec.CommandContext(ctx, "sudo", "tee", HostsFile)
+	tee.Stdin = strings.NewReader(content)
+	tee.Stderr = os.Stderr
+	if err := tee.Run(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", HostsFile, err)
+	}
+	// Cached lookups would keep resolving the old entries until they expire
+	_ = exec.CommandContext(ctx, "sudo", "dscacheutil", "-flushcache").Run()
+	_ = exec.CommandContext(ctx, "sudo", "killall", "-HUP", "mDNSResponder").Run()
+	return nil
+}
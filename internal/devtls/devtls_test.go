@@ -0,0 +1,111 @@
+package devtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// issue writes a PEM certificate for names signed by parent (self-signed CA when parent is nil)
+func issue(t *testing.T, path string, names []string, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     names,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CAROOT", dir)
+	now := time.Now()
+	ca, caKey := issue(t, filepath.Join(dir, "rootCA.pem"), nil, now.Add(10*365*24*time.Hour), nil, nil)
+
+	cert := filepath.Join(dir, "dev.pem")
+	issue(t, cert, []string{"app.test", "*.app.test"}, now.Add(90*24*time.Hour), ca, caKey)
+
+	if err := Check(cert, []string{"app.test", "*.app.test", "api.app.test"}, 30*24*time.Hour, now); err != nil {
+		t.Errorf("valid certificate failed: %v", err)
+	}
+	for _, tt := range []struct {
+		domains  []string
+		minValid time.Duration
+		now      time.Time
+		want     string
+	}{
+		{[]string{"other.test"}, 0, now, "doesn't cover other.test"},
+		{[]string{"*.api.app.test"}, 0, now, "doesn't cover"},
+		{nil, 120 * 24 * time.Hour, now, "renewal is due"},
+		{nil, 0, now.Add(100 * 24 * time.Hour), "expired"},
+	} {
+		if err := Check(cert, tt.domains, tt.minValid, tt.now); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Check(%v, %s) = %v, want %q", tt.domains, tt.minValid, err, tt.want)
+		}
+	}
+
+	// A certificate from another CA (e.g. copied from another machine) isn't trusted here
+	other, otherKey := issue(t, filepath.Join(t.TempDir(), "otherCA.pem"), nil, now.Add(time.Hour*24*365), nil, nil)
+	issue(t, cert, []string{"app.test"}, now.Add(90*24*time.Hour), other, otherKey)
+	if err := Check(cert, []string{"app.test"}, 0, now); err == nil || !strings.Contains(err.Error(), "mkcert CA") {
+		t.Errorf("foreign certificate: %v", err)
+	}
+
+	if err := Check(filepath.Join(dir, "missing.pem"), nil, 0, now); !os.IsNotExist(err) {
+		t.Errorf("missing certificate should be a not-exist error, got %v", err)
+	}
+}
+
+func TestHostsBlock(t *testing.T) {
+	base := "127.0.0.1\tlocalhost\n255.255.255.255\tbroadcasthost\n"
+	domains := HostDomains([]string{"app.test", "*.app.test", "localhost", "127.0.0.1", "api.app.test", "app.test"})
+	if want := []string{"app.test", "api.app.test"}; !reflect.DeepEqual(domains, want) {
+		t.Fatalf("HostDomains = %v, want %v", domains, want)
+	}
+
+	added := HostsBlock(base, domains)
+	want := base + "\n# >>> devsetup dev_tls >>>\n127.0.0.1\tapp.test api.app.test\n# <<< devsetup dev_tls <<<\n"
+	if added != want {
+		t.Errorf("HostsBlock =\n%s\nwant\n%s", added, want)
+	}
+	if again := HostsBlock(added, domains); again != added {
+		t.Errorf("an up-to-date block should leave the file unchanged:\n%s", again)
+	}
+	if replaced := HostsBlock(added, []string{"web.test"}); !strings.Contains(replaced, "127.0.0.1\tweb.test\n") || strings.Contains(replaced, "api.app.test") {
+		t.Errorf("block not replaced:\n%s", replaced)
+	}
+	if removed := HostsBlock(added, nil); removed != base {
+		t.Errorf("empty domains should remove the block:\n%s", removed)
+	}
+}
@@ -0,0 +1,273 @@
+// File: internal/doctor/brew.go
+// Purpose: Homebrew deep health checks for doctor
+// Problem: Developers were told to run brew doctor themselves and decode its wall of prose, and problems it doesn't
+//          report (missing dependencies, shallow homebrew-core, broken taps) went unnoticed
+// Role: Runs brew doctor and brew missing, checks prefix permissions, homebrew-core depth, and every tap's git
+//       checkout, and turns each problem into a categorized finding with a fix where one is safe
+// Usage: Registered in Doctor.diagnostics when brew is on PATH
+// Design choices: brew doctor warnings are grouped by keyword into a few categories (links, unbrewed files,
+//                 developer tools, PATH, taps, deprecated) so similar problems read together; fixes are only
+//                 offered when nothing local can be lost (brew link/cleanup/install, git fetch, re-tapping a broken
+//                 checkout)
+// Assumptions: brew doctor keeps its "Warning: <title>" paragraph format; taps are git checkouts
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/policy"
+)
+
+// brewWarning is one "Warning:" paragraph from brew doctor
+type brewWarning struct {
+	// Title is the text on the Warning: line
+	Title string
+
+	// Items are indented entries listed under the warning (files, formulae)
+	Items []string
+}
+
+// brewCategories map brew doctor warning keywords to finding categories, first match wins
+var brewCategories = []struct {
+	name     string
+	keywords []string
+}{
+	{"links", []string{"symlink", "not linked", "unlinked", "keg"}},
+	{"unbrewed files", []string{"unbrewed", "unexpected"}},
+	{"developer tools", []string{"command line tools", "xcode", "clt"}},
+	{"PATH", []string{"path", "config scripts"}},
+	{"taps", []string{"tap"}},
+	{"deprecated", []string{"deprecated", "disabled"}},
+}
+
+// checkBrewHealth runs the Homebrew deep health checks
+// What: brew doctor warnings, missing dependencies, prefix permissions, shallow core taps, tap integrity
+// Why: One categorized report with fixes instead of pointing users at brew doctor
+// Params: ctx - context with timeout
+// Returns: Findings grouped by check
+func (d *Doctor) checkBrewHealth(ctx context.Context) []Finding {
+	prefix, err := brewOutput(ctx, "--prefix")
+	if err != nil {
+		return []Finding{{Name: "homebrew", Status: StatusError, Message: err.Error(), Guidance: "Reinstall Homebrew (devsetup install)"}}
+	}
+
+	var findings []Finding
+	findings = append(findings, brewDoctorFindings(ctx)...)
+	findings = append(findings, brewMissingFindings(ctx)...)
+
+	ownership := policyFinding(policy.BrewOwnership(ctx, prefix))
+	ownership.Name = "prefix permissions"
+	if ownership.Status != StatusOK {
+		ownership.Status = StatusError
+	}
+	findings = append(findings, ownership)
+
+	findings = append(findings, shallowTapFindings(ctx)...)
+	findings = append(findings, tapFindings(ctx)...)
+	return findings
+}
+
+// brewDoctorFindings runs brew doctor and converts each warning to a finding
+// Edge cases: A clean run yields one OK finding; brew doctor exits non-zero whenever it warns, so only missing output is an error
+func brewDoctorFindings(ctx context.Context) []Finding {
+	out, err := exec.CommandContext(ctx, "brew", "doctor").CombinedOutput()
+	warnings := parseBrewDoctor(string(out))
+	if err != nil && len(warnings) == 0 {
+		return []Finding{{Name: "brew doctor", Status: StatusWarning, Message: fmt.Sprintf("did not complete: %v", err)}}
+	}
+	if len(warnings) == 0 {
+		return []Finding{{Name: "brew doctor", Status: StatusOK, Message: "no warnings"}}
+	}
+
+	var findings []Finding
+	for _, w := range warnings {
+		category := brewCategory(w.Title)
+		f := Finding{Name: "brew doctor: " + category, Status: StatusWarning, Message: w.Title, FixCommand: brewDoctorFix(w)}
+		if len(w.Items) > 0 {
+			shown := w.Items[:min(len(w.Items), 3)]
+			f.Guidance = strings.Join(shown, ", ")
+			if more := len(w.Items) - len(shown); more > 0 {
+				f.Guidance += fmt.Sprintf(" (+%d more)", more)
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// brewMissingFindings reports installed formulae with missing dependencies
+// Returns: One error finding per formula with a brew install fix, or a single OK finding
+func brewMissingFindings(ctx context.Context) []Finding {
+	out, err := brewOutput(ctx, "missing")
+	if err != nil {
+		return []Finding{{Name: "dependencies", Status: StatusWarning, Message: fmt.Sprintf("brew missing failed: %v", err)}}
+	}
+	missing := parseBrewMissing(out)
+	if len(missing) == 0 {
+		return []Finding{{Name: "dependencies", Status: StatusOK, Message: "no missing dependencies"}}
+	}
+
+	var findings []Finding
+	for _, m := range missing {
+		findings = append(findings, Finding{
+			Name:       "dependencies: " + m[0],
+			Status:     StatusError,
+			Message:    "missing " + strings.Join(m[1:], ", "),
+			FixCommand: "brew install " + strings.Join(m[1:], " "),
+		})
+	}
+	return findings
+}
+
+// shallowTapFindings flags shallow clones of the core taps
+// Why: A shallow homebrew-core breaks `brew update` and formula history; since Homebrew 4 the taps are optional
+// Edge cases: Untapped core taps (formulae from the JSON API) are fine and not reported
+func shallowTapFindings(ctx context.Context) []Finding {
+	var findings []Finding
+	for _, tap := range []string{"homebrew/core", "homebrew/cask"} {
+		repo, err := brewOutput(ctx, "--repository", tap)
+		if err != nil || !isDir(repo) {
+			continue
+		}
+		f := Finding{Name: tap, Status: StatusOK, Message: "full clone"}
+		if _, err := os.Stat(filepath.Join(repo, ".git", "shallow")); err == nil {
+			f.Status = StatusWarning
+			f.Message = "shallow clone (brew update fails)"
+			f.FixCommand = fmt.Sprintf("git -C %q fetch --unshallow", repo)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// tapFindings verifies each tapped repository is an intact git checkout
+// What: HEAD resolves, an origin remote is set, and the tree has no local modifications
+// Returns: One finding per problematic tap, or a single OK finding
+func tapFindings(ctx context.Context) []Finding {
+	out, err := brewOutput(ctx, "tap")
+	if err != nil {
+		return []Finding{{Name: "taps", Status: StatusWarning, Message: fmt.Sprintf("brew tap failed: %v", err)}}
+	}
+
+	taps := strings.Fields(out)
+	var findings []Finding
+	for _, tap := range taps {
+		repo, err := brewOutput(ctx, "--repository", tap)
+		if err != nil {
+			continue
+		}
+		problem, fix := tapProblem(ctx, tap, repo)
+		if problem == "" {
+			continue
+		}
+		f := Finding{Name: "tap " + tap, Status: StatusWarning, Message: problem, FixCommand: fix}
+		if fix == "" {
+			f.Guidance = fmt.Sprintf("Inspect %s, or re-tap with: brew untap %s && brew tap %s", repo, tap, tap)
+		}
+		findings = append(findings, f)
+	}
+	if len(findings) == 0 {
+		return []Finding{{Name: "taps", Status: StatusOK, Message: fmt.Sprintf("%d tap(s) intact", len(taps))}}
+	}
+	return findings
+}
+
+// tapProblem describes what is wrong with one tap checkout
+// Returns: Problem ("" when intact) and a fix command ("" when the fix could lose local work)
+func tapProblem(ctx context.Context, tap, repo string) (string, string) {
+	retap := fmt.Sprintf("brew untap %s && brew tap %s", tap, tap)
+	git := func(args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, "git", append([]string{"-C", repo}, args...)...).Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	if !isDir(repo) {
+		return "checkout missing", retap
+	}
+	if _, err := git("rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		return "git checkout is corrupt (HEAD does not resolve)", retap
+	}
+	if _, err := git("remote", "get-url", "origin"); err != nil {
+		return "no origin remote (cannot update)", retap
+	}
+	if status, err := git("status", "--porcelain"); err == nil && status != "" {
+		return "has local modifications (brew update may fail)", ""
+	}
+	return "", ""
+}
+
+// parseBrewDoctor splits brew doctor output into warnings
+// Edge cases: Text before the first "Warning:" (the preamble) is ignored; items are indented lines
+func parseBrewDoctor(output string) []brewWarning {
+	var warnings []brewWarning
+	var current *brewWarning
+	for _, line := range strings.Split(output, "\n") {
+		if title, ok := strings.CutPrefix(line, "Warning: "); ok {
+			warnings = append(warnings, brewWarning{Title: strings.TrimSpace(title)})
+			current = &warnings[len(warnings)-1]
+			continue
+		}
+		if current != nil && strings.HasPrefix(line, "  ") && strings.TrimSpace(line) != "" {
+			current.Items = append(current.Items, strings.TrimSpace(line))
+		}
+	}
+	return warnings
+}
+
+// parseBrewMissing parses `brew missing` lines ("formula: dep dep")
+// Returns: One slice per formula: the formula followed by its missing dependencies
+func parseBrewMissing(output string) [][]string {
+	var missing [][]string
+	for _, line := range strings.Split(output, "\n") {
+		formula, deps, ok := strings.Cut(line, ":")
+		if !ok || len(strings.Fields(deps)) == 0 {
+			continue
+		}
+		missing = append(missing, append([]string{strings.TrimSpace(formula)}, strings.Fields(deps)...))
+	}
+	return missing
+}
+
+// brewCategory returns the category for a brew doctor warning title
+func brewCategory(title string) string {
+	lower := strings.ToLower(title)
+	for _, c := range brewCategories {
+		for _, k := range c.keywords {
+			if strings.Contains(lower, k) {
+				return c.name
+			}
+		}
+	}
+	return "other"
+}
+
+// brewDoctorFix returns a safe fix for a brew doctor warning
+// Returns: brew cleanup for broken symlinks, brew link for unlinked kegs, otherwise ""
+func brewDoctorFix(w brewWarning) string {
+	lower := strings.ToLower(w.Title)
+	switch {
+	case strings.Contains(lower, "broken symlinks"):
+		return "brew cleanup"
+	case (strings.Contains(lower, "unlinked") || strings.Contains(lower, "not linked")) && len(w.Items) > 0:
+		return "brew link " + strings.Join(w.Items, " ")
+	}
+	return ""
+}
+
+// brewOutput runs brew with args and returns trimmed stdout
+func brewOutput(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "brew", args...).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,56 @@
+package doctor
+
+import (
+	"reflect"
+	"testing"
+)
+
+const brewDoctorOutput = `Please note that these warnings are just used to help the Homebrew maintainers
+with debugging if you file an issue. If everything you use Homebrew for is
+working fine: please don't worry or file an issue; just ignore this. Thanks!
+
+Warning: You have unlinked kegs in your Cellar.
+Leaving kegs unlinked can lead to build-trouble and cause formulae that depend on
+those kegs to fail to run properly once built. Run ` + "`brew link`" + ` on these:
+  jq
+  node
+
+Warning: Unbrewed header files were found in /usr/local/include.
+If you didn't put them there on purpose they could cause problems when
+building Homebrew formulae, and may need to be deleted.
+
+Unexpected header files:
+  /usr/local/include/foo.h
+`
+
+func TestParseBrewDoctor(t *testing.T) {
+	warnings := parseBrewDoctor(brewDoctorOutput)
+	want := []brewWarning{
+		{Title: "You have unlinked kegs in your Cellar.", Items: []string{"jq", "node"}},
+		{Title: "Unbrewed header files were found in /usr/local/include.", Items: []string{"/usr/local/include/foo.h"}},
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Fatalf("parseBrewDoctor() = %+v, want %+v", warnings, want)
+	}
+
+	if got := brewCategory(warnings[0].Title); got != "links" {
+		t.Errorf("category = %q, want links", got)
+	}
+	if got := brewCategory(warnings[1].Title); got != "unbrewed files" {
+		t.Errorf("category = %q, want unbrewed files", got)
+	}
+	if got := brewDoctorFix(warnings[0]); got != "brew link jq node" {
+		t.Errorf("fix = %q", got)
+	}
+	if got := brewDoctorFix(warnings[1]); got != "" {
+		t.Errorf("fix for unbrewed files = %q, want none", got)
+	}
+}
+
+func TestParseBrewMissing(t *testing.T) {
+	got := parseBrewMissing("ffmpeg: libvpx x264\nimagemagick: libheif\n")
+	want := [][]string{{"ffmpeg", "libvpx", "x264"}, {"imagemagick", "libheif"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBrewMissing() = %v, want %v", got, want)
+	}
+}
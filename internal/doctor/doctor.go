@@ -0,0 +1,508 @@
+// File: internal/doctor/doctor.go
+// Purpose: Diagnostics engine for `devsetup doctor` with guided fixes
+// Problem: Users were told to run brew doctor and read docs themselves to find environment issues
+// Role: Runs diagnostic checks, reports findings by category, and offers to apply fixes
+// Usage: d := NewDoctor(policies, ui, fix); findings, err := d.Run()
+// Design choices: Each diagnostic is a function returning findings; fixes are shell commands confirmed per finding
+// Assumptions: User is present to confirm fixes; some fixes prompt for sudo password
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/policy"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+	"github.com/rkinnovate/dev-setup/internal/redact"
+	"github.com/rkinnovate/dev-setup/internal/rosetta"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+// Status is the severity of a finding
+type Status string
+
+const (
+	// StatusOK means the check passed
+	StatusOK Status = "ok"
+	// StatusWarning means a recommended item is not satisfied
+	StatusWarning Status = "warning"
+	// StatusError means a required item is not satisfied
+	StatusError Status = "error"
+)
+
+// checkTimeout bounds each diagnostic so doctor never hangs
+const checkTimeout = 30 * time.Second
+
+// Finding is a single diagnostic result
+// What: Outcome of one diagnostic with optional remediation
+// Why: Doctor reports findings by category and offers fixes for those with a FixCommand
+type Finding struct {
+	// Category groups findings in output (e.g. "policy")
+	Category string
+
+	// Name identifies the checked item
+	Name string
+
+	// Status is ok, warning, or error
+	Status Status
+
+	// Message describes the observed state
+	Message string
+
+	// Guidance is manual remediation instructions
+	Guidance string
+
+	// FixCommand is a shell command that remediates the finding
+	FixCommand string
+
+	// Diff previews the change FixCommand makes to a file (unified diff), shown before asking to apply it
+	Diff string
+}
+
+// diagnostic produces findings for one area of the environment
+type diagnostic struct {
+	category string
+	heading  string
+	run      func(ctx context.Context) []Finding
+}
+
+// Doctor runs diagnostics and guided fixes
+// What: Holds configs needed by diagnostics plus UI and fix mode
+// Why: Single entry point for the doctor command
+type Doctor struct {
+	policies *config.PolicyConfig
+	tools    *config.ToolsConfig
+	state    *config.State
+	setup    *config.SetupConfig
+	ui       ui.UI
+	fix      bool
+}
+
+// NewDoctor creates a new doctor
+// What: Constructor for Doctor with policy config, UI, and fix mode
+// Why: Centralized creation with dependencies
+// Params: policies - compliance policies (nil skips policy checks), ui - UI for output, fix - offer guided fixes
+// Returns: Configured Doctor instance
+// Example: d := NewDoctor(policies, progressUI, true)
+func NewDoctor(policies *config.PolicyConfig, ui ui.UI, fix bool) *Doctor {
+	return &Doctor{
+		policies: policies,
+		ui:       ui,
+		fix:      fix,
+	}
+}
+
+// SetTools enables tools.yaml diagnostics (macOS compatibility)
+// Params: tools - tools configuration (nil skips them), state - install state used to tell which tools are installed
+func (d *Doctor) SetTools(tools *config.ToolsConfig, state *config.State) {
+	d.tools = tools
+	d.state = state
+}
+
+// SetSetup marks the .zshrc lines setup.yaml adds in shell startup findings
+// Params: setup - setup configuration (nil = only the managed PATH block is marked)
+func (d *Doctor) SetSetup(setup *config.SetupConfig) {
+	d.setup = setup
+}
+
+// Run executes all diagnostics, prints findings, and applies confirmed fixes
+// What: Runs each diagnostic in turn, reports, then walks fixable findings when fix mode is on
+// Why: Main entry point for doctor command
+// Returns: All findings and error if any error-level finding remains unresolved
+// Example: findings, err := d.Run()
+func (d *Doctor) Run() ([]Finding, error) {
+	d.ui.Info("🔧 Running diagnostics...")
+	d.ui.Info("")
+
+	var findings []Finding
+	for _, diag := range d.diagnostics() {
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		results := diag.run(ctx)
+		cancel()
+
+		for i := range results {
+			results[i].Category = diag.category
+		}
+
+		d.ui.Info("%s", diag.heading)
+		for _, f := range results {
+			d.printFinding(f)
+		}
+		d.ui.Info("")
+
+		findings = append(findings, results...)
+	}
+
+	if d.fix {
+		d.applyFixes(findings)
+	}
+
+	errors := 0
+	warnings := 0
+	for _, f := range findings {
+		switch f.Status {
+		case StatusError:
+			errors++
+		case StatusWarning:
+			warnings++
+		}
+	}
+
+	if errors == 0 && warnings == 0 {
+		d.ui.Success("✅ No issues found")
+		return findings, nil
+	}
+
+	d.ui.Info("Found %d error(s), %d warning(s)", errors, warnings)
+	if !d.fix && hasFixes(findings) {
+		d.ui.Info("Run 'devsetup doctor --fix' to apply available fixes")
+	}
+
+	if errors > 0 {
+		return findings, fmt.Errorf("doctor found %d error(s)", errors)
+	}
+	return findings, nil
+}
+
+// diagnostics returns the diagnostics to run, in display order
+// Returns: Slice of diagnostics enabled by the current configuration
+func (d *Doctor) diagnostics() []diagnostic {
+	var diags []diagnostic
+	if d.policies != nil {
+		diags = append(diags, diagnostic{
+			category: "policy",
+			heading:  "🛡️  Compliance policies",
+			run:      d.checkPolicies,
+		})
+		diags = append(diags, diagnostic{
+			category: "denied",
+			heading:  "⛔ Denied software",
+			run:      d.checkDenied,
+		})
+	}
+	if _, err := exec.LookPath("brew"); err == nil {
+		diags = append(diags, diagnostic{
+			category: "brew-health",
+			heading:  "🩺 Homebrew health",
+			run:      d.checkBrewHealth,
+		})
+	}
+	diags = append(diags, diagnostic{
+		category: "version-managers",
+		heading:  "🧰 Version managers",
+		run:      d.checkVersionManagers,
+	})
+	if d.tools != nil && d.tools.MacOS != nil {
+		diags = append(diags, diagnostic{
+			category: "macos",
+			heading:  "🍎 macOS compatibility",
+			run:      d.checkMacOS,
+		})
+	}
+	if d.tools != nil && rosetta.AppleSilicon(context.Background()) {
+		diags = append(diags, diagnostic{
+			category: "rosetta",
+			heading:  "🧬 Rosetta 2",
+			run:      d.checkRosetta,
+		})
+	}
+	if d.tools != nil && runtime.GOOS == "darwin" {
+		diags = append(diags, diagnostic{
+			category: "quarantine",
+			heading:  "🚧 Gatekeeper quarantine",
+			run:      d.checkQuarantine,
+		})
+	}
+	if _, err := exec.LookPath("zsh"); err == nil {
+		diags = append(diags, diagnostic{
+			category: "shell-startup",
+			heading:  "🐚 Shell startup",
+			run:      d.checkShellStartup,
+		})
+	}
+	if d.policies != nil && d.policies.Homebrew != nil {
+		diags = append(diags, diagnostic{
+			category: "homebrew",
+			heading:  "🍺 Homebrew hardening",
+			run:      d.checkHomebrew,
+		})
+	}
+	return diags
+}
+
+// checkPolicies evaluates compliance policies
+// What: Converts policy results into findings (required → error, recommended → warning)
+// Why: Doctor offers guided fixes for non-compliant baselines
+// Params: ctx - context with timeout
+// Returns: One finding per policy
+func (d *Doctor) checkPolicies(ctx context.Context) []Finding {
+	var findings []Finding
+	for _, r := range policy.EvaluateAll(ctx, d.policies) {
+		findings = append(findings, policyFinding(r))
+	}
+	return findings
+}
+
+// checkHomebrew evaluates the Homebrew hardening baseline
+// What: Analytics, brew.env settings, and directory ownership from policies.yaml homebrew section
+// Why: Verifies what the homebrew_policy setup task applied and offers the same fixes
+// Params: ctx - context with timeout
+// Returns: One finding per managed setting
+func (d *Doctor) checkHomebrew(ctx context.Context) []Finding {
+	var findings []Finding
+	for _, r := range policy.EvaluateHomebrew(ctx, *d.policies.Homebrew) {
+		findings = append(findings, policyFinding(r))
+	}
+	return findings
+}
+
+// checkMacOS compares the running macOS with the tools.yaml macos section
+// What: Minimum version, tools broken on this release (error), and tools the next upgrade would break (warning)
+// Why: Developers should hold an OS upgrade until pinned casks are replaced, not find out afterwards
+// Params: ctx - context with timeout
+// Returns: Findings; only installed tools are reported for incompatibilities
+func (d *Doctor) checkMacOS(ctx context.Context) []Finding {
+	current, err := policy.MacOSVersion(ctx)
+	if err != nil {
+		return []Finding{{Name: "macOS version", Status: StatusWarning, Message: fmt.Sprintf("unable to determine: %v", err)}}
+	}
+
+	requirements := d.tools.MacOS
+	findings := []Finding{{Name: "macOS " + current, Status: StatusOK, Message: "supported"}}
+	if !requirements.Supports(current) {
+		findings[0] = Finding{
+			Name:     "macOS " + current,
+			Status:   StatusError,
+			Message:  "below supported minimum " + requirements.MinVersion,
+			Guidance: "Update macOS via System Settings → General → Software Update",
+		}
+	}
+
+	for _, e := range requirements.BrokenOn(current) {
+		if config.IsToolInstalled(d.state, e.Tool) {
+			findings = append(findings, Finding{
+				Name:     e.Tool,
+				Status:   StatusError,
+				Message:  fmt.Sprintf("incompatible with macOS %s+: %s", e.Since, e.Reason),
+				Guidance: fmt.Sprintf("Replace or remove %s (see tools.yaml macos.incompatible)", e.Tool),
+			})
+		}
+	}
+	for _, e := range requirements.Upcoming(current) {
+		if config.IsToolInstalled(d.state, e.Tool) {
+			findings = append(findings, Finding{
+				Name:     e.Tool,
+				Status:   StatusWarning,
+				Message:  fmt.Sprintf("upgrading to macOS %s will break it: %s", e.Since, e.Reason),
+				Guidance: fmt.Sprintf("Hold the macOS %s upgrade until %s is replaced", e.Since, e.Tool),
+			})
+		}
+	}
+	return findings
+}
+
+// policyFinding converts a policy result (required → error, recommended → warning)
+func policyFinding(r policy.Result) Finding {
+	f := Finding{
+		Name:       r.Policy.Name,
+		Status:     StatusOK,
+		Message:    r.Detail,
+		Guidance:   r.Guidance,
+		FixCommand: r.FixCommand,
+		Diff:       r.Diff,
+	}
+	if !r.Compliant {
+		f.Status = StatusError
+		if !r.Policy.IsRequired() {
+			f.Status = StatusWarning
+		}
+	}
+	return f
+}
+
+// checkDenied flags installed software on the org denylist
+// What: One error finding per installed denied package with its uninstall command
+// Why: Unapproved VPNs and EOL runtimes must be removed
+// Params: ctx - context with timeout
+// Returns: Findings (single OK finding when nothing denied is installed)
+func (d *Doctor) checkDenied(ctx context.Context) []Finding {
+	if len(d.policies.Denied) == 0 {
+		return nil
+	}
+
+	matches, err := policy.FindDenied(ctx, d.policies)
+	if err != nil {
+		return []Finding{{Name: "denylist scan", Status: StatusWarning, Message: err.Error()}}
+	}
+	if len(matches) == 0 {
+		return []Finding{{Name: "denylist", Status: StatusOK, Message: "no denied software installed"}}
+	}
+
+	var findings []Finding
+	for _, m := range matches {
+		f := Finding{
+			Name:       fmt.Sprintf("%s %s", m.Kind, m.Name),
+			Status:     StatusError,
+			Message:    m.Entry.Reason,
+			FixCommand: m.UninstallCommand(),
+		}
+		if f.FixCommand == "" {
+			f.Guidance = fmt.Sprintf("Remove %s manually (installed outside Homebrew)", m.Name)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// checkVersionManagers flags Node and Python version managers installed side by side
+// What: One finding per detected manager; those other than the policies.yaml version_managers standard get cleanup
+// Why: Competing managers rewrite PATH in shell hooks, so node/python resolve differently per shell
+// Params: ctx - context with timeout (unused; detection is filesystem-only)
+// Returns: Findings (single OK finding when no manager is detected)
+func (d *Doctor) checkVersionManagers(ctx context.Context) []Finding {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return []Finding{{Name: "version managers", Status: StatusWarning, Message: err.Error()}}
+	}
+	found := policy.FindVersionManagers(home)
+	if len(found) == 0 {
+		return []Finding{{Name: "version managers", Status: StatusOK, Message: "none detected"}}
+	}
+
+	var standards map[string]string
+	if d.policies != nil {
+		standards = d.policies.VersionManagers
+	}
+
+	var findings []Finding
+	for _, lang := range []string{"node", "python"} {
+		var group []policy.FoundVersionManager
+		for _, f := range found {
+			if f.Language == lang {
+				group = append(group, f)
+			}
+		}
+		standard := standards[lang]
+
+		for _, f := range group {
+			finding := Finding{Name: fmt.Sprintf("%s (%s)", f.Name, lang), Status: StatusOK, Message: managerDetail(f)}
+			switch {
+			case standard == f.Name && f.Location == "":
+				finding.Status = StatusWarning
+				finding.Guidance = fmt.Sprintf("Org standard %s is missing; run 'devsetup install' or remove the hooks", f.Name)
+			case standard == f.Name:
+				finding.Message += "; org standard"
+			case standard != "":
+				finding.Status = StatusWarning
+				finding.Message = fmt.Sprintf("%s; org standard is %s", managerDetail(f), standard)
+				finding.FixCommand = f.CleanupCommand()
+				finding.Guidance = fmt.Sprintf("Remove %s and its shell hooks (rc files are backed up as *.devsetup-bak)", f.Name)
+			case len(group) > 1:
+				finding.Status = StatusWarning
+				finding.Message = fmt.Sprintf("%s; %d %s managers installed", managerDetail(f), len(group), lang)
+				finding.Guidance = fmt.Sprintf("Keep one %s manager; set version_managers.%s in policies.yaml to get cleanup commands", lang, lang)
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// managerDetail describes where a version manager was found
+// Returns: e.g. "installed at ~/.nvm, hooks in ~/.zshrc:12" or "not installed, dangling hooks in ..."
+func managerDetail(f policy.FoundVersionManager) string {
+	var hooks []string
+	for _, h := range f.Hooks {
+		hooks = append(hooks, fmt.Sprintf("%s:%d", h.File, h.Line))
+	}
+
+	switch {
+	case f.Location == "":
+		return "not installed, dangling hooks in " + strings.Join(hooks, ", ")
+	case len(hooks) == 0:
+		return "installed at " + f.Location
+	default:
+		return fmt.Sprintf("installed at %s, hooks in %s", f.Location, strings.Join(hooks, ", "))
+	}
+}
+
+// printFinding prints one finding with status symbol and guidance
+// Params: f - finding to print
+func (d *Doctor) printFinding(f Finding) {
+	switch f.Status {
+	case StatusOK:
+		d.ui.Success("  ✓ %s %s", f.Name, parenthesize(f.Message))
+	case StatusWarning:
+		d.ui.Warning("  ⚠ %s: %s", f.Name, f.Message)
+	case StatusError:
+		d.ui.Error("  ✗ %s: %s", f.Name, f.Message)
+	}
+
+	if f.Status != StatusOK && f.Guidance != "" {
+		d.ui.Info("      → %s", f.Guidance)
+	}
+}
+
+// applyFixes offers each available fix to the user
+// What: Prompts y/N for each non-OK finding with a FixCommand and runs confirmed ones
+// Why: Guided fixes keep the user in control (fixes may need sudo or change system settings)
+// Params: findings - findings to walk (updated in place when a fix succeeds)
+func (d *Doctor) applyFixes(findings []Finding) {
+	for i := range findings {
+		f := &findings[i]
+		if f.Status == StatusOK || f.FixCommand == "" {
+			continue
+		}
+
+		d.ui.Info("🩺 %s: %s", f.Name, f.Message)
+		d.ui.Info("   Fix: %s", f.FixCommand)
+		d.ui.Diff(f.Diff)
+		ok, err := prompt.Confirm(f.Name, "   Apply this fix? [y/N] ")
+		if err != nil {
+			d.ui.Info("   Deferred")
+			continue
+		}
+		if !ok {
+			d.ui.Info("   Skipped")
+			continue
+		}
+
+		cmd := exec.Command("sh", "-c", f.FixCommand)
+		cmd.Stdin = prompt.Stdin()
+		cmd.Stdout = redact.NewWriter(os.Stdout)
+		cmd.Stderr = redact.NewWriter(os.Stderr)
+		if err := cmd.Run(); err != nil {
+			d.ui.Error("   ✗ Fix failed: %v", err)
+			continue
+		}
+
+		d.ui.Success("   ✓ Fixed")
+		f.Status = StatusOK
+	}
+	d.ui.Info("")
+}
+
+// hasFixes reports whether any unresolved finding has a fix command
+func hasFixes(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Status != StatusOK && f.FixCommand != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parenthesize wraps a detail message in parentheses when non-empty
+func parenthesize(message string) string {
+	if message == "" {
+		return ""
+	}
+	return "(" + message + ")"
+}
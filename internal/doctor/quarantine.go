@@ -0,0 +1,88 @@
+// File: internal/doctor/quarantine.go
+// Purpose: Gatekeeper diagnostics for `devsetup doctor`: installed tools still blocked by quarantine
+// Problem: A tool installed fine but its first run failed with "cannot be opened because the developer cannot be
+//          verified", and people didn't connect that to the download's quarantine attribute
+// Role: Reports installed tools (state.json paths and declared apps) that are quarantined and rejected by Gatekeeper
+// Usage: Registered by diagnostics() on macOS when tools.yaml is loaded
+// Design choices: Only paths devsetup knows about are checked; the fix clears quarantine only where the
+//                 policies.yaml quarantine section allows clear, otherwise the guidance is the manual approval
+// Assumptions: Recorded paths are current (a stale path is skipped)
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/installer"
+	"github.com/rkinnovate/dev-setup/internal/quarantine"
+)
+
+// checkQuarantine reports installed tools Gatekeeper would block
+// What: Each installed tool's binary and declared apps, checked for quarantine and a Gatekeeper rejection
+// Why: Blocked binaries fail on first run, far from the install that caused it
+// Params: ctx - context with timeout
+// Returns: Findings (single OK finding when nothing is blocked)
+func (d *Doctor) checkQuarantine(ctx context.Context) []Finding {
+	return quarantineFindings(d.tools, d.state, d.policies, func(path string) string {
+		return quarantine.Blocked(ctx, path)
+	})
+}
+
+// quarantineFindings builds the quarantine findings
+// Params: tools - tools config, state - install state, policies - org policies (nil allowed), blocked - Gatekeeper's reason for rejecting a path ("" = runs)
+// Returns: One warning per blocked path, sorted by tool name
+func quarantineFindings(tools *config.ToolsConfig, state *config.State, policies *config.PolicyConfig, blocked func(path string) string) []Finding {
+	_, clearErr := policies.QuarantineAction(config.QuarantineClear)
+
+	paths := make(map[string][]string)
+	if state != nil {
+		for name, ts := range state.Installed {
+			if ts.Path != "" && ts.Path != "unknown" {
+				paths[name] = append(paths[name], ts.Path)
+			}
+		}
+	}
+	for _, tool := range tools.Tools {
+		if !config.IsToolInstalled(state, tool.Name) {
+			continue
+		}
+		for _, app := range tool.Apps {
+			if p := installer.AppPath(app); p != "" {
+				paths[tool.Name] = append(paths[tool.Name], p)
+			}
+		}
+	}
+
+	var names []string
+	for name := range paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []Finding
+	for _, name := range names {
+		for _, p := range paths[name] {
+			reason := blocked(p)
+			if reason == "" {
+				continue
+			}
+			f := Finding{
+				Name:     name,
+				Status:   StatusWarning,
+				Message:  fmt.Sprintf("%s is quarantined and Gatekeeper blocks it (%s)", p, reason),
+				Guidance: "Open it once from Finder with right-click → Open, or approve it in System Settings → Privacy & Security",
+			}
+			if clearErr == nil {
+				f.FixCommand = quarantine.ClearCommand(p)
+			}
+			findings = append(findings, f)
+		}
+	}
+	if len(findings) == 0 {
+		return []Finding{{Name: "quarantine", Status: StatusOK, Message: "no installed tools blocked by Gatekeeper"}}
+	}
+	return findings
+}
@@ -0,0 +1,38 @@
+package doctor
+
+import (
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestQuarantineFindings(t *testing.T) {
+	tools := &config.ToolsConfig{Tools: []config.Tool{{Name: "jq"}, {Name: "kubectx"}}}
+	state := &config.State{Installed: map[string]config.ToolState{
+		"jq":      {Path: "/opt/homebrew/bin/jq"},
+		"kubectx": {Path: "/Users/dev/.local/bin/kubectx"},
+		"gone":    {Path: "unknown"},
+	}}
+	blocked := func(path string) string {
+		if path == "/Users/dev/.local/bin/kubectx" {
+			return "rejected"
+		}
+		return ""
+	}
+
+	findings := quarantineFindings(tools, state, nil, blocked)
+	if len(findings) != 1 || findings[0].Name != "kubectx" || findings[0].Status != StatusWarning || findings[0].FixCommand == "" {
+		t.Fatalf("findings = %+v, want one kubectx warning with a fix", findings)
+	}
+
+	// The fix clears quarantine, so it is only offered where policy allows clearing
+	strict := &config.PolicyConfig{Quarantine: &config.QuarantinePolicy{Allow: []string{config.QuarantineApprove}}}
+	if f := quarantineFindings(tools, state, strict, blocked); f[0].FixCommand != "" || f[0].Guidance == "" {
+		t.Errorf("clear not allowed: %+v, want guidance only", f[0])
+	}
+
+	none := func(string) string { return "" }
+	if f := quarantineFindings(tools, state, nil, none); len(f) != 1 || f[0].Status != StatusOK {
+		t.Errorf("nothing blocked: %+v, want single ok finding", f)
+	}
+}
@@ -0,0 +1,96 @@
+// File: internal/doctor/rosetta.go
+// Purpose: Rosetta 2 diagnostics for `devsetup doctor` on Apple Silicon
+// Problem: Tools migrated from an Intel Mac (or installed by Intel Homebrew in /usr/local) kept running translated,
+//          slower and with native-module mismatches, and nothing told the developer which ones
+// Role: Reports installed tools whose executable is Intel-only, and Rosetta 2 missing while declared Intel-only
+//       tools need it
+// Usage: Registered by diagnostics() when tools.yaml is loaded and the Mac is Apple Silicon
+// Design choices: Looks at the executable recorded in state.json, so only tools devsetup knows about are reported;
+//                 tools declared rosetta: true are expected to be Intel-only and reported as ok
+// Assumptions: Recorded paths are current (a stale path is skipped, not reported)
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/rosetta"
+)
+
+// installRosettaCommand installs Rosetta 2 non-interactively
+const installRosettaCommand = "sudo softwareupdate --install-rosetta --agree-to-license"
+
+// checkRosetta reports tools running under Rosetta 2
+// What: Classifies each installed tool's executable by architecture and checks Rosetta is present when needed
+// Why: Intel-only binaries that have native builds should be reinstalled; declared Intel-only tools need Rosetta
+// Params: ctx - context with timeout
+// Returns: Findings (single OK finding when no tool runs translated)
+func (d *Doctor) checkRosetta(ctx context.Context) []Finding {
+	return rosettaFindings(d.tools, d.state, rosetta.Missing(ctx), func(path string) string {
+		return rosetta.BinaryArch(ctx, path)
+	})
+}
+
+// rosettaFindings builds the Rosetta findings
+// Params: tools - tools config, state - install state, missing - Rosetta 2 is not installed, arch - classifies an executable
+// Returns: Findings sorted by tool name, after a Rosetta availability finding when one is needed
+func rosettaFindings(tools *config.ToolsConfig, state *config.State, missing bool, arch func(path string) string) []Finding {
+	declared := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		declared[tool.Name] = tool.Rosetta
+	}
+
+	var names []string
+	if state != nil {
+		for name := range state.Installed {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var needing []string
+	var results []Finding
+	for _, name := range names {
+		path := state.Installed[name].Path
+		if path == "" || path == "unknown" {
+			continue
+		}
+		if declared[name] {
+			needing = append(needing, name)
+		}
+		if arch(path) != rosetta.ArchIntel {
+			continue
+		}
+		if declared[name] {
+			results = append(results, Finding{Name: name, Status: StatusOK, Message: "Intel-only, runs under Rosetta 2 (declared rosetta: true)"})
+			continue
+		}
+		f := Finding{
+			Name:     name,
+			Status:   StatusWarning,
+			Message:  fmt.Sprintf("runs under Rosetta 2 (%s is Intel-only)", path),
+			Guidance: fmt.Sprintf("Reinstall %s to get a native arm64 build", name),
+		}
+		if strings.HasPrefix(path, "/usr/local/") {
+			f.Guidance = fmt.Sprintf("Installed by Intel Homebrew in /usr/local; reinstall %s with /opt/homebrew/bin/brew", name)
+		}
+		results = append(results, f)
+	}
+
+	if missing && len(needing) > 0 {
+		results = append([]Finding{{
+			Name:       "Rosetta 2",
+			Status:     StatusError,
+			Message:    "not installed; needed by " + strings.Join(needing, ", "),
+			FixCommand: installRosettaCommand,
+		}}, results...)
+	}
+	if len(results) == 0 {
+		return []Finding{{Name: "Rosetta 2", Status: StatusOK, Message: "no installed tools run under Rosetta"}}
+	}
+	return results
+}
@@ -0,0 +1,42 @@
+package doctor
+
+import (
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/rosetta"
+)
+
+func TestRosettaFindings(t *testing.T) {
+	tools := &config.ToolsConfig{Tools: []config.Tool{{Name: "legacy", Rosetta: true}, {Name: "node"}, {Name: "jq"}}}
+	state := &config.State{Installed: map[string]config.ToolState{
+		"legacy": {Path: "/Applications/Legacy.app/Contents/MacOS/legacy"},
+		"node":   {Path: "/usr/local/bin/node"},
+		"jq":     {Path: "/opt/homebrew/bin/jq"},
+	}}
+	arch := func(path string) string {
+		if path == "/opt/homebrew/bin/jq" {
+			return rosetta.ArchNative
+		}
+		return rosetta.ArchIntel
+	}
+
+	findings := rosettaFindings(tools, state, true, arch)
+	if len(findings) != 3 {
+		t.Fatalf("findings = %+v, want 3", findings)
+	}
+	if f := findings[0]; f.Name != "Rosetta 2" || f.Status != StatusError || f.FixCommand != installRosettaCommand {
+		t.Errorf("first finding = %+v, want missing Rosetta error with fix", f)
+	}
+	if f := findings[1]; f.Name != "legacy" || f.Status != StatusOK {
+		t.Errorf("declared Intel-only tool = %+v, want ok", f)
+	}
+	if f := findings[2]; f.Name != "node" || f.Status != StatusWarning || f.Guidance == "" {
+		t.Errorf("translated tool = %+v, want warning with guidance", f)
+	}
+
+	native := func(string) string { return rosetta.ArchNative }
+	if findings := rosettaFindings(tools, state, false, native); len(findings) != 1 || findings[0].Status != StatusOK {
+		t.Errorf("all native: %+v, want single ok finding", findings)
+	}
+}
@@ -0,0 +1,291 @@
+// File: internal/doctor/shell.go
+// Purpose: Interactive zsh startup time diagnostics for `devsetup doctor`
+// Problem: Every onboarding step appended another source/eval line to ~/.zshrc (plugins, prompt, version managers),
+//          and new terminal tabs got slower without anyone noticing which line cost what
+// Role: Times `zsh -i -c exit` against the policies.yaml shell_startup budget and, over budget, lists the slowest
+//       ~/.zshrc lines, marking the ones devsetup added (managed PATH block, setup.yaml zshrc_lines)
+// Usage: Registered by diagnostics() when zsh is on PATH
+// Design choices: The total is the median of a few real startups; per-line costs come from one extra startup run
+//                 with xtrace and a timestamped PS4 through a wrapper ZDOTDIR, so a line's cost includes everything
+//                 it sources; lines are attributed by file and line number, not by matching command text
+// Assumptions: zsh 5.6+ (millisecond %D{%.} in prompts, standard on macOS); the rc file is ~/.zshrc or $ZDOTDIR/.zshrc
+
+package doctor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/pathmgr"
+)
+
+// Shell startup measurement
+const (
+	// shellStartupRuns is how many timed startups are taken (the median is reported)
+	shellStartupRuns = 3
+	// slowSnippets is how many of the slowest .zshrc lines are listed
+	slowSnippets = 5
+	// minSnippetCost hides lines too fast to matter
+	minSnippetCost = 10 * time.Millisecond
+	// tracePS4 prefixes each xtrace line with a timestamp (µs), the source file, and the line in it
+	tracePS4 = "+%D{%s.%6.}|%x|%I> "
+)
+
+// traceLine matches one timestamped xtrace line
+var traceLine = regexp.MustCompile(`^\+*(\d+\.\d+)\|([^|]*)\|(\d+)> `)
+
+// snippet is one ~/.zshrc line and what it costs at startup
+type snippet struct {
+	line    int
+	text    string
+	cost    time.Duration
+	managed bool
+}
+
+// checkShellStartup times interactive zsh startup
+// What: Median startup against the budget; over budget, the slowest ~/.zshrc lines with devsetup's marked
+// Why: Onboarding keeps adding to the shell; this says whether devsetup's additions are what made it slow
+// Params: ctx - context with timeout
+// Returns: Findings (a single OK finding within budget)
+func (d *Doctor) checkShellStartup(ctx context.Context) []Finding {
+	total, err := timeShellStartup(ctx)
+	if err != nil {
+		return []Finding{{Name: "zsh startup", Status: StatusWarning, Message: fmt.Sprintf("unable to time: %v", err)}}
+	}
+
+	budget := d.policies.ShellStartupBudget()
+	var snippets []snippet
+	if total > budget {
+		// Best effort: without a trace the total is still reported
+		if trace, err := traceShellStartup(ctx); err == nil {
+			snippets = rcSnippets(trace, rcPath(), d.managedShellLines())
+		}
+	}
+	return shellFindings(total, budget, snippets)
+}
+
+// shellFindings builds the shell startup findings
+// Params: total - median startup, budget - allowed startup, snippets - per-line costs (may be empty)
+// Returns: OK finding within budget; otherwise a warning for the total and one per slow line (slowest first)
+func shellFindings(total, budget time.Duration, snippets []snippet) []Finding {
+	summary := Finding{Name: "zsh startup", Status: StatusOK, Message: fmt.Sprintf("%s, budget %s", round(total), budget)}
+	if total <= budget {
+		return []Finding{summary}
+	}
+
+	var managed time.Duration
+	for _, s := range snippets {
+		if s.managed {
+			managed += s.cost
+		}
+	}
+	summary.Status = StatusWarning
+	summary.Message = fmt.Sprintf("takes %s, over the %s budget", round(total), budget)
+	switch {
+	case managed > 0 && total-managed <= budget:
+		summary.Message += fmt.Sprintf("; lines added by devsetup take %s and push it past", round(managed))
+	case managed > 0:
+		summary.Message += fmt.Sprintf("; lines added by devsetup take %s of it", round(managed))
+	}
+	summary.Guidance = "Lazy-load or remove the slow lines below; re-check with: time zsh -i -c exit"
+	findings := []Finding{summary}
+
+	sort.SliceStable(snippets, func(i, j int) bool { return snippets[i].cost > snippets[j].cost })
+	for i, s := range snippets {
+		if i == slowSnippets || s.cost < minSnippetCost {
+			break
+		}
+		f := Finding{
+			Name:    fmt.Sprintf("~/.zshrc:%d", s.line),
+			Status:  StatusWarning,
+			Message: fmt.Sprintf("%s: %s", round(s.cost), s.text),
+		}
+		if s.managed {
+			f.Message += " (added by devsetup)"
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// timeShellStartup returns the median wall time of `zsh -i -c exit`
+func timeShellStartup(ctx context.Context) (time.Duration, error) {
+	var runs []time.Duration
+	for range shellStartupRuns {
+		start := time.Now()
+		if err := exec.CommandContext(ctx, "zsh", "-i", "-c", "exit").Run(); err != nil {
+			return 0, err
+		}
+		runs = append(runs, time.Since(start))
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i] < runs[j] })
+	return runs[len(runs)/2], nil
+}
+
+// traceShellStartup runs one interactive startup with a timestamped xtrace of the rc file
+// What: A temporary ZDOTDIR whose .zshenv and .zshrc source the real ones, the latter with xtrace on
+// Returns: The xtrace output (stderr)
+func traceShellStartup(ctx context.Context) (string, error) {
+	dir, err := os.MkdirTemp("", "devsetup-zsh-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	realDir := filepath.Dir(rcPath())
+	zshenv := fmt.Sprintf("ZDOTDIR=%q\n[[ -f $ZDOTDIR/.zshenv ]] && source $ZDOTDIR/.zshenv\nZDOTDIR=%q\n", realDir, dir)
+	zshrc := fmt.Sprintf("ZDOTDIR=%q\nPS4=%q\nsetopt xtrace\nsource %q\nunsetopt xtrace\n", realDir, tracePS4, rcPath())
+	if err := os.WriteFile(filepath.Join(dir, ".zshenv"), []byte(zshenv), 0600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".zshrc"), []byte(zshrc), 0600); err != nil {
+		return "", err
+	}
+
+	var stderr strings.Builder
+	cmd := exec.CommandContext(ctx, "zsh", "-i", "-c", "exit")
+	cmd.Env = append(os.Environ(), "ZDOTDIR="+dir)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return stderr.String(), nil
+}
+
+// rcSnippets attributes traced startup time to the rc file's lines
+// What: Each traced rc line costs the time until the next traced rc line (or the last trace line); repeated lines add up
+// Why: A line that sources a file is charged for everything in it, which is the cost of keeping that line
+// Params: trace - xtrace output with tracePS4 prefixes, rc - rc file path, managed - trimmed lines devsetup adds
+// Returns: Snippets in line order, text read from the rc file
+func rcSnippets(trace, rc string, managed map[string]bool) []snippet {
+	type event struct {
+		at   time.Time
+		rc   bool
+		line int
+	}
+	var events []event
+	for _, raw := range strings.Split(trace, "\n") {
+		m := traceLine.FindStringSubmatch(raw)
+		if m == nil {
+			continue
+		}
+		at, err := parseEpoch(m[1])
+		if err != nil {
+			continue
+		}
+		line, _ := strconv.Atoi(m[3])
+		events = append(events, event{at: at, rc: m[2] == rc, line: line})
+	}
+
+	costs := make(map[int]time.Duration)
+	for i, e := range events {
+		if !e.rc {
+			continue
+		}
+		end := events[len(events)-1].at
+		for _, next := range events[i+1:] {
+			if next.rc {
+				end = next.at
+				break
+			}
+		}
+		costs[e.line] += end.Sub(e.at)
+	}
+
+	lines, inBlock := rcLines(rc)
+	var snippets []snippet
+	for line, cost := range costs {
+		text := ""
+		if line > 0 && line <= len(lines) {
+			text = strings.TrimSpace(lines[line-1])
+		}
+		snippets = append(snippets, snippet{
+			line:    line,
+			text:    text,
+			cost:    cost,
+			managed: managed[text] || (line > 0 && line <= len(inBlock) && inBlock[line-1]),
+		})
+	}
+	sort.Slice(snippets, func(i, j int) bool { return snippets[i].line < snippets[j].line })
+	return snippets
+}
+
+// rcLines reads the rc file
+// Returns: Its lines, and per line whether it is inside the devsetup managed block
+func rcLines(rc string) ([]string, []bool) {
+	f, err := os.Open(rc)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	var lines []string
+	var inBlock []bool
+	block := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch strings.TrimSpace(text) {
+		case pathmgr.BlockStart:
+			block = true
+		case pathmgr.BlockEnd:
+			block = false
+		}
+		lines = append(lines, text)
+		inBlock = append(inBlock, block)
+	}
+	return lines, inBlock
+}
+
+// managedShellLines returns the .zshrc lines setup.yaml adds (zshrc_lines), trimmed
+func (d *Doctor) managedShellLines() map[string]bool {
+	managed := make(map[string]bool)
+	if d.setup == nil {
+		return managed
+	}
+	for _, task := range d.setup.SetupTasks {
+		for _, l := range task.ZshrcLines {
+			managed[strings.TrimSpace(l.Content)] = true
+		}
+	}
+	return managed
+}
+
+// rcPath returns the interactive zsh rc file
+// Returns: $ZDOTDIR/.zshrc when ZDOTDIR is set, else ~/.zshrc
+func rcPath() string {
+	dir := os.Getenv("ZDOTDIR")
+	if dir == "" {
+		dir, _ = os.UserHomeDir()
+	}
+	return filepath.Join(dir, ".zshrc")
+}
+
+// parseEpoch parses "seconds.fraction" since the epoch
+func parseEpoch(s string) (time.Time, error) {
+	secs, frac, _ := strings.Cut(s, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	frac = (frac + "000000000")[:9]
+	nsec, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// round shortens a duration for display
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
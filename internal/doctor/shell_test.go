@@ -0,0 +1,80 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/pathmgr"
+)
+
+func TestRcSnippets(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".zshrc")
+	content := strings.Join([]string{
+		"export EDITOR=vim",
+		"source ~/.zsh/zsh-autosuggestions/zsh-autosuggestions.zsh",
+		pathmgr.BlockStart,
+		"source ~/.config/devsetup/path.zsh",
+		pathmgr.BlockEnd,
+		`eval "$(slow-tool init zsh)"`,
+	}, "\n")
+	if err := os.WriteFile(rc, []byte(content+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	trace := strings.Join([]string{
+		"+1700000000.000000|" + rc + "|1> export EDITOR=vim",
+		"+1700000000.001000|" + rc + "|2> source /Users/dev/.zsh/zsh-autosuggestions/zsh-autosuggestions.zsh",
+		"++1700000000.002000|/Users/dev/.zsh/zsh-autosuggestions/zsh-autosuggestions.zsh|10> autoload -Uz add-zsh-hook",
+		"+1700000000.081000|" + rc + "|4> source /Users/dev/.config/devsetup/path.zsh",
+		"+1700000000.091000|/Users/dev/.config/devsetup/path.zsh|1> export PATH=/opt/homebrew/bin:$PATH",
+		"+1700000000.101000|" + rc + "|6> slow-tool init zsh",
+		"continuation of a multi-line command",
+		"+1700000000.401000|/tmp/devsetup-zsh-1/.zshrc|4> unsetopt xtrace",
+	}, "\n")
+
+	managed := map[string]bool{"source ~/.zsh/zsh-autosuggestions/zsh-autosuggestions.zsh": true}
+	snippets := rcSnippets(trace, rc, managed)
+	want := []snippet{
+		{line: 1, cost: time.Millisecond},
+		{line: 2, cost: 80 * time.Millisecond, managed: true},
+		{line: 4, cost: 20 * time.Millisecond, managed: true},
+		{line: 6, cost: 300 * time.Millisecond},
+	}
+	if len(snippets) != len(want) {
+		t.Fatalf("snippets = %+v, want %d", snippets, len(want))
+	}
+	for i, w := range want {
+		s := snippets[i]
+		if s.line != w.line || s.cost.Round(time.Millisecond) != w.cost || s.managed != w.managed {
+			t.Errorf("snippet %d = %+v, want line %d cost %s managed %t", i, s, w.line, w.cost, w.managed)
+		}
+	}
+	if snippets[3].text != `eval "$(slow-tool init zsh)"` {
+		t.Errorf("text = %q, want the rc line", snippets[3].text)
+	}
+}
+
+func TestShellFindings(t *testing.T) {
+	if f := shellFindings(200*time.Millisecond, 500*time.Millisecond, nil); len(f) != 1 || f[0].Status != StatusOK {
+		t.Errorf("within budget: %+v, want single ok finding", f)
+	}
+
+	snippets := []snippet{
+		{line: 1, text: "export EDITOR=vim", cost: time.Millisecond},
+		{line: 2, text: "source plugin.zsh", cost: 300 * time.Millisecond, managed: true},
+		{line: 6, text: "eval slow", cost: 100 * time.Millisecond},
+	}
+	f := shellFindings(700*time.Millisecond, 500*time.Millisecond, snippets)
+	if len(f) != 3 {
+		t.Fatalf("findings = %+v, want summary and two slow lines", f)
+	}
+	if f[0].Status != StatusWarning || !strings.Contains(f[0].Message, "push it past") {
+		t.Errorf("summary = %+v, want warning blaming devsetup's lines", f[0])
+	}
+	if f[1].Name != "~/.zshrc:2" || !strings.Contains(f[1].Message, "added by devsetup") || f[2].Name != "~/.zshrc:6" {
+		t.Errorf("slow lines = %+v, want line 2 (managed) then line 6", f[1:])
+	}
+}
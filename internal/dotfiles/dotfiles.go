@@ -0,0 +1,305 @@
+// File: internal/dotfiles/dotfiles.go
+// Purpose: Previewed, backed-up edits of shell dotfiles (.zshrc, .zprofile) and their restore
+// Problem: Setup appended to .zshrc without showing what it would add, and a bad edit could only be undone by hand
+// Role: Renders a unified diff of a proposed change, keeps a timestamped copy of every file before it is modified,
+//       and restores those copies (devsetup dotfiles restore)
+// Usage: fmt.Print(dotfiles.Diff(path, old, new)); err := dotfiles.Write(path, new); backups, _ := dotfiles.Backups(".zshrc")
+// Design choices: Backups live under the state dir (not beside the dotfile) so they never clutter $HOME or get
+//                 sourced by a glob; restoring backs up the current file first, so a restore can be undone too;
+//                 the diff is a plain line LCS, which is plenty for rc files of a few hundred lines
+// Assumptions: Dotfiles are small text files; one devsetup process edits them at a time
+
+package dotfiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// stampFormat names backups: <file>.<stamp>; milliseconds keep a restore right after a run from replacing its backup
+const stampFormat = "20060102-150405,000"
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// Backup is one saved copy of a dotfile
+type Backup struct {
+	// Name is the dotfile's path relative to the home directory (e.g. ".zshrc", ".ssh/config")
+	Name string
+
+	// Path is the backup file
+	Path string
+
+	// Time the backup was taken
+	Time time.Time
+}
+
+// BackupDir returns where dotfile backups are kept
+// Returns: ~/.local/share/devsetup/backups/dotfiles
+func BackupDir() string {
+	return filepath.Join(config.GetStateDir(), "backups", "dotfiles")
+}
+
+// Write replaces a dotfile after backing up its current content
+// Params: path - dotfile, content - new content
+// Returns: Error if the backup or the write fails (the dotfile is untouched when the backup fails)
+// Edge cases: A dotfile that doesn't exist yet is created without a backup (0644, or 0600 under ~/.ssh); an
+// existing file keeps its permissions
+func Write(path, content string) error {
+	if _, err := backup(path, time.Now()); err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if filepath.Base(filepath.Dir(path)) == ".ssh" {
+		mode = 0600
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Name returns the name backups of a dotfile are listed under (see Backup.Name)
+// Example: Name("/Users/dev/.ssh/config") → ".ssh/config"; Name("~/.zshrc") → ".zshrc"; Name(".zshrc") → ".zshrc"
+func Name(path string) string {
+	path = strings.TrimPrefix(path, "~/")
+	if home, err := os.UserHomeDir(); err == nil && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+		return filepath.Base(path)
+	}
+	return filepath.Clean(path)
+}
+
+// Backups lists the saved copies of a dotfile, newest first
+// Params: name - dotfile name relative to home (e.g. ".zshrc", see Name), "" for every dotfile
+// Returns: Backups, error if the backup directory can't be read
+func Backups(name string) ([]Backup, error) {
+	entries, err := os.ReadDir(BackupDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, e := range entries {
+		i := strings.LastIndex(e.Name(), ".")
+		if i <= 0 {
+			continue
+		}
+		t, err := time.ParseInLocation(stampFormat, e.Name()[i+1:], time.Local)
+		file := strings.ReplaceAll(e.Name()[:i], "%", "/")
+		if err != nil || (name != "" && file != name) {
+			continue
+		}
+		backups = append(backups, Backup{Name: file, Path: filepath.Join(BackupDir(), e.Name()), Time: t})
+	}
+	sort.SliceStable(backups, func(i, j int) bool { return backups[i].Time.After(backups[j].Time) })
+	return backups, nil
+}
+
+// Restore puts a backup back in place in the home directory
+// What: Backs up the current dotfile, then overwrites it with the backup's content
+// Params: b - backup to restore
+// Returns: Path restored, error if reading, backing up, or writing fails
+func Restore(b Backup) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+	path := filepath.Join(home, b.Name)
+	return path, Write(path, string(data))
+}
+
+// backup copies a dotfile into BackupDir as <name>.<stamp>, with the "/" of a nested name (.ssh/config) as "%"
+// Returns: Backup path ("" if the file doesn't exist), error if the copy fails
+func backup(path string, now time.Time) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := os.MkdirAll(BackupDir(), 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	dst := filepath.Join(BackupDir(), strings.ReplaceAll(Name(path), "/", "%")+"."+now.Format(stampFormat))
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return dst, nil
+}
+
+// ReplaceBlock returns content with a named devsetup block holding lines
+// What: Replaces the lines between "# >>> devsetup <name> >>>" and "# <<< devsetup <name> <<<" in place, or appends
+// the block after a blank line; the rest of the file is kept as is
+// Params: content - current file, name - block name, lines - block body (empty removes the block)
+// Returns: New content; equal to content when the block is already current
+// Example: ReplaceBlock("a\n", "x", []string{"b"}) → "a\n\n# >>> devsetup x >>>\nb\n# <<< devsetup x <<<\n"
+func ReplaceBlock(content, name string, lines []string) string {
+	begin, end := "# >>> devsetup "+name+" >>>", "# <<< devsetup "+name+" <<<"
+	var block []string
+	if len(lines) > 0 {
+		block = append(append([]string{begin}, lines...), end)
+	}
+
+	var out []string
+	found, inBlock := false, false
+	for _, line := range splitLines(content) {
+		switch {
+		case line == begin:
+			if !found {
+				out = append(out, block...)
+			}
+			found, inBlock = true, true
+		case line == end && inBlock:
+			inBlock = false
+		case !inBlock:
+			out = append(out, line)
+		}
+	}
+	switch {
+	case !found && len(block) > 0:
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, block...)
+	case found && len(block) == 0:
+		for len(out) > 0 && out[len(out)-1] == "" {
+			out = out[:len(out)-1]
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// Diff renders a unified diff between two versions of a file
+// Params: path - file name for the headers, old - current content, new - proposed content
+// Returns: Diff text ("" when the contents are equal)
+// Example: Diff("~/.zshrc", "a\n", "a\nb\n") → "--- ~/.zshrc\n+++ ~/.zshrc (proposed)\n@@ -1 +1,2 @@\n a\n+b\n"
+func Diff(path, old, new string) string {
+	if old == new {
+		return ""
+	}
+	a, b := splitLines(old), splitLines(new)
+	ops := lineOps(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s (proposed)\n", path, path)
+	for start := 0; start < len(ops); {
+		// Find the next change and the extent of its hunk (changes closer than 2*context merge)
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			} else if i-last > 2*diffContext {
+				break
+			}
+		}
+		from, to := max(first-diffContext, start), min(last+diffContext+1, len(ops))
+
+		hunk := ops[from:to]
+		aStart, bStart := ops[from].a, ops[from].b
+		aLen, bLen := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		for _, op := range hunk {
+			out.WriteString(string(op.kind) + op.text + "\n")
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// lineOp is one line of an edit script: ' ' kept, '-' removed, '+' added; a/b are 0-based positions before it
+type lineOp struct {
+	kind byte
+	text string
+	a, b int
+}
+
+// lineOps computes the edit script from a to b via longest common subsequence
+func lineOps(a, b []string) []lineOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []lineOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, lineOp{' ', a[i], i, j})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			// Removals first, like diff -u
+			ops = append(ops, lineOp{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, lineOp{'+', b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+// hunkRange formats a unified diff range from a 0-based start
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// splitLines splits content into lines without their newlines
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
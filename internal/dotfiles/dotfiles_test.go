@@ -0,0 +1,87 @@
+package dotfiles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nexport PATH=x\n"
+	want := `--- .zshrc
++++ .zshrc (proposed)
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++export PATH=x
+`
+	if got := Diff(".zshrc", old, new); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Diff(".zshrc", "", "x\n"); !strings.Contains(got, "@@ -0,0 +1 @@\n+x\n") {
+		t.Errorf("Diff() of a new file =\n%s", got)
+	}
+	if Diff(".zshrc", old, old) != "" {
+		t.Error("Diff() of equal contents should be empty")
+	}
+}
+
+func TestWriteAndRestore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".zshrc")
+
+	// A new file has nothing to back up
+	if err := Write(path, "one\n"); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := Backups(".zshrc"); len(backups) != 0 {
+		t.Fatalf("Backups() = %v, want none for a new file", backups)
+	}
+
+	if err := Write(path, "two\n"); err != nil {
+		t.Fatal(err)
+	}
+	backups, err := Backups(".zshrc")
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Backups() = %v, %v", backups, err)
+	}
+	if _, err := Restore(backups[0]); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "one\n" {
+		t.Errorf("restored content = %q, want one", data)
+	}
+
+	// Files in subdirectories restore to their own path and keep their permissions
+	ssh := filepath.Join(home, ".ssh", "config")
+	if err := os.MkdirAll(filepath.Dir(ssh), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ssh, "Host a\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ssh, "Host b\n"); err != nil {
+		t.Fatal(err)
+	}
+	backups, err = Backups(Name("~/.ssh/config"))
+	if err != nil || len(backups) != 1 || backups[0].Name != ".ssh/config" {
+		t.Fatalf("Backups(.ssh/config) = %v, %v", backups, err)
+	}
+	if restored, err := Restore(backups[0]); err != nil || restored != ssh {
+		t.Fatalf("Restore = %s, %v; want %s", restored, err, ssh)
+	}
+	if info, _ := os.Stat(ssh); info.Mode().Perm() != 0600 {
+		t.Errorf("~/.ssh/config mode = %v, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,328 @@
+// File: internal/download/download.go
+// Purpose: Shared HTTP downloader with resume, retry, and progress reporting
+// Problem: Large downloads restarted from zero after a network blip
+// Role: Single download path for the updater and download-type install tasks
+// Usage: d := download.New(client); err := d.ToFile(ctx, url, path)
+// Design choices: HTTP Range requests to resume; partial data kept in <path>.part across runs, with the remote file's
+//                 validator (strong ETag, else Last-Modified) in <path>.part.validator and sent as If-Range so a file
+//                 that changed between runs restarts instead of splicing old and new bytes; exponential backoff on
+//                 network errors and transient statuses; throttled progress callback
+// Assumptions: Servers that ignore Range (or find If-Range stale) answer 200 with the full body (handled by
+//              restarting); a partial download without a validator is never resumed
+
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// progressInterval throttles progress callbacks
+const progressInterval = 100 * time.Millisecond
+
+// ProgressFunc receives bytes downloaded so far and the total size (-1 if unknown)
+type ProgressFunc func(done, total int64)
+
+// Downloader fetches URLs with resume and retry
+// What: HTTP client plus retry policy and optional progress reporting
+// Why: Updater and install tasks share one robust download implementation
+type Downloader struct {
+	// Client performs requests
+	Client *http.Client
+
+	// Retries is how many times a failed transfer is retried (resuming where it stopped)
+	Retries int
+
+	// Backoff is the delay before the first retry; doubled on each further retry
+	Backoff time.Duration
+
+	// UserAgent is sent with every request when set
+	UserAgent string
+
+	// Progress is called as data arrives (optional)
+	Progress ProgressFunc
+}
+
+// errNotResumable means the server ignored a Range request
+var errNotResumable = errors.New("server does not support resume")
+
+// New creates a downloader with default retry policy
+// Params: client - HTTP client (nil uses a client without an overall timeout)
+// Returns: Downloader with 3 retries and 2s initial backoff
+// Example: d := download.New(nil)
+func New(client *http.Client) *Downloader {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Downloader{
+		Client:  client,
+		Retries: 3,
+		Backoff: 2 * time.Second,
+	}
+}
+
+// ToFile downloads url to path, resuming a previous partial download
+// What: Appends to <path>.part using Range/If-Range requests, renames to path when complete
+// Why: An interrupted run (or network blip) continues from the last byte instead of zero
+// Params: ctx - cancellation, url - source URL, path - destination file
+// Returns: Error if the download fails after all retries
+// Example: err := d.ToFile(ctx, "https://example.com/tool.tar.gz", "/tmp/tool.tar.gz")
+// Edge cases: A .part without a saved validator, one whose remote file changed (200 or a different validator), or
+// one larger than the remote file (416) is discarded and restarted
+func (d *Downloader) ToFile(ctx context.Context, url, path string) error {
+	partPath := path + ".part"
+	file, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", partPath, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek %s: %w", partPath, err)
+	}
+
+	restart := func() error {
+		if err := file.Truncate(0); err != nil {
+			return err
+		}
+		_, err := file.Seek(0, io.SeekStart)
+		return err
+	}
+
+	v := loadValidator(partPath + ".validator")
+	if offset > 0 && v.value == "" {
+		// Nothing ties these bytes to the remote file; resuming could splice two versions together
+		if err := restart(); err != nil {
+			return fmt.Errorf("failed to restart download: %w", err)
+		}
+		offset = 0
+	}
+
+	err = d.fetch(ctx, url, file, offset, restart, v)
+	if err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", partPath, err)
+	}
+	_ = os.Remove(v.path)
+	return os.Rename(partPath, path)
+}
+
+// Copy downloads url into dst
+// What: Streams the body to dst, resuming with Range on retry within this call
+// Why: Callers that stream (e.g. into a temp file they own) still get retry and progress
+// Params: ctx - cancellation, dst - destination writer, url - source URL
+// Returns: Error if the download fails after all retries
+func (d *Downloader) Copy(ctx context.Context, dst io.Writer, url string) error {
+	return d.fetch(ctx, url, dst, 0, nil, &validator{})
+}
+
+// validator identifies the version of the remote file a partial download holds
+type validator struct {
+	// value is a strong ETag or a Last-Modified date ("" = unknown, so the download can't be resumed)
+	value string
+
+	// path persists value next to the .part file ("" = kept in memory for one call)
+	path string
+}
+
+// loadValidator reads a validator saved by an earlier run ("" value if there is none)
+func loadValidator(path string) *validator {
+	data, _ := os.ReadFile(path)
+	return &validator{value: strings.TrimSpace(string(data)), path: path}
+}
+
+// set records the validator of a response that starts the download over
+// Edge cases: A response without one clears the saved validator, so the next run restarts instead of resuming
+func (v *validator) set(value string) error {
+	v.value = value
+	if v.path == "" {
+		return nil
+	}
+	if value == "" {
+		if err := os.Remove(v.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(v.path, []byte(value+"\n"), 0644)
+}
+
+// responseValidator returns the validator If-Range can use for a response
+// Returns: The ETag unless it is weak (If-Range needs a strong one), else Last-Modified, else ""
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// fetch runs the transfer loop with retries
+// What: Each retry resumes at the bytes already written; restart resets dst when the server can't resume
+// Params: ctx - cancellation, url - source URL, dst - writer positioned at offset, offset - bytes already present, restart - resets dst (nil if it can't be reset),
+// v - validator of the bytes already present
+// Returns: Error from the last attempt
+func (d *Downloader) fetch(ctx context.Context, url string, dst io.Writer, offset int64, restart func() error, v *validator) error {
+	backoff := d.Backoff
+	var lastErr error
+
+	for attempt := 0; attempt <= d.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		n, err := d.attempt(ctx, url, dst, offset, v)
+		offset += n
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if errors.Is(err, errNotResumable) {
+			if restart == nil {
+				return err
+			}
+			if err := restart(); err != nil {
+				return fmt.Errorf("failed to restart download: %w", err)
+			}
+			offset = 0
+			continue
+		}
+
+		if ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("download failed after %d attempts: %w", d.Retries+1, lastErr)
+}
+
+// statusError is a non-success HTTP status
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("download failed with status %d", e.code)
+}
+
+// isRetryable reports whether a failed attempt is worth retrying
+// Returns: true for network errors and transient statuses (408, 429, 502, 503, 504)
+func isRetryable(err error) bool {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return true
+	}
+	switch se.code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// attempt performs one request starting at offset
+// Params: ctx - cancellation, url - source URL, dst - destination, offset - resume position, v - validator of the
+// bytes before offset (updated when the response starts the file over)
+// Returns: Bytes written during this attempt and error if the transfer didn't complete
+// Edge cases: Resuming without a validator, or a 206 for a different validator, returns errNotResumable
+func (d *Downloader) attempt(ctx context.Context, url string, dst io.Writer, offset int64, v *validator) (int64, error) {
+	if offset > 0 && v.value == "" {
+		return 0, errNotResumable
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if d.UserAgent != "" {
+		req.Header.Set("User-Agent", d.UserAgent)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		req.Header.Set("If-Range", v.value)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	total := int64(-1)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		if responseValidator(resp) != v.value {
+			// The remote file changed and the server ignored If-Range
+			return 0, errNotResumable
+		}
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+	case resp.StatusCode == http.StatusOK:
+		if offset > 0 {
+			// Range unsupported, or If-Range found the file changed
+			return 0, errNotResumable
+		}
+		if err := v.set(responseValidator(resp)); err != nil {
+			return 0, fmt.Errorf("failed to save download validator: %w", err)
+		}
+		total = resp.ContentLength
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// Partial file is stale or already complete; start over to be safe
+		return 0, errNotResumable
+	default:
+		return 0, &statusError{code: resp.StatusCode}
+	}
+
+	reader := io.Reader(resp.Body)
+	if d.Progress != nil {
+		reader = &progressReader{reader: resp.Body, done: offset, total: total, report: d.Progress}
+	}
+
+	n, err := io.Copy(dst, reader)
+	if err != nil {
+		return n, err
+	}
+	if total >= 0 && offset+n < total {
+		return n, io.ErrUnexpectedEOF
+	}
+	if d.Progress != nil {
+		d.Progress(offset+n, total)
+	}
+	return n, nil
+}
+
+// progressReader reports progress while reading, throttled to progressInterval
+type progressReader struct {
+	reader io.Reader
+	done   int64
+	total  int64
+	report ProgressFunc
+	last   time.Time
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.done += int64(n)
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.report(p.done, p.total)
+	}
+	return n, err
+}
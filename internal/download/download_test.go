@@ -0,0 +1,122 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToFileResumesPartialDownload(t *testing.T) {
+	content := strings.Repeat("0123456789", 1000)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path+".part", []byte(content[:4000]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".part.validator", []byte(`"v1"`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := New(server.Client()).ToFile(context.Background(), server.URL, path); err != nil {
+		t.Fatalf("ToFile: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Errorf("content mismatch: got %d bytes, want %d", len(got), len(content))
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=4000-" {
+		t.Errorf("ranges = %q, want [bytes=4000-]", ranges)
+	}
+	for _, leftover := range []string{".part", ".part.validator"} {
+		if _, err := os.Stat(path + leftover); !os.IsNotExist(err) {
+			t.Errorf("expected %s file to be removed", leftover)
+		}
+	}
+}
+
+func TestToFileRestartsWhenRemoteChanged(t *testing.T) {
+	old, content := strings.Repeat("a", 4000), strings.Repeat("0123456789", 1000)
+	tests := []struct {
+		name      string
+		validator string // saved next to the .part ("" = none)
+		ranges    []string
+	}{
+		// If-Range names the old version: the server answers 200 with the new file
+		{"changed", `"v1"`, []string{"bytes=4000-", ""}},
+		// Nothing ties the partial bytes to a version: never resumed
+		{"no validator", "", []string{""}},
+	}
+	for _, tt := range tests {
+		var ranges []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ranges = append(ranges, r.Header.Get("Range"))
+			w.Header().Set("ETag", `"v2"`)
+			http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(content))
+		}))
+
+		path := filepath.Join(t.TempDir(), "file")
+		if err := os.WriteFile(path+".part", []byte(old), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if tt.validator != "" {
+			if err := os.WriteFile(path+".part.validator", []byte(tt.validator), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		d := New(server.Client())
+		d.Backoff = time.Millisecond
+		err := d.ToFile(context.Background(), server.URL, path)
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: ToFile: %v", tt.name, err)
+		}
+		if got, _ := os.ReadFile(path); string(got) != content {
+			t.Errorf("%s: got %d bytes starting %q, want the new file", tt.name, len(got), got[:10])
+		}
+		if strings.Join(ranges, "|") != strings.Join(tt.ranges, "|") {
+			t.Errorf("%s: ranges = %q, want %q", tt.name, ranges, tt.ranges)
+		}
+	}
+}
+
+func TestCopyRetriesTransientStatus(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	d := New(server.Client())
+	d.Backoff = time.Millisecond
+
+	var buf bytes.Buffer
+	if err := d.Copy(context.Background(), &buf, server.URL); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if buf.String() != "ok" || calls != 2 {
+		t.Errorf("got %q after %d calls, want \"ok\" after 2", buf.String(), calls)
+	}
+}
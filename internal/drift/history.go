@@ -0,0 +1,379 @@
+// File: internal/drift/history.go
+// Purpose: Time series of verify results per tool, and the drift episodes and trends read from it
+// Problem: verify --deep only said what had drifted right now; the platform team had no record of when tools
+//          drifted, for how long, or whether drift followed tools.yaml updates, so there was no evidence that
+//          pinning works
+// Role: Record appends one sample per tool for every verify run; Episodes turns the samples into drift episodes
+//       (start, end, detail) and ties each to the last generation that changed the tool's declaration
+// Usage: drift.Record(drift.FromResult(result, toolsCfg, time.Now(), gen)); runs, _ := drift.Load(since);
+//        report := drift.NewReport(runs, gens, nil, since, time.Now())
+// Design choices: JSON lines under the state directory (one verify run per line), rewritten atomically when runs
+//                 older than Retention are dropped; only deep runs decide drift (a standard run proves the tool
+//                 is installed, not that it is unchanged), so they neither start nor end an episode; generations
+//                 stand in for lock updates, since each records the resolved tools.yaml an install converged to
+// Assumptions: Verify runs are occasional (a few a day at most), so reading the whole file per run is cheap
+
+package drift
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/generations"
+	"github.com/rkinnovate/dev-setup/internal/verify"
+)
+
+// Retention is how long verify runs are kept
+const Retention = 365 * 24 * time.Hour
+
+// Tool statuses in a Sample
+const (
+	// StatusOK is a deep run finding the tool installed and unchanged
+	StatusOK = "ok"
+	// StatusInstalled is a quick or standard run finding the tool installed (drift not checked)
+	StatusInstalled = "installed"
+	// StatusDrifted is a deep run finding the version or binary changed since install
+	StatusDrifted = "drifted"
+	// StatusMissing is the tool's check failing
+	StatusMissing = "missing"
+	// StatusExcepted is the tool passing only because of a per-user exception (override or skip_verify)
+	StatusExcepted = "excepted"
+)
+
+// Run is one verify run
+type Run struct {
+	// Time the run finished
+	Time time.Time `json:"time"`
+
+	// Mode is the verify mode (quick, standard, deep)
+	Mode string `json:"mode"`
+
+	// Generation is the latest recorded generation at the time (0 = none)
+	Generation int `json:"generation,omitempty"`
+
+	// Tools maps tool name to what the run found
+	Tools map[string]Sample `json:"tools"`
+}
+
+// Sample is one tool's status in a run
+type Sample struct {
+	Status string `json:"status"`
+
+	// Detail is the failing checks' messages (e.g. `recorded "20.1.0", found "20.3.0"`)
+	Detail string `json:"detail,omitempty"`
+}
+
+// Episode is one stretch of drift of a tool
+type Episode struct {
+	Tool string `json:"tool"`
+
+	// Start is the first deep run that found the drift
+	Start time.Time `json:"start"`
+
+	// End is the first deep run that found the tool back in line, excepted, or gone (zero = ongoing)
+	End time.Time `json:"end,omitempty"`
+
+	// Removed is whether the episode ended because the tool was no longer in tools.yaml
+	Removed bool `json:"removed,omitempty"`
+
+	// LastSeen is the last run that found the drift
+	LastSeen time.Time `json:"last_seen"`
+
+	// Detail is what the first run found
+	Detail string `json:"detail"`
+
+	// After is the last update of the tool's declaration before the drift started (nil = none recorded)
+	After *LockUpdate `json:"after,omitempty"`
+}
+
+// Ongoing reports whether the tool was still drifted at the last run
+func (e Episode) Ongoing() bool {
+	return e.End.IsZero()
+}
+
+// Duration returns how long the episode lasted (until now when ongoing)
+func (e Episode) Duration(now time.Time) time.Duration {
+	if e.Ongoing() {
+		return now.Sub(e.Start)
+	}
+	return e.End.Sub(e.Start)
+}
+
+// LockUpdate is a generation that changed a tool's tools.yaml declaration
+type LockUpdate struct {
+	Generation int       `json:"generation"`
+	Time       time.Time `json:"time"`
+}
+
+// Path returns the history file
+// Returns: <state dir>/drift/history.jsonl
+func Path() string {
+	return filepath.Join(config.GetStateDir(), "drift", "history.jsonl")
+}
+
+// FromResult turns a verify result into a Run
+// What: Each tool's main check gives installed/missing; in deep mode its version and checksum checks turn an
+// installed tool into ok or drifted
+// Params: result - verify result, tools - tools config (deprecated tools are left out), now - run time, gen -
+// latest generation number
+// Returns: Run with one sample per non-deprecated tool that was checked
+func FromResult(result *verify.VerifyResult, tools *config.ToolsConfig, now time.Time, gen int) Run {
+	run := Run{Time: now, Mode: string(result.Mode), Generation: gen, Tools: make(map[string]Sample)}
+	deprecated := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		deprecated[tool.Name] = tool.Deprecated
+	}
+
+	for _, c := range result.Checks {
+		if c.Category != verify.CategoryTool || c.Name != c.Subject || deprecated[c.Subject] {
+			continue
+		}
+		switch {
+		case c.Excepted:
+			run.Tools[c.Subject] = Sample{Status: StatusExcepted, Detail: c.Message}
+		case !c.Passed:
+			run.Tools[c.Subject] = Sample{Status: StatusMissing, Detail: c.Message}
+		case result.Mode == verify.ModeDeep:
+			run.Tools[c.Subject] = Sample{Status: StatusOK}
+		default:
+			run.Tools[c.Subject] = Sample{Status: StatusInstalled}
+		}
+	}
+
+	for _, c := range result.Checks {
+		sample, ok := run.Tools[c.Subject]
+		if c.Category != verify.CategoryDeep || !ok || (sample.Status != StatusOK && sample.Status != StatusDrifted) {
+			continue
+		}
+		switch {
+		case c.Excepted:
+			run.Tools[c.Subject] = Sample{Status: StatusExcepted, Detail: c.Message}
+		case !c.Passed:
+			detail := strings.TrimPrefix(c.Name, c.Subject+" ") + ": " + c.Message
+			if sample.Status == StatusDrifted {
+				detail = sample.Detail + "; " + detail
+			}
+			run.Tools[c.Subject] = Sample{Status: StatusDrifted, Detail: detail}
+		}
+	}
+	return run
+}
+
+// Record appends a run to the history
+// What: Loads the history, appends the run, drops runs older than Retention, and replaces the file
+// Returns: Error if the history can't be read or written
+func Record(run Run) error {
+	runs, err := Load(time.Time{})
+	if err != nil {
+		return err
+	}
+	runs = append(runs, run)
+	cutoff := run.Time.Add(-Retention)
+	runs = slices.DeleteFunc(runs, func(r Run) bool { return r.Time.Before(cutoff) })
+
+	var b strings.Builder
+	for _, r := range runs {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(Path()), 0755); err != nil {
+		return fmt.Errorf("failed to create drift history directory: %w", err)
+	}
+	tmp := Path() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write drift history: %w", err)
+	}
+	return os.Rename(tmp, Path())
+}
+
+// Load reads the recorded runs
+// Params: since - earliest run to return (zero = all)
+// Returns: Runs in time order (none if nothing is recorded), error if the file can't be read
+// Edge cases: Unparsable lines (e.g. a hand-edited file) are skipped
+func Load(since time.Time) ([]Run, error) {
+	f, err := os.Open(Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drift history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var run Run
+		if json.Unmarshal(scanner.Bytes(), &run) != nil || run.Time.Before(since) {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read drift history: %w", err)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Time.Before(runs[j].Time) })
+	return runs, nil
+}
+
+// Episodes finds the drift episodes in a series of runs
+// What: An episode starts at a deep run finding a tool drifted and ends at the next deep run finding it ok,
+// excepted, or missing, or not listing it at all (removed from tools.yaml); quick and standard runs don't affect
+// episodes
+// Params: runs - Load result (time order), gens - generations in order (for After)
+// Returns: Episodes ordered by start, then tool
+func Episodes(runs []Run, gens []*generations.Generation) []Episode {
+	updates := lockUpdates(gens)
+	open := make(map[string]*Episode)
+	var episodes []Episode
+
+	for _, run := range runs {
+		if run.Mode != string(verify.ModeDeep) {
+			continue
+		}
+		for _, name := range sortedTools(run.Tools) {
+			sample := run.Tools[name]
+			e, drifting := open[name]
+			switch {
+			case sample.Status == StatusDrifted && drifting:
+				e.LastSeen = run.Time
+			case sample.Status == StatusDrifted:
+				open[name] = &Episode{Tool: name, Start: run.Time, LastSeen: run.Time, Detail: sample.Detail, After: lastUpdate(updates[name], run.Time)}
+			case drifting && sample.Status != StatusInstalled:
+				e.End = run.Time
+				episodes = append(episodes, *e)
+				delete(open, name)
+			}
+		}
+		for name, e := range open {
+			if _, listed := run.Tools[name]; !listed {
+				e.End, e.Removed = run.Time, true
+				episodes = append(episodes, *e)
+				delete(open, name)
+			}
+		}
+	}
+	for _, e := range open {
+		episodes = append(episodes, *e)
+	}
+	sort.SliceStable(episodes, func(i, j int) bool {
+		if !episodes[i].Start.Equal(episodes[j].Start) {
+			return episodes[i].Start.Before(episodes[j].Start)
+		}
+		return episodes[i].Tool < episodes[j].Tool
+	})
+	return episodes
+}
+
+// Trend counts episodes started in the last window and in the window before it
+// Example: Trend(episodes, now, 30*24*time.Hour) → 2, 5 (fewer episodes this month than last)
+func Trend(episodes []Episode, now time.Time, window time.Duration) (recent, previous int) {
+	for _, e := range episodes {
+		switch age := now.Sub(e.Start); {
+		case age < window:
+			recent++
+		case age < 2*window:
+			previous++
+		}
+	}
+	return recent, previous
+}
+
+// TrendWindow is the period Report compares with the one before it
+const TrendWindow = 30 * 24 * time.Hour
+
+// Report is the drift history over a period, as `devsetup drift history` shows it
+type Report struct {
+	Since    time.Time `json:"since"`
+	Runs     int       `json:"runs"`
+	DeepRuns int       `json:"deep_runs"`
+	Episodes []Episode `json:"episodes"`
+
+	// Recent and Previous count episodes started in the last TrendWindow and the one before it
+	Recent   int `json:"recent"`
+	Previous int `json:"previous"`
+}
+
+// NewReport builds the report of runs since a time
+// Params: runs - Load(since) result, gens - generations in order, tools - tools to report (empty = all), since -
+// start of the period, now - end of the period
+func NewReport(runs []Run, gens []*generations.Generation, tools []string, since, now time.Time) Report {
+	report := Report{Since: since, Runs: len(runs), Episodes: Episodes(runs, gens)}
+	for _, run := range runs {
+		if run.Mode == string(verify.ModeDeep) {
+			report.DeepRuns++
+		}
+	}
+	if report.Episodes == nil {
+		report.Episodes = []Episode{}
+	}
+	if len(tools) > 0 {
+		report.Episodes = slices.DeleteFunc(report.Episodes, func(e Episode) bool { return !slices.Contains(tools, e.Tool) })
+	}
+	report.Recent, report.Previous = Trend(report.Episodes, now, TrendWindow)
+	return report
+}
+
+// WriteJSON writes the report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// lockUpdates returns, per tool, the generations that added or changed its declaration (in order)
+// Edge cases: The first generation counts as an update of every tool it declares; unparsable ones are skipped
+func lockUpdates(gens []*generations.Generation) map[string][]LockUpdate {
+	updates := make(map[string][]LockUpdate)
+	empty := &generations.Generation{}
+	for i, gen := range gens {
+		prev := empty
+		if i > 0 {
+			prev = gens[i-1]
+		}
+		changes, err := generations.Diff(prev, gen)
+		if err != nil {
+			continue
+		}
+		for _, name := range append(changes.ToolsAdded, changes.ToolsChanged...) {
+			updates[name] = append(updates[name], LockUpdate{Generation: gen.Number, Time: gen.Time})
+		}
+	}
+	return updates
+}
+
+// lastUpdate returns the latest update at or before t
+func lastUpdate(updates []LockUpdate, t time.Time) *LockUpdate {
+	var last *LockUpdate
+	for i := range updates {
+		if updates[i].Time.After(t) {
+			break
+		}
+		last = &updates[i]
+	}
+	return last
+}
+
+// sortedTools returns a run's tool names in order, so episodes of one run start in a stable order
+func sortedTools(tools map[string]Sample) []string {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
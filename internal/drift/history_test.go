@@ -0,0 +1,109 @@
+package drift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/generations"
+	"github.com/rkinnovate/dev-setup/internal/verify"
+)
+
+func TestFromResult(t *testing.T) {
+	tools := &config.ToolsConfig{Tools: []config.Tool{{Name: "node"}, {Name: "git"}, {Name: "gh"}, {Name: "yarn", Deprecated: true}}}
+	result := &verify.VerifyResult{Mode: verify.ModeDeep, Checks: []verify.CheckResult{
+		{Name: "node", Subject: "node", Category: verify.CategoryTool, Passed: true},
+		{Name: "node service", Subject: "node", Category: verify.CategoryTool, Passed: false},
+		{Name: "git", Subject: "git", Category: verify.CategoryTool, Passed: true},
+		{Name: "gh", Subject: "gh", Category: verify.CategoryTool, Passed: false, Message: "not found"},
+		{Name: "yarn", Subject: "yarn", Category: verify.CategoryTool, Passed: true},
+		{Name: "node version", Subject: "node", Category: verify.CategoryDeep, Message: `recorded "20.1", found "20.3"`},
+		{Name: "node checksum", Subject: "node", Category: verify.CategoryDeep, Message: "binary changed since install"},
+		{Name: "git version", Subject: "git", Category: verify.CategoryDeep, Passed: true},
+		{Name: "starship [format].add_newline", Subject: "starship-config", Category: verify.CategoryDeep},
+	}}
+
+	run := FromResult(result, tools, time.Now(), 3)
+	want := map[string]Sample{
+		"node": {Status: StatusDrifted, Detail: `version: recorded "20.1", found "20.3"; checksum: binary changed since install`},
+		"git":  {Status: StatusOK},
+		"gh":   {Status: StatusMissing, Detail: "not found"},
+	}
+	if len(run.Tools) != len(want) {
+		t.Errorf("Tools = %v, want %v", run.Tools, want)
+	}
+	for name, sample := range want {
+		if run.Tools[name] != sample {
+			t.Errorf("%s = %+v, want %+v", name, run.Tools[name], sample)
+		}
+	}
+
+	result.Mode = verify.ModeStandard
+	result.Checks = result.Checks[:5]
+	if got := FromResult(result, tools, time.Now(), 3).Tools["node"]; got.Status != StatusInstalled {
+		t.Errorf("standard run node = %+v, want installed", got)
+	}
+}
+
+func TestEpisodes(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2026, 10, n, 9, 0, 0, 0, time.UTC) }
+	run := func(n int, mode verify.Mode, node string) Run {
+		return Run{Time: day(n), Mode: string(mode), Tools: map[string]Sample{"node": {Status: node, Detail: "version: " + node}, "git": {Status: StatusOK}}}
+	}
+	runs := []Run{
+		run(1, verify.ModeDeep, StatusOK),
+		run(3, verify.ModeDeep, StatusDrifted),
+		run(4, verify.ModeStandard, StatusInstalled), // doesn't end the episode
+		run(5, verify.ModeDeep, StatusDrifted),
+		run(6, verify.ModeDeep, StatusOK),
+		run(9, verify.ModeDeep, StatusDrifted),
+	}
+	gens := []*generations.Generation{
+		{Number: 1, Time: day(1).Add(-time.Hour), ToolsYAML: "tools:\n  - name: node\n    check: node -v\n  - name: git\n"},
+		{Number: 2, Time: day(2), ToolsYAML: "tools:\n  - name: node\n    check: node --version\n  - name: git\n"},
+		{Number: 3, Time: day(7), ToolsYAML: "tools:\n  - name: node\n    check: node --version\n  - name: git\n    check: git --version\n"},
+	}
+
+	episodes := Episodes(runs, gens)
+	if len(episodes) != 2 {
+		t.Fatalf("episodes = %+v, want 2", episodes)
+	}
+	first, second := episodes[0], episodes[1]
+	if !first.Start.Equal(day(3)) || !first.End.Equal(day(6)) || !first.LastSeen.Equal(day(5)) || first.Duration(day(10)) != 3*24*time.Hour {
+		t.Errorf("first episode = %+v", first)
+	}
+	if first.After == nil || first.After.Generation != 2 {
+		t.Errorf("first episode after %+v, want generation 2 (node's check changed)", first.After)
+	}
+	if !second.Ongoing() || second.After == nil || second.After.Generation != 2 {
+		t.Errorf("second episode = %+v, want ongoing after generation 2 (generation 3 only changed git)", second)
+	}
+
+	if recent, previous := Trend(episodes, day(10), 5*24*time.Hour); recent != 1 || previous != 1 {
+		t.Errorf("Trend = %d, %d; want 1, 1", recent, previous)
+	}
+
+	// A tool removed from tools.yaml drops out of later runs, which ends its episode
+	removed := Run{Time: day(11), Mode: string(verify.ModeDeep), Tools: map[string]Sample{"git": {Status: StatusOK}}}
+	episodes = Episodes(append(runs, removed), gens)
+	if last := episodes[len(episodes)-1]; last.Ongoing() || !last.Removed || !last.End.Equal(day(11)) {
+		t.Errorf("episode of a removed tool = %+v, want ended at day 11", last)
+	}
+}
+
+func TestRecordDropsOldRuns(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Now()
+	for _, at := range []time.Time{now.Add(-Retention - time.Hour), now.Add(-time.Hour), now} {
+		if err := Record(Run{Time: at, Mode: string(verify.ModeDeep), Tools: map[string]Sample{"git": {Status: StatusOK}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, err := Load(time.Time{})
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("Load = %d runs, %v; want 2 (the oldest dropped)", len(runs), err)
+	}
+	if recent, _ := Load(now.Add(-time.Minute)); len(recent) != 1 {
+		t.Errorf("Load(since) = %d runs, want 1", len(recent))
+	}
+}
@@ -0,0 +1,238 @@
+// File: internal/editor/editor.go
+// Purpose: Team default settings and keybindings for Zed and VS Code, merged into the user's own files
+// Problem: Every developer set format-on-save, rulers, and the team's shortcuts by hand (or not at all), and copying
+//          a shared settings.json over theirs threw away their personal settings
+// Role: Knows where each editor keeps its user settings and keybindings, merges managed keys into them, and reads
+//       keys back for verify
+// Usage: updated, err := editor.MergeSettings(current, managed); v, ok, err := editor.Lookup(content, "tab_size")
+// Design choices: Only managed keys change (objects merge key by key, so a managed "terminal.font_size" keeps the
+//                 user's other terminal settings); managed keybindings are appended unless an identical entry exists,
+//                 never removed; key order and indentation are kept, comments are not (the diff shows it, and the
+//                 previous file is backed up)
+// Assumptions: VS Code is the stable build (Code, not Code - Insiders); Zed uses ~/.config/zed
+
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// stampFormat names backups: <editor>-<file>.<stamp>
+const stampFormat = "20060102-150405,000"
+
+// Name returns the editor's display name
+// Example: Name("vscode") → "VS Code"
+func Name(editor string) string {
+	switch editor {
+	case config.EditorZed:
+		return "Zed"
+	case config.EditorVSCode:
+		return "VS Code"
+	}
+	return editor
+}
+
+// SettingsPath returns the editor's user settings file
+// Returns: ~/.config/zed/settings.json or ~/Library/Application Support/Code/User/settings.json
+func SettingsPath(editor string) string {
+	return filepath.Join(configDir(editor), "settings.json")
+}
+
+// KeybindingsPath returns the editor's user keybindings file
+// Returns: ~/.config/zed/keymap.json or ~/Library/Application Support/Code/User/keybindings.json
+func KeybindingsPath(editor string) string {
+	if editor == config.EditorZed {
+		return filepath.Join(configDir(editor), "keymap.json")
+	}
+	return filepath.Join(configDir(editor), "keybindings.json")
+}
+
+// configDir returns the editor's user configuration directory
+func configDir(editor string) string {
+	home, _ := os.UserHomeDir()
+	if editor == config.EditorZed {
+		return filepath.Join(home, ".config", "zed")
+	}
+	return filepath.Join(home, "Library", "Application Support", "Code", "User")
+}
+
+// MergeSettings merges managed settings into a settings file's content
+// What: Sets every managed key, merging nested objects key by key; other keys and their order are kept
+// Why: Team defaults apply without replacing the developer's own settings
+// Params: content - current file ("" or comments only for a new file), managed - settings from setup.yaml
+// Returns: New content (equal to content when nothing changes), error if content isn't a JSON object
+// Example: MergeSettings(`{"theme": "One Dark"}`, {"tab_size": 2}) → "{\n  \"theme\": \"One Dark\",\n  \"tab_size\": 2\n}\n"
+func MergeSettings(content string, managed map[string]any) (string, error) {
+	current, err := parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid settings: %w", err)
+	}
+	root, ok := current.(*object)
+	if current == nil {
+		root, ok = newObject(), true
+	}
+	if !ok {
+		return "", fmt.Errorf("invalid settings: top level is not an object")
+	}
+	want, err := fromYAML(managed)
+	if err != nil {
+		return "", fmt.Errorf("invalid managed settings: %w", err)
+	}
+
+	before := canonical(root)
+	mergeObject(root, want.(*object))
+	if canonical(root) == before && current != nil {
+		return content, nil
+	}
+	return encode(root, detectIndent(content)), nil
+}
+
+// mergeObject sets src's keys in dst; objects on both sides are merged recursively
+func mergeObject(dst, src *object) {
+	for _, key := range src.keys {
+		value := src.values[key]
+		if into, ok := dst.values[key].(*object); ok {
+			if from, ok := value.(*object); ok {
+				mergeObject(into, from)
+				continue
+			}
+		}
+		dst.set(key, value)
+	}
+}
+
+// MergeKeybindings appends managed keybindings to a keybindings file's content
+// What: Adds each managed entry unless an identical entry (same keys and values, any order) is already there
+// Why: The team's shortcuts are added without touching bindings the developer made
+// Params: content - current file ("" for a new file), managed - entries from setup.yaml (VS Code: key/command/when;
+// Zed: context/bindings)
+// Returns: New content (equal to content when nothing changes), error if content isn't a JSON array
+func MergeKeybindings(content string, managed []any) (string, error) {
+	current, err := parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid keybindings: %w", err)
+	}
+	list, ok := current.([]any)
+	if current == nil {
+		list, ok = []any{}, true
+	}
+	if !ok {
+		return "", fmt.Errorf("invalid keybindings: top level is not an array")
+	}
+
+	present := make(map[string]bool, len(list))
+	for _, entry := range list {
+		present[canonical(entry)] = true
+	}
+	added := 0
+	for _, raw := range managed {
+		entry, err := fromYAML(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid managed keybinding: %w", err)
+		}
+		if key := canonical(entry); !present[key] {
+			present[key] = true
+			list = append(list, entry)
+			added++
+		}
+	}
+	if added == 0 && current != nil {
+		return content, nil
+	}
+	return encode(list, detectIndent(content)), nil
+}
+
+// Lookup finds a setting in a settings file's content
+// What: Matches the key literally first (VS Code's "editor.formatOnSave"), else walks dotted segments through
+// nested objects (Zed's "terminal.font_size")
+// Params: content - settings file, key - setting name
+// Returns: Value in encoding/json's generic form, whether it exists, error if content can't be parsed
+func Lookup(content, key string) (any, bool, error) {
+	current, err := parse(content)
+	if err != nil {
+		return nil, false, err
+	}
+	root, ok := current.(*object)
+	if !ok {
+		return nil, false, nil
+	}
+	v, ok := lookup(root, key)
+	return plain(v), ok, nil
+}
+
+// lookup resolves key in obj, trying the literal key before each dotted split
+func lookup(obj *object, key string) (any, bool) {
+	if v, ok := obj.values[key]; ok {
+		return v, true
+	}
+	for i := range len(key) {
+		if key[i] != '.' {
+			continue
+		}
+		if sub, ok := obj.values[key[:i]].(*object); ok {
+			if v, ok := lookup(sub, key[i+1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Equal reports whether a looked-up value equals an expected value from YAML
+// Example: Equal(json.Number("14"), 14) → true
+func Equal(actual, expected any) bool {
+	want, err := fromYAML(expected)
+	return err == nil && canonical(actual) == canonical(want)
+}
+
+// HasValue reports whether a settings file holds a key with an expected value (json_value verify checks)
+// Params: path - settings file, key - setting (see Lookup), equals - expected value (nil = the key only has to exist)
+// Returns: False when the file is missing, unparsable, or the value differs
+func HasValue(path, key string, equals any) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	v, ok, err := Lookup(string(data), key)
+	if err != nil || !ok {
+		return false
+	}
+	return equals == nil || Equal(v, equals)
+}
+
+// BackupDir returns where previous editor files are kept
+// Returns: ~/.local/share/devsetup/backups/editor
+func BackupDir() string {
+	return filepath.Join(config.GetStateDir(), "backups", "editor")
+}
+
+// Write replaces an editor file after backing up its current content
+// Params: editor - names the backup (zed, vscode, or an AI CLI whose settings ai_assistant manages), path - settings or keybindings file, content - new content
+// Returns: Backup path ("" for a new file), error if the backup or write fails (the file is untouched when the backup fails)
+func Write(editor, path, content string) (string, error) {
+	backup := ""
+	if data, err := os.ReadFile(path); err == nil {
+		if err := os.MkdirAll(BackupDir(), 0700); err != nil {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		backup = filepath.Join(BackupDir(), editor+"-"+filepath.Base(path)+"."+time.Now().Format(stampFormat))
+		if err := os.WriteFile(backup, data, 0600); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return backup, nil
+}
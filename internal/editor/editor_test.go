@@ -0,0 +1,133 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeSettings(t *testing.T) {
+	current := `// Zed settings
+{
+    "theme": "One Dark",
+    "terminal": {"font_size": 13, "shell": "system"},
+    "tab_size": 4, // personal
+}
+`
+	got, err := MergeSettings(current, map[string]any{
+		"tab_size":       2,
+		"format_on_save": "on",
+		"terminal":       map[string]any{"font_size": 14},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+    "theme": "One Dark",
+    "terminal": {
+        "font_size": 14,
+        "shell": "system"
+    },
+    "tab_size": 2,
+    "format_on_save": "on"
+}
+`
+	if got != want {
+		t.Errorf("MergeSettings =\n%s\nwant\n%s", got, want)
+	}
+
+	again, err := MergeSettings(got, map[string]any{"tab_size": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != got {
+		t.Errorf("merging values already set should leave the file unchanged, got\n%s", again)
+	}
+
+	if fresh, err := MergeSettings("", map[string]any{"tab_size": 2}); err != nil || fresh != "{\n  \"tab_size\": 2\n}\n" {
+		t.Errorf("MergeSettings(empty) = %q, %v", fresh, err)
+	}
+	if _, err := MergeSettings("[1, 2]", map[string]any{"tab_size": 2}); err == nil {
+		t.Error("a settings file that isn't an object should be rejected")
+	}
+}
+
+func TestMergeKeybindings(t *testing.T) {
+	current := `[
+  {"key": "cmd+k cmd+t", "command": "workbench.action.terminal.toggleTerminal"}
+]`
+	got, err := MergeKeybindings(current, []any{
+		map[string]any{"command": "workbench.action.terminal.toggleTerminal", "key": "cmd+k cmd+t"},
+		map[string]any{"key": "cmd+shift+r", "command": "editor.action.rename", "when": "editorTextFocus"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+  {
+    "key": "cmd+k cmd+t",
+    "command": "workbench.action.terminal.toggleTerminal"
+  },
+  {
+    "command": "editor.action.rename",
+    "key": "cmd+shift+r",
+    "when": "editorTextFocus"
+  }
+]
+`
+	if got != want {
+		t.Errorf("MergeKeybindings =\n%s\nwant\n%s", got, want)
+	}
+
+	again, err := MergeKeybindings(got, []any{map[string]any{"key": "cmd+shift+r", "command": "editor.action.rename", "when": "editorTextFocus"}})
+	if err != nil || again != got {
+		t.Errorf("present keybindings should leave the file unchanged, got %v\n%s", err, again)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	content := `{
+  "editor.formatOnSave": true,
+  "terminal": {"font_size": 14, "env": {"A.B": "x"}},
+  "url": "http://example.com" // not a comment inside the string: "//"
+}`
+	for _, tt := range []struct {
+		key    string
+		equals any
+		found  bool
+	}{
+		{"editor.formatOnSave", true, true},
+		{"terminal.font_size", 14, true},
+		{"terminal.env.A.B", "x", true},
+		{"url", "http://example.com", true},
+		{"terminal.missing", nil, false},
+	} {
+		v, ok, err := Lookup(content, tt.key)
+		if err != nil {
+			t.Fatalf("Lookup(%s): %v", tt.key, err)
+		}
+		if ok != tt.found || (ok && !Equal(v, tt.equals)) {
+			t.Errorf("Lookup(%s) = %v, %v; want %v, %v", tt.key, v, ok, tt.equals, tt.found)
+		}
+	}
+}
+
+func TestWriteBacksUp(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "zed", "settings.json")
+
+	backup, err := Write("zed", path, "{}\n")
+	if err != nil || backup != "" {
+		t.Fatalf("Write(new) = %q, %v; want no backup", backup, err)
+	}
+	backup, err = Write("zed", path, "{\"tab_size\": 2}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(backup); err != nil || string(data) != "{}\n" {
+		t.Errorf("backup %s = %q, %v; want the previous content", backup, data, err)
+	}
+	if !HasValue(path, "tab_size", 2) || HasValue(path, "tab_size", 4) {
+		t.Error("HasValue should match the written tab_size")
+	}
+}
@@ -0,0 +1,273 @@
+// File: internal/editor/jsonc.go
+// Purpose: Order-preserving read and write of the JSON-with-comments files editors keep their settings in
+// Problem: encoding/json sorts object keys and rejects comments and trailing commas, so decoding and re-encoding a
+//          user's settings.json would reorder every key and fail on the comments Zed's default file ships with
+// Role: Parses JSONC into values whose objects keep their key order, and encodes them back in the file's indentation
+// Usage: v, err := parse(content); out := encode(v, detectIndent(content))
+// Design choices: Comments are stripped rather than kept (re-attaching them to moved keys is guesswork); numbers stay
+//                 json.Number so 1.50 isn't rewritten as 1.5
+// Assumptions: Settings files are small enough to hold in memory twice
+
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// object is a JSON object that remembers its key order
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+// newObject returns an empty object
+func newObject() *object {
+	return &object{values: make(map[string]any)}
+}
+
+// set adds or replaces a key; new keys go last
+func (o *object) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// parse decodes JSONC content; empty or comment-only content is nil
+// Returns: *object, []any, string, json.Number, bool, or nil; error on malformed content
+func parse(content string) (any, error) {
+	stripped := stripJSONC(content)
+	if strings.TrimSpace(stripped) == "" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(stripped))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected content after the top-level value")
+	}
+	return v, nil
+}
+
+// decodeValue reads one value from the token stream
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := newObject()
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(keyTok.(string), value)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case '[':
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+// stripJSONC removes // and /* */ comments and trailing commas outside strings
+func stripJSONC(content string) string {
+	var out strings.Builder
+	inString, escaped := false, false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case inString:
+			out.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out.WriteByte(c)
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			out.WriteByte('\n')
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end + 3
+			}
+		case c == ',' && closesNext(content[i+1:]):
+			// Trailing comma: dropped
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// closesNext reports whether the next significant character (skipping space and comments) closes an object or array
+func closesNext(rest string) bool {
+	rest = strings.TrimLeft(stripLeadingComments(rest), " \t\r\n")
+	return strings.HasPrefix(rest, "}") || strings.HasPrefix(rest, "]")
+}
+
+// stripLeadingComments skips whitespace and comments at the start of s
+func stripLeadingComments(s string) string {
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		switch {
+		case strings.HasPrefix(s, "//"):
+			if i := strings.IndexByte(s, '\n'); i >= 0 {
+				s = s[i:]
+			} else {
+				return ""
+			}
+		case strings.HasPrefix(s, "/*"):
+			if i := strings.Index(s, "*/"); i >= 0 {
+				s = s[i+2:]
+			} else {
+				return ""
+			}
+		default:
+			return s
+		}
+	}
+}
+
+// detectIndent returns the indentation of the first indented line (two spaces when there is none)
+func detectIndent(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+// encode writes v as indented JSON with a trailing newline
+func encode(v any, indent string) string {
+	var b strings.Builder
+	writeValue(&b, v, indent, 0)
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// writeValue writes one value at a nesting depth
+func writeValue(b *strings.Builder, v any, indent string, depth int) {
+	pad := strings.Repeat(indent, depth+1)
+	switch t := v.(type) {
+	case *object:
+		if len(t.keys) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{\n")
+		for i, key := range t.keys {
+			b.WriteString(pad)
+			b.WriteString(scalar(key))
+			b.WriteString(": ")
+			writeValue(b, t.values[key], indent, depth+1)
+			if i < len(t.keys)-1 {
+				b.WriteByte(',')
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.Repeat(indent, depth) + "}")
+	case []any:
+		if len(t) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[\n")
+		for i, item := range t {
+			b.WriteString(pad)
+			writeValue(b, item, indent, depth+1)
+			if i < len(t)-1 {
+				b.WriteByte(',')
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.Repeat(indent, depth) + "]")
+	default:
+		b.WriteString(scalar(t))
+	}
+}
+
+// scalar encodes a string, number, bool, or null without HTML escaping
+func scalar(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "null"
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// plain converts parsed values to encoding/json's generic form (objects become maps)
+func plain(v any) any {
+	switch t := v.(type) {
+	case *object:
+		m := make(map[string]any, len(t.keys))
+		for _, key := range t.keys {
+			m[key] = plain(t.values[key])
+		}
+		return m
+	case []any:
+		out := make([]any, len(t))
+		for i, item := range t {
+			out[i] = plain(item)
+		}
+		return out
+	}
+	return v
+}
+
+// fromYAML converts a value decoded from YAML into parsed form (map keys sorted)
+// Returns: Converted value, error if the value has no JSON form (e.g. non-string map keys)
+func fromYAML(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return parse(string(data))
+}
+
+// canonical is v's JSON with sorted keys, for equality checks
+func canonical(v any) string {
+	data, _ := json.Marshal(plain(v))
+	return string(data)
+}
@@ -0,0 +1,47 @@
+// File: internal/export/brewbundle.go
+// Purpose: Brewfile generation (devsetup export --format brewbundle)
+// Problem: Teams trying 'brew bundle' kept a Brewfile next to tools.yaml and the two disagreed within weeks
+// Role: Renders the brew formulas and casks of tools.yaml as a Brewfile
+// Usage: brewfile, skipped := export.Brewfile(items)
+// Design choices: Entries follow install order with the tool name as a comment so the Brewfile maps back to
+//                 tools.yaml; non-brew installs can't be expressed and are reported as skipped
+// Assumptions: Tap-qualified formulas (owner/tap/name) are installable by brew bundle as written
+
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Brewfile renders the items as a Brewfile
+// Params: items - Classify output
+// Returns: Brewfile content and the tools left out
+func Brewfile(items []Item) (string, []Skipped) {
+	var b strings.Builder
+	var skipped []Skipped
+
+	b.WriteString("# Generated by 'devsetup export --format brewbundle' from tools.yaml; regenerate instead of editing.\n")
+	b.WriteString("# Host versions are notes only: brew bundle installs the current versions.\n\n")
+	for _, item := range items {
+		var directive string
+		switch item.Kind {
+		case KindFormula:
+			directive = "brew"
+		case KindCask:
+			directive = "cask"
+		default:
+			skipped = append(skipped, Skipped{item.Tool.Name, "not a brew install"})
+			continue
+		}
+
+		note := item.Tool.Name
+		if item.HostVersion != "" {
+			note += " (host: " + item.HostVersion + ")"
+		}
+		for _, pkg := range item.Packages {
+			fmt.Fprintf(&b, "%s %q  # %s\n", directive, pkg, note)
+		}
+	}
+	return b.String(), skipped
+}
@@ -0,0 +1,106 @@
+// File: internal/export/container.go
+// Purpose: Dockerfile and devcontainer.json generation (devsetup containerize)
+// Problem: Contractors on locked-down Macs can't install tools on the host, so they got a different, hand-built
+//          environment that never matched what the team ran
+// Role: Renders tools.yaml as a Homebrew-on-Linux image plus a devcontainer.json that builds it
+// Usage: files, skipped := export.Container(items)
+// Design choices: The base image ships Homebrew on Linux, so formulas install with the same `brew install` lines as
+//                 on the Mac; one RUN per tool keeps layers cacheable and failures attributable; casks and
+//                 script/archive installs are macOS-specific and are reported as skipped
+// Assumptions: Containers have no launchd, so brew services are listed as ports to forward but not started
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ContainerImage is the base image: Ubuntu with Homebrew installed for the linuxbrew user
+const ContainerImage = "homebrew/brew:latest"
+
+// containerUser is the non-root user of ContainerImage that owns the Homebrew prefix
+const containerUser = "linuxbrew"
+
+// Skipped is a tool a generator left out
+type Skipped struct {
+	// Name of the tool
+	Name string
+
+	// Reason it was left out
+	Reason string
+}
+
+// devcontainer is the subset of devcontainer.json devsetup writes
+type devcontainer struct {
+	Name         string            `json:"name"`
+	Build        map[string]string `json:"build"`
+	RemoteUser   string            `json:"remoteUser"`
+	ForwardPorts []int             `json:"forwardPorts,omitempty"`
+}
+
+// Container renders the Dockerfile and devcontainer.json for the items
+// Params: items - Classify output
+// Returns: File name → content ("Dockerfile", "devcontainer.json"), and the tools left out
+func Container(items []Item) (map[string]string, []Skipped) {
+	var b strings.Builder
+	var skipped []Skipped
+	var ports []int
+
+	b.WriteString("# syntax=docker/dockerfile:1\n")
+	b.WriteString("# Generated by 'devsetup containerize' from tools.yaml; regenerate instead of editing.\n")
+	b.WriteString("# Host versions are notes only: brew installs the current formula versions.\n")
+	fmt.Fprintf(&b, "FROM %s\n", ContainerImage)
+
+	for _, item := range items {
+		tool := item.Tool
+		switch item.Kind {
+		case KindCask:
+			skipped = append(skipped, Skipped{tool.Name, "macOS app (cask)"})
+			continue
+		case KindOther:
+			reason := "script/archive installs are macOS-specific; add it by hand if needed"
+			if tool.Install.IsScript() && tool.Install.Script.IsHomebrew() {
+				reason = "provided by the base image"
+			}
+			skipped = append(skipped, Skipped{tool.Name, reason})
+			continue
+		}
+
+		b.WriteString("\n# " + tool.Name)
+		if item.HostVersion != "" {
+			b.WriteString(" (host: " + item.HostVersion + ")")
+		}
+		b.WriteString("\n")
+		if svc := tool.Service; svc != nil {
+			fmt.Fprintf(&b, "# Service %s isn't started automatically (no launchd in containers)\n", svc.ServiceName(tool.Name))
+			if svc.Port != 0 {
+				ports = append(ports, svc.Port)
+			}
+		}
+
+		if item.Kind == KindFormula {
+			fmt.Fprintf(&b, "RUN brew install %s\n", strings.Join(item.Packages, " "))
+			continue
+		}
+		command := strings.TrimSpace(tool.Install.Command)
+		if item.Shell == "sh" && !strings.Contains(command, "\n") {
+			fmt.Fprintf(&b, "RUN %s\n", command)
+			continue
+		}
+		fmt.Fprintf(&b, "RUN %s <<'DEVSETUP'\n%s\nDEVSETUP\n", item.Shell, command)
+	}
+
+	dc, _ := json.MarshalIndent(devcontainer{
+		Name:         "devsetup",
+		Build:        map[string]string{"dockerfile": "Dockerfile"},
+		RemoteUser:   containerUser,
+		ForwardPorts: ports,
+	}, "", "  ")
+
+	return map[string]string{
+		"Dockerfile":        b.String(),
+		"devcontainer.json": string(dc) + "\n",
+	}, skipped
+}
@@ -0,0 +1,107 @@
+// File: internal/export/export.go
+// Purpose: Translates tools.yaml into other provisioning formats (devcontainer, Brewfile, Nix flake)
+// Problem: Teams trying other provisioning paths (containers, brew bundle, Nix) hand-copied the tool list and it
+//          drifted from tools.yaml within weeks
+// Role: Classifies each tool by how it installs (brew formula, cask, shell command, script/archive) so every
+//       generator reads one consistent view of tools.yaml
+// Usage: items, err := export.Classify(toolsConfig, state); brewfile, skipped := export.Brewfile(items)
+// Design choices: Tools in install order so generated commands respect depends_on; anything a generator can't
+//                 express is reported back, never silently dropped; recorded host versions are carried as notes
+//                 because brew and nix can't pin arbitrary versions
+// Assumptions: brew installs are written as `brew install [--cask] ...` (see ToolInstall.BrewPackages)
+
+package export
+
+import (
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// Install kinds
+const (
+	KindFormula = "formula"
+	KindCask    = "cask"
+	KindCommand = "command"
+	KindOther   = "other"
+)
+
+// Item is one tool as generators see it
+type Item struct {
+	// Tool is the tools.yaml entry
+	Tool config.Tool
+
+	// Kind is formula, cask, command (non-brew shell command), or other (script/archive installs)
+	Kind string
+
+	// Packages are the brew formulas or casks the tool installs (formula and cask kinds)
+	Packages []string
+
+	// HostVersion is the version recorded on this machine ("" if not installed here)
+	HostVersion string
+
+	// Shell runs the tool's install command (sh, bash, or zsh)
+	Shell string
+}
+
+// Classify sorts tools.yaml into export items
+// What: One item per tool, in install (dependency) order
+// Why: Generators share one view of which tools are brew packages and which are commands
+// Params: tc - tools config, state - install state for host versions (nil = none)
+// Returns: Items, error if the install order can't be resolved
+// Edge cases: Commands mixing brew and other steps count as command; formulas and casks in one tool count as formula
+func Classify(tc *config.ToolsConfig, state *config.State) ([]Item, error) {
+	tools, err := tc.GetInstallOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(tools))
+	for _, tool := range tools {
+		item := Item{Tool: tool, Kind: KindOther, Shell: tc.ShellFor(tool)}
+		if state != nil {
+			if ts, ok := state.Installed[tool.Name]; ok && ts.Version != "unknown" {
+				item.HostVersion = ts.Version
+			}
+		}
+
+		if tool.Install.IsScript() || tool.Install.IsArchive() {
+			items = append(items, item)
+			continue
+		}
+		formulas, casks := tool.Install.BrewPackages()
+		switch {
+		case len(formulas) > 0 && onlyBrew(tool.Install.Command):
+			item.Kind, item.Packages = KindFormula, formulas
+		case len(casks) > 0 && onlyBrew(tool.Install.Command):
+			item.Kind, item.Packages = KindCask, casks
+		case tool.Install.Command != "":
+			item.Kind = KindCommand
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// onlyBrew reports whether every step of a command is a brew install
+func onlyBrew(command string) bool {
+	probe := config.ToolInstall{Command: command}
+	for _, step := range splitSteps(command) {
+		probe.Command = step
+		if f, c := probe.BrewPackages(); len(f) == 0 && len(c) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// splitSteps splits a shell command on &&, ;, and newlines, dropping blank steps
+func splitSteps(command string) []string {
+	var steps []string
+	for _, step := range strings.FieldsFunc(command, func(r rune) bool { return r == '&' || r == ';' || r == '\n' }) {
+		if strings.TrimSpace(step) != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,114 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func testTools() *config.ToolsConfig {
+	return &config.ToolsConfig{Tools: []config.Tool{
+		{Name: "homebrew", Install: config.ToolInstall{Type: config.InstallTypeScript, Script: &config.ScriptInstall{URL: "https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh"}}},
+		{Name: "git", Install: config.ToolInstall{Command: "brew install git"}, DependsOn: []string{"homebrew"}},
+		{Name: "zed", Install: config.ToolInstall{Command: "brew install --cask zed"}, DependsOn: []string{"homebrew"}},
+		{Name: "postgresql", Install: config.ToolInstall{Command: "brew install postgresql@16"}, Service: &config.ToolService{Name: "postgresql@16", Port: 5432}},
+		{Name: "pnpm-setup", Install: config.ToolInstall{Command: "brew install pnpm && pnpm setup"}},
+	}}
+}
+
+func TestClassify(t *testing.T) {
+	state := &config.State{Installed: map[string]config.ToolState{"git": {Version: "git version 2.43.0"}}}
+	items, err := Classify(testTools(), state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[string]string{}
+	for _, item := range items {
+		kinds[item.Tool.Name] = item.Kind
+		if item.Tool.Name == "git" && item.HostVersion != "git version 2.43.0" {
+			t.Errorf("git host version = %q", item.HostVersion)
+		}
+	}
+	want := map[string]string{"homebrew": KindOther, "git": KindFormula, "zed": KindCask, "postgresql": KindFormula, "pnpm-setup": KindCommand}
+	for name, kind := range want {
+		if kinds[name] != kind {
+			t.Errorf("%s classified as %q, want %q", name, kinds[name], kind)
+		}
+	}
+}
+
+func TestContainer(t *testing.T) {
+	items, err := Classify(testTools(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, skipped := Container(items)
+
+	dockerfile := files["Dockerfile"]
+	for _, line := range []string{"FROM " + ContainerImage, "RUN brew install git\n", "RUN brew install postgresql@16\n", "RUN brew install pnpm && pnpm setup\n"} {
+		if !strings.Contains(dockerfile, line) {
+			t.Errorf("Dockerfile missing %q:\n%s", line, dockerfile)
+		}
+	}
+	if strings.Contains(dockerfile, "zed") {
+		t.Error("casks must not be in the Dockerfile")
+	}
+	if len(skipped) != 2 || skipped[0].Name != "homebrew" || skipped[1].Name != "zed" {
+		t.Errorf("skipped = %+v", skipped)
+	}
+	if !strings.Contains(files["devcontainer.json"], "5432") {
+		t.Errorf("devcontainer.json should forward the service port:\n%s", files["devcontainer.json"])
+	}
+}
+
+func TestBrewfile(t *testing.T) {
+	items, err := Classify(testTools(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	brewfile, skipped := Brewfile(items)
+	for _, line := range []string{`brew "git"  # git`, `cask "zed"  # zed`, `brew "postgresql@16"  # postgresql`} {
+		if !strings.Contains(brewfile, line) {
+			t.Errorf("Brewfile missing %q:\n%s", line, brewfile)
+		}
+	}
+	if len(skipped) != 2 {
+		t.Errorf("skipped = %+v, want homebrew and pnpm-setup", skipped)
+	}
+}
+
+func TestNixAttr(t *testing.T) {
+	tests := map[string]string{
+		"git":           "git",
+		"node":          "nodejs",
+		"node@20":       "nodejs_20",
+		"python@3.12":   "python312",
+		"postgresql@16": "postgresql_16",
+	}
+	for formula, want := range tests {
+		if got, ok := NixAttr(formula); !ok || got != want {
+			t.Errorf("NixAttr(%q) = %q, %v; want %q", formula, got, ok, want)
+		}
+	}
+	if _, ok := NixAttr("hashicorp/tap/terraform"); ok {
+		t.Error("tap formulas have no nixpkgs attribute")
+	}
+}
+
+func TestNixFlake(t *testing.T) {
+	items, err := Classify(testTools(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flake, skipped := NixFlake(items)
+	for _, line := range []string{"            git  # git\n", "            postgresql_16  # postgresql\n", "pkgs.mkShell"} {
+		if !strings.Contains(flake, line) {
+			t.Errorf("flake missing %q:\n%s", line, flake)
+		}
+	}
+	if len(skipped) != 3 {
+		t.Errorf("skipped = %+v, want homebrew, zed, pnpm-setup", skipped)
+	}
+}
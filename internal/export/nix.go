@@ -0,0 +1,102 @@
+// File: internal/export/nix.go
+// Purpose: Nix flake devShell generation (devsetup export --format nix)
+// Problem: Teams experimenting with Nix rebuilt the tool list by hand and it drifted from tools.yaml
+// Role: Renders the brew formulas of tools.yaml as nixpkgs packages in a flake devShell
+// Usage: flake, skipped := export.NixFlake(items)
+// Design choices: Formula names map to nixpkgs attributes through a small table plus the versioned-formula rule
+//                 (postgresql@16 → postgresql_16); casks, tap formulas, and commands are reported as skipped rather
+//                 than guessed; the flake tracks nixpkgs-unstable and pins through flake.lock like any flake
+// Assumptions: Formulas without a table entry have a nixpkgs attribute of the same name (true for most CLIs)
+
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// nixAttrs maps brew formula names to nixpkgs attributes where they differ
+var nixAttrs = map[string]string{
+	"node":   "nodejs",
+	"python": "python3",
+	"awscli": "awscli2",
+}
+
+// NixFlake renders the items as a flake.nix with a default devShell
+// Params: items - Classify output
+// Returns: flake.nix content and the tools left out
+func NixFlake(items []Item) (string, []Skipped) {
+	var pkgs []string
+	var skipped []Skipped
+	for _, item := range items {
+		if item.Kind != KindFormula {
+			reason := "not a brew formula"
+			if item.Kind == KindCask {
+				reason = "macOS app (cask)"
+			}
+			skipped = append(skipped, Skipped{item.Tool.Name, reason})
+			continue
+		}
+		for _, formula := range item.Packages {
+			attr, ok := NixAttr(formula)
+			if !ok {
+				skipped = append(skipped, Skipped{item.Tool.Name, "tap formula " + formula + " has no nixpkgs equivalent"})
+				continue
+			}
+			line := attr + "  # " + item.Tool.Name
+			if item.HostVersion != "" {
+				line += " (host: " + item.HostVersion + ")"
+			}
+			pkgs = append(pkgs, line)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by 'devsetup export --format nix' from tools.yaml; regenerate instead of editing.\n")
+	b.WriteString("# Versions come from nixpkgs (pinned by flake.lock); host versions are notes only.\n")
+	b.WriteString(`{
+  description = "devsetup environment (generated from tools.yaml)";
+
+  inputs.nixpkgs.url = "github:NixOS/nixpkgs/nixpkgs-unstable";
+
+  outputs = { self, nixpkgs }:
+    let
+      systems = [ "aarch64-darwin" "x86_64-darwin" "aarch64-linux" "x86_64-linux" ];
+      forAllSystems = f: nixpkgs.lib.genAttrs systems (system: f nixpkgs.legacyPackages.${system});
+    in {
+      devShells = forAllSystems (pkgs: {
+        default = pkgs.mkShell {
+          packages = with pkgs; [
+`)
+	for _, p := range pkgs {
+		fmt.Fprintf(&b, "            %s\n", p)
+	}
+	b.WriteString(`          ];
+        };
+      });
+    };
+}
+`)
+	return b.String(), skipped
+}
+
+// NixAttr maps a brew formula to its nixpkgs attribute
+// Returns: Attribute name, false for tap formulas (owner/tap/name)
+// Example: NixAttr("postgresql@16") == "postgresql_16"; NixAttr("python@3.12") == "python312"
+func NixAttr(formula string) (string, bool) {
+	if strings.Contains(formula, "/") {
+		return "", false
+	}
+	name, version, versioned := strings.Cut(formula, "@")
+	if attr, ok := nixAttrs[name]; ok {
+		name = attr
+	}
+	if !versioned {
+		return name, true
+	}
+	// nixpkgs writes python versions without separators (python312) and others with _ (postgresql_16, nodejs_20)
+	if name == "python3" {
+		return "python" + strings.ReplaceAll(version, ".", ""), true
+	}
+	return name + "_" + strings.ReplaceAll(version, ".", "_"), true
+}
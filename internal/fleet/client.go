@@ -0,0 +1,124 @@
+// File: internal/fleet/client.go
+// Purpose: Reporting client that pushes verify results to a central team dashboard
+// Problem: The platform team had no way to see which laptops were out of compliance
+// Role: Builds the machine report document and POSTs it to a dashboard URL
+// Usage: err := fleet.Push(ctx, url, token, fleet.NewReport(version, snapshot, result))
+// Design choices: One JSON document per push (machine identity + status snapshot + verify JSON report);
+//                 reuses verify's JSON format so the dashboard and `verify --format json` agree
+// Assumptions: Dashboard accepts POST application/json and answers 2xx on success
+
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"runtime"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/status"
+	"github.com/rkinnovate/dev-setup/internal/verify"
+)
+
+// pushTimeout bounds a single report upload
+const pushTimeout = 15 * time.Second
+
+// Machine identifies the reporting laptop
+type Machine struct {
+	Hostname string `json:"hostname"`
+	User     string `json:"user"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+}
+
+// Report is the document served at /status and pushed to the dashboard
+// What: Machine identity, devsetup version, progress snapshot, and (optionally) verify results
+// Why: One schema for the local endpoint and the central dashboard
+type Report struct {
+	Machine     Machine         `json:"machine"`
+	Version     string          `json:"devsetup_version"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Status      status.Snapshot `json:"status"`
+	Verify      json.RawMessage `json:"verify,omitempty"`
+	Compliant   *bool           `json:"compliant,omitempty"`
+}
+
+// CurrentMachine describes this host
+// Returns: Machine with best-effort hostname and user
+func CurrentMachine() Machine {
+	m := Machine{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	m.Hostname, _ = os.Hostname()
+	if u, err := user.Current(); err == nil {
+		m.User = u.Username
+	}
+	return m
+}
+
+// NewReport builds a report document
+// What: Combines machine identity, status snapshot, and the verify JSON report
+// Params: version - devsetup version, snapshot - status snapshot, result - verify result (nil to omit)
+// Returns: Report, error if the verify result can't be encoded
+// Example: report, err := NewReport(version, reporter.Snapshot(), verifier.Run())
+func NewReport(version string, snapshot status.Snapshot, result *verify.VerifyResult) (*Report, error) {
+	report := &Report{
+		Machine:     CurrentMachine(),
+		Version:     version,
+		GeneratedAt: time.Now().UTC(),
+		Status:      snapshot,
+	}
+
+	if result != nil {
+		var buf bytes.Buffer
+		if err := verify.WriteReport(&buf, result, verify.FormatJSON); err != nil {
+			return nil, err
+		}
+		compliant := len(result.Errors) == 0
+		report.Verify = buf.Bytes()
+		report.Compliant = &compliant
+	}
+
+	return report, nil
+}
+
+// Push uploads a report to the team dashboard
+// What: POSTs the report as JSON, with "Authorization: Bearer <token>" when a token is set
+// Why: Lets the platform team see which laptops are out of compliance
+// Params: ctx - cancellation, url - dashboard endpoint, token - optional bearer token, report - document to send
+// Returns: Error on network failure or non-2xx status
+// Example: err := Push(ctx, "https://dash.example.com/api/reports", os.Getenv("DEVSETUP_REPORT_TOKEN"), report)
+func Push(ctx context.Context, url, token string, report *Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid report url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "devsetup/"+report.Version)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send report: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("dashboard rejected report: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func testServer(t *testing.T) *Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	tools := &config.ToolsConfig{Tools: []config.Tool{
+		{Name: "present", Check: "true"},
+		{Name: "absent", Check: "false"},
+	}}
+	return NewServer(tools, &config.SetupConfig{}, "1.2.3")
+}
+
+func TestStatusEndpoint(t *testing.T) {
+	srv := httptest.NewServer(testServer(t).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Version != "1.2.3" || report.Status.ToolsDone != 1 || report.Status.ToolsTotal != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.Verify != nil || report.Compliant != nil {
+		t.Error("/status should not include verify results")
+	}
+}
+
+func TestVerifyEndpointRejectsUnknownMode(t *testing.T) {
+	srv := httptest.NewServer(testServer(t).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/verify?mode=thorough")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestVerifyEndpointAuthorization(t *testing.T) {
+	server := testServer(t)
+	server.SetToken("s3cret")
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+
+	get := func(path, host, token string) int {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		if host != "" {
+			req.Host = host
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, tc := range []struct {
+		path, host, token string
+		want              int
+	}{
+		{"/verify", "", "", http.StatusOK},
+		{"/verify?mode=deep", "", "", http.StatusUnauthorized},
+		{"/verify?mode=standard", "", "wrong", http.StatusUnauthorized},
+		{"/verify?mode=deep", "", "s3cret", http.StatusOK},
+		{"/verify?mode=deep", "localhost:7780", "s3cret", http.StatusOK},
+		{"/status", "attacker.example:7780", "", http.StatusForbidden},
+	} {
+		if got := get(tc.path, tc.host, tc.token); got != tc.want {
+			t.Errorf("GET %s (host %q, token %q) = %d, want %d", tc.path, tc.host, tc.token, got, tc.want)
+		}
+	}
+}
+
+func TestPushSendsReportWithToken(t *testing.T) {
+	var auth string
+	var body []byte
+	dashboard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer dashboard.Close()
+
+	report, err := testServer(t).Report(true, "quick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Push(context.Background(), dashboard.URL, "secret", report); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	var got Report
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Compliant == nil || *got.Compliant {
+		t.Error("report with failing checks should be marked non-compliant")
+	}
+}
+
+func TestPushFailsOnRejectedReport(t *testing.T) {
+	dashboard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer dashboard.Close()
+
+	report, err := testServer(t).Report(false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Push(context.Background(), dashboard.URL, "", report); err == nil {
+		t.Error("expected error for 401 response")
+	}
+}
@@ -0,0 +1,240 @@
+// File: internal/fleet/server.go
+// Purpose: Local HTTP endpoint exposing machine status as JSON (devsetup serve)
+// Problem: Dashboards and scripts had to scrape terminal output to learn a laptop's setup state
+// Role: Serves /status, /verify, and /healthz; optionally pushes reports to a team dashboard on an interval
+// Usage: srv := fleet.NewServer(toolsCfg, setupCfg, version); srv.ListenAndServe(ctx, "127.0.0.1:7780")
+// Design choices: Stdlib net/http only; state reloaded per request so results track installs in progress;
+//                 verify runs quick mode unless ?mode= asks for more, and standard/deep (which run every check
+//                 command) need the API bearer token; verify runs are serialized; requests naming another host
+//                 are refused, so a web page can't reach the port through DNS rebinding
+// Assumptions: Bound to localhost by default; the dashboard URL (if any) is trusted with hostname and results
+
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/api"
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/status"
+	"github.com/rkinnovate/dev-setup/internal/verify"
+)
+
+// DefaultAddr is the default listen address for devsetup serve
+const DefaultAddr = "127.0.0.1:7780"
+
+// Server serves machine status over HTTP
+type Server struct {
+	toolsConfig *config.ToolsConfig
+	setupConfig *config.SetupConfig
+	version     string
+	policies    *config.PolicyConfig
+	token       string
+	verifyMu    sync.Mutex
+	mounts      map[string]http.Handler
+}
+
+// NewServer creates a status server
+// Params: toolsConfig, setupConfig - loaded configs, version - devsetup version reported in documents
+// Returns: Server ready for Handler or ListenAndServe
+func NewServer(toolsConfig *config.ToolsConfig, setupConfig *config.SetupConfig, version string) *Server {
+	return &Server{
+		toolsConfig: toolsConfig,
+		setupConfig: setupConfig,
+		version:     version,
+	}
+}
+
+// SetPolicies includes compliance policy checks in verify results
+// Params: policies - loaded policy config (nil disables policy checks)
+func (s *Server) SetPolicies(policies *config.PolicyConfig) {
+	s.policies = policies
+}
+
+// SetToken sets the bearer token GET /verify needs for standard and deep mode
+// Params: token - API token (empty = only quick mode is served)
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// Mount serves additional routes under a path prefix
+// Why: The programmatic API (internal/api) shares the serve listener
+// Params: prefix - path prefix ending in "/", h - handler for it
+func (s *Server) Mount(prefix string, h http.Handler) {
+	if s.mounts == nil {
+		s.mounts = make(map[string]http.Handler)
+	}
+	s.mounts[prefix] = h
+}
+
+// StatusHandler serves the /status document on its own
+// Why: Lets the API expose the same document behind its authentication
+// Returns: http.Handler
+func (s *Server) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, err := s.Report(false, "")
+		s.respond(w, report, err)
+	})
+}
+
+// Handler returns the HTTP routes
+// What: GET /status (snapshot), GET /verify?mode=quick|standard|deep (snapshot + verify results), GET /healthz
+// Why: Exposed separately so tests and embedders can mount it without listening
+// Returns: http.Handler that refuses requests for other hosts (see allowedHost)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("GET /status", s.StatusHandler())
+	mux.HandleFunc("GET /verify", func(w http.ResponseWriter, r *http.Request) {
+		mode := verify.Mode(r.URL.Query().Get("mode"))
+		switch mode {
+		case "":
+			mode = verify.ModeQuick
+		case verify.ModeQuick, verify.ModeStandard, verify.ModeDeep:
+		default:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "mode must be quick, standard, or deep"})
+			return
+		}
+		if mode != verify.ModeQuick && !api.Authorized(r, s.token) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "mode " + string(mode) + " needs the API bearer token"})
+			return
+		}
+		report, err := s.Report(true, mode)
+		s.respond(w, report, err)
+	})
+	for prefix, h := range s.mounts {
+		mux.Handle(prefix, h)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r.Host) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "unexpected Host header"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether a Host header names this machine
+// What: IP addresses, localhost, and the machine's host name (plain or .local)
+// Why: DNS rebinding needs a host name the attacker controls; IP literals can't be rebound
+func allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return true
+	}
+	name, err := os.Hostname()
+	if err != nil {
+		return false
+	}
+	name = strings.TrimSuffix(strings.ToLower(name), ".local")
+	return host == name || host == name+".local"
+}
+
+// Report builds a report from freshly loaded state
+// What: Reloads state.json, takes a status snapshot, optionally runs verify
+// Why: Shared by the HTTP handlers and the periodic dashboard push
+// Params: withVerify - also run checks, mode - verify mode when withVerify is set
+// Returns: Report, error if state can't be loaded
+func (s *Server) Report(withVerify bool, mode verify.Mode) (*Report, error) {
+	state, err := config.LoadState()
+	if err != nil {
+		return nil, err
+	}
+
+	// A malformed exceptions file is reported by `devsetup verify`; here it just means no exceptions
+	exceptions, _ := config.LoadExceptions()
+
+	reporter := status.NewReporter(s.toolsConfig, s.setupConfig, state, nil)
+	reporter.SetExceptions(exceptions)
+	var result *verify.VerifyResult
+	if withVerify {
+		s.verifyMu.Lock()
+		verifier := verify.NewVerifier(s.toolsConfig, s.setupConfig, state, nil, mode)
+		if s.policies != nil {
+			verifier.SetPolicies(s.policies)
+		}
+		verifier.SetExceptions(exceptions)
+		result = verifier.Run()
+		s.verifyMu.Unlock()
+	}
+	return NewReport(s.version, reporter.Snapshot(), result)
+}
+
+// ListenAndServe serves until ctx is cancelled
+// Params: ctx - stops the server when done, addr - listen address (empty uses DefaultAddr)
+// Returns: Error if listening fails
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	srv := &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ReportPeriodically pushes quick-verify reports to a dashboard until ctx is cancelled
+// What: Pushes once immediately, then every interval
+// Why: Keeps the team dashboard current for machines running devsetup serve
+// Params: ctx - stops the loop, url - dashboard endpoint, token - optional bearer token, interval - time between pushes, onError - called with each failed push (may be nil)
+func (s *Server) ReportPeriodically(ctx context.Context, url, token string, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := s.Report(true, verify.ModeQuick)
+		if err == nil {
+			err = Push(ctx, url, token, report)
+		}
+		if err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// respond writes a report or a 500 error document
+func (s *Server) respond(w http.ResponseWriter, report *Report, err error) {
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// writeJSON encodes v with the given status code
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,380 @@
+// File: internal/generations/generations.go
+// Purpose: nix-style generation history of environment changes
+// Problem: No auditable record of what a laptop's environment looked like after each install/setup run
+// Role: Records numbered generations (resolved config + installed state), diffs them, and rolls configs back
+// Usage: generations.Record("install", version, toolsCfg, setupCfg, state); generations.List(); generations.Diff(a, b)
+// Design choices: One JSON file per generation under <state dir>/generations; configs stored as resolved YAML so a
+//                 rollback writes loadable files; a run identical to the latest generation records nothing
+// Assumptions: Rollback restores declarations only; tools installed since are reported, and uninstalled only when
+//              the caller asks (generations rollback --uninstall)
+
+package generations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Generation is one recorded environment state
+// What: Resolved configs plus installed tool versions and configured tasks after a successful run
+// Why: Answers "what changed on this machine, and when"
+type Generation struct {
+	// Number increases by one per recorded generation
+	Number int `json:"number"`
+
+	// Time the generation was recorded
+	Time time.Time `json:"time"`
+
+	// Command that produced it (install, setup)
+	Command string `json:"command"`
+
+	// Version of devsetup that recorded it
+	Version string `json:"devsetup_version"`
+
+	// ToolsYAML is the resolved tools configuration
+	ToolsYAML string `json:"tools_yaml"`
+
+	// SetupYAML is the resolved setup configuration
+	SetupYAML string `json:"setup_yaml"`
+
+	// Installed maps tool name to installed version
+	Installed map[string]string `json:"installed"`
+
+	// Configured lists configured setup tasks (sorted)
+	Configured []string `json:"configured"`
+}
+
+// Dir returns the generations directory
+// Returns: <state dir>/generations
+func Dir() string {
+	return filepath.Join(config.GetStateDir(), "generations")
+}
+
+// Record stores a new generation if the environment changed
+// What: Snapshots configs and state; skips when identical to the latest generation
+// Why: Called after every successful install/setup run
+// Params: command - "install" or "setup", version - devsetup version, tools/setup - loaded configs (setup may be nil), state - current state
+// Returns: The new generation (nil if unchanged) and error if it couldn't be written
+// Example: gen, err := Record("install", version, toolsCfg, setupCfg, state)
+func Record(command, version string, tools *config.ToolsConfig, setup *config.SetupConfig, state *config.State) (*Generation, error) {
+	gen, err := snapshot(command, version, tools, setup, state)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := List()
+	if err != nil {
+		return nil, err
+	}
+	if len(all) > 0 {
+		latest := all[len(all)-1]
+		if latest.sameEnvironment(gen) {
+			return nil, nil
+		}
+		gen.Number = latest.Number + 1
+	} else {
+		gen.Number = 1
+	}
+
+	data, err := json.MarshalIndent(gen, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create generations dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(Dir(), fmt.Sprintf("%d.json", gen.Number)), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write generation: %w", err)
+	}
+	return gen, nil
+}
+
+// snapshot builds an unnumbered generation from configs and state
+func snapshot(command, version string, tools *config.ToolsConfig, setup *config.SetupConfig, state *config.State) (*Generation, error) {
+	gen := &Generation{
+		Time:      time.Now(),
+		Command:   command,
+		Version:   version,
+		Installed: make(map[string]string, len(state.Installed)),
+	}
+
+	toolsYAML, err := yaml.Marshal(tools)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tools config: %w", err)
+	}
+	gen.ToolsYAML = string(toolsYAML)
+
+	if setup != nil {
+		setupYAML, err := yaml.Marshal(setup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode setup config: %w", err)
+		}
+		gen.SetupYAML = string(setupYAML)
+	}
+
+	for name, ts := range state.Installed {
+		gen.Installed[name] = ts.Version
+	}
+	for name, done := range state.Configured {
+		if done {
+			gen.Configured = append(gen.Configured, name)
+		}
+	}
+	sort.Strings(gen.Configured)
+
+	return gen, nil
+}
+
+// sameEnvironment reports whether two generations describe the same environment
+func (g *Generation) sameEnvironment(other *Generation) bool {
+	if g.ToolsYAML != other.ToolsYAML || g.SetupYAML != other.SetupYAML {
+		return false
+	}
+	if len(g.Installed) != len(other.Installed) || strings.Join(g.Configured, ",") != strings.Join(other.Configured, ",") {
+		return false
+	}
+	for name, v := range g.Installed {
+		if ov, ok := other.Installed[name]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+// List returns all generations in ascending order
+// Returns: Generations (empty if none recorded yet)
+func List() ([]*Generation, error) {
+	entries, err := os.ReadDir(Dir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var all []*Generation
+	for _, entry := range entries {
+		n, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		gen, err := Get(n)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, gen)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Number < all[j].Number })
+	return all, nil
+}
+
+// Get loads one generation
+// Params: n - generation number
+// Returns: Generation, error if missing or unreadable
+func Get(n int) (*Generation, error) {
+	data, err := os.ReadFile(filepath.Join(Dir(), fmt.Sprintf("%d.json", n)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("generation %d does not exist", n)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var gen Generation
+	if err := json.Unmarshal(data, &gen); err != nil {
+		return nil, fmt.Errorf("generation %d is corrupt: %w", n, err)
+	}
+	return &gen, nil
+}
+
+// Changes is the difference between two generations
+type Changes struct {
+	ToolsAdded, ToolsRemoved, ToolsChanged []string
+	TasksAdded, TasksRemoved, TasksChanged []string
+	VersionChanges                         []string
+	Configured, Unconfigured               []string
+}
+
+// Empty reports whether nothing changed
+func (c *Changes) Empty() bool {
+	return len(c.Lines()) == 0
+}
+
+// Lines renders changes as "+ tool x", "- task y", "~ ..." lines
+// Returns: Human-readable change lines in a stable order
+func (c *Changes) Lines() []string {
+	var lines []string
+	add := func(prefix string, items []string) {
+		for _, item := range items {
+			lines = append(lines, prefix+item)
+		}
+	}
+	add("+ tool ", c.ToolsAdded)
+	add("- tool ", c.ToolsRemoved)
+	add("~ tool ", c.ToolsChanged)
+	add("+ task ", c.TasksAdded)
+	add("- task ", c.TasksRemoved)
+	add("~ task ", c.TasksChanged)
+	add("~ version ", c.VersionChanges)
+	add("+ configured ", c.Configured)
+	add("- configured ", c.Unconfigured)
+	return lines
+}
+
+// Diff compares two generations
+// What: Declaration changes (added/removed/changed tools and tasks), installed version changes, configured task changes
+// Params: from - older generation, to - newer generation
+// Returns: Changes, error if a stored config can't be parsed
+// Example: changes, err := Diff(gen3, gen4)
+func Diff(from, to *Generation) (*Changes, error) {
+	c := &Changes{}
+
+	fromTools, err := toolDeclarations(from.ToolsYAML)
+	if err != nil {
+		return nil, err
+	}
+	toTools, err := toolDeclarations(to.ToolsYAML)
+	if err != nil {
+		return nil, err
+	}
+	c.ToolsAdded, c.ToolsRemoved, c.ToolsChanged = compare(fromTools, toTools)
+
+	fromTasks, err := taskDeclarations(from.SetupYAML)
+	if err != nil {
+		return nil, err
+	}
+	toTasks, err := taskDeclarations(to.SetupYAML)
+	if err != nil {
+		return nil, err
+	}
+	c.TasksAdded, c.TasksRemoved, c.TasksChanged = compare(fromTasks, toTasks)
+
+	for _, name := range sortedKeys(to.Installed) {
+		if old, ok := from.Installed[name]; ok && old != to.Installed[name] {
+			c.VersionChanges = append(c.VersionChanges, fmt.Sprintf("%s: %s → %s", name, old, to.Installed[name]))
+		}
+	}
+
+	c.Configured = missingFrom(to.Configured, from.Configured)
+	c.Unconfigured = missingFrom(from.Configured, to.Configured)
+	return c, nil
+}
+
+// toolDeclarations maps tool name to its encoded declaration
+func toolDeclarations(data string) (map[string]string, error) {
+	var cfg config.ToolsConfig
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse stored tools config: %w", err)
+	}
+	decls := make(map[string]string, len(cfg.Tools))
+	for _, tool := range cfg.Tools {
+		encoded, _ := yaml.Marshal(tool)
+		decls[tool.Name] = string(encoded)
+	}
+	return decls, nil
+}
+
+// taskDeclarations maps setup task name to its encoded declaration
+func taskDeclarations(data string) (map[string]string, error) {
+	var cfg config.SetupConfig
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse stored setup config: %w", err)
+	}
+	decls := make(map[string]string, len(cfg.SetupTasks))
+	for _, task := range cfg.SetupTasks {
+		encoded, _ := yaml.Marshal(task)
+		decls[task.Name] = string(encoded)
+	}
+	return decls, nil
+}
+
+// compare returns added, removed, and changed keys (sorted)
+func compare(from, to map[string]string) (added, removed, changed []string) {
+	for _, name := range sortedKeys(to) {
+		old, ok := from[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case old != to[name]:
+			changed = append(changed, name)
+		}
+	}
+	for _, name := range sortedKeys(from) {
+		if _, ok := to[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed, changed
+}
+
+// missingFrom returns items in a that are not in b
+func missingFrom(a, b []string) []string {
+	set := make(map[string]bool, len(b))
+	for _, item := range b {
+		set[item] = true
+	}
+	var out []string
+	for _, item := range a {
+		if !set[item] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// sortedKeys returns map keys in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Rollback writes a generation's configs back to configDir
+// What: Backs up current tools.yaml/setup.yaml (*.bak-<timestamp>) then writes the generation's resolved configs
+// Why: Returns declarations to a known-good generation; the next install/setup converges to it
+// Params: gen - generation to restore, configDir - directory holding tools.yaml and setup.yaml
+// Returns: Paths written, error if any write fails
+// Edge cases: Tools installed after the generation stay installed; callers report them from Diff
+func Rollback(gen *Generation, configDir string) ([]string, error) {
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, err
+	}
+
+	stamp := time.Now().Format("20060102-150405")
+	files := []struct{ name, content string }{
+		{"tools.yaml", gen.ToolsYAML},
+		{"setup.yaml", gen.SetupYAML},
+	}
+
+	var written []string
+	for _, f := range files {
+		if f.content == "" {
+			continue
+		}
+		path := filepath.Join(configDir, f.name)
+		if current, err := os.ReadFile(path); err == nil {
+			if err := os.WriteFile(path+".bak-"+stamp, current, 0644); err != nil {
+				return written, fmt.Errorf("failed to back up %s: %w", path, err)
+			}
+		}
+		header := fmt.Sprintf("# Restored from devsetup generation %d (%s)\n", gen.Number, gen.Time.Format(time.RFC3339))
+		if err := os.WriteFile(path, []byte(header+f.content), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,50 @@
+package generations
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestRecordSkipsUnchangedAndDiffs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tools := &config.ToolsConfig{Tools: []config.Tool{{Name: "git", Check: "git --version"}}}
+	state := &config.State{
+		Installed:  map[string]config.ToolState{"git": {Version: "2.40"}},
+		Configured: map[string]bool{},
+	}
+
+	first, err := Record("install", "test", tools, nil, state)
+	if err != nil || first == nil || first.Number != 1 {
+		t.Fatalf("first Record = %+v, %v", first, err)
+	}
+
+	again, err := Record("install", "test", tools, nil, state)
+	if err != nil || again != nil {
+		t.Fatalf("unchanged Record = %+v, %v; want nil", again, err)
+	}
+
+	tools.Tools = append(tools.Tools, config.Tool{Name: "gh", Check: "gh --version"})
+	state.Installed["git"] = config.ToolState{Version: "2.41"}
+	state.Installed["gh"] = config.ToolState{Version: "2.0"}
+	second, err := Record("install", "test", tools, nil, state)
+	if err != nil || second == nil || second.Number != 2 {
+		t.Fatalf("second Record = %+v, %v", second, err)
+	}
+
+	changes, err := Diff(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"+ tool gh", "~ version git: 2.40 → 2.41"}
+	if got := changes.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+
+	all, err := List()
+	if err != nil || len(all) != 2 {
+		t.Fatalf("List = %d generations, %v", len(all), err)
+	}
+}
@@ -0,0 +1,166 @@
+// File: internal/githooks/githooks.go
+// Purpose: The team's standard git hooks (lefthook or pre-commit) for every repository on a machine
+// Problem: Hook managers need `lefthook install` / `pre-commit install` in each clone, which people forgot, so
+//          lint and commit-message rules were enforced on some machines only
+// Role: Renders hook scripts that run the standard hook config with the manager and then the repository's own
+//       hook, registers their directory as core.hooksPath (global or per checkout), and probes that git runs them
+// Usage: githooks.WriteScripts(dir, scripts); githooks.SetHooksPath(ctx, "", dir); githooks.Probe(ctx, repo, "pre-commit")
+// Design choices: core.hooksPath instead of per-repo installs, so new clones get the hooks with no step; the
+//                 repository's own .git/hooks script still runs after the team hook, so repo-specific hooks keep
+//                 working; a probe environment variable turns a hook run into a config check, so verify proves
+//                 git → script → manager works without running linters over someone's staged changes
+// Assumptions: git 2.36+ (`git hook run`; Xcode's git qualifies); lefthook honors LEFTHOOK_CONFIG
+
+package githooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// probeEnv names the variable that switches hook scripts to probe mode (see Probe)
+const probeEnv = "DEVSETUP_HOOK_PROBE"
+
+// Dir returns the directory holding a task's hook scripts
+// Example: Dir("git-hooks") → "~/.local/share/devsetup/git-hooks/git-hooks"
+func Dir(task string) string {
+	return filepath.Join(config.GetStateDir(), "git-hooks", task)
+}
+
+// Script returns the hook script for one git hook
+// What: Runs the manager with the standard config, then the repository's own hook (if executable); with
+// DEVSETUP_HOOK_PROBE set it only records that it ran and validates the config
+// Params: manager - lefthook or pre-commit, cfg - absolute path of the standard config, hook - git hook name
+func Script(manager, cfg, hook string) string {
+	var run, validate string
+	switch manager {
+	case config.HookManagerPreCommit:
+		run = "pre-commit hook-impl --config=" + config.ShellQuote(cfg) + " --hook-type=" + hook + ` --hook-dir "$(dirname "$0")" -- "$@"`
+		validate = "pre-commit validate-config " + config.ShellQuote(cfg)
+	default:
+		run = "LEFTHOOK_CONFIG=" + config.ShellQuote(cfg) + " lefthook run " + hook + ` "$@"`
+		validate = "LEFTHOOK_CONFIG=" + config.ShellQuote(cfg) + " lefthook validate"
+	}
+	return `#!/bin/sh
+# Written by devsetup: team ` + hook + ` hook (` + manager + `), then the repository's own hook
+if [ -n "$` + probeEnv + `" ]; then
+	echo ` + hook + ` >"$` + probeEnv + `"
+	` + validate + `
+	exit $?
+fi
+` + run + ` || exit $?
+# --git-common-dir, not --git-path: the latter follows core.hooksPath back to this script
+own="$(git rev-parse --git-common-dir)/hooks/` + hook + `"
+if [ -x "$own" ]; then
+	exec "$own" "$@"
+fi
+`
+}
+
+// WriteScripts makes dir hold exactly the given hook scripts
+// Params: dir - hook directory, scripts - hook name → script
+// Returns: Names written or removed (empty when dir was current), error if a write fails
+func WriteScripts(dir string, scripts map[string]string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	var changed []string
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if _, keep := scripts[e.Name()]; !keep {
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+				return changed, err
+			}
+			changed = append(changed, e.Name())
+		}
+	}
+	for name, script := range scripts {
+		path := filepath.Join(dir, name)
+		if data, err := os.ReadFile(path); err == nil && string(data) == script {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+			return changed, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		changed = append(changed, name)
+	}
+	slices.Sort(changed)
+	return changed, nil
+}
+
+// HooksPath reads core.hooksPath
+// Params: ctx - bounds git, repo - checkout ("" = the global setting)
+// Returns: The configured path ("" when unset), error if git fails otherwise
+func HooksPath(ctx context.Context, repo string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", gitConfig(repo, "--get", "core.hooksPath")...).Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == 1 {
+			return "", nil // Unset
+		}
+		return "", fmt.Errorf("failed to read core.hooksPath: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// SetHooksPath sets core.hooksPath
+// Params: ctx - bounds git, repo - checkout ("" = the global setting), dir - hook directory
+func SetHooksPath(ctx context.Context, repo, dir string) error {
+	if out, err := exec.CommandContext(ctx, "git", gitConfig(repo, "core.hooksPath", dir)...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set core.hooksPath: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// gitConfig returns `git config` arguments scoped to a checkout's own config, or the global one for ""
+func gitConfig(repo string, args ...string) []string {
+	if repo == "" {
+		return append([]string{"config", "--global"}, args...)
+	}
+	return append([]string{"-C", repo, "config", "--local"}, args...)
+}
+
+// Probe checks git runs devsetup's script for a hook
+// What: `git hook run <hook>` in repo (a scratch repository for "") with DEVSETUP_HOOK_PROBE set; passes when the
+// script ran and the manager accepted the hook config
+// Params: ctx - bounds git, repo - checkout ("" = global hooks), hook - git hook name
+// Returns: Error naming the hook path git used when the script didn't run, or the manager's complaint
+func Probe(ctx context.Context, repo, hook string) error {
+	if repo == "" {
+		scratch, err := os.MkdirTemp("", "devsetup-hook-probe-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(scratch)
+		if out, err := exec.CommandContext(ctx, "git", "init", "-q", scratch).CombinedOutput(); err != nil {
+			return fmt.Errorf("git init failed: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		repo = scratch
+	}
+	marker, err := os.CreateTemp("", "devsetup-hook-probe-*")
+	if err != nil {
+		return err
+	}
+	marker.Close()
+	defer os.Remove(marker.Name())
+
+	cmd := exec.CommandContext(ctx, "git", "-C", repo, "hook", "run", "--ignore-missing", hook)
+	cmd.Env = append(os.Environ(), probeEnv+"="+marker.Name())
+	out, runErr := cmd.CombinedOutput()
+	if data, _ := os.ReadFile(marker.Name()); strings.TrimSpace(string(data)) != hook {
+		path, _ := exec.CommandContext(ctx, "git", "-C", repo, "rev-parse", "--git-path", "hooks").Output()
+		return fmt.Errorf("git runs %s hooks from %s, not devsetup's", hook, strings.TrimSpace(string(path)))
+	}
+	if runErr != nil {
+		return fmt.Errorf("%s hook config check failed: %s", hook, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package githooks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// setupGit isolates git and puts a lefthook stub on PATH that logs its runs and fails validation for "bad" configs
+func setupGit(t *testing.T) (log string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home, bin := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	log = filepath.Join(home, "lefthook.log")
+	stub := "#!/bin/sh\necho \"$LEFTHOOK_CONFIG $*\" >>" + log + "\ncase \"$1 $LEFTHOOK_CONFIG\" in validate*bad*) exit 1;; esac\n"
+	if err := os.WriteFile(filepath.Join(bin, "lefthook"), []byte(stub), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestHooks(t *testing.T) {
+	log := setupGit(t)
+	ctx := context.Background()
+	dir := Dir("team-hooks")
+
+	changed, err := WriteScripts(dir, map[string]string{"pre-commit": Script(config.HookManagerLefthook, "/std/lefthook.yml", "pre-commit")})
+	if err != nil || !reflect.DeepEqual(changed, []string{"pre-commit"}) {
+		t.Fatalf("WriteScripts = %v, %v", changed, err)
+	}
+	if changed, _ := WriteScripts(dir, map[string]string{"pre-commit": Script(config.HookManagerLefthook, "/std/lefthook.yml", "pre-commit")}); len(changed) != 0 {
+		t.Errorf("unchanged scripts rewritten: %v", changed)
+	}
+
+	if err := Probe(ctx, "", "pre-commit"); err == nil || !strings.Contains(err.Error(), "not devsetup's") {
+		t.Errorf("unregistered hooks should fail the probe, got %v", err)
+	}
+	if err := SetHooksPath(ctx, "", dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := HooksPath(ctx, ""); got != dir {
+		t.Errorf("HooksPath = %q, want %q", got, dir)
+	}
+	if err := Probe(ctx, "", "pre-commit"); err != nil {
+		t.Errorf("Probe: %v", err)
+	}
+	if data, _ := os.ReadFile(log); string(data) != "/std/lefthook.yml validate\n" {
+		t.Errorf("probe should only validate the config, lefthook ran: %q", data)
+	}
+
+	// A real run: the team hook, then the repository's own hook
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	own := filepath.Join(repo, ".git", "hooks", "pre-commit")
+	if err := os.WriteFile(own, []byte("#!/bin/sh\necho own >>"+log+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", repo, "hook", "run", "pre-commit").CombinedOutput(); err != nil {
+		t.Fatalf("git hook run: %v: %s", err, out)
+	}
+	if data, _ := os.ReadFile(log); !strings.HasSuffix(string(data), "/std/lefthook.yml run pre-commit\nown\n") {
+		t.Errorf("hook run log = %q", data)
+	}
+
+	// Per-repo registration, and a config the manager rejects
+	if _, err := WriteScripts(dir, map[string]string{"commit-msg": Script(config.HookManagerLefthook, "/std/bad.yml", "commit-msg")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pre-commit")); !os.IsNotExist(err) {
+		t.Error("scripts for hooks no longer listed should be removed")
+	}
+	if err := SetHooksPath(ctx, repo, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := HooksPath(ctx, repo); got != dir {
+		t.Errorf("repo HooksPath = %q", got)
+	}
+	if err := Probe(ctx, repo, "commit-msg"); err == nil || !strings.Contains(err.Error(), "config check failed") {
+		t.Errorf("rejected config should fail the probe, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// File: internal/github/access.go
+// Purpose: GitHub org/team membership and SSO preflight
+// Problem: Private clones failed deep into setup with "repository not found" when the developer wasn't in the org
+//          yet or their token wasn't SSO-authorized, which says nothing about how to fix it
+// Role: Checks required org and team access up front and returns one actionable problem per gap
+// Usage: problems, err := github.CheckAccess(ctx, []string{"rkinnovate"}, []string{"rkinnovate/mobile"})
+// Design choices: Membership endpoints of the signed-in user (no admin rights needed); SAML errors are told apart
+//                 from missing membership because the fixes differ
+// Assumptions: gh authenticated with read:org scope; pending invitations count as missing access
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AccessProblem is one missing piece of GitHub access
+type AccessProblem struct {
+	// Subject is the org or "org/team" concerned
+	Subject string
+
+	// Message describes what is missing
+	Message string
+
+	// Fix tells the developer what to do
+	Fix string
+}
+
+// CheckAccess verifies membership of required orgs and teams
+// What: Active org membership, SSO authorization of the gh token, and team membership
+// Why: Preflight for tasks that clone private repositories
+// Params: ctx - bounds the gh calls, orgs - org logins, teams - "org/team-slug" entries
+// Returns: Problems (empty when everything is in place), error only if gh itself can't be used
+func CheckAccess(ctx context.Context, orgs, teams []string) ([]AccessProblem, error) {
+	login, err := api(ctx, "user", "--jq", ".login")
+	if err != nil {
+		return []AccessProblem{{
+			Subject: "gh",
+			Message: "not signed in to GitHub",
+			Fix:     "Run: gh auth login --scopes read:org",
+		}}, nil
+	}
+	login = strings.TrimSpace(login)
+
+	var problems []AccessProblem
+	for _, org := range orgs {
+		state, err := api(ctx, "user/memberships/orgs/"+org, "--jq", ".state")
+		if problem, ok := accessProblem(org, login, err); ok {
+			problems = append(problems, problem)
+			continue
+		}
+		if strings.TrimSpace(state) != "active" {
+			problems = append(problems, AccessProblem{
+				Subject: org,
+				Message: "invitation pending",
+				Fix:     fmt.Sprintf("Accept the invitation at https://github.com/orgs/%s/invitation", org),
+			})
+		}
+	}
+
+	for _, team := range teams {
+		org, slug, ok := strings.Cut(team, "/")
+		if !ok {
+			return nil, fmt.Errorf("team %q must be written as org/team", team)
+		}
+		state, err := api(ctx, fmt.Sprintf("orgs/%s/teams/%s/memberships/%s", org, slug, login), "--jq", ".state")
+		if problem, ok := accessProblem(team, login, err); ok {
+			problems = append(problems, problem)
+			continue
+		}
+		if strings.TrimSpace(state) != "active" {
+			problems = append(problems, AccessProblem{
+				Subject: team,
+				Message: "team membership pending",
+				Fix:     fmt.Sprintf("Ask a maintainer of %s to approve your membership", team),
+			})
+		}
+	}
+	return problems, nil
+}
+
+// accessProblem classifies a failed membership lookup
+// Params: subject - org or team, login - GitHub user, err - gh api error
+// Returns: Problem and true if err is non-nil
+func accessProblem(subject, login string, err error) (AccessProblem, bool) {
+	if err == nil {
+		return AccessProblem{}, false
+	}
+	org, _, _ := strings.Cut(subject, "/")
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "SAML"):
+		return AccessProblem{
+			Subject: subject,
+			Message: "token not authorized for SSO",
+			Fix:     fmt.Sprintf("Open https://github.com/orgs/%s/sso to sign in, then run: gh auth refresh -s read:org", org),
+		}, true
+	case strings.Contains(msg, "read:org"):
+		return AccessProblem{
+			Subject: subject,
+			Message: "gh token lacks the read:org scope",
+			Fix:     "Run: gh auth refresh -s read:org",
+		}, true
+	case strings.Contains(msg, "404"):
+		return AccessProblem{
+			Subject: subject,
+			Message: fmt.Sprintf("%s is not a member", login),
+			Fix:     fmt.Sprintf("Ask an owner of %s to add %s", subject, login),
+		}, true
+	default:
+		return AccessProblem{Subject: subject, Message: msg, Fix: "Check your network and GitHub status, then rerun"}, true
+	}
+}
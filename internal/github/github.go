@@ -0,0 +1,58 @@
+// File: internal/github/github.go
+// Purpose: Queries about the signed-in GitHub user via the gh CLI
+// Problem: Team-based defaults need to know which GitHub teams the developer belongs to
+// Role: Thin wrapper over `gh api` used by team profile assignment
+// Usage: teams, err := github.Teams(ctx)
+// Design choices: Uses gh's stored credentials instead of handling tokens; errors carry gh's message so
+//                 "not logged in" is actionable
+// Assumptions: gh installed and authenticated (`gh auth login`) with read:org scope
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Teams returns the signed-in user's teams
+// Params: ctx - bounds the gh call
+// Returns: Teams as "org/team-slug", sorted; error if gh is missing or not authenticated
+func Teams(ctx context.Context) ([]string, error) {
+	output, err := api(ctx, "user/teams", "--paginate", "--jq", `.[] | .organization.login + "/" + .slug`)
+	if err != nil {
+		return nil, err
+	}
+	teams := lines(output)
+	sort.Strings(teams)
+	return teams, nil
+}
+
+// api runs `gh api <path> [args...]`
+// Returns: stdout, or an error including gh's stderr
+func api(ctx context.Context, path string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "gh", append([]string{"api", path}, args...)...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("gh api %s: %s", path, msg)
+		}
+		return "", fmt.Errorf("gh api %s: %w", path, err)
+	}
+	return string(output), nil
+}
+
+// lines splits output into non-empty trimmed lines
+func lines(output string) []string {
+	var result []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package github
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAccessProblem(t *testing.T) {
+	tests := []struct {
+		err  string
+		want string
+	}{
+		{"gh api user/memberships/orgs/acme: Resource protected by organization SAML enforcement. (HTTP 403)", "SSO"},
+		{"gh api orgs/acme/teams/web/memberships/dev: Not Found (HTTP 404)", "not a member"},
+		{"gh api user/memberships/orgs/acme: This API operation needs the \"read:org\" scope. (HTTP 403)", "read:org"},
+	}
+	for _, tt := range tests {
+		p, ok := accessProblem("acme/web", "dev", errors.New(tt.err))
+		if !ok || !strings.Contains(p.Message, tt.want) {
+			t.Errorf("accessProblem(%q) = %+v, want message containing %q", tt.err, p, tt.want)
+		}
+		if p.Fix == "" {
+			t.Errorf("accessProblem(%q) has no fix", tt.err)
+		}
+	}
+
+	if _, ok := accessProblem("acme", "dev", nil); ok {
+		t.Error("nil error should not be a problem")
+	}
+}
@@ -0,0 +1,216 @@
+// File: internal/gitid/gitid.go
+// Purpose: Several git identities on one machine (personal + work accounts), chosen by directory
+// Problem: Juggling two GitHub accounts meant hand-written includeIf sections, ~/.ssh/config aliases, and remotes
+//          rewritten per clone; mistakes showed up as commits under the wrong email or pushes denied to one account
+// Role: Renders each identity's git include file, the ~/.gitconfig include/includeIf block, and the ~/.ssh/config
+//       host alias block; generates SSH keys; checks which identity git resolves in a directory and which account
+//       an SSH alias authenticates as
+// Usage: content := gitid.IdentityConfig(id, name, email); lines := gitid.GitconfigLines(ids); err := gitid.Check(ctx, id)
+// Design choices: One include file per identity under ~/.config/git/identities, owned by devsetup and rewritten
+//                 whole, so ~/.gitconfig only gains a small managed block; inside an identity's directories
+//                 git@<host>: and https://<host>/ remotes are rewritten to its SSH alias, so existing clones push
+//                 with the right key without editing their remotes
+// Assumptions: OpenSSH with the macOS keychain options (UseKeychain); the server greets `ssh -T` like GitHub
+//              ("Hi <login>!") or GitLab ("Welcome to GitLab, @<login>!")
+
+package gitid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// greeting matches the account name in a git server's `ssh -T` reply
+var greeting = regexp.MustCompile(`(?:Hi |Welcome to GitLab, @)([A-Za-z0-9_.-]+)!`)
+
+// Dir returns where identity include files are kept
+// Returns: ~/.config/git/identities
+func Dir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "git", "identities")
+}
+
+// File returns an identity's include file
+// Example: File("work") → "~/.config/git/identities/work.gitconfig"
+func File(name string) string {
+	return filepath.Join(Dir(), name+".gitconfig")
+}
+
+// gitdir returns an includeIf gitdir: pattern matching every repository under dir
+func gitdir(dir string) string {
+	return strings.TrimSuffix(config.ExpandPath(dir), "/") + "/"
+}
+
+// IdentityConfig renders an identity's include file
+// What: user.name/email, plus (for identities with dirs) remote rewrites to the identity's SSH alias
+// Params: id - identity, name/email - user.name and user.email
+func IdentityConfig(id config.GitIdentity, name, email string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Written by devsetup (git_identities): %s identity; changes are overwritten on setup\n", id.Name)
+	fmt.Fprintf(&b, "[user]\n\tname = %s\n\temail = %s\n", quote(name), quote(email))
+	if len(id.Dirs) > 0 {
+		fmt.Fprintf(&b, "[url \"git@%s:\"]\n\tinsteadOf = git@%s:\n\tinsteadOf = https://%s/\n", id.SSHAlias(), id.GitHost(), id.GitHost())
+	}
+	return b.String()
+}
+
+// quote quotes a git config value when it has characters git would otherwise strip or treat as a comment
+func quote(v string) string {
+	if v != strings.TrimSpace(v) || strings.ContainsAny(v, `#;"\`) {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+	}
+	return v
+}
+
+// ReadIdentity reads user.name and user.email from an identity's include file
+// Returns: Empty values when the file doesn't exist or lacks them
+func ReadIdentity(name string) (userName, email string) {
+	get := func(key string) string {
+		out, err := exec.Command("git", "config", "--file", File(name), "--get", key).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	return get("user.name"), get("user.email")
+}
+
+// GitconfigLines returns the ~/.gitconfig block: the default identity's include, then one includeIf per directory
+// Example: [include] path = ~/.config/git/identities/personal.gitconfig, [includeIf "gitdir:/Users/dev/work/"] path = ...
+func GitconfigLines(ids []config.GitIdentity) []string {
+	var lines []string
+	for _, id := range ids {
+		if len(id.Dirs) == 0 {
+			lines = append(lines, "[include]", "\tpath = "+File(id.Name))
+		}
+	}
+	// Later includes win, so directory identities override the default inside their directories
+	for _, id := range ids {
+		for _, dir := range id.Dirs {
+			lines = append(lines, `[includeIf "gitdir:`+gitdir(dir)+`"]`, "\tpath = "+File(id.Name))
+		}
+	}
+	return lines
+}
+
+// SSHLines returns the ~/.ssh/config block: one Host stanza per identity's alias
+// Edge cases: The default identity's stanza also matches the plain host, so git@github.com uses its key
+func SSHLines(ids []config.GitIdentity) []string {
+	var lines []string
+	for _, id := range ids {
+		hosts := id.SSHAlias()
+		if len(id.Dirs) == 0 {
+			hosts += " " + id.GitHost()
+		}
+		lines = append(lines,
+			"Host "+hosts,
+			"\tHostName "+id.GitHost(),
+			"\tUser git",
+			"\tIdentityFile "+id.KeyPath(),
+			"\tIdentitiesOnly yes",
+			"\tAddKeysToAgent yes",
+			"\tIgnoreUnknown UseKeychain",
+			"\tUseKeychain yes",
+		)
+	}
+	return lines
+}
+
+// GenerateKey creates an ed25519 key pair with ssh-keygen, which asks for the passphrase on the terminal
+// Params: ctx - bounds ssh-keygen, key - private key path, comment - key comment (the identity's email)
+// Returns: Error if ssh-keygen fails; the key is then added to the agent and keychain (failures ignored)
+func GenerateKey(ctx context.Context, key, comment string) error {
+	key = config.ExpandPath(key)
+	if err := os.MkdirAll(filepath.Dir(key), 0700); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "ssh-keygen", "-t", "ed25519", "-C", comment, "-f", key)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = prompt.Stdin(), os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ssh-keygen failed: %w", err)
+	}
+	add := exec.CommandContext(ctx, "ssh-add", "--apple-use-keychain", key)
+	add.Stdin, add.Stdout, add.Stderr = prompt.Stdin(), os.Stdout, os.Stderr
+	_ = add.Run()
+	return nil
+}
+
+// Resolve reports which file supplies user.email to repositories in dir
+// What: Creates a scratch repository in dir (removed afterwards) and asks git for user.email with its origin
+// Params: ctx - bounds git, dir - directory (created if missing; "" = a temporary directory outside any identity dirs)
+// Returns: Origin file and email, error if git fails or no user.email is set
+func Resolve(ctx context.Context, dir string) (origin, email string, err error) {
+	base := ""
+	if dir != "" {
+		base = config.ExpandPath(dir)
+		if err := os.MkdirAll(base, 0755); err != nil {
+			return "", "", err
+		}
+	}
+	probe, err := os.MkdirTemp(base, ".devsetup-identity-probe-")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(probe)
+	if out, err := exec.CommandContext(ctx, "git", "init", "-q", probe).CombinedOutput(); err != nil {
+		return "", "", fmt.Errorf("git init failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", probe, "config", "--show-origin", "--get", "user.email").Output()
+	if err != nil {
+		return "", "", errors.New("no user.email set")
+	}
+	origin, email, _ = strings.Cut(strings.TrimSpace(string(out)), "\t")
+	return strings.TrimPrefix(origin, "file:"), email, nil
+}
+
+// Login returns the account an SSH host alias authenticates as
+// Params: ctx - bounds ssh, alias - host alias from ~/.ssh/config
+// Returns: Account name, error with ssh's complaint when the server doesn't greet an account (key not added, etc.)
+func Login(ctx context.Context, alias string) (string, error) {
+	out, _ := exec.CommandContext(ctx, "ssh", "-T", "-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new", "-o", "ConnectTimeout=10", "git@"+alias).CombinedOutput()
+	if m := greeting.FindSubmatch(out); m != nil {
+		return string(m[1]), nil
+	}
+	return "", fmt.Errorf("ssh git@%s: %s", alias, strings.TrimSpace(string(out)))
+}
+
+// Check verifies an identity end to end
+// What: Repositories in each of its dirs (or, for the default identity, outside them) take user.email from its
+// include file, and its SSH alias authenticates (as Login, when set)
+// Returns: Error describing the first mismatch
+func Check(ctx context.Context, id config.GitIdentity) error {
+	dirs := id.Dirs
+	if len(dirs) == 0 {
+		dirs = []string{""}
+	}
+	for _, dir := range dirs {
+		origin, email, err := Resolve(ctx, dir)
+		where := dir
+		if where == "" {
+			where = "repositories outside identity directories"
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		if origin != File(id.Name) {
+			return fmt.Errorf("%s use %s from %s, not the %s identity", where, email, origin, id.Name)
+		}
+	}
+	login, err := Login(ctx, id.SSHAlias())
+	if err != nil {
+		return err
+	}
+	if id.Login != "" && !strings.EqualFold(login, id.Login) {
+		return fmt.Errorf("%s authenticates as %s, expected %s", id.SSHAlias(), login, id.Login)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package gitid
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestLines(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+	ids := []config.GitIdentity{
+		{Name: "work", Dirs: []string{"~/code/work/", "/srv/work"}},
+		{Name: "personal"},
+	}
+
+	want := []string{
+		"[include]", "\tpath = /home/dev/.config/git/identities/personal.gitconfig",
+		`[includeIf "gitdir:/home/dev/code/work/"]`, "\tpath = /home/dev/.config/git/identities/work.gitconfig",
+		`[includeIf "gitdir:/srv/work/"]`, "\tpath = /home/dev/.config/git/identities/work.gitconfig",
+	}
+	if got := GitconfigLines(ids); !reflect.DeepEqual(got, want) {
+		t.Errorf("GitconfigLines =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	ssh := strings.Join(SSHLines(ids), "\n")
+	for _, line := range []string{"Host github-work\n\tHostName github.com", "IdentityFile ~/.ssh/id_ed25519_work", "Host github-personal github.com\n"} {
+		if !strings.Contains(ssh, line) {
+			t.Errorf("SSHLines missing %q:\n%s", line, ssh)
+		}
+	}
+
+	work := IdentityConfig(ids[0], "Dev One", "dev@corp.example")
+	if !strings.Contains(work, "email = dev@corp.example") || !strings.Contains(work, `[url "git@github-work:"]`) || !strings.Contains(work, "insteadOf = https://github.com/") {
+		t.Errorf("IdentityConfig(work) =\n%s", work)
+	}
+	if personal := IdentityConfig(ids[1], "Dev # One", "dev@example.com"); strings.Contains(personal, "[url") || !strings.Contains(personal, `name = "Dev # One"`) {
+		t.Errorf("IdentityConfig(personal) =\n%s", personal)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(home, ".gitconfig"))
+	t.Setenv("TMPDIR", t.TempDir())
+
+	ids := []config.GitIdentity{
+		{Name: "work", Dirs: []string{"~/code/work"}},
+		{Name: "personal"},
+	}
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i, email := range []string{"dev@corp.example", "dev@example.com"} {
+		if err := os.WriteFile(File(ids[i].Name), []byte(IdentityConfig(ids[i], "Dev", email)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitconfig := strings.Join(GitconfigLines(ids), "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte(gitconfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	origin, email, err := Resolve(ctx, "~/code/work/acme")
+	if err != nil || origin != File("work") || email != "dev@corp.example" {
+		t.Errorf("Resolve(work) = %q, %q, %v", origin, email, err)
+	}
+	origin, email, err = Resolve(ctx, "")
+	if err != nil || origin != File("personal") || email != "dev@example.com" {
+		t.Errorf("Resolve(outside) = %q, %q, %v", origin, email, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(home, "code", "work", "acme")); len(entries) != 0 {
+		t.Errorf("Resolve left its probe repository behind: %v", entries)
+	}
+}
@@ -0,0 +1,141 @@
+// File: internal/hooks/hooks.go
+// Purpose: Dispatches lifecycle events to configured hooks
+// Problem: Teams wanted ticketing/chat integration on failures and drift without modifying the binary
+// Role: Runs the shell commands and webhook POSTs registered in hooks.yaml for each fired event
+// Usage: d := hooks.NewDispatcher(hooksCfg, ui); d.Fire(config.EventTaskFailed, map[string]string{"name": "gh"})
+// Design choices: Synchronous with per-hook timeout so hooks finish before the process exits; nil Dispatcher is a no-op
+//                 so callers don't need nil checks; failures are warnings; payload data is redacted
+// Assumptions: Hooks are few and fast; webhook endpoints accept JSON
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/redact"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+// defaultTimeout bounds a hook without an explicit timeout
+const defaultTimeout = 10 * time.Second
+
+// Dispatcher fires events to hooks
+type Dispatcher struct {
+	hooks []config.Hook
+	ui    ui.UI
+}
+
+// payload is the webhook request body
+type payload struct {
+	Event    string            `json:"event"`
+	Time     time.Time         `json:"time"`
+	Hostname string            `json:"hostname"`
+	Data     map[string]string `json:"data"`
+}
+
+// NewDispatcher creates an event dispatcher
+// Params: cfg - hooks config (nil means no hooks), ui - for hook failure warnings (nil writes them to stderr)
+// Returns: Dispatcher
+// Example: d := NewDispatcher(hooksCfg, progressUI)
+func NewDispatcher(cfg *config.HooksConfig, ui ui.UI) *Dispatcher {
+	d := &Dispatcher{ui: ui}
+	if cfg != nil {
+		d.hooks = cfg.Hooks
+	}
+	return d
+}
+
+// Fire runs every hook registered for event
+// What: Command hooks get DEVSETUP_EVENT plus DEVSETUP_<KEY> env vars; webhooks get a JSON POST
+// Why: Single call site per lifecycle event
+// Params: event - event name (config.Event*), data - event details
+// Edge cases: Safe on a nil Dispatcher; failures only warn
+func (d *Dispatcher) Fire(event string, data map[string]string) {
+	if d == nil {
+		return
+	}
+
+	clean := make(map[string]string, len(data))
+	for k, v := range data {
+		clean[k] = redact.String(v)
+	}
+
+	for _, h := range d.hooks {
+		if h.Event != event {
+			continue
+		}
+
+		timeout := h.Timeout
+		if timeout == 0 {
+			timeout = defaultTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		var err error
+		if h.Command != "" {
+			err = runCommand(ctx, h.Command, event, clean)
+		} else {
+			err = postWebhook(ctx, h, event, clean)
+		}
+		cancel()
+
+		if err != nil {
+			if d.ui != nil {
+				d.ui.Warning("⚠️  %s hook failed: %v", event, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: %s hook failed: %v\n", event, err)
+			}
+		}
+	}
+}
+
+// runCommand runs a command hook with event data in the environment
+func runCommand(ctx context.Context, command, event string, data map[string]string) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), "DEVSETUP_EVENT="+event)
+	for k, v := range data {
+		cmd.Env = append(cmd.Env, "DEVSETUP_"+strings.ToUpper(k)+"="+v)
+	}
+	// Hook output goes to stderr so it never mixes with machine-readable stdout
+	cmd.Stdout = redact.NewWriter(os.Stderr)
+	cmd.Stderr = redact.NewWriter(os.Stderr)
+	return cmd.Run()
+}
+
+// postWebhook sends the event as JSON to a webhook
+func postWebhook(ctx context.Context, h config.Hook, event string, data map[string]string) error {
+	hostname, _ := os.Hostname()
+	body, err := json.Marshal(payload{Event: event, Time: time.Now().UTC(), Hostname: hostname, Data: data})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
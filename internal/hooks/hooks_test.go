@@ -0,0 +1,62 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestFireRunsMatchingCommandHooks(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	cfg := &config.HooksConfig{Hooks: []config.Hook{
+		{Event: config.EventTaskFailed, Command: `echo "$DEVSETUP_EVENT $DEVSETUP_NAME" > ` + out},
+		{Event: config.EventStageComplete, Command: "echo wrong >> " + out},
+	}}
+
+	NewDispatcher(cfg, nil).Fire(config.EventTaskFailed, map[string]string{"name": "gh"})
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(got)) != "task_failed gh" {
+		t.Errorf("hook output = %q", got)
+	}
+}
+
+func TestFirePostsWebhookWithHeaders(t *testing.T) {
+	t.Setenv("HOOK_TOKEN", "abc")
+	var got payload
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	cfg := &config.HooksConfig{Hooks: []config.Hook{{
+		Event:   config.EventVerifyDrift,
+		Webhook: server.URL,
+		Headers: map[string]string{"Authorization": "Bearer ${HOOK_TOKEN}"},
+	}}}
+
+	NewDispatcher(cfg, nil).Fire(config.EventVerifyDrift, map[string]string{"drifted": "git"})
+
+	if got.Event != config.EventVerifyDrift || got.Data["drifted"] != "git" {
+		t.Errorf("payload = %+v", got)
+	}
+	if auth != "Bearer abc" {
+		t.Errorf("Authorization = %q", auth)
+	}
+}
+
+func TestNilDispatcherIsNoop(t *testing.T) {
+	var d *Dispatcher
+	d.Fire(config.EventStageComplete, nil)
+}
@@ -0,0 +1,258 @@
+// File: internal/i18n/i18n.go
+// Purpose: Message catalogs for terminal output, so regional offices can localize onboarding
+// Problem: Every message was hard-coded English; new hires in non-English offices read their first day's
+//          setup output in a second language, and there was nowhere to put a translation
+// Role: Picks the language (DEVSETUP_LANG, ~/.config/devsetup/locale.yaml, then system settings) and translates
+//       ProgressUI's format strings through the catalog for it
+// Usage: i18n.Init() once at startup; ProgressUI calls i18n.T(format) before formatting every message
+// Design choices: gettext-style: catalogs are keyed by the English format string itself, so untranslated messages
+//                 fall back to English and no call site needs a message ID; a translation must keep the key's
+//                 printf verbs in the same order or it is ignored (a broken translation must not garble output);
+//                 built-in catalogs are embedded, and files in ~/.config/devsetup/locales/ add to or override them
+// Assumptions: English is the source language; API events and logs stay English for programs that parse them
+
+package i18n
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Default is the source language of every message
+const Default = "en"
+
+// builtin holds the catalogs shipped with devsetup (locales/<language>.yaml)
+//
+//go:embed locales/*.yaml
+var builtin embed.FS
+
+// verbPattern matches printf verbs (with flags, width, and precision), not %%
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z]`)
+
+var (
+	mu       sync.RWMutex
+	language = Default
+	catalog  map[string]string
+)
+
+// LocaleConfig is ~/.config/devsetup/locale.yaml
+type LocaleConfig struct {
+	// Language is the language code to use (e.g. de, pt-BR); empty follows the system
+	Language string `yaml:"language"`
+}
+
+// ConfigPath returns the per-user language setting
+// Returns: ~/.config/devsetup/locale.yaml
+func ConfigPath() string {
+	return filepath.Join(config.GetUserConfigDir(), "locale.yaml")
+}
+
+// CatalogDir returns where per-user catalogs live
+// Returns: ~/.config/devsetup/locales
+func CatalogDir() string {
+	return filepath.Join(config.GetUserConfigDir(), "locales")
+}
+
+// Init selects the language for this run and loads its catalog
+// What: Detects the language (see Detect) and calls SetLanguage, falling back to English
+// Why: Called once at startup, before any output
+// Returns: Error if a configured language has no catalog or a catalog is malformed (output stays English)
+// Edge cases: A system language without a catalog silently stays English; only an explicit choice is reported
+func Init() error {
+	lang := Detect(context.Background())
+	if err := SetLanguage(lang); err != nil {
+		_ = SetLanguage(Default)
+		if configured() != "" {
+			return err
+		}
+	}
+	return nil
+}
+
+// Detect returns the language to use
+// What: DEVSETUP_LANG, then language in locale.yaml, then LC_ALL/LC_MESSAGES/LANG, then macOS AppleLocale
+// Returns: Normalized language code (e.g. "de", "pt-BR"), Default when nothing is set
+// Edge cases: The C and POSIX locales mean "no preference" and are skipped
+func Detect(ctx context.Context) string {
+	if lang := configured(); lang != "" {
+		return lang
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang := Normalize(os.Getenv(name)); lang != "" {
+			return lang
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, "defaults", "read", "-g", "AppleLocale").Output(); err == nil {
+			if lang := Normalize(string(out)); lang != "" {
+				return lang
+			}
+		}
+	}
+	return Default
+}
+
+// configured returns the language chosen explicitly (DEVSETUP_LANG or locale.yaml), "" if none
+func configured() string {
+	if lang := os.Getenv("DEVSETUP_LANG"); lang != "" {
+		return Normalize(lang)
+	}
+	if data, err := os.ReadFile(ConfigPath()); err == nil {
+		var cfg LocaleConfig
+		if yaml.Unmarshal(data, &cfg) == nil && cfg.Language != "" {
+			return Normalize(cfg.Language)
+		}
+	}
+	return ""
+}
+
+// Normalize turns a locale name into a language code
+// Example: "de_DE.UTF-8" → "de"; "pt_BR" → "pt-BR"; "C" → ""
+// Edge cases: The region is kept only for languages that have a regional catalog; others drop it
+func Normalize(locale string) string {
+	locale = strings.TrimSpace(locale)
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	base, region, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	base = strings.ToLower(base)
+	if region != "" {
+		regional := base + "-" + strings.ToUpper(region)
+		if slices.Contains(Languages(), regional) {
+			return regional
+		}
+	}
+	return base
+}
+
+// Languages lists the languages with a catalog (built-in or per-user), English included
+func Languages() []string {
+	langs := []string{Default}
+	add := func(name string) {
+		if lang, ok := strings.CutSuffix(name, ".yaml"); ok && !slices.Contains(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+	if entries, err := builtin.ReadDir("locales"); err == nil {
+		for _, e := range entries {
+			add(e.Name())
+		}
+	}
+	if entries, err := os.ReadDir(CatalogDir()); err == nil {
+		for _, e := range entries {
+			add(e.Name())
+		}
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// SetLanguage switches output to a language
+// Params: lang - language code (Default or one of Languages)
+// Returns: Error if the language has no catalog or its catalog is malformed
+func SetLanguage(lang string) error {
+	if lang == "" {
+		lang = Default
+	}
+	messages := map[string]string{}
+	if lang != Default {
+		var err error
+		if messages, err = load(lang); err != nil {
+			return err
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	language = lang
+	catalog = messages
+	return nil
+}
+
+// Language returns the language in use
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T translates a message format string
+// Params: format - English format string as passed to the UI
+// Returns: The translation, or format itself when the catalog has none
+// Example: i18n.T("✅ Setup complete!") → "✅ Einrichtung abgeschlossen!" with language de
+func T(format string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if translated, ok := catalog[format]; ok {
+		return translated
+	}
+	return format
+}
+
+// load reads a language's built-in catalog and layers the per-user one over it
+// Returns: Messages with mismatched verbs dropped; error if neither catalog exists or one is invalid YAML
+func load(lang string) (map[string]string, error) {
+	messages := map[string]string{}
+	found := false
+
+	if data, err := builtin.ReadFile("locales/" + lang + ".yaml"); err == nil {
+		if err := parse(data, messages); err != nil {
+			return nil, fmt.Errorf("built-in catalog %s: %w", lang, err)
+		}
+		found = true
+	}
+	path := filepath.Join(CatalogDir(), lang+".yaml")
+	if data, err := os.ReadFile(path); err == nil {
+		if err := parse(data, messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("no messages for language %q (available: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	return messages, nil
+}
+
+// parse adds a catalog's valid translations to messages
+func parse(data []byte, messages map[string]string) error {
+	var entries map[string]string
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for source, translated := range entries {
+		if translated != "" && sameVerbs(source, translated) {
+			messages[source] = translated
+		}
+	}
+	return nil
+}
+
+// sameVerbs reports whether a translation keeps the source's printf verbs in order
+func sameVerbs(source, translated string) bool {
+	return slices.Equal(Verbs(source), Verbs(translated))
+}
+
+// Verbs returns the printf verbs in a format string, in order
+// Example: "Stage %d/%d: %s" → [%d %d %s]
+func Verbs(format string) []string {
+	return verbPattern.FindAllString(strings.ReplaceAll(format, "%%", ""), -1)
+}
@@ -0,0 +1,133 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"de_DE.UTF-8": "de",
+		"de":          "de",
+		"en_US.UTF-8": "en",
+		"pt_BR":       "pt",
+		"sr_RS@latin": "sr",
+		"C":           "",
+		"POSIX":       "",
+		" de_CH\n":    "de",
+		"":            "",
+	}
+	for in, want := range cases {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer SetLanguage(Default)
+
+	if err := SetLanguage("de"); err != nil {
+		t.Fatal(err)
+	}
+	if got := T("✅ Setup complete!"); got != "✅ Einrichtung abgeschlossen!" {
+		t.Errorf("T(setup complete) = %q", got)
+	}
+	if got := T("an untranslated message %s"); got != "an untranslated message %s" {
+		t.Errorf("untranslated message = %q, want the English source", got)
+	}
+
+	// Per-user catalogs override built-in messages; translations that change the verbs are ignored
+	dir := CatalogDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	user := "\"✅ Setup complete!\": \"✅ Fertig!\"\n\"Installing %d tools...\": \"Installiere %s Tools...\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "de.yaml"), []byte(user), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLanguage("de"); err != nil {
+		t.Fatal(err)
+	}
+	if got := T("✅ Setup complete!"); got != "✅ Fertig!" {
+		t.Errorf("user override = %q", got)
+	}
+	if got := T("Installing %d tools..."); got != "%d Tools werden installiert..." {
+		t.Errorf("verb-mismatched override = %q, want the built-in translation", got)
+	}
+
+	if err := SetLanguage("xx"); err == nil {
+		t.Error("SetLanguage(xx) succeeded without a catalog")
+	}
+	if Language() != "de" {
+		t.Errorf("failed SetLanguage changed the language to %q", Language())
+	}
+}
+
+func TestInit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer SetLanguage(Default)
+
+	// A system locale without a catalog quietly stays English
+	t.Setenv("DEVSETUP_LANG", "")
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	if err := Init(); err != nil || Language() != Default {
+		t.Errorf("Init() with LC_ALL=fr = %v, language %q", err, Language())
+	}
+
+	// An explicit choice without a catalog is reported
+	t.Setenv("DEVSETUP_LANG", "fr")
+	if err := Init(); err == nil {
+		t.Error("Init() with DEVSETUP_LANG=fr succeeded")
+	}
+
+	t.Setenv("DEVSETUP_LANG", "de_AT")
+	if err := Init(); err != nil || Language() != "de" {
+		t.Errorf("Init() with DEVSETUP_LANG=de_AT = %v, language %q", err, Language())
+	}
+}
+
+// Every built-in translation must keep its verbs and match a message the code actually prints
+func TestBuiltinCatalogs(t *testing.T) {
+	var sources strings.Builder
+	for _, root := range []string{"../../cmd", "../../internal"} {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			sources.Write(data)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	code := sources.String()
+
+	entries, err := builtin.ReadDir("locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, _ := builtin.ReadFile("locales/" + e.Name())
+		var messages map[string]string
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			t.Fatalf("%s: %v", e.Name(), err)
+		}
+		for source, translated := range messages {
+			if !sameVerbs(source, translated) {
+				t.Errorf("%s: %q changes the verbs of %q", e.Name(), translated, source)
+			}
+			if !strings.Contains(code, strconv.Quote(source)) && !strings.Contains(code, `"`+source+`"`) {
+				t.Errorf("%s: no message %q in the code", e.Name(), source)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// File: internal/installer/apps.go
+// Purpose: Install preflight that quits Mac apps blocking their own install or upgrade
+// Problem: Cask installs over a running app (upgrading Docker Desktop while it runs) failed halfway or left the old
+//          version in place, and the error never said that quitting the app was the fix
+// Role: Before a tool with apps installs, finds which are running, asks to quit them (--yes quits without asking),
+//       retries once if the install fails while one came back, and reopens what it quit afterwards
+// Usage: Called by runInstall around the install attempts
+// Design choices: Apps come from the tool's apps list or, for cask installs, from `brew info --cask`; apps are asked
+//                 to quit through Apple Events (unsaved work prompts as usual), never killed; declining fails the
+//                 tool rather than installing over a running app
+// Assumptions: macOS (pgrep, osascript, open); an app's processes run from <Name>.app/Contents/MacOS
+
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// Running app handling
+const (
+	// appQuitTimeout is how long a quit app gets to exit
+	appQuitTimeout = 30 * time.Second
+	// appPollInterval is how often a quitting app is checked
+	appPollInterval = 500 * time.Millisecond
+)
+
+// quitRunningApps quits the tool's apps that are running, with consent
+// What: Lists running apps, asks once for all of them (unless --yes), and waits for each to exit
+// Why: Installers can't replace a running app bundle
+// Params: ctx - install context, tool - tool about to install
+// Returns: Apps that were quit (to reopen later); error if quitting is declined or an app doesn't exit
+func (ti *ToolInstaller) quitRunningApps(ctx context.Context, tool config.Tool) ([]string, error) {
+	var running []string
+	for _, app := range ti.toolApps(ctx, tool) {
+		if appRunning(ctx, app) {
+			running = append(running, app)
+		}
+	}
+	if len(running) == 0 {
+		return nil, nil
+	}
+
+	names := strings.Join(running, ", ")
+	if !ti.assumeYes {
+		ok, err := ti.confirmQuit(tool, names)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s is running and blocks installing %s; quit it and rerun, or rerun with --yes to quit it automatically", names, tool.Name)
+		}
+	}
+
+	var quit []string
+	for _, app := range running {
+		ti.ui.Info("  Quitting %s...", app)
+		if err := quitApp(ctx, app); err != nil {
+			return quit, err
+		}
+		quit = append(quit, app)
+	}
+	return quit, nil
+}
+
+// confirmQuit asks for consent to quit running apps
+// Returns: True if the user answered yes; prompt.ErrDeferred on Ctrl-C
+func (ti *ToolInstaller) confirmQuit(tool config.Tool, names string) (bool, error) {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	ti.ui.Info("🛑 %s is running and must be quit before %s can be installed or upgraded", names, tool.Name)
+	return prompt.Confirm(tool.Name, "   Quit it now (it is reopened afterwards)? [y/N] ")
+}
+
+// reopenApps launches apps quit for an install again
+// Edge cases: Failures only warn; the install result is what matters
+func (ti *ToolInstaller) reopenApps(apps []string) {
+	for _, app := range apps {
+		if err := exec.Command("open", "-a", app).Run(); err != nil {
+			ti.ui.Warning("⚠️  Failed to reopen %s: %v", app, err)
+		}
+	}
+}
+
+// toolApps returns the apps a tool installs
+// Returns: The tool's apps list, else a cask's app artifacts from brew, else nothing
+func (ti *ToolInstaller) toolApps(ctx context.Context, tool config.Tool) []string {
+	if len(tool.Apps) > 0 {
+		return tool.Apps
+	}
+	if tool.Install.Type != config.InstallTypeCask || tool.Install.Package == nil {
+		return nil
+	}
+	out, err := exec.CommandContext(ctx, "brew", "info", "--cask", "--json=v2", tool.Install.Package.Name).Output()
+	if err != nil {
+		return nil
+	}
+	return parseCaskApps(out)
+}
+
+// parseCaskApps extracts app names from `brew info --cask --json=v2` output
+// Example: {"casks":[{"artifacts":[{"app":["Docker.app"]}]}]} → [Docker]
+// Edge cases: Renamed apps ({"target": "..."} entries) use the installed name
+func parseCaskApps(data []byte) []string {
+	var info struct {
+		Casks []struct {
+			Artifacts []map[string]json.RawMessage `json:"artifacts"`
+		} `json:"casks"`
+	}
+	if json.Unmarshal(data, &info) != nil {
+		return nil
+	}
+
+	var apps []string
+	add := func(name string) {
+		name = strings.TrimSuffix(name[strings.LastIndex(name, "/")+1:], ".app")
+		if name != "" && !slices.Contains(apps, name) {
+			apps = append(apps, name)
+		}
+	}
+	for _, cask := range info.Casks {
+		for _, artifact := range cask.Artifacts {
+			var entries []any
+			if json.Unmarshal(artifact["app"], &entries) != nil {
+				continue
+			}
+			for _, e := range entries {
+				switch v := e.(type) {
+				case string:
+					add(v)
+				case map[string]any:
+					if target, ok := v["target"].(string); ok {
+						// The source entry before it was already added; the target replaces it
+						if len(apps) > 0 {
+							apps = apps[:len(apps)-1]
+						}
+						add(target)
+					}
+				}
+			}
+		}
+	}
+	return apps
+}
+
+// appRunning reports whether any process runs from the app's bundle
+func appRunning(ctx context.Context, app string) bool {
+	pattern := "/" + regexp.QuoteMeta(app) + `\.app/Contents/MacOS/`
+	return exec.CommandContext(ctx, "pgrep", "-f", pattern).Run() == nil
+}
+
+// quitApp asks an app to quit and waits for it to exit
+// Returns: Error if the app is still running after appQuitTimeout (e.g. waiting on unsaved work)
+func quitApp(ctx context.Context, app string) error {
+	script := fmt.Sprintf("quit app %q", app)
+	if err := exec.CommandContext(ctx, "osascript", "-e", script).Run(); err != nil {
+		return fmt.Errorf("failed to quit %s: %w", app, err)
+	}
+
+	deadline := time.Now().Add(appQuitTimeout)
+	for appRunning(ctx, app) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s did not quit within %s (unsaved work?); quit it and rerun", app, appQuitTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(appPollInterval):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package installer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseCaskApps(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want []string
+	}{
+		{"single app", `{"casks":[{"token":"docker","artifacts":[{"uninstall":[{"quit":"com.docker.docker"}]},{"app":["Docker.app"]}]}]}`, []string{"Docker"}},
+		{"renamed app", `{"casks":[{"artifacts":[{"app":["Visual Studio Code.app",{"target":"VS Code.app"}]}]}]}`, []string{"VS Code"}},
+		{"no app artifact", `{"casks":[{"artifacts":[{"binary":["bin/tool"]}]}]}`, nil},
+		{"invalid", `not json`, nil},
+	}
+	for _, tt := range tests {
+		if got := parseCaskApps([]byte(tt.json)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: parseCaskApps() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
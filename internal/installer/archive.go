@@ -0,0 +1,412 @@
+// File: internal/installer/archive.go
+// Purpose: Built-in archive_install type (download, verify, extract, install binaries)
+// Problem: Configs embedded fragile curl | tar | mv | chmod pipelines with no checksum verification
+// Role: Installs tools declared with install.type: archive_install
+// Usage: Called by installTool when tool.Install.IsArchive()
+// Design choices: Pinned downloads cached under the state dir via the shared resumable downloader (unpinned ones are
+//                 fetched again every install, since nothing says a cached copy is still current); extraction in a
+//                 temp dir; only the listed binaries are copied to the target, unless dir keeps the whole tree
+//                 (binaries are then linked); symlinks are extracted only when they stay inside the archive, and no entry is
+//                 written or resolved through a symlink extracted before it (chained links can't lead outside)
+// Assumptions: Archives are .tar.gz/.tgz, .tar, or .zip
+
+package installer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/download"
+)
+
+// installArchive installs a tool from a downloadable archive
+// What: Downloads (resuming), verifies checksum, extracts with strip_components, copies binaries to target
+// Why: Declarative and verifiable alternative to shell pipelines
+// Params: ctx - context for timeout/cancellation, tool - tool with install.archive
+// Returns: Error if any step fails
+// Edge cases: With dir, the previous tree is replaced only after the new one extracted completely
+func (ti *ToolInstaller) installArchive(ctx context.Context, tool config.Tool) error {
+	archive := tool.Install.Archive
+	archiveURL, checksum := archive.DownloadFor(runtime.GOARCH)
+
+	cacheDir := filepath.Join(config.GetStateDir(), "downloads")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create download cache: %w", err)
+	}
+
+	name, err := archiveName(archiveURL)
+	if err != nil {
+		return err
+	}
+	archivePath := filepath.Join(cacheDir, tool.Name+"-"+name)
+
+	// Reuse a cached archive only if it is pinned and still matches the checksum
+	if _, err := os.Stat(archivePath); err != nil || checksum == "" || verifySHA256(archivePath, checksum) != nil {
+		_ = os.Remove(archivePath)
+		if err := download.New(nil).ToFile(ctx, archiveURL, archivePath); err != nil {
+			return fmt.Errorf("download failed: %w", err)
+		}
+	}
+
+	if err := verifySHA256(archivePath, checksum); err != nil {
+		_ = os.Remove(archivePath)
+		return err
+	}
+	if checksum == "" {
+		ti.ui.Warning("  ⚠️  %s archive is not pinned, so it is downloaded again every install (add archive.checksum, or checksums per architecture)", tool.Name)
+	}
+
+	// A kept tree is extracted next to its final place, so moving it there is a rename
+	tempParent := ""
+	if archive.Dir != "" {
+		tempParent = filepath.Dir(config.ExpandPath(archive.Dir))
+		if err := os.MkdirAll(tempParent, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", tempParent, err)
+		}
+	}
+	extractDir, err := os.MkdirTemp(tempParent, "devsetup-"+tool.Name+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create extract dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(extractDir) }()
+
+	if err := extractArchive(archivePath, extractDir, archive.StripComponents); err != nil {
+		return fmt.Errorf("extract failed: %w", err)
+	}
+	if archive.Dir != "" {
+		return ti.installArchiveDir(extractDir, archive)
+	}
+
+	target := archive.Target
+	if target == "" {
+		target = "~/.local/bin"
+	}
+	target = config.ExpandPath(target)
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return fmt.Errorf("failed to create target %s: %w", target, err)
+	}
+
+	for _, binary := range archive.Binaries {
+		src := filepath.Join(extractDir, filepath.FromSlash(binary))
+		dst := filepath.Join(target, path.Base(binary))
+		if err := installBinary(src, dst); err != nil {
+			return fmt.Errorf("failed to install %s: %w", binary, err)
+		}
+		ti.ui.Info("  Installed %s", dst)
+	}
+
+	return nil
+}
+
+// installArchiveDir moves an extracted tree to archive.dir and links its binaries into the target
+// Params: extracted - complete extracted tree, archive - install.archive with dir set
+// Returns: Error if a binary is missing or the move fails
+func (ti *ToolInstaller) installArchiveDir(extracted string, archive *config.ArchiveInstall) error {
+	for _, binary := range archive.Binaries {
+		if _, err := os.Stat(filepath.Join(extracted, filepath.FromSlash(binary))); err != nil {
+			return fmt.Errorf("failed to install %s: not found in archive: %w", binary, err)
+		}
+	}
+
+	dir := config.ExpandPath(archive.Dir)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", dir, err)
+	}
+	if err := os.Rename(extracted, dir); err != nil {
+		return fmt.Errorf("failed to move archive to %s: %w", dir, err)
+	}
+	ti.ui.Info("  Installed %s", dir)
+
+	target := archive.Target
+	if target == "" {
+		target = "~/.local/bin"
+	}
+	target = config.ExpandPath(target)
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return fmt.Errorf("failed to create target %s: %w", target, err)
+	}
+	for _, binary := range archive.Binaries {
+		dst := filepath.Join(target, path.Base(binary))
+		if err := replaceSymlink(filepath.Join(dir, filepath.FromSlash(binary)), dst); err != nil {
+			return fmt.Errorf("failed to link %s: %w", binary, err)
+		}
+		ti.ui.Info("  Linked %s", dst)
+	}
+	return nil
+}
+
+// archiveName returns the file name of an archive URL
+// Params: rawURL - archive URL
+// Returns: Last path element (e.g. "tool-1.2.3.tar.gz")
+func archiveName(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid archive url: %w", err)
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return "", fmt.Errorf("archive url has no file name: %s", rawURL)
+	}
+	return name, nil
+}
+
+// verifySHA256 checks a file against an expected checksum
+// Params: file - path to check, expected - "sha256:<hex>", bare hex, or empty (skip)
+// Returns: Error on mismatch or read failure
+func verifySHA256(file, expected string) error {
+	expected = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(expected), "sha256:"))
+	if expected == "" {
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return fmt.Errorf("failed to hash %s: %w", file, err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// extractArchive extracts a tar(.gz) or zip archive
+// Params: archivePath - archive file, dest - destination dir, strip - leading path elements to drop
+// Returns: Error on unsupported format or unsafe entries
+func extractArchive(archivePath, dest string, strip int) error {
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return extractZip(archivePath, dest, strip)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gz.Close() }()
+		return extractTar(gz, dest, strip)
+	case strings.HasSuffix(lower, ".tar"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		return extractTar(f, dest, strip)
+	}
+	return fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+}
+
+// extractTar extracts regular files, directories, and contained symlinks from a tar stream
+func extractTar(r io.Reader, dest string, strip int) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, ok, err := entryPath(dest, header.Name, strip)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, os.FileMode(header.Mode)&0777); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := writeSymlink(dest, target, header.Linkname); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// extractZip extracts regular files and directories from a zip file
+func extractZip(archivePath, dest string, strip int) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+
+	for _, f := range zr.File {
+		target, ok, err := entryPath(dest, f.Name, strip)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(target, rc, f.Mode()&0777)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryPath maps an archive entry to a path under dest
+// What: Applies strip_components and rejects entries escaping dest (zip slip) or passing through an extracted symlink
+// Returns: Target path, false if the entry is stripped away entirely, error if unsafe
+func entryPath(dest, name string, strip int) (string, bool, error) {
+	parts := strings.Split(strings.Trim(path.Clean("/"+name), "/"), "/")
+	if len(parts) <= strip || (len(parts) == 1 && parts[0] == "") {
+		return "", false, nil
+	}
+
+	target := filepath.Join(dest, filepath.FromSlash(path.Join(parts[strip:]...)))
+	if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) || throughSymlink(dest, target) {
+		return "", false, fmt.Errorf("unsafe path in archive: %s", name)
+	}
+	return target, true, nil
+}
+
+// throughSymlink reports whether a path under dest is, or is inside, a symlink extracted earlier
+// Why: Writing through such a link would land wherever the link (or a chain of them) really points
+func throughSymlink(dest, target string) bool {
+	rel, err := filepath.Rel(dest, target)
+	if err != nil {
+		return true
+	}
+	cur := dest
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		cur = filepath.Join(cur, part)
+		if info, err := os.Lstat(cur); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSymlink creates a relative symlink found in an archive (node's bin/npm → ../lib/node_modules/npm/bin/npm-cli.js)
+// Edge cases: Absolute links, links leading out of dest, and links resolved through another link are skipped, like
+// other special entries
+func writeSymlink(dest, target, linkname string) error {
+	if !linkInside(dest, filepath.Dir(target), linkname) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(linkname, target)
+}
+
+// linkInside reports whether a relative link in dir resolves inside dest
+// What: Follows the link one element at a time, so ".." is applied where the link really is; an element that is
+// itself an extracted symlink (other than the last) makes the link unsafe, since its real target isn't the text
+func linkInside(dest, dir, linkname string) bool {
+	if filepath.IsAbs(linkname) {
+		return false
+	}
+	root := filepath.Clean(dest)
+	parts := strings.Split(filepath.FromSlash(linkname), string(os.PathSeparator))
+	cur := dir
+	for i, part := range parts {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			cur = filepath.Dir(cur)
+		default:
+			cur = filepath.Join(cur, part)
+		}
+		if cur != root && !strings.HasPrefix(cur, root+string(os.PathSeparator)) {
+			return false
+		}
+		if info, err := os.Lstat(cur); i < len(parts)-1 && err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return false
+		}
+	}
+	return cur != root
+}
+
+// writeFile writes r to path, creating parent directories
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if mode == 0 {
+		mode = 0644
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// installBinary copies an extracted binary into place and makes it executable
+// Params: src - extracted file, dst - destination path
+// Returns: Error if src is missing or the copy fails
+// Edge cases: Writes to a temp file and renames so a running binary is replaced atomically
+func installBinary(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("not found in archive: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	tmp := dst + ".devsetup-tmp"
+	if err := writeFile(tmp, in, 0755); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,84 @@
+package installer
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestExtractTarChainedSymlinks(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "extract")
+	if err := os.Mkdir(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each link's text stays inside dest, but d/e is dest/d, so f would really be dest (and f/.. outside it)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, link := range [][2]string{{"d/e", "../d"}, {"f", "d/e/.."}, {"bin/tool", "../d/e"}} {
+		_ = tw.WriteHeader(&tar.Header{Name: link[0], Linkname: link[1], Typeflag: tar.TypeSymlink})
+	}
+	_ = tw.Close()
+	if err := extractTar(bytes.NewReader(buf.Bytes()), dest, 0); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"d/e": true, "f": false, "bin/tool": true} {
+		if _, err := os.Lstat(filepath.Join(dest, name)); (err == nil) != want {
+			t.Errorf("%s extracted = %v, want %v", name, err == nil, want)
+		}
+	}
+
+	// A file written through an extracted link is refused
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	_ = tw.WriteHeader(&tar.Header{Name: "d/e/evil", Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+	_, _ = tw.Write([]byte("x"))
+	_ = tw.Close()
+	if err := extractTar(bytes.NewReader(buf.Bytes()), dest, 0); err == nil {
+		t.Error("extractTar wrote through an extracted symlink")
+	}
+}
+
+func TestUnpinnedArchiveNotReusedFromCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	release := "v1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		_ = tw.WriteHeader(&tar.Header{Name: "tool", Mode: 0755, Size: int64(len(release)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(release))
+		_ = tw.Close()
+		_ = gz.Close()
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	target := t.TempDir()
+	tool := config.Tool{Name: "tool", Install: config.ToolInstall{Type: config.InstallTypeArchive, Archive: &config.ArchiveInstall{
+		URL: server.URL + "/tool.tar.gz", Target: target, Binaries: []string{"tool"},
+	}}}
+	ti := NewToolInstaller(&config.ToolsConfig{Tools: []config.Tool{tool}}, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+
+	for _, want := range []string{"v1", "v2"} {
+		release = want
+		if err := ti.installArchive(context.Background(), tool); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(target, "tool")); string(got) != want {
+			t.Errorf("installed %q, want %q (stale cached archive reused)", got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// File: internal/installer/artifact.go
+// Purpose: Office-local Homebrew artifact cache detection (HOMEBREW_ARTIFACT_DOMAIN)
+// Problem: Provisioning many laptops in one office downloaded every bottle from upstream, per laptop
+// Role: Probes the caches listed in tools.yaml, then (with discover: true) allowlisted caches advertised over Bonjour,
+//       and points brew install commands at the first reachable one
+// Usage: Runs at the start of InstallAll when tools.yaml has a homebrew_cache section
+// Design choices: Any HTTP response counts as reachable (cache roots often 404); Homebrew itself falls back to
+//                 upstream when the cache lacks an artifact, so a stale cache never breaks installs; an
+//                 HOMEBREW_ARTIFACT_DOMAIN already set by the user always wins; Bonjour discovery reuses share's
+//                 dns-sd browsing, and listed domains go first so an office can pin its preferred cache; Bonjour
+//                 answers are unauthenticated (anyone on the LAN can advertise, and casks with sha256 :no_check
+//                 install whatever the cache serves), so a discovered cache is used only when its host is one of
+//                 artifact_domains' hosts or discover_hosts
+// Assumptions: ".local" cache hosts resolve through the system resolver (mDNS on macOS); advertised caches serve
+//              plain HTTP at the advertised host and port
+
+package installer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/share"
+)
+
+// defaultProbeTimeout bounds each cache probe when tools.yaml sets none
+const defaultProbeTimeout = 2 * time.Second
+
+// cacheServiceType is the Bonjour service type office Homebrew caches advertise (homebrew_cache.discover)
+const cacheServiceType = "_homebrew-cache._tcp"
+
+// detectArtifactDomain selects the Homebrew artifact cache for this run
+// What: Probes homebrew_cache.artifact_domains in order, then allowlisted caches found via Bonjour (discover: true),
+// and remembers the first reachable one
+// Why: Install commands then run with HOMEBREW_ARTIFACT_DOMAIN set (see installEnv)
+// Edge cases: No reachable cache (or none configured) leaves brew on upstream; a failed Bonjour browse only warns;
+// a discovered cache on a host that is not allowlisted is skipped with a warning
+func (ti *ToolInstaller) detectArtifactDomain() {
+	cache := ti.toolsConfig.HomebrewCache
+	if cache == nil || (len(cache.ArtifactDomains) == 0 && !cache.Discover) {
+		return
+	}
+	if existing := os.Getenv("HOMEBREW_ARTIFACT_DOMAIN"); existing != "" {
+		ti.ui.Info("🏢 Homebrew artifact domain: %s (from environment)", existing)
+		return
+	}
+
+	timeout := cache.ProbeTimeout
+	if timeout == 0 {
+		timeout = defaultProbeTimeout
+	}
+	for _, domain := range cache.ArtifactDomains {
+		if reachable(domain, timeout) {
+			ti.artifactDomain = domain
+			ti.ui.Info("🏢 Using office Homebrew cache: %s", domain)
+			return
+		}
+	}
+	if cache.Discover {
+		found, err := share.Browse(context.Background(), cacheServiceType, timeout)
+		if err != nil {
+			ti.ui.Warning("⚠️  Bonjour discovery of Homebrew caches failed: %v", err)
+		}
+		allowed := cache.AllowedHosts()
+		for _, domain := range found {
+			if !hostAllowed(domain, allowed) {
+				ti.ui.Warning("⚠️  Ignoring Homebrew cache %s found via Bonjour: host not in artifact_domains or discover_hosts", domain)
+				continue
+			}
+			if reachable(domain, timeout) {
+				ti.artifactDomain = domain
+				ti.ui.Info("🏢 Using office Homebrew cache: %s (found via Bonjour)", domain)
+				return
+			}
+		}
+	}
+	ti.ui.Info("Homebrew cache not reachable, downloading from upstream")
+}
+
+// installEnv returns the environment for install commands
+// Returns: os.Environ plus HOMEBREW_ARTIFACT_DOMAIN when an office cache was detected
+func (ti *ToolInstaller) installEnv() []string {
+	env := os.Environ()
+	if ti.artifactDomain != "" {
+		env = append(env, "HOMEBREW_ARTIFACT_DOMAIN="+ti.artifactDomain)
+	}
+	return env
+}
+
+// hostAllowed reports whether a discovered cache URL's host is one of allowed (case-insensitive, trailing dot ignored)
+func hostAllowed(rawURL string, allowed []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, h := range allowed {
+		if host != "" && host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// reachable reports whether a cache URL answers HTTP within timeout
+func reachable(url string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
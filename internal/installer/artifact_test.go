@@ -0,0 +1,86 @@
+package installer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestDetectArtifactDomain(t *testing.T) {
+	t.Setenv("HOMEBREW_ARTIFACT_DOMAIN", "")
+
+	cache := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r) // cache roots often 404; still reachable
+	}))
+	defer cache.Close()
+
+	cfg := &config.ToolsConfig{HomebrewCache: &config.HomebrewCacheConfig{
+		ArtifactDomains: []string{"http://127.0.0.1:1", cache.URL},
+	}}
+	ti := NewToolInstaller(cfg, &config.State{}, ui.NewEventUI(io.Discard, nil), true, "test")
+	ti.detectArtifactDomain()
+
+	if ti.artifactDomain != cache.URL {
+		t.Fatalf("artifactDomain = %q, want %q", ti.artifactDomain, cache.URL)
+	}
+	if !slices.Contains(ti.installEnv(), "HOMEBREW_ARTIFACT_DOMAIN="+cache.URL) {
+		t.Error("install environment lacks HOMEBREW_ARTIFACT_DOMAIN")
+	}
+}
+
+func TestDetectArtifactDomainViaBonjour(t *testing.T) {
+	t.Setenv("HOMEBREW_ARTIFACT_DOMAIN", "")
+
+	cache := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer cache.Close()
+
+	// dns-sd stub: browsing lists one cache instance, resolving it points at the test server
+	bin := t.TempDir()
+	stub := `#!/bin/sh
+case "$1" in
+-B) echo "10:00:00.123  Add        2   4 local.               _homebrew-cache._tcp. office cache" ;;
+-L) echo "office\\032cache._homebrew-cache._tcp.local. can be reached at ` + strings.TrimPrefix(cache.URL, "http://") + ` (interface 4)" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "dns-sd"), []byte(stub), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name            string
+		artifactDomains []string
+		discoverHosts   []string
+		want            string
+	}{
+		{"host in discover_hosts", nil, []string{"127.0.0.1"}, cache.URL},
+		{"host of an artifact domain", []string{"http://127.0.0.1:1"}, nil, cache.URL},
+		{"unlisted host", nil, []string{"brew-cache.local"}, ""},
+		{"no allowlist", nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.ToolsConfig{HomebrewCache: &config.HomebrewCacheConfig{
+				ArtifactDomains: tt.artifactDomains,
+				Discover:        true,
+				DiscoverHosts:   tt.discoverHosts,
+				ProbeTimeout:    200 * time.Millisecond,
+			}}
+			ti := NewToolInstaller(cfg, &config.State{}, ui.NewEventUI(io.Discard, nil), true, "test")
+			ti.detectArtifactDomain()
+
+			if ti.artifactDomain != tt.want {
+				t.Errorf("artifactDomain = %q, want %q", ti.artifactDomain, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// File: internal/installer/bootstrap.go
+// Purpose: Bootstrap of Homebrew itself, the first tool every other install depends on
+// Problem: Homebrew was installed like any other script: its installer stopped at "Press RETURN" prompts, one
+//          GitHub hiccup failed the whole run, and on Apple Silicon brew wasn't on PATH afterwards, so every
+//          following `brew install` failed with "command not found" until the user opened a new shell
+// Role: Runs the official installer non-interactively with retries, then applies `brew shellenv` to this process
+//       so later tasks see brew immediately; also activates an existing Homebrew that isn't on PATH yet
+// Usage: installScript hands the official installer to bootstrapHomebrew; installAll calls ActivateHomebrew first
+// Design choices: Same download, checksum pin, and inspect path as other scripts (fetchScript); sudo is cached up
+//                 front because the installer refuses to prompt when NONINTERACTIVE=1; only failures that look like
+//                 network errors are retried, never checksum or permission failures; shellenv is evaluated by a
+//                 real shell rather than parsed, so new brew versions that change its output keep working
+// Assumptions: Homebrew uses its default prefix for the architecture (see pathmgr.BrewPrefix); the cached sudo
+//              timestamp outlasts the installer's own sudo calls (macOS default is 5 minutes, renewed on use)
+
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/pathmgr"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// Retry policy for the Homebrew installer
+const (
+	bootstrapAttempts = 3
+	bootstrapBackoff  = 10 * time.Second
+)
+
+// intelBrew is where Intel Homebrew lives; on Apple Silicon it only runs under Rosetta
+const intelBrew = "/usr/local/bin/brew"
+
+// transientPatterns mark installer failures caused by the network (mostly GitHub) rather than the machine
+var transientPatterns = []string{
+	"could not resolve host",
+	"failed to connect",
+	"connection reset",
+	"connection timed out",
+	"operation timed out",
+	"timed out after",
+	"early eof",
+	"rpc failed",
+	"unexpected disconnect",
+	"the requested url returned error: 5",
+	"the requested url returned error: 429",
+	"ssl_error",
+	"curl: (18)",
+	"curl: (56)",
+}
+
+// shellenvVars are the variables `brew shellenv` sets
+var shellenvVars = []string{"PATH", "MANPATH", "INFOPATH", "HOMEBREW_PREFIX", "HOMEBREW_CELLAR", "HOMEBREW_REPOSITORY"}
+
+// BrewBinary returns where brew is installed for this Mac's architecture
+// Returns: /opt/homebrew/bin/brew on Apple Silicon, /usr/local/bin/brew on Intel
+func BrewBinary() string {
+	return filepath.Join(pathmgr.BrewPrefix(), "bin", "brew")
+}
+
+// ActivateHomebrew puts an installed Homebrew on this process's PATH
+// What: If brew exists at the default prefix but isn't on PATH, applies `brew shellenv` to the process environment
+// Why: On Apple Silicon a fresh Homebrew (or one installed by an earlier, interrupted run) isn't on PATH until the shell is reloaded; install commands and checks inherit this process's environment
+// Params: ctx - bounds the shellenv call
+// Returns: Variables changed (empty if Homebrew is absent or already on PATH), error if shellenv fails
+func ActivateHomebrew(ctx context.Context) ([]string, error) {
+	brew := BrewBinary()
+	if _, err := os.Stat(brew); err != nil {
+		return nil, nil
+	}
+	if found, err := exec.LookPath("brew"); err == nil && found == brew {
+		return nil, nil
+	}
+	return applyShellenv(ctx, brew)
+}
+
+// activateExistingHomebrew runs ActivateHomebrew at the start of an install, warning if it fails
+func (ti *ToolInstaller) activateExistingHomebrew() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	changed, err := ActivateHomebrew(ctx)
+	if err != nil {
+		ti.ui.Warning("⚠️  Could not activate Homebrew at %s: %v", pathmgr.BrewPrefix(), err)
+		return
+	}
+	if len(changed) > 0 {
+		ti.ui.Info("🍺 Homebrew at %s was not on PATH; activated it for this run", pathmgr.BrewPrefix())
+	}
+}
+
+// bootstrapHomebrew installs Homebrew with the official installer
+// What: Downloads and verifies the script, caches sudo, runs it with NONINTERACTIVE=1 and CI=1 (retrying network failures), then activates brew for the rest of the run
+// Why: The first tool has to work unattended and leave brew usable by every tool after it
+// Params: ctx - context for timeout and cancellation, tool - the homebrew tool
+// Returns: Error if the installer fails for good or brew is missing afterwards
+// Edge cases: An existing Homebrew at the default prefix (check failed only because it isn't on PATH) is activated, not reinstalled
+func (ti *ToolInstaller) bootstrapHomebrew(ctx context.Context, tool config.Tool) error {
+	brew := BrewBinary()
+	if _, err := os.Stat(brew); err == nil {
+		ti.ui.Info("  🍺 Homebrew is already installed at %s; adding it to PATH", pathmgr.BrewPrefix())
+		return ti.activateHomebrew(ctx, brew)
+	}
+	if _, err := os.Stat(intelBrew); err == nil && runtime.GOARCH == "arm64" {
+		ti.ui.Warning("  ⚠️  Found Intel Homebrew at /usr/local (runs under Rosetta); installing native Homebrew to %s", pathmgr.BrewPrefix())
+	}
+
+	scriptPath, err := ti.fetchScript(ctx, tool)
+	if err != nil {
+		return err
+	}
+	if err := ti.cacheSudo(ctx, "Homebrew"); err != nil {
+		return err
+	}
+
+	env := append(ti.installEnv(), "NONINTERACTIVE=1", "CI=1")
+	backoff := bootstrapBackoff
+	for attempt := 1; ; attempt++ {
+		err = ti.runScript(ctx, tool, scriptPath, env)
+		if err == nil || attempt == bootstrapAttempts || !isTransient(err) {
+			break
+		}
+		ti.ui.Warning("  ⚠️  Homebrew install hit a network error (attempt %d/%d), retrying in %s", attempt, bootstrapAttempts, backoff)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(brew); err != nil {
+		return fmt.Errorf("Homebrew installer finished but %s is missing", brew)
+	}
+	return ti.activateHomebrew(ctx, brew)
+}
+
+// activateHomebrew applies shellenv and reports what changed
+func (ti *ToolInstaller) activateHomebrew(ctx context.Context, brew string) error {
+	changed, err := applyShellenv(ctx, brew)
+	if err != nil {
+		return err
+	}
+	if len(changed) > 0 {
+		ti.ui.Info("  🍺 Activated Homebrew for this run (%s)", strings.Join(changed, ", "))
+	}
+	return nil
+}
+
+// cacheSudo makes sure sudo won't prompt during the installer
+// What: Checks for a cached sudo timestamp; otherwise runs `sudo -v` on the terminal
+// Why: With NONINTERACTIVE=1 the Homebrew installer aborts instead of asking for a password
+// Params: ctx - bounds sudo, what - what is being installed, for the prompt ("Homebrew")
+// Returns: Error if the user can't or doesn't authenticate
+func (ti *ToolInstaller) cacheSudo(ctx context.Context, what string) error {
+	if exec.CommandContext(ctx, "sudo", "-n", "-v").Run() == nil {
+		return nil
+	}
+
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	ti.ui.Info("  🔑 Installing %s needs administrator access; enter your login password", what)
+	cmd := exec.CommandContext(ctx, "sudo", "-v")
+	cmd.Stdin = prompt.Stdin()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("administrator access is required to install %s: %w", what, err)
+	}
+	return nil
+}
+
+// applyShellenv evaluates `brew shellenv` in a shell and copies the result into this process's environment
+// Params: ctx - bounds the shell, brew - brew binary
+// Returns: Names of the variables that changed, error if the shell fails
+func applyShellenv(ctx context.Context, brew string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// SHELL picks the output syntax on brew versions that don't take a shell argument
+	cmd := exec.CommandContext(ctx, "/bin/bash", "-c", `eval "$("$0" shellenv)" && env -0`, brew)
+	cmd.Env = append(os.Environ(), "SHELL=/bin/bash")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("brew shellenv failed: %w", err)
+	}
+
+	var changed []string
+	for _, kv := range strings.Split(string(out), "\x00") {
+		name, value, ok := strings.Cut(kv, "=")
+		if name == "PATH" {
+			value = dedupePath(value)
+		}
+		if !ok || !slices.Contains(shellenvVars, name) || os.Getenv(name) == value {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return changed, err
+		}
+		changed = append(changed, name)
+	}
+	return changed, nil
+}
+
+// dedupePath drops repeated PATH entries, keeping the first (older brew versions prepend unconditionally)
+func dedupePath(path string) string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(path) {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return strings.Join(dirs, string(os.PathListSeparator))
+}
+
+// isTransient reports whether an installer failure looks like a network error worth retrying
+func isTransient(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range transientPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
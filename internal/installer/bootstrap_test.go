@@ -0,0 +1,60 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestApplyShellenv(t *testing.T) {
+	prefix := t.TempDir()
+	brew := filepath.Join(prefix, "brew")
+	// Mimics brew shellenv output, including its ${PATH+:$PATH} expansion
+	script := "#!/bin/sh\n" +
+		"echo 'export HOMEBREW_PREFIX=\"" + prefix + "\";'\n" +
+		"echo 'export PATH=\"" + prefix + "/bin${PATH+:$PATH}\";'\n"
+	if err := os.WriteFile(brew, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", os.Getenv("PATH"))
+	t.Setenv("HOMEBREW_PREFIX", "")
+
+	changed, err := applyShellenv(context.Background(), brew)
+	if err != nil {
+		t.Fatalf("applyShellenv: %v", err)
+	}
+	slices.Sort(changed)
+	if !slices.Equal(changed, []string{"HOMEBREW_PREFIX", "PATH"}) {
+		t.Errorf("changed = %v, want [HOMEBREW_PREFIX PATH]", changed)
+	}
+	if os.Getenv("HOMEBREW_PREFIX") != prefix {
+		t.Errorf("HOMEBREW_PREFIX = %q", os.Getenv("HOMEBREW_PREFIX"))
+	}
+	if !strings.HasPrefix(os.Getenv("PATH"), prefix+"/bin:") {
+		t.Errorf("PATH = %q, want %s/bin first", os.Getenv("PATH"), prefix)
+	}
+
+	// Applying again changes nothing
+	if changed, _ := applyShellenv(context.Background(), brew); len(changed) != 0 {
+		t.Errorf("second apply changed %v", changed)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	cases := map[string]bool{
+		"installer script failed: exit status 1 (curl: (6) Could not resolve host: github.com)": true,
+		"installer script failed: exit status 128 (error: RPC failed; curl 92 HTTP/2 stream)":   true,
+		"installer script failed: exit status 1 (The requested URL returned error: 502)":        true,
+		"installer script failed: exit status 1 (Need sudo access on macOS)":                    false,
+		"installer script https://x/install.sh: checksum mismatch":                              false,
+	}
+	for msg, want := range cases {
+		if got := isTransient(errors.New(msg)); got != want {
+			t.Errorf("isTransient(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
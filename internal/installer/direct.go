@@ -0,0 +1,224 @@
+// File: internal/installer/direct.go
+// Purpose: Degraded install without Homebrew (`devsetup install --without-brew`) and its later reconciliation
+// Problem: When brew install was blocked, the required homebrew tool failed and nothing after it ran, so a new
+//          developer couldn't start coding until Homebrew worked
+// Role: Swaps tools to their direct: installs (see config.WithoutHomebrew), installs the dmg and clt types, records
+//       direct installs in state, and on the next normal run replaces them with the Homebrew install
+// Usage: installer.SetWithoutHomebrew(true); installer.InstallAll()
+// Design choices: Direct files go under the user's home so no admin rights are needed (clt excepted); a later normal
+//                 run reinstalls with brew even though the tool's check passes, then deletes only the files the
+//                 direct install recorded, so one PATH entry can't shadow the other
+// Assumptions: hdiutil, ditto, xcode-select, and softwareupdate are present (stock macOS)
+
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/download"
+)
+
+// cltInProgress makes softwareupdate list the Command Line Tools (what `xcode-select --install` does behind its dialog)
+const cltInProgress = "/tmp/.com.apple.dt.CommandLineTools.installondemand.in-progress"
+
+// cltLabelPattern finds the Command Line Tools label in `softwareupdate -l` output
+var cltLabelPattern = regexp.MustCompile(`(?m)^\s*\* (?:Label: )?(Command Line Tools for Xcode-[^\n]+?)\s*$`)
+
+// SetWithoutHomebrew runs the degraded install: Homebrew tools use their direct: install or are deferred
+// Params: without - true for `install --without-brew`
+func (ti *ToolInstaller) SetWithoutHomebrew(without bool) {
+	ti.withoutHomebrew = without
+}
+
+// applyWithoutHomebrew reduces the tools config for a --without-brew run and reports the switch
+// Edge cases: No-op for a normal run; ~/.local/bin is put on this process's PATH so checks find the direct installs
+func (ti *ToolInstaller) applyWithoutHomebrew() {
+	if !ti.withoutHomebrew {
+		return
+	}
+	reduced, direct, deferred := config.WithoutHomebrew(ti.toolsConfig)
+	ti.toolsConfig = reduced
+	ti.direct = make(map[string]bool, len(direct))
+	for _, name := range direct {
+		ti.direct[name] = true
+	}
+
+	ti.ui.Info("🧰 Installing without Homebrew")
+	if len(direct) > 0 {
+		ti.ui.Info("   Direct installs: %s", strings.Join(direct, ", "))
+	}
+	if len(deferred) > 0 {
+		ti.ui.Info("   Waiting for Homebrew: %s (run 'devsetup install' once brew works)", strings.Join(deferred, ", "))
+	}
+	ti.ui.Info("")
+
+	bin := config.ExpandPath("~/.local/bin")
+	if !strings.Contains(":"+os.Getenv("PATH")+":", ":"+bin+":") {
+		_ = os.Setenv("PATH", bin+":"+os.Getenv("PATH"))
+	}
+}
+
+// replacesDirect reports whether a normal run should reinstall a tool that was installed without Homebrew
+func (ti *ToolInstaller) replacesDirect(tool config.Tool) bool {
+	if ti.withoutHomebrew || !tool.Install.UsesHomebrew() {
+		return false
+	}
+	ti.stateMu.Lock()
+	defer ti.stateMu.Unlock()
+	return ti.state.Installed[tool.Name].Direct
+}
+
+// removeDirect deletes what a tool's direct install placed, now that Homebrew's install replaced it
+// Params: tool - tool just installed with Homebrew
+func (ti *ToolInstaller) removeDirect(tool config.Tool) {
+	ti.stateMu.Lock()
+	paths := ti.state.Installed[tool.Name].DirectPaths
+	ti.stateMu.Unlock()
+
+	for _, p := range paths {
+		if err := os.RemoveAll(p); err != nil {
+			ti.ui.Warning("⚠️  Failed to remove %s: %v", p, err)
+			continue
+		}
+		ti.ui.Info("  Removed direct install %s", p)
+	}
+}
+
+// installDMG installs an app bundle from a disk image
+// What: Downloads (resuming) and verifies the image, mounts it read-only, copies the app to the target, links its CLIs
+// Why: Editors ship as DMGs; this is what a cask install does, minus Homebrew
+// Params: ctx - context for timeout/cancellation, tool - tool with install.dmg
+// Returns: Error if any step fails
+// Edge cases: An existing copy of the app is replaced; the image is detached even when the copy fails
+func (ti *ToolInstaller) installDMG(ctx context.Context, tool config.Tool) error {
+	dmg := tool.Install.DMG
+	url, checksum := dmg.DownloadFor(runtime.GOARCH)
+
+	cacheDir := filepath.Join(config.GetStateDir(), "downloads")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create download cache: %w", err)
+	}
+	name, err := archiveName(url)
+	if err != nil {
+		return err
+	}
+	image := filepath.Join(cacheDir, tool.Name+"-"+name)
+
+	// Reuse a cached image only if it still matches the checksum
+	if _, err := os.Stat(image); err != nil || verifySHA256(image, checksum) != nil {
+		_ = os.Remove(image)
+		if err := download.New(nil).ToFile(ctx, url, image); err != nil {
+			return fmt.Errorf("download failed: %w", err)
+		}
+	}
+	if err := verifySHA256(image, checksum); err != nil {
+		_ = os.Remove(image)
+		return err
+	}
+
+	mount, err := os.MkdirTemp("", "devsetup-"+tool.Name+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create mount point: %w", err)
+	}
+	defer func() { _ = os.Remove(mount) }()
+
+	attach := exec.CommandContext(ctx, "hdiutil", "attach", "-nobrowse", "-readonly", "-noautoopen", "-mountpoint", mount, image)
+	if out, err := attach.CombinedOutput(); err != nil {
+		return fmt.Errorf("hdiutil attach failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	defer func() { _ = exec.Command("hdiutil", "detach", "-quiet", "-force", mount).Run() }()
+
+	target := config.ExpandPath(dmg.Target)
+	if dmg.Target == "" {
+		target = config.ExpandPath("~/Applications")
+	}
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return fmt.Errorf("failed to create target %s: %w", target, err)
+	}
+	app := filepath.Join(target, dmg.App)
+	if err := os.RemoveAll(app); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", app, err)
+	}
+	if out, err := exec.CommandContext(ctx, "ditto", filepath.Join(mount, dmg.App), app).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to copy %s: %w: %s", dmg.App, err, strings.TrimSpace(string(out)))
+	}
+	ti.ui.Info("  Installed %s", app)
+
+	bin := config.ExpandPath("~/.local/bin")
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", bin, err)
+	}
+	for link, inside := range dmg.Links {
+		if err := replaceSymlink(filepath.Join(app, filepath.FromSlash(inside)), filepath.Join(bin, link)); err != nil {
+			return fmt.Errorf("failed to link %s: %w", link, err)
+		}
+		ti.ui.Info("  Linked %s", filepath.Join(bin, link))
+	}
+	return nil
+}
+
+// installCLT installs the Xcode Command Line Tools (git, make, clang) without the GUI dialog
+// What: Asks softwareupdate for the newest Command Line Tools label and installs it with sudo
+// Why: `xcode-select --install` opens a dialog and returns at once, so nothing can wait for it
+// Params: ctx - context for timeout/cancellation, tool - tool with type clt
+// Returns: Error if no label is offered or the install fails
+// Edge cases: Already-installed tools (xcode-select -p succeeds) are left alone
+func (ti *ToolInstaller) installCLT(ctx context.Context, tool config.Tool) error {
+	if exec.CommandContext(ctx, "xcode-select", "-p").Run() == nil {
+		ti.ui.Info("  Command Line Tools are already installed")
+		return nil
+	}
+
+	if err := os.WriteFile(cltInProgress, nil, 0644); err != nil {
+		return fmt.Errorf("failed to request Command Line Tools: %w", err)
+	}
+	defer func() { _ = os.Remove(cltInProgress) }()
+
+	ti.ui.Info("  Looking up Command Line Tools in Software Update...")
+	out, err := exec.CommandContext(ctx, "softwareupdate", "-l").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("softwareupdate -l failed: %w", err)
+	}
+	label := cltLabel(string(out))
+	if label == "" {
+		return fmt.Errorf("no Command Line Tools offered by Software Update; run 'xcode-select --install'")
+	}
+
+	if err := ti.cacheSudo(ctx, "the Command Line Tools"); err != nil {
+		return err
+	}
+	ti.ui.Info("  Installing %s...", label)
+	cmd := exec.CommandContext(ctx, "sudo", "softwareupdate", "-i", label, "--verbose")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("softwareupdate failed: %w", err)
+	}
+	return nil
+}
+
+// cltLabel returns the last (newest) Command Line Tools label in `softwareupdate -l` output, "" if none
+// Example: "* Label: Command Line Tools for Xcode-16.0" → "Command Line Tools for Xcode-16.0"
+func cltLabel(list string) string {
+	matches := cltLabelPattern.FindAllStringSubmatch(list, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1][1]
+}
+
+// replaceSymlink points link at target, replacing whatever is at link
+func replaceSymlink(target, link string) error {
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(target, link)
+}
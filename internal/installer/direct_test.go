@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestDirectInstallReplacedByHomebrew(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", os.Getenv("PATH")) // applyWithoutHomebrew adds ~/.local/bin
+
+	// node-v1-darwin-arm64.tar.gz: node-v1/bin/node, node-v1/bin/npm -> ../lib/npm-cli.js
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct{ name, body, link string }{
+		{name: "node-v1/bin/node", body: "#!/bin/sh\necho v1\n"},
+		{name: "node-v1/lib/npm-cli.js", body: "npm\n"},
+		{name: "node-v1/bin/npm", link: "../lib/npm-cli.js"},
+		{name: "node-v1/bin/escape", link: "../../../etc/passwd"},
+	} {
+		hdr := &tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.body)), Typeflag: tar.TypeReg}
+		if f.link != "" {
+			hdr = &tar.Header{Name: f.name, Linkname: f.link, Typeflag: tar.TypeSymlink}
+		}
+		_ = tw.WriteHeader(hdr)
+		_, _ = tw.Write([]byte(f.body))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	tool := config.Tool{Name: "node", Check: "false", Install: config.ToolInstall{Command: "brew install node"},
+		Direct: &config.ToolInstall{Type: config.InstallTypeArchive, Archive: &config.ArchiveInstall{
+			URL:             server.URL + "/node-v1-darwin-{arch}.tar.gz",
+			StripComponents: 1,
+			Dir:             "~/.local/opt/node",
+			Binaries:        []string{"bin/node", "bin/npm"},
+		}}}
+	state := &config.State{}
+	tc := &config.ToolsConfig{Tools: []config.Tool{tool}}
+
+	ti := NewToolInstaller(tc, state, ui.NewEventUI(io.Discard, nil), false, "test")
+	ti.SetWithoutHomebrew(true)
+	ti.applyWithoutHomebrew()
+	if err := ti.installTool(context.Background(), ti.toolsConfig.Tools[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	npm := filepath.Join(home, ".local", "bin", "npm")
+	if data, err := os.ReadFile(npm); err != nil || string(data) != "npm\n" {
+		t.Errorf("npm link = %q, %v; want the archive's relative symlink resolved", data, err)
+	}
+	if _, err := os.Lstat(filepath.Join(home, ".local", "opt", "node", "bin", "escape")); !os.IsNotExist(err) {
+		t.Error("symlink leading out of the archive was extracted")
+	}
+	recorded := state.Installed["node"]
+	if !recorded.Direct || len(recorded.DirectPaths) != 3 {
+		t.Fatalf("state = %+v, want direct with node, npm, and the kept dir", recorded)
+	}
+
+	// The next normal install reinstalls with Homebrew even though node is on PATH, then removes the direct files
+	normal := NewToolInstaller(tc, state, ui.NewEventUI(io.Discard, nil), false, "test")
+	if !normal.replacesDirect(tool) {
+		t.Fatal("replacesDirect() = false for a direct install")
+	}
+	normal.removeDirect(tool)
+	for _, p := range recorded.DirectPaths {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			t.Errorf("%s not removed", p)
+		}
+	}
+}
+
+func TestCLTLabel(t *testing.T) {
+	list := `Software Update found the following new or updated software:
+* Label: Command Line Tools for Xcode-15.3
+	Title: Command Line Tools for Xcode, Version: 15.3, Size: 707501KiB, Recommended: YES,
+* Label: Command Line Tools for Xcode-16.0
+	Title: Command Line Tools for Xcode, Version: 16.0, Size: 751274KiB, Recommended: YES,
+`
+	if got := cltLabel(list); got != "Command Line Tools for Xcode-16.0" {
+		t.Errorf("cltLabel() = %q", got)
+	}
+	if got := cltLabel("No new software available.\n"); got != "" {
+		t.Errorf("cltLabel() without updates = %q", got)
+	}
+}
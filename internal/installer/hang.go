@@ -0,0 +1,80 @@
+// File: internal/installer/hang.go
+// Purpose: Hung install detection from install.expected_duration
+// Problem: A stuck installer (waiting on a hidden prompt, a dead mirror) sat silently until its timeout, often 30
+//          minutes later, with nothing telling the developer it was stuck
+// Role: Watches a running install; once it runs past 3× its expected duration while silent, flags it and, per
+//       install.on_hang, kills or retries it
+// Usage: stop := ti.watchHang(ctx, tool, out, kill); err := cmd.Run(); if stop() { err = errHung }
+// Design choices: "Silent" means no output for at least the expected duration, so a slow but chatty install (brew
+//                 pouring many bottles) is never flagged; the warning fires once per run
+// Assumptions: Installers print something while making progress; expected_duration reflects a normal run
+
+package installer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// hangFactor is how many expected durations an install may run before it can be flagged
+const hangFactor = 3
+
+// errHung marks an install killed by hung detection
+var errHung = errors.New("killed after going silent (possibly hung)")
+
+// watchHang monitors a running install for hangs
+// What: Polls the captured output; warns once when hung and calls kill if on_hang is kill or retry
+// Params: ctx - ends the watch, tool - tool being installed, out - its captured output, kill - stops the command
+// Returns: stop function that ends the watch and reports whether the install was killed as hung
+// Edge cases: Without expected_duration the watch does nothing
+func (ti *ToolInstaller) watchHang(ctx context.Context, tool config.Tool, out *capture.Output, kill context.CancelFunc) func() bool {
+	expected := tool.Install.ExpectedDuration
+	if expected <= 0 {
+		return func() bool { return false }
+	}
+
+	var killed atomic.Bool
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	started := time.Now()
+	interval := min(max(expected/4, time.Second), 10*time.Second)
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			silent := time.Since(out.LastOutput())
+			if time.Since(started) < hangFactor*expected || silent < expected {
+				continue
+			}
+
+			ti.ui.Warning("⏳ %s may be hung: no output for %s (usually takes ~%s); log: %s",
+				tool.Name, silent.Round(time.Second), expected, out.Path())
+			if tool.Install.OnHang == config.OnHangKill || tool.Install.OnHang == config.OnHangRetry {
+				killed.Store(true)
+				kill()
+			}
+			return
+		}
+	}()
+
+	return func() bool {
+		close(done)
+		<-finished
+		return killed.Load()
+	}
+}
@@ -0,0 +1,84 @@
+// File: internal/installer/quarantine.go
+// Purpose: Post-install quarantine handling for the files a tool installed
+// Problem: A freshly installed binary or app stopped on a Gatekeeper prompt the first time someone ran it
+// Role: After a successful install, applies the tool's quarantine action (policy-resolved) to its binaries and apps
+// Usage: Called by installTool once runInstall succeeds
+// Design choices: Failures and policy refusals only warn; the tool is installed either way and doctor reports
+//                 whatever stays blocked
+// Assumptions: Installed files are the archive binaries, the tool's apps, or its binary on PATH
+
+package installer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/quarantine"
+)
+
+// applyQuarantine applies the tool's quarantine action to what it installed
+// Params: ctx - install context, tool - tool just installed
+// Edge cases: keep (the default) does nothing; a disallowed action warns and keeps quarantine
+func (ti *ToolInstaller) applyQuarantine(ctx context.Context, tool config.Tool) {
+	action, err := ti.policies.QuarantineAction(tool.Quarantine)
+	if err != nil {
+		ti.ui.Warning("⚠️  %s: %v; keeping quarantine", tool.Name, err)
+	}
+	if action == config.QuarantineKeep {
+		return
+	}
+	for _, p := range ti.installedPaths(ctx, tool) {
+		if err := quarantine.Apply(ctx, action, p); err != nil {
+			ti.ui.Warning("⚠️  %v", err)
+		}
+	}
+}
+
+// installedPaths returns the files and app bundles a tool installed
+// Returns: Archive binaries in their target, a dmg's copied app, else the tool's apps and its binary on PATH (symlinks resolved)
+func (ti *ToolInstaller) installedPaths(ctx context.Context, tool config.Tool) []string {
+	if tool.Install.Type == config.InstallTypeDMG {
+		return tool.Install.Placed()[:1]
+	}
+	if tool.Install.IsArchive() {
+		target := tool.Install.Archive.Target
+		if target == "" {
+			target = "~/.local/bin"
+		}
+		var paths []string
+		for _, binary := range tool.Install.Archive.Binaries {
+			paths = append(paths, filepath.Join(config.ExpandPath(target), path.Base(binary)))
+		}
+		return paths
+	}
+
+	var paths []string
+	for _, app := range ti.toolApps(ctx, tool) {
+		if p := AppPath(app); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if out, err := exec.CommandContext(ctx, "sh", "-c", "command -v "+tool.Binary()).Output(); err == nil {
+		if p, err := filepath.EvalSymlinks(strings.TrimSpace(string(out))); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// AppPath returns where an app bundle is installed
+// Returns: /Applications/<app>.app or ~/Applications/<app>.app, "" if neither exists
+func AppPath(app string) string {
+	for _, dir := range []string{"/Applications", config.ExpandPath("~/Applications")} {
+		p := filepath.Join(dir, app+".app")
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
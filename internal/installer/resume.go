@@ -0,0 +1,110 @@
+// File: internal/installer/resume.go
+// Purpose: Resumes an install that failed or was interrupted after the tools it already finished
+// Problem: When a stage failed halfway, rerunning install went through every tool again: checks for the ones already
+//          there, and a full reinstall for tools without check markers
+// Role: Each tool an install finishes is recorded in state (CompletedTasks); the next install skips recorded tools
+//       until a full run succeeds and clears the record. SetForce (`install --force`) starts over
+// Usage: ti.SetForce(force); installAll calls ti.resume() before the first stage; installTool asks ti.resumed(tool)
+//        and calls ti.markCompleted(tool) once a tool is in place
+// Design choices: A record only counts while the tool's tools.yaml entry is unchanged (spec digest) and for
+//                 resumeWindow, so a bumped version or a machine left alone for days is checked again; denied
+//                 tools are refused before the shortcut, as with already-installed tools; filtered runs (--stages,
+//                 --only-group) add to the record but don't clear it
+// Assumptions: A tool that finished is still in place when the interrupted install is rerun
+
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// resumeWindow is how long a finished tool is skipped by later installs
+const resumeWindow = 24 * time.Hour
+
+// SetForce reruns every tool, ignoring the tools an interrupted install already finished
+// Params: force - true for `install --force`
+func (ti *ToolInstaller) SetForce(force bool) {
+	ti.force = force
+}
+
+// resume reports the tools this install takes over from an interrupted one
+// What: With --force, forgets them instead; dry runs report without changing state
+func (ti *ToolInstaller) resume() {
+	ti.stateMu.Lock()
+	defer ti.stateMu.Unlock()
+	if len(ti.state.CompletedTasks) == 0 {
+		return
+	}
+	if ti.force {
+		ti.ui.Info("--force: rerunning the %d tool(s) the last install finished", len(ti.state.CompletedTasks))
+		if !ti.dryRun {
+			config.ClearCompletedTasks(ti.state)
+		}
+		return
+	}
+	done, since := 0, time.Time{}
+	for _, tool := range ti.toolsConfig.Tools {
+		if ti.resumedLocked(tool) {
+			done++
+			if at := ti.state.CompletedTasks[tool.Name].At; since.IsZero() || at.Before(since) {
+				since = at
+			}
+		}
+	}
+	if done > 0 {
+		ti.ui.Info("↻ Resuming the install started %s: %d tool(s) already finished are skipped (--force reruns them)", since.Format("Jan 2 15:04"), done)
+		ti.ui.Info("")
+	}
+}
+
+// resumed reports whether an interrupted install finished the tool, so this one can skip it
+func (ti *ToolInstaller) resumed(tool config.Tool) bool {
+	ti.stateMu.Lock()
+	defer ti.stateMu.Unlock()
+	return ti.resumedLocked(tool)
+}
+
+// resumedLocked is resumed with stateMu held
+func (ti *ToolInstaller) resumedLocked(tool config.Tool) bool {
+	if ti.force {
+		return false
+	}
+	done, ok := ti.state.CompletedTasks[tool.Name]
+	return ok && done.Spec != "" && done.Spec == toolSpec(tool) && time.Since(done.At) < resumeWindow
+}
+
+// markCompleted records that this install finished the tool
+// Edge cases: Dry runs record nothing
+func (ti *ToolInstaller) markCompleted(tool config.Tool) {
+	if ti.dryRun {
+		return
+	}
+	ti.stateMu.Lock()
+	defer ti.stateMu.Unlock()
+	config.MarkTaskCompleted(ti.state, tool.Name, toolSpec(tool))
+}
+
+// finishRun forgets the finished tools once a full install succeeded
+// Edge cases: Filtered runs keep the record, since tools outside the filter haven't run
+func (ti *ToolInstaller) finishRun() {
+	if ti.filter.Empty() {
+		ti.stateMu.Lock()
+		config.ClearCompletedTasks(ti.state)
+		ti.stateMu.Unlock()
+	}
+}
+
+// toolSpec returns a digest of a tool's declaration
+// Returns: Hex SHA256 of the tool as JSON
+func toolSpec(tool config.Tool) string {
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
@@ -0,0 +1,106 @@
+package installer
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestResumeSkipsFinishedTools(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "installed")
+	tool := config.Tool{Name: "sdk", Install: config.ToolInstall{Command: "echo run >> " + marker}}
+	tc := &config.ToolsConfig{Tools: []config.Tool{tool}}
+	runs := func() int {
+		data, _ := os.ReadFile(marker)
+		return len(data) / len("run\n")
+	}
+
+	// The first install finishes the tool (it has no check, so only the record can skip it)
+	state := &config.State{}
+	if err := NewToolInstaller(tc, state, ui.NewEventUI(io.Discard, nil), false, "test").installTool(context.Background(), tool); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.CompletedTasks["sdk"]; !ok || runs() != 1 {
+		t.Fatalf("completed = %v after %d run(s), want sdk recorded after 1", state.CompletedTasks, runs())
+	}
+
+	v2 := func(tool *config.Tool, _ *config.State) { tool.Install.Command += " # v2" }
+	for _, tt := range []struct {
+		name  string
+		edit  func(*config.Tool, *config.State)
+		force bool
+		runs  int
+	}{
+		{name: "resumed", runs: 1},
+		{name: "record expired", edit: func(_ *config.Tool, s *config.State) {
+			done := s.CompletedTasks["sdk"]
+			done.At = done.At.Add(-resumeWindow)
+			s.CompletedTasks["sdk"] = done
+		}, runs: 2},
+		{name: "declaration changed", edit: v2, runs: 3},
+		{name: "resumed after the change", edit: v2, runs: 3},
+		{name: "forced", edit: v2, force: true, runs: 4},
+	} {
+		current := tool
+		if tt.edit != nil {
+			tt.edit(&current, state)
+		}
+		ti := NewToolInstaller(&config.ToolsConfig{Tools: []config.Tool{current}}, state, ui.NewEventUI(io.Discard, nil), false, "test")
+		ti.SetForce(tt.force)
+		if err := ti.installTool(context.Background(), current); err != nil {
+			t.Fatal(err)
+		}
+		if runs() != tt.runs {
+			t.Errorf("%s: install ran %d time(s) in total, want %d", tt.name, runs(), tt.runs)
+		}
+	}
+
+	// A successful full install forgets the record; a filtered one keeps it
+	filtered, _ := config.NewRunFilter("", "other", "")
+	ti := NewToolInstaller(tc, state, ui.NewEventUI(io.Discard, nil), false, "test")
+	ti.SetFilter(filtered)
+	ti.finishRun()
+	if len(state.CompletedTasks) == 0 {
+		t.Error("a filtered install cleared the record")
+	}
+	NewToolInstaller(tc, state, ui.NewEventUI(io.Discard, nil), false, "test").finishRun()
+	if len(state.CompletedTasks) != 0 {
+		t.Errorf("completed = %v after a full install, want none", state.CompletedTasks)
+	}
+}
+
+func TestStateFlushedPerTool(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// The second install fails unless the first is already in state.json, as a run killed here would need
+	first := config.Tool{Name: "first", Install: config.ToolInstall{Command: "true"}}
+	second := config.Tool{Name: "second", Required: true, Install: config.ToolInstall{
+		Command: `grep -q '"first"' "` + config.GetStatePath() + `"`,
+	}}
+	tc := &config.ToolsConfig{Tools: []config.Tool{first, second}}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+
+	for _, tool := range tc.Tools {
+		if err := ti.installTool(context.Background(), tool); err != nil {
+			t.Fatalf("installTool(%s) = %v", tool.Name, err)
+		}
+	}
+	saved, err := config.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"first", "second"} {
+		if _, ok := saved.Installed[name]; !ok {
+			t.Errorf("state.json missing %s: %v", name, saved.Installed)
+		}
+	}
+	if _, err := os.Stat(config.GetStatePath() + ".tmp"); !os.IsNotExist(err) {
+		t.Error("SaveState left its temp file behind")
+	}
+}
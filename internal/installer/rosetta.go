@@ -0,0 +1,95 @@
+// File: internal/installer/rosetta.go
+// Purpose: Install preflight that provides Rosetta 2 for Intel-only tools on Apple Silicon
+// Problem: Tools marked rosetta: true failed mid-run on fresh Apple Silicon Macs with "Bad CPU type in executable"
+// Role: Before the first stage, installs Rosetta 2 (with consent) when an included tool needs it and it is missing
+// Usage: Runs at the start of InstallAll; SetAssumeYes skips the consent prompt
+// Design choices: Asked once up front rather than when the tool's stage starts, so the one prompt of the run comes
+//                 before the user walks away; declining only fails the run when a required tool needs Rosetta
+// Assumptions: Installing Rosetta accepts Apple's license, which is why it is never done without a yes
+
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// rosettaTimeout bounds the Rosetta check and install (softwareupdate downloads from Apple)
+const rosettaTimeout = 10 * time.Minute
+
+// SetAssumeYes installs Rosetta 2 and quits apps blocking an install (see apps.go) without asking
+// Params: yes - true to skip the consent prompts
+func (ti *ToolInstaller) SetAssumeYes(yes bool) {
+	ti.assumeYes = yes
+}
+
+// checkRosetta is the install preflight for tools marked rosetta: true
+// What: On an Apple Silicon Mac without Rosetta 2, asks to install it and runs softwareupdate
+// Why: Intel-only tools can neither finish installing nor run without it
+// Returns: Error if Rosetta is declined or fails to install and a required tool needs it
+// Edge cases: Intel Macs and Macs that already have Rosetta pass without a prompt; dry runs only report it
+func (ti *ToolInstaller) checkRosetta() error {
+	var needed []string
+	required := false
+	for _, tool := range ti.toolsConfig.Tools {
+		if tool.Rosetta && ti.filter.IncludesTool(tool) {
+			needed = append(needed, tool.Name)
+			required = required || tool.Required
+		}
+	}
+	if len(needed) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rosettaTimeout)
+	defer cancel()
+	if !ti.rosettaMissing(ctx) {
+		return nil
+	}
+
+	names := strings.Join(needed, ", ")
+	if ti.dryRun {
+		ti.ui.Info("[DRY RUN] Would install Rosetta 2 (needed by %s)", names)
+		return nil
+	}
+	if !ti.assumeYes {
+		ok, err := ti.confirmRosetta(names)
+		if err != nil {
+			// Ctrl-C at the prompt defers Rosetta without stopping the run
+			ti.ui.Warning("⚠️  Rosetta 2 deferred; %s will not run until it is installed", names)
+			return nil
+		}
+		if !ok {
+			if required {
+				return fmt.Errorf("Rosetta 2 is required by %s; install it with 'softwareupdate --install-rosetta --agree-to-license' or rerun with --yes", names)
+			}
+			ti.ui.Warning("⚠️  Skipping Rosetta 2; %s will not run until it is installed", names)
+			return nil
+		}
+	}
+
+	ti.ui.Info("🧬 Installing Rosetta 2 (needed by %s)...", names)
+	if err := ti.installRosetta(ctx); err != nil {
+		if required {
+			return err
+		}
+		ti.ui.Warning("⚠️  %v; %s will not run until Rosetta 2 is installed", err, names)
+		return nil
+	}
+	ti.ui.Success("✅ Rosetta 2 installed")
+	return nil
+}
+
+// confirmRosetta asks for consent to install Rosetta 2
+// Returns: True if the user answered yes (a timeout counts as no); prompt.ErrDeferred on Ctrl-C
+func (ti *ToolInstaller) confirmRosetta(names string) (bool, error) {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	ti.ui.Info("🧬 %s only run on Intel and need Rosetta 2, which isn't installed", names)
+	return prompt.Confirm("Rosetta 2", "   Install it now (softwareupdate --install-rosetta --agree-to-license, accepts Apple's license)? [y/N] ")
+}
@@ -0,0 +1,170 @@
+// File: internal/installer/schedule.go
+// Purpose: Runs install stages, letting independent parallel groups overlap
+// Problem: Stages ran strictly one after another, so a stage of casks sat idle until every CLI formula in the
+//          stage before it finished, even though nothing in it needed them
+// Role: installAll hands the stages from Stages to runStages, which starts each one once the stages it waits
+//       for are done
+// Usage: err := ti.runStages(stages, ti.stagePrerequisites(stages))
+// Design choices: Only stages of groups declared under groups: in tools.yaml wait on their actual dependencies
+//                 (tool and group depends_on); every other stage waits for all stages before it, as before; stages
+//                 start in stage order even when a later one is ready first, so logs of two runs line up; after a
+//                 required failure no further stage starts, while stages already running finish
+// Assumptions: Stages are in Stages order, so a stage's prerequisites always come before it
+
+package installer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// StagePrerequisites returns, per stage, the earlier stages it waits for
+// What: See stagePrerequisites; stage numbers are 1-based, as Stages and plans number them
+// Why: Plans show which stages install alongside each other
+// Params: toolsConfig - loaded tools configuration, stages - Stages result
+// Returns: Stage numbers per stage (element N-1 is stage N)
+func StagePrerequisites(toolsConfig *config.ToolsConfig, stages [][]config.Tool) [][]int {
+	after := (&ToolInstaller{toolsConfig: toolsConfig}).stagePrerequisites(stages)
+	for _, stage := range after {
+		for k := range stage {
+			stage[k]++
+		}
+	}
+	return after
+}
+
+// stagePrerequisites returns, per stage, the stages it must wait for
+// What: A declared group's stage waits for the stages holding its tools' dependencies; any other stage for all earlier ones
+// Why: Lets independent groups overlap without reordering configs that rely on stage order
+// Params: stages - batches from Stages
+// Returns: Earlier stage indexes (0-based) per stage, ascending
+func (ti *ToolInstaller) stagePrerequisites(stages [][]config.Tool) [][]int {
+	stageOf := make(map[string]int)
+	for i, batch := range stages {
+		for _, tool := range batch {
+			stageOf[tool.Name] = i
+		}
+	}
+
+	after := make([][]int, len(stages))
+	for i, batch := range stages {
+		if len(batch) == 0 || ti.toolsConfig.Group(batch[0].Install.ParallelGroup) == nil {
+			for j := range i {
+				after[i] = append(after[i], j)
+			}
+			continue
+		}
+		for _, tool := range batch {
+			for _, dep := range ti.dependencies(tool) {
+				if j, ok := stageOf[dep]; ok && j < i && !slices.Contains(after[i], j) {
+					after[i] = append(after[i], j)
+				}
+			}
+		}
+		sort.Ints(after[i])
+	}
+	return after
+}
+
+// runStages installs stages, each as soon as its prerequisites are done
+// What: One goroutine per stage waits for its prerequisites and for the previous stage to start, then installs its tools
+// Why: Independent groups (e.g. casks and CLI formulas) install at the same time, in a repeatable order
+// Params: stages - batches from Stages, after - stagePrerequisites result
+// Returns: First error from a stage (a required tool failed); later stages don't start after it
+func (ti *ToolInstaller) runStages(stages [][]config.Tool, after [][]int) error {
+	done := make([]chan struct{}, len(stages))
+	started := make([]chan struct{}, len(stages))
+	for i := range done {
+		done[i] = make(chan struct{})
+		started[i] = make(chan struct{})
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		running  []int
+		wg       sync.WaitGroup
+	)
+	for i, batch := range stages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			for _, j := range after[i] {
+				<-done[j]
+			}
+			if i > 0 {
+				<-started[i-1]
+			}
+
+			stage := i + 1
+			selected := ti.selectStage(stage, batch)
+			mu.Lock()
+			if firstErr != nil || len(selected) == 0 {
+				close(started[i])
+				mu.Unlock()
+				return
+			}
+			if ti.dryRun || !ti.filter.Empty() {
+				ti.ui.Info("Stage %d/%d: %s", stage, len(stages), toolNames(selected))
+			}
+			if len(running) > 0 {
+				ti.ui.Info("⚡ Stage %d/%d (%s) starts alongside stage %s", stage, len(stages), batch[0].Install.ParallelGroup, stageList(running))
+			}
+			running = append(running, stage)
+			ti.beginStage(stage, namesOf(selected))
+			close(started[i])
+			mu.Unlock()
+
+			err := ti.installGroup(selected)
+			ti.endStage(stage)
+
+			mu.Lock()
+			defer mu.Unlock()
+			running = slices.DeleteFunc(running, func(s int) bool { return s == stage })
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// selectStage returns a stage's tools that the run filter keeps
+// Returns: Selected tools, nil when the filter skips the whole stage
+func (ti *ToolInstaller) selectStage(stage int, batch []config.Tool) []config.Tool {
+	if !ti.filter.IncludesStage(stage) {
+		return nil
+	}
+	var selected []config.Tool
+	for _, tool := range batch {
+		if ti.filter.IncludesTool(tool) {
+			selected = append(selected, tool)
+		}
+	}
+	return selected
+}
+
+// dependencies returns what a tool waits for, group depends_on included
+func (ti *ToolInstaller) dependencies(tool config.Tool) []string {
+	if ti.toolsConfig == nil {
+		return tool.DependsOn
+	}
+	return ti.toolsConfig.Dependencies(tool)
+}
+
+// stageList formats stage numbers for display
+// Example: [2 3] → "2, 3"
+func stageList(stages []int) string {
+	parts := make([]string, 0, len(stages))
+	for _, s := range stages {
+		parts = append(parts, fmt.Sprint(s))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,97 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestStagePrerequisites(t *testing.T) {
+	tool := func(name, group string, deps ...string) config.Tool {
+		return config.Tool{Name: name, Install: config.ToolInstall{ParallelGroup: group}, DependsOn: deps}
+	}
+	tc := &config.ToolsConfig{
+		Tools: []config.Tool{
+			tool("homebrew", ""),
+			tool("git", "cli", "homebrew"),
+			tool("zed", "casks", "homebrew"),
+			tool("pnpm-setup", "", "git"),
+		},
+		Groups: []config.ParallelGroup{{Name: "cli"}, {Name: "casks"}},
+	}
+	stages, err := Stages(tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ti := &ToolInstaller{toolsConfig: tc}
+	// Stages: homebrew, git (cli), zed (casks), pnpm-setup (undeclared: waits for all)
+	want := [][]int{nil, {0}, {0}, {0, 1, 2}}
+	if got := ti.stagePrerequisites(stages); !reflect.DeepEqual(got, want) {
+		t.Errorf("stagePrerequisites() = %v, want %v", got, want)
+	}
+}
+
+func TestRunStagesOverlap(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	// Each tool waits for the other's marker, so the run only succeeds if both stages run at once
+	wait := func(mine, theirs string) string {
+		return fmt.Sprintf("touch %s; for i in $(seq 100); do [ -f %s ] && exit 0; sleep 0.05; done; exit 1",
+			filepath.Join(dir, mine), filepath.Join(dir, theirs))
+	}
+	tc := &config.ToolsConfig{
+		Tools: []config.Tool{
+			{Name: "git", Required: true, Install: config.ToolInstall{Command: wait("a", "b"), ParallelGroup: "cli"}},
+			{Name: "zed", Required: true, Install: config.ToolInstall{Command: wait("b", "a"), ParallelGroup: "casks"}},
+		},
+		Groups: []config.ParallelGroup{{Name: "cli"}, {Name: "casks"}},
+	}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+
+	stages, err := Stages(tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ti.runStages(stages, ti.stagePrerequisites(stages)); err != nil {
+		t.Fatalf("runStages() = %v, want both stages to run together", err)
+	}
+	results := ti.Results()
+	if len(results) != 2 || results[0].Stage != 1 || results[0].OK != 1 || results[1].OK != 1 {
+		t.Errorf("Results() = %+v, want one ok tool in each of stages 1 and 2", results)
+	}
+}
+
+func TestStagesFollowGroupDeclarationOrder(t *testing.T) {
+	tc := &config.ToolsConfig{
+		Tools: []config.Tool{
+			{Name: "homebrew"},
+			{Name: "git", Install: config.ToolInstall{ParallelGroup: "cli"}, DependsOn: []string{"homebrew"}},
+			{Name: "jq", DependsOn: []string{"homebrew"}},
+			{Name: "zed", Install: config.ToolInstall{ParallelGroup: "casks"}, DependsOn: []string{"homebrew"}},
+		},
+		Groups: []config.ParallelGroup{{Name: "casks"}, {Name: "cli"}},
+	}
+
+	// Declared groups first, in groups: order; then the rest as they appear in tools
+	want := [][]string{{"homebrew"}, {"zed"}, {"git"}, {"jq"}}
+	for range 5 {
+		stages, err := Stages(tc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got [][]string
+		for _, batch := range stages {
+			got = append(got, namesOf(batch))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Stages() = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// File: internal/installer/scheduler.go
+// Purpose: Per-class concurrency limits for tool installs
+// Problem: A parallel group ran every member at once, so a big compile starved quick installs and downloads saturated the network
+// Role: Hands out install slots per scheduler class (download, cpu, disk, interactive)
+// Usage: release, err := limiter.acquire(ctx, tool.Install.Class); defer release()
+// Design choices: Buffered channel per class as a counting semaphore; unclassified tools are not limited
+// Assumptions: Limits are validated (>= 1) by ToolsConfig.Validate
+
+package installer
+
+import "context"
+
+// classLimiter is a set of counting semaphores keyed by scheduler class
+type classLimiter struct {
+	slots map[string]chan struct{}
+}
+
+// newClassLimiter creates a limiter with the given per-class limits
+// Params: limits - maximum concurrent installs per class
+// Returns: Limiter ready for acquire
+func newClassLimiter(limits map[string]int) *classLimiter {
+	slots := make(map[string]chan struct{}, len(limits))
+	for class, limit := range limits {
+		slots[class] = make(chan struct{}, limit)
+	}
+	return &classLimiter{slots: slots}
+}
+
+// acquire waits for a slot in the given class
+// What: Blocks until a slot frees up or ctx is cancelled
+// Why: Queued installs must still honor group cancellation
+// Params: ctx - group context, class - scheduler class ("" is unlimited)
+// Returns: Release function (always non-nil) and ctx error if cancelled while waiting
+func (l *classLimiter) acquire(ctx context.Context, class string) (func(), error) {
+	slot, ok := l.slots[class]
+	if !ok {
+		return func() {}, nil
+	}
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return func() {}, ctx.Err()
+	}
+}
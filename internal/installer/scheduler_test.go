@@ -0,0 +1,81 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestClassLimiterPeakConcurrency(t *testing.T) {
+	limits := map[string]int{config.ClassCPU: 2, config.ClassInteractive: 1}
+	limiter := newClassLimiter(limits)
+
+	var mu sync.Mutex
+	running := make(map[string]int)
+	peak := make(map[string]int)
+
+	// Six blocking installs per class, more than either limit
+	var wg sync.WaitGroup
+	for class := range limits {
+		for range 6 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				release, err := limiter.acquire(context.Background(), class)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer release()
+
+				mu.Lock()
+				running[class]++
+				peak[class] = max(peak[class], running[class])
+				mu.Unlock()
+
+				time.Sleep(20 * time.Millisecond)
+
+				mu.Lock()
+				running[class]--
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+
+	for class, limit := range limits {
+		if peak[class] != limit {
+			t.Errorf("%s peak concurrency = %d, want %d", class, peak[class], limit)
+		}
+	}
+}
+
+func TestClassLimiterAcquireCancelled(t *testing.T) {
+	limiter := newClassLimiter(map[string]int{config.ClassInteractive: 1})
+	hold, err := limiter.acquire(context.Background(), config.ClassInteractive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hold()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	release, err := limiter.acquire(ctx, config.ClassInteractive)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() on a full class = %v, want the context error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("acquire() returned after %s, want it to stop waiting at the deadline", elapsed)
+	}
+	release()
+
+	// Unclassified installs are never limited
+	if _, err := limiter.acquire(ctx, ""); err != nil {
+		t.Errorf("acquire() of an unclassified install = %v", err)
+	}
+}
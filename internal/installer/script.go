@@ -0,0 +1,173 @@
+// File: internal/installer/script.go
+// Purpose: Remote installer scripts with checksum pinning and optional inspection
+// Problem: Remote install scripts were piped from curl straight into a shell and run blindly
+// Role: Installs tools declared with install.type: script
+// Usage: Called by installTool when tool.Install.IsScript(); inspection enabled via SetInspectScripts
+// Design choices: Always download to disk first; verify pin before running; inspection prompts are serialized
+// Assumptions: Scripts are small text files; user is present when --inspect-scripts is used; scripts run unsandboxed
+//              with the user's privileges, so the SHA256 pin (not a publisher signature) is what vouches for them
+
+package installer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/download"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// promptMu serializes interactive prompts from parallel installs
+var promptMu sync.Mutex
+
+// SetInspectScripts enables download-then-inspect mode
+// What: Remote scripts are shown (path, size, checksum) and run only after confirmation
+// Why: Lets a cautious user read a script before it executes with their privileges
+// Params: inspect - true to prompt before running each script
+func (ti *ToolInstaller) SetInspectScripts(inspect bool) {
+	ti.inspectScripts = inspect
+}
+
+// installScript downloads, verifies, and runs a remote installer script
+// What: Fetches script to the download cache, checks the SHA256 pin, optionally prompts, runs it with the configured shell
+// Why: A pinned checksum turns a silently changed upstream script into a hard failure
+// Params: ctx - context for timeout and cancellation, tool - tool with install.script
+// Returns: Error if download, verification, confirmation, or execution fails
+// Edge cases: The official Homebrew installer goes through bootstrapHomebrew instead
+func (ti *ToolInstaller) installScript(ctx context.Context, tool config.Tool) error {
+	if tool.Install.Script.IsHomebrew() {
+		return ti.bootstrapHomebrew(ctx, tool)
+	}
+	scriptPath, err := ti.fetchScript(ctx, tool)
+	if err != nil {
+		return err
+	}
+	return ti.runScript(ctx, tool, scriptPath, ti.installEnv())
+}
+
+// fetchScript downloads and verifies a tool's installer script, confirming it first in inspect mode
+// Params: ctx - context for cancellation, tool - tool with install.script
+// Returns: Path of the downloaded script, error if download, verification, or confirmation fails
+func (ti *ToolInstaller) fetchScript(ctx context.Context, tool config.Tool) (string, error) {
+	script := tool.Install.Script
+
+	cacheDir := filepath.Join(config.GetStateDir(), "downloads")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download cache: %w", err)
+	}
+
+	name, err := archiveName(script.URL)
+	if err != nil {
+		return "", err
+	}
+	scriptPath := filepath.Join(cacheDir, tool.Name+"-"+name)
+
+	// Always fetch a fresh copy: unpinned scripts change upstream, pinned ones are verified below
+	_ = os.Remove(scriptPath)
+	_ = os.Remove(scriptPath + ".part")
+	if err := download.New(nil).ToFile(ctx, script.URL, scriptPath); err != nil {
+		return "", fmt.Errorf("failed to download installer script: %w", err)
+	}
+
+	if err := verifySHA256(scriptPath, script.Checksum); err != nil {
+		return "", fmt.Errorf("installer script %s: %w", script.URL, err)
+	}
+	if script.Checksum == "" {
+		ti.ui.Warning("  ⚠️  %s installer script is not pinned (add script.checksum)", tool.Name)
+	}
+
+	if ti.inspectScripts {
+		if err := ti.confirmScript(tool, scriptPath); err != nil {
+			return "", err
+		}
+	}
+	return scriptPath, nil
+}
+
+// runScript runs a downloaded installer script with the configured shell and args
+// Params: ctx - context for timeout and cancellation, tool - tool with install.script, scriptPath - fetchScript output, env - process environment
+// Returns: Error if the script fails or hangs; output is logged to install-<tool>.log
+func (ti *ToolInstaller) runScript(ctx context.Context, tool config.Tool, scriptPath string, env []string) error {
+	script := tool.Install.Script
+	shell := script.Shell
+	if shell == "" {
+		shell = "/bin/bash"
+	}
+
+	out := capture.Open("install-" + tool.Name)
+	defer out.Close()
+
+	ctx, kill := context.WithCancel(ctx)
+	defer kill()
+
+	cmd := exec.CommandContext(ctx, shell, append([]string{scriptPath}, script.Args...)...)
+	cmd.Stdin = prompt.Stdin()
+	cmd.Env = env
+	out.Attach(cmd, os.Stdout, os.Stderr)
+	stop := ti.watchHang(ctx, tool, out, kill)
+	err := cmd.Run()
+	if stop() {
+		return fmt.Errorf("installer script %w", errHung)
+	}
+	if err != nil {
+		return fmt.Errorf("installer script failed: %w", out.Wrap(err))
+	}
+	return nil
+}
+
+// confirmScript shows a downloaded script and asks whether to run it
+// What: Prints path, size, and SHA256; "v" opens the script in $PAGER (default less)
+// Why: Download-then-inspect mode
+// Params: tool - tool being installed, scriptPath - downloaded script
+// Returns: Error if the user declines (a timeout declines); wraps prompt.ErrDeferred on Ctrl-C
+func (ti *ToolInstaller) confirmScript(tool config.Tool, scriptPath string) error {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+
+	ti.ui.Info("  📜 %s installer script: %s", tool.Name, tool.Install.Script.URL)
+	ti.ui.Info("     Saved to %s (%d lines, sha256:%s)", scriptPath, strings.Count(string(data), "\n"), hex.EncodeToString(sum[:]))
+
+	for {
+		fmt.Print("     Run it? [y/N/v=view] ")
+		answer, err := prompt.Ask(prompt.Question{Task: tool.Name, Label: "run installer script", Default: "n"})
+		if errors.Is(err, prompt.ErrDeferred) {
+			return fmt.Errorf("installer script %w", err)
+		}
+		if err != nil {
+			return fmt.Errorf("installer script not confirmed")
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return nil
+		case "v", "view":
+			pager := os.Getenv("PAGER")
+			if pager == "" {
+				pager = "less"
+			}
+			cmd := exec.Command("sh", "-c", pager+` "$1"`, "sh", scriptPath)
+			cmd.Stdin = prompt.Stdin()
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			_ = cmd.Run()
+		default:
+			return fmt.Errorf("installer script declined by user")
+		}
+	}
+}
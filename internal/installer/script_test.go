@@ -0,0 +1,96 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+// scriptServer serves an installer script that creates marker when it runs
+func scriptServer(t *testing.T, marker string) (*httptest.Server, string) {
+	t.Helper()
+	body := "#!/bin/sh\ntouch '" + marker + "'\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	sum := sha256.Sum256([]byte(body))
+	return server, hex.EncodeToString(sum[:])
+}
+
+func TestInstallScript(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "ran")
+	server, sum := scriptServer(t, marker)
+
+	tests := []struct {
+		name     string
+		checksum string
+		wantErr  string
+		wantWarn bool
+	}{
+		{"pinned", "sha256:" + sum, "", false},
+		{"pin mismatch", "sha256:" + strings.Repeat("0", 64), "checksum mismatch", false},
+		{"unpinned", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(marker)
+			tool := config.Tool{Name: "tool", Install: config.ToolInstall{Script: &config.ScriptInstall{
+				URL: server.URL + "/install.sh", Checksum: tt.checksum, Shell: "sh",
+			}}}
+			var events bytes.Buffer
+			ti := NewToolInstaller(&config.ToolsConfig{Tools: []config.Tool{tool}}, &config.State{}, ui.NewEventUI(&events, nil), false, "test")
+
+			err := ti.installScript(context.Background(), tool)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("installScript() = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("installScript() = %v, want %q", err, tt.wantErr)
+			}
+			if _, statErr := os.Stat(marker); (statErr == nil) != (tt.wantErr == "") {
+				t.Errorf("script ran = %v, want %v", statErr == nil, tt.wantErr == "")
+			}
+			if warned := strings.Contains(events.String(), "installer script is not pinned"); warned != tt.wantWarn {
+				t.Errorf("unpinned warning = %v, want %v", warned, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestInstallScriptInspectDeclined(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "ran")
+	server, sum := scriptServer(t, marker)
+
+	// Nobody answers the inspect prompt, which declines
+	prompt.SetTimeout(50 * time.Millisecond)
+	t.Cleanup(func() { prompt.SetTimeout(prompt.DefaultTimeout) })
+
+	tool := config.Tool{Name: "tool", Install: config.ToolInstall{Script: &config.ScriptInstall{
+		URL: server.URL + "/install.sh", Checksum: sum, Shell: "sh",
+	}}}
+	ti := NewToolInstaller(&config.ToolsConfig{Tools: []config.Tool{tool}}, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+	ti.SetInspectScripts(true)
+
+	if err := ti.installScript(context.Background(), tool); err == nil {
+		t.Fatal("installScript() succeeded without confirmation")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("declined script ran")
+	}
+}
@@ -0,0 +1,40 @@
+// File: internal/installer/service.go
+// Purpose: Starts brew services declared on tools (tools.yaml service:)
+// Problem: Installing postgresql or redis left them stopped; the first 'connection refused' came hours later
+// Role: After a tool is installed (or found installed), starts its service and waits for its port
+// Usage: Called from installTool; tools without service: (or with manual: true) are untouched
+// Design choices: Runs on every install so a service stopped since the last run is started again (Ensure is a
+//                 no-op for running services); a service that won't come up fails the tool like a failed install
+// Assumptions: brew is on PATH by the time a tool with a service installs (tools depend on homebrew)
+
+package installer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/services"
+)
+
+// serviceCommandTimeout bounds the brew services calls on top of the port wait
+const serviceCommandTimeout = time.Minute
+
+// startService starts the tool's brew service and waits for its port
+// Params: ctx - group context, tool - installed tool
+// Returns: Error naming the service if it can't be started or never listens
+func (ti *ToolInstaller) startService(ctx context.Context, tool config.Tool) error {
+	svc := tool.Service
+	if svc == nil || svc.Manual || ti.dryRun {
+		return nil
+	}
+	name := svc.ServiceName(tool.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, svc.Timeout()+serviceCommandTimeout)
+	defer cancel()
+	if err := services.Ensure(ctx, name, svc.Port, svc.Timeout()); err != nil {
+		return fmt.Errorf("service %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+// File: internal/installer/summary.go
+// Purpose: Per-stage outcomes of an install run, the closing summary table, and the exit code policy
+// Problem: A multi-stage install ended in a scroll of mixed ✓/✗ lines and warnings, and exited 0 even when
+//          optional tools failed, so neither people nor scripts could tell how a run actually went
+// Role: installTool tallies each tool as ok, failed, skipped (already installed), or cancelled in its stage;
+//       install prints the table and exits with ExitCode
+// Usage: tasks, err := ti.InstallAll(); PrintSummary(ui, ti.Results(), tasks); os.Exit(ExitCode(ti.Results(), err))
+// Design choices: Stages are the install batches Stages returns (numbered as --stages and --dry-run number them);
+//                 the exit code separates a stopped run (1) from a finished run with optional failures (2) so
+//                 CI can choose to tolerate the latter
+// Assumptions: Stages may overlap (declared independent groups), so tallies find their stage by tool name
+
+package installer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+// Exit codes for install runs
+const (
+	// ExitOK means every tool that ran is installed
+	ExitOK = 0
+	// ExitFailed means a required tool failed (or the run couldn't start) and later stages didn't run
+	ExitFailed = 1
+	// ExitOptionalFailed means the run finished but optional tools failed
+	ExitOptionalFailed = 2
+)
+
+// Tool outcomes (TaskResult.Outcome), tallied per stage
+const (
+	OutcomeOK        = "ok"
+	OutcomeFailed    = "failed"
+	OutcomeSkipped   = "skipped"
+	OutcomeCancelled = "cancelled"
+)
+
+// slowestShown is how many of the slowest installs the summary names
+const slowestShown = 3
+
+// TaskResult is the outcome of one tool in an install run
+type TaskResult struct {
+	// Name is the tool name
+	Name string `json:"name"`
+
+	// Stage is the stage that ran the tool (from 1)
+	Stage int `json:"stage"`
+
+	// Outcome is OutcomeOK, OutcomeFailed, OutcomeSkipped (already installed or deprecated), or OutcomeCancelled
+	Outcome string `json:"outcome"`
+
+	// Required is whether a failure stops the run
+	Required bool `json:"required,omitempty"`
+
+	// Duration is how long the tool took, from its check to its result
+	Duration time.Duration `json:"duration"`
+
+	// Error is why the tool failed or was cancelled
+	Error string `json:"error,omitempty"`
+
+	// Owner is who maintains the tool ("" = the platform team)
+	Owner string `json:"owner,omitempty"`
+}
+
+// StageResult is the outcome of one install stage
+type StageResult struct {
+	// Stage is the stage number (from 1)
+	Stage int
+
+	// Tools are the tools the stage ran
+	Tools []string
+
+	// Duration is how long the stage took
+	Duration time.Duration
+
+	// OK, Failed, Skipped, and Cancelled count tools by outcome (skipped = already installed)
+	OK, Failed, Skipped, Cancelled int
+
+	// started is when the stage began (for Duration)
+	started time.Time
+}
+
+// TaskResults returns the outcome of each tool that ran, in the order they finished
+func (ti *ToolInstaller) TaskResults() []TaskResult {
+	ti.resultMu.Lock()
+	defer ti.resultMu.Unlock()
+	return append([]TaskResult(nil), ti.tasks...)
+}
+
+// Results returns the outcome of each stage that ran, in stage order
+func (ti *ToolInstaller) Results() []StageResult {
+	ti.resultMu.Lock()
+	defer ti.resultMu.Unlock()
+	results := append([]StageResult(nil), ti.results...)
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Stage < results[j].Stage })
+	return results
+}
+
+// beginStage starts tallying a stage
+func (ti *ToolInstaller) beginStage(stage int, tools []string) {
+	ti.resultMu.Lock()
+	defer ti.resultMu.Unlock()
+	ti.results = append(ti.results, StageResult{Stage: stage, Tools: tools, started: time.Now()})
+}
+
+// endStage records a stage's duration
+func (ti *ToolInstaller) endStage(stage int) {
+	ti.resultMu.Lock()
+	defer ti.resultMu.Unlock()
+	for i := range ti.results {
+		if ti.results[i].Stage == stage {
+			ti.results[i].Duration = time.Since(ti.results[i].started)
+		}
+	}
+}
+
+// startTally notes when a tool's install began (for TaskResult.Duration)
+func (ti *ToolInstaller) startTally(tool string) {
+	ti.resultMu.Lock()
+	defer ti.resultMu.Unlock()
+	if ti.started == nil {
+		ti.started = make(map[string]time.Time)
+	}
+	ti.started[tool] = time.Now()
+}
+
+// tally records one tool's outcome and counts it in the stage running it
+// Params: tool - the tool, outcome - Outcome* constant, err - why it failed or was cancelled (nil if not known)
+// Edge cases: Stages can overlap, so the stage is found by tool; a tool in no stage counts in the latest one
+func (ti *ToolInstaller) tally(tool config.Tool, outcome string, err error) {
+	ti.resultMu.Lock()
+	defer ti.resultMu.Unlock()
+
+	task := TaskResult{Name: tool.Name, Outcome: outcome, Required: tool.Required, Owner: ti.toolsConfig.OwnerOf(tool).String()}
+	if start, ok := ti.started[tool.Name]; ok {
+		task.Duration = time.Since(start)
+	}
+	if err != nil {
+		task.Error = err.Error()
+	}
+	defer func() { ti.tasks = append(ti.tasks, task) }()
+
+	n := len(ti.results)
+	if n == 0 {
+		return
+	}
+	r := &ti.results[n-1]
+	for i := n - 1; i >= 0; i-- {
+		if slices.Contains(ti.results[i].Tools, tool.Name) {
+			r = &ti.results[i]
+			break
+		}
+	}
+	task.Stage = r.Stage
+	switch outcome {
+	case OutcomeOK:
+		r.OK++
+	case OutcomeFailed:
+		r.Failed++
+	case OutcomeSkipped:
+		r.Skipped++
+	case OutcomeCancelled:
+		r.Cancelled++
+	}
+}
+
+// ExitCode applies the exit code policy to a run
+// Params: results - stage results, err - error InstallAll returned
+// Returns: ExitFailed if the run stopped, ExitOptionalFailed if any tool failed, else ExitOK
+func ExitCode(results []StageResult, err error) int {
+	if err != nil {
+		return ExitFailed
+	}
+	for _, r := range results {
+		if r.Failed > 0 {
+			return ExitOptionalFailed
+		}
+	}
+	return ExitOK
+}
+
+// PrintSummary prints one row per stage: tools, duration, and counts by outcome, then the slowest installs
+// Params: u - UI to print on, results - stage results, tasks - tool results (InstallAll's)
+// Edge cases: Prints nothing for fewer than two stages; a single stage's ✓/✗ lines already say it all
+func PrintSummary(u ui.UI, results []StageResult, tasks []TaskResult) {
+	if len(results) < 2 {
+		return
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STAGE\tRESULT\tDURATION\tOK\tFAILED\tSKIPPED\tCANCELLED\tTOOLS")
+	var total StageResult
+	for _, r := range results {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n", r.Stage, r.status(), r.Duration.Round(time.Second),
+			r.OK, r.Failed, r.Skipped, r.Cancelled, summarizeTools(r.Tools))
+		total.Duration += r.Duration
+		total.OK += r.OK
+		total.Failed += r.Failed
+		total.Skipped += r.Skipped
+		total.Cancelled += r.Cancelled
+	}
+	fmt.Fprintf(w, "total\t\t%s\t%d\t%d\t%d\t%d\n", total.Duration.Round(time.Second), total.OK, total.Failed, total.Skipped, total.Cancelled)
+	_ = w.Flush()
+
+	u.Info("📊 Install summary")
+	for _, line := range strings.Split(strings.TrimRight(b.String(), "\n"), "\n") {
+		u.Info("  %s", line)
+	}
+	if slowest := Slowest(tasks, slowestShown); len(slowest) > 0 {
+		var names []string
+		for _, t := range slowest {
+			names = append(names, fmt.Sprintf("%s %s", t.Name, t.Duration.Round(time.Second)))
+		}
+		u.Info("  Slowest: %s", strings.Join(names, ", "))
+	}
+	u.Info("")
+}
+
+// Slowest returns the longest tool installs of a run
+// Params: tasks - tool results, n - how many to return
+// Returns: Up to n installed or failed tools, slowest first (already-installed and cancelled tools are left out)
+func Slowest(tasks []TaskResult, n int) []TaskResult {
+	var ran []TaskResult
+	for _, t := range tasks {
+		if t.Outcome == OutcomeOK || t.Outcome == OutcomeFailed {
+			ran = append(ran, t)
+		}
+	}
+	sort.SliceStable(ran, func(i, j int) bool { return ran[i].Duration > ran[j].Duration })
+	return ran[:min(n, len(ran))]
+}
+
+// status summarizes a stage in one word
+func (r StageResult) status() string {
+	switch {
+	case r.Failed > 0 && r.OK+r.Skipped == 0:
+		return "failed"
+	case r.Failed > 0 || r.Cancelled > 0:
+		return "partial"
+	}
+	return "ok"
+}
+
+// summarizeTools lists a stage's tools, shortened past a few names
+func summarizeTools(tools []string) string {
+	const shown = 3
+	if len(tools) <= shown {
+		return strings.Join(tools, ", ")
+	}
+	return fmt.Sprintf("%s +%d more", strings.Join(tools[:shown], ", "), len(tools)-shown)
+}
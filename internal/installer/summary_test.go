@@ -0,0 +1,90 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestStageResults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tools := []config.Tool{
+		{Name: "present", Check: "true", Install: config.ToolInstall{Command: "exit 9"}},
+		{Name: "fresh", Install: config.ToolInstall{Command: "true"}},
+		{Name: "broken", Install: config.ToolInstall{Command: "exit 3"}},
+	}
+	tc := &config.ToolsConfig{Tools: tools}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+
+	ti.beginStage(1, namesOf(tools))
+	if err := ti.installGroup(tools); err != nil {
+		t.Fatalf("installGroup: %v", err)
+	}
+	ti.endStage(1)
+
+	results := ti.Results()
+	if len(results) != 1 {
+		t.Fatalf("Results() = %+v, want one stage", results)
+	}
+	r := results[0]
+	if r.OK != 1 || r.Failed != 1 || r.Skipped != 1 || r.Cancelled != 0 || r.status() != "partial" {
+		t.Errorf("stage result = %+v (%s), want 1 ok, 1 failed, 1 skipped, partial", r, r.status())
+	}
+
+	outcomes := make(map[string]string)
+	for _, task := range ti.TaskResults() {
+		if task.Stage != 1 {
+			t.Errorf("task %s in stage %d, want 1", task.Name, task.Stage)
+		}
+		outcomes[task.Name] = task.Outcome
+		if task.Name == "broken" && task.Error == "" {
+			t.Error("failed task has no error")
+		}
+	}
+	if want := map[string]string{"present": OutcomeSkipped, "fresh": OutcomeOK, "broken": OutcomeFailed}; !reflect.DeepEqual(outcomes, want) {
+		t.Errorf("task outcomes = %v, want %v", outcomes, want)
+	}
+
+	if code := ExitCode(results, nil); code != ExitOptionalFailed {
+		t.Errorf("ExitCode with an optional failure = %d, want %d", code, ExitOptionalFailed)
+	}
+	if code := ExitCode(results, errors.New("required tool git failed")); code != ExitFailed {
+		t.Errorf("ExitCode with a run error = %d, want %d", code, ExitFailed)
+	}
+	if code := ExitCode([]StageResult{{Stage: 1, OK: 2}, {Stage: 2, Skipped: 1}}, nil); code != ExitOK {
+		t.Errorf("ExitCode of a clean run = %d, want %d", code, ExitOK)
+	}
+
+	// A tool started after its stage was cancelled counts as cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ti.beginStage(2, []string{"late"})
+	_ = ti.installTool(ctx, config.Tool{Name: "late", Install: config.ToolInstall{Command: "true"}})
+	if r := ti.Results()[1]; r.Cancelled != 1 {
+		t.Errorf("stage 2 = %+v, want 1 cancelled", r)
+	}
+}
+
+func TestSlowest(t *testing.T) {
+	tasks := []TaskResult{
+		{Name: "git", Outcome: OutcomeSkipped, Duration: time.Hour},
+		{Name: "node", Outcome: OutcomeOK, Duration: 40 * time.Second},
+		{Name: "zed", Outcome: OutcomeFailed, Duration: 90 * time.Second},
+		{Name: "uv", Outcome: OutcomeOK, Duration: 2 * time.Second},
+		{Name: "pnpm", Outcome: OutcomeCancelled, Duration: time.Minute},
+	}
+	var names []string
+	for _, task := range Slowest(tasks, 2) {
+		names = append(names, task.Name)
+	}
+	if want := []string{"zed", "node"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Slowest() = %v, want %v", names, want)
+	}
+}
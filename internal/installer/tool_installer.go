@@ -10,25 +10,72 @@ package installer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/checkcache"
 	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/hooks"
+	"github.com/rkinnovate/dev-setup/internal/policy"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+	"github.com/rkinnovate/dev-setup/internal/rosetta"
+	"github.com/rkinnovate/dev-setup/internal/support"
 	"github.com/rkinnovate/dev-setup/internal/ui"
+	versionpkg "github.com/rkinnovate/dev-setup/internal/version"
 )
 
+// errCancelled marks a tool stopped because a required sibling in its group failed
+var errCancelled = errors.New("cancelled")
+
 // ToolInstaller manages tool installation with idempotency and parallelism
 // What: Installs tools from tools.yaml with proper checking and ordering
 // Why: Need reliable, fast installation that doesn't redo completed work
 type ToolInstaller struct {
-	toolsConfig *config.ToolsConfig
-	state       *config.State
-	ui          ui.UI
-	dryRun      bool
-	version     string
+	toolsConfig    *config.ToolsConfig
+	state          *config.State
+	ui             ui.UI
+	dryRun         bool
+	version        string
+	policies       *config.PolicyConfig
+	limiter        *classLimiter
+	inspectScripts bool
+	assumeYes      bool
+	hooks          *hooks.Dispatcher
+	filter         *config.RunFilter
+	checks         *checkcache.Cache
+	osVersion      func(ctx context.Context) (string, error)
+	rosettaMissing func(ctx context.Context) bool
+	installRosetta func(ctx context.Context) error
+	artifactDomain string
+
+	// withoutHomebrew is `install --without-brew`; direct names the tools switched to their direct: install
+	withoutHomebrew bool
+	direct          map[string]bool
+
+	// force is `install --force`: tools an interrupted install finished run again (see resume.go)
+	force bool
+
+	// stateMu guards state, which parallel installs update and flush
+	stateMu sync.Mutex
+
+	// failures collects failed tools for the last-failure summary (guarded by failMu)
+	failures []support.Failure
+	failMu   sync.Mutex
+
+	// results are the per-stage outcomes of this run, in start order; tasks the per-tool ones, and started when
+	// each tool began (all guarded by resultMu)
+	results  []StageResult
+	tasks    []TaskResult
+	started  map[string]time.Time
+	resultMu sync.Mutex
 }
 
 // NewToolInstaller creates a new tool installer
@@ -39,41 +86,120 @@ type ToolInstaller struct {
 // Example: installer := NewToolInstaller(cfg, state, ui, false)
 func NewToolInstaller(toolsConfig *config.ToolsConfig, state *config.State, ui ui.UI, dryRun bool, version string) *ToolInstaller {
 	return &ToolInstaller{
-		toolsConfig: toolsConfig,
-		state:       state,
-		ui:          ui,
-		dryRun:      dryRun,
-		version:     version,
+		toolsConfig:    toolsConfig,
+		state:          state,
+		ui:             ui,
+		dryRun:         dryRun,
+		version:        version,
+		limiter:        newClassLimiter(toolsConfig.ClassLimits()),
+		osVersion:      policy.MacOSVersion,
+		rosettaMissing: rosetta.Missing,
+		installRosetta: rosetta.Install,
 	}
 }
 
+// SetPolicies enables org denylist enforcement
+// What: Tools that would install denied software are refused
+// Why: Denied software must never be installed, even if a user config requests it
+// Params: policies - loaded policy configuration
+func (ti *ToolInstaller) SetPolicies(policies *config.PolicyConfig) {
+	ti.policies = policies
+}
+
+// SetHooks enables lifecycle event hooks (stage_complete, stage_failed, task_failed)
+// Params: dispatcher - hooks dispatcher (nil disables hooks)
+func (ti *ToolInstaller) SetHooks(dispatcher *hooks.Dispatcher) {
+	ti.hooks = dispatcher
+}
+
+// SetFilter restricts the run to selected stages, groups, and tools
+// What: Stages are install batches in run order (numbered from 1); filtered-out tools are skipped within their batch
+// Why: Backs --stages, --skip-tasks, and --only-group for debugging partial setups
+// Params: filter - run filter (nil runs everything)
+func (ti *ToolInstaller) SetFilter(filter *config.RunFilter) {
+	ti.filter = filter
+}
+
+// SetCheckCache reuses recent check command results for the already-installed test
+// What: Fresh cached results skip the shell-out; tools installed by this run are invalidated
+// Params: cache - check cache (nil runs every check)
+func (ti *ToolInstaller) SetCheckCache(cache *checkcache.Cache) {
+	ti.checks = cache
+}
+
 // InstallAll installs all tools from configuration
 // What: Main entry point for tool installation, handles all tools with dependencies
 // Why: Single method to install entire tool suite
-// Returns: Error if any required tool fails, nil if all succeeded
-// Example: err := installer.InstallAll()
+// Returns: Each tool's result (also on failure, for the tools that ran), error if any required tool fails
+// Example: tasks, err := installer.InstallAll()
 // Edge cases: Skips already-installed tools; respects dependencies; parallel within groups
-func (ti *ToolInstaller) InstallAll() error {
+func (ti *ToolInstaller) InstallAll() ([]TaskResult, error) {
+	err := ti.installAll()
+	_ = ti.checks.Save()
+	if !ti.dryRun {
+		if err != nil {
+			// Keep what this partial run learned (including tools found already installed)
+			ti.stateMu.Lock()
+			_ = config.SaveState(ti.state)
+			ti.stateMu.Unlock()
+
+			ti.hooks.Fire(config.EventStageFailed, map[string]string{"stage": "install", "error": err.Error()})
+			if recErr := support.RecordFailure(support.FailureSummary{Stage: "install", Error: err.Error(), Failures: ti.failures}); recErr != nil {
+				ti.ui.Warning("⚠️  Failed to save failure summary: %v", recErr)
+			}
+		} else {
+			ti.hooks.Fire(config.EventStageComplete, map[string]string{"stage": "install"})
+		}
+	}
+	return ti.TaskResults(), err
+}
+
+// installAll runs the installation; InstallAll wraps it to fire stage hooks
+func (ti *ToolInstaller) installAll() error {
 	ti.ui.Info("📦 Starting tool installation...")
 	ti.ui.Info("")
 
-	// Get tools in dependency order
-	orderedTools, err := ti.toolsConfig.GetInstallOrder()
+	if err := ti.checkMacOS(); err != nil {
+		return err
+	}
+	ti.applyWithoutHomebrew()
+	ti.detectArtifactDomain()
+	ti.activateExistingHomebrew()
+	if err := ti.checkRosetta(); err != nil {
+		return err
+	}
+
+	// Tools in dependency order, batched by parallel group
+	toolGroups, err := Stages(ti.toolsConfig)
 	if err != nil {
-		return fmt.Errorf("failed to resolve dependencies: %w", err)
+		return err
+	}
+	declared := len(ti.toolsConfig.Tools)
+	if err := ti.checkShells(); err != nil {
+		return err
+	}
+	if ti.filter != nil {
+		for _, stage := range ti.filter.Stages {
+			if stage > len(toolGroups) {
+				return fmt.Errorf("stage %d does not exist (install has %d stages)", stage, len(toolGroups))
+			}
+		}
 	}
 
-	ti.ui.Info("Installing %d tools...", len(orderedTools))
+	if ti.filter.Empty() {
+		ti.ui.Info("Installing %d tools...", declared)
+	} else {
+		ti.ui.Info("Installing filtered subset of %d declared tools...", declared)
+	}
+	if held := ti.toolsConfig.HeldBack; len(held) > 0 {
+		ti.ui.Info("⏸  Not rolled out to this machine yet: %s ('devsetup rollout')", strings.Join(held, ", "))
+	}
 	ti.ui.Info("")
+	ti.resume()
 
-	// Group tools by parallel group
-	toolGroups := ti.groupToolsByParallelGroup(orderedTools)
-
-	// Install each group (sequential between groups, parallel within groups)
-	for _, group := range toolGroups {
-		if err := ti.installGroup(group); err != nil {
-			return fmt.Errorf("installation failed: %w", err)
-		}
+	// Install each group (parallel within groups; declared independent groups overlap, see schedule.go)
+	if err := ti.runStages(toolGroups, ti.stagePrerequisites(toolGroups)); err != nil {
+		return fmt.Errorf("installation failed: %w", err)
 	}
 
 	ti.ui.Info("")
@@ -82,6 +208,7 @@ func (ti *ToolInstaller) InstallAll() error {
 
 	// Save final state
 	if !ti.dryRun {
+		ti.finishRun()
 		ti.state.Version = ti.version
 		if err := config.SaveState(ti.state); err != nil {
 			ti.ui.Warning("⚠️  Failed to save state: %v", err)
@@ -91,40 +218,200 @@ func (ti *ToolInstaller) InstallAll() error {
 	return nil
 }
 
+// Stages returns the install batches in run order
+// What: Tools in dependency order, batched by parallel group (stage N is element N-1)
+// Why: Shared by the installer and by planning, so plans number stages exactly as install runs them
+// Params: toolsConfig - loaded tools configuration
+// Returns: Batches of tools, error if dependencies can't be resolved
+func Stages(toolsConfig *config.ToolsConfig) ([][]config.Tool, error) {
+	ordered, err := toolsConfig.GetInstallOrder()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	return (&ToolInstaller{toolsConfig: toolsConfig}).groupToolsByParallelGroup(ordered), nil
+}
+
+// checkShells is the install preflight for configured shells
+// What: Every shell an included command install would use must be on PATH
+// Returns: Error naming the missing shells
+func (ti *ToolInstaller) checkShells() error {
+	var shells []string
+	for _, tool := range ti.toolsConfig.Tools {
+		if ti.filter.IncludesTool(tool) && !tool.Install.IsArchive() && !tool.Install.IsScript() {
+			shells = append(shells, ti.toolsConfig.ShellFor(tool))
+		}
+	}
+	if err := config.CheckShells(shells); err != nil {
+		return fmt.Errorf("preflight failed: %w", err)
+	}
+	return nil
+}
+
+// checkMacOS is the install preflight for the tools.yaml macos section
+// What: Blocks install below min_version; warns about declared tools known to be broken on the running macOS
+// Why: Failing up front with guidance beats failing halfway through a 30-minute install
+// Returns: Error with upgrade guidance if the running macOS is below the minimum
+// Edge cases: An undeterminable macOS version (no sw_vers) only warns
+func (ti *ToolInstaller) checkMacOS() error {
+	requirements := ti.toolsConfig.MacOS
+	if requirements == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	current, err := ti.osVersion(ctx)
+	if err != nil {
+		ti.ui.Warning("⚠️  Could not determine macOS version, skipping macOS checks: %v", err)
+		return nil
+	}
+
+	if !requirements.Supports(current) {
+		return fmt.Errorf("macOS %s is below the supported minimum %s; update via System Settings → General → Software Update, then rerun devsetup install",
+			current, requirements.MinVersion)
+	}
+
+	for _, e := range requirements.BrokenOn(current) {
+		for _, tool := range ti.toolsConfig.Tools {
+			if tool.Name == e.Tool {
+				ti.ui.Warning("⚠️  %s is known to be incompatible with macOS %s+ (%s)", e.Tool, e.Since, e.Reason)
+			}
+		}
+	}
+	return nil
+}
+
 // groupToolsByParallelGroup groups tools for parallel execution
-// What: Groups tools by parallel_group field, preserving dependency order
-// Why: Tools in same group can run concurrently; different groups run sequentially
-// Params: tools - ordered tools list
-// Returns: Slice of tool groups (each group can run in parallel)
+// What: Schedules batches from the dependency DAG; each batch is the ready members of one parallel_group
+// Why: Grouping by adjacency split a parallel_group whenever another tool sat between its members
+// Params: tools - ordered tools list (GetInstallOrder; among ready groups, groupRanks order wins, then this order)
+// Returns: Slice of tool groups (each group can run in parallel; groups start in order unless declared independent)
+// Edge cases: A group waits until all its members are ready when possible, and is split only when the DAG forces it; ungrouped tools run alone
 func (ti *ToolInstaller) groupToolsByParallelGroup(tools []config.Tool) [][]config.Tool {
+	done := make(map[string]bool, len(tools))
+	remaining := append([]config.Tool(nil), tools...)
+
+	isReady := func(tool config.Tool) bool {
+		for _, dep := range ti.dependencies(tool) {
+			if !done[dep] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// groupReady reports whether every pending member of a parallel group can start now
+	groupReady := func(group string) bool {
+		for _, tool := range remaining {
+			if tool.Install.ParallelGroup == group && !isReady(tool) {
+				return false
+			}
+		}
+		return true
+	}
+
+	rank := ti.groupRanks(tools)
 	var groups [][]config.Tool
-	currentGroup := []config.Tool{}
-	lastParallelGroup := ""
+	for len(remaining) > 0 {
+		// Prefer the lowest-ranked ready tool whose whole group is ready; otherwise split the lowest-ranked ready group
+		lead, whole := -1, false
+		for i, tool := range remaining {
+			if !isReady(tool) {
+				continue
+			}
+			ready := tool.Install.ParallelGroup == "" || groupReady(tool.Install.ParallelGroup)
+			if lead == -1 || (ready && !whole) || (ready == whole && rank(tool) < rank(remaining[lead])) {
+				lead, whole = i, ready
+			}
+		}
+		if lead == -1 {
+			// Unreachable for a validated (acyclic) config; run the rest in order rather than loop forever
+			for _, tool := range remaining {
+				groups = append(groups, []config.Tool{tool})
+			}
+			break
+		}
 
-	for _, tool := range tools {
-		parallelGroup := tool.Install.ParallelGroup
+		batch := []config.Tool{remaining[lead]}
+		if group := remaining[lead].Install.ParallelGroup; group != "" {
+			batch = batch[:0]
+			for _, tool := range remaining {
+				if tool.Install.ParallelGroup == group && isReady(tool) {
+					batch = append(batch, tool)
+				}
+			}
+		}
 
-		// If this tool has different parallel group, start new group
-		if parallelGroup != lastParallelGroup && len(currentGroup) > 0 {
-			groups = append(groups, currentGroup)
-			currentGroup = []config.Tool{}
+		var rest []config.Tool
+		for _, tool := range remaining {
+			if !containsTool(batch, tool.Name) {
+				rest = append(rest, tool)
+			}
 		}
+		for _, tool := range batch {
+			done[tool.Name] = true
+		}
+		remaining = rest
+		groups = append(groups, batch)
+	}
 
-		currentGroup = append(currentGroup, tool)
-		lastParallelGroup = parallelGroup
+	return groups
+}
+
+// groupRanks orders the units stages are made of, so equally ready groups always run in the same order
+// What: Groups declared under groups: first, in declaration order; then other groups and ungrouped tools as they appear
+// Params: tools - ordered tools list
+// Returns: Rank of a tool's unit (lower runs first)
+func (ti *ToolInstaller) groupRanks(tools []config.Tool) func(config.Tool) int {
+	ranks := make(map[string]int)
+	if ti.toolsConfig != nil {
+		for _, group := range ti.toolsConfig.Groups {
+			if _, ok := ranks["group:"+group.Name]; !ok {
+				ranks["group:"+group.Name] = len(ranks)
+			}
+		}
 	}
+	key := func(tool config.Tool) string {
+		if tool.Install.ParallelGroup == "" {
+			return "tool:" + tool.Name
+		}
+		return "group:" + tool.Install.ParallelGroup
+	}
+	for _, tool := range tools {
+		if _, ok := ranks[key(tool)]; !ok {
+			ranks[key(tool)] = len(ranks)
+		}
+	}
+	return func(tool config.Tool) int { return ranks[key(tool)] }
+}
 
-	// Add final group
-	if len(currentGroup) > 0 {
-		groups = append(groups, currentGroup)
+// toolNames joins tool names for display
+func toolNames(tools []config.Tool) string {
+	return strings.Join(namesOf(tools), ", ")
+}
+
+// namesOf returns the tools' names
+func namesOf(tools []config.Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
 	}
+	return names
+}
 
-	return groups
+// containsTool reports whether a batch contains the named tool
+func containsTool(tools []config.Tool, name string) bool {
+	for _, tool := range tools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 // installGroup installs a group of tools (in parallel if >1 tool)
-// What: Installs all tools in a group concurrently
-// Why: Maximize installation speed within a group
+// What: Installs all tools in a group concurrently; a required failure cancels the rest of the group
+// Why: Maximize installation speed within a group without wasting time once the run is doomed
 // Params: tools - slice of tools to install
 // Returns: Error if any required tool fails
 func (ti *ToolInstaller) installGroup(tools []config.Tool) error {
@@ -134,73 +421,177 @@ func (ti *ToolInstaller) installGroup(tools []config.Tool) error {
 
 	// If only one tool, install sequentially
 	if len(tools) == 1 {
-		return ti.installTool(tools[0])
+		if err := ti.installTool(context.Background(), tools[0]); err != nil && !errors.Is(err, errCancelled) {
+			return err
+		}
+		return nil
 	}
 
 	// Multiple tools - install in parallel
 	ti.ui.Info("⚡ Installing %d tools in parallel...", len(tools))
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var firstError error
+	cancelled := 0
 
 	for _, tool := range tools {
 		wg.Add(1)
 		go func(t config.Tool) {
 			defer wg.Done()
 
-			if err := ti.installTool(t); err != nil {
-				mu.Lock()
-				if firstError == nil {
-					firstError = err
-				}
-				mu.Unlock()
+			err := ti.installTool(ctx, t)
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if errors.Is(err, errCancelled) {
+				cancelled++
+				return
+			}
+			if firstError == nil {
+				firstError = err
 			}
+			// Required failure: stop siblings still installing or waiting to start
+			cancel()
 		}(tool)
 	}
 
 	wg.Wait()
 
+	if cancelled > 0 {
+		ti.ui.Warning("⚠️  Cancelled %d tool(s) in group after required failure", cancelled)
+	}
+
 	return firstError
 }
 
 // installTool installs a single tool with idempotency check
 // What: Checks if tool exists, installs if missing, updates state
 // Why: Core installation logic with proper checking
-// Params: tool - Tool to install
-// Returns: Error if installation fails and tool is required
-func (ti *ToolInstaller) installTool(tool config.Tool) error {
-	// Check if already installed
-	if ti.isToolInstalled(tool) {
+// Params: ctx - group context (cancelled when a required sibling fails), tool - Tool to install
+// Returns: Error if installation fails and tool is required, errCancelled if stopped by the group
+func (ti *ToolInstaller) installTool(ctx context.Context, tool config.Tool) error {
+	ti.startTally(tool.Name)
+	if ctx.Err() != nil {
+		ti.ui.CancelTask(tool.Name)
+		ti.tally(tool, OutcomeCancelled, nil)
+		return errCancelled
+	}
+
+	// Refuse denied software before anything else (including the already-installed shortcut)
+	if ti.policies != nil {
+		if entry, pkg := ti.policies.DeniedTool(tool); entry != nil {
+			err := fmt.Errorf("%s is denied by org policy: %s", pkg, entry.Reason)
+			ti.ui.FailTask(tool.Name, err)
+			ti.fireTaskFailed(tool, err)
+			ti.tally(tool, OutcomeFailed, err)
+			if tool.Required {
+				return fmt.Errorf("required tool %s refused: %w", tool.Name, err)
+			}
+			return nil
+		}
+	}
+
+	// Finished by the install this one resumes
+	if ti.resumed(tool) {
+		ti.ui.Info("✓ %s (finished by the interrupted install)", tool.Name)
+		ti.tally(tool, OutcomeSkipped, nil)
+		return nil
+	}
+
+	// Check if already installed (a tool installed without Homebrew is reinstalled with it once brew works)
+	replacing := ti.replacesDirect(tool)
+	if !replacing && ti.isToolInstalled(tool) {
 		ti.ui.Info("✓ %s (already installed)", tool.Name)
+		if tool.Deprecated {
+			ti.ui.Warning("⚠️  %s is %s; remove it with 'devsetup uninstall --deprecated'", tool.Name, tool.DeprecationNote())
+		}
 
 		// Still update state with current version info
 		if !ti.dryRun {
-			version, path := ti.getToolInfo(tool)
-			config.MarkToolInstalled(ti.state, tool.Name, version, path)
+			ti.recordInstalled(tool, false)
 		}
+
+		// An installed tool's service may have been stopped since the last run
+		if err := ti.startService(ctx, tool); err != nil {
+			ti.ui.FailTask(tool.Name, err)
+			ti.fireTaskFailed(tool, err)
+			ti.tally(tool, OutcomeFailed, err)
+			if tool.Required {
+				return fmt.Errorf("required tool %s failed: %w", tool.Name, err)
+			}
+			ti.ui.Warning("⚠️  Optional tool %s failed: %v", tool.Name, err)
+			return nil
+		}
+		ti.markCompleted(tool)
+		ti.tally(tool, OutcomeSkipped, nil)
+		return nil
+	}
+
+	// Deprecated tools stay on machines that have them but aren't installed anywhere new
+	if tool.Deprecated {
+		ti.ui.Info("⊘ %s (%s; not installed)", tool.Name, tool.DeprecationNote())
+		ti.tally(tool, OutcomeSkipped, nil)
 		return nil
 	}
 
+	// Wait for a slot in the tool's scheduler class
+	release, err := ti.limiter.acquire(ctx, tool.Install.Class)
+	defer release()
+	if err != nil {
+		ti.ui.CancelTask(tool.Name)
+		ti.tally(tool, OutcomeCancelled, nil)
+		return errCancelled
+	}
+
 	ti.ui.StartTask(tool.Name)
 
 	// Dry run mode
 	if ti.dryRun {
 		ti.ui.Info("  [DRY RUN] Would install: %s", tool.Name)
 		ti.ui.CompleteTask(tool.Name)
+		ti.tally(tool, OutcomeOK, nil)
 		return nil
 	}
 
 	// Install the tool
-	ctx := context.Background()
+	installCtx := ctx
 	if tool.Install.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, tool.Install.Timeout)
+		installCtx, cancel = context.WithTimeout(ctx, tool.Install.Timeout)
 		defer cancel()
 	}
 
-	if err := ti.runInstallCommand(ctx, tool); err != nil {
+	err = ti.runInstall(installCtx, tool)
+	if errors.Is(err, prompt.ErrDeferred) {
+		// Ctrl-C at one of the tool's prompts skips just this tool, required or not
+		ti.ui.CancelTask(tool.Name)
+		ti.ui.Info("  ⏭  Deferred %s; run install again to finish it", tool.Name)
+		ti.tally(tool, OutcomeCancelled, err)
+		return nil
+	}
+	if err != nil {
+		ti.runCleanup(tool, err)
+	} else {
+		ti.applyQuarantine(ctx, tool)
+		err = ti.startService(ctx, tool)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			ti.ui.CancelTask(tool.Name)
+			ti.tally(tool, OutcomeCancelled, nil)
+			return errCancelled
+		}
+
 		ti.ui.FailTask(tool.Name, err)
+		ti.fireTaskFailed(tool, err)
+		ti.tally(tool, OutcomeFailed, err)
 
 		if tool.Required {
 			return fmt.Errorf("required tool %s failed: %w", tool.Name, err)
@@ -211,66 +602,228 @@ func (ti *ToolInstaller) installTool(tool config.Tool) error {
 	}
 
 	ti.ui.CompleteTask(tool.Name)
+	ti.tally(tool, OutcomeOK, nil)
+	shell := ti.toolsConfig.ShellFor(tool)
+	ti.checks.Invalidate(shell, tool.Check)
+	ti.checks.Invalidate(shell, tool.Unless)
+	if replacing {
+		ti.removeDirect(tool)
+	}
+
+	// Update state and flush it now, so a run killed later still records this install (and resumes after it)
+	ti.markCompleted(tool)
+	ti.recordInstalled(tool, true)
 
-	// Update state
+	return nil
+}
+
+// recordInstalled records a tool in state
+// What: Probes version/path, then updates state under stateMu and optionally saves it
+// Why: Parallel installs share one state; flushing per tool makes partial progress survive a closed terminal
+// Params: tool - installed tool, flush - save state.json immediately
+// Edge cases: flush marks a fresh install; a direct one is marked as such, and a tool found already installed
+// keeps its earlier direct marking
+func (ti *ToolInstaller) recordInstalled(tool config.Tool, flush bool) {
 	version, path := ti.getToolInfo(tool)
+
+	ti.stateMu.Lock()
+	defer ti.stateMu.Unlock()
+	previous := ti.state.Installed[tool.Name]
 	config.MarkToolInstalled(ti.state, tool.Name, version, path)
+	switch {
+	case flush && ti.direct[tool.Name]:
+		config.MarkToolDirect(ti.state, tool.Name, tool.Install.Placed())
+	case !flush && previous.Direct:
+		config.MarkToolDirect(ti.state, tool.Name, previous.DirectPaths)
+	}
+	if flush {
+		if err := config.SaveState(ti.state); err != nil {
+			ti.ui.Warning("⚠️  Failed to save state: %v", err)
+		}
+	}
+}
 
-	return nil
+// Failures returns the tools that failed in this run, each with its owner
+func (ti *ToolInstaller) Failures() []support.Failure {
+	ti.failMu.Lock()
+	defer ti.failMu.Unlock()
+	return append([]support.Failure(nil), ti.failures...)
+}
+
+// fireTaskFailed fires the task_failed hook for a tool and notes it for the failure summary
+func (ti *ToolInstaller) fireTaskFailed(tool config.Tool, err error) {
+	ti.failMu.Lock()
+	failure := support.NewFailure(config.NodeTool, tool.Name, tool.Required, err)
+	failure.Owner = ti.toolsConfig.OwnerOf(tool).String()
+	ti.failures = append(ti.failures, failure)
+	ti.failMu.Unlock()
+
+	ti.hooks.Fire(config.EventTaskFailed, map[string]string{
+		"kind":     config.NodeTool,
+		"name":     tool.Name,
+		"error":    err.Error(),
+		"required": strconv.FormatBool(tool.Required),
+	})
 }
 
 // isToolInstalled checks if a tool is already installed
-// What: Runs the check command to see if tool exists
+// What: Applies the tool's idempotency markers (see IsInstalled)
 // Why: Idempotency - don't reinstall what exists
 // Params: tool - Tool to check
 // Returns: True if tool is installed, false otherwise
 func (ti *ToolInstaller) isToolInstalled(tool config.Tool) bool {
-	if tool.Check == "" {
-		return false
-	}
+	return IsInstalled(context.Background(), tool, ti.toolsConfig.ShellFor(tool), ti.checks)
+}
 
-	// First check state
-	if config.IsToolInstalled(ti.state, tool.Name) {
-		// Verify it still exists
-		cmd := exec.Command("sh", "-c", tool.Check)
-		if err := cmd.Run(); err == nil {
+// IsInstalled reports whether a tool's install can be skipped
+// What: Cheapest marker first: creates path exists, then unless command, then check command passes
+// Why: Shared by install and plan; creates lets file-producing installs skip without spawning a shell
+// Params: ctx - bounds the commands, tool - tool declaration, shell - shell its commands run under (ToolsConfig.ShellFor),
+// checks - check cache (nil runs every command)
+// Returns: true if any marker says the tool is in place; false for a tool without markers
+func IsInstalled(ctx context.Context, tool config.Tool, shell string, checks *checkcache.Cache) bool {
+	if tool.Creates != "" {
+		if _, err := os.Stat(config.ExpandPath(tool.Creates)); err == nil {
 			return true
 		}
-		// Tool was in state but no longer exists, need to reinstall
+	}
+	if tool.Unless != "" && checks.Passes(ctx, shell, tool.Unless) {
+		return true
 	}
 
-	// Check via command
-	cmd := exec.Command("sh", "-c", tool.Check)
-	err := cmd.Run()
-	return err == nil
+	// State alone is not trusted: a recorded tool may have been removed since, so the markers always decide
+	return tool.Check != "" && checks.Passes(ctx, shell, tool.Check)
+}
+
+// runInstall performs the installation for the tool's install type
+// Params: ctx - context for timeout and cancellation, tool - Tool to install
+// Returns: Error if installation fails
+// Edge cases: An install killed as hung is run once more when on_hang is retry; the tool's running apps are quit
+// first (see apps.go) and a failed install is retried once if one was relaunched meanwhile
+func (ti *ToolInstaller) runInstall(ctx context.Context, tool config.Tool) error {
+	quit, err := ti.quitRunningApps(ctx, tool)
+	defer func() { ti.reopenApps(quit) }()
+	if err != nil {
+		return err
+	}
+
+	err = ti.runInstallOnce(ctx, tool)
+	if errors.Is(err, errHung) && tool.Install.OnHang == config.OnHangRetry && ctx.Err() == nil {
+		ti.ui.Info("🔁 Retrying %s after hang", tool.Name)
+		err = ti.runInstallOnce(ctx, tool)
+	}
+	if err != nil && ctx.Err() == nil {
+		// Some apps relaunch themselves (Docker's helper does); quit them again and retry once
+		again, quitErr := ti.quitRunningApps(ctx, tool)
+		quit = append(quit, again...)
+		if quitErr == nil && len(again) > 0 {
+			ti.ui.Info("🔁 Retrying %s with %s quit", tool.Name, strings.Join(again, ", "))
+			err = ti.runInstallOnce(ctx, tool)
+		}
+	}
+	return err
+}
+
+// runCleanup runs a tool's on_failure command after its install failed
+// What: Runs the command in the tool's shell with the failure in DEVSETUP_FAILED_NAME/ERROR; output is logged to cleanup-<tool>.log
+// Why: A failed or cancelled install shouldn't leave partial downloads or half-installed formulas behind
+// Params: tool - failed tool, cause - install error
+// Edge cases: Runs on its own timeout (the install context may be cancelled); a failing cleanup only warns
+func (ti *ToolInstaller) runCleanup(tool config.Tool, cause error) {
+	if tool.OnFailure == nil {
+		return
+	}
+	ti.ui.Info("🧹 Cleaning up after %s...", tool.Name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), tool.OnFailure.CleanupTimeout())
+	defer cancel()
+
+	out := capture.Open("cleanup-" + tool.Name)
+	defer out.Close()
+
+	cmd := exec.CommandContext(ctx, ti.toolsConfig.ShellFor(tool), "-c", tool.OnFailure.Command)
+	out.Attach(cmd, os.Stdout, os.Stderr)
+	cmd.Env = append(ti.installEnv(), tool.OnFailure.Env(tool.Name, cause)...)
+	if err := out.Wrap(cmd.Run()); err != nil {
+		ti.ui.Warning("⚠️  Cleanup for %s failed: %v", tool.Name, err)
+	}
+}
+
+// runInstallOnce dispatches on the tool's install type
+func (ti *ToolInstaller) runInstallOnce(ctx context.Context, tool config.Tool) error {
+	if tool.Install.IsArchive() {
+		return ti.installArchive(ctx, tool)
+	}
+	if tool.Install.IsScript() {
+		return ti.installScript(ctx, tool)
+	}
+	switch tool.Install.Type {
+	case config.InstallTypeDMG:
+		return ti.installDMG(ctx, tool)
+	case config.InstallTypeCLT:
+		return ti.installCLT(ctx, tool)
+	}
+	return ti.runInstallCommand(ctx, tool)
 }
 
 // runInstallCommand executes the installation command
 // What: Runs the shell command to install the tool
 // Why: Actual installation work
-// Params: ctx - context for timeout, tool - Tool to install
+// Params: ctx - context for timeout and cancellation, tool - Tool to install
 // Returns: Error if command fails
+// Edge cases: Runs in its own process group so cancellation also stops children (e.g. brew under the shell);
+// output goes to ~/.local/share/devsetup/logs/install-<tool>.log (and the terminal with --verbose), the error quotes its excerpt
 func (ti *ToolInstaller) runInstallCommand(ctx context.Context, tool config.Tool) error {
-	cmd := exec.CommandContext(ctx, "sh", "-c", tool.Install.Command)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	out := capture.Open("install-" + tool.Name)
+	defer out.Close()
+
+	ctx, kill := context.WithCancel(ctx)
+	defer kill()
+
+	cmd := exec.CommandContext(ctx, ti.toolsConfig.ShellFor(tool), "-c", tool.Install.Command)
+	out.Attach(cmd, os.Stdout, os.Stderr)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
 
 	// Set environment
-	cmd.Env = os.Environ()
+	cmd.Env = ti.installEnv()
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("install command failed: %w", err)
+	stop := ti.watchHang(ctx, tool, out, kill)
+	err := cmd.Run()
+	if stop() {
+		return fmt.Errorf("install command %w", errHung)
+	}
+	if err != nil {
+		return fmt.Errorf("install command failed: %w", out.Wrap(err))
 	}
 
 	return nil
 }
 
+// toolInfoTimeout bounds the version and path probes recorded after an install
+const toolInfoTimeout = 30 * time.Second
+
 // getToolInfo extracts version and path of installed tool
 // What: Gets version string and binary path for installed tool
 // Why: Populate state with installation details
 // Params: tool - Installed tool
 // Returns: version string and path string
 func (ti *ToolInstaller) getToolInfo(tool config.Tool) (string, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), toolInfoTimeout)
+	defer cancel()
+	return GetToolInfo(ctx, tool, ti.toolsConfig.ShellFor(tool))
+}
+
+// GetToolInfo probes the version and path of an installed tool
+// What: Runs version_command (or common version flags) and `command -v` for the tool binary
+// Why: Shared by installer (state recording) and verifier (deep drift checks)
+// Params: ctx - bounds the probe commands (they are killed with their children when it ends), tool - Tool to probe,
+// shell - shell the probes run under (ToolsConfig.ShellFor)
+// Returns: version string normalized with the tool's version_pattern ("2.43.0", not "git version 2.43.0 (Apple Git-146)") and path string ("unknown" when not determinable)
+// Example: version, path := GetToolInfo(ctx, tool, toolsConfig.ShellFor(tool))
+func GetToolInfo(ctx context.Context, tool config.Tool, shell string) (string, string) {
 	// Try to get version
 	version := "unknown"
 	versionCommands := []string{
@@ -278,13 +831,14 @@ func (ti *ToolInstaller) getToolInfo(tool config.Tool) (string, string) {
 		tool.Name + " -v",
 		tool.Name + " version",
 	}
+	if tool.VersionCommand != "" {
+		versionCommands = []string{tool.VersionCommand}
+	}
 
 	for _, cmd := range versionCommands {
-		if output, err := exec.Command("sh", "-c", cmd).Output(); err == nil {
-			version = strings.TrimSpace(string(output))
-			// Take first line only
-			if lines := strings.Split(version, "\n"); len(lines) > 0 {
-				version = lines[0]
+		if output, err := probe(ctx, shell, cmd); err == nil {
+			if v := versionpkg.Normalize(string(output), tool.VersionPattern); v != "" {
+				version = v
 			}
 			break
 		}
@@ -292,9 +846,20 @@ func (ti *ToolInstaller) getToolInfo(tool config.Tool) (string, string) {
 
 	// Get path
 	path := "unknown"
-	if output, err := exec.Command("sh", "-c", "command -v "+tool.Name).Output(); err == nil {
+	if output, err := probe(ctx, shell, "command -v "+tool.Binary()); err == nil {
 		path = strings.TrimSpace(string(output))
 	}
 
 	return version, path
 }
+
+// probe runs a short shell command and returns its stdout
+// Edge cases: Runs in its own process group, so a hung `tool --version` under the shell is killed with it
+func probe(ctx context.Context, shell, command string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, shell, "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd.Output()
+}
@@ -0,0 +1,269 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestGroupToolsByParallelGroup(t *testing.T) {
+	tool := func(name, group string, deps ...string) config.Tool {
+		return config.Tool{Name: name, Install: config.ToolInstall{ParallelGroup: group}, DependsOn: deps}
+	}
+
+	tests := []struct {
+		name  string
+		tools []config.Tool
+		want  [][]string
+	}{
+		{
+			name: "group not split by interleaved tool",
+			tools: []config.Tool{
+				tool("homebrew", ""),
+				tool("git", "cli", "homebrew"),
+				tool("zed", "cask", "homebrew"),
+				tool("gh", "cli", "homebrew"),
+			},
+			want: [][]string{{"homebrew"}, {"git", "gh"}, {"zed"}},
+		},
+		{
+			name: "group waits for member prerequisite",
+			tools: []config.Tool{
+				tool("a", "g"),
+				tool("x", ""),
+				tool("b", "g", "x"),
+			},
+			want: [][]string{{"x"}, {"a", "b"}},
+		},
+		{
+			name: "intra-group dependency splits group",
+			tools: []config.Tool{
+				tool("a", "g"),
+				tool("b", "g", "a"),
+			},
+			want: [][]string{{"a"}, {"b"}},
+		},
+	}
+
+	ti := &ToolInstaller{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, batch := range ti.groupToolsByParallelGroup(tt.tools) {
+				var names []string
+				for _, tool := range batch {
+					names = append(names, tool.Name)
+				}
+				got = append(got, names)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsInstalledMarkers(t *testing.T) {
+	dir := t.TempDir()
+	created := filepath.Join(dir, "sdk")
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		tool config.Tool
+		want bool
+	}{
+		{"no markers", config.Tool{Name: "x"}, false},
+		{"creates missing", config.Tool{Name: "x", Creates: created}, false},
+		{"unless passes", config.Tool{Name: "x", Unless: "true"}, true},
+		{"unless fails, check passes", config.Tool{Name: "x", Unless: "false", Check: "true"}, true},
+		{"check fails", config.Tool{Name: "x", Check: "false"}, false},
+	}
+	for _, tt := range tests {
+		if got := IsInstalled(ctx, tt.tool, config.ShellSh, nil); got != tt.want {
+			t.Errorf("%s: IsInstalled() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// An existing creates path wins without running the (failing) check
+	if err := os.Mkdir(created, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !IsInstalled(ctx, config.Tool{Name: "x", Creates: created, Check: "false"}, config.ShellSh, nil) {
+		t.Error("IsInstalled() ignored an existing creates path")
+	}
+}
+
+func TestHungInstallRetried(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tool := config.Tool{Name: "stuck", Install: config.ToolInstall{
+		Command:          "sleep 30",
+		ExpectedDuration: 100 * time.Millisecond,
+		OnHang:           config.OnHangRetry,
+	}}
+	tc := &config.ToolsConfig{Tools: []config.Tool{tool}}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+
+	start := time.Now()
+	err := ti.runInstall(context.Background(), tool)
+	if !errors.Is(err, errHung) {
+		t.Fatalf("runInstall() = %v, want errHung", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("hung install took %s to be killed twice", elapsed)
+	}
+}
+
+func TestGroupCancelledOnRequiredFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// One sibling is mid-install and one waits for the single interactive slot when the required tool fails
+	tools := []config.Tool{
+		{Name: "broken", Check: "false", Required: true, Install: config.ToolInstall{Command: "sleep 0.2; exit 3"}},
+		{Name: "slow", Check: "false", Install: config.ToolInstall{Command: "sleep 30", Class: config.ClassInteractive}},
+		{Name: "queued", Check: "false", Install: config.ToolInstall{Command: "sleep 30", Class: config.ClassInteractive}},
+	}
+	tc := &config.ToolsConfig{Tools: tools}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+
+	ti.beginStage(1, namesOf(tools))
+	start := time.Now()
+	err := ti.installGroup(tools)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("group took %s, want siblings stopped at the failure", elapsed)
+	}
+	if err == nil || errors.Is(err, errCancelled) || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("installGroup() = %v, want the required failure", err)
+	}
+
+	outcomes := make(map[string]string)
+	for _, task := range ti.TaskResults() {
+		outcomes[task.Name] = task.Outcome
+	}
+	if want := map[string]string{"broken": OutcomeFailed, "slow": OutcomeCancelled, "queued": OutcomeCancelled}; !reflect.DeepEqual(outcomes, want) {
+		t.Errorf("task outcomes = %v, want %v", outcomes, want)
+	}
+	if r := ti.Results()[0]; r.Failed != 1 || r.Cancelled != 2 {
+		t.Errorf("stage result = %+v, want 1 failed and 2 cancelled", r)
+	}
+}
+
+func TestGetToolInfoTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The shell's child holds stdout open; it must be killed with the shell for the probe to return
+	start := time.Now()
+	version, _ := GetToolInfo(ctx, config.Tool{Name: "stuck", VersionCommand: "sleep 30; echo 1.0"}, config.ShellSh)
+	if version != "unknown" {
+		t.Errorf("version = %q, want unknown", version)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hung version command took %s to be killed", elapsed)
+	}
+}
+
+func TestOnFailureCleanup(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	partial := filepath.Join(t.TempDir(), "partial")
+	marker := filepath.Join(t.TempDir(), "cleaned")
+
+	tool := config.Tool{
+		Name:      "broken",
+		Install:   config.ToolInstall{Command: "mkdir " + partial + " && exit 3"},
+		OnFailure: &config.OnFailure{Command: `rm -rf ` + partial + ` && echo "$DEVSETUP_FAILED_NAME" > ` + marker},
+	}
+	tc := &config.ToolsConfig{Tools: []config.Tool{tool}}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+
+	if err := ti.installTool(context.Background(), tool); err != nil {
+		t.Fatalf("optional tool failure should not be returned: %v", err)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Error("on_failure did not remove the partial install")
+	}
+	if data, _ := os.ReadFile(marker); string(data) != "broken\n" {
+		t.Errorf("DEVSETUP_FAILED_NAME = %q, want broken", data)
+	}
+}
+
+func TestDeniedToolRefused(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "installed")
+
+	tool := config.Tool{Name: "node14", Check: "false", Required: true, Install: config.ToolInstall{Command: "brew install node@14; touch " + marker}}
+	tc := &config.ToolsConfig{Tools: []config.Tool{tool}}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+	ti.SetPolicies(&config.PolicyConfig{Denied: []config.DeniedEntry{{Name: "node@1[0-6]", Kind: config.DeniedFormula, Reason: "EOL Node.js"}}})
+
+	err := ti.installTool(context.Background(), tool)
+	if err == nil || !strings.Contains(err.Error(), "EOL Node.js") {
+		t.Fatalf("installTool() = %v, want the policy refusal", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("denied tool was installed")
+	}
+	if tasks := ti.tasks; len(tasks) != 1 || tasks[0].Outcome != OutcomeFailed {
+		t.Errorf("tasks = %+v, want one failed", tasks)
+	}
+}
+
+func TestDeprecatedToolNotInstalled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "installed")
+
+	tool := config.Tool{Name: "yarn-classic", Check: "false", Deprecated: true, Install: config.ToolInstall{Command: "touch " + marker}}
+	tc := &config.ToolsConfig{Tools: []config.Tool{tool}}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+
+	if err := ti.installTool(context.Background(), tool); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("deprecated tool was installed on a machine without it")
+	}
+	if tasks := ti.tasks; len(tasks) != 1 || tasks[0].Outcome != OutcomeSkipped {
+		t.Errorf("tasks = %+v, want one skipped", tasks)
+	}
+}
+
+func TestCheckRosetta(t *testing.T) {
+	tc := &config.ToolsConfig{Tools: []config.Tool{{Name: "legacy", Rosetta: true, Required: true}, {Name: "jq"}}}
+	ti := NewToolInstaller(tc, &config.State{}, ui.NewEventUI(io.Discard, nil), false, "test")
+	ti.SetAssumeYes(true)
+
+	installs := 0
+	missing := true
+	ti.rosettaMissing = func(context.Context) bool { return missing }
+	ti.installRosetta = func(context.Context) error { installs++; return nil }
+
+	if err := ti.checkRosetta(); err != nil || installs != 1 {
+		t.Fatalf("checkRosetta() = %v with %d installs, want nil and 1", err, installs)
+	}
+
+	missing = false
+	if err := ti.checkRosetta(); err != nil || installs != 1 {
+		t.Errorf("Rosetta present: checkRosetta() = %v with %d installs, want no new install", err, installs)
+	}
+
+	missing = true
+	ti.installRosetta = func(context.Context) error { return errors.New("softwareupdate failed") }
+	if err := ti.checkRosetta(); err == nil {
+		t.Error("failed Rosetta install for a required tool should fail the preflight")
+	}
+
+	ti.SetFilter(&config.RunFilter{SkipTasks: []string{"legacy"}})
+	if err := ti.checkRosetta(); err != nil {
+		t.Errorf("filtered-out Intel-only tool should not need Rosetta: %v", err)
+	}
+}
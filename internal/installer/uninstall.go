@@ -0,0 +1,75 @@
+// File: internal/installer/uninstall.go
+// Purpose: Removes installed tools with their inferred (or declared) uninstall command
+// Problem: Tools could be installed and rolled back in config, but removing one meant looking up how it was
+//          installed and undoing it by hand, then editing state.json so status stopped listing it
+// Role: Runs a tool's UninstallCommand, logs it like an install, and drops the tool from state
+// Usage: err := toolInstaller.Uninstall(ctx, tool, force)  (devsetup uninstall, generations rollback --uninstall)
+// Design choices: Refuses while another installed tool depends on it unless forced; the command's output goes to
+//                 uninstall-<tool>.log and the error quotes its tail, same as installs
+// Assumptions: Uninstall commands are idempotent enough to rerun after a partial failure
+
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// Uninstall removes an installed tool
+// What: Runs the tool's uninstall command (declared or inferred), then removes it from state and saves state
+// Why: Backs devsetup uninstall and rollback --uninstall
+// Params: ctx - bounds the command, tool - tool to remove, force - remove even if installed tools depend on it
+// Returns: Error if the command can't be inferred, dependents are installed, or the command fails
+func (ti *ToolInstaller) Uninstall(ctx context.Context, tool config.Tool, force bool) error {
+	command := tool.UninstallCommand()
+	if command == "" {
+		return fmt.Errorf("can't infer how to uninstall %s (add uninstall: to its tools.yaml entry)", tool.Name)
+	}
+	if dependents := ti.installedDependents(tool.Name); len(dependents) > 0 && !force {
+		return fmt.Errorf("%s is needed by installed %s (use --force to remove it anyway)", tool.Name, strings.Join(dependents, ", "))
+	}
+
+	if ti.dryRun {
+		ti.ui.Info("  [DRY RUN] Would run: %s", command)
+		return nil
+	}
+
+	out := capture.Open("uninstall-" + tool.Name)
+	defer out.Close()
+
+	cmd := exec.CommandContext(ctx, ti.toolsConfig.ShellFor(tool), "-c", command)
+	out.Attach(cmd, os.Stdout, os.Stderr)
+	cmd.Env = ti.installEnv()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("uninstall command failed: %w", out.Wrap(err))
+	}
+
+	shell := ti.toolsConfig.ShellFor(tool)
+	ti.checks.Invalidate(shell, tool.Check)
+	ti.checks.Invalidate(shell, tool.Unless)
+	_ = ti.checks.Save()
+
+	ti.stateMu.Lock()
+	defer ti.stateMu.Unlock()
+	config.MarkToolUninstalled(ti.state, tool.Name)
+	return config.SaveState(ti.state)
+}
+
+// installedDependents lists installed tools that declare depends_on the named tool
+func (ti *ToolInstaller) installedDependents(name string) []string {
+	var dependents []string
+	for _, t := range ti.toolsConfig.Tools {
+		for _, dep := range t.DependsOn {
+			if dep == name && config.IsToolInstalled(ti.state, t.Name) {
+				dependents = append(dependents, t.Name)
+			}
+		}
+	}
+	return dependents
+}
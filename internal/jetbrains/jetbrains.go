@@ -0,0 +1,204 @@
+// File: internal/jetbrains/jetbrains.go
+// Purpose: JetBrains IDE lookup and plugin management (IntelliJ IDEA, Android Studio, GoLand, PyCharm, WebStorm)
+// Problem: Required plugins were a list in the onboarding doc; new machines missed some, and nobody noticed until
+//          a build or inspection behaved differently
+// Role: Finds an IDE's command-line launcher (Toolbox scripts, linked CLI, or the app bundle), reads which plugins
+//       its newest configuration has installed, and installs missing ones with the launcher's installPlugins command
+// Usage: missing, err := jetbrains.Missing("idea", ids); launcher, err := jetbrains.Launcher("idea")
+// Design choices: Installed plugins are read from plugin.xml inside each plugin's jars (directory names aren't
+//                 plugin IDs); installPlugins is the IDE's own headless installer, so Marketplace compatibility and
+//                 dependencies are resolved by the IDE rather than devsetup
+// Assumptions: The IDE isn't running during installPlugins (it refuses to start a second instance); versions are
+//              pinned by the tool that installs the IDE (tools.yaml dmg install), not here
+
+package jetbrains
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/version"
+)
+
+// ide describes where an IDE lives on disk
+type ide struct {
+	name   string // display name
+	app    string // bundle in /Applications or ~/Applications
+	bin    string // launcher name (Toolbox script, CLI link, Contents/MacOS binary)
+	vendor string // directory under ~/Library/Application Support
+	prefix string // configuration directory prefix, followed by the version
+}
+
+// ides maps config IDE names to their layout
+var ides = map[string]ide{
+	config.IDEIntelliJ:      {"IntelliJ IDEA", "IntelliJ IDEA.app", "idea", "JetBrains", "IntelliJIdea"},
+	config.IDEIntelliJCE:    {"IntelliJ IDEA CE", "IntelliJ IDEA CE.app", "idea", "JetBrains", "IdeaIC"},
+	config.IDEAndroidStudio: {"Android Studio", "Android Studio.app", "studio", "Google", "AndroidStudio"},
+	config.IDEGoLand:        {"GoLand", "GoLand.app", "goland", "JetBrains", "GoLand"},
+	config.IDEPyCharm:       {"PyCharm", "PyCharm.app", "pycharm", "JetBrains", "PyCharm"},
+	config.IDEWebStorm:      {"WebStorm", "WebStorm.app", "webstorm", "JetBrains", "WebStorm"},
+}
+
+// Name returns the IDE's display name
+// Example: Name("studio") → "Android Studio"
+func Name(name string) string {
+	if d, ok := ides[name]; ok {
+		return d.name
+	}
+	return name
+}
+
+// Launcher finds the IDE's command-line launcher
+// What: Looks on PATH, then in Toolbox's generated scripts, then inside the app bundle in /Applications and ~/Applications
+// Params: name - config IDE name (idea, studio, ...)
+// Returns: Launcher path, error if the IDE isn't installed
+func Launcher(name string) (string, error) {
+	d, ok := ides[name]
+	if !ok {
+		return "", fmt.Errorf("unknown IDE %s", name)
+	}
+	if path, err := exec.LookPath(d.bin); err == nil {
+		return path, nil
+	}
+	home, _ := os.UserHomeDir()
+	for _, path := range []string{
+		filepath.Join(home, "Library", "Application Support", "JetBrains", "Toolbox", "scripts", d.bin),
+		filepath.Join("/Applications", d.app, "Contents", "MacOS", d.bin),
+		filepath.Join(home, "Applications", d.app, "Contents", "MacOS", d.bin),
+	} {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not installed (no %s launcher; in Toolbox, enable Settings → Tools → Generate shell scripts)", d.name, d.bin)
+}
+
+// ConfigDir returns the newest configuration directory of an IDE
+// Returns: e.g. ~/Library/Application Support/JetBrains/IntelliJIdea2024.3, "" if the IDE was never started
+func ConfigDir(name string) string {
+	d, ok := ides[name]
+	if !ok {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	matches, _ := filepath.Glob(filepath.Join(home, "Library", "Application Support", d.vendor, d.prefix+"*"))
+
+	newest, newestVersion := "", ""
+	for _, dir := range matches {
+		v := strings.TrimPrefix(filepath.Base(dir), d.prefix)
+		// PyCharm's prefix also matches PyCharmCE2024.3; versions start with a digit
+		if v == "" || v[0] < '0' || v[0] > '9' {
+			continue
+		}
+		if newest == "" || version.Compare(v, newestVersion) > 0 {
+			newest, newestVersion = dir, v
+		}
+	}
+	return newest
+}
+
+// InstalledPlugins returns the IDs of the plugins installed in the IDE's newest configuration
+// Returns: Set of plugin IDs (empty when the IDE was never started or has no plugins)
+// Edge cases: Plugins without an <id> are keyed by <name>, which is what the IDE uses as their ID
+func InstalledPlugins(name string) map[string]bool {
+	installed := make(map[string]bool)
+	dir := ConfigDir(name)
+	if dir == "" {
+		return installed
+	}
+	jars, _ := filepath.Glob(filepath.Join(dir, "plugins", "*", "lib", "*.jar"))
+	single, _ := filepath.Glob(filepath.Join(dir, "plugins", "*.jar"))
+	for _, jar := range append(jars, single...) {
+		if id := pluginID(jar); id != "" {
+			installed[id] = true
+		}
+	}
+	return installed
+}
+
+// Missing returns the plugins an IDE doesn't have yet, in the given order
+func Missing(name string, plugins []string) []string {
+	installed := InstalledPlugins(name)
+	var missing []string
+	for _, id := range plugins {
+		if !installed[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// HasPlugin reports whether an IDE has a plugin installed (jetbrains_plugin verify checks)
+func HasPlugin(name, id string) bool {
+	return InstalledPlugins(name)[id]
+}
+
+// InstallPlugins installs plugins with the IDE's headless installer (`<launcher> installPlugins <id>...`)
+// Params: ctx - bounds the install, name - config IDE name, plugins - plugin IDs
+// Returns: Error if the launcher is missing or the installer fails (e.g. the IDE is running)
+// Edge cases: Output goes to ~/.local/share/devsetup/logs/setup-jetbrains-<ide>.log; errors quote its tail
+func InstallPlugins(ctx context.Context, name string, plugins []string) error {
+	launcher, err := Launcher(name)
+	if err != nil {
+		return err
+	}
+	out := capture.Open("setup-jetbrains-" + name)
+	defer out.Close()
+
+	cmd := exec.CommandContext(ctx, launcher, append([]string{"installPlugins"}, plugins...)...)
+	cmd.Env = os.Environ()
+	out.Attach(cmd, nil, nil)
+	return out.Wrap(cmd.Run())
+}
+
+// pluginDescriptor is the part of META-INF/plugin.xml naming a plugin
+type pluginDescriptor struct {
+	ID   string `xml:"id"`
+	Name string `xml:"name"`
+}
+
+// pluginID reads a plugin's ID from META-INF/plugin.xml inside a jar
+// Returns: ID (or name when the descriptor has no id), "" if the jar has no descriptor
+func pluginID(jar string) string {
+	r, err := zip.OpenReader(jar)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = r.Close() }()
+
+	for _, f := range r.File {
+		if f.Name != "META-INF/plugin.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return ""
+		}
+		defer func() { _ = rc.Close() }()
+		return parseDescriptor(rc)
+	}
+	return ""
+}
+
+// parseDescriptor extracts the ID from plugin.xml content
+func parseDescriptor(r io.Reader) string {
+	var desc pluginDescriptor
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	if err := dec.Decode(&desc); err != nil {
+		return ""
+	}
+	if id := strings.TrimSpace(desc.ID); id != "" {
+		return id
+	}
+	return strings.TrimSpace(desc.Name)
+}
@@ -0,0 +1,88 @@
+package jetbrains
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// writePlugin creates a plugin jar with a META-INF/plugin.xml descriptor
+func writePlugin(t *testing.T, jar, descriptor string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(jar), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("META-INF/plugin.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(descriptor)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstalledPlugins(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	support := filepath.Join(home, "Library", "Application Support", "JetBrains")
+
+	// The newest configuration wins (2024.10 > 2024.9); PyCharm CE's directory isn't PyCharm's
+	old := filepath.Join(support, "PyCharm2024.9")
+	newest := filepath.Join(support, "PyCharm2024.10")
+	writePlugin(t, filepath.Join(old, "plugins", "ruff", "lib", "ruff.jar"), `<idea-plugin><id>com.koxudaxi.ruff</id></idea-plugin>`)
+	writePlugin(t, filepath.Join(newest, "plugins", "detekt", "lib", "detekt-idea.jar"),
+		`<?xml version="1.0"?><idea-plugin url="https://detekt.dev"><name>Detekt</name><id>detekt</id><vendor>detekt</vendor></idea-plugin>`)
+	writePlugin(t, filepath.Join(newest, "plugins", "legacy.jar"), `<idea-plugin><name>Legacy Tool</name></idea-plugin>`)
+	writePlugin(t, filepath.Join(support, "PyCharmCE2025.1", "plugins", "x", "lib", "x.jar"), `<idea-plugin><id>ce.only</id></idea-plugin>`)
+
+	if got := ConfigDir(config.IDEPyCharm); got != newest {
+		t.Errorf("ConfigDir = %s, want %s", got, newest)
+	}
+	want := map[string]bool{"detekt": true, "Legacy Tool": true}
+	if got := InstalledPlugins(config.IDEPyCharm); !reflect.DeepEqual(got, want) {
+		t.Errorf("InstalledPlugins = %v, want %v", got, want)
+	}
+	if got := Missing(config.IDEPyCharm, []string{"detekt", "com.koxudaxi.ruff"}); !reflect.DeepEqual(got, []string{"com.koxudaxi.ruff"}) {
+		t.Errorf("Missing = %v", got)
+	}
+	if HasPlugin(config.IDEGoLand, "detekt") {
+		t.Error("GoLand has no configuration, so no plugins")
+	}
+}
+
+func TestLauncher(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", t.TempDir())
+
+	if _, err := Launcher(config.IDEAndroidStudio); err == nil || !strings.Contains(err.Error(), "Generate shell scripts") {
+		t.Errorf("missing IDE should point at Toolbox's shell scripts, got %v", err)
+	}
+
+	script := filepath.Join(home, "Library", "Application Support", "JetBrains", "Toolbox", "scripts", "studio")
+	if err := os.MkdirAll(filepath.Dir(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Launcher(config.IDEAndroidStudio); err != nil || got != script {
+		t.Errorf("Launcher = %s, %v; want the Toolbox script", got, err)
+	}
+}
@@ -0,0 +1,79 @@
+// File: internal/keychain/keychain.go
+// Purpose: Secrets in the macOS login keychain (generic passwords) for setup tasks
+// Problem: Tokens prompted during setup ended up as plain `export` lines in ~/.zshrc, readable by anything that
+//          reads dotfiles (backups, sync, screen shares of a terminal)
+// Role: Reads and stores generic passwords by service name, and renders the shell snippet that reads one back
+// Usage: token, err := keychain.Get("devsetup.turbo-remote-cache"); err := keychain.Set(service, token)
+// Design choices: The `security` CLI instead of cgo Keychain APIs (no cgo in the build); values are written through
+//                 `security -i` on stdin so they never appear in a process list; the account is the login user
+// Assumptions: Login keychain unlocked (true in a normal GUI session)
+
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/redact"
+)
+
+// ErrNotFound is returned by Get when the keychain has no item for the service
+var ErrNotFound = errors.New("not in keychain")
+
+// account is the keychain account items are stored under
+func account() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// Get reads a secret from the login keychain
+// Params: service - item service name (e.g. "devsetup.turbo-remote-cache")
+// Returns: Secret (registered for redaction), ErrNotFound when missing, other errors when security fails
+func Get(service string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account(), "-w").Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == 44 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to read %s from the keychain: %w", service, err)
+	}
+	value := strings.TrimRight(string(out), "\n")
+	redact.Register(value)
+	return value, nil
+}
+
+// Set stores a secret in the login keychain, replacing an existing item
+// Params: service - item service name, value - secret
+// Returns: Error if security fails
+func Set(service, value string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(service), quote(account()), quote(value)))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store %s in the keychain: %w: %s", service, err, strings.TrimSpace(redact.String(string(out))))
+	}
+	return nil
+}
+
+// ReadCommand returns a shell command printing a secret from the keychain
+// Example: ReadCommand("devsetup.claude-api-key") → "security find-generic-password -s 'devsetup.claude-api-key' -w"
+func ReadCommand(service string) string {
+	return "security find-generic-password -s '" + strings.ReplaceAll(service, "'", `'\''`) + "' -w"
+}
+
+// ShellRead returns a shell command substitution reading a secret from the keychain
+// Example: ShellRead("devsetup.nx-remote-cache") → "$(security find-generic-password -s 'devsetup.nx-remote-cache' -w 2>/dev/null)"
+func ShellRead(service string) string {
+	return "$(" + ReadCommand(service) + " 2>/dev/null)"
+}
+
+// quote double-quotes a word for `security -i`, which splits its input lines like a shell
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,270 @@
+// File: internal/lint/lint.go
+// Purpose: Best-practice checks of tools.yaml and setup.yaml (devsetup config lint)
+// Problem: Validation only rejects configs that can't run; configs that run but re-install every time, hang without
+//          a timeout, or hand-write what a typed install does were only caught in review, if at all
+// Role: Runs a fixed set of rules over the loaded configs and reports each finding with its file:line and a fix
+// Usage: findings := lint.Run(toolsCfg, setupCfg); lint.Rules lists the rule names
+// Design choices: Rules only read the configs (nothing is executed, so lint runs in CI on any OS); findings are
+//                 advisory and ordered by file position; rules are plain functions so adding one is one entry in rules
+// Assumptions: Configs were loaded by LoadToolsConfig/LoadSetupConfig, so typed installs are already resolved and
+//              line numbers are set
+
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// Rule names
+const (
+	RuleIdempotency      = "idempotency"
+	RuleTimeout          = "timeout"
+	RuleRequiredOptional = "required-after-optional"
+	RuleTypedInstall     = "typed-install"
+	RuleUnreferenced     = "unreferenced"
+)
+
+// Finding is one rule violation
+type Finding struct {
+	// Rule is the rule name (e.g. timeout)
+	Rule string
+
+	// Location is "file:line" of the offending entry ("file" if the line is unknown)
+	Location string
+
+	// Subject names the tool, task, or group
+	Subject string
+
+	// Message says what is wrong and how to fix it
+	Message string
+
+	// line orders findings within a file
+	line int
+}
+
+// rule checks both configs and returns its findings
+type rule func(tools *config.ToolsConfig, setup *config.SetupConfig) []Finding
+
+// rules run in this order; findings are sorted afterwards
+var rules = []rule{idempotency, timeouts, requiredAfterOptional, typedInstalls, unreferenced}
+
+// Rules lists every rule name, for help output
+var Rules = []string{RuleIdempotency, RuleTimeout, RuleRequiredOptional, RuleTypedInstall, RuleUnreferenced}
+
+// idempotentStep matches step commands that are safe to repeat, so they need no creates
+var idempotentStep = regexp.MustCompile(`^(mkdir -p|ln -sfn?|touch) `)
+
+// typedPatterns map hand-written install commands to the typed install that replaces them
+var typedPatterns = []struct {
+	pattern *regexp.Regexp
+	suggest string
+}{
+	{regexp.MustCompile(`^brew install --cask [\w@./+-]+$`), "type: cask"},
+	{regexp.MustCompile(`^brew install [\w@./+-]+$`), "type: brew"},
+	{regexp.MustCompile(`^npm install -g [\w@./+-]+$`), "type: npm"},
+	{regexp.MustCompile(`^go install [\w@./+-]+$`), "type: go"},
+	{regexp.MustCompile(`^cargo install( --locked)? [\w-]+$`), "type: cargo"},
+	{regexp.MustCompile(`(curl|wget)[^|;&]*\|\s*(sudo\s+)?(ba|z)?sh\b`), "type: script with a checksum"},
+}
+
+// Run applies every rule
+// Params: tools - tools configuration, setup - setup configuration (either may be nil)
+// Returns: Findings ordered by file, then line
+func Run(tools *config.ToolsConfig, setup *config.SetupConfig) []Finding {
+	if tools == nil {
+		tools = &config.ToolsConfig{}
+	}
+	if setup == nil {
+		setup = &config.SetupConfig{}
+	}
+	var findings []Finding
+	for _, r := range rules {
+		findings = append(findings, r(tools, setup)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		fi, fj := fileOf(findings[i].Location), fileOf(findings[j].Location)
+		if fi != fj {
+			return fi < fj
+		}
+		return findings[i].line < findings[j].line
+	})
+	return findings
+}
+
+// idempotency flags tools without a check, creates, or unless, and setup steps without creates
+func idempotency(tools *config.ToolsConfig, setup *config.SetupConfig) []Finding {
+	var findings []Finding
+	for _, tool := range tools.Tools {
+		if tool.Check == "" && tool.Creates == "" && tool.Unless == "" {
+			findings = append(findings, toolFinding(tools, tool, RuleIdempotency,
+				"no check, creates, or unless: install runs it every time; add a check command"))
+		}
+	}
+	for _, task := range setup.SetupTasks {
+		for i, step := range task.Steps {
+			if step.Command != "" && step.Creates == "" && !idempotentStep.MatchString(strings.TrimSpace(step.Command)) {
+				findings = append(findings, taskFinding(setup, task, RuleIdempotency,
+					fmt.Sprintf("step %d runs every time the task does; add creates: with the file it makes", i+1)))
+			}
+		}
+	}
+	return findings
+}
+
+// timeouts flags command and script installs and remote/local setup commands without a timeout
+func timeouts(tools *config.ToolsConfig, setup *config.SetupConfig) []Finding {
+	var findings []Finding
+	for _, tool := range tools.Tools {
+		if (tool.Install.Command != "" || tool.Install.IsScript()) && tool.Install.Timeout == 0 {
+			findings = append(findings, toolFinding(tools, tool, RuleTimeout,
+				"no install.timeout: a stuck download hangs the run; set one a few times the normal install time"))
+		}
+	}
+	for _, task := range setup.SetupTasks {
+		for _, c := range []struct {
+			name string
+			cmd  *config.CommandConfig
+		}{{"remote", task.Remote}, {"local", task.Local}} {
+			if c.cmd != nil && c.cmd.Command != "" && c.cmd.Timeout == 0 {
+				findings = append(findings, taskFinding(setup, task, RuleTimeout,
+					fmt.Sprintf("%s command has no timeout; set %s.timeout", c.name, c.name)))
+			}
+		}
+	}
+	return findings
+}
+
+// requiredAfterOptional flags required tools and tasks that depend on optional ones
+// Why: An optional failure doesn't stop the run, so the required entry then runs without its dependency and fails instead
+func requiredAfterOptional(tools *config.ToolsConfig, setup *config.SetupConfig) []Finding {
+	optional := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		optional[config.NodeID(config.NodeTool, tool.Name)] = !tool.Required
+	}
+	for _, task := range setup.SetupTasks {
+		optional[config.NodeID(config.NodeTask, task.Name)] = task.Optional
+	}
+
+	var findings []Finding
+	for _, tool := range tools.Tools {
+		if !tool.Required {
+			continue
+		}
+		for _, dep := range tools.Dependencies(tool) {
+			if optional[config.NodeID(config.NodeTool, dep)] {
+				findings = append(findings, toolFinding(tools, tool, RuleRequiredOptional,
+					fmt.Sprintf("required, but depends on optional tool %s; make %s required or this one optional", dep, dep)))
+			}
+		}
+	}
+	for _, task := range setup.SetupTasks {
+		if task.Optional {
+			continue
+		}
+		for _, dep := range task.DependsOn {
+			if id := config.ParseDependency(dep, config.NodeTask); optional[id] {
+				findings = append(findings, taskFinding(setup, task, RuleRequiredOptional,
+					fmt.Sprintf("not optional, but depends on optional %s; mark this task optional too", id)))
+			}
+		}
+	}
+	return findings
+}
+
+// typedInstalls flags hand-written commands a typed install replaces
+func typedInstalls(tools *config.ToolsConfig, setup *config.SetupConfig) []Finding {
+	var findings []Finding
+	for _, tool := range tools.Tools {
+		if tool.Install.IsStrategy() {
+			continue
+		}
+		if suggest := typedSuggestion(tool.Install.Command); suggest != "" {
+			findings = append(findings, toolFinding(tools, tool, RuleTypedInstall,
+				fmt.Sprintf("use %s instead of %q (checks, versions, and uninstall come for free)", suggest, tool.Install.Command)))
+		}
+	}
+	for _, task := range setup.SetupTasks {
+		for _, command := range task.Install {
+			if suggest := typedSuggestion(command); suggest != "" {
+				findings = append(findings, taskFinding(setup, task, RuleTypedInstall,
+					fmt.Sprintf("%q installs a tool; declare it in tools.yaml with %s and depend on it", command, suggest)))
+			}
+		}
+	}
+	return findings
+}
+
+// typedSuggestion returns the typed install that replaces a command ("" if none)
+func typedSuggestion(command string) string {
+	command = strings.TrimSpace(command)
+	for _, p := range typedPatterns {
+		if p.pattern.MatchString(command) {
+			return p.suggest
+		}
+	}
+	return ""
+}
+
+// unreferenced flags declared groups no tool uses, and optional undescribed tools nothing depends on
+func unreferenced(tools *config.ToolsConfig, setup *config.SetupConfig) []Finding {
+	used := make(map[string]bool)
+	depended := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		used[tool.Install.ParallelGroup] = true
+		for _, dep := range tool.DependsOn {
+			depended[config.ParseDependency(dep, config.NodeTool)] = true
+		}
+	}
+	for _, task := range setup.SetupTasks {
+		for _, dep := range task.DependsOn {
+			depended[config.ParseDependency(dep, config.NodeTask)] = true
+		}
+	}
+
+	var findings []Finding
+	for _, group := range tools.Groups {
+		if !used[group.Name] {
+			findings = append(findings, Finding{Rule: RuleUnreferenced, Location: location(tools.Path, 0), Subject: "group " + group.Name,
+				Message: "declared under groups: but no tool uses it; remove it"})
+		}
+	}
+	for _, tool := range tools.Tools {
+		if !tool.Required && tool.Description == "" && !depended[config.NodeID(config.NodeTool, tool.Name)] {
+			findings = append(findings, toolFinding(tools, tool, RuleUnreferenced,
+				"optional, undescribed, and nothing depends on it; add a description saying who needs it, or remove it"))
+		}
+	}
+	return findings
+}
+
+// toolFinding builds a finding located at a tool
+func toolFinding(tools *config.ToolsConfig, tool config.Tool, rule, message string) Finding {
+	return Finding{Rule: rule, Location: location(tools.Path, tool.Line), Subject: "tool " + tool.Name, Message: message, line: tool.Line}
+}
+
+// taskFinding builds a finding located at a setup task
+func taskFinding(setup *config.SetupConfig, task config.SetupTask, rule, message string) Finding {
+	return Finding{Rule: rule, Location: location(setup.Path, task.Line), Subject: "task " + task.Name, Message: message, line: task.Line}
+}
+
+// location formats "file:line", or just the file when the line is unknown
+func location(path string, line int) string {
+	if path == "" {
+		path = "-"
+	}
+	if line == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s:%d", path, line)
+}
+
+// fileOf returns the file part of a location
+func fileOf(loc string) string {
+	file, _, _ := strings.Cut(loc, ":")
+	return file
+}
@@ -0,0 +1,64 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestRun(t *testing.T) {
+	tools := &config.ToolsConfig{
+		Path:   "tools.yaml",
+		Groups: []config.ParallelGroup{{Name: "casks"}},
+		Tools: []config.Tool{
+			{Name: "jq", Line: 10, Check: "command -v jq", Description: "JSON", Install: config.ToolInstall{Command: "brew install jq", Timeout: time.Minute}},
+			{Name: "rustup", Line: 20, Description: "Rust", Install: config.ToolInstall{Command: "curl -fsSL https://sh.rustup.rs | sh"}},
+			{Name: "fzf", Line: 30, Check: "command -v fzf"},
+			{Name: "bat", Line: 35, Check: "command -v bat"},
+			{Name: "zoxide", Line: 40, Check: "command -v zoxide", Description: "cd", Required: true, DependsOn: []string{"fzf"}},
+		},
+	}
+	setup := &config.SetupConfig{
+		Path: "setup.yaml",
+		SetupTasks: []config.SetupTask{
+			{Name: "dirs", Line: 5, Optional: true, Steps: []config.SetupStep{{Command: "mkdir -p ~/.config"}, {Command: "cp a b"}}},
+			{Name: "sync", Line: 15, Optional: true, Remote: &config.CommandConfig{Command: "rsync"}},
+			{Name: "zoxide-init", Line: 25, DependsOn: []string{"tool:fzf"}},
+		},
+	}
+
+	var got []string
+	for _, f := range Run(tools, setup) {
+		got = append(got, f.Location+" "+f.Rule+" "+f.Subject)
+	}
+	want := []string{
+		"setup.yaml:5 idempotency task dirs",
+		"setup.yaml:15 timeout task sync",
+		"setup.yaml:25 required-after-optional task zoxide-init",
+		"tools.yaml unreferenced group casks",
+		"tools.yaml:10 typed-install tool jq",
+		"tools.yaml:20 idempotency tool rustup",
+		"tools.yaml:20 timeout tool rustup",
+		"tools.yaml:20 typed-install tool rustup",
+		"tools.yaml:35 unreferenced tool bat",
+		"tools.yaml:40 required-after-optional tool zoxide",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Run() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTypedSuggestion(t *testing.T) {
+	for command, want := range map[string]string{
+		"brew install --cask zed":         "type: cask",
+		"npm install -g pnpm":             "type: npm",
+		"curl -fsSL x | sudo bash":        "type: script with a checksum",
+		"brew install jq && jq --version": "",
+	} {
+		if got := typedSuggestion(command); got != want {
+			t.Errorf("typedSuggestion(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
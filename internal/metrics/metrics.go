@@ -0,0 +1,236 @@
+// File: internal/metrics/metrics.go
+// Purpose: Run metrics for install and setup in Prometheus text format
+// Problem: On shared build machines provisioning failures surfaced only when a build broke hours later; infra
+//          had nothing to alert on and no history of how long provisioning took
+// Role: Records stage and per-tool/task durations and failures, and writes them as a node_exporter textfile
+// Usage: rec := metrics.NewRecorder(progressUI); rec.BeginStage("setup"); setup.NewSetupExecutor(..., rec, ...);
+//        rec.EndStage(err); rec.WriteTextfile(dir, version)
+// Design choices: Recorder decorates ui.UI (setup reports every task start/finish there), so no executor changes;
+//                 install instead hands over the installer's own TaskResults (SetTasks), the same timings the run
+//                 journal and summary use, so an install has one timing source; one file per stage (devsetup_<stage>.prom) so separate install and setup
+//                 runs don't overwrite each other; files are replaced atomically as node_exporter requires
+// Assumptions: One stage per Recorder at a time; the textfile directory is watched by node_exporter
+//              (--collector.textfile.directory)
+
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+// Task results
+const (
+	ResultSuccess   = "success"
+	ResultFailed    = "failed"
+	ResultCancelled = "cancelled"
+	ResultSkipped   = "skipped"
+)
+
+// TaskMetric is the outcome of one tool install or setup task
+type TaskMetric struct {
+	Name     string
+	Result   string
+	Duration time.Duration
+}
+
+// Recorder is a ui.UI that records task timings
+// What: Passes every call to the wrapped UI and times StartTask → Complete/Fail/CancelTask
+// Why: Metrics without threading a collector through installer and setup
+type Recorder struct {
+	ui.UI
+
+	mu         sync.Mutex
+	stage      string
+	stageStart time.Time
+	stageEnd   time.Time
+	stageErr   error
+	started    map[string]time.Time
+	tasks      map[string]TaskMetric
+}
+
+// NewRecorder wraps a UI
+// Params: inner - UI that still receives every call
+// Returns: Recorder to pass wherever inner would go
+func NewRecorder(inner ui.UI) *Recorder {
+	return &Recorder{UI: inner}
+}
+
+// BeginStage starts recording a stage, clearing earlier task metrics
+// Params: name - stage name (install or setup)
+func (r *Recorder) BeginStage(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stage, r.stageStart, r.stageEnd, r.stageErr = name, time.Now(), time.Time{}, nil
+	r.started = make(map[string]time.Time)
+	r.tasks = make(map[string]TaskMetric)
+}
+
+// EndStage finishes the current stage
+// Params: err - the stage's result (nil = success)
+func (r *Recorder) EndStage(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stageEnd, r.stageErr = time.Now(), err
+}
+
+// StartTask records the task start and forwards the call
+func (r *Recorder) StartTask(taskName string) {
+	r.mu.Lock()
+	if r.started != nil {
+		r.started[taskName] = time.Now()
+	}
+	r.mu.Unlock()
+	r.UI.StartTask(taskName)
+}
+
+// CompleteTask records a success and forwards the call
+func (r *Recorder) CompleteTask(taskName string) {
+	r.finish(taskName, ResultSuccess)
+	r.UI.CompleteTask(taskName)
+}
+
+// FailTask records a failure and forwards the call
+func (r *Recorder) FailTask(taskName string, err error) {
+	r.finish(taskName, ResultFailed)
+	r.UI.FailTask(taskName, err)
+}
+
+// CancelTask records a cancellation and forwards the call
+func (r *Recorder) CancelTask(taskName string) {
+	r.finish(taskName, ResultCancelled)
+	r.UI.CancelTask(taskName)
+}
+
+// SetTasks replaces the tasks timed from UI calls with results the engine measured itself
+// Why: The installer already times each tool for its summary and run journal; metrics reuse those numbers
+// Params: tasks - one metric per task (a later one with the same name wins)
+func (r *Recorder) SetTasks(tasks []TaskMetric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks = make(map[string]TaskMetric, len(tasks))
+	for _, t := range tasks {
+		r.tasks[t.Name] = t
+	}
+}
+
+// Tasks returns the recorded tasks sorted by name
+func (r *Recorder) Tasks() []TaskMetric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tasks := make([]TaskMetric, 0, len(r.tasks))
+	for _, t := range r.tasks {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+// finish records a task outcome (tasks never started, e.g. failed before StartTask, get zero duration)
+func (r *Recorder) finish(taskName, result string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tasks == nil {
+		return
+	}
+	var d time.Duration
+	if start, ok := r.started[taskName]; ok {
+		d = time.Since(start)
+	}
+	r.tasks[taskName] = TaskMetric{Name: taskName, Result: result, Duration: d}
+}
+
+// Textfile renders the current stage in Prometheus text exposition format
+// Params: version - devsetup version for devsetup_info
+// Returns: Metrics text (empty if no stage was begun)
+func (r *Recorder) Textfile(version string) string {
+	tasks := r.Tasks()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stage == "" {
+		return ""
+	}
+	end := r.stageEnd
+	if end.IsZero() {
+		end = time.Now()
+	}
+	stage := label(r.stage)
+	success := 1
+	if r.stageErr != nil {
+		success = 0
+	}
+	failures := 0
+	for _, t := range tasks {
+		if t.Result == ResultFailed {
+			failures++
+		}
+	}
+
+	var b strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	metric("devsetup_info", "gauge", "devsetup version that wrote these metrics")
+	fmt.Fprintf(&b, "devsetup_info{stage=%q,version=%q} 1\n", stage, label(version))
+	metric("devsetup_stage_duration_seconds", "gauge", "Duration of the last run of the stage")
+	fmt.Fprintf(&b, "devsetup_stage_duration_seconds{stage=%q} %.3f\n", stage, end.Sub(r.stageStart).Seconds())
+	metric("devsetup_stage_success", "gauge", "Whether the last run of the stage succeeded (1) or failed (0)")
+	fmt.Fprintf(&b, "devsetup_stage_success{stage=%q} %d\n", stage, success)
+	metric("devsetup_stage_last_run_timestamp_seconds", "gauge", "Unix time the last run of the stage finished")
+	fmt.Fprintf(&b, "devsetup_stage_last_run_timestamp_seconds{stage=%q} %d\n", stage, end.Unix())
+	metric("devsetup_stage_task_failures", "gauge", "Tools or tasks that failed in the last run of the stage")
+	fmt.Fprintf(&b, "devsetup_stage_task_failures{stage=%q} %d\n", stage, failures)
+	if len(tasks) > 0 {
+		metric("devsetup_task_duration_seconds", "gauge", "Duration of each tool install or setup task in the last run")
+		for _, t := range tasks {
+			fmt.Fprintf(&b, "devsetup_task_duration_seconds{stage=%q,task=%q,result=%q} %.3f\n", stage, label(t.Name), t.Result, t.Duration.Seconds())
+		}
+	}
+	return b.String()
+}
+
+// WriteTextfile atomically writes the stage's metrics to <dir>/devsetup_<stage>.prom
+// Params: dir - node_exporter textfile directory, version - devsetup version
+// Returns: Path written, error if the file can't be written
+func (r *Recorder) WriteTextfile(dir, version string) (string, error) {
+	text := r.Textfile(version)
+	if text == "" {
+		return "", fmt.Errorf("no stage recorded")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	path := filepath.Join(dir, "devsetup_"+r.stage+".prom")
+	r.mu.Unlock()
+
+	tmp, err := os.CreateTemp(dir, ".devsetup-*.prom.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(text); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp.Name(), path)
+}
+
+// label escapes a Prometheus label value (%q handles quotes and backslashes; newlines are dropped)
+func label(s string) string {
+	return strings.ReplaceAll(s, "\n", " ")
+}
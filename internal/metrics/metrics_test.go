@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestRecorderTextfile(t *testing.T) {
+	rec := NewRecorder(ui.NewEventUI(io.Discard, nil))
+	rec.BeginStage("install")
+	rec.StartTask("jq")
+	rec.CompleteTask("jq")
+	rec.StartTask("node")
+	rec.FailTask("node", errors.New("exit status 1"))
+	rec.EndStage(errors.New("required tool node failed"))
+
+	dir := t.TempDir()
+	path, err := rec.WriteTextfile(dir, "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "devsetup_install.prom") {
+		t.Errorf("path = %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{
+		`devsetup_stage_success{stage="install"} 0`,
+		`devsetup_stage_task_failures{stage="install"} 1`,
+		`devsetup_task_duration_seconds{stage="install",task="jq",result="success"}`,
+		`devsetup_task_duration_seconds{stage="install",task="node",result="failed"}`,
+		`devsetup_info{stage="install",version="v1.2.0"} 1`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("textfile missing %q:\n%s", want, text)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temp file left behind: %v", entries)
+	}
+}
+
+func TestRecorderSetTasks(t *testing.T) {
+	rec := NewRecorder(ui.NewEventUI(io.Discard, nil))
+	rec.BeginStage("install")
+	rec.StartTask("jq")
+	rec.CompleteTask("jq")
+	rec.SetTasks([]TaskMetric{
+		{Name: "node", Result: ResultFailed, Duration: 1500 * time.Millisecond},
+		{Name: "git", Result: ResultSkipped},
+	})
+	rec.EndStage(nil)
+
+	tasks := rec.Tasks()
+	if len(tasks) != 2 || tasks[0].Name != "git" || tasks[1].Name != "node" {
+		t.Fatalf("Tasks() = %+v, want git and node only", tasks)
+	}
+	text := rec.Textfile("v1.2.0")
+	for _, want := range []string{
+		`devsetup_task_duration_seconds{stage="install",task="node",result="failed"} 1.500`,
+		`devsetup_stage_task_failures{stage="install"} 1`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("textfile missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// File: internal/pathmgr/pathmgr.go
+// Purpose: Managed PATH: one generated file built from the path entries tools declare in tools.yaml
+// Problem: Every tool's installer appended its own `export PATH=...` line to .zshrc, leaving duplicates, an order
+//          nobody chose, and Intel paths on Apple Silicon Macs migrated from older machines
+// Role: Collects tool path entries in install order, expands the arch-specific Homebrew prefix, deduplicates them,
+//       and renders a script that puts them at the front of PATH; the ~/.zshrc managed block sources it
+// Usage: entries, err := pathmgr.Write(toolsConfig); rc = pathmgr.EnsureBlock(rc)
+// Design choices: The file lives in the state dir and is regenerated whole, so removing a tool removes its entry;
+//                 managed entries are moved to the front even if PATH already has them later (macOS path_helper
+//                 puts /usr/bin first); the rc file only gets a marked block that sources the file
+// Assumptions: zsh or bash sources the file (it uses ${var//pattern/replacement}); Homebrew is in its default prefix
+
+package pathmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// Managed block markers in ~/.zshrc
+const (
+	BlockStart = "# >>> devsetup >>>"
+	BlockEnd   = "# <<< devsetup <<<"
+)
+
+// brewPrefixVar is the placeholder for the Homebrew prefix in path entries
+const brewPrefixVar = "{brew_prefix}"
+
+// Entry is one directory on the managed PATH
+type Entry struct {
+	// Dir is the directory as written to the path file ($HOME-relative where declared with ~/)
+	Dir string
+
+	// Tool that declared it
+	Tool string
+}
+
+// BrewPrefix returns the default Homebrew prefix for this Mac's architecture
+// Returns: /opt/homebrew on Apple Silicon, /usr/local on Intel
+func BrewPrefix() string {
+	if runtime.GOARCH == "arm64" {
+		return "/opt/homebrew"
+	}
+	return "/usr/local"
+}
+
+// FilePath returns the generated path file
+// Returns: ~/.local/share/devsetup/path.sh
+func FilePath() string {
+	return filepath.Join(config.GetStateDir(), "path.sh")
+}
+
+// Entries collects the declared path entries
+// What: Every tool's path list in install order, expanded and deduplicated (first declaration wins), followed by
+// the directories of the tool's direct (no Homebrew) install where its files are on disk
+// Params: tc - tools config
+// Returns: Entries in PATH priority order, error if the install order can't be resolved
+func Entries(tc *config.ToolsConfig) ([]Entry, error) {
+	tools, err := tc.GetInstallOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	seen := make(map[string]bool)
+	for _, tool := range tools {
+		for _, dir := range tool.Path {
+			dir = expand(dir)
+			if dir == "" || seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			entries = append(entries, Entry{Dir: dir, Tool: tool.Name})
+		}
+		for _, dir := range directDirs(tool) {
+			if !seen[dir] {
+				seen[dir] = true
+				entries = append(entries, Entry{Dir: dir, Tool: tool.Name})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// directDirs returns the directories holding a tool's direct install files that exist ($HOME-relative where possible)
+// Why: After `install --without-brew`, node's links in ~/.local/bin must be on PATH until Homebrew's node replaces them
+func directDirs(tool config.Tool) []string {
+	if tool.Direct == nil || !tool.Install.UsesHomebrew() {
+		return nil
+	}
+	var dirs []string
+	for _, p := range tool.Direct.Placed() {
+		if info, err := os.Lstat(p); err != nil || info.IsDir() {
+			continue
+		}
+		dir := filepath.Dir(p)
+		if rest, ok := strings.CutPrefix(dir, homeDir()+"/"); ok {
+			dir = "$HOME/" + rest
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// Render produces the path file script
+// Params: entries - Entries output
+// Returns: Script that moves the entries, in order, to the front of PATH
+func Render(entries []Entry) string {
+	var b strings.Builder
+	b.WriteString("# Generated by devsetup from tools.yaml path entries; changes are overwritten ('devsetup path' regenerates)\n")
+	if len(entries) == 0 {
+		return b.String()
+	}
+
+	dirs := make([]string, len(entries))
+	for i, e := range entries {
+		fmt.Fprintf(&b, "# %s: %s\n", e.Tool, e.Dir)
+		dirs[i] = e.Dir
+	}
+	b.WriteString("__devsetup_rest=\":$PATH:\"\n")
+	b.WriteString("for __devsetup_dir in")
+	for _, dir := range dirs {
+		b.WriteString(" \"" + dir + "\"")
+	}
+	b.WriteString("; do\n")
+	b.WriteString("  __devsetup_rest=\"${__devsetup_rest//\":$__devsetup_dir:\"/:}\"\n")
+	b.WriteString("done\n")
+	b.WriteString("__devsetup_rest=\"${__devsetup_rest#:}\"; __devsetup_rest=\"${__devsetup_rest%:}\"\n")
+	fmt.Fprintf(&b, "export PATH=\"%s${__devsetup_rest:+:$__devsetup_rest}\"\n", strings.Join(dirs, ":"))
+	b.WriteString("unset __devsetup_rest __devsetup_dir\n")
+	return b.String()
+}
+
+// Write regenerates the path file
+// Params: tc - tools config
+// Returns: Entries written, error if they can't be collected or the file can't be written
+func Write(tc *config.ToolsConfig) ([]Entry, error) {
+	entries, err := Entries(tc)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(FilePath()), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(FilePath(), []byte(Render(entries)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", FilePath(), err)
+	}
+	return entries, nil
+}
+
+// Block returns the managed block that sources the path file
+func Block() string {
+	source := strings.Replace(FilePath(), homeDir(), "$HOME", 1)
+	return BlockStart + "\n" +
+		"# Managed by devsetup; changes inside this block are overwritten\n" +
+		fmt.Sprintf("[ -f \"%s\" ] && . \"%s\"\n", source, source) +
+		BlockEnd + "\n"
+}
+
+// EnsureBlock returns rc content with the managed block present and current
+// Params: rc - current ~/.zshrc content
+// Returns: Content with an existing block replaced in place, or the block appended
+func EnsureBlock(rc string) string {
+	start := strings.Index(rc, BlockStart)
+	end := strings.Index(rc, BlockEnd)
+	if start >= 0 && end > start {
+		rest := rc[end+len(BlockEnd):]
+		rest = strings.TrimPrefix(rest, "\n")
+		return rc[:start] + Block() + rest
+	}
+	if rc != "" && !strings.HasSuffix(rc, "\n") {
+		rc += "\n"
+	}
+	if rc != "" {
+		rc += "\n"
+	}
+	return rc + Block()
+}
+
+// expand resolves {brew_prefix} and a leading ~/ (written as $HOME so the file follows the user)
+func expand(dir string) string {
+	dir = strings.TrimSpace(strings.ReplaceAll(dir, brewPrefixVar, BrewPrefix()))
+	if len(dir) > 1 {
+		dir = strings.TrimRight(dir, "/")
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		return "$HOME/" + rest
+	}
+	return dir
+}
+
+// homeDir returns the home directory, or a value that never matches a path
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return "\x00"
+}
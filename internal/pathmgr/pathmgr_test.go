@@ -0,0 +1,60 @@
+package pathmgr
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestEntriesAndRender(t *testing.T) {
+	tc := &config.ToolsConfig{Tools: []config.Tool{
+		{Name: "pnpm-setup", Path: []string{"~/Library/pnpm/"}, DependsOn: []string{"homebrew"}},
+		{Name: "homebrew", Path: []string{"{brew_prefix}/bin", "{brew_prefix}/sbin"}},
+		{Name: "uv", Path: []string{"{brew_prefix}/bin"}, DependsOn: []string{"homebrew"}},
+	}}
+	entries, err := Entries(tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, e := range entries {
+		dirs = append(dirs, e.Dir)
+	}
+	want := BrewPrefix() + "/bin," + BrewPrefix() + "/sbin,$HOME/Library/pnpm"
+	if got := strings.Join(dirs, ","); got != want {
+		t.Fatalf("Entries() = %s, want %s (install order, deduplicated)", got, want)
+	}
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	script := Render([]Entry{{Dir: "/opt/x/bin"}, {Dir: "/opt/y/bin"}}) + `printf %s "$PATH"`
+	cmd := exec.Command(bash, "-c", script)
+	cmd.Env = []string{"PATH=/usr/bin:/opt/y/bin:/bin"}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "/opt/x/bin:/opt/y/bin:/usr/bin:/bin" {
+		t.Errorf("PATH = %s, want managed entries first without duplicates", out)
+	}
+}
+
+func TestEnsureBlock(t *testing.T) {
+	rc := "export A=1\n"
+	once := EnsureBlock(rc)
+	if !strings.HasPrefix(once, rc+"\n"+BlockStart) || !strings.HasSuffix(once, BlockEnd+"\n") {
+		t.Fatalf("EnsureBlock() appended block wrong:\n%s", once)
+	}
+	if twice := EnsureBlock(once); twice != once {
+		t.Errorf("EnsureBlock() is not idempotent:\n%s", twice)
+	}
+
+	stale := rc + "\n" + BlockStart + "\nold\n" + BlockEnd + "\nalias x=y\n"
+	if got := EnsureBlock(stale); got != rc+"\n"+Block()+"alias x=y\n" {
+		t.Errorf("EnsureBlock() should replace the block in place, got:\n%s", got)
+	}
+}
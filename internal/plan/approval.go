@@ -0,0 +1,162 @@
+// File: internal/plan/approval.go
+// Purpose: Plan approval for change-managed workstations
+// Problem: Regulated teams need a reviewer to sign off what changes on production-adjacent laptops, and proof that
+//          what ran is what was signed off
+// Role: Records who approved a plan and their SSH signature over the plan's content hash; apply verifies both
+//       against the approvers' public keys in policies.yaml before running
+// Usage: p.Approve(ctx, plan.Identity(ctx), keyPath); later: if err := p.CheckApproval(ctx, policy, applier); err != nil { refuse }
+// Design choices: The approval hash covers the whole plan (including the config hash), so editing the plan file or
+//                 changing configs after approval both invalidate it; signatures are ssh-keygen -Y (the format git
+//                 uses for SSH-signed commits), so approvers sign with keys they already have; identities are git
+//                 user.email like commits
+// Assumptions: ssh-keygen is on PATH (it ships with macOS); policies.yaml itself is managed, since whoever can edit
+//              it can add approvers
+
+package plan
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// signatureNamespace scopes approval signatures so an SSH signature made for anything else can't be replayed
+const signatureNamespace = "devsetup-plan"
+
+// Approval records a plan sign-off
+type Approval struct {
+	// By is the approver identity (git user.email)
+	By string `json:"by"`
+
+	// At is when the plan was approved
+	At time.Time `json:"at"`
+
+	// PlanHash is the SHA256 of the plan content at approval time
+	PlanHash string `json:"plan_hash"`
+
+	// Signature is the approver's armored SSH signature over PlanHash
+	Signature string `json:"signature"`
+}
+
+// Hash fingerprints the plan content
+// What: SHA256 of the plan's JSON encoding without its approval
+// Returns: Hex hash, error if the plan can't be encoded
+func (p *Plan) Hash() (string, error) {
+	content := *p
+	content.Approval = nil
+	data, err := json.Marshal(&content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Approve signs off the plan
+// What: Signs the plan hash with the approver's SSH key (ssh-keygen -Y sign)
+// Params: by - approver identity; key - SSH private key, or public key whose private half is in ssh-agent
+// Returns: Error if the plan can't be hashed or signed
+// Edge cases: Re-approving replaces the previous approval
+func (p *Plan) Approve(ctx context.Context, by, key string) error {
+	hash, err := p.Hash()
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "ssh-keygen", "-Y", "sign", "-q", "-f", key, "-n", signatureNamespace)
+	cmd.Stdin = strings.NewReader(hash)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	signature, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to sign plan with %s: %v: %s", key, err, strings.TrimSpace(stderr.String()))
+	}
+	p.Approval = &Approval{By: by, At: time.Now().UTC().Truncate(time.Second), PlanHash: hash, Signature: string(signature)}
+	return nil
+}
+
+// CheckApproval decides whether a plan may be applied
+// What: An approval must match the plan content and carry a valid signature by an allowed approver
+// Why: When the policy requires approval it must also exist and, unless self-approval is allowed, not be the applier's
+// Params: cm - change management policy (nil = approval optional), applier - identity running apply
+// Returns: Error explaining why the plan is refused, nil if it may run
+// Edge cases: Without approvers in the policy there are no keys to check, so an optional approval only has its
+// hash checked (it is informational)
+func (p *Plan) CheckApproval(ctx context.Context, cm *config.ChangeManagementPolicy, applier string) error {
+	if p.Approval == nil {
+		if cm != nil && cm.RequirePlanApproval {
+			return fmt.Errorf("plan is not approved (policy requires approval: devsetup plan approve <file>)")
+		}
+		return nil
+	}
+
+	hash, err := p.Hash()
+	if err != nil {
+		return err
+	}
+	if hash != p.Approval.PlanHash {
+		return fmt.Errorf("plan was modified after %s approved it", p.Approval.By)
+	}
+	if cm == nil || len(cm.Approvers) == 0 {
+		return nil
+	}
+	if !cm.IsApprover(p.Approval.By) {
+		return fmt.Errorf("%s is not an allowed plan approver", p.Approval.By)
+	}
+	if err := p.verifySignature(ctx, cm); err != nil {
+		return err
+	}
+	if cm.RequirePlanApproval && !cm.AllowSelfApproval && strings.EqualFold(p.Approval.By, applier) {
+		return fmt.Errorf("plan was approved by the person applying it (%s); another approver is required", applier)
+	}
+	return nil
+}
+
+// verifySignature checks the approval signature against the approvers' keys (ssh-keygen -Y verify)
+// Returns: Error unless the signature over PlanHash was made by the key listed for Approval.By
+func (p *Plan) verifySignature(ctx context.Context, cm *config.ChangeManagementPolicy) error {
+	if p.Approval.Signature == "" {
+		return fmt.Errorf("approval by %s is not signed; approve the plan again", p.Approval.By)
+	}
+
+	dir, err := os.MkdirTemp("", "devsetup-approval-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	signers := filepath.Join(dir, "allowed_signers")
+	signature := filepath.Join(dir, "plan.sig")
+	if err := os.WriteFile(signers, []byte(cm.AllowedSigners(signatureNamespace)), 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(signature, []byte(p.Approval.Signature), 0600); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "ssh-keygen", "-Y", "verify", "-f", signers, "-I", strings.ToLower(p.Approval.By), "-n", signatureNamespace, "-s", signature)
+	cmd.Stdin = strings.NewReader(p.Approval.PlanHash)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("approval signature is not a valid signature by %s: %s", p.Approval.By, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Identity returns who is running devsetup
+// Returns: git user.email, falling back to $USER
+func Identity(ctx context.Context) string {
+	if output, err := exec.CommandContext(ctx, "git", "config", "user.email").Output(); err == nil {
+		if email := strings.TrimSpace(string(output)); email != "" {
+			return email
+		}
+	}
+	return os.Getenv("USER")
+}
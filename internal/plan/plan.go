@@ -0,0 +1,273 @@
+// File: internal/plan/plan.go
+// Purpose: Differential execution plans (devsetup plan / devsetup apply --plan)
+// Problem: There was no way to see what install + setup would change on this machine before running them, or to
+//          run later exactly what was reviewed
+// Role: Resolves configs against the machine (check commands, state) into a saved list of installs and setup tasks
+// Usage: p, err := plan.Build(ctx, tools, setup, state, checks, version); p.Save("plan.json"); later plan.Load + apply
+// Design choices: A plan stores names, not commands; apply runs the current configs restricted to the planned names
+//                 and refuses when the config hash differs from the plan's, so "exactly" means same configs + same set;
+//                 estimates are upper bounds (install timeouts, archive Content-Length) rather than guesses
+// Assumptions: Check commands are side-effect free; Homebrew download sizes are not known ahead of time
+
+package plan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/checkcache"
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/installer"
+	"gopkg.in/yaml.v3"
+)
+
+// sizeProbeTimeout bounds each archive Content-Length request
+const sizeProbeTimeout = 5 * time.Second
+
+// Plan is a resolved execution plan
+// What: Tools to install and setup tasks to configure on this machine, with estimates
+// Why: Reviewed before running; apply executes exactly this set
+type Plan struct {
+	// CreatedAt is when the plan was built
+	CreatedAt time.Time `json:"created_at"`
+
+	// Version of devsetup that built the plan
+	Version string `json:"devsetup_version"`
+
+	// ConfigHash is the SHA256 of the resolved tools and setup configs
+	ConfigHash string `json:"config_hash"`
+
+	// Installs are tools whose check fails, in install order
+	Installs []Step `json:"installs"`
+
+	// Setup are setup tasks not yet configured, in dependency order
+	Setup []Step `json:"setup"`
+
+	// Unchanged counts tools and tasks already in place
+	Unchanged int `json:"unchanged"`
+
+	// EstimatedTime is an upper bound: per stage, the longest install timeout of its planned tools
+	EstimatedTime time.Duration `json:"estimated_time"`
+
+	// DownloadBytes is the known download size (archive installs)
+	DownloadBytes int64 `json:"download_bytes"`
+
+	// UnknownSizes counts planned installs whose download size isn't known ahead (brew, scripts)
+	UnknownSizes int `json:"unknown_sizes"`
+
+	// Rosetta lists planned installs that are Intel-only (tools.yaml rosetta: true)
+	Rosetta []string `json:"rosetta,omitempty"`
+
+	// Approval is the sign-off recorded by 'devsetup plan approve' (nil = not approved)
+	Approval *Approval `json:"approval,omitempty"`
+}
+
+// Step is one planned action
+type Step struct {
+	// Name is the tool or setup task name
+	Name string `json:"name"`
+
+	// Stage is the install batch number (tools only)
+	Stage int `json:"stage,omitempty"`
+
+	// Group is the tool's parallel_group
+	Group string `json:"group,omitempty"`
+
+	// Independent is set for declared groups (tools.yaml groups:), whose stage waits only for After
+	Independent bool `json:"independent,omitempty"`
+
+	// After are the stages an independent stage waits for (others wait for every earlier stage)
+	After []int `json:"after,omitempty"`
+
+	// Detail summarizes what runs (install command, archive URL, or task description)
+	Detail string `json:"detail,omitempty"`
+}
+
+// Build resolves configs against the machine
+// What: Runs each tool's check (through the check cache) and reads configured tasks from state
+// Params: ctx - bounds size probes, tools/setup - loaded configs, state - current state, checks - check cache (nil runs every check), version - devsetup version
+// Returns: Plan, error if dependencies can't be resolved
+func Build(ctx context.Context, tools *config.ToolsConfig, setup *config.SetupConfig, state *config.State, checks *checkcache.Cache, version string) (*Plan, error) {
+	hash, err := ConfigHash(tools, setup)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{CreatedAt: time.Now().UTC().Truncate(time.Second), Version: version, ConfigHash: hash}
+
+	stages, err := installer.Stages(tools)
+	if err != nil {
+		return nil, err
+	}
+	prerequisites := installer.StagePrerequisites(tools, stages)
+	for i, batch := range stages {
+		var longest time.Duration
+		for _, tool := range batch {
+			// Deprecated tools are never installed anew
+			if tool.Deprecated || installer.IsInstalled(ctx, tool, tools.ShellFor(tool), checks) {
+				p.Unchanged++
+				continue
+			}
+			step := Step{Name: tool.Name, Stage: i + 1, Group: tool.Install.ParallelGroup, Detail: installDetail(tool)}
+			if tools.Group(step.Group) != nil {
+				step.Independent, step.After = true, prerequisites[i]
+			}
+			p.Installs = append(p.Installs, step)
+			longest = max(longest, tool.Install.Timeout)
+			if tool.Rosetta {
+				p.Rosetta = append(p.Rosetta, tool.Name)
+			}
+
+			if tool.Install.IsArchive() {
+				if size := contentLength(ctx, tool.Install.Archive.URL); size > 0 {
+					p.DownloadBytes += size
+					continue
+				}
+			}
+			p.UnknownSizes++
+		}
+		p.EstimatedTime += longest
+	}
+
+	graph, err := config.BuildDependencyGraph(tools, setup)
+	if err != nil {
+		return nil, err
+	}
+	order, err := graph.Order()
+	if err != nil {
+		return nil, err
+	}
+	tasks := make(map[string]config.SetupTask, len(setup.SetupTasks))
+	for _, task := range setup.SetupTasks {
+		tasks[task.Name] = task
+	}
+	for _, node := range order {
+		if node.Kind != config.NodeTask {
+			continue
+		}
+		if config.IsTaskConfigured(state, node.Name) {
+			p.Unchanged++
+			continue
+		}
+		p.Setup = append(p.Setup, Step{Name: node.Name, Detail: tasks[node.Name].Description})
+	}
+	return p, nil
+}
+
+// ConfigHash fingerprints the resolved configs (overlays applied)
+// Params: tools/setup - loaded configs
+// Returns: Hex SHA256 of their YAML encoding
+func ConfigHash(tools *config.ToolsConfig, setup *config.SetupConfig) (string, error) {
+	h := sha256.New()
+	for _, v := range []interface{}{tools, setup} {
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode config: %w", err)
+		}
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Empty reports whether the plan changes nothing
+func (p *Plan) Empty() bool {
+	return len(p.Installs) == 0 && len(p.Setup) == 0
+}
+
+// Filter restricts a run to the planned tools and tasks
+// What: Every declared tool and task not in the plan is skipped
+// Why: apply reuses the regular install/setup paths (--skip-tasks semantics)
+// Params: tools/setup - loaded configs (must match ConfigHash)
+// Returns: Run filter for installer.SetFilter and FilterSetup
+func (p *Plan) Filter(tools *config.ToolsConfig, setup *config.SetupConfig) *config.RunFilter {
+	planned := make(map[string]bool, len(p.Installs)+len(p.Setup))
+	for _, s := range p.Installs {
+		planned[config.NodeID(config.NodeTool, s.Name)] = true
+	}
+	for _, s := range p.Setup {
+		planned[config.NodeID(config.NodeTask, s.Name)] = true
+	}
+
+	f := &config.RunFilter{}
+	for _, tool := range tools.Tools {
+		if !planned[config.NodeID(config.NodeTool, tool.Name)] {
+			f.SkipTasks = append(f.SkipTasks, tool.Name)
+		}
+	}
+	for _, task := range setup.SetupTasks {
+		if !planned[config.NodeID(config.NodeTask, task.Name)] {
+			f.SkipTasks = append(f.SkipTasks, task.Name)
+		}
+	}
+	return f
+}
+
+// Save writes the plan as JSON
+// Params: path - destination file
+// Returns: Error if it can't be written
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// Load reads a saved plan
+// Params: path - plan file written by Save
+// Returns: Plan, error if missing or not a plan
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", path, err)
+	}
+	if p.ConfigHash == "" {
+		return nil, fmt.Errorf("invalid plan %s: missing config_hash", path)
+	}
+	return &p, nil
+}
+
+// installDetail summarizes how a tool installs
+func installDetail(tool config.Tool) string {
+	switch {
+	case tool.Install.IsArchive():
+		return "archive " + tool.Install.Archive.URL
+	case tool.Install.IsScript():
+		return "script " + tool.Install.Script.URL
+	default:
+		return tool.Install.Command
+	}
+}
+
+// contentLength asks the server for a download's size
+// Returns: Size in bytes, 0 if unknown
+func contentLength(ctx context.Context, url string) int64 {
+	ctx, cancel := context.WithTimeout(ctx, sizeProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 {
+		return 0
+	}
+	return resp.ContentLength
+}
@@ -0,0 +1,153 @@
+package plan
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestFilterSkipsUnplanned(t *testing.T) {
+	tools := &config.ToolsConfig{Tools: []config.Tool{{Name: "jq"}, {Name: "gh"}}}
+	setup := &config.SetupConfig{SetupTasks: []config.SetupTask{{Name: "git-config"}, {Name: "gh"}}}
+
+	// Tool gh is planned, task gh is not: names are matched per kind
+	p := &Plan{ConfigHash: "x", Installs: []Step{{Name: "gh", Stage: 1}}, Setup: []Step{{Name: "git-config"}}}
+	f := p.Filter(tools, setup)
+	if want := []string{"jq", "gh"}; !reflect.DeepEqual(f.SkipTasks, want) {
+		t.Errorf("SkipTasks = %v, want %v", f.SkipTasks, want)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	tools := &config.ToolsConfig{Tools: []config.Tool{{Name: "jq", Check: "true"}}}
+	setup := &config.SetupConfig{}
+	hash, err := ConfigHash(tools, setup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	p := &Plan{ConfigHash: hash, Installs: []Step{{Name: "jq", Stage: 1, Detail: "brew install jq"}}}
+	if err := p.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Installs, p.Installs) || loaded.ConfigHash != hash {
+		t.Errorf("Load() = %+v, want %+v", loaded, p)
+	}
+
+	tools.Tools[0].Check = "command -v jq"
+	if changed, _ := ConfigHash(tools, setup); changed == hash {
+		t.Error("ConfigHash() unchanged after editing a tool")
+	}
+}
+
+// sshKey generates an unencrypted ed25519 key, returning its path and public key line
+func sshKey(t *testing.T, name string) (string, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", name, "-f", path).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v: %s", err, output)
+	}
+	pub, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, string(pub)
+}
+
+func TestCheckApproval(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not installed")
+	}
+	ctx := context.Background()
+	leadKey, leadPub := sshKey(t, "lead")
+	devKey, devPub := sshKey(t, "dev")
+	required := &config.ChangeManagementPolicy{RequirePlanApproval: true, Approvers: []config.PlanApprover{
+		{Identity: "lead@example.com", Key: leadPub},
+		{Identity: "dev@example.com", Key: devPub},
+	}}
+
+	p := &Plan{ConfigHash: "x", Installs: []Step{{Name: "jq", Stage: 1}}}
+	if err := p.CheckApproval(ctx, nil, "dev@example.com"); err != nil {
+		t.Errorf("unapproved plan without policy: %v", err)
+	}
+	if err := p.CheckApproval(ctx, required, "dev@example.com"); err == nil {
+		t.Error("unapproved plan accepted when approval is required")
+	}
+
+	if err := p.Approve(ctx, "lead@example.com", leadKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CheckApproval(ctx, required, "dev@example.com"); err != nil {
+		t.Errorf("approved plan refused: %v", err)
+	}
+	if err := p.CheckApproval(ctx, required, "LEAD@example.com"); err == nil {
+		t.Error("self-approved plan accepted")
+	}
+
+	// Claiming to be the lead with someone else's key
+	if err := p.Approve(ctx, "lead@example.com", devKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CheckApproval(ctx, required, "ops@example.com"); err == nil {
+		t.Error("approval signed with another approver's key accepted")
+	}
+
+	// An approval without a signature, as written by hand
+	p.Approve(ctx, "lead@example.com", leadKey)
+	p.Approval.Signature = ""
+	if err := p.CheckApproval(ctx, required, "dev@example.com"); err == nil {
+		t.Error("unsigned approval accepted")
+	}
+
+	p.Approve(ctx, "lead@example.com", leadKey)
+	p.Installs = append(p.Installs, Step{Name: "docker", Stage: 2})
+	if err := p.CheckApproval(ctx, nil, "dev@example.com"); err == nil {
+		t.Error("plan edited after approval accepted")
+	}
+
+	internKey, _ := sshKey(t, "intern")
+	p.Approve(ctx, "intern@example.com", internKey)
+	if err := p.CheckApproval(ctx, required, "dev@example.com"); err == nil {
+		t.Error("approval by a non-approver accepted")
+	}
+}
+
+func TestBuildStages(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tools := &config.ToolsConfig{
+		Tools: []config.Tool{
+			{Name: "homebrew", Check: "false"},
+			{Name: "git", Check: "false", Install: config.ToolInstall{ParallelGroup: "cli"}, DependsOn: []string{"homebrew"}},
+			{Name: "zed", Check: "false", Install: config.ToolInstall{ParallelGroup: "casks"}, DependsOn: []string{"homebrew"}},
+			{Name: "pnpm-setup", Check: "false", DependsOn: []string{"git"}},
+		},
+		Groups: []config.ParallelGroup{{Name: "casks"}},
+	}
+
+	p, err := Build(context.Background(), tools, &config.SetupConfig{}, &config.State{}, nil, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Step{
+		{Name: "homebrew", Stage: 1},
+		{Name: "zed", Stage: 2, Group: "casks", Independent: true, After: []int{1}},
+		{Name: "git", Stage: 3, Group: "cli"},
+		{Name: "pnpm-setup", Stage: 4},
+	}
+	for i := range p.Installs {
+		p.Installs[i].Detail = ""
+	}
+	if !reflect.DeepEqual(p.Installs, want) {
+		t.Errorf("Installs = %+v, want %+v", p.Installs, want)
+	}
+}
@@ -0,0 +1,106 @@
+// File: internal/policy/denied.go
+// Purpose: Detects installed software that org policy denies
+// Problem: Unapproved VPNs and EOL runtimes linger on laptops unnoticed
+// Role: Scans Homebrew formulae/casks and PATH commands against the policies.yaml denylist
+// Usage: matches, err := policy.FindDenied(ctx, policyConfig)
+// Design choices: One `brew list` per kind (not per entry); uninstall command derived from kind
+// Assumptions: Homebrew may be absent (then only command entries are checked)
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// DeniedMatch is installed software matching a denied entry
+// What: The installed name, its kind, and the entry it matched
+// Why: verify reports it; doctor offers the uninstall command
+type DeniedMatch struct {
+	// Name is the installed formula, cask, or command
+	Name string
+
+	// Kind is formula, cask, or command
+	Kind string
+
+	// Entry is the denylist entry that matched
+	Entry config.DeniedEntry
+}
+
+// UninstallCommand returns the command that removes the software
+// Returns: brew uninstall command, or empty for commands (no known installer)
+func (m DeniedMatch) UninstallCommand() string {
+	switch m.Kind {
+	case config.DeniedFormula:
+		return "brew uninstall " + m.Name
+	case config.DeniedCask:
+		return "brew uninstall --cask " + m.Name
+	}
+	return ""
+}
+
+// FindDenied lists installed software matching the denylist
+// What: Lists brew formulae and casks once each, checks PATH for command entries
+// Why: Shared by verify --policy and doctor
+// Params: ctx - context for command timeouts, cfg - policy configuration
+// Returns: Matches in denylist order, error if brew listing fails unexpectedly
+func FindDenied(ctx context.Context, cfg *config.PolicyConfig) ([]DeniedMatch, error) {
+	if len(cfg.Denied) == 0 {
+		return nil, nil
+	}
+
+	installed := map[string][]string{}
+	if _, err := exec.LookPath("brew"); err == nil {
+		for _, kind := range []string{config.DeniedFormula, config.DeniedCask} {
+			names, err := brewList(ctx, kind)
+			if err != nil {
+				return nil, err
+			}
+			installed[kind] = names
+		}
+	}
+
+	var matches []DeniedMatch
+	seen := map[string]bool{}
+	add := func(kind, name string) {
+		entry := cfg.IsDenied(kind, name)
+		if entry == nil || seen[kind+"/"+name] {
+			return
+		}
+		seen[kind+"/"+name] = true
+		matches = append(matches, DeniedMatch{Name: name, Kind: kind, Entry: *entry})
+	}
+
+	for _, kind := range []string{config.DeniedFormula, config.DeniedCask} {
+		for _, name := range installed[kind] {
+			add(kind, name)
+		}
+	}
+
+	for _, d := range cfg.Denied {
+		// Command patterns with wildcards can't be resolved via PATH lookup
+		if d.Kind != config.DeniedCommand || strings.ContainsAny(d.Name, "*?[") {
+			continue
+		}
+		if _, err := exec.LookPath(d.Name); err == nil {
+			add(config.DeniedCommand, d.Name)
+		}
+	}
+
+	return matches, nil
+}
+
+// brewList lists installed formulae or casks
+// Params: ctx - context, kind - formula or cask
+// Returns: Installed names
+func brewList(ctx context.Context, kind string) ([]string, error) {
+	output, err := exec.CommandContext(ctx, "brew", "list", "--"+kind, "-1").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list brew %ss: %w", kind, err)
+	}
+	return strings.Fields(string(output)), nil
+}
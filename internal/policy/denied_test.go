@@ -0,0 +1,51 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestFindDenied(t *testing.T) {
+	// brew lists two formulae and two casks; telnet is on PATH
+	bin := t.TempDir()
+	brew := `#!/bin/sh
+case "$2" in
+--formula) echo "jq"; echo "node@14" ;;
+--cask) echo "corp-vpn"; echo "free-vpn" ;;
+esac
+`
+	for name, script := range map[string]string{"brew": brew, "telnet": "#!/bin/sh\n"} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := &config.PolicyConfig{
+		Denied: []config.DeniedEntry{
+			{Name: "node@1[0-6]", Kind: config.DeniedFormula},
+			{Name: "*vpn*", Kind: config.DeniedCask},
+			{Name: "telnet", Kind: config.DeniedCommand},
+			{Name: "ftp", Kind: config.DeniedCommand},
+		},
+		Allowed: []string{"corp-vpn"},
+	}
+	matches, err := FindDenied(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range matches {
+		got = append(got, m.UninstallCommand()+"|"+m.Name)
+	}
+	want := []string{"brew uninstall node@14|node@14", "brew uninstall --cask free-vpn|free-vpn", "|telnet"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDenied() = %v, want %v", got, want)
+	}
+}
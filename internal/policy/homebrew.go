@@ -0,0 +1,207 @@
+// File: internal/policy/homebrew.go
+// Purpose: Evaluates the org Homebrew hardening baseline (policies.yaml homebrew section)
+// Problem: Analytics, insecure redirects, auto-update cadence, and prefix ownership were left at whatever each laptop had
+// Role: Produces one Result per managed setting; doctor reports them, the homebrew_policy setup builtin runs their fixes
+// Usage: results := policy.EvaluateHomebrew(ctx, *policyConfig.Homebrew)
+// Design choices: Environment settings go to Homebrew's own brew.env so they apply to every brew invocation
+//                 (scripts, GUI apps, devsetup itself), not just interactive shells; fixes are plain shell
+//                 commands so doctor and setup share them
+// Assumptions: Homebrew 4.x (reads brew.env); variables exported in shell profiles can still override brew.env
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+)
+
+// Homebrew environment variables managed by the policy
+const (
+	envNoInsecureRedirect = "HOMEBREW_NO_INSECURE_REDIRECT"
+	envAutoUpdateSecs     = "HOMEBREW_AUTO_UPDATE_SECS"
+)
+
+// brewManagedDirs are the prefix subdirectories Homebrew writes to
+// Why: On Intel Macs the prefix (/usr/local) itself is root-owned; only these must belong to the user
+var brewManagedDirs = []string{"bin", "etc", "include", "lib", "opt", "sbin", "share", "var", "Cellar", "Caskroom", "Frameworks"}
+
+// BrewEnvPath returns the per-user Homebrew environment file
+// Returns: $XDG_CONFIG_HOME/homebrew/brew.env when XDG_CONFIG_HOME is set, else ~/.homebrew/brew.env
+func BrewEnvPath() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "homebrew", "brew.env")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".homebrew", "brew.env")
+}
+
+// EvaluateHomebrew checks each setting managed by the Homebrew policy
+// What: Analytics state, brew.env variables, and Homebrew directory ownership
+// Why: Shared by doctor (report + guided fix) and the homebrew_policy setup builtin (apply)
+// Params: ctx - context for command timeouts, hp - Homebrew policy from policies.yaml
+// Returns: One Result per managed setting, named homebrew-<setting>
+// Edge cases: Without brew on PATH every result is non-compliant with guidance to install Homebrew first
+func EvaluateHomebrew(ctx context.Context, hp config.HomebrewPolicy) []Result {
+	var results []Result
+	add := func(name, description string, r Result) {
+		r.Policy = config.Policy{Name: "homebrew-" + name, Description: description, Severity: hp.Severity}
+		results = append(results, r)
+	}
+
+	prefix, err := run(ctx, "brew", "--prefix")
+	if err != nil {
+		add("installed", "Homebrew is installed", Result{
+			Detail:   "brew not found",
+			Guidance: "Install Homebrew first (devsetup install)",
+		})
+		return results
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	if hp.Analytics != nil && !*hp.Analytics {
+		add("analytics", "Homebrew analytics are disabled", evaluateBrewAnalytics(ctx))
+	}
+
+	env := readBrewEnv(BrewEnvPath())
+	if hp.NoInsecureRedirect {
+		add("insecure-redirect", "Homebrew refuses HTTPS to HTTP redirects", evaluateBrewEnv(env, envNoInsecureRedirect, "1"))
+	}
+	if hp.AutoUpdateInterval > 0 {
+		secs := strconv.Itoa(int(hp.AutoUpdateInterval.Seconds()))
+		add("auto-update", "Homebrew auto-update interval", evaluateBrewEnv(env, envAutoUpdateSecs, secs))
+	}
+
+	if hp.PrefixOwnership {
+		add("ownership", "Homebrew directories are owned by you", BrewOwnership(ctx, prefix))
+	}
+
+	return results
+}
+
+// BrewOwnership checks that the Homebrew prefix directories and repository belong to the user
+// Why: Shared by the homebrew policy and doctor's Homebrew health checks
+// Params: ctx - context for command timeouts, prefix - output of `brew --prefix`
+// Returns: Result with a chown fix command when any directory is foreign-owned or read-only
+func BrewOwnership(ctx context.Context, prefix string) Result {
+	dirs := []string{}
+	for _, name := range brewManagedDirs {
+		dirs = append(dirs, filepath.Join(prefix, name))
+	}
+	if repo, err := run(ctx, "brew", "--repository"); err == nil {
+		dirs = append(dirs, strings.TrimSpace(repo))
+	}
+	return evaluateBrewOwnership(dirs)
+}
+
+// evaluateBrewAnalytics checks `brew analytics state`
+func evaluateBrewAnalytics(ctx context.Context) Result {
+	output, err := run(ctx, "brew", "analytics", "state")
+	r := Result{FixCommand: "brew analytics off"}
+	if err != nil {
+		r.Detail = fmt.Sprintf("unable to query analytics: %v", err)
+		return r
+	}
+	r.Compliant = strings.Contains(output, "disabled")
+	r.Detail = firstLine(output)
+	return r
+}
+
+// evaluateBrewEnv checks one brew.env variable against the wanted value
+func evaluateBrewEnv(env map[string]string, key, want string) Result {
+	r := Result{FixCommand: brewEnvFixCommand(BrewEnvPath(), key, want)}
+	got, ok := env[key]
+	switch {
+	case !ok:
+		r.Detail = fmt.Sprintf("%s not set in brew.env", key)
+	case got != want:
+		r.Detail = fmt.Sprintf("%s=%s (want %s)", key, got, want)
+	default:
+		r.Compliant = true
+		r.Detail = fmt.Sprintf("%s=%s", key, got)
+		return r
+	}
+	r.Diff = brewEnvDiff(BrewEnvPath(), key, want)
+	return r
+}
+
+// evaluateBrewOwnership checks that existing Homebrew directories belong to the current user and are writable
+func evaluateBrewOwnership(dirs []string) Result {
+	uid := uint32(os.Getuid())
+	var bad []string
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			continue
+		}
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if (ok && st.Uid != uid) || info.Mode().Perm()&0200 == 0 {
+			bad = append(bad, dir)
+		}
+	}
+
+	if len(bad) == 0 {
+		return Result{Compliant: true, Detail: "owned and writable"}
+	}
+	quoted := make([]string, len(bad))
+	for i, dir := range bad {
+		quoted[i] = config.ShellQuote(dir)
+	}
+	list := strings.Join(quoted, " ")
+	return Result{
+		Detail:     fmt.Sprintf("not owned/writable: %s", strings.Join(bad, ", ")),
+		FixCommand: fmt.Sprintf(`sudo chown -R "$(whoami)" %s && chmod u+w %s`, list, list),
+	}
+}
+
+// readBrewEnv parses KEY=VALUE lines from a brew.env file
+// Returns: Variables (empty when the file doesn't exist)
+// Edge cases: Comments, blank lines, "export " prefixes, and surrounding quotes are tolerated
+func readBrewEnv(path string) map[string]string {
+	env := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return env
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		env[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return env
+}
+
+// brewEnvFixCommand returns a shell command that sets key=value in a brew.env file
+// Why: Replaces any existing line for key so repeated fixes don't pile up duplicates
+func brewEnvFixCommand(path, key, value string) string {
+	p := config.ShellQuote(path)
+	return fmt.Sprintf(`mkdir -p %s && touch %s && { grep -v '^%s=' %s; echo '%s=%s'; } > %s.tmp && mv %s.tmp %s`,
+		config.ShellQuote(filepath.Dir(path)), p, key, p, key, value, p, p, p)
+}
+
+// brewEnvDiff previews what brewEnvFixCommand does to a brew.env file
+// Returns: Unified diff of the file before and after the fix (a missing file diffs from empty)
+func brewEnvDiff(path, key, value string) string {
+	data, _ := os.ReadFile(path)
+	old := string(data)
+	var kept []string
+	for _, line := range strings.SplitAfter(old, "\n") {
+		if line != "" && !strings.HasPrefix(line, key+"=") {
+			kept = append(kept, strings.TrimSuffix(line, "\n")+"\n")
+		}
+	}
+	return dotfiles.Diff(path, old, strings.Join(kept, "")+key+"="+value+"\n")
+}
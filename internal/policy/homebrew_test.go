@@ -0,0 +1,24 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBrewEnvDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "brew.env")
+	if err := os.WriteFile(path, []byte("HOMEBREW_NO_ANALYTICS=1\nHOMEBREW_AUTO_UPDATE_SECS=60\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "--- " + path + "\n+++ " + path + " (proposed)\n@@ -1,2 +1,2 @@\n HOMEBREW_NO_ANALYTICS=1\n-HOMEBREW_AUTO_UPDATE_SECS=60\n+HOMEBREW_AUTO_UPDATE_SECS=86400\n"
+	if got := brewEnvDiff(path, envAutoUpdateSecs, "86400"); got != want {
+		t.Errorf("brewEnvDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	missing := filepath.Join(t.TempDir(), "brew.env")
+	if got := brewEnvDiff(missing, envNoInsecureRedirect, "1"); got == "" {
+		t.Error("brewEnvDiff() of a missing file should add the line")
+	}
+}
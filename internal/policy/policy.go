@@ -0,0 +1,200 @@
+// File: internal/policy/policy.go
+// Purpose: Evaluates organization compliance policies against the running Mac
+// Problem: Security baselines (FileVault, firewall, screen lock, OS version) need consistent checks
+// Role: Policy engine used by verify --policy and doctor guided fixes
+// Usage: results := policy.EvaluateAll(ctx, policyConfig)
+// Design choices: One evaluator per built-in type; each result carries fix command and/or guidance
+// Assumptions: macOS system tools (fdesetup, socketfilterfw, defaults, sw_vers) are available
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/version"
+)
+
+// firewallCmd is the macOS application firewall control binary
+const firewallCmd = "/usr/libexec/ApplicationFirewall/socketfilterfw"
+
+// Result is the outcome of evaluating one policy
+// What: Compliance status plus observed state and remediation options
+// Why: verify reports compliance; doctor needs the fix command or manual guidance
+type Result struct {
+	// Policy that was evaluated
+	Policy config.Policy
+
+	// Compliant is true when the machine satisfies the policy
+	Compliant bool
+
+	// Detail describes the observed state (e.g. "idle time 1200s")
+	Detail string
+
+	// FixCommand is a shell command that remediates the policy (may require sudo)
+	FixCommand string
+
+	// Diff previews the change FixCommand makes to a file (unified diff), when it edits one
+	Diff string
+
+	// Guidance is manual remediation instructions when no command fix exists
+	Guidance string
+}
+
+// EvaluateAll evaluates every policy in the configuration
+// What: Runs Evaluate for each policy in declaration order
+// Why: Convenience for doctor, which reports all policies together
+// Params: ctx - context for command timeouts, cfg - loaded policy configuration
+// Returns: One Result per policy
+func EvaluateAll(ctx context.Context, cfg *config.PolicyConfig) []Result {
+	results := make([]Result, 0, len(cfg.Policies))
+	for _, p := range cfg.Policies {
+		results = append(results, Evaluate(ctx, p))
+	}
+	return results
+}
+
+// Evaluate checks a single policy
+// What: Dispatches to the evaluator for the policy type
+// Why: Each built-in baseline needs its own probe and fix
+// Params: ctx - context for command timeouts, p - policy to evaluate
+// Returns: Result with compliance, detail, and remediation
+func Evaluate(ctx context.Context, p config.Policy) Result {
+	var r Result
+	switch p.Type {
+	case config.PolicyFileVault:
+		r = evaluateFileVault(ctx)
+	case config.PolicyFirewall:
+		r = evaluateFirewall(ctx)
+	case config.PolicyScreenLock:
+		r = evaluateScreenLock(ctx, p.MaxIdle)
+	case config.PolicyOSVersion:
+		r = evaluateOSVersion(ctx, p.MinVersion)
+	case config.PolicyCommand:
+		r = evaluateCommand(ctx, p)
+	default:
+		r = Result{Detail: fmt.Sprintf("unknown policy type %q", p.Type)}
+	}
+
+	r.Policy = p
+	if p.Guidance != "" {
+		r.Guidance = p.Guidance
+	}
+	return r
+}
+
+// evaluateFileVault checks disk encryption status via fdesetup
+func evaluateFileVault(ctx context.Context) Result {
+	output, err := run(ctx, "fdesetup", "status")
+	if err != nil {
+		return Result{Detail: fmt.Sprintf("unable to query FileVault: %v", err)}
+	}
+
+	return Result{
+		Compliant: strings.Contains(output, "FileVault is On"),
+		Detail:    firstLine(output),
+		// Enabling FileVault needs a restart and recovery key escrow, so no auto-fix
+		Guidance: "Enable FileVault in System Settings → Privacy & Security → FileVault",
+	}
+}
+
+// evaluateFirewall checks the application firewall global state
+func evaluateFirewall(ctx context.Context) Result {
+	output, err := run(ctx, firewallCmd, "--getglobalstate")
+	if err != nil {
+		return Result{Detail: fmt.Sprintf("unable to query firewall: %v", err)}
+	}
+
+	return Result{
+		Compliant:  strings.Contains(output, "enabled"),
+		Detail:     firstLine(output),
+		FixCommand: "sudo " + firewallCmd + " --setglobalstate on",
+		Guidance:   "Enable the firewall in System Settings → Network → Firewall",
+	}
+}
+
+// evaluateScreenLock checks screensaver idle time is set and within the maximum
+func evaluateScreenLock(ctx context.Context, maxIdle time.Duration) Result {
+	maxSeconds := int(maxIdle.Seconds())
+	r := Result{
+		FixCommand: fmt.Sprintf("defaults -currentHost write com.apple.screensaver idleTime -int %d", maxSeconds),
+		Guidance:   "Set 'Start Screen Saver when inactive' in System Settings → Lock Screen",
+	}
+
+	output, err := run(ctx, "defaults", "-currentHost", "read", "com.apple.screensaver", "idleTime")
+	if err != nil {
+		r.Detail = "idle time not configured"
+		return r
+	}
+
+	idle, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		r.Detail = fmt.Sprintf("unparseable idle time %q", strings.TrimSpace(output))
+		return r
+	}
+
+	// 0 means "never", which is the least compliant setting
+	r.Compliant = idle > 0 && idle <= maxSeconds
+	r.Detail = fmt.Sprintf("idle time %ds (max %ds)", idle, maxSeconds)
+	return r
+}
+
+// MacOSVersion returns the running macOS version
+// Why: Shared by the os_version policy, the install preflight, and doctor's upgrade advisory
+// Params: ctx - context for the sw_vers call
+// Returns: Version like "14.6.1", error if sw_vers is unavailable (not macOS)
+func MacOSVersion(ctx context.Context) (string, error) {
+	output, err := run(ctx, "sw_vers", "-productVersion")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// evaluateOSVersion compares the running macOS version against the minimum
+func evaluateOSVersion(ctx context.Context, minVersion string) Result {
+	current, err := MacOSVersion(ctx)
+	if err != nil {
+		return Result{Detail: fmt.Sprintf("unable to determine macOS version: %v", err)}
+	}
+
+	return Result{
+		Compliant: version.Compare(current, minVersion) >= 0,
+		Detail:    fmt.Sprintf("macOS %s (minimum %s)", current, minVersion),
+		Guidance:  "Update macOS via System Settings → General → Software Update",
+	}
+}
+
+// evaluateCommand runs a custom check command
+func evaluateCommand(ctx context.Context, p config.Policy) Result {
+	output, err := run(ctx, "sh", "-c", p.Check)
+	r := Result{Compliant: err == nil, FixCommand: p.Fix}
+	if err != nil {
+		r.Detail = firstLine(output)
+		if r.Detail == "" {
+			r.Detail = err.Error()
+		}
+	}
+	return r
+}
+
+// run executes a command and returns its combined output
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return string(output), err
+}
+
+// firstLine returns the first non-empty line of command output
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
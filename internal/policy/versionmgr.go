@@ -0,0 +1,191 @@
+// File: internal/policy/versionmgr.go
+// Purpose: Detects Node and Python version managers installed side by side
+// Problem: nvm + fnm + volta (or pyenv + uv + conda) on one laptop fight over PATH through their shell hooks, so
+//          `node` and `python3` resolve differently per shell and "works on my machine" bugs follow
+// Role: Finds each known manager by install location and by hook lines in shell startup files
+// Usage: found := policy.FindVersionManagers(home); doctor compares them with policies.yaml version_managers
+// Design choices: Hooks are detected even when the manager itself is gone (a dangling hook still breaks shells);
+//                 cleanup commands back up rc files (.devsetup-bak) before deleting hook lines
+// Assumptions: Managers live at their documented default locations or on PATH; hooks sit in the usual rc files
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// VersionManager describes how to find and remove one version manager
+type VersionManager struct {
+	// Name matches policies.yaml version_managers values (e.g. "nvm")
+	Name string
+
+	// Language is the runtime it manages (node or python)
+	Language string
+
+	// Commands are executables that indicate an install when on PATH
+	Commands []string
+
+	// Dirs are install directories relative to the home directory
+	Dirs []string
+
+	// HookPatterns are substrings identifying its shell startup lines
+	HookPatterns []string
+
+	// Uninstall removes the manager itself
+	Uninstall string
+
+	// UnhookCommand removes its hooks instead of deleting lines (conda manages its own block)
+	UnhookCommand string
+}
+
+// VersionManagers are the managers doctor knows how to detect
+var VersionManagers = []VersionManager{
+	{Name: "nvm", Language: "node", Dirs: []string{".nvm"}, HookPatterns: []string{"NVM_DIR", "nvm.sh"},
+		Uninstall: `rm -rf "${NVM_DIR:-$HOME/.nvm}"`},
+	{Name: "fnm", Language: "node", Commands: []string{"fnm"}, Dirs: []string{".local/share/fnm"}, HookPatterns: []string{"fnm env"},
+		Uninstall: `brew uninstall fnm; rm -rf "$HOME/.local/share/fnm"`},
+	{Name: "volta", Language: "node", Commands: []string{"volta"}, Dirs: []string{".volta"}, HookPatterns: []string{"VOLTA_HOME"},
+		Uninstall: `rm -rf "$HOME/.volta"`},
+	{Name: "pyenv", Language: "python", Commands: []string{"pyenv"}, Dirs: []string{".pyenv"}, HookPatterns: []string{"pyenv init", "PYENV_ROOT"},
+		Uninstall: `brew uninstall pyenv; rm -rf "$HOME/.pyenv"`},
+	{Name: "uv", Language: "python", Commands: []string{"uv"},
+		Uninstall: "brew uninstall uv"},
+	{Name: "conda", Language: "python", Commands: []string{"conda"}, Dirs: []string{"miniconda3", "anaconda3", "miniforge3"},
+		HookPatterns: []string{">>> conda initialize", "__conda_setup", "conda.sh"},
+		Uninstall:    `rm -rf "$HOME/miniconda3" "$HOME/anaconda3" "$HOME/miniforge3"`, UnhookCommand: "conda init --reverse --all"},
+}
+
+// shellStartupFiles are the rc files scanned for hooks, relative to home
+var shellStartupFiles = []string{".zshenv", ".zprofile", ".zshrc", ".profile", ".bash_profile", ".bashrc"}
+
+// ShellHook is one line in a shell startup file that activates a manager
+type ShellHook struct {
+	// File is the startup file path
+	File string
+
+	// Line is the 1-based line number
+	Line int
+
+	// Text is the trimmed line
+	Text string
+}
+
+// FoundVersionManager is a manager present on this machine
+type FoundVersionManager struct {
+	VersionManager
+
+	// Location is where it is installed ("" when only hooks remain)
+	Location string
+
+	// Hooks are its active lines in shell startup files
+	Hooks []ShellHook
+}
+
+// FindVersionManagers lists version managers installed or hooked into shells
+// What: Checks PATH and default install directories, then scans rc files for hook lines
+// Why: Doctor flags languages with more than one manager and those that differ from the org standard
+// Params: home - home directory to inspect
+// Returns: Found managers in VersionManagers order
+// Edge cases: Commented-out hook lines are ignored
+func FindVersionManagers(home string) []FoundVersionManager {
+	var found []FoundVersionManager
+	for _, vm := range VersionManagers {
+		f := FoundVersionManager{VersionManager: vm, Location: managerLocation(home, vm)}
+		for _, rc := range shellStartupFiles {
+			f.Hooks = append(f.Hooks, findHooks(filepath.Join(home, rc), vm.HookPatterns)...)
+		}
+		if f.Location != "" || len(f.Hooks) > 0 {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+// CleanupCommand returns the shell command that removes the manager and its hooks
+// What: Backs up each rc file with hooks and deletes the hook lines, then uninstalls
+// Returns: Hook removal then uninstall, as steps separated by "; " (empty when nothing to do)
+func (f FoundVersionManager) CleanupCommand() string {
+	var steps []string
+	if f.UnhookCommand != "" && len(f.Hooks) > 0 {
+		steps = append(steps, f.UnhookCommand)
+	} else {
+		seen := map[string]bool{}
+		for _, h := range f.Hooks {
+			if seen[h.File] {
+				continue
+			}
+			seen[h.File] = true
+			var exprs []string
+			for _, p := range f.HookPatterns {
+				exprs = append(exprs, "-e "+config.ShellQuote("/"+sedEscape(p)+"/d"))
+			}
+			steps = append(steps, fmt.Sprintf("sed -i.devsetup-bak %s %s", strings.Join(exprs, " "), config.ShellQuote(h.File)))
+		}
+	}
+	if f.Location != "" {
+		steps = append(steps, f.Uninstall)
+	}
+	return strings.Join(steps, "; ")
+}
+
+// managerLocation returns where a manager is installed
+// Returns: Executable path, install directory, or "" if neither exists
+func managerLocation(home string, vm VersionManager) string {
+	if vm.Name == "nvm" {
+		if dir := os.Getenv("NVM_DIR"); dir != "" {
+			if _, err := os.Stat(filepath.Join(dir, "nvm.sh")); err == nil {
+				return dir
+			}
+		}
+	}
+	for _, c := range vm.Commands {
+		if path, err := exec.LookPath(c); err == nil {
+			return path
+		}
+	}
+	for _, d := range vm.Dirs {
+		dir := filepath.Join(home, d)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// findHooks returns lines of a startup file containing any pattern
+// Edge cases: Missing files yield nothing; comments are skipped except conda's ">>> conda initialize" marker
+func findHooks(path string, patterns []string) []ShellHook {
+	if len(patterns) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var hooks []ShellHook
+	for i, line := range strings.Split(string(data), "\n") {
+		text := strings.TrimSpace(line)
+		if text == "" || (strings.HasPrefix(text, "#") && !strings.Contains(text, ">>>")) {
+			continue
+		}
+		for _, p := range patterns {
+			if strings.Contains(text, p) {
+				hooks = append(hooks, ShellHook{File: path, Line: i + 1, Text: text})
+				break
+			}
+		}
+	}
+	return hooks
+}
+
+// sedEscape escapes a literal for use inside a sed basic regex delimited by /
+func sedEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "/", `\/`, ".", `\.`, "$", `\$`, "*", `\*`, "[", `\[`, "^", `\^`).Replace(s)
+}
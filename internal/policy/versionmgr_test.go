@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindVersionManagersHooks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("NVM_DIR", "")
+	zshrc := filepath.Join(home, ".zshrc")
+	rc := "# export NVM_DIR=\"$HOME/.nvm\"\n" +
+		"export NVM_DIR=\"$HOME/.nvm\"\n" +
+		"[ -s \"$NVM_DIR/nvm.sh\" ] && \\. \"$NVM_DIR/nvm.sh\"\n" +
+		"eval \"$(fnm env --use-on-cd)\"\n"
+	if err := os.WriteFile(zshrc, []byte(rc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(home, ".nvm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]FoundVersionManager{}
+	for _, f := range FindVersionManagers(home) {
+		byName[f.Name] = f
+	}
+
+	nvm, ok := byName["nvm"]
+	if !ok || nvm.Location != filepath.Join(home, ".nvm") || len(nvm.Hooks) != 2 || nvm.Hooks[0].Line != 2 {
+		t.Fatalf("nvm = %+v, want installed with hooks on lines 2-3", nvm)
+	}
+	if fnm, ok := byName["fnm"]; !ok || len(fnm.Hooks) != 1 {
+		t.Errorf("fnm = %+v, want one hook", fnm)
+	}
+
+	cleanup := nvm.CleanupCommand()
+	if !strings.HasPrefix(cleanup, "sed -i.devsetup-bak ") || !strings.Contains(cleanup, `rm -rf "${NVM_DIR:-$HOME/.nvm}"`) {
+		t.Errorf("CleanupCommand() = %q", cleanup)
+	}
+
+	if _, err := exec.LookPath("sed"); err != nil {
+		return
+	}
+	hookOnly := nvm
+	hookOnly.Location = ""
+	if out, err := exec.Command("sh", "-c", hookOnly.CleanupCommand()).CombinedOutput(); err != nil {
+		t.Fatalf("cleanup: %v: %s", err, out)
+	}
+	data, _ := os.ReadFile(zshrc)
+	if want := "eval \"$(fnm env --use-on-cd)\"\n"; string(data) != want {
+		t.Errorf("after cleanup .zshrc = %q, want %q", data, want)
+	}
+}
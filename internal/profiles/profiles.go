@@ -0,0 +1,52 @@
+// File: internal/profiles/profiles.go
+// Purpose: Configuration profile (.mobileconfig) installation and lookup
+// Problem: Wi-Fi, VPN, and printer profiles were the last manual items on the new-laptop IT checklist
+// Role: Lists installed profile identifiers and hands profiles to System Settings for approval
+// Usage: ids, err := profiles.Installed(ctx); if !ids["com.corp.wifi"] { profiles.Open(ctx, path) }
+// Design choices: Identifiers (not file names) are the source of truth for "installed", so a profile pushed by
+//                 MDM counts too; installation goes through `open` because macOS 11+ requires user approval
+//                 in System Settings and `profiles install` no longer installs configuration profiles
+// Assumptions: macOS `profiles` command; `profiles show` lists user and device profiles with their identifiers
+
+package profiles
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+)
+
+// identifierPattern matches identifier lines in `profiles show` output
+var identifierPattern = regexp.MustCompile(`profileIdentifier:\s*(\S+)`)
+
+// Installed returns the identifiers of installed configuration profiles
+// Params: ctx - bounds the profiles command
+// Returns: Set of identifiers, error if the profiles command fails (e.g. not macOS)
+func Installed(ctx context.Context) (map[string]bool, error) {
+	output, err := exec.CommandContext(ctx, "profiles", "show", "-type", "configuration").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	return parseIdentifiers(string(output)), nil
+}
+
+// Open hands a .mobileconfig file to System Settings
+// What: Registers the profile for installation; the user approves it in System Settings
+// Params: ctx - bounds the open command, path - .mobileconfig file
+// Returns: Error if the file can't be opened
+func Open(ctx context.Context, path string) error {
+	if output, err := exec.CommandContext(ctx, "open", path).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to open %s: %w (%s)", path, err, output)
+	}
+	return nil
+}
+
+// parseIdentifiers extracts profile identifiers from `profiles show` output
+func parseIdentifiers(output string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, m := range identifierPattern.FindAllStringSubmatch(output, -1) {
+		ids[m[1]] = true
+	}
+	return ids
+}
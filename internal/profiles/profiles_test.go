@@ -0,0 +1,16 @@
+package profiles
+
+import "testing"
+
+func TestParseIdentifiers(t *testing.T) {
+	output := `There are 2 configuration profiles installed
+
+Attribute: profileIdentifier: com.corp.wifi
+  Attribute: profileDisplayName: Corp Wi-Fi
+_computerlevel[2] attribute: profileIdentifier: com.corp.vpn
+`
+	ids := parseIdentifiers(output)
+	if len(ids) != 2 || !ids["com.corp.wifi"] || !ids["com.corp.vpn"] {
+		t.Errorf("parseIdentifiers() = %v", ids)
+	}
+}
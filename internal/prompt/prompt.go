@@ -0,0 +1,245 @@
+// File: internal/prompt/prompt.go
+// Purpose: Terminal prompts with a timeout, Ctrl-C deferral, and a recap of unanswered prompts
+// Problem: A prompt that slipped into an unattended run (a new confirm, a prompt task) blocked forever, and Ctrl-C
+//          at any prompt killed the whole install or setup instead of just skipping that question
+// Role: Reads prompt answers for install, setup, and doctor; a prompt left unanswered takes its default after the
+//       timeout, Ctrl-C defers the asking task, and both are listed by Recap when the run ends
+// Usage: answer, err := prompt.Ask(prompt.Question{Task: "gemini-api-key", Label: "GEMINI_API_KEY"});
+//        if errors.Is(err, prompt.ErrDeferred) { ... }; prompt.Recap(ui) at the end of the run
+// Design choices: Stdin is read one byte at a time by a single reader goroutine, so nothing is buffered past the
+//                 answer (commands run later still get their input); a read abandoned by a timeout or Ctrl-C is
+//                 picked up by the next prompt rather than started twice, and interactive child processes get their
+//                 stdin from Stdin so they never read the terminal alongside it; SIGINT is caught only while a
+//                 prompt waits; secret answers are read with terminal echo off (stty)
+// Assumptions: Prompts are answered one line at a time; a line typed after its prompt timed out answers the next one
+
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+// DefaultTimeout is how long a prompt waits for an answer
+const DefaultTimeout = 5 * time.Minute
+
+// ErrDeferred is returned when the user presses Ctrl-C at a prompt
+var ErrDeferred = errors.New("deferred with Ctrl-C")
+
+// Question is one prompt
+type Question struct {
+	// Task is what asks (tool, setup task, or file), shown in the recap
+	Task string
+
+	// Label is a short form of the question for the recap
+	Label string
+
+	// Default is the answer used when the prompt times out
+	Default string
+
+	// Secret hides the typed answer (terminal echo off) and keeps Default out of the recap
+	Secret bool
+}
+
+// Skipped is a prompt that got no answer
+type Skipped struct {
+	Question
+
+	// Reason is why (timed out or deferred)
+	Reason string
+
+	// Deferred is whether Ctrl-C skipped it (rather than the timeout)
+	Deferred bool
+}
+
+// line is the result of one read
+type line struct {
+	text string
+	err  error
+}
+
+var (
+	mu      sync.Mutex
+	timeout           = DefaultTimeout
+	input   io.Reader = os.Stdin
+	pending chan line
+	skipped []Skipped
+)
+
+// SetTimeout sets how long prompts wait
+// Params: d - timeout (0 = wait forever)
+func SetTimeout(d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	timeout = d
+}
+
+// ParseTimeout parses a --prompt-timeout value
+// Params: s - duration ("5m") or "0"/"none" to wait forever
+// Returns: Timeout, error for negative or malformed values
+func ParseTimeout(s string) (time.Duration, error) {
+	if s == "none" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid prompt timeout %q (use a duration like 5m, or 0 to wait forever)", s)
+	}
+	return d, nil
+}
+
+// Ask waits for one line of input
+// What: Returns the typed line; after the timeout the question's default; on Ctrl-C ErrDeferred
+// Why: Every prompt in a run goes through here so none can hang it and all unanswered ones are recapped
+// Params: q - the question (callers print the question text themselves)
+// Returns: Answer without the line ending; ErrDeferred on Ctrl-C; io.EOF when stdin is closed
+// Edge cases: Timed-out and deferred prompts are recorded for Recap; echo is restored even when a secret prompt
+// times out
+func Ask(q Question) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if q.Secret && input == os.Stdin {
+		if err := stty("-echo"); err == nil {
+			defer func() {
+				_ = stty("echo")
+				fmt.Println()
+			}()
+		}
+	}
+
+	if pending == nil {
+		pending = make(chan line, 1)
+		go func(r io.Reader, ch chan<- line) {
+			text, err := readLine(r)
+			ch <- line{text: text, err: err}
+		}(input, pending)
+	}
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case l := <-pending:
+		pending = nil
+		return l.text, l.err
+	case <-interrupts:
+		fmt.Println()
+		skipped = append(skipped, Skipped{Question: q, Reason: "deferred with Ctrl-C", Deferred: true})
+		return "", ErrDeferred
+	case <-expired:
+		fmt.Println()
+		skipped = append(skipped, Skipped{Question: q, Reason: fmt.Sprintf("no answer within %s", timeout)})
+		return q.Default, nil
+	}
+}
+
+// Confirm asks a yes/no question that defaults to no
+// Params: task - what asks, question - printed as is (e.g. "Apply this change? [y/N] ")
+// Returns: True for y/yes; false on a timeout, closed stdin, or any other answer; ErrDeferred on Ctrl-C
+func Confirm(task, question string) (bool, error) {
+	fmt.Print(question)
+	label := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(question), "[y/N]"))
+	answer, err := Ask(Question{Task: task, Label: label, Default: "n"})
+	if errors.Is(err, ErrDeferred) {
+		return false, err
+	}
+	if err != nil {
+		fmt.Println()
+		return false, nil
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// Stdin returns the stdin to give an interactive child process (sudo, an installer script, a pager)
+// What: The prompt input, or nil (the null device) while a read abandoned by a timed-out or deferred prompt is still
+// waiting on it
+// Why: That read can't be cancelled, so a child sharing the terminal with it would lose keystrokes to it; the
+// line it gets answers the next prompt instead
+// Edge cases: Waits while a prompt is being answered; sudo and ssh-keygen read passwords from /dev/tty regardless
+func Stdin() io.Reader {
+	mu.Lock()
+	defer mu.Unlock()
+	if pending != nil {
+		return nil
+	}
+	return input
+}
+
+// Unanswered returns the prompts of this run that got no answer, in order
+func Unanswered() []Skipped {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Skipped(nil), skipped...)
+}
+
+// Recap lists the prompts that got no answer
+// Params: u - UI to print on
+// Edge cases: Prints nothing when every prompt was answered
+func Recap(u ui.UI) {
+	list := Unanswered()
+	if len(list) == 0 {
+		return
+	}
+	u.Warning("⏭  %d prompt(s) got no answer:", len(list))
+	for _, s := range list {
+		msg := fmt.Sprintf("  • %s: %s (%s", s.Task, s.Label, s.Reason)
+		switch {
+		case s.Deferred:
+			msg += ", task deferred)"
+		case s.Secret || s.Default == "":
+			msg += ", skipped)"
+		default:
+			msg += fmt.Sprintf(", used %q)", s.Default)
+		}
+		u.Info("%s", msg)
+	}
+	u.Info("  Run the command again to answer them")
+	u.Info("")
+}
+
+// stty changes terminal settings of stdin (an error when stdin is not a terminal)
+func stty(setting string) error {
+	cmd := exec.Command("stty", setting)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// readLine reads up to a newline one byte at a time, so nothing past it is consumed
+func readLine(r io.Reader) (string, error) {
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return strings.TrimSuffix(b.String(), "\r"), nil
+			}
+			b.WriteByte(buf[0])
+		}
+		if err != nil {
+			if b.Len() > 0 && errors.Is(err, io.EOF) {
+				return b.String(), nil
+			}
+			return b.String(), err
+		}
+	}
+}
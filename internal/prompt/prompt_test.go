@@ -0,0 +1,133 @@
+package prompt
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// usePipe feeds prompts from a pipe for the duration of a test
+func usePipe(t *testing.T, d time.Duration) *io.PipeWriter {
+	t.Helper()
+	r, w := io.Pipe()
+	mu.Lock()
+	input, pending, skipped, timeout = r, nil, nil, d
+	mu.Unlock()
+	t.Cleanup(func() {
+		_ = w.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		// Let a read abandoned by a timeout or Ctrl-C end on the closed pipe before input changes back
+		if pending != nil {
+			<-pending
+		}
+		input, pending, skipped, timeout = os.Stdin, nil, nil, DefaultTimeout
+	})
+	return w
+}
+
+func TestAskAnswered(t *testing.T) {
+	w := usePipe(t, time.Minute)
+	go func() { _, _ = io.WriteString(w, "sk-123\r\nsecond\n") }()
+
+	for _, want := range []string{"sk-123", "second"} {
+		got, err := Ask(Question{Task: "claude-api-key", Label: "ANTHROPIC_API_KEY"})
+		if err != nil || got != want {
+			t.Errorf("Ask() = %q, %v; want %q", got, err, want)
+		}
+	}
+	if list := Unanswered(); len(list) != 0 {
+		t.Errorf("Unanswered() = %+v, want none", list)
+	}
+}
+
+func TestAskTimeout(t *testing.T) {
+	w := usePipe(t, 20*time.Millisecond)
+
+	got, err := Ask(Question{Task: "git-config", Label: "GIT_EDITOR", Default: "vim"})
+	if err != nil || got != "vim" {
+		t.Fatalf("Ask() = %q, %v; want the default", got, err)
+	}
+	list := Unanswered()
+	if len(list) != 1 || list[0].Task != "git-config" || list[0].Deferred {
+		t.Fatalf("Unanswered() = %+v", list)
+	}
+
+	// A line typed late answers the next prompt
+	go func() { _, _ = io.WriteString(w, "nano\n") }()
+	SetTimeout(time.Minute)
+	if got, err := Ask(Question{Task: "git-config", Label: "GIT_PAGER"}); err != nil || got != "nano" {
+		t.Errorf("Ask() after a timeout = %q, %v; want nano", got, err)
+	}
+}
+
+func TestStdinWhileReadPending(t *testing.T) {
+	w := usePipe(t, 20*time.Millisecond)
+
+	if got := Stdin(); got == nil {
+		t.Fatal("Stdin() = nil with no read pending")
+	}
+	if _, err := Ask(Question{Task: "xcode-clt", Label: "continue"}); err != nil {
+		t.Fatal(err)
+	}
+	// The timed-out read still owns the input: a child process must not read alongside it
+	if got := Stdin(); got != nil {
+		t.Errorf("Stdin() = %v while a timed-out read is pending, want nil", got)
+	}
+
+	go func() { _, _ = io.WriteString(w, "y\n") }()
+	SetTimeout(time.Minute)
+	if got, err := Ask(Question{Task: "xcode-clt", Label: "continue"}); err != nil || got != "y" {
+		t.Fatalf("Ask() = %q, %v; want y", got, err)
+	}
+	if got := Stdin(); got == nil {
+		t.Error("Stdin() = nil after the pending read was answered")
+	}
+}
+
+func TestAskInterrupt(t *testing.T) {
+	usePipe(t, time.Minute)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	}()
+	if _, err := Ask(Question{Task: "gemini-api-key", Label: "GEMINI_API_KEY"}); !errors.Is(err, ErrDeferred) {
+		t.Fatalf("Ask() error = %v, want ErrDeferred", err)
+	}
+	if list := Unanswered(); len(list) != 1 || !list[0].Deferred {
+		t.Errorf("Unanswered() = %+v, want one deferred prompt", list)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	w := usePipe(t, time.Minute)
+	go func() { _, _ = io.WriteString(w, "Y\nnope\n") }()
+
+	if ok, err := Confirm("~/.zshrc", "Apply this change? [y/N] "); !ok || err != nil {
+		t.Errorf("Confirm(Y) = %v, %v", ok, err)
+	}
+	if ok, err := Confirm("~/.zshrc", "Apply this change? [y/N] "); ok || err != nil {
+		t.Errorf("Confirm(nope) = %v, %v", ok, err)
+	}
+	_ = w.Close()
+	if ok, err := Confirm("~/.zshrc", "Apply this change? [y/N] "); ok || err != nil {
+		t.Errorf("Confirm(EOF) = %v, %v", ok, err)
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	for in, want := range map[string]time.Duration{"5m": 5 * time.Minute, "0": 0, "none": 0, "90s": 90 * time.Second} {
+		if got, err := ParseTimeout(in); err != nil || got != want {
+			t.Errorf("ParseTimeout(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"-1m", "soon", ""} {
+		if _, err := ParseTimeout(in); err == nil {
+			t.Errorf("ParseTimeout(%q) succeeded, want error", in)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// File: internal/quarantine/quarantine.go
+// Purpose: Detect, clear, and pre-approve macOS quarantine (Gatekeeper) on installed files
+// Problem: Downloaded binaries and apps keep com.apple.quarantine, so their first run stops on a Gatekeeper prompt
+//          (or "cannot be opened because the developer cannot be verified") long after the install finished
+// Role: Reports whether a path is quarantined or rejected by Gatekeeper, and carries out the keep/clear/approve
+//       actions for install; doctor uses the same checks to find blocked binaries
+// Usage: if quarantine.Quarantined(ctx, path) { err := quarantine.Apply(ctx, config.QuarantineClear, path) }
+// Design choices: Shells out to xattr and spctl (both ship with macOS) instead of reading extended attributes
+//                 directly; clearing is recursive so an app bundle's nested helpers are covered too
+// Assumptions: macOS; approve needs an admin password (spctl --add runs under sudo)
+
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// Attribute is the extended attribute macOS sets on downloaded files
+const Attribute = "com.apple.quarantine"
+
+// label tags spctl rules added by devsetup
+const label = "devsetup"
+
+// Quarantined reports whether a path carries the quarantine attribute
+func Quarantined(ctx context.Context, path string) bool {
+	return exec.CommandContext(ctx, "xattr", "-p", Attribute, path).Run() == nil
+}
+
+// Blocked reports whether Gatekeeper would stop a quarantined path from running
+// Returns: Gatekeeper's reason (e.g. "rejected"), "" when the path is not quarantined or is accepted
+func Blocked(ctx context.Context, path string) string {
+	if !Quarantined(ctx, path) {
+		return ""
+	}
+	kind := "execute"
+	if strings.HasSuffix(strings.TrimSuffix(path, "/"), ".app") {
+		kind = "exec"
+	}
+	out, err := exec.CommandContext(ctx, "spctl", "--assess", "--type", kind, path).CombinedOutput()
+	if err == nil {
+		return ""
+	}
+	reason := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(out)), path+":"))
+	if reason == "" {
+		reason = "rejected"
+	}
+	return reason
+}
+
+// ClearCommand returns the shell command that removes quarantine from a path
+// Why: Doctor offers it as a fix
+func ClearCommand(path string) string {
+	return fmt.Sprintf("xattr -dr %s '%s'", Attribute, strings.ReplaceAll(path, "'", `'\''`))
+}
+
+// Apply carries out a quarantine action on an installed path
+// Params: action - config.QuarantineKeep, QuarantineClear, or QuarantineApprove; path - file or app bundle
+// Returns: Error if xattr or spctl fails
+// Edge cases: Paths that are not quarantined are left alone whatever the action
+func Apply(ctx context.Context, action, path string) error {
+	if action == config.QuarantineKeep || !Quarantined(ctx, path) {
+		return nil
+	}
+	var cmd *exec.Cmd
+	switch action {
+	case config.QuarantineClear:
+		cmd = exec.CommandContext(ctx, "xattr", "-dr", Attribute, path)
+	case config.QuarantineApprove:
+		cmd = exec.CommandContext(ctx, "sudo", "spctl", "--add", "--label", label, path)
+		cmd.Stdin = prompt.Stdin()
+	default:
+		return fmt.Errorf("unknown quarantine action %s", action)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to %s quarantine on %s: %v: %s", action, path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+// File: internal/reconcile/reconcile.go
+// Purpose: Reconciles Homebrew packages on the machine against tools.yaml
+// Problem: Laptops accumulate brew packages nobody declared, and declared packages go missing, silently
+// Role: Finds extra and missing packages; adopts extras into tools.yaml or records them in a per-user ignore list
+// Usage: result, err := reconcile.Find(ctx, toolsCfg, ignore); reconcile.Adopt("configs/tools.yaml", pkg, true)
+// Design choices: Formulae compared via `brew leaves --installed-on-request` so dependencies aren't reported;
+//                 adoption inserts a tool block as text so comments and formatting in tools.yaml survive
+// Assumptions: Homebrew is the only package manager reconciled; tap-qualified names compare by their last element
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Package kinds
+const (
+	KindFormula = "formula"
+	KindCask    = "cask"
+)
+
+// Package is a Homebrew formula or cask
+type Package struct {
+	Name string
+	Kind string
+}
+
+// String formats the package as "formula foo" / "cask bar"
+func (p Package) String() string {
+	return p.Kind + " " + p.Name
+}
+
+// InstallCommand returns the brew command that installs the package
+func (p Package) InstallCommand() string {
+	if p.Kind == KindCask {
+		return "brew install --cask " + p.Name
+	}
+	return "brew install " + p.Name
+}
+
+// UninstallCommand returns the brew command that removes the package
+func (p Package) UninstallCommand() string {
+	if p.Kind == KindCask {
+		return "brew uninstall --cask " + p.Name
+	}
+	return "brew uninstall " + p.Name
+}
+
+// Result lists differences between the machine and tools.yaml
+type Result struct {
+	// Extra are installed on the machine but not declared (and not ignored)
+	Extra []Package
+
+	// Missing are declared in tools.yaml but not installed
+	Missing []Package
+}
+
+// Find compares installed Homebrew packages with tools.yaml
+// What: Lists formulae installed on request and all casks, compares with BrewPackages of every tool
+// Why: Main entry point for devsetup reconcile
+// Params: ctx - command timeouts, tools - tools configuration, ignore - per-user ignore list (may be nil)
+// Returns: Result sorted by kind then name, error if brew is unavailable or listing fails
+func Find(ctx context.Context, tools *config.ToolsConfig, ignore *Ignore) (*Result, error) {
+	if _, err := exec.LookPath("brew"); err != nil {
+		return nil, fmt.Errorf("homebrew not found on PATH")
+	}
+
+	formulas, err := brewOutput(ctx, "leaves", "--installed-on-request")
+	if err != nil {
+		return nil, err
+	}
+	casks, err := brewOutput(ctx, "list", "--cask", "-1")
+	if err != nil {
+		return nil, err
+	}
+
+	installed := map[string]map[string]bool{KindFormula: set(formulas), KindCask: set(casks)}
+	declared := Declared(tools)
+
+	result := &Result{}
+	for _, kind := range []string{KindFormula, KindCask} {
+		for _, name := range sortedKeys(installed[kind]) {
+			pkg := Package{Name: name, Kind: kind}
+			if !declared[kind][name] && !ignore.Contains(pkg) {
+				result.Extra = append(result.Extra, pkg)
+			}
+		}
+		for _, name := range sortedKeys(declared[kind]) {
+			if !installed[kind][name] {
+				result.Missing = append(result.Missing, Package{Name: name, Kind: kind})
+			}
+		}
+	}
+	return result, nil
+}
+
+// Declared returns the Homebrew packages tools.yaml installs
+// Params: tools - tools configuration
+// Returns: kind → set of package names (tap prefixes removed)
+func Declared(tools *config.ToolsConfig) map[string]map[string]bool {
+	declared := map[string]map[string]bool{KindFormula: {}, KindCask: {}}
+	for _, tool := range tools.Tools {
+		formulas, casks := tool.Install.BrewPackages()
+		for _, f := range formulas {
+			declared[KindFormula][path.Base(f)] = true
+		}
+		for _, c := range casks {
+			declared[KindCask][path.Base(c)] = true
+		}
+	}
+	return declared
+}
+
+// brewOutput runs a brew command and returns its whitespace-separated output
+func brewOutput(ctx context.Context, args ...string) ([]string, error) {
+	output, err := exec.CommandContext(ctx, "brew", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("brew %s failed: %w", strings.Join(args, " "), err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// Adopt appends a tool declaration for pkg to tools.yaml
+// What: Inserts a tool block at the end of the tools list, leaving the rest of the file untouched
+// Why: "Adopt into config" choice of devsetup reconcile
+// Params: toolsPath - path to tools.yaml, pkg - package to adopt, dependsOnHomebrew - add depends_on: [homebrew]
+// Returns: Error if the file can't be parsed or written, or a tool with the same name exists
+func Adopt(toolsPath string, pkg Package, dependsOnHomebrew bool) error {
+	data, err := os.ReadFile(toolsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", toolsPath, err)
+	}
+
+	var cfg config.ToolsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", toolsPath, err)
+	}
+	for _, tool := range cfg.Tools {
+		if tool.Name == pkg.Name {
+			return fmt.Errorf("tool %s already exists in %s", pkg.Name, toolsPath)
+		}
+	}
+
+	checkFlag := "--formula"
+	if pkg.Kind == KindCask {
+		checkFlag = "--cask"
+	}
+	block := []string{
+		"",
+		"  - name: " + pkg.Name,
+		fmt.Sprintf("    description: %q", "Adopted by devsetup reconcile"),
+		fmt.Sprintf("    check: brew list %s %s", checkFlag, pkg.Name),
+		"    install:",
+		"      command: " + pkg.InstallCommand(),
+		"      class: download",
+	}
+	if dependsOnHomebrew {
+		block = append(block, "    depends_on: [homebrew]")
+	}
+	block = append(block, "    required: false")
+
+	lines := strings.Split(string(data), "\n")
+	at, err := toolsListEnd(data, lines)
+	if err != nil {
+		return err
+	}
+
+	updated := append(append(append([]string{}, lines[:at]...), block...), lines[at:]...)
+	return os.WriteFile(toolsPath, []byte(strings.Join(updated, "\n")), 0644)
+}
+
+// toolsListEnd finds the line index after the last entry of the top-level tools list
+// What: Uses the next top-level key (if any) and backs up over blank and comment lines
+// Returns: Insertion index into lines
+func toolsListEnd(data []byte, lines []string) (int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return 0, fmt.Errorf("failed to parse tools config")
+	}
+	root := doc.Content[0]
+
+	end := len(lines)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "tools" {
+			continue
+		}
+		if i+2 < len(root.Content) {
+			end = root.Content[i+2].Line - 1
+		}
+		for end > 0 {
+			trimmed := strings.TrimSpace(lines[end-1])
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				break
+			}
+			end--
+		}
+		return end, nil
+	}
+	return 0, fmt.Errorf("tools config has no tools list")
+}
+
+// Ignore is the per-user list of packages reconcile should not report
+type Ignore struct {
+	Formulas []string `yaml:"formulas"`
+	Casks    []string `yaml:"casks"`
+}
+
+// IgnorePath returns the ignore list location
+// Returns: ~/.config/devsetup/reconcile-ignore.yaml
+func IgnorePath() string {
+	return filepath.Join(config.GetUserConfigDir(), "reconcile-ignore.yaml")
+}
+
+// LoadIgnore reads the ignore list
+// Returns: Ignore list (empty if the file doesn't exist)
+func LoadIgnore() (*Ignore, error) {
+	ignore := &Ignore{}
+	data, err := os.ReadFile(IgnorePath())
+	if os.IsNotExist(err) {
+		return ignore, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, ignore); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", IgnorePath(), err)
+	}
+	return ignore, nil
+}
+
+// Contains reports whether pkg is ignored (nil list ignores nothing)
+func (ig *Ignore) Contains(pkg Package) bool {
+	if ig == nil {
+		return false
+	}
+	names := ig.Formulas
+	if pkg.Kind == KindCask {
+		names = ig.Casks
+	}
+	for _, name := range names {
+		if name == pkg.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Add ignores pkg and saves the list
+// Params: pkg - package to ignore
+// Returns: Error if the file can't be written
+func (ig *Ignore) Add(pkg Package) error {
+	if ig.Contains(pkg) {
+		return nil
+	}
+	if pkg.Kind == KindCask {
+		ig.Casks = append(ig.Casks, pkg.Name)
+		sort.Strings(ig.Casks)
+	} else {
+		ig.Formulas = append(ig.Formulas, pkg.Name)
+		sort.Strings(ig.Formulas)
+	}
+
+	data, err := yaml.Marshal(ig)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(IgnorePath()), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(IgnorePath(), append([]byte("# Packages devsetup reconcile should not report\n"), data...), 0644)
+}
+
+// set builds a lookup set from names
+func set(names []string) map[string]bool {
+	s := make(map[string]bool, len(names))
+	for _, name := range names {
+		s[name] = true
+	}
+	return s
+}
+
+// sortedKeys returns set members in sorted order
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
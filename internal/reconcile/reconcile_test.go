@@ -0,0 +1,62 @@
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAdoptInsertsAtEndOfToolsList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	original := `tools:
+  # Core
+  - name: git
+    check: command -v git
+    install:
+      command: brew install git
+
+# Scheduler limits
+concurrency:
+  download: 2
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Adopt(path, Package{Name: "jq", Kind: KindFormula}, false); err != nil {
+		t.Fatalf("Adopt: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	var cfg config.ToolsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("adopted file no longer parses: %v\n%s", err, data)
+	}
+	if len(cfg.Tools) != 2 || cfg.Tools[1].Name != "jq" || cfg.Tools[1].Install.Command != "brew install jq" {
+		t.Errorf("unexpected tools after adopt: %+v", cfg.Tools)
+	}
+	if cfg.Concurrency["download"] != 2 {
+		t.Error("concurrency block was disturbed")
+	}
+	if !strings.Contains(string(data), "  # Core\n") || !strings.Contains(string(data), "# Scheduler limits\nconcurrency:") {
+		t.Errorf("comments not preserved:\n%s", data)
+	}
+
+	if err := Adopt(path, Package{Name: "jq", Kind: KindFormula}, false); err == nil {
+		t.Error("adopting an existing tool should fail")
+	}
+}
+
+func TestDeclaredStripsTapPrefix(t *testing.T) {
+	tools := &config.ToolsConfig{Tools: []config.Tool{
+		{Name: "x", Install: config.ToolInstall{Command: "brew install owner/tap/tool && brew install --cask zed"}},
+	}}
+	declared := Declared(tools)
+	if !declared[KindFormula]["tool"] || !declared[KindCask]["zed"] {
+		t.Errorf("Declared = %v", declared)
+	}
+}
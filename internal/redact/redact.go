@@ -0,0 +1,102 @@
+// File: internal/redact/redact.go
+// Purpose: Masks secrets in everything devsetup prints or writes
+// Problem: API keys entered at prompts (or found in the environment) could show up in command echo and output
+// Role: Process-wide registry of secret values plus a set of common token patterns, applied to UI and command output
+// Usage: redact.Register(value); safe := redact.String(s); cmd.Stdout = redact.NewWriter(os.Stdout)
+// Design choices: Global registry (secrets come from many packages); per-Write redaction so interactive prompts
+//                 without a trailing newline still appear immediately
+// Assumptions: Secrets are not split across separate Write calls (output is line- or message-oriented)
+
+package redact
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces redacted content
+const Mask = "********"
+
+// minSecretLength avoids masking short, common strings (e.g. "y", "yes")
+const minSecretLength = 6
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// patterns match well-known token formats even when never registered
+var patterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{20,}\b`), // GitHub tokens
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{20,}\b`),           // GitHub fine-grained tokens
+	regexp.MustCompile(`\bsk-(ant-)?[A-Za-z0-9_\-]{20,}`),            // Anthropic / OpenAI keys
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),                 // Google API keys
+	regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),                // AWS access key IDs
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9\-]{10,}`),            // Slack tokens
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`),              // GitLab tokens
+	regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9._~+/\-]{16,}=*`), // Authorization headers
+}
+
+// Register adds a secret value to mask
+// What: Stores the value; later output containing it is masked
+// Why: Prompted API keys and keychain values have no recognizable format
+// Params: value - secret (ignored if shorter than 6 characters)
+// Example: redact.Register(apiKey)
+func Register(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, s := range secrets {
+		if s == value {
+			return
+		}
+	}
+	secrets = append(secrets, value)
+	// Longest first so a secret containing another is masked whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// String masks registered secrets and known token patterns
+// Params: s - text to redact
+// Returns: Text with secrets replaced by Mask
+// Example: redact.String("key=ghp_abc...") → "key=********"
+func String(s string) string {
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	mu.RUnlock()
+
+	for _, p := range patterns {
+		s = p.ReplaceAllString(s, Mask)
+	}
+	return s
+}
+
+// writer redacts each Write before passing it on
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter wraps w so everything written through it is redacted
+// Params: w - underlying writer (e.g. os.Stdout)
+// Returns: Redacting writer
+// Example: cmd.Stdout = redact.NewWriter(os.Stdout)
+func NewWriter(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+// Write redacts p and writes it; reports len(p) so callers don't see short writes when masking changes length
+func (rw *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,190 @@
+// File: internal/remotecache/remotecache.go
+// Purpose: Remote build cache configuration and health checks for monorepo build tools (Bazel, Nx, Turborepo)
+// Problem: A wrong cache URL or an expired token doesn't fail a build, it silently turns every build into a cold one,
+//          and nobody notices until someone compares build times
+// Role: Pings a cache with a token (telling auth failures apart from unreachable servers), and renders the config
+//       each tool reads: a ~/.bazelrc block with a keychain-backed credential helper, or env exports for ~/.zshrc
+// Usage: err := remotecache.Ping(ctx, rc, token); lines := remotecache.Lines(rc); file := remotecache.File(rc)
+// Design choices: Tokens never land in dotfiles; rendered config reads them from the keychain when the tool runs
+//                 (Bazel's credential helper protocol, $(security ...) in the shell exports); a cache miss (404)
+//                 counts as healthy because it proves the server accepted the token
+// Assumptions: Bazel 7.1+ (--credential_helper); Nx self-hosted caches implement the Nx remote cache OpenAPI spec;
+//              gRPC Bazel caches are only checked for reachability (no gRPC client in the build)
+
+package remotecache
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/keychain"
+)
+
+// ErrUnauthorized is returned by Ping when the cache rejects the token
+var ErrUnauthorized = errors.New("the cache rejected the token")
+
+// missingKey is a cache key that is never stored (all zeros), so probing it reads nothing real
+const missingKey = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Ping checks the cache accepts a token
+// What: bazel http(s) - GET /ac/<key>; turbo - GET /v8/artifacts/status; nx - GET /v1/cache/<key>;
+// bazel grpc(s) - TCP (and TLS) connect only
+// Params: ctx - bounds the request, rc - cache definition, token - bearer token
+// Returns: nil when the cache answered (a miss included), ErrUnauthorized on 401/403, other errors otherwise
+func Ping(ctx context.Context, rc *config.RemoteCacheConfig, token string) error {
+	endpoint := rc.Endpoint()
+	var probe string
+	switch rc.Tool {
+	case config.BuildToolBazel:
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "grpc" || u.Scheme == "grpcs" {
+			return dial(ctx, u)
+		}
+		probe = endpoint + "/ac/" + missingKey
+	case config.BuildToolTurbo:
+		team := "slug"
+		if strings.HasPrefix(rc.Team, "team_") {
+			team = "teamId"
+		}
+		probe = endpoint + "/v8/artifacts/status?" + url.Values{team: {rc.Team}}.Encode()
+	case config.BuildToolNx:
+		probe = endpoint + "/v1/cache/" + missingKey
+	default:
+		return fmt.Errorf("unknown build tool %q", rc.Tool)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("cache unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.StatusCode == http.StatusNotFound && rc.Tool != config.BuildToolTurbo:
+		return nil
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("cache answered %s", resp.Status)
+	}
+	if rc.Tool == config.BuildToolTurbo {
+		// Vercel answers 200 for teams that turned remote caching off; turbo then skips the cache without a word
+		var status struct {
+			Status string `json:"status"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&status) == nil && status.Status != "" && status.Status != "enabled" {
+			return fmt.Errorf("remote caching is %s for team %s", status.Status, rc.Team)
+		}
+	}
+	return nil
+}
+
+// Check pings a cache with the token stored in the keychain
+// Returns: Error if the token is missing from the keychain or Ping fails
+func Check(ctx context.Context, rc *config.RemoteCacheConfig) error {
+	token, err := keychain.Get(rc.KeychainService())
+	if err != nil {
+		return fmt.Errorf("%s token: %w", rc.Tool, err)
+	}
+	return Ping(ctx, rc, token)
+}
+
+// dial checks a gRPC cache accepts connections
+func dial(ctx context.Context, u *url.URL) error {
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return fmt.Errorf("cache unreachable: %w", err)
+	}
+	defer conn.Close()
+	if u.Scheme == "grpcs" {
+		tc := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("TLS handshake with %s failed: %w", u.Host, err)
+		}
+	}
+	return nil
+}
+
+// File returns the dotfile holding a tool's cache config
+// Returns: "~/.bazelrc" for bazel, "~/.zshrc" for nx and turbo (read from the environment)
+func File(rc *config.RemoteCacheConfig) string {
+	if rc.Tool == config.BuildToolBazel {
+		return "~/.bazelrc"
+	}
+	return "~/.zshrc"
+}
+
+// BlockName names the managed block in File (see dotfiles.ReplaceBlock)
+// Example: BlockName(rc) → "remote_cache_turbo"
+func BlockName(rc *config.RemoteCacheConfig) string {
+	return "remote_cache_" + rc.Tool
+}
+
+// HelperPath returns where the Bazel credential helper for a keychain item is written
+// Example: HelperPath("devsetup.bazel-remote-cache") → "~/.local/share/devsetup/bin/devsetup.bazel-remote-cache-credential-helper"
+func HelperPath(service string) string {
+	return filepath.Join(config.GetStateDir(), "bin", service+"-credential-helper")
+}
+
+// HelperScript returns a Bazel credential helper printing the keychain token as a bearer header
+// What: Bazel runs `<helper> get` with the request on stdin and reads {"headers": {...}} from stdout
+func HelperScript(service string) string {
+	return `#!/bin/sh
+# Written by devsetup: Bazel credential helper for the remote cache (token from the login keychain)
+cat >/dev/null
+token=` + keychain.ShellRead(service) + ` || exit 1
+[ -n "$token" ] || { echo "remote cache token missing from the keychain (` + service + `); run devsetup setup" >&2; exit 1; }
+printf '{"headers":{"Authorization":["Bearer %s"]}}\n' "$token"
+`
+}
+
+// Lines returns the body of the managed block in File
+// Example: turbo with team acme → export TURBO_TEAM='acme', export TURBO_TOKEN="$(security find-generic-password ...)"
+func Lines(rc *config.RemoteCacheConfig) []string {
+	service := rc.KeychainService()
+	switch rc.Tool {
+	case config.BuildToolBazel:
+		lines := []string{"build --remote_cache=" + rc.Endpoint()}
+		if u, err := url.Parse(rc.Endpoint()); err == nil {
+			lines = append(lines, "build --credential_helper="+u.Hostname()+"="+HelperPath(service))
+		}
+		return lines
+	case config.BuildToolTurbo:
+		var lines []string
+		if rc.URL != "" {
+			lines = append(lines, "export TURBO_API="+config.ShellQuote(rc.Endpoint()))
+		}
+		return append(lines,
+			"export TURBO_TEAM="+config.ShellQuote(rc.Team),
+			`export TURBO_TOKEN="`+keychain.ShellRead(service)+`"`)
+	case config.BuildToolNx:
+		return []string{
+			"export NX_SELF_HOSTED_REMOTE_CACHE_SERVER=" + config.ShellQuote(rc.Endpoint()),
+			`export NX_SELF_HOSTED_REMOTE_CACHE_ACCESS_TOKEN="` + keychain.ShellRead(service) + `"`,
+		}
+	}
+	return nil
+}
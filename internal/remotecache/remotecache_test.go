@@ -0,0 +1,89 @@
+package remotecache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestPing(t *testing.T) {
+	var status int
+	var body, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.RequestURI()
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	// A miss proves the token was accepted
+	bazel := &config.RemoteCacheConfig{Tool: config.BuildToolBazel, URL: srv.URL + "/"}
+	status = http.StatusNotFound
+	if err := Ping(ctx, bazel, "good"); err != nil || path != "/ac/"+missingKey {
+		t.Errorf("bazel miss: %v (path %s)", err, path)
+	}
+	if err := Ping(ctx, bazel, "bad"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("bazel bad token: %v, want ErrUnauthorized", err)
+	}
+	status = http.StatusBadGateway
+	if err := Ping(ctx, bazel, "good"); err == nil || errors.Is(err, ErrUnauthorized) {
+		t.Errorf("bazel 502: %v, want a server error", err)
+	}
+
+	nx := &config.RemoteCacheConfig{Tool: config.BuildToolNx, URL: srv.URL}
+	status = http.StatusNotFound
+	if err := Ping(ctx, nx, "good"); err != nil || path != "/v1/cache/"+missingKey {
+		t.Errorf("nx miss: %v (path %s)", err, path)
+	}
+
+	turbo := &config.RemoteCacheConfig{Tool: config.BuildToolTurbo, URL: srv.URL, Team: "team_abc"}
+	status, body = http.StatusOK, `{"status":"enabled"}`
+	if err := Ping(ctx, turbo, "good"); err != nil || path != "/v8/artifacts/status?teamId=team_abc" {
+		t.Errorf("turbo enabled: %v (path %s)", err, path)
+	}
+	body = `{"status":"disabled"}`
+	if err := Ping(ctx, turbo, "good"); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("turbo disabled: %v", err)
+	}
+	status = http.StatusNotFound
+	if err := Ping(ctx, turbo, "good"); err == nil {
+		t.Error("turbo 404 should fail (wrong API URL)")
+	}
+}
+
+func TestLines(t *testing.T) {
+	t.Setenv("HOME", "/Users/dev")
+	turbo := Lines(&config.RemoteCacheConfig{Tool: config.BuildToolTurbo, Team: "acme"})
+	want := []string{
+		"export TURBO_TEAM='acme'",
+		`export TURBO_TOKEN="$(security find-generic-password -s 'devsetup.turbo-remote-cache' -w 2>/dev/null)"`,
+	}
+	if strings.Join(turbo, "\n") != strings.Join(want, "\n") {
+		t.Errorf("turbo Lines =\n%s\nwant\n%s", strings.Join(turbo, "\n"), strings.Join(want, "\n"))
+	}
+
+	bazel := &config.RemoteCacheConfig{Tool: config.BuildToolBazel, URL: "grpcs://cache.example.com:443", Keychain: "corp.bazel"}
+	want = []string{
+		"build --remote_cache=grpcs://cache.example.com:443",
+		"build --credential_helper=cache.example.com=/Users/dev/.local/share/devsetup/bin/corp.bazel-credential-helper",
+	}
+	if got := Lines(bazel); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bazel Lines = %v, want %v", got, want)
+	}
+	if File(bazel) != "~/.bazelrc" || BlockName(bazel) != "remote_cache_bazel" {
+		t.Errorf("bazel config goes in a remote_cache_bazel block of ~/.bazelrc, got %s in %s", BlockName(bazel), File(bazel))
+	}
+	if !strings.Contains(HelperScript("corp.bazel"), `-s 'corp.bazel'`) {
+		t.Error("credential helper should read the task's keychain item")
+	}
+}
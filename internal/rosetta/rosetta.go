@@ -0,0 +1,102 @@
+// File: internal/rosetta/rosetta.go
+// Purpose: Rosetta 2 detection and install on Apple Silicon, and finding binaries that still run under it
+// Problem: Intel-only tools failed on fresh Apple Silicon Macs with "Bad CPU type in executable" until someone
+//          knew to install Rosetta by hand, and nobody could tell which installed tools were still translated
+// Role: Reports whether this Mac needs Rosetta for a set of x86-only tools, installs it with
+//       `softwareupdate --install-rosetta --agree-to-license`, and classifies binaries by architecture
+// Usage: if rosetta.Missing(ctx) { err := rosetta.Install(ctx) }; arch := rosetta.BinaryArch(ctx, path)
+// Design choices: Availability is probed by running /usr/bin/true as x86_64 rather than looking for Rosetta's
+//                 files, whose location has moved between macOS releases; architectures come from `file -bL`,
+//                 which ships with macOS (lipo needs the Command Line Tools)
+// Assumptions: macOS; the caller has the user's consent before Install (it runs sudo and accepts Apple's license)
+
+package rosetta
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// Binary architectures reported by BinaryArch
+const (
+	ArchNative    = "arm64"
+	ArchIntel     = "x86_64"
+	ArchUniversal = "universal"
+	ArchUnknown   = ""
+)
+
+// AppleSilicon reports whether this Mac has an Apple Silicon CPU
+// Edge cases: True for a devsetup binary that itself runs translated (an amd64 build on Apple Silicon)
+func AppleSilicon(ctx context.Context) bool {
+	if runtime.GOOS != "darwin" {
+		return false
+	}
+	if runtime.GOARCH == "arm64" {
+		return true
+	}
+	out, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.optional.arm64").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "1"
+}
+
+// Missing reports whether this is an Apple Silicon Mac without Rosetta 2
+func Missing(ctx context.Context) bool {
+	if !AppleSilicon(ctx) {
+		return false
+	}
+	return exec.CommandContext(ctx, "/usr/bin/arch", "-x86_64", "/usr/bin/true").Run() != nil
+}
+
+// Install installs Rosetta 2 non-interactively
+// What: Runs `sudo softwareupdate --install-rosetta --agree-to-license` on the terminal
+// Why: Intel-only tools need it before they can be installed or run
+// Params: ctx - bounds the install (softwareupdate downloads from Apple)
+// Returns: Error if softwareupdate fails or Rosetta still isn't usable afterwards
+func Install(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "sudo", "softwareupdate", "--install-rosetta", "--agree-to-license")
+	cmd.Stdin = prompt.Stdin()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("softwareupdate --install-rosetta failed: %w", err)
+	}
+	if Missing(ctx) {
+		return fmt.Errorf("Rosetta 2 is still unavailable after softwareupdate")
+	}
+	return nil
+}
+
+// BinaryArch reports which architectures an executable contains
+// Params: ctx - bounds the probe, path - executable (symlinks are followed)
+// Returns: ArchNative, ArchIntel, ArchUniversal, or ArchUnknown (not Mach-O, e.g. a script, or unreadable)
+func BinaryArch(ctx context.Context, path string) string {
+	out, err := exec.CommandContext(ctx, "file", "-bL", path).Output()
+	if err != nil {
+		return ArchUnknown
+	}
+	return parseFileArch(string(out))
+}
+
+// parseFileArch classifies `file -b` output
+// Example: "Mach-O 64-bit executable x86_64" → ArchIntel
+func parseFileArch(output string) string {
+	if !strings.Contains(output, "Mach-O") {
+		return ArchUnknown
+	}
+	arm := strings.Contains(output, "arm64")
+	intel := strings.Contains(output, "x86_64")
+	switch {
+	case arm && intel:
+		return ArchUniversal
+	case arm:
+		return ArchNative
+	case intel:
+		return ArchIntel
+	}
+	return ArchUnknown
+}
@@ -0,0 +1,17 @@
+package rosetta
+
+import "testing"
+
+func TestParseFileArch(t *testing.T) {
+	cases := map[string]string{
+		"Mach-O 64-bit executable x86_64": ArchIntel,
+		"Mach-O 64-bit executable arm64":  ArchNative,
+		"Mach-O universal binary with 2 architectures: [x86_64:Mach-O 64-bit executable x86_64] [arm64]": ArchUniversal,
+		"POSIX shell script text executable, ASCII text":                                                 ArchUnknown,
+	}
+	for output, want := range cases {
+		if got := parseFileArch(output); got != want {
+			t.Errorf("parseFileArch(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+// File: internal/runlog/runlog.go
+// Purpose: Live record of the install that is running now, so other processes can wait for it
+// Problem: An install started elsewhere (another terminal, the local API, a provisioning script) gave CI and
+//          scripted flows nothing to block on; a second install started meanwhile raced the first on state.json
+// Role: The running install writes its progress as NDJSON events plus a small run file (pid, start, result);
+//       devsetup wait and install --wait replay the events live and return the run's result
+// Usage: rec, err := runlog.Begin("install", progressUI); toolInstaller := installer.NewToolInstaller(cfg, state, rec, ...);
+//        rec.End(err). Elsewhere: run, _ := runlog.Current(); if run.Running() { final, err := runlog.Follow(ctx, run, fn) }
+// Design choices: Recorder decorates ui.UI like metrics.Recorder, so the installer is unchanged; events use the
+//                 API's ui.Event schema (already redacted); followers poll files instead of connecting to the
+//                 running process, so they work across terminals and after the runner's terminal is closed
+// Assumptions: One install per user at a time (Begin refuses a second); a run whose process is gone without
+//              finishing crashed or was killed
+
+package runlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/installer"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+// pollInterval is how often followers look for new events
+const pollInterval = 500 * time.Millisecond
+
+// Run describes the latest recorded install
+type Run struct {
+	// PID is the process running the install
+	PID int `json:"pid"`
+
+	// Command is what started it (install, apply, api)
+	Command string `json:"command"`
+
+	// Started is when the run began
+	Started time.Time `json:"started"`
+
+	// Finished is when the run ended (zero while running)
+	Finished time.Time `json:"finished,omitzero"`
+
+	// OK is whether the run succeeded (meaningful once finished)
+	OK bool `json:"ok"`
+
+	// Error is the error the run ended with
+	Error string `json:"error,omitempty"`
+
+	// Tasks are the results of the tools the run got to (set when it finishes)
+	Tasks []installer.TaskResult `json:"tasks,omitempty"`
+}
+
+// BusyError is returned by Begin while another install is running
+type BusyError struct {
+	Run *Run
+}
+
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("another install is running (%s, pid %d, started %s)", e.Run.Command, e.Run.PID, e.Run.Started.Local().Format("15:04:05"))
+}
+
+// Dir returns where the current run is recorded
+// Returns: <state dir>/run
+func Dir() string {
+	return filepath.Join(config.GetStateDir(), "run")
+}
+
+// runPath returns the run file location
+func runPath() string {
+	return filepath.Join(Dir(), "run.json")
+}
+
+// EventsPath returns the current run's event log
+// Returns: <state dir>/run/events.ndjson
+func EventsPath() string {
+	return filepath.Join(Dir(), "events.ndjson")
+}
+
+// Current loads the latest recorded run
+// Returns: Run (nil if no install was ever recorded), error if the file is unreadable
+func Current() (*Run, error) {
+	data, err := os.ReadFile(runPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run file: %w", err)
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse run file: %w", err)
+	}
+	return &run, nil
+}
+
+// Running reports whether the run is still in progress
+// Edge cases: A run whose process no longer exists is not running, even though it never finished
+func (r *Run) Running() bool {
+	return r != nil && r.Finished.IsZero() && alive(r.PID)
+}
+
+// Recorder is a ui.UI that also records every call as an event of the current run
+// What: Passes each call to the wrapped UI and appends it to events.ndjson
+// Why: Followers see the same progress as the terminal that runs the install
+type Recorder struct {
+	ui.UI
+
+	events *ui.EventUI
+	file   *os.File
+	run    Run
+}
+
+// Begin records the start of an install
+// What: Refuses if another install is running, then starts a fresh event log and run file
+// Why: Makes the run visible to devsetup wait and keeps two installs from racing on state
+// Params: command - what started the run (shown to followers), inner - UI that still receives every call
+// Returns: Recorder to pass wherever inner would go; *BusyError if another install is running
+func Begin(command string, inner ui.UI) (*Recorder, error) {
+	current, err := Current()
+	if err == nil && current.Running() && current.PID != os.Getpid() {
+		return nil, &BusyError{Run: current}
+	}
+
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	file, err := os.Create(EventsPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event log: %w", err)
+	}
+	rec := &Recorder{
+		UI:     inner,
+		events: ui.NewEventUI(file, nil),
+		file:   file,
+		run:    Run{PID: os.Getpid(), Command: command, Started: time.Now().UTC()},
+	}
+	if err := writeRun(&rec.run); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return rec, nil
+}
+
+// End records the run's result and closes the event log
+// Params: tasks - per-tool results (InstallAll's), runErr - error the install ended with (nil = success)
+// Edge cases: Safe on a nil Recorder; failures to write are ignored (the install's own result matters more)
+func (r *Recorder) End(tasks []installer.TaskResult, runErr error) {
+	if r == nil {
+		return
+	}
+	r.run.OK = runErr == nil
+	r.run.Finished = time.Now().UTC()
+	r.run.Tasks = tasks
+	done := ui.Event{Type: ui.EventDone, OK: &r.run.OK}
+	if runErr != nil {
+		r.run.Error = runErr.Error()
+		done.Message = r.run.Error
+	}
+	r.events.Emit(done)
+	_ = r.file.Close()
+	_ = writeRun(&r.run)
+}
+
+// PrintBanner passes through; banners are not recorded
+func (r *Recorder) PrintBanner() { r.UI.PrintBanner() }
+
+// StartStage passes through and records a stage event
+func (r *Recorder) StartStage(name, estimatedTime string) {
+	r.UI.StartStage(name, estimatedTime)
+	r.events.StartStage(name, estimatedTime)
+}
+
+// StartTask passes through and records a task_start event
+func (r *Recorder) StartTask(taskName string) {
+	r.UI.StartTask(taskName)
+	r.events.StartTask(taskName)
+}
+
+// CompleteTask passes through and records a task_complete event
+func (r *Recorder) CompleteTask(taskName string) {
+	r.UI.CompleteTask(taskName)
+	r.events.CompleteTask(taskName)
+}
+
+// FailTask passes through and records a task_failed event
+func (r *Recorder) FailTask(taskName string, err error) {
+	r.UI.FailTask(taskName, err)
+	r.events.FailTask(taskName, err)
+}
+
+// CancelTask passes through and records a task_cancelled event
+func (r *Recorder) CancelTask(taskName string) {
+	r.UI.CancelTask(taskName)
+	r.events.CancelTask(taskName)
+}
+
+// Success passes through and records the message
+func (r *Recorder) Success(format string, args ...interface{}) {
+	r.UI.Success(format, args...)
+	r.events.Success(format, args...)
+}
+
+// Error passes through and records the message
+func (r *Recorder) Error(format string, args ...interface{}) {
+	r.UI.Error(format, args...)
+	r.events.Error(format, args...)
+}
+
+// Warning passes through and records the message
+func (r *Recorder) Warning(format string, args ...interface{}) {
+	r.UI.Warning(format, args...)
+	r.events.Warning(format, args...)
+}
+
+// Info passes through and records the message
+func (r *Recorder) Info(format string, args ...interface{}) {
+	r.UI.Info(format, args...)
+	r.events.Info(format, args...)
+}
+
+// Diff passes through and records a diff event
+func (r *Recorder) Diff(diff string) {
+	r.UI.Diff(diff)
+	r.events.Diff(diff)
+}
+
+// PrintProgress passes through and records a progress event
+func (r *Recorder) PrintProgress(current, total int, label string) {
+	r.UI.PrintProgress(current, total, label)
+	r.events.PrintProgress(current, total, label)
+}
+
+// Follow replays a run's events from the start and keeps following until the run ends
+// What: Calls fn for every event (the final done event included), polling for new ones
+// Why: Backs devsetup wait and install --wait
+// Params: ctx - stops following (e.g. a --timeout), run - run to follow (from Current), fn - called per event
+// Returns: The finished run; error if ctx ends first, the process exits without finishing, or a newer run replaces it
+func Follow(ctx context.Context, run *Run, fn func(ui.Event)) (*Run, error) {
+	file, err := os.Open(EventsPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var pending string
+	// drain reads every complete line written so far, keeping a partial last line for the next poll
+	drain := func() error {
+		for {
+			line, err := reader.ReadString('\n')
+			pending += line
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read event log: %w", err)
+			}
+			var event ui.Event
+			if json.Unmarshal([]byte(pending), &event) == nil {
+				fn(event)
+			}
+			pending = ""
+		}
+	}
+
+	for {
+		if err := drain(); err != nil {
+			return nil, err
+		}
+
+		latest, err := Current()
+		switch {
+		case err != nil:
+			return nil, err
+		case latest == nil || latest.PID != run.PID || !latest.Started.Equal(run.Started):
+			return latest, fmt.Errorf("the install (pid %d) was replaced by a newer run", run.PID)
+		case !latest.Finished.IsZero():
+			// End writes the last events before the run file, so one more pass reads them all
+			return latest, drain()
+		case !alive(latest.PID):
+			return latest, fmt.Errorf("the install (pid %d) exited without finishing", latest.PID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return latest, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Render shows a recorded event on a UI, as the running install showed it
+// Edge cases: The done event is not rendered; callers report the run's result themselves
+func Render(u ui.UI, event ui.Event) {
+	switch event.Type {
+	case ui.EventStage:
+		u.StartStage(event.Name, event.Message)
+	case ui.EventTaskStart:
+		u.StartTask(event.Name)
+	case ui.EventTaskDone:
+		u.CompleteTask(event.Name)
+	case ui.EventTaskFail:
+		u.FailTask(event.Name, errors.New(event.Message))
+	case ui.EventTaskStop:
+		u.CancelTask(event.Name)
+	case ui.EventProgress:
+		u.PrintProgress(event.Current, event.Total, event.Name)
+	case ui.EventDiff:
+		u.Diff(event.Message)
+	case ui.EventMessage:
+		switch event.Level {
+		case "success":
+			u.Success("%s", event.Message)
+		case "error":
+			u.Error("%s", event.Message)
+		case "warning":
+			u.Warning("%s", event.Message)
+		default:
+			u.Info("%s", event.Message)
+		}
+	}
+}
+
+// writeRun replaces the run file atomically, so followers never read a partial file
+func writeRun(run *Run) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize run: %w", err)
+	}
+	tmp := runPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run file: %w", err)
+	}
+	if err := os.Rename(tmp, runPath()); err != nil {
+		return fmt.Errorf("failed to write run file: %w", err)
+	}
+	return nil
+}
+
+// alive reports whether a process exists (EPERM means it exists but belongs to someone else)
+func alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
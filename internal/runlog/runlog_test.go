@@ -0,0 +1,120 @@
+package runlog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/installer"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+// nopUI discards every call
+type nopUI struct{ ui.UI }
+
+func (nopUI) StartTask(string)               {}
+func (nopUI) CompleteTask(string)            {}
+func (nopUI) FailTask(string, error)         {}
+func (nopUI) Info(string, ...interface{})    {}
+func (nopUI) Warning(string, ...interface{}) {}
+func (nopUI) PrintProgress(int, int, string) {}
+func (nopUI) Success(string, ...interface{}) {}
+func (nopUI) Error(string, ...interface{})   {}
+func (nopUI) CancelTask(string)              {}
+func (nopUI) StartStage(string, string)      {}
+
+func TestBeginFollowEnd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if run, err := Current(); err != nil || run != nil {
+		t.Fatalf("Current() before any run = %v, %v", run, err)
+	}
+
+	rec, err := Begin("install", nopUI{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, _ := Current()
+	if !run.Running() || run.Command != "install" {
+		t.Fatalf("Current() = %+v, want running install", run)
+	}
+
+	rec.StartTask("git")
+	rec.CompleteTask("git")
+	rec.Info("Stage %d/%d", 2, 3)
+
+	// Finish while a follower is waiting
+	go func() {
+		time.Sleep(2 * pollInterval)
+		rec.FailTask("node", errors.New("exit status 1"))
+		rec.End([]installer.TaskResult{{Name: "node", Stage: 1, Outcome: installer.OutcomeFailed, Required: true}}, errors.New("required tool node failed"))
+	}()
+
+	var types []string
+	final, err := Follow(context.Background(), run, func(e ui.Event) { types = append(types, e.Type) })
+	if err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	want := []string{ui.EventTaskStart, ui.EventTaskDone, ui.EventMessage, ui.EventTaskFail, ui.EventDone}
+	if len(types) != len(want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("event %d = %s, want %s", i, types[i], want[i])
+		}
+	}
+	if final.OK || final.Error != "required tool node failed" || final.Finished.IsZero() || final.Running() || len(final.Tasks) != 1 {
+		t.Errorf("final run = %+v", final)
+	}
+}
+
+func TestBeginRefusesWhileRunning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// A run by another live process: this test's parent stands in for it
+	if err := writeRunForTest(&Run{PID: 1, Command: "api", Started: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	var busy *BusyError
+	if _, err := Begin("install", nopUI{}); !errors.As(err, &busy) || busy.Run.PID != 1 {
+		t.Fatalf("Begin() error = %v, want BusyError for pid 1", err)
+	}
+
+	// A run whose process is gone doesn't block
+	if err := writeRunForTest(&Run{PID: 1 << 30, Command: "install", Started: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := Begin("install", nopUI{})
+	if err != nil {
+		t.Fatalf("Begin() after a crashed run: %v", err)
+	}
+	rec.End(nil, nil)
+}
+
+func TestFollowCrashedRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	rec, err := Begin("install", nopUI{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rec.file.Close()
+	crashed := &Run{PID: 1 << 30, Command: "install", Started: time.Now().UTC()}
+	if err := writeRunForTest(crashed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Follow(context.Background(), crashed, func(ui.Event) {}); err == nil {
+		t.Error("Follow() of a crashed run succeeded, want error")
+	}
+}
+
+// writeRunForTest records a run as if another process had started it
+func writeRunForTest(run *Run) error {
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return err
+	}
+	return writeRun(run)
+}
@@ -0,0 +1,188 @@
+// File: internal/selfcheck/selfcheck.go
+// Purpose: Integrity checks of devsetup itself (devsetup self check)
+// Problem: Support triage started by guessing whether the tool or the machine was broken: a corrupted or
+//          hand-built binary, an unreadable state file, or a read-only state directory all fail in confusing ways
+// Role: Verifies the binary against its release checksum, the embedded configs, state file readability, and that
+//       devsetup's directories are writable
+// Usage: results := selfcheck.Run(version); selfcheck.Failed(results)
+// Design choices: Checks that can't run (dev builds, offline) are warnings, not failures, so the command stays
+//                 useful on a plane; writability is tested by creating a file, not by inspecting mode bits
+// Assumptions: Release binaries carry a vX.Y.Z[+sha] version; state files are JSON
+
+package selfcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/updater"
+)
+
+// Result statuses
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusError   = "error"
+)
+
+// Result is the outcome of one self check
+type Result struct {
+	// Name identifies the check (e.g. "binary", "embedded tools.yaml")
+	Name string
+
+	// Status is ok, warning, or error
+	Status string
+
+	// Detail describes what was found
+	Detail string
+}
+
+// Run performs every self check
+// What: Binary checksum, embedded configs, state files, writable directories
+// Why: First triage step for support: rules out devsetup itself before debugging the machine
+// Params: version - version of the running binary
+// Returns: Results in check order
+func Run(version string) []Result {
+	results := []Result{checkBinary(version)}
+	results = append(results, checkEmbedded()...)
+	results = append(results, checkConfigDrift("configs"))
+	results = append(results, checkStateFiles(config.GetStateDir())...)
+	results = append(results,
+		checkWritable("state dir", config.GetStateDir()),
+		checkWritable("log dir", filepath.Join(config.GetStateDir(), "logs")),
+		checkWritable("download cache", filepath.Join(config.GetStateDir(), "downloads")),
+		checkWritable("config dir", config.GetUserConfigDir()),
+	)
+	return results
+}
+
+// Failed reports whether any result is an error
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusError {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBinary compares the running executable with the released checksum
+// Edge cases: Dev builds and lookup failures (offline, no release) are warnings
+func checkBinary(version string) Result {
+	r := Result{Name: "binary"}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		r.Status, r.Detail = StatusError, fmt.Sprintf("cannot locate executable: %v", err)
+		return r
+	}
+
+	expected, err := updater.NewUpdater(version).ReleaseChecksum()
+	if err != nil {
+		r.Status, r.Detail = StatusWarning, fmt.Sprintf("%s not verified: %v", exe, err)
+		return r
+	}
+	if err := updater.VerifyChecksum(exe, expected); err != nil {
+		r.Status, r.Detail = StatusError, fmt.Sprintf("%s does not match release %s: %v (reinstall devsetup)", exe, version, err)
+		return r
+	}
+	r.Status, r.Detail = StatusOK, fmt.Sprintf("%s matches release %s", exe, version)
+	return r
+}
+
+// checkEmbedded validates the configs compiled into the binary
+// Returns: One result per embedded config, sorted by name
+func checkEmbedded() []Result {
+	checks := config.CheckEmbedded()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []Result
+	for _, name := range names {
+		r := Result{Name: "embedded " + name, Status: StatusOK, Detail: "parses and validates"}
+		if err := checks[name]; err != nil {
+			r.Status, r.Detail = StatusError, err.Error()
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// checkConfigDrift compares a configs/ checkout with the embedded configs
+// Params: dir - checkout directory (configs)
+// Returns: OK when the embedded configs are used or match; a warning naming both commits when they drifted
+func checkConfigDrift(dir string) Result {
+	r := Result{Name: "config drift", Status: StatusOK, Detail: "using embedded configs from " + orUnknown(config.EmbeddedCommit())}
+	if _, err := os.Stat(filepath.Join(dir, "tools.yaml")); err == nil {
+		r.Detail = dir + "/ matches the embedded configs"
+	}
+	drift, err := config.DetectConfigDrift(dir)
+	switch {
+	case err != nil:
+		r.Status, r.Detail = StatusWarning, err.Error()
+	case drift.Significant():
+		r.Status, r.Detail = StatusWarning, fmt.Sprintf("%s/ at %s vs embedded %s: %s; update devsetup or the config repo ref",
+			dir, orUnknown(drift.Commit), orUnknown(drift.EmbeddedCommit), drift)
+	}
+	return r
+}
+
+// orUnknown returns s, or "unknown" when empty
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// checkStateFiles verifies each JSON file in the state directory can be read and parsed
+// Params: dir - state directory
+// Returns: One result per file, or a single OK result when there is no state yet
+func checkStateFiles(dir string) []Result {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(paths) == 0 {
+		return []Result{{Name: "state files", Status: StatusOK, Detail: "none yet (first run)"}}
+	}
+
+	var results []Result
+	for _, path := range paths {
+		r := Result{Name: "state " + filepath.Base(path), Status: StatusOK, Detail: "readable"}
+		data, err := os.ReadFile(path)
+		switch {
+		case err != nil:
+			r.Status, r.Detail = StatusError, err.Error()
+		case !json.Valid(data):
+			r.Status, r.Detail = StatusError, fmt.Sprintf("not valid JSON; move %s aside and re-run devsetup", path)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// checkWritable verifies devsetup can create files in a directory
+// Params: name - label for the result, dir - directory to test (created if missing, as devsetup would)
+// Returns: OK, or an error naming the directory
+func checkWritable(name, dir string) Result {
+	r := Result{Name: name, Status: StatusOK, Detail: dir + " is writable"}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		r.Status, r.Detail = StatusError, err.Error()
+		return r
+	}
+	f, err := os.CreateTemp(dir, ".selfcheck-*")
+	if err != nil {
+		r.Status, r.Detail = StatusError, err.Error()
+		return r
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return r
+}
@@ -0,0 +1,51 @@
+package selfcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckStateFiles(t *testing.T) {
+	dir := t.TempDir()
+	if got := checkStateFiles(dir); len(got) != 1 || got[0].Status != StatusOK {
+		t.Fatalf("empty state dir = %+v, want single OK", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "state.json"), []byte(`{"installed":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "check-cache.json"), []byte(`{"trunc`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]string{}
+	for _, r := range checkStateFiles(dir) {
+		statuses[r.Name] = r.Status
+	}
+	if statuses["state state.json"] != StatusOK || statuses["state check-cache.json"] != StatusError {
+		t.Errorf("statuses = %v, want state.json ok and check-cache.json error", statuses)
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	if r := checkWritable("log dir", dir); r.Status != StatusOK {
+		t.Errorf("new dir = %+v, want OK", r)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("probe file left behind: %v", entries)
+	}
+
+	if os.Getuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chmod(dir, 0700) }()
+	if r := checkWritable("log dir", dir); r.Status != StatusError {
+		t.Errorf("read-only dir = %+v, want error", r)
+	}
+}
@@ -0,0 +1,170 @@
+// File: internal/services/services.go
+// Purpose: Homebrew services (postgresql, redis) declared on tools
+// Problem: After installing postgresql or redis every developer had to remember 'brew services start' and then
+//          find out the hard way that nothing listened on 5432 because the service was crash-looping
+// Role: Starts, stops, and inspects brew services and health-checks their TCP port; used by install, verify,
+//       status, and devsetup services
+// Usage: err := services.Ensure(ctx, "postgresql@16", 5432, 30*time.Second); info, err := services.Get(ctx, name)
+// Design choices: Wraps the brew CLI ('brew services info --json') instead of reading launchd plists, so state
+//                 matches what 'brew services list' shows; "running" is not enough, the port must accept connections
+// Assumptions: brew is on PATH; services listen on 127.0.0.1
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// portPollInterval is how often Ensure retries the port while a service starts
+const portPollInterval = 500 * time.Millisecond
+
+// Info is the brew view of one service
+type Info struct {
+	// Name is the formula name (e.g. postgresql@16)
+	Name string `json:"name"`
+
+	// Running is whether launchd reports the service running
+	Running bool `json:"running"`
+
+	// Status is brew's status word: started, stopped, none, error, scheduled
+	Status string `json:"status"`
+
+	// PID of the running service (0 if not running)
+	PID int `json:"pid"`
+}
+
+// Get returns the brew status of a service
+// Params: ctx - context for the brew call, name - formula name
+// Returns: Info, error if brew fails or doesn't know the service
+func Get(ctx context.Context, name string) (Info, error) {
+	out, err := brew(ctx, "info", name, "--json")
+	if err != nil {
+		return Info{}, err
+	}
+	infos, err := parseInfo(out)
+	if err != nil {
+		return Info{}, err
+	}
+	for _, info := range infos {
+		if info.Name == name {
+			return info, nil
+		}
+	}
+	return Info{}, fmt.Errorf("brew services doesn't know %s", name)
+}
+
+// Start runs 'brew services start'
+// Params: ctx - context for the brew call, name - formula name
+// Returns: Error with brew's output if it fails
+func Start(ctx context.Context, name string) error {
+	_, err := brew(ctx, "start", name)
+	return err
+}
+
+// Stop runs 'brew services stop'
+// Params: ctx - context for the brew call, name - formula name
+// Returns: Error with brew's output if it fails
+func Stop(ctx context.Context, name string) error {
+	_, err := brew(ctx, "stop", name)
+	return err
+}
+
+// Ensure starts a service if needed and waits until its port accepts connections
+// What: Start when brew reports it not running, then poll the port until timeout
+// Why: Install must leave services usable, not merely registered with launchd
+// Params: ctx - context, name - formula name, port - TCP port (0 = don't wait), timeout - how long to wait for the port
+// Returns: Error if the service can't be started or the port never opens
+// Edge cases: An already running service with a closed port is not restarted; the port error says to check its log
+func Ensure(ctx context.Context, name string, port int, timeout time.Duration) error {
+	info, err := Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !info.Running {
+		if err := Start(ctx, name); err != nil {
+			return err
+		}
+	}
+	if port == 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for !PortOpen(port, time.Second) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s started but nothing accepts connections on port %d after %s (see 'brew services info %s')", name, port, timeout, name)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(portPollInterval):
+		}
+	}
+	return nil
+}
+
+// Check reports whether a service is running and, if port is set, accepting connections
+// Params: ctx - context, name - formula name, port - TCP port (0 = skip the port check)
+// Returns: pass/fail and a message for verify and status
+func Check(ctx context.Context, name string, port int) (bool, string) {
+	info, err := Get(ctx, name)
+	if err != nil {
+		return false, err.Error()
+	}
+	if !info.Running {
+		return false, fmt.Sprintf("%s is %s", name, orStopped(info.Status))
+	}
+	if port != 0 && !PortOpen(port, time.Second) {
+		return false, fmt.Sprintf("%s is running (pid %d) but port %d is closed", name, info.PID, port)
+	}
+	if port != 0 {
+		return true, fmt.Sprintf("running, port %d open", port)
+	}
+	return true, "running"
+}
+
+// PortOpen reports whether something accepts TCP connections on localhost:port
+func PortOpen(port int, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), timeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// parseInfo decodes 'brew services info --json' output
+func parseInfo(data []byte) ([]Info, error) {
+	var infos []Info
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("failed to parse brew services output: %w", err)
+	}
+	return infos, nil
+}
+
+// brew runs a 'brew services' subcommand
+func brew(ctx context.Context, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, "brew", append([]string{"services"}, args...)...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("brew services %s: %s", strings.Join(args, " "), strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("brew services %s: %w", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+// orStopped returns brew's status word, or "stopped" when it has none
+func orStopped(status string) string {
+	if status == "" || status == "none" {
+		return "stopped"
+	}
+	return status
+}
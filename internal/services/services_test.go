@@ -0,0 +1,46 @@
+package services
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestParseInfo(t *testing.T) {
+	out := []byte(`[{"name":"postgresql@16","service_name":"homebrew.mxcl.postgresql@16","running":true,"loaded":true,"status":"started","pid":812,"exit_code":0}]`)
+	infos, err := parseInfo(out)
+	if err != nil {
+		t.Fatalf("parseInfo: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "postgresql@16" || !infos[0].Running || infos[0].PID != 812 {
+		t.Errorf("unexpected infos: %+v", infos)
+	}
+
+	if _, err := parseInfo([]byte("Error: not json")); err == nil {
+		t.Error("expected an error for non-JSON output")
+	}
+}
+
+func TestPortOpen(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can't listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	if !PortOpen(port, time.Second) {
+		t.Errorf("port %d should be open", port)
+	}
+
+	_ = ln.Close()
+	if PortOpen(port, 200*time.Millisecond) {
+		t.Errorf("port %d should be closed", port)
+	}
+}
+
+func TestOrStopped(t *testing.T) {
+	for in, want := range map[string]string{"": "stopped", "none": "stopped", "error": "error"} {
+		if got := orStopped(in); got != want {
+			t.Errorf("orStopped(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// File: internal/setup/aitools.go
+// Purpose: AI coding assistant setup for setup tasks (Claude Code, GitHub Copilot CLI)
+// Problem: The Claude setup was a remote script and Copilot had none; keys ended up in ~/.zshrc exports and a
+//          revoked key surfaced as a failing CLI weeks later
+// Role: Executes the ai_assistant block of a setup task: gets the key from the keychain (or asks for it), checks
+//       the service accepts it, and writes the CLI's settings and env block so the CLI reads the key from the keychain
+// Usage: setup.yaml task with `ai_assistant: {tool: claude}`; run by SetupExecutor.executeTask
+// Design choices: Same keychain flow as remote_cache (keychainSecret); settings merge like editor_settings (managed
+//                 keys only, diff confirmed, previous file backed up); the key itself never reaches a file or log
+// Assumptions: The CLI comes from tools.yaml (tool:claude-code, tool:copilot-cli)
+
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/rkinnovate/dev-setup/internal/aitools"
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+	"github.com/rkinnovate/dev-setup/internal/editor"
+)
+
+// executeAIAssistant sets up an AI assistant CLI's key and settings
+// Params: task - Task with ai_assistant set
+// Returns: Error if the CLI is missing, no accepted key is given, or a file change is declined or fails
+func (se *SetupExecutor) executeAIAssistant(task config.SetupTask) error {
+	ai := task.AIAssistant
+	if _, err := exec.LookPath(ai.Tool); err != nil {
+		return fmt.Errorf("%s CLI is not installed (add it to depends_on)", ai.Tool)
+	}
+
+	label := ai.Prompt
+	if label == "" {
+		label = ai.Tool + " API key"
+	}
+	check := func(ctx context.Context, key string) error {
+		se.ui.Info("  Checking the %s...", label)
+		return aitools.CheckKey(ctx, ai.Tool, key)
+	}
+	if _, err := se.keychainSecret(task, ai.KeychainService(), label, check, aitools.ErrUnauthorized); err != nil {
+		return fmt.Errorf("%s authentication failed: %w", ai.Tool, err)
+	}
+
+	if settings := aitools.ManagedSettings(ai); len(settings) > 0 {
+		f, err := editorChange(aitools.SettingsPath(ai.Tool), func(content string) (string, error) {
+			return editor.MergeSettings(content, settings)
+		})
+		if err != nil {
+			return err
+		}
+		display := displayPath(f.path)
+		if f.updated != f.old {
+			se.ui.Diff(dotfiles.Diff(display, f.old, f.updated))
+			if err := se.confirmChange(display); err != nil {
+				return err
+			}
+			backup, err := editor.Write(ai.Tool, f.path, f.updated)
+			if err != nil {
+				return err
+			}
+			if backup != "" {
+				se.ui.Info("  Previous %s backed up to %s", display, displayPath(backup))
+			}
+		}
+	}
+
+	if lines := aitools.EnvLines(ai); len(lines) > 0 {
+		path := config.ExpandHome("~/.zshrc")
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", displayPath(path), err)
+		}
+		if updated := dotfiles.ReplaceBlock(string(data), aitools.BlockName(ai), lines); updated != string(data) {
+			if err := se.writeDotfile(path, string(data), updated); err != nil {
+				return err
+			}
+		}
+	}
+	se.ui.Success("  ✓ %s authenticated (key in the login keychain)", ai.Tool)
+	return nil
+}
+
+// previewAIAssistant shows the settings and ~/.zshrc changes an ai_assistant task would make, for dry runs
+func (se *SetupExecutor) previewAIAssistant(task config.SetupTask) {
+	ai := task.AIAssistant
+	if ai == nil {
+		return
+	}
+	if settings := aitools.ManagedSettings(ai); len(settings) > 0 {
+		f, err := editorChange(aitools.SettingsPath(ai.Tool), func(content string) (string, error) {
+			return editor.MergeSettings(content, settings)
+		})
+		if err != nil {
+			se.ui.Warning("  ⚠️  %v", err)
+		} else if f.updated != f.old {
+			se.ui.Diff(dotfiles.Diff(displayPath(f.path), f.old, f.updated))
+		}
+	}
+	if lines := aitools.EnvLines(ai); len(lines) > 0 {
+		path := config.ExpandHome("~/.zshrc")
+		data, _ := os.ReadFile(path)
+		if updated := dotfiles.ReplaceBlock(string(data), aitools.BlockName(ai), lines); updated != string(data) {
+			se.ui.Diff(dotfiles.Diff(displayPath(path), string(data), updated))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package setup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/policy"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestHomebrewPolicyBuiltin(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	prefix := t.TempDir()
+	if err := os.Mkdir(filepath.Join(prefix, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// brew stub: analytics stay enabled until `brew analytics off`
+	bin := t.TempDir()
+	analyticsOff := filepath.Join(t.TempDir(), "analytics-off")
+	brew := `#!/bin/sh
+case "$1 $2" in
+"--prefix "|"--repository ") echo '` + prefix + `' ;;
+"analytics state") if [ -e '` + analyticsOff + `' ]; then echo "InfluxDB analytics are disabled."; else echo "InfluxDB analytics are enabled."; fi ;;
+"analytics off") touch '` + analyticsOff + `' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "brew"), []byte(brew), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// An existing brew.env with a stale auto-update interval
+	brewEnv := policy.BrewEnvPath()
+	if err := os.MkdirAll(filepath.Dir(brewEnv), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(brewEnv, []byte("HOMEBREW_AUTO_UPDATE_SECS=60\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	off := false
+	hp := &config.HomebrewPolicy{Analytics: &off, NoInsecureRedirect: true, AutoUpdateInterval: 24 * time.Hour, PrefixOwnership: true}
+	se := NewSetupExecutor(&config.SetupConfig{}, &config.ToolsConfig{}, &config.State{}, ui.NewEventUI(io.Discard, nil), false)
+	se.SetPolicies(&config.PolicyConfig{Homebrew: hp})
+
+	if err := se.executeBuiltin(config.SetupTask{Name: "homebrew-hardening", Builtin: config.BuiltinHomebrewPolicy}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(brewEnv)
+	if got := strings.Fields(string(data)); len(got) != 2 || !strings.Contains(string(data), "HOMEBREW_AUTO_UPDATE_SECS=86400\n") || !strings.Contains(string(data), "HOMEBREW_NO_INSECURE_REDIRECT=1\n") {
+		t.Errorf("brew.env after the builtin:\n%s", data)
+	}
+	for _, r := range policy.EvaluateHomebrew(t.Context(), *hp) {
+		if !r.Compliant {
+			t.Errorf("%s still non-compliant after the builtin: %s", r.Policy.Name, r.Detail)
+		}
+	}
+
+	// A read-only Homebrew directory is reported, with a fix
+	if err := os.Chmod(filepath.Join(prefix, "bin"), 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(filepath.Join(prefix, "bin"), 0755) })
+	if r := policy.BrewOwnership(t.Context(), prefix); r.Compliant || !strings.Contains(r.FixCommand, "chmod u+w") {
+		t.Errorf("BrewOwnership() with a read-only bin = %+v", r)
+	}
+}
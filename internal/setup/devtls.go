@@ -0,0 +1,93 @@
+// File: internal/setup/devtls.go
+// Purpose: Development TLS for setup tasks (mkcert CA, certificate for dev domains, /etc/hosts entries)
+// Problem: Each web team documented its own mkcert steps; new machines served plain HTTP or carried expired certs
+// Role: Executes the dev_tls block of a setup task: installs and trusts mkcert's CA, issues or renews the
+//       certificate, and maintains the hosts block for the dev domains
+// Usage: setup.yaml task with `dev_tls: {domains: [app.test, "*.app.test"]}`; run by SetupExecutor.executeTask
+// Design choices: The certificate is reissued only when it is missing, due for renewal, lacks a domain, or was
+//                 signed by another CA (e.g. restored from a backup of a different machine), so reruns are no-ops;
+//                 the hosts change is shown as a diff and confirmed like dotfile changes
+// Assumptions: mkcert comes from tools.yaml (tool:mkcert); `mkcert -install` may ask for the login password
+
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/devtls"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+)
+
+// executeDevTLS sets up local HTTPS for a task's domains
+// Params: task - Task with dev_tls set
+// Returns: Error if mkcert is missing or fails, or the hosts change is declined or fails
+func (se *SetupExecutor) executeDevTLS(task config.SetupTask) error {
+	tls := task.DevTLS
+	if _, err := exec.LookPath("mkcert"); err != nil {
+		return fmt.Errorf("mkcert is not installed (add depends_on: [tool:mkcert])")
+	}
+
+	ctx, cancel := se.getContext(5 * time.Minute)
+	defer cancel()
+
+	// Idempotent: creates the CA on first run, (re)adds it to the trust stores when missing
+	se.ui.Info("  Trusting the local CA (mkcert -install)...")
+	if err := se.runTaskCommand(ctx, task, "mkcert -install"); err != nil {
+		return fmt.Errorf("failed to install the local CA: %w", err)
+	}
+
+	certFile, keyFile := tls.CertFiles()
+	cert, key := config.ExpandPath(certFile), config.ExpandPath(keyFile)
+	renewBefore := tls.RenewBefore
+	if renewBefore == 0 {
+		renewBefore = config.DefaultRenewBefore
+	}
+	if err := devtls.Check(cert, tls.Domains, renewBefore, time.Now()); err != nil {
+		if !os.IsNotExist(err) {
+			se.ui.Info("  Reissuing %s: %v", displayPath(cert), err)
+		}
+		if err := devtls.Issue(ctx, cert, key, tls.Domains); err != nil {
+			return fmt.Errorf("failed to issue certificate: %w", err)
+		}
+		se.ui.Success("  ✓ Certificate for %s: %s", strings.Join(tls.Domains, ", "), displayPath(cert))
+	} else {
+		se.ui.Info("  ✓ Certificate for %s is valid (%s)", strings.Join(tls.Domains, ", "), displayPath(cert))
+	}
+
+	if tls.Hosts {
+		return se.updateHosts(ctx, devtls.HostDomains(tls.Domains))
+	}
+	return nil
+}
+
+// updateHosts points dev domains at 127.0.0.1 in the managed /etc/hosts block
+// What: Shows the change as a diff, asks unless --yes, then writes with sudo
+// Params: ctx - bounds the sudo commands, domains - names for the block
+func (se *SetupExecutor) updateHosts(ctx context.Context, domains []string) error {
+	data, err := os.ReadFile(devtls.HostsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", devtls.HostsFile, err)
+	}
+	updated := devtls.HostsBlock(string(data), domains)
+	if updated == string(data) {
+		se.ui.Info("  ✓ %s points %s at 127.0.0.1", devtls.HostsFile, strings.Join(domains, ", "))
+		return nil
+	}
+
+	se.ui.Diff(dotfiles.Diff(devtls.HostsFile, string(data), updated))
+	if err := se.confirmChange(devtls.HostsFile); err != nil {
+		return err
+	}
+	se.ui.Info("  Updating %s (may prompt for sudo)...", devtls.HostsFile)
+	if err := devtls.WriteHosts(ctx, updated); err != nil {
+		return err
+	}
+	se.ui.Success("  ✓ %s updated (previous copy: %s.devsetup-backup)", devtls.HostsFile, devtls.HostsFile)
+	return nil
+}
@@ -0,0 +1,115 @@
+// File: internal/setup/editor.go
+// Purpose: Editor settings sync for setup tasks (team defaults for Zed or VS Code)
+// Problem: Team editor defaults lived in a wiki page; settings drifted per machine and new hires missed them
+// Role: Executes the editor_settings block of a setup task: merges managed settings and keybindings into the
+//       user's files, showing each change as a diff first
+// Usage: setup.yaml task with `editor_settings: {editor: zed, settings: {tab_size: 2}}`; run by SetupExecutor.executeTask
+// Design choices: Same confirm-with-diff flow as dotfile changes; unchanged files are reported, not rewritten, so
+//                 re-running setup is quiet; the previous file is kept under backups/editor
+// Assumptions: The editor doesn't need to be running or restarted (both reload their settings files on change)
+
+package setup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+	"github.com/rkinnovate/dev-setup/internal/editor"
+)
+
+// editorFile is one editor file a task changes
+type editorFile struct {
+	path    string
+	old     string
+	updated string
+}
+
+// executeEditorSettings merges a task's managed settings and keybindings into the editor's files
+// Params: task - Task with editor_settings set
+// Returns: Error if a file can't be parsed, a change is declined, or a write fails
+func (se *SetupExecutor) executeEditorSettings(task config.SetupTask) error {
+	es := task.EditorSettings
+	files, err := editorChanges(es)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		display := displayPath(f.path)
+		if f.updated == f.old {
+			se.ui.Info("  ✓ %s already has the team defaults", display)
+			continue
+		}
+		se.ui.Diff(dotfiles.Diff(display, f.old, f.updated))
+		if err := se.confirmChange(display); err != nil {
+			return err
+		}
+		backup, err := editor.Write(es.Editor, f.path, f.updated)
+		if err != nil {
+			return err
+		}
+		if backup != "" {
+			se.ui.Info("  Previous %s backed up to %s", display, displayPath(backup))
+		}
+		se.ui.Success("  ✓ Updated %s", display)
+	}
+	return nil
+}
+
+// previewEditorSettings shows the editor file changes a task would make, for dry runs
+// Params: task - task to preview (tasks without editor_settings show nothing)
+func (se *SetupExecutor) previewEditorSettings(task config.SetupTask) {
+	if task.EditorSettings == nil {
+		return
+	}
+	files, err := editorChanges(task.EditorSettings)
+	if err != nil {
+		se.ui.Warning("  ⚠️  %v", err)
+		return
+	}
+	for _, f := range files {
+		if f.updated != f.old {
+			se.ui.Diff(dotfiles.Diff(displayPath(f.path), f.old, f.updated))
+		}
+	}
+}
+
+// editorChanges computes the new content of the settings and keybindings files
+// Returns: One entry per file the task manages (settings first), error if a file is unreadable or malformed
+func editorChanges(es *config.EditorSettingsConfig) ([]editorFile, error) {
+	var files []editorFile
+	if len(es.Settings) > 0 {
+		f, err := editorChange(editor.SettingsPath(es.Editor), func(content string) (string, error) {
+			return editor.MergeSettings(content, es.Settings)
+		})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	if len(es.Keybindings) > 0 {
+		f, err := editorChange(editor.KeybindingsPath(es.Editor), func(content string) (string, error) {
+			return editor.MergeKeybindings(content, es.Keybindings)
+		})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// editorChange reads one editor file and applies merge to its content
+func editorChange(path string, merge func(string) (string, error)) (editorFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return editorFile{}, fmt.Errorf("failed to read %s: %w", displayPath(path), err)
+	}
+	updated, err := merge(string(data))
+	if err != nil {
+		return editorFile{}, fmt.Errorf("%s: %w", displayPath(path), err)
+	}
+	return editorFile{path: path, old: string(data), updated: updated}, nil
+}
@@ -0,0 +1,110 @@
+// File: internal/setup/githooks.go
+// Purpose: Team git hooks for setup tasks (lefthook or pre-commit with a standard hook config)
+// Problem: Hook installs were a per-clone README step, so whether commits were linted depended on the machine
+// Role: Executes the git_hooks block of a setup task: writes the hook scripts, points core.hooksPath at them
+//       (globally or in each listed checkout), and probes that git runs them
+// Usage: setup.yaml task with `git_hooks: {manager: lefthook, config: ~/code/standards/lefthook.yml}`
+// Design choices: A hooks path another tool set (husky's .husky/_, a previous global setting) is never replaced
+//                 silently: globally it's confirmed like a dotfile change, in a checkout it's left alone with a
+//                 warning because the repo's own tooling manages it
+// Assumptions: The manager comes from tools.yaml (tool:lefthook); the standard config is on disk (e.g. a cloned
+//              standards repo from an earlier task)
+
+package setup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/githooks"
+)
+
+// executeGitHooks registers the team's git hooks
+// Params: task - Task with git_hooks set
+// Returns: Error if the manager or config is missing, a hooks path change is declined, or git fails
+func (se *SetupExecutor) executeGitHooks(task config.SetupTask) error {
+	gh := task.GitHooks
+	if _, err := exec.LookPath(gh.Manager); err != nil {
+		return fmt.Errorf("%s is not installed (add depends_on: [tool:%s])", gh.Manager, gh.Manager)
+	}
+	cfg, err := filepath.Abs(config.ExpandPath(gh.Config))
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(cfg); err != nil {
+		return fmt.Errorf("hook config %s: %w", gh.Config, err)
+	}
+
+	dir := githooks.Dir(task.Name)
+	scripts := map[string]string{}
+	for _, hook := range gh.HookNames() {
+		scripts[hook] = githooks.Script(gh.Manager, cfg, hook)
+	}
+	changed, err := githooks.WriteScripts(dir, scripts)
+	if err != nil {
+		return err
+	}
+	if len(changed) > 0 {
+		se.ui.Info("  Hook scripts updated: %s", strings.Join(changed, ", "))
+	}
+
+	ctx, cancel := se.getContext(2 * time.Minute)
+	defer cancel()
+
+	if len(gh.Repos) == 0 {
+		current, err := githooks.HooksPath(ctx, "")
+		if err != nil {
+			return err
+		}
+		if current != dir {
+			if current != "" {
+				se.ui.Warning("  ⚠️  Global core.hooksPath is %s; hooks there stop running (repos' own .git/hooks still run)", current)
+				if err := se.confirmChange("global core.hooksPath"); err != nil {
+					return err
+				}
+			}
+			if err := githooks.SetHooksPath(ctx, "", dir); err != nil {
+				return err
+			}
+		}
+		if err := githooks.Probe(ctx, "", gh.HookNames()[0]); err != nil {
+			return err
+		}
+		se.ui.Success("  ✓ %s hooks (%s) run in every repository", gh.Manager, strings.Join(gh.HookNames(), ", "))
+		return nil
+	}
+
+	registered := 0
+	for _, repo := range gh.Repos {
+		path := config.ExpandPath(repo)
+		if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+			se.ui.Info("  - %s not cloned yet, skipping", repo)
+			continue
+		}
+		current, err := githooks.HooksPath(ctx, path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", repo, err)
+		}
+		if current != "" && current != dir {
+			se.ui.Warning("  ⚠️  %s uses its own hooks path (%s); not changed", repo, current)
+			continue
+		}
+		if current == "" {
+			if err := githooks.SetHooksPath(ctx, path, dir); err != nil {
+				return fmt.Errorf("%s: %w", repo, err)
+			}
+		}
+		if err := githooks.Probe(ctx, path, gh.HookNames()[0]); err != nil {
+			return fmt.Errorf("%s: %w", repo, err)
+		}
+		se.ui.Info("  ✓ %s", repo)
+		registered++
+	}
+	se.ui.Success("  ✓ %s hooks (%s) registered in %d of %d repositories", gh.Manager, strings.Join(gh.HookNames(), ", "), registered, len(gh.Repos))
+	return nil
+}
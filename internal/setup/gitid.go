@@ -0,0 +1,218 @@
+// File: internal/setup/gitid.go
+// Purpose: Multi-account git identities for setup tasks (personal + work, chosen by directory)
+// Problem: Developers with two GitHub accounts set up includeIf, keys, and SSH aliases from a blog post, and
+//          half of them ended up committing to work repos as their personal email
+// Role: Executes the git_identities block of a setup task: asks for each identity's name and email, generates
+//       missing SSH keys, writes the include files and the ~/.gitconfig and ~/.ssh/config blocks, then checks
+//       every identity resolves and authenticates
+// Usage: setup.yaml task with `git_identities: [{name: work, dirs: [~/code/work]}, {name: personal}]`
+// Design choices: Answers already in an identity's include file are reused, so reruns don't ask again; a new key
+//                 is shown (and copied) with the page to add it on, and setup waits for Enter before checking it;
+//                 the dotfile blocks are diffed and confirmed like other dotfile changes
+// Assumptions: The task is interactive (ssh-keygen asks for a passphrase); git and OpenSSH come with macOS
+
+package setup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+	"github.com/rkinnovate/dev-setup/internal/gitid"
+	promptpkg "github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// identitiesBlock names the managed blocks in ~/.gitconfig and ~/.ssh/config
+const identitiesBlock = "git_identities"
+
+// executeGitIdentities sets up a task's git identities
+// Params: task - Task with git_identities set
+// Returns: Error if input fails, a key can't be generated, a dotfile change is declined, or an identity check fails
+func (se *SetupExecutor) executeGitIdentities(task config.SetupTask) error {
+	ctx, cancel := se.getContext(30 * time.Minute)
+	defer cancel()
+
+	if err := os.MkdirAll(gitid.Dir(), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", displayPath(gitid.Dir()), err)
+	}
+	for _, id := range task.GitIdentities {
+		se.ui.Info("  %s identity (%s)", id.Name, identityScope(id))
+		name, email, err := se.identityAnswers(task, id)
+		if err != nil {
+			return err
+		}
+		content := gitid.IdentityConfig(id, name, email)
+		if data, err := os.ReadFile(gitid.File(id.Name)); err != nil || string(data) != content {
+			if err := os.WriteFile(gitid.File(id.Name), []byte(content), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", displayPath(gitid.File(id.Name)), err)
+			}
+		}
+
+		key := config.ExpandPath(id.KeyPath())
+		if _, err := os.Stat(key); os.IsNotExist(err) {
+			se.ui.Info("  Generating SSH key %s (choose a passphrase; it's kept in the keychain)", displayPath(key))
+			if err := gitid.GenerateKey(ctx, key, email); err != nil {
+				return err
+			}
+			if err := se.showPublicKey(task, id, key+".pub"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := os.MkdirAll(config.ExpandHome("~/.ssh"), 0700); err != nil {
+		return err
+	}
+	files, err := identityDotfiles(task.GitIdentities)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.updated == f.old {
+			se.ui.Info("  ✓ %s is current", displayPath(f.path))
+			continue
+		}
+		if err := se.writeDotfile(f.path, f.old, f.updated); err != nil {
+			return err
+		}
+	}
+
+	var failed []string
+	for _, id := range task.GitIdentities {
+		if err := gitid.Check(ctx, id); err != nil {
+			se.ui.Warning("  ✗ %s: %v", id.Name, err)
+			failed = append(failed, id.Name)
+			continue
+		}
+		se.ui.Success("  ✓ %s identity resolves and authenticates as git@%s", id.Name, id.SSHAlias())
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("git identities not working: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// identityDotfiles returns the ~/.ssh/config and ~/.gitconfig changes for a task's identities
+func identityDotfiles(ids []config.GitIdentity) ([]editorFile, error) {
+	var files []editorFile
+	for _, block := range []struct {
+		path  string
+		lines []string
+	}{{"~/.ssh/config", gitid.SSHLines(ids)}, {"~/.gitconfig", gitid.GitconfigLines(ids)}} {
+		path, lines := config.ExpandHome(block.path), block.lines
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", displayPath(path), err)
+		}
+		files = append(files, editorFile{path, string(data), dotfiles.ReplaceBlock(string(data), identitiesBlock, lines)})
+	}
+	return files, nil
+}
+
+// previewGitIdentities shows the dotfile changes a git_identities task would make, for dry runs
+func (se *SetupExecutor) previewGitIdentities(task config.SetupTask) {
+	if len(task.GitIdentities) == 0 {
+		return
+	}
+	files, err := identityDotfiles(task.GitIdentities)
+	if err != nil {
+		se.ui.Warning("  ⚠️  %v", err)
+		return
+	}
+	for _, f := range files {
+		if f.updated != f.old {
+			se.ui.Diff(dotfiles.Diff(displayPath(f.path), f.old, f.updated))
+		}
+	}
+}
+
+// identityScope describes where an identity applies
+func identityScope(id config.GitIdentity) string {
+	if len(id.Dirs) == 0 {
+		return "default"
+	}
+	return strings.Join(id.Dirs, ", ")
+}
+
+// identityAnswers returns an identity's user.name and user.email
+// What: Configured values win, then the identity's include file from an earlier run, then a question
+func (se *SetupExecutor) identityAnswers(task config.SetupTask, id config.GitIdentity) (string, string, error) {
+	prevName, prevEmail := gitid.ReadIdentity(id.Name)
+	name, email := id.UserName, id.Email
+	if name == "" {
+		name = prevName
+	}
+	if email == "" {
+		email = prevEmail
+	}
+	var err error
+	if name == "" {
+		global, _ := exec.Command("git", "config", "--global", "--get", "user.name").Output()
+		if name, err = se.askIdentity(task, id.Name+" git user.name", strings.TrimSpace(string(global))); err != nil {
+			return "", "", err
+		}
+	}
+	if email == "" {
+		if email, err = se.askIdentity(task, id.Name+" git user.email", ""); err != nil {
+			return "", "", err
+		}
+	}
+	if name == "" || !strings.Contains(email, "@") {
+		return "", "", fmt.Errorf("%s identity needs a user.name and an email address", id.Name)
+	}
+	return name, email, nil
+}
+
+// askIdentity asks one identity question, offering def on Enter
+func (se *SetupExecutor) askIdentity(task config.SetupTask, label, def string) (string, error) {
+	if def != "" {
+		se.ui.Info("  %s (press Enter for default: %s):", label, def)
+	} else {
+		se.ui.Info("  %s:", label)
+	}
+	value, err := promptpkg.Ask(promptpkg.Question{Task: task.Name, Label: label, Default: def})
+	if errors.Is(err, promptpkg.ErrDeferred) {
+		return "", err
+	}
+	value = strings.TrimSpace(value)
+	if err != nil && value == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	if value == "" {
+		value = def
+	}
+	return value, nil
+}
+
+// showPublicKey prints a new public key, copies it to the clipboard, and waits until the user has added it
+func (se *SetupExecutor) showPublicKey(task config.SetupTask, id config.GitIdentity, pub string) error {
+	data, err := os.ReadFile(pub)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", displayPath(pub), err)
+	}
+	copied := ""
+	pbcopy := exec.Command("pbcopy")
+	pbcopy.Stdin = strings.NewReader(string(data))
+	if pbcopy.Run() == nil {
+		copied = " (copied to the clipboard)"
+	}
+	page := "your " + id.GitHost() + " account's SSH keys settings"
+	if id.GitHost() == "github.com" {
+		page = "https://github.com/settings/ssh/new"
+	}
+	se.ui.Info("")
+	se.ui.Info("  Add this key%s to the %s account at %s:", copied, id.Name, page)
+	se.ui.Info("    %s", strings.TrimSpace(string(data)))
+	se.ui.Info("")
+	if se.assumeYes {
+		return nil
+	}
+	se.ui.Info("  Press Enter once it's added:")
+	_, err = promptpkg.Ask(promptpkg.Question{Task: task.Name, Label: "press Enter once the " + id.Name + " key is added"})
+	return err
+}
@@ -0,0 +1,66 @@
+// File: internal/setup/jetbrains.go
+// Purpose: Required JetBrains IDE plugins for setup tasks
+// Problem: IntelliJ/Android Studio plugins the team relies on were installed by hand from the onboarding doc
+// Role: Executes the jetbrains block of a setup task: installs the plugins the IDE doesn't have yet
+// Usage: setup.yaml task with `jetbrains: {ide: studio, plugins: [...]}`; run by SetupExecutor.executeTask
+// Design choices: Only missing plugins are passed to installPlugins, so reruns don't start the IDE at all; the
+//                 IDE must be closed, which is checked up front with a clear message rather than a failed install
+// Assumptions: The IDE is installed by a tool the task depends on (tool:jetbrains-toolbox or a pinned IDE tool)
+
+package setup
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/jetbrains"
+)
+
+// defaultPluginTimeout bounds installPlugins when the task sets no timeout
+const defaultPluginTimeout = 10 * time.Minute
+
+// executeJetBrains installs a task's missing IDE plugins
+// Params: task - Task with jetbrains set
+// Returns: Error if the IDE is missing or running, or plugins are still missing after the install
+func (se *SetupExecutor) executeJetBrains(task config.SetupTask) error {
+	jb := task.JetBrains
+	name := jetbrains.Name(jb.IDE)
+
+	missing := jetbrains.Missing(jb.IDE, jb.Plugins)
+	if len(missing) == 0 {
+		se.ui.Info("  ✓ %s plugins: %s", name, strings.Join(jb.Plugins, ", "))
+		return nil
+	}
+	if _, err := jetbrains.Launcher(jb.IDE); err != nil {
+		return err
+	}
+	if ideRunning(jb.IDE) {
+		return fmt.Errorf("%s is running; quit it and re-run setup to install %s", name, strings.Join(missing, ", "))
+	}
+
+	timeout := jb.Timeout
+	if timeout == 0 {
+		timeout = defaultPluginTimeout
+	}
+	se.ui.Info("  Installing %s plugins: %s...", name, strings.Join(missing, ", "))
+	ctx, cancel := se.getContext(timeout)
+	defer cancel()
+	if err := jetbrains.InstallPlugins(ctx, jb.IDE, missing); err != nil {
+		return fmt.Errorf("%s plugin install failed: %w", name, err)
+	}
+
+	if still := jetbrains.Missing(jb.IDE, jb.Plugins); len(still) > 0 {
+		return fmt.Errorf("%s plugins not installed: %s (check the plugin IDs on their Marketplace pages)", name, strings.Join(still, ", "))
+	}
+	se.ui.Success("  ✓ %s plugins installed", name)
+	return nil
+}
+
+// ideRunning reports whether the IDE's app is running
+// Edge cases: Matches the launcher inside any running bundle of that name (Toolbox and /Applications installs alike)
+func ideRunning(ide string) bool {
+	return exec.Command("pgrep", "-f", "/"+jetbrains.Name(ide)+`\.app/Contents/MacOS/`).Run() == nil
+}
@@ -0,0 +1,94 @@
+// File: internal/setup/manual.go
+// Purpose: Manual setup steps: shown with their instructions, done by the user, acknowledged in state
+// Problem: Steps like requesting VPN access or accepting a SaaS invite can't be automated, so they lived in a wiki
+//          page and nobody could tell which of them a new hire still had open
+// Role: Executes the manual block of a setup task: shows the instructions, asks whether the step is done, runs the
+//       optional verify command, and records the acknowledgment; `devsetup manual done` acknowledges it later
+// Usage: setup.yaml task with `manual: {instructions: "...", url: ..., verify: "..."}`; run by SetupExecutor.executeTask
+// Design choices: A step that isn't done yet is pending, not failed: setup goes on and tasks depending on it are
+//                 deferred like after a Ctrl-C; a passing verify command counts as done without asking; --yes never
+//                 acknowledges a step, since nobody answered for it
+// Assumptions: Verify commands are quick checks (bounded by manualVerifyTimeout) run under sh
+
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	promptpkg "github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// manualVerifyTimeout bounds a manual step's verify command
+const manualVerifyTimeout = 30 * time.Second
+
+// ErrManualPending is returned for a manual step the user hasn't done yet
+var ErrManualPending = errors.New("manual step not done yet")
+
+// executeManual shows a manual step and records it once the user has done it
+// Params: task - Task with manual set
+// Returns: nil when done (verified or confirmed); ErrManualPending (wrapped) when not; prompt.ErrDeferred on Ctrl-C
+func (se *SetupExecutor) executeManual(task config.SetupTask) error {
+	if task.Manual.Verify != "" && VerifyManual(task, se.setupConfig.ShellFor(task)) == nil {
+		se.ui.Info("  ✓ Already done (verified)")
+		return nil
+	}
+
+	se.ui.Info("  📝 Manual step:")
+	for _, line := range ManualInstructions(task) {
+		se.ui.Info("     %s", line)
+	}
+	if se.assumeYes {
+		return ErrManualPending
+	}
+
+	ok, err := promptpkg.Confirm(task.Name, "  Done? [y/N] ")
+	if err != nil {
+		return fmt.Errorf("manual step %s: %w", task.Name, err)
+	}
+	if !ok {
+		return ErrManualPending
+	}
+	if err := VerifyManual(task, se.setupConfig.ShellFor(task)); err != nil {
+		return fmt.Errorf("%w (%v)", ErrManualPending, err)
+	}
+	config.AcknowledgeTask(se.state, task.Name)
+	return nil
+}
+
+// VerifyManual runs a manual step's verify command
+// Why: Setup, status, and `devsetup manual done` agree on whether a step is really done
+// Params: task - Task with manual set, shell - the task's shell (SetupConfig.ShellFor)
+// Returns: nil if the command succeeds, or if the step has none; error naming the failed command otherwise
+func VerifyManual(task config.SetupTask, shell string) error {
+	if task.Manual == nil || task.Manual.Verify == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), manualVerifyTimeout)
+	defer cancel()
+	if err := exec.CommandContext(ctx, shell, "-c", task.Manual.Verify).Run(); err != nil {
+		return fmt.Errorf("verify failed: %s", task.Manual.Verify)
+	}
+	return nil
+}
+
+// ManualInstructions returns a manual step's instructions as display lines
+// Returns: The description (if any), each instruction line, then the URL (if any)
+func ManualInstructions(task config.SetupTask) []string {
+	var lines []string
+	if task.Description != "" {
+		lines = append(lines, task.Description)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(task.Manual.Instructions), "\n") {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	if task.Manual.URL != "" {
+		lines = append(lines, "→ "+task.Manual.URL)
+	}
+	return lines
+}
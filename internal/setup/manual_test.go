@@ -0,0 +1,35 @@
+package setup
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestVerifyManual(t *testing.T) {
+	task := config.SetupTask{Name: "vpn-access", Manual: &config.ManualConfig{Instructions: "Request VPN access"}}
+	if err := VerifyManual(task, config.ShellSh); err != nil {
+		t.Errorf("VerifyManual() without a verify command = %v, want nil", err)
+	}
+	task.Manual.Verify = "true"
+	if err := VerifyManual(task, config.ShellSh); err != nil {
+		t.Errorf("VerifyManual(true) = %v", err)
+	}
+	task.Manual.Verify = "exit 3"
+	if err := VerifyManual(task, config.ShellSh); err == nil {
+		t.Error("VerifyManual(exit 3) succeeded")
+	}
+}
+
+func TestManualInstructions(t *testing.T) {
+	task := config.SetupTask{
+		Name:        "vpn-access",
+		Description: "Request VPN access",
+		Manual:      &config.ManualConfig{Instructions: "Open an IT ticket.\nWait for approval.\n", URL: "https://it.example.com/vpn"},
+	}
+	want := []string{"Request VPN access", "Open an IT ticket.", "Wait for approval.", "→ https://it.example.com/vpn"}
+	if got := ManualInstructions(task); !reflect.DeepEqual(got, want) {
+		t.Errorf("ManualInstructions() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+// File: internal/setup/personalize.go
+// Purpose: Machine personalization for setup tasks (timezone, locale, keyboard, Finder)
+// Problem: IT scripted laptop personalization separately from devsetup, so it drifted and was often skipped
+// Role: Executes the personalize block of a setup task
+// Usage: setup.yaml task with `personalize:`; run by SetupExecutor.executeTask
+// Design choices: Each preference is read before it is written so reruns change nothing; only preferences set in
+//                 YAML are touched; Finder is restarted once, and only if a Finder preference changed
+// Assumptions: macOS `defaults` and `systemsetup`; setting the timezone prompts for sudo
+
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// preference is one `defaults` value managed by personalization
+type preference struct {
+	description string
+	domain      string
+	key         string
+	kind        string // bool, int, string
+	value       string
+	finder      bool
+}
+
+// executePersonalize applies a task's personalize block
+// What: Sets timezone, then each defaults preference that differs from the desired value
+// Why: YAML-driven replacement for the separate IT personalization script
+// Params: task - Task with personalize set
+// Returns: Error if a setting can't be applied
+func (se *SetupExecutor) executePersonalize(task config.SetupTask) error {
+	p := task.Personalize
+
+	if p.Timezone != "" {
+		if current := currentTimezone(); current == p.Timezone {
+			se.ui.Info("  ✓ Timezone %s", p.Timezone)
+		} else {
+			se.ui.Info("  Setting timezone to %s (may prompt for sudo)...", p.Timezone)
+			ctx, cancel := se.getContext(30 * time.Second)
+			defer cancel()
+			if err := se.runCommand(ctx, "sudo systemsetup -settimezone "+p.Timezone); err != nil {
+				return fmt.Errorf("failed to set timezone: %w", err)
+			}
+		}
+	}
+
+	finderChanged := false
+	for _, pref := range preferences(p) {
+		ctx, cancel := se.getContext(10 * time.Second)
+		current, err := readDefault(ctx, pref.domain, pref.key)
+		if err == nil && current == pref.value {
+			cancel()
+			se.ui.Info("  ✓ %s", pref.description)
+			continue
+		}
+
+		se.ui.Info("  Setting %s...", pref.description)
+		err = exec.CommandContext(ctx, "defaults", "write", pref.domain, pref.key, "-"+pref.kind, pref.value).Run()
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", pref.description, err)
+		}
+		finderChanged = finderChanged || pref.finder
+	}
+
+	if finderChanged {
+		// Finder only rereads its preferences on launch; it relaunches automatically
+		_ = exec.Command("killall", "Finder").Run()
+	}
+	return nil
+}
+
+// preferences lists the defaults values requested by a personalize block
+// Params: p - personalize block
+// Returns: Preferences in a stable order (bool values as "1"/"0", matching `defaults read` output)
+func preferences(p *config.PersonalizeConfig) []preference {
+	var prefs []preference
+	if p.Locale != "" {
+		prefs = append(prefs, preference{description: "locale " + p.Locale, domain: "NSGlobalDomain", key: "AppleLocale", kind: "string", value: p.Locale})
+	}
+	if p.TextReplacement != nil {
+		for _, key := range []string{
+			"NSAutomaticSpellingCorrectionEnabled",
+			"NSAutomaticQuoteSubstitutionEnabled",
+			"NSAutomaticDashSubstitutionEnabled",
+			"NSAutomaticCapitalizationEnabled",
+			"NSAutomaticPeriodSubstitutionEnabled",
+			"NSAutomaticTextReplacementEnabled",
+		} {
+			prefs = append(prefs, boolPreference("text replacement ("+key+")", "NSGlobalDomain", key, *p.TextReplacement, false))
+		}
+	}
+	if p.KeyRepeat > 0 {
+		prefs = append(prefs, preference{description: "key repeat", domain: "NSGlobalDomain", key: "KeyRepeat", kind: "int", value: strconv.Itoa(p.KeyRepeat)})
+	}
+	if p.InitialKeyRepeat > 0 {
+		prefs = append(prefs, preference{description: "initial key repeat", domain: "NSGlobalDomain", key: "InitialKeyRepeat", kind: "int", value: strconv.Itoa(p.InitialKeyRepeat)})
+	}
+
+	if f := p.Finder; f != nil {
+		if f.ShowExtensions != nil {
+			prefs = append(prefs, boolPreference("Finder file extensions", "NSGlobalDomain", "AppleShowAllExtensions", *f.ShowExtensions, true))
+		}
+		if f.ShowHidden != nil {
+			prefs = append(prefs, boolPreference("Finder hidden files", "com.apple.finder", "AppleShowAllFiles", *f.ShowHidden, true))
+		}
+		if f.ShowPathBar != nil {
+			prefs = append(prefs, boolPreference("Finder path bar", "com.apple.finder", "ShowPathbar", *f.ShowPathBar, true))
+		}
+		if f.ShowStatusBar != nil {
+			prefs = append(prefs, boolPreference("Finder status bar", "com.apple.finder", "ShowStatusBar", *f.ShowStatusBar, true))
+		}
+	}
+	return prefs
+}
+
+// boolPreference builds a boolean defaults preference
+func boolPreference(description, domain, key string, on, finder bool) preference {
+	value := "0"
+	if on {
+		value = "1"
+	}
+	return preference{description: description, domain: domain, key: key, kind: "bool", value: value, finder: finder}
+}
+
+// readDefault reads a defaults value
+// Returns: Trimmed value, error if the key is not set
+func readDefault(ctx context.Context, domain, key string) (string, error) {
+	output, err := exec.CommandContext(ctx, "defaults", "read", domain, key).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// currentTimezone returns the system timezone from the /etc/localtime symlink
+// Why: `systemsetup -gettimezone` needs sudo; the symlink (→ /var/db/timezone/zoneinfo/<zone>) is world-readable
+// Returns: IANA zone name, or "" if it can't be determined
+func currentTimezone() string {
+	target, err := os.Readlink("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	if i := strings.Index(target, "zoneinfo/"); i >= 0 {
+		return target[i+len("zoneinfo/"):]
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package setup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestExecutePersonalize(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Stubs: defaults keeps values in store, every write, sudo, and killall call is logged
+	store, bin := t.TempDir(), t.TempDir()
+	log := filepath.Join(t.TempDir(), "calls")
+	stubs := map[string]string{
+		"defaults": `#!/bin/sh
+f='` + store + `'/"$2.$3"
+case "$1" in
+read) cat "$f" 2>/dev/null || exit 1 ;;
+write) echo "$5" > "$f"; echo "defaults $2 $3 $4 $5" >> '` + log + `' ;;
+esac
+`,
+		"sudo":    "#!/bin/sh\necho \"sudo $*\" >> '" + log + "'\n",
+		"killall": "#!/bin/sh\necho \"killall $*\" >> '" + log + "'\n",
+	}
+	for name, script := range stubs {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	on := true
+	task := config.SetupTask{Name: "personalize", Personalize: &config.PersonalizeConfig{
+		Timezone:  "Pacific/Chatham",
+		Locale:    "en_GB",
+		KeyRepeat: 2,
+		Finder:    &config.FinderConfig{ShowPathBar: &on},
+	}}
+	se := NewSetupExecutor(&config.SetupConfig{}, &config.ToolsConfig{}, &config.State{}, ui.NewEventUI(io.Discard, nil), false)
+
+	if err := se.executePersonalize(task); err != nil {
+		t.Fatal(err)
+	}
+	calls, _ := os.ReadFile(log)
+	want := "sudo systemsetup -settimezone Pacific/Chatham\n" +
+		"defaults NSGlobalDomain AppleLocale -string en_GB\n" +
+		"defaults NSGlobalDomain KeyRepeat -int 2\n" +
+		"defaults com.apple.finder ShowPathbar -bool 1\n" +
+		"killall Finder\n"
+	if string(calls) != want {
+		t.Errorf("first run calls:\n%s\nwant:\n%s", calls, want)
+	}
+
+	// A rerun finds every preference already set: no writes and no Finder restart
+	if err := os.Remove(log); err != nil {
+		t.Fatal(err)
+	}
+	if err := se.executePersonalize(task); err != nil {
+		t.Fatal(err)
+	}
+	if calls, _ := os.ReadFile(log); strings.Contains(string(calls), "defaults") || strings.Contains(string(calls), "killall") {
+		t.Errorf("rerun changed settings:\n%s", calls)
+	}
+}
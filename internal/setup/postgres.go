@@ -0,0 +1,78 @@
+// File: internal/setup/postgres.go
+// Purpose: Local Postgres bootstrap for setup tasks (role, database, seed)
+// Problem: Creating the app role and database and loading seed data was the manual step everyone did after setup
+// Role: Executes the postgres block of a setup task
+// Usage: setup.yaml task with `postgres:` and depends_on: [tool:postgresql]; run by SetupExecutor.executeTask
+// Design choices: Every step checks before it acts (role, database, seed_check), so reruns and half-finished
+//                 bootstraps are safe; the seed runs as the app role so the objects it creates belong to it
+// Assumptions: The postgresql service is running (declare it as a tool service); psql is on PATH
+
+package setup
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/database"
+)
+
+// defaultPostgresTimeout bounds a bootstrap whose task sets no timeout
+const defaultPostgresTimeout = 5 * time.Minute
+
+// executePostgres creates the task's role and database if missing and seeds the database once
+// Params: task - Task with postgres set
+// Returns: Error naming the step that failed
+func (se *SetupExecutor) executePostgres(task config.SetupTask) error {
+	pg := task.Postgres
+	timeout := pg.Timeout
+	if timeout == 0 {
+		timeout = defaultPostgresTimeout
+	}
+	ctx, cancel := se.getContext(timeout)
+	defer cancel()
+
+	admin := database.Conn{}
+	app := database.Conn{User: pg.User, Password: os.ExpandEnv(pg.Password)}
+
+	if pg.User != "" {
+		created, err := database.EnsureRole(ctx, admin, app.User, app.Password)
+		if err != nil {
+			return fmt.Errorf("role %s: %w", pg.User, err)
+		}
+		se.reportStep(created, "role "+pg.User)
+	}
+
+	created, err := database.EnsureDatabase(ctx, admin, pg.Database, pg.User)
+	if err != nil {
+		return fmt.Errorf("database %s: %w", pg.Database, err)
+	}
+	se.reportStep(created, "database "+pg.Database)
+
+	if pg.Seed == "" {
+		return nil
+	}
+	seeded, err := database.Seeded(ctx, app, pg.Database, pg.SeedCheck)
+	if err != nil {
+		return fmt.Errorf("seed check: %w", err)
+	}
+	if seeded {
+		se.ui.Info("  ✓ %s already seeded", pg.Database)
+		return nil
+	}
+	se.ui.Info("  Seeding %s from %s...", pg.Database, pg.Seed)
+	if err := database.RunFile(ctx, app, pg.Database, os.ExpandEnv(pg.Seed)); err != nil {
+		return fmt.Errorf("seed %s: %w", pg.Seed, err)
+	}
+	return nil
+}
+
+// reportStep prints whether a bootstrap step created something or found it in place
+func (se *SetupExecutor) reportStep(created bool, what string) {
+	if created {
+		se.ui.Success("  ✓ Created %s", what)
+		return
+	}
+	se.ui.Info("  ✓ %s exists", what)
+}
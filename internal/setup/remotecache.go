@@ -0,0 +1,91 @@
+// File: internal/setup/remotecache.go
+// Purpose: Remote build cache setup for monorepo build tools (Bazel, Nx, Turborepo)
+// Problem: Cache tokens were pasted into ~/.zshrc or ~/.bazelrc by hand from a wiki page; typos and expired tokens
+//          left builds quietly uncached
+// Role: Executes the remote_cache block of a setup task: gets the token from the keychain (or asks for it), checks
+//       the cache accepts it, stores it, and writes the tool's config block
+// Usage: setup.yaml task with `remote_cache: {tool: turbo, team: acme}`; run by SetupExecutor.executeTask
+// Design choices: The token goes through keychainSecret (stored only once the cache accepted it, asked for again
+//                 when the stored one is rejected, so a rotated token is fixed by rerunning setup); the config
+//                 change is shown as a diff and confirmed like other dotfile changes
+// Assumptions: The build tool itself comes from tools.yaml (depends_on: [tool:...]); the keychain is unlocked
+
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+	"github.com/rkinnovate/dev-setup/internal/remotecache"
+)
+
+// executeRemoteCache configures a build tool's remote cache
+// Params: task - Task with remote_cache set
+// Returns: Error if no working token is given, the cache is unreachable, or a file change is declined or fails
+func (se *SetupExecutor) executeRemoteCache(task config.SetupTask) error {
+	rc := task.RemoteCache
+	service := rc.KeychainService()
+
+	label := rc.Prompt
+	if label == "" {
+		label = rc.Tool + " remote cache token"
+	}
+	check := func(ctx context.Context, token string) error {
+		se.ui.Info("  Checking %s...", rc.Endpoint())
+		return remotecache.Ping(ctx, rc, token)
+	}
+	if _, err := se.keychainSecret(task, service, label, check, remotecache.ErrUnauthorized); err != nil {
+		return fmt.Errorf("%s remote cache check failed: %w", rc.Tool, err)
+	}
+
+	if rc.Tool == config.BuildToolBazel {
+		if err := writeCredentialHelper(service); err != nil {
+			return err
+		}
+	}
+	path := config.ExpandHome(remotecache.File(rc))
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", displayPath(path), err)
+	}
+	updated := dotfiles.ReplaceBlock(string(data), remotecache.BlockName(rc), remotecache.Lines(rc))
+	if updated != string(data) {
+		if err := se.writeDotfile(path, string(data), updated); err != nil {
+			return err
+		}
+	}
+	se.ui.Success("  ✓ %s remote cache: %s", rc.Tool, rc.Endpoint())
+	return nil
+}
+
+// writeCredentialHelper writes the Bazel credential helper script for a keychain item, when it changed
+func writeCredentialHelper(service string) error {
+	path, script := remotecache.HelperPath(service), remotecache.HelperScript(service)
+	if data, err := os.ReadFile(path); err == nil && string(data) == script {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		return fmt.Errorf("failed to write credential helper: %w", err)
+	}
+	return nil
+}
+
+// previewRemoteCache shows the config change a remote_cache task would make, for dry runs
+func (se *SetupExecutor) previewRemoteCache(task config.SetupTask) {
+	rc := task.RemoteCache
+	if rc == nil {
+		return
+	}
+	path := config.ExpandHome(remotecache.File(rc))
+	data, _ := os.ReadFile(path)
+	if updated := dotfiles.ReplaceBlock(string(data), remotecache.BlockName(rc), remotecache.Lines(rc)); updated != string(data) {
+		se.ui.Diff(dotfiles.Diff(displayPath(path), string(data), updated))
+	}
+}
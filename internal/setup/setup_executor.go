@@ -9,16 +9,29 @@
 package setup
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/rkinnovate/dev-setup/internal/capture"
 	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/dotfiles"
+	"github.com/rkinnovate/dev-setup/internal/github"
+	"github.com/rkinnovate/dev-setup/internal/hooks"
+	"github.com/rkinnovate/dev-setup/internal/keychain"
+	"github.com/rkinnovate/dev-setup/internal/pathmgr"
+	"github.com/rkinnovate/dev-setup/internal/policy"
+	"github.com/rkinnovate/dev-setup/internal/profiles"
+	promptpkg "github.com/rkinnovate/dev-setup/internal/prompt"
+	"github.com/rkinnovate/dev-setup/internal/redact"
+	"github.com/rkinnovate/dev-setup/internal/support"
 	"github.com/rkinnovate/dev-setup/internal/ui"
 )
 
@@ -27,36 +40,116 @@ import (
 // Why: Need configurable, verifiable post-install setup
 type SetupExecutor struct {
 	setupConfig *config.SetupConfig
+	toolsConfig *config.ToolsConfig
 	state       *config.State
 	ui          ui.UI
 	dryRun      bool
+	hooks       *hooks.Dispatcher
+	policies    *config.PolicyConfig
+	answers     map[string]string
+	assumeYes   bool
+	failures    []support.Failure
 }
 
 // NewSetupExecutor creates a new setup executor
 // What: Constructor for SetupExecutor with config and state
 // Why: Centralized creation with dependencies
-// Params: setupConfig - loaded setup configuration, state - current state, ui - UI for feedback, dryRun - if true, don't actually configure
+// Params: setupConfig - loaded setup configuration, toolsConfig - tools configuration for tool:<name> dependencies, state - current state, ui - UI for feedback, dryRun - if true, don't actually configure
 // Returns: Configured SetupExecutor instance
-// Example: executor := NewSetupExecutor(cfg, state, ui, false)
-func NewSetupExecutor(setupConfig *config.SetupConfig, state *config.State, ui ui.UI, dryRun bool) *SetupExecutor {
+// Example: executor := NewSetupExecutor(setupCfg, toolsCfg, state, ui, false)
+func NewSetupExecutor(setupConfig *config.SetupConfig, toolsConfig *config.ToolsConfig, state *config.State, ui ui.UI, dryRun bool) *SetupExecutor {
 	return &SetupExecutor{
 		setupConfig: setupConfig,
+		toolsConfig: toolsConfig,
 		state:       state,
 		ui:          ui,
 		dryRun:      dryRun,
 	}
 }
 
+// SetHooks enables lifecycle event hooks (stage_complete, stage_failed, task_failed)
+// Params: dispatcher - hooks dispatcher (nil disables hooks)
+func (se *SetupExecutor) SetHooks(dispatcher *hooks.Dispatcher) {
+	se.hooks = dispatcher
+}
+
+// SetAnswers provides default prompt answers (team defaults merged with remembered personal answers)
+// Params: answers - default answers keyed by prompt env_var (nil = no defaults)
+func (se *SetupExecutor) SetAnswers(answers map[string]string) {
+	se.answers = answers
+}
+
+// SetAssumeYes applies shell dotfile changes without asking (their diffs are still shown)
+// Params: yes - true for --yes
+func (se *SetupExecutor) SetAssumeYes(yes bool) {
+	se.assumeYes = yes
+}
+
+// SetPolicies provides policies.yaml for builtin tasks (homebrew_policy)
+// Params: policies - loaded policy config (nil makes policy builtins fail)
+func (se *SetupExecutor) SetPolicies(policies *config.PolicyConfig) {
+	se.policies = policies
+}
+
 // SetupAll executes all setup tasks from configuration
-// What: Main entry point for post-install configuration
+// What: Main entry point for post-install configuration; runs tasks in unified dependency order
 // Why: Single method to configure entire environment
-// Returns: Error if any required task fails
+// Returns: Error if any required task fails or has an unmet dependency
 // Example: err := executor.SetupAll()
 func (se *SetupExecutor) SetupAll() error {
+	err := se.setupAll()
+	if !se.dryRun {
+		if err != nil {
+			se.hooks.Fire(config.EventStageFailed, map[string]string{"stage": "setup", "error": err.Error()})
+			if recErr := support.RecordFailure(support.FailureSummary{Stage: "setup", Error: err.Error(), Failures: se.failures}); recErr != nil {
+				se.ui.Warning("⚠️  Failed to save failure summary: %v", recErr)
+			}
+		} else {
+			se.hooks.Fire(config.EventStageComplete, map[string]string{"stage": "setup"})
+		}
+	}
+	return err
+}
+
+// setupAll runs the setup tasks; SetupAll wraps it to fire stage hooks
+func (se *SetupExecutor) setupAll() error {
+	graph, err := config.BuildDependencyGraph(se.toolsConfig, se.setupConfig)
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	order, err := graph.Order()
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	tasks := make(map[string]config.SetupTask, len(se.setupConfig.SetupTasks))
+	for _, task := range se.setupConfig.SetupTasks {
+		tasks[task.Name] = task
+	}
+
 	se.ui.Info("⚙️  Starting post-install setup...")
 	se.ui.Info("")
 
+	// Preflight: shells of pending tasks must exist before anything runs
+	var shells []string
 	for _, task := range se.setupConfig.SetupTasks {
+		if !config.IsTaskConfigured(se.state, task.Name) {
+			shells = append(shells, se.setupConfig.ShellFor(task))
+		}
+	}
+	if err := config.CheckShells(shells); err != nil {
+		return fmt.Errorf("preflight failed: %w", err)
+	}
+
+	// Tasks deferred with Ctrl-C at a prompt (by node ID); their dependents are deferred with them
+	deferred := make(map[string]bool)
+
+	for _, node := range order {
+		if node.Kind != config.NodeTask {
+			continue
+		}
+		task := tasks[node.Name]
+
 		// Check if already configured
 		if config.IsTaskConfigured(se.state, task.Name) {
 			se.ui.Info("✓ %s (already configured)", task.Name)
@@ -67,13 +160,55 @@ func (se *SetupExecutor) SetupAll() error {
 
 		if se.dryRun {
 			se.ui.Info("  [DRY RUN] Would configure: %s", task.Name)
+			se.previewDotfile(task)
+			se.previewEditorSettings(task)
+			se.previewRemoteCache(task)
+			se.previewAIAssistant(task)
+			se.previewGitIdentities(task)
 			se.ui.CompleteTask(task.Name)
 			continue
 		}
 
+		// Prerequisites (tools or tasks) must be in place before configuring
+		if missing := se.unmetDependencies(graph, node); len(missing) > 0 {
+			if slices.ContainsFunc(missing, func(id string) bool { return deferred[id] }) {
+				deferred[node.ID] = true
+				se.ui.CancelTask(task.Name)
+				se.ui.Info("  ⏭  Deferred with %s", strings.Join(missing, ", "))
+				continue
+			}
+			err := fmt.Errorf("unmet dependencies: %s", strings.Join(missing, ", "))
+			se.ui.FailTask(task.Name, err)
+			se.fireTaskFailed(task, err)
+
+			if !task.Optional {
+				return fmt.Errorf("required task %s blocked: %w", task.Name, err)
+			}
+
+			se.ui.Warning("⚠️  Skipping optional task %s: %v", task.Name, err)
+			continue
+		}
+
 		// Execute the setup task
 		if err := se.executeTask(task); err != nil {
+			// Ctrl-C at a prompt skips this task only; the run goes on and the task runs again next time
+			if errors.Is(err, promptpkg.ErrDeferred) {
+				deferred[node.ID] = true
+				se.ui.CancelTask(task.Name)
+				se.ui.Info("  ⏭  Deferred; run setup again to finish it")
+				continue
+			}
+			// A manual step not done yet is pending, like a deferred task; tasks that need it wait too
+			if errors.Is(err, ErrManualPending) {
+				deferred[node.ID] = true
+				se.ui.CancelTask(task.Name)
+				se.ui.Info("  📝 Pending (%v); mark it done with 'devsetup manual done %s'", err, task.Name)
+				continue
+			}
+
+			se.runCleanup(task, err)
 			se.ui.FailTask(task.Name, err)
+			se.fireTaskFailed(task, err)
 
 			if !task.Optional {
 				return fmt.Errorf("required task %s failed: %w", task.Name, err)
@@ -101,6 +236,87 @@ func (se *SetupExecutor) SetupAll() error {
 	return nil
 }
 
+// runCleanup runs a task's on_failure command after the task failed
+// What: Runs the command in the task's shell with the failure in DEVSETUP_FAILED_NAME/ERROR
+// Why: A failed task shouldn't leave partial downloads or half-written config behind
+// Params: task - failed task, cause - its error
+// Edge cases: A failing cleanup only warns; the task's own error is what gets reported
+func (se *SetupExecutor) runCleanup(task config.SetupTask, cause error) {
+	if task.OnFailure == nil {
+		return
+	}
+	se.ui.Info("  🧹 Cleaning up after %s...", task.Name)
+
+	ctx, cancel := se.getContext(task.OnFailure.CleanupTimeout())
+	defer cancel()
+	err := se.runShellCommand(ctx, "cleanup-"+task.Name, se.setupConfig.ShellFor(task), task.OnFailure.Command, task.OnFailure.Env(task.Name, cause)...)
+	if err != nil {
+		se.ui.Warning("  ⚠️  Cleanup for %s failed: %v", task.Name, err)
+	}
+}
+
+// Failures returns the tasks that failed in this run, each with its owner
+func (se *SetupExecutor) Failures() []support.Failure {
+	return append([]support.Failure(nil), se.failures...)
+}
+
+// fireTaskFailed fires the task_failed hook for a setup task and notes it for the failure summary
+func (se *SetupExecutor) fireTaskFailed(task config.SetupTask, err error) {
+	failure := support.NewFailure(config.NodeTask, task.Name, !task.Optional, err)
+	failure.Owner = se.setupConfig.OwnerOf(task).String()
+	se.failures = append(se.failures, failure)
+
+	se.hooks.Fire(config.EventTaskFailed, map[string]string{
+		"kind":     config.NodeTask,
+		"name":     task.Name,
+		"error":    err.Error(),
+		"required": strconv.FormatBool(!task.Optional),
+	})
+}
+
+// unmetDependencies lists prerequisites of a task that are not satisfied
+// What: Tools count as met when recorded in state or their check command passes; tasks when configured
+// Why: A task configuring a tool (e.g. gh auth) must not run when the tool failed to install
+// Params: graph - unified dependency graph, node - task node to check
+// Returns: Unmet dependency IDs (e.g. "tool:gh"), empty when all are met
+func (se *SetupExecutor) unmetDependencies(graph *config.DependencyGraph, node *config.DependencyNode) []string {
+	var missing []string
+	for _, id := range node.DependsOn {
+		dep, _ := graph.Node(id)
+		switch dep.Kind {
+		case config.NodeTool:
+			if !config.IsToolInstalled(se.state, dep.Name) && !se.toolCheckPasses(dep.Name) {
+				missing = append(missing, id)
+			}
+		case config.NodeTask:
+			if !config.IsTaskConfigured(se.state, dep.Name) {
+				missing = append(missing, id)
+			}
+		}
+	}
+	return missing
+}
+
+// toolCheckPasses runs a tool's check command
+// What: Detects tools installed outside devsetup (not recorded in state)
+// Params: name - tool name
+// Returns: true if the tool has a check command that exits 0
+func (se *SetupExecutor) toolCheckPasses(name string) bool {
+	if se.toolsConfig == nil {
+		return false
+	}
+
+	for _, tool := range se.toolsConfig.Tools {
+		if tool.Name != name || tool.Check == "" {
+			continue
+		}
+		ctx, cancel := se.getContext(10 * time.Second)
+		defer cancel()
+		return exec.CommandContext(ctx, se.toolsConfig.ShellFor(tool), "-c", tool.Check).Run() == nil
+	}
+	return false
+}
+
 // executeTask executes a single setup task
 // What: Runs one setup task based on its strategy
 // Why: Different tasks need different execution strategies
@@ -114,6 +330,51 @@ func (se *SetupExecutor) executeTask(task config.SetupTask) error {
 		return se.executeLocalOnly(task)
 	case "":
 		// No strategy specified, try to infer from fields
+		if task.Builtin != "" {
+			return se.executeBuiltin(task)
+		}
+		if task.Personalize != nil {
+			return se.executePersonalize(task)
+		}
+		if len(task.Profiles) > 0 {
+			return se.executeProfiles(task)
+		}
+		if task.GitHubAccess != nil {
+			return se.executeGitHubAccess(task)
+		}
+		if task.Postgres != nil {
+			return se.executePostgres(task)
+		}
+		if task.WarmCaches != nil {
+			return se.executeWarmCaches(task)
+		}
+		if len(task.GitIdentities) > 0 {
+			return se.executeGitIdentities(task)
+		}
+		if task.AIAssistant != nil {
+			return se.executeAIAssistant(task)
+		}
+		if task.GitHooks != nil {
+			return se.executeGitHooks(task)
+		}
+		if task.Browser != nil {
+			return se.executeBrowser(task)
+		}
+		if task.RemoteCache != nil {
+			return se.executeRemoteCache(task)
+		}
+		if task.DevTLS != nil {
+			return se.executeDevTLS(task)
+		}
+		if task.JetBrains != nil {
+			return se.executeJetBrains(task)
+		}
+		if task.EditorSettings != nil {
+			return se.executeEditorSettings(task)
+		}
+		if task.Manual != nil {
+			return se.executeManual(task)
+		}
 		if len(task.ZshrcLines) > 0 {
 			return se.executeZshrcConfig(task)
 		}
@@ -129,6 +390,152 @@ func (se *SetupExecutor) executeTask(task config.SetupTask) error {
 	}
 }
 
+// executeBuiltin runs a task implemented by devsetup
+// What: homebrew_policy applies the policies.yaml homebrew baseline by running the fix for each unmet setting;
+// managed_path regenerates the PATH file (see executeManagedPath)
+// Why: The same evaluation backs `devsetup doctor`, so setup and doctor never disagree
+// Params: task - Task with builtin set
+// Returns: Error if policies are unavailable or a fix fails
+func (se *SetupExecutor) executeBuiltin(task config.SetupTask) error {
+	switch task.Builtin {
+	case config.BuiltinManagedPath:
+		return se.executeManagedPath()
+	case config.BuiltinHomebrewPolicy:
+	default:
+		return fmt.Errorf("unknown builtin: %s", task.Builtin)
+	}
+	if se.policies == nil || se.policies.Homebrew == nil {
+		return fmt.Errorf("policies.yaml has no homebrew section")
+	}
+
+	ctx, cancel := se.getContext(2 * time.Minute)
+	defer cancel()
+
+	for _, r := range policy.EvaluateHomebrew(ctx, *se.policies.Homebrew) {
+		if r.Compliant {
+			se.ui.Info("  ✓ %s (%s)", r.Policy.Description, r.Detail)
+			continue
+		}
+		if r.FixCommand == "" {
+			return fmt.Errorf("%s: %s (%s)", r.Policy.Name, r.Detail, r.Guidance)
+		}
+		se.ui.Info("  Applying %s...", r.Policy.Description)
+		se.ui.Diff(r.Diff)
+		if err := se.runCommand(ctx, r.FixCommand); err != nil {
+			return fmt.Errorf("%s: %w", r.Policy.Name, err)
+		}
+	}
+	return nil
+}
+
+// executeManagedPath writes the generated PATH file and makes ~/.zshrc source it
+// What: Regenerates the path file from tools.yaml path entries, then adds or refreshes the ~/.zshrc managed block
+// Why: One ordered, deduplicated PATH instead of an export line per tool
+// Returns: Error if the file can't be written or the .zshrc change is declined
+func (se *SetupExecutor) executeManagedPath() error {
+	if se.toolsConfig == nil {
+		return fmt.Errorf("managed_path needs the tools config")
+	}
+	entries, err := pathmgr.Write(se.toolsConfig)
+	if err != nil {
+		return err
+	}
+	se.ui.Info("  %d PATH entries written to %s", len(entries), pathmgr.FilePath())
+
+	zshrcPath := filepath.Join(os.Getenv("HOME"), ".zshrc")
+	content, err := os.ReadFile(zshrcPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .zshrc: %w", err)
+	}
+	updated := pathmgr.EnsureBlock(string(content))
+	if updated == string(content) {
+		se.ui.Info("  Managed block already present in .zshrc")
+		return nil
+	}
+	return se.writeDotfile(zshrcPath, string(content), updated)
+}
+
+// executeProfiles installs configuration profiles that aren't installed yet
+// What: Opens each missing .mobileconfig, waits for the user to approve it, then confirms its identifier
+// Why: macOS requires user approval in System Settings; checking the identifier proves it was approved
+// Params: task - Task with profiles
+// Returns: Error if profiles can't be listed or a profile is still missing after approval
+func (se *SetupExecutor) executeProfiles(task config.SetupTask) error {
+	ctx, cancel := se.getContext(30 * time.Second)
+	defer cancel()
+
+	installed, err := profiles.Installed(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range task.Profiles {
+		label := p.Description
+		if label == "" {
+			label = p.Identifier
+		}
+		if installed[p.Identifier] {
+			se.ui.Info("  ✓ %s (profile installed)", label)
+			continue
+		}
+		if err := se.approveProfile(ctx, task, label, p.Identifier, os.ExpandEnv(p.Path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// approveProfile opens a profile for installation and waits for the user to approve it
+// What: Hands the file to System Settings, asks the user to press Enter once approved, then checks it is installed
+// Params: ctx - bounds the profiles commands, task - task asking, label - shown name, identifier - PayloadIdentifier,
+// path - .mobileconfig file
+// Returns: Error if the profile can't be opened or isn't installed after the user continues
+func (se *SetupExecutor) approveProfile(ctx context.Context, task config.SetupTask, label, identifier, path string) error {
+	if err := profiles.Open(ctx, path); err != nil {
+		return err
+	}
+	se.ui.Info("  Approve \"%s\" in System Settings → General → Device Management, then press Enter", label)
+	if _, err := promptpkg.Ask(promptpkg.Question{Task: task.Name, Label: "approve profile " + label}); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	installed, err := profiles.Installed(ctx)
+	if err != nil {
+		return err
+	}
+	if !installed[identifier] {
+		return fmt.Errorf("profile %s is not installed (approval pending?)", identifier)
+	}
+	se.ui.Success("  ✓ %s installed", label)
+	return nil
+}
+
+// executeGitHubAccess checks required GitHub org/team access
+// What: Lists every missing membership or SSO authorization with the fix for each
+// Why: Preflight for private clones; reporting all gaps at once saves round trips with org admins
+// Params: task - Task with github_access
+// Returns: Error if any access is missing
+func (se *SetupExecutor) executeGitHubAccess(task config.SetupTask) error {
+	ctx, cancel := se.getContext(60 * time.Second)
+	defer cancel()
+
+	problems, err := github.CheckAccess(ctx, task.GitHubAccess.Orgs, task.GitHubAccess.Teams)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		required := append(append([]string{}, task.GitHubAccess.Orgs...), task.GitHubAccess.Teams...)
+		se.ui.Info("  ✓ GitHub access: %s", strings.Join(required, ", "))
+		return nil
+	}
+
+	for _, p := range problems {
+		se.ui.Warning("  ✗ %s: %s", p.Subject, p.Message)
+		se.ui.Info("    → %s", p.Fix)
+	}
+	return fmt.Errorf("missing GitHub access (%d problem(s)); fix the above and rerun devsetup setup", len(problems))
+}
+
 // executeRemoteFirst tries remote installation first, falls back to local
 // What: Remote-first with local fallback execution strategy
 // Why: Prefer latest remote version, but work offline with local copy
@@ -141,7 +548,7 @@ func (se *SetupExecutor) executeRemoteFirst(task config.SetupTask) error {
 		ctx, cancel := se.getContext(task.Remote.Timeout)
 		defer cancel()
 
-		if err := se.runCommand(ctx, task.Remote.Command); err == nil {
+		if err := se.runTaskCommand(ctx, task, task.Remote.Command); err == nil {
 			se.ui.Success("  ✓ Remote installation succeeded")
 			return nil
 		} else {
@@ -155,7 +562,7 @@ func (se *SetupExecutor) executeRemoteFirst(task config.SetupTask) error {
 		ctx, cancel := se.getContext(task.Local.Timeout)
 		defer cancel()
 
-		if err := se.runCommand(ctx, task.Local.Command); err != nil {
+		if err := se.runTaskCommand(ctx, task, task.Local.Command); err != nil {
 			return fmt.Errorf("both remote and local failed: %w", err)
 		}
 
@@ -176,7 +583,7 @@ func (se *SetupExecutor) executeLocalOnly(task config.SetupTask) error {
 		ctx, cancel := se.getContext(30 * time.Second)
 		defer cancel()
 
-		if err := se.runCommand(ctx, cmd); err != nil {
+		if err := se.runTaskCommand(ctx, task, cmd); err != nil {
 			return fmt.Errorf("command failed: %w", err)
 		}
 	}
@@ -198,39 +605,43 @@ func (se *SetupExecutor) executeZshrcConfig(task config.SetupTask) error {
 	}
 
 	existingContent := string(content)
-	newLines := []string{}
+	newContent, added := appendZshrcLines(existingContent, task.ZshrcLines)
+
+	// If nothing to add, we're done
+	if added == 0 {
+		se.ui.Info("  All lines already present in .zshrc")
+		return nil
+	}
+
+	// Write back (previewed, confirmed, and backed up)
+	if err := se.writeDotfile(zshrcPath, existingContent, newContent); err != nil {
+		return err
+	}
+
+	se.ui.Success("  ✓ Added %d lines to .zshrc", added)
+	return nil
+}
 
-	// Check each line
-	for _, line := range task.ZshrcLines {
-		if !strings.Contains(existingContent, line.Content) {
-			// Add comment and content
+// appendZshrcLines appends the lines (with their comments) that content doesn't contain yet
+// Returns: New content and how many lines were added (0 means content is returned unchanged)
+func appendZshrcLines(content string, lines []config.ZshrcLine) (string, int) {
+	var newLines []string
+	for _, line := range lines {
+		if !strings.Contains(content, line.Content) {
 			if line.Comment != "" {
 				newLines = append(newLines, line.Comment)
 			}
 			newLines = append(newLines, line.Content)
 		}
 	}
-
-	// If nothing to add, we're done
 	if len(newLines) == 0 {
-		se.ui.Info("  All lines already present in .zshrc")
-		return nil
-	}
-
-	// Append new lines
-	newContent := existingContent
-	if !strings.HasSuffix(newContent, "\n") && newContent != "" {
-		newContent += "\n"
+		return content, 0
 	}
-	newContent += "\n" + strings.Join(newLines, "\n") + "\n"
 
-	// Write back
-	if err := os.WriteFile(zshrcPath, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write .zshrc: %w", err)
+	if !strings.HasSuffix(content, "\n") && content != "" {
+		content += "\n"
 	}
-
-	se.ui.Success("  ✓ Added %d lines to .zshrc", len(newLines))
-	return nil
+	return content + "\n" + strings.Join(newLines, "\n") + "\n", len(newLines)
 }
 
 // executeSteps executes multi-step configuration
@@ -264,7 +675,7 @@ func (se *SetupExecutor) executeSteps(task config.SetupTask) error {
 			ctx, cancel := se.getContext(30 * time.Second)
 			defer cancel()
 
-			if err := se.runCommand(ctx, step.Command); err != nil {
+			if err := se.runTaskCommand(ctx, task, step.Command); err != nil {
 				return fmt.Errorf("step %d failed: %w", i+1, err)
 			}
 		}
@@ -280,24 +691,49 @@ func (se *SetupExecutor) executeSteps(task config.SetupTask) error {
 func (se *SetupExecutor) executePrompt(task config.SetupTask) error {
 	prompt := task.Prompt
 
+	// Existing secret values must be masked too (e.g. echoed by later commands)
+	if prompt.Secret {
+		redact.Register(os.Getenv(prompt.EnvVar))
+	}
+
 	// Check if already set and skip_if_set is true
 	if prompt.SkipIfSet && os.Getenv(prompt.EnvVar) != "" {
 		se.ui.Info("  %s already set, skipping prompt", prompt.EnvVar)
 		return nil
 	}
 
+	// Team and remembered answers are offered, never forced; secret defaults are not shown
+	defaultAnswer := se.answers[prompt.EnvVar]
+	if prompt.Secret {
+		redact.Register(defaultAnswer)
+	}
+
 	// Prompt user
 	se.ui.Info("")
 	se.ui.Info("  %s", prompt.Message)
+	switch {
+	case defaultAnswer != "" && prompt.Secret:
+		se.ui.Info("  (press Enter to use the default)")
+	case defaultAnswer != "":
+		se.ui.Info("  (press Enter for default: %s)", defaultAnswer)
+	}
 	se.ui.Info("")
 
-	reader := bufio.NewReader(os.Stdin)
-	value, err := reader.ReadString('\n')
-	if err != nil {
+	value, err := promptpkg.Ask(promptpkg.Question{Task: task.Name, Label: prompt.EnvVar, Default: defaultAnswer, Secret: prompt.Secret})
+	if errors.Is(err, promptpkg.ErrDeferred) {
+		return err
+	}
+	if err != nil && value == "" {
 		return fmt.Errorf("failed to read input: %w", err)
 	}
 
 	value = strings.TrimSpace(value)
+	if value == "" {
+		value = defaultAnswer
+	}
+	if prompt.Secret {
+		redact.Register(value)
+	}
 
 	// If empty and optional, skip
 	if value == "" {
@@ -305,6 +741,13 @@ func (se *SetupExecutor) executePrompt(task config.SetupTask) error {
 		return nil
 	}
 
+	// Non-secret answers are remembered (and synced by 'devsetup sync'); secrets never leave the target file
+	if !prompt.Secret {
+		if err := config.SaveAnswer(prompt.EnvVar, value); err != nil {
+			se.ui.Warning("  ⚠️  Failed to remember answer: %v", err)
+		}
+	}
+
 	// Add to file
 	if prompt.AddTo != "" {
 		filePath := os.ExpandEnv(prompt.AddTo)
@@ -329,8 +772,8 @@ func (se *SetupExecutor) executePrompt(task config.SetupTask) error {
 		}
 		newContent += "\n" + exportLine + "\n"
 
-		if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		if err := se.writeDotfile(filePath, string(content), newContent); err != nil {
+			return err
 		}
 
 		se.ui.Success("  ✓ Added to %s", filePath)
@@ -339,6 +782,136 @@ func (se *SetupExecutor) executePrompt(task config.SetupTask) error {
 	return nil
 }
 
+// writeDotfile previews a change to a dotfile, asks for confirmation, and writes it with a backup
+// What: Shows a unified diff, asks [y/N] unless --yes, then backs up the file and writes the new content
+// Why: Shell startup files are the user's; nothing is changed there without showing what and keeping the old copy
+// Params: path - dotfile, old - current content ("" if missing), new - proposed content
+// Returns: Error if the change is declined or the backup/write fails
+// Edge cases: The diff goes through the UI, so prompted secrets in it are masked
+func (se *SetupExecutor) writeDotfile(path, old, new string) error {
+	display := displayPath(path)
+	se.ui.Diff(dotfiles.Diff(display, old, new))
+	if err := se.confirmChange(display); err != nil {
+		return err
+	}
+
+	if err := dotfiles.Write(path, new); err != nil {
+		return err
+	}
+	if old != "" {
+		se.ui.Info("  Previous %s backed up (restore with 'devsetup dotfiles restore')", display)
+	}
+	return nil
+}
+
+// confirmChange asks whether to apply a change just shown as a diff
+// Params: display - file being changed, as shown in the diff
+// Returns: Error if the change is declined or the prompt fails; nil at once with --yes
+func (se *SetupExecutor) confirmChange(display string) error {
+	if se.assumeYes {
+		return nil
+	}
+	ok, err := promptpkg.Confirm(display, "  Apply this change? [y/N] ")
+	if err != nil {
+		return fmt.Errorf("change to %s: %w", display, err)
+	}
+	if !ok {
+		return fmt.Errorf("change to %s not confirmed (re-run with --yes to apply it)", display)
+	}
+	return nil
+}
+
+// previewDotfile shows the .zshrc change a task would make, for dry runs
+// What: Diffs ~/.zshrc against what a zshrc_lines or managed_path task would write, without writing anything
+// Why: A dry run should show exactly what setup will change in the user's shell startup file
+// Params: task - task to preview (other kinds of tasks show nothing)
+func (se *SetupExecutor) previewDotfile(task config.SetupTask) {
+	if task.Builtin != config.BuiltinManagedPath && (task.Builtin != "" || len(task.ZshrcLines) == 0) {
+		return
+	}
+	zshrcPath := filepath.Join(os.Getenv("HOME"), ".zshrc")
+	content, err := os.ReadFile(zshrcPath)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	updated := pathmgr.EnsureBlock(string(content))
+	if task.Builtin == "" {
+		updated, _ = appendZshrcLines(string(content), task.ZshrcLines)
+	}
+	se.ui.Diff(dotfiles.Diff(displayPath(zshrcPath), string(content), updated))
+}
+
+// displayPath shortens a path under the home directory to ~/...
+func displayPath(path string) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		if rest, ok := strings.CutPrefix(path, home+"/"); ok {
+			return "~/" + rest
+		}
+	}
+	return path
+}
+
+// keychainSecret returns a secret checked against its service, from the keychain or asked for
+// What: Uses the keychain item when the check accepts it; otherwise asks (input hidden), and asks once more when
+// the check rejects the answer; the secret is stored in the keychain only after the check accepted it
+// Why: A stored token that was rotated or revoked is replaced by rerunning setup, and a typo is never saved
+// Params: task - Task asking, service - keychain item, label - question, check - validates a secret (bounded by
+// 15s), rejected - the error check returns for a refused secret
+// Returns: Secret (registered for redaction), error if input fails or the check fails for another reason
+func (se *SetupExecutor) keychainSecret(task config.SetupTask, service, label string, check func(context.Context, string) error, rejected error) (string, error) {
+	secret, err := keychain.Get(service)
+	if err != nil && !errors.Is(err, keychain.ErrNotFound) {
+		return "", err
+	}
+	stored := secret != ""
+	for attempt := 0; ; attempt++ {
+		if secret == "" {
+			se.ui.Info("")
+			se.ui.Info("  Enter the %s (input is hidden):", label)
+			if secret, err = askSecret(task, label); err != nil {
+				return "", err
+			}
+		}
+		ctx, cancel := se.getContext(15 * time.Second)
+		err = check(ctx, secret)
+		cancel()
+		if errors.Is(err, rejected) && attempt == 0 {
+			se.ui.Warning("  ⚠️  The %s was rejected; enter a new one", label)
+			secret, stored = "", false
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		break
+	}
+	if !stored {
+		if err := keychain.Set(service, secret); err != nil {
+			return "", err
+		}
+		se.ui.Info("  Stored in the login keychain (%s)", service)
+	}
+	return secret, nil
+}
+
+// askSecret prompts for a secret with input hidden
+// Returns: Answer (registered for redaction), error if input fails, is deferred, or is empty
+func askSecret(task config.SetupTask, label string) (string, error) {
+	value, err := promptpkg.Ask(promptpkg.Question{Task: task.Name, Label: label, Secret: true})
+	if errors.Is(err, promptpkg.ErrDeferred) {
+		return "", err
+	}
+	value = strings.TrimSpace(value)
+	if err != nil && value == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	if value == "" {
+		return "", fmt.Errorf("no %s entered", label)
+	}
+	redact.Register(value)
+	return value, nil
+}
+
 // editTomlFile edits a TOML configuration file
 // What: Updates a key in a TOML file
 // Why: Common operation for tool configuration (e.g., starship.toml)
@@ -352,18 +925,35 @@ func (se *SetupExecutor) editTomlFile(edit *config.TomlEdit) error {
 	return nil
 }
 
-// runCommand executes a shell command
+// runCommand executes a devsetup-generated command under sh
 // What: Runs shell command with context for timeout
-// Why: Common operation across all strategies
+// Why: Builtin tasks (policy fixes, personalization) build their own sh commands
 // Params: ctx - context for timeout, command - shell command
 // Returns: Error if command fails
 func (se *SetupExecutor) runCommand(ctx context.Context, command string) error {
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Env = os.Environ()
+	return se.runShellCommand(ctx, "setup", config.ShellSh, command)
+}
+
+// runTaskCommand executes a command from setup.yaml under the task's shell
+// Why: Common operation across all strategies; zsh-specific snippets declare shell: zsh
+// Params: ctx - context for timeout, task - task the command belongs to, command - shell command
+// Returns: Error if command fails
+func (se *SetupExecutor) runTaskCommand(ctx context.Context, task config.SetupTask, command string) error {
+	return se.runShellCommand(ctx, task.Name, se.setupConfig.ShellFor(task), command)
+}
+
+// runShellCommand runs command with `<shell> -c`, capturing redacted output (streamed with --verbose)
+// What: Output is also written to ~/.local/share/devsetup/logs/setup-<name>.log; errors quote its tail
+// Params: env - extra variables added to the environment
+func (se *SetupExecutor) runShellCommand(ctx context.Context, name, shell, command string, env ...string) error {
+	out := capture.Open("setup-" + name)
+	defer out.Close()
+
+	cmd := exec.CommandContext(ctx, shell, "-c", command)
+	out.Attach(cmd, os.Stdout, os.Stderr)
+	cmd.Env = append(os.Environ(), env...)
 
-	return cmd.Run()
+	return out.Wrap(cmd.Run())
 }
 
 // getContext creates a context with timeout
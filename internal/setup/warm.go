@@ -0,0 +1,122 @@
+// File: internal/setup/warm.go
+// Purpose: Package manager cache warm-up for setup tasks (pnpm store, uv cache, CocoaPods, Gradle wrapper)
+// Problem: The first build of each main repo on a new machine spent 20 minutes downloading dependencies
+// Role: Executes the warm_caches block of a setup task: detects each repo's lockfiles and runs the command that
+//       fills the matching cache
+// Usage: setup.yaml task with `warm_caches: {repos: [~/code/web]}`; run by SetupExecutor.executeTask
+// Design choices: Warmers are chosen from lockfiles, so one repo list covers JS, Python, iOS, and Android repos;
+//                 commands only download (pnpm fetch, uv sync --frozen, pod install, gradlew --version) and never
+//                 rewrite lockfiles; one failing warmer doesn't stop the others
+// Assumptions: Repos are cloned by an earlier task (missing ones are skipped); pnpm/uv/pod come from tools.yaml
+
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// defaultWarmTimeout bounds each warmer when the task sets no timeout
+const defaultWarmTimeout = 20 * time.Minute
+
+// warmer is one cache-filling command detected in a repo
+type warmer struct {
+	kind string
+	dir  string
+	args []string
+}
+
+// executeWarmCaches runs every detected warmer of the task's repos
+// Params: task - Task with warm_caches set
+// Returns: Error listing the warmers that failed (after all of them ran)
+func (se *SetupExecutor) executeWarmCaches(task config.SetupTask) error {
+	wc := task.WarmCaches
+	timeout := wc.Timeout
+	if timeout == 0 {
+		timeout = defaultWarmTimeout
+	}
+
+	var failed []string
+	for _, repo := range wc.Repos {
+		dir := config.ExpandPath(repo)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			se.ui.Info("  - %s not cloned yet, skipping", repo)
+			continue
+		}
+
+		warmers := detectWarmers(dir, wc.Only)
+		if len(warmers) == 0 {
+			se.ui.Info("  - %s: no lockfiles to warm from", repo)
+			continue
+		}
+		for _, w := range warmers {
+			se.ui.Info("  Warming %s cache from %s...", w.kind, w.dir)
+			ctx, cancel := se.getContext(timeout)
+			err := runWarmer(ctx, w)
+			cancel()
+			if err != nil {
+				se.ui.Warning("  ⚠️  %s in %s failed: %v", w.kind, w.dir, err)
+				failed = append(failed, fmt.Sprintf("%s (%s)", w.kind, filepath.Base(w.dir)))
+				continue
+			}
+			se.ui.Success("  ✓ %s cache warm (%s)", w.kind, filepath.Base(w.dir))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("cache warm-up failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// detectWarmers returns the warmers a repo's lockfiles call for
+// Params: dir - repo directory, only - allowed warmer kinds (empty = all)
+// Returns: Warmers in pnpm, uv, cocoapods, gradle order
+// Edge cases: CocoaPods and Gradle are also looked for under ios/ and android/ (React Native layouts)
+func detectWarmers(dir string, only []string) []warmer {
+	allowed := func(kind string) bool { return len(only) == 0 || slices.Contains(only, kind) }
+	exists := func(parts ...string) bool {
+		_, err := os.Stat(filepath.Join(append([]string{dir}, parts...)...))
+		return err == nil
+	}
+
+	var warmers []warmer
+	if allowed(config.WarmerPnpm) && exists("pnpm-lock.yaml") {
+		warmers = append(warmers, warmer{config.WarmerPnpm, dir, []string{"pnpm", "fetch"}})
+	}
+	if allowed(config.WarmerUv) && exists("uv.lock") {
+		warmers = append(warmers, warmer{config.WarmerUv, dir, []string{"uv", "sync", "--frozen", "--no-install-project"}})
+	}
+	for _, sub := range []string{"", "ios"} {
+		if allowed(config.WarmerCocoapods) && exists(sub, "Podfile.lock") {
+			warmers = append(warmers, warmer{config.WarmerCocoapods, filepath.Join(dir, sub), []string{"pod", "install"}})
+		}
+	}
+	for _, sub := range []string{"", "android"} {
+		if allowed(config.WarmerGradle) && exists(sub, "gradle", "wrapper", "gradle-wrapper.properties") && exists(sub, "gradlew") {
+			warmers = append(warmers, warmer{config.WarmerGradle, filepath.Join(dir, sub), []string{"./gradlew", "--version"}})
+		}
+	}
+	return warmers
+}
+
+// runWarmer runs one warmer in its directory, logging output like other setup commands
+func runWarmer(ctx context.Context, w warmer) error {
+	out := capture.Open(fmt.Sprintf("setup-warm-%s-%s", w.kind, filepath.Base(w.dir)))
+	defer out.Close()
+
+	cmd := exec.CommandContext(ctx, w.args[0], w.args[1:]...)
+	cmd.Dir = w.dir
+	cmd.Env = os.Environ()
+	out.Attach(cmd, nil, nil)
+	return out.Wrap(cmd.Run())
+}
@@ -0,0 +1,40 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectWarmers(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"pnpm-lock.yaml", "uv.lock", "ios/Podfile.lock", "android/gradlew", "android/gradle/wrapper/gradle-wrapper.properties"} {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kinds := func(ws []warmer) []string {
+		var out []string
+		for _, w := range ws {
+			out = append(out, w.kind+":"+filepath.Base(w.dir))
+		}
+		return out
+	}
+	base := filepath.Base(dir)
+
+	if got, want := kinds(detectWarmers(dir, nil)), []string{"pnpm:" + base, "uv:" + base, "cocoapods:ios", "gradle:android"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("detectWarmers = %v, want %v", got, want)
+	}
+	if got, want := kinds(detectWarmers(dir, []string{"uv"})), []string{"uv:" + base}; !reflect.DeepEqual(got, want) {
+		t.Errorf("detectWarmers(only uv) = %v, want %v", got, want)
+	}
+	if got := detectWarmers(t.TempDir(), nil); len(got) != 0 {
+		t.Errorf("empty repo should have no warmers, got %v", kinds(got))
+	}
+}
@@ -0,0 +1,396 @@
+// File: internal/share/share.go
+// Purpose: Peer-to-peer Homebrew download sharing for onboarding events
+// Problem: A room of new Macs onboarding together downloaded the same bottles from upstream once per laptop
+// Role: One machine serves its Homebrew downloads directory on the LAN; others discover it via Bonjour and
+//       copy the files into their own Homebrew cache before installing
+// Usage: devsetup share serve (seeder); devsetup share pull / devsetup install --from-peers (others)
+// Design choices: Homebrew names cached downloads by a hash of the source URL, so files copied verbatim are hits
+//                 on the receiving Mac; a file is pulled only when its checksum is one Homebrew itself records for
+//                 a declared package (or a dependency), since the peer's manifest only proves the transfer, and
+//                 any LAN host can advertise; discovery uses the macOS dns-sd tool (no Bonjour library dependency)
+// Assumptions: Plain HTTP on the LAN; casks with sha256 :no_check are never pulled, since nothing vouches for them
+
+package share
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/download"
+)
+
+// ServiceType is the Bonjour service type advertised by share serve
+const ServiceType = "_devsetup-brew._tcp"
+
+// DefaultPort is the port share serve listens on
+const DefaultPort = 7781
+
+// reachedPattern matches the address line of `dns-sd -L` output
+var reachedPattern = regexp.MustCompile(`can be reached at ([^\s:]+?)\.?:(\d+)`)
+
+// Manifest lists the shared files
+type Manifest struct {
+	// Files are the shared downloads, sorted by name
+	Files []File `json:"files"`
+}
+
+// File is one shared download
+type File struct {
+	// Name is the file name in the Homebrew downloads directory
+	Name string `json:"name"`
+
+	// Size is the file size in bytes
+	Size int64 `json:"size"`
+
+	// SHA256 is the hex checksum of the file
+	SHA256 string `json:"sha256"`
+}
+
+// DownloadsDir returns the Homebrew downloads cache directory
+// Returns: $(brew --cache)/downloads, error if brew is unavailable
+func DownloadsDir(ctx context.Context) (string, error) {
+	output, err := exec.CommandContext(ctx, "brew", "--cache").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate Homebrew cache: %w", err)
+	}
+	return filepath.Join(strings.TrimSpace(string(output)), "downloads"), nil
+}
+
+// BuildManifest checksums every complete download in dir
+// Params: dir - Homebrew downloads directory
+// Returns: Manifest, error if the directory can't be read
+// Edge cases: In-progress downloads (*.incomplete) and subdirectories are skipped
+func BuildManifest(dir string) (*Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	m := &Manifest{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), ".incomplete") {
+			continue
+		}
+		sum, size, err := fileSHA256(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, File{Name: entry.Name(), Size: size, SHA256: sum})
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Name < m.Files[j].Name })
+	return m, nil
+}
+
+// Handler serves a manifest and its files
+// What: GET /manifest.json and GET /files/<name>
+// Why: Pullers fetch the manifest once, then only the files they lack
+// Params: dir - directory the manifest was built from, m - manifest
+// Returns: HTTP handler
+// Edge cases: Only files listed in the manifest are served (no path traversal)
+func Handler(dir string, m *Manifest) http.Handler {
+	listed := make(map[string]bool, len(m.Files))
+	for _, f := range m.Files {
+		listed[f.Name] = true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(m)
+	})
+	mux.HandleFunc("GET /files/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if !listed[name] {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(dir, name))
+	})
+	return mux
+}
+
+// Serve shares dir on the LAN until ctx is cancelled
+// What: Builds the manifest, listens on port, and advertises the service over Bonjour
+// Params: ctx - stops serving, dir - downloads directory, port - TCP port, name - Bonjour instance name
+// Returns: Number of files shared via onReady, error if the server fails
+func Serve(ctx context.Context, dir string, port int, name string, onReady func(files int)) error {
+	m, err := BuildManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: Handler(dir, m), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	// dns-sd -R registers the service for as long as it runs
+	advertise := exec.CommandContext(ctx, "dns-sd", "-R", name, ServiceType, "local", fmt.Sprint(port))
+	if err := advertise.Start(); err != nil {
+		return fmt.Errorf("failed to advertise via Bonjour: %w", err)
+	}
+	defer func() { _ = advertise.Wait() }()
+
+	if onReady != nil {
+		onReady(len(m.Files))
+	}
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Discover finds peers advertising ServiceType
+// Params: ctx - cancellation, wait - how long to browse and resolve
+// Returns: Peer base URLs (e.g. "http://alices-mbp.local:7781")
+func Discover(ctx context.Context, wait time.Duration) ([]string, error) {
+	return Browse(ctx, ServiceType, wait)
+}
+
+// Browse finds HTTP services advertising a Bonjour service type on the LAN
+// What: Browses with `dns-sd -B`, then resolves each instance with `dns-sd -L`
+// Why: Shared by share peers and office Homebrew caches (installer), without a Bonjour library dependency
+// Params: ctx - cancellation, serviceType - e.g. "_devsetup-brew._tcp", wait - how long to browse and resolve
+// Returns: Base URLs (e.g. "http://alices-mbp.local:7781"), in the order instances appeared
+func Browse(ctx context.Context, serviceType string, wait time.Duration) ([]string, error) {
+	browse, err := runFor(ctx, wait, "dns-sd", "-B", serviceType, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []string
+	for _, instance := range parseBrowse(browse, serviceType) {
+		resolved, err := runFor(ctx, wait, "dns-sd", "-L", instance, serviceType, "local")
+		if err != nil {
+			return nil, err
+		}
+		if m := reachedPattern.FindStringSubmatch(resolved); m != nil {
+			peers = append(peers, fmt.Sprintf("http://%s:%s", m[1], m[2]))
+		}
+	}
+	return peers, nil
+}
+
+// PullResult is what a pull copied
+type PullResult struct {
+	// Files and Bytes are the downloads copied into the cache
+	Files int
+	Bytes int64
+
+	// Untrusted counts peer files skipped because Homebrew doesn't record their checksum
+	Untrusted int
+}
+
+// Pull copies the files dir lacks from a peer
+// What: Fetches the peer manifest, downloads missing files Homebrew vouches for, verifies SHA256, then moves them
+// into dir
+// Params: ctx - cancellation, peer - base URL, dir - local downloads directory, trusted - Checksums result
+// Returns: What was copied, error if the manifest can't be fetched or a file fails verification
+// Edge cases: Files already present with the same size are skipped, as are names that aren't plain file names;
+// a failed file is removed, never kept
+func Pull(ctx context.Context, peer, dir string, trusted map[string]bool) (PullResult, error) {
+	var result PullResult
+	var m Manifest
+	var buf bytes.Buffer
+	d := download.New(nil)
+	if err := d.Copy(ctx, &buf, peer+"/manifest.json"); err != nil {
+		return result, fmt.Errorf("failed to fetch manifest from %s: %w", peer, err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		return result, fmt.Errorf("invalid manifest from %s: %w", peer, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return result, err
+	}
+
+	for _, f := range m.Files {
+		if !plainName(f.Name) {
+			continue
+		}
+		if !trusted[strings.ToLower(f.SHA256)] {
+			result.Untrusted++
+			continue
+		}
+		dest := filepath.Join(dir, f.Name)
+		if info, err := os.Stat(dest); err == nil && info.Size() == f.Size {
+			continue
+		}
+
+		tmp := dest + ".peer"
+		if err := d.ToFile(ctx, peer+"/files/"+f.Name, tmp); err != nil {
+			return result, fmt.Errorf("failed to copy %s: %w", f.Name, err)
+		}
+		sum, size, err := fileSHA256(tmp)
+		if err != nil || sum != strings.ToLower(f.SHA256) {
+			_ = os.Remove(tmp)
+			return result, fmt.Errorf("checksum mismatch for %s from %s", f.Name, peer)
+		}
+		if err := os.Rename(tmp, dest); err != nil {
+			return result, err
+		}
+		result.Files++
+		result.Bytes += size
+	}
+	return result, nil
+}
+
+// Checksums returns the download checksums Homebrew records for packages and their dependencies
+// What: Bottle SHA256s of formulas (every OS tag) and cask SHA256s (every variation), from `brew info --json=v2`
+// Why: Pulled files are trusted only when brew vouches for their checksum, not the peer that sent them
+// Params: ctx - context for the brew calls, formulas/casks - declared package names
+// Returns: Set of lower-case hex checksums, error if brew fails
+// Edge cases: Formula dependencies are followed until none are new; casks with sha256 :no_check add nothing
+func Checksums(ctx context.Context, formulas, casks []string) (map[string]bool, error) {
+	trusted := make(map[string]bool)
+	if len(casks) > 0 {
+		out, err := exec.CommandContext(ctx, "brew", append([]string{"info", "--json=v2", "--cask"}, casks...)...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("brew info --cask failed: %w", err)
+		}
+		if _, err := parseChecksums(out, trusted); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for len(formulas) > 0 {
+		var batch []string
+		for _, name := range formulas {
+			if !seen[name] {
+				seen[name] = true
+				batch = append(batch, name)
+			}
+		}
+		if len(batch) == 0 {
+			break
+		}
+		out, err := exec.CommandContext(ctx, "brew", append([]string{"info", "--json=v2", "--formula"}, batch...)...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("brew info --formula failed: %w", err)
+		}
+		if formulas, err = parseChecksums(out, trusted); err != nil {
+			return nil, err
+		}
+	}
+	return trusted, nil
+}
+
+// parseChecksums adds the checksums in `brew info --json=v2` output to trusted
+// Returns: Dependencies of the formulas in the output
+func parseChecksums(data []byte, trusted map[string]bool) ([]string, error) {
+	var info struct {
+		Formulae []struct {
+			Dependencies []string `json:"dependencies"`
+			Bottle       struct {
+				Stable struct {
+					Files map[string]struct {
+						SHA256 string `json:"sha256"`
+					} `json:"files"`
+				} `json:"stable"`
+			} `json:"bottle"`
+		} `json:"formulae"`
+		Casks []struct {
+			SHA256     string `json:"sha256"`
+			Variations map[string]struct {
+				SHA256 string `json:"sha256"`
+			} `json:"variations"`
+		} `json:"casks"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("invalid brew info output: %w", err)
+	}
+
+	add := func(sum string) {
+		if len(sum) == sha256.Size*2 {
+			trusted[strings.ToLower(sum)] = true
+		}
+	}
+	var deps []string
+	for _, f := range info.Formulae {
+		for _, file := range f.Bottle.Stable.Files {
+			add(file.SHA256)
+		}
+		deps = append(deps, f.Dependencies...)
+	}
+	for _, c := range info.Casks {
+		add(c.SHA256)
+		for _, v := range c.Variations {
+			add(v.SHA256)
+		}
+	}
+	return deps, nil
+}
+
+// plainName reports whether a manifest name is a plain file name (no directories, not "." or "..")
+func plainName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`) && filepath.Base(name) == name
+}
+
+// parseBrowse extracts instance names of serviceType from `dns-sd -B` output
+// Returns: Unique instance names of "Add" events, in order
+func parseBrowse(output, serviceType string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		// Timestamp A/R Flags if Domain ServiceType InstanceName...
+		if len(fields) < 7 || fields[1] != "Add" || !strings.HasPrefix(fields[5], serviceType) {
+			continue
+		}
+		name := strings.Join(fields[6:], " ")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// runFor runs a long-lived command for a fixed time and returns what it printed
+// Why: dns-sd browse/lookup never exit on their own
+func runFor(ctx context.Context, wait time.Duration, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	_ = cmd.Wait()
+	return out.String(), nil
+}
+
+// fileSHA256 returns the hex SHA256 and size of a file
+func fileSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,98 @@
+package share
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPull(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "abc--jq--1.7.bottle.tar.gz"), []byte("bottle"), 0644)
+	os.WriteFile(filepath.Join(src, "def--gh.incomplete"), []byte("partial"), 0644)
+	os.WriteFile(filepath.Join(src, "fed--evil-cask.dmg"), []byte("untrusted"), 0644)
+
+	m, err := BuildManifest(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Files) != 2 {
+		t.Fatalf("manifest = %+v, want only the complete downloads", m.Files)
+	}
+	// Homebrew vouches for the bottle only
+	trusted := map[string]bool{m.Files[0].SHA256: true}
+
+	srv := httptest.NewServer(Handler(src, m))
+	defer srv.Close()
+
+	dst := t.TempDir()
+	result, err := Pull(context.Background(), srv.URL, dst, trusted)
+	if err != nil || result.Files != 1 || result.Bytes != 6 || result.Untrusted != 1 {
+		t.Fatalf("Pull() = %+v, %v", result, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "abc--jq--1.7.bottle.tar.gz")); string(data) != "bottle" {
+		t.Errorf("pulled content = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "fed--evil-cask.dmg")); !os.IsNotExist(err) {
+		t.Error("untrusted file was pulled")
+	}
+
+	// Second pull has nothing to copy
+	if result, err := Pull(context.Background(), srv.URL, dst, trusted); err != nil || result.Files != 0 {
+		t.Errorf("second Pull() = %+v, %v", result, err)
+	}
+
+	// A file that doesn't match the manifest checksum is rejected and not kept
+	m.Files[0].SHA256 = m.Files[1].SHA256
+	trusted[m.Files[1].SHA256] = true
+	other := t.TempDir()
+	if _, err := Pull(context.Background(), srv.URL, other, trusted); err == nil {
+		t.Error("Pull() accepted a checksum mismatch")
+	}
+	if entries, _ := os.ReadDir(other); len(entries) != 0 {
+		t.Errorf("mismatched file kept: %v", entries)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	bottle, cask := strings.Repeat("a", 64), strings.Repeat("B", 64)
+	data := `{"formulae":[{"name":"gh","dependencies":["libyaml"],"bottle":{"stable":{"files":{"arm64_sonoma":{"sha256":"` + bottle + `"}}}}}],
+		"casks":[{"token":"zed","sha256":"` + cask + `","variations":{}},{"token":"chrome","sha256":"no_check"}]}`
+	trusted := make(map[string]bool)
+	deps, err := parseChecksums([]byte(data), trusted)
+	if err != nil || len(deps) != 1 || deps[0] != "libyaml" {
+		t.Fatalf("parseChecksums() deps = %v, %v", deps, err)
+	}
+	if len(trusted) != 2 || !trusted[bottle] || !trusted[strings.ToLower(cask)] {
+		t.Errorf("trusted = %v, want the bottle and the pinned cask", trusted)
+	}
+}
+
+func TestPlainName(t *testing.T) {
+	for name, want := range map[string]bool{"abc--jq--1.7.bottle.tar.gz": true, ".": false, "..": false, "": false, "a/b": false, `..\x`: false} {
+		if got := plainName(name); got != want {
+			t.Errorf("plainName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseBrowse(t *testing.T) {
+	output := `Browsing for _devsetup-brew._tcp.local
+Timestamp     A/R    Flags  if Domain               Service Type         Instance Name
+10:00:00.123  Add        3   4 local.               _devsetup-brew._tcp. devsetup on alices-mbp
+10:00:00.124  Add        2   6 local.               _devsetup-brew._tcp. devsetup on alices-mbp
+10:00:01.000  Rmv        0   4 local.               _devsetup-brew._tcp. devsetup on bobs-mbp
+`
+	names := parseBrowse(output, ServiceType)
+	if len(names) != 1 || names[0] != "devsetup on alices-mbp" {
+		t.Errorf("parseBrowse() = %q", names)
+	}
+
+	m := reachedPattern.FindStringSubmatch("devsetup\\032on\\032alices-mbp._devsetup-brew._tcp.local. can be reached at alices-mbp.local.:7781 (interface 4)")
+	if m == nil || m[1] != "alices-mbp.local" || m[2] != "7781" {
+		t.Errorf("reachedPattern = %q", m)
+	}
+}
@@ -0,0 +1,142 @@
+// File: internal/status/checklist.go
+// Purpose: Markdown onboarding checklist of this machine (devsetup export --format checklist)
+// Problem: Team leads and onboarding buddies tracked a new hire's progress in a hand-kept doc that never matched
+//          what devsetup had actually done, and didn't separate automated steps from manual ones
+// Role: Renders every tool and setup task as a checkbox with this machine's status, automated items apart from
+//       manual steps, so the file can be pasted into an onboarding ticket or shared as is
+// Usage: md := reporter.Checklist(time.Now())
+// Design choices: Same done rules as ShowStatus (state, then live checks), so checklist and status never disagree;
+//                 open items say which command finishes them; plain GitHub-flavored markdown task lists render in
+//                 GitHub, Jira, Notion, and Slack canvases
+// Assumptions: Run on the new hire's machine; USER and the hostname identify it
+
+package status
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/setup"
+)
+
+// checklistItem is one checkbox line and the indented lines under it
+type checklistItem struct {
+	done   bool
+	line   string
+	detail []string
+}
+
+// Checklist renders the onboarding checklist as markdown
+// What: A header with machine and totals, then Tools and Configuration (automated) and Manual steps sections
+// Why: Lets a team lead see what's done and what's left without access to the machine
+// Params: now - generation time shown in the header
+// Returns: Markdown document
+func (r *Reporter) Checklist(now time.Time) string {
+	var tools, tasks, manual []checklistItem
+	for _, tool := range r.currentTools() {
+		tools = append(tools, r.toolItem(tool))
+	}
+	for _, task := range r.setupConfig.SetupTasks {
+		if task.Manual != nil {
+			manual = append(manual, r.manualItem(task))
+			continue
+		}
+		tasks = append(tasks, r.taskItem(task))
+	}
+	_ = r.checks.Save()
+
+	done := countDone(tools) + countDone(tasks) + countDone(manual)
+	total := len(tools) + len(tasks) + len(manual)
+
+	machine, _ := os.Hostname()
+	if user := os.Getenv("USER"); user != "" {
+		machine = user + "@" + machine
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Onboarding checklist: %s\n\n", machine)
+	fmt.Fprintf(&b, "Generated %s — %d/%d done.\n", now.Format("2006-01-02 15:04"), done, total)
+	writeChecklistSection(&b, "Tools (automated: devsetup install)", tools)
+	writeChecklistSection(&b, "Configuration (automated: devsetup setup)", tasks)
+	writeChecklistSection(&b, "Manual steps", manual)
+	return b.String()
+}
+
+// toolItem checks off an installed tool
+func (r *Reporter) toolItem(tool config.Tool) checklistItem {
+	item := checklistItem{line: "**" + tool.Name + "**" + suffix(tool.Description)}
+	if toolState, ok := r.state.Installed[tool.Name]; ok {
+		item.done = true
+		if toolState.Version != "" {
+			item.line += fmt.Sprintf(" (%s)", strings.TrimSpace(toolState.Version))
+		}
+	} else if r.isToolActuallyInstalled(tool) {
+		item.done = true
+	} else if !tool.Required {
+		item.line += " (optional)"
+	}
+	return item
+}
+
+// taskItem checks off a configured setup task
+func (r *Reporter) taskItem(task config.SetupTask) checklistItem {
+	return checklistItem{
+		done: r.state.Configured[task.Name] || r.isTaskActuallyConfigured(task),
+		line: "**" + task.Name + "**" + suffix(task.Description),
+	}
+}
+
+// manualItem checks off a manual step; an open one lists its instructions
+func (r *Reporter) manualItem(task config.SetupTask) checklistItem {
+	item := checklistItem{line: "**" + task.Name + "**"}
+	if at, ok := r.state.Acknowledged[task.Name]; ok {
+		item.done = true
+		item.line += " (done " + at.Format(time.DateOnly) + ")"
+		return item
+	}
+	if r.state.Configured[task.Name] || r.isTaskActuallyConfigured(task) {
+		item.done = true
+		return item
+	}
+	item.detail = append(setup.ManualInstructions(task), fmt.Sprintf("Mark done: `devsetup manual done %s`", task.Name))
+	return item
+}
+
+// writeChecklistSection writes one "## heading (done/total)" task list; empty sections are left out
+func writeChecklistSection(b *strings.Builder, heading string, items []checklistItem) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s — %d/%d\n\n", heading, countDone(items), len(items))
+	for _, item := range items {
+		box := " "
+		if item.done {
+			box = "x"
+		}
+		fmt.Fprintf(b, "- [%s] %s\n", box, item.line)
+		for _, line := range item.detail {
+			fmt.Fprintf(b, "  - %s\n", line)
+		}
+	}
+}
+
+// countDone counts checked items
+func countDone(items []checklistItem) int {
+	n := 0
+	for _, item := range items {
+		if item.done {
+			n++
+		}
+	}
+	return n
+}
+
+// suffix formats a description after a name (" — description", or nothing)
+func suffix(description string) string {
+	if description == "" {
+		return ""
+	}
+	return " — " + description
+}
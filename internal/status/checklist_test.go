@@ -0,0 +1,39 @@
+package status
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestChecklist(t *testing.T) {
+	tools := &config.ToolsConfig{Tools: []config.Tool{
+		{Name: "git", Description: "Version control", Required: true},
+		{Name: "zed"},
+	}}
+	setupCfg := &config.SetupConfig{SetupTasks: []config.SetupTask{
+		{Name: "git-config", Description: "Configure git"},
+		{Name: "vpn-access", Manual: &config.ManualConfig{Instructions: "Open an IT ticket", URL: "https://it.example.com/vpn"}},
+		{Name: "slack-invite", Manual: &config.ManualConfig{Instructions: "Accept the Slack invite"}},
+	}}
+	state := &config.State{
+		Installed:    map[string]config.ToolState{"git": {Version: "2.44.0"}},
+		Configured:   map[string]bool{"slack-invite": true},
+		Acknowledged: map[string]time.Time{"slack-invite": time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+	}
+
+	md := NewReporter(tools, setupCfg, state, nil).Checklist(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	for _, want := range []string{
+		"Generated 2026-10-17 12:00 — 2/5 done.",
+		"## Tools (automated: devsetup install) — 1/2\n\n- [x] **git** — Version control (2.44.0)\n- [ ] **zed** (optional)\n",
+		"## Configuration (automated: devsetup setup) — 0/1\n\n- [ ] **git-config** — Configure git\n",
+		"- [ ] **vpn-access**\n  - Open an IT ticket\n  - → https://it.example.com/vpn\n  - Mark done: `devsetup manual done vpn-access`\n",
+		"- [x] **slack-invite** (done 2026-10-01)\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Checklist() missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// File: internal/status/menubar.go
+// Purpose: Menu bar companion output for xbar / SwiftBar
+// Problem: Onboarding progress was only visible while a terminal with devsetup status was open
+// Role: Renders status in the xbar/SwiftBar plugin text format and installs the plugin script
+// Usage: devsetup status --menubar (plugin output); devsetup status --menubar-plugin <dir> (install plugin)
+// Design choices: No GUI dependency - xbar and SwiftBar run a script on an interval and render its stdout;
+//                 drift is the cheap checksum comparison (no version commands) so refreshes stay fast
+// Assumptions: xbar/SwiftBar installed by the user; plugin refresh interval encoded in the file name
+
+package status
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// menubarPluginName is the plugin file name; "5m" is the xbar/SwiftBar refresh interval
+const menubarPluginName = "devsetup.5m.sh"
+
+// WriteMenubar renders status in xbar/SwiftBar plugin format
+// What: Title line (icon + done/total), "---", then a dropdown with progress, drift, and actions
+// Why: Lets developers watch onboarding progress from the menu bar
+// Params: w - destination (stdout when run as a plugin), executable - devsetup path used for menu actions
+// Returns: Write error
+// Example: r.WriteMenubar(os.Stdout, "/usr/local/bin/devsetup")
+func (r *Reporter) WriteMenubar(w io.Writer, executable string) error {
+	s := r.Snapshot()
+	done := s.ToolsDone + s.TasksDone
+	total := s.ToolsTotal + s.TasksTotal
+
+	var lines []string
+	switch {
+	case len(s.Drifted) > 0:
+		lines = append(lines, fmt.Sprintf("🛠 %d/%d ⚠️", done, total))
+	case s.Complete():
+		lines = append(lines, "🛠 ✓")
+	default:
+		lines = append(lines, fmt.Sprintf("🛠 %d/%d", done, total))
+	}
+	lines = append(lines, "---")
+
+	lines = append(lines,
+		fmt.Sprintf("Tools: %d/%d installed", s.ToolsDone, s.ToolsTotal),
+		fmt.Sprintf("Setup: %d/%d configured", s.TasksDone, s.TasksTotal),
+	)
+	if !s.LastRun.IsZero() {
+		lines = append(lines, "Last run: "+s.LastRun.Local().Format("2006-01-02 15:04")+" | color=gray")
+	}
+
+	if len(s.Missing) > 0 {
+		lines = append(lines, "---", fmt.Sprintf("Remaining (%d)", len(s.Missing)))
+		for _, name := range s.Missing {
+			lines = append(lines, "--"+name)
+		}
+	}
+
+	if len(s.Drifted) > 0 {
+		lines = append(lines, "---", fmt.Sprintf("⚠️ Drift detected (%d) | color=orange", len(s.Drifted)))
+		for _, name := range s.Drifted {
+			lines = append(lines, "--"+name+" binary changed since install")
+		}
+	}
+
+	lines = append(lines, "---")
+	if s.ToolsDone < s.ToolsTotal {
+		lines = append(lines, menubarAction("Install missing tools", executable, "install"))
+	}
+	if s.TasksDone < s.TasksTotal {
+		lines = append(lines, menubarAction("Run setup", executable, "setup"))
+	}
+	lines = append(lines,
+		menubarAction("Verify (deep)", executable, "verify", "--deep"),
+		"Refresh | refresh=true",
+	)
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// menubarAction formats a dropdown item that runs devsetup in a terminal
+func menubarAction(title, executable string, args ...string) string {
+	line := fmt.Sprintf("%s | bash=%q terminal=true", title, executable)
+	for i, arg := range args {
+		line += fmt.Sprintf(" param%d=%s", i+1, arg)
+	}
+	return line + " refresh=true"
+}
+
+// InstallMenubarPlugin writes the xbar/SwiftBar plugin script
+// What: Creates <dir>/devsetup.5m.sh which runs `devsetup status --menubar` from workDir
+// Why: One command to enable the menu bar companion
+// Params: dir - plugin directory, executable - devsetup binary path, workDir - directory containing configs/
+// Returns: Path of the written plugin, error if it couldn't be written
+// Example: path, err := InstallMenubarPlugin("~/Library/Application Support/SwiftBar/Plugins", exe, cwd)
+func InstallMenubarPlugin(dir, executable, workDir string) (string, error) {
+	dir = config.ExpandPath(dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create plugin dir: %w", err)
+	}
+
+	script := fmt.Sprintf(`#!/bin/bash
+# <xbar.title>devsetup</xbar.title>
+# <xbar.desc>Developer environment onboarding progress and drift</xbar.desc>
+# <swiftbar.hideRunInTerminal>true</swiftbar.hideRunInTerminal>
+cd %q || exit 0
+exec %q status --menubar
+`, workDir, executable)
+
+	path := filepath.Join(dir, menubarPluginName)
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return "", fmt.Errorf("failed to write plugin: %w", err)
+	}
+	return path, nil
+}
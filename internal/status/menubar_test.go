@@ -0,0 +1,67 @@
+package status
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+func TestWriteMenubar(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	binary := filepath.Join(t.TempDir(), "git")
+	if err := os.WriteFile(binary, []byte("changed"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tools := &config.ToolsConfig{Tools: []config.Tool{{Name: "git"}, {Name: "zed", Check: "false"}}}
+	setupCfg := &config.SetupConfig{SetupTasks: []config.SetupTask{{Name: "git-config"}}}
+	state := &config.State{
+		Installed:  map[string]config.ToolState{"git": {Path: binary, Checksum: "sha256:recorded-at-install"}},
+		Configured: map[string]bool{"git-config": true},
+	}
+
+	var buf bytes.Buffer
+	if err := NewReporter(tools, setupCfg, state, nil).WriteMenubar(&buf, "/usr/local/bin/devsetup"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if title, _, _ := strings.Cut(out, "\n"); title != "🛠 2/3 ⚠️" {
+		t.Errorf("title = %q, want progress with a drift warning", title)
+	}
+	for _, want := range []string{
+		"Tools: 1/2 installed\n",
+		"--tool: zed\n",
+		"⚠️ Drift detected (1) | color=orange\n--git binary changed since install\n",
+		`Install missing tools | bash="/usr/local/bin/devsetup" terminal=true param1=install refresh=true` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("menu bar output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Run setup") {
+		t.Errorf("offers setup with every task configured:\n%s", out)
+	}
+}
+
+func TestInstallMenubarPlugin(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Plugins")
+	path, err := InstallMenubarPlugin(dir, "/usr/local/bin/devsetup", "/Users/dev/dev-setup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "devsetup.5m.sh") {
+		t.Errorf("plugin path = %s", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("plugin not executable: %v", err)
+	}
+	script, _ := os.ReadFile(path)
+	if !strings.Contains(string(script), "cd \"/Users/dev/dev-setup\" || exit 0\nexec \"/usr/local/bin/devsetup\" status --menubar\n") {
+		t.Errorf("plugin script:\n%s", script)
+	}
+}
@@ -9,13 +9,26 @@
 package status
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/rkinnovate/dev-setup/internal/aitools"
+	"github.com/rkinnovate/dev-setup/internal/browser"
+	"github.com/rkinnovate/dev-setup/internal/checkcache"
 	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/database"
+	"github.com/rkinnovate/dev-setup/internal/devtls"
+	"github.com/rkinnovate/dev-setup/internal/editor"
+	"github.com/rkinnovate/dev-setup/internal/githooks"
+	"github.com/rkinnovate/dev-setup/internal/gitid"
+	"github.com/rkinnovate/dev-setup/internal/jetbrains"
+	"github.com/rkinnovate/dev-setup/internal/remotecache"
+	"github.com/rkinnovate/dev-setup/internal/services"
+	"github.com/rkinnovate/dev-setup/internal/setup"
 	"github.com/rkinnovate/dev-setup/internal/ui"
 )
 
@@ -25,6 +38,8 @@ type Reporter struct {
 	setupConfig *config.SetupConfig
 	state       *config.State
 	ui          ui.UI
+	exceptions  *config.Exceptions
+	checks      *checkcache.Cache
 }
 
 // NewReporter creates a new status reporter
@@ -37,6 +52,18 @@ func NewReporter(toolsConfig *config.ToolsConfig, setupConfig *config.SetupConfi
 	}
 }
 
+// SetExceptions excludes sanctioned deviations from drift
+// Params: exceptions - per-user exceptions (nil disables)
+func (r *Reporter) SetExceptions(exceptions *config.Exceptions) {
+	r.exceptions = exceptions
+}
+
+// SetCheckCache reuses recent tool check results instead of shelling out per tool
+// Params: cache - check cache (nil runs every check)
+func (r *Reporter) SetCheckCache(cache *checkcache.Cache) {
+	r.checks = cache
+}
+
 // ShowStatus displays current installation and configuration status
 func (r *Reporter) ShowStatus() {
 	r.ui.Info("")
@@ -50,26 +77,38 @@ func (r *Reporter) ShowStatus() {
 
 	r.ui.Info("")
 
+	// Service status (only when tools declare services)
+	if r.showServicesStatus() {
+		r.ui.Info("")
+	}
+
 	// Setup status
 	r.showSetupStatus()
 
 	r.ui.Info("")
 
+	// Manual steps still to do (only when setup.yaml has manual tasks)
+	if r.showManualSteps() {
+		r.ui.Info("")
+	}
+
 	// Overall progress
 	r.showOverallProgress()
 
 	r.ui.Info("")
+	_ = r.checks.Save()
 }
 
 // showToolsStatus displays installed tools
 // What: Shows which tools are installed, checking state first then running actual checks
 // Why: Provides accurate status even for manually installed tools
 func (r *Reporter) showToolsStatus() {
-	totalTools := len(r.toolsConfig.Tools)
+	tools := r.currentTools()
+	totalTools := len(tools)
 	installedCount := 0
 
 	// Count installed tools (state + actual checks)
-	for _, tool := range r.toolsConfig.Tools {
+	for _, tool := range tools {
 		if _, ok := r.state.Installed[tool.Name]; ok {
 			installedCount++
 		} else if r.isToolActuallyInstalled(tool) {
@@ -79,9 +118,13 @@ func (r *Reporter) showToolsStatus() {
 
 	r.ui.Info("📦 Installed Tools (%d/%d complete):", installedCount, totalTools)
 
-	for _, tool := range r.toolsConfig.Tools {
+	for _, tool := range tools {
 		if toolState, ok := r.state.Installed[tool.Name]; ok {
-			// Tool in state - show version info
+			// Tool in state - show version info, and any sanctioned override
+			if override, ok := r.exceptions.Override(tool.Name, time.Now()); ok {
+				r.ui.Warning("  ✓ %-20s %s [overridden: %s, %s]", tool.Name, r.formatToolInfo(toolState), override.Version, override.Describe())
+				continue
+			}
 			r.ui.Success("  ✓ %-20s %s", tool.Name, r.formatToolInfo(toolState))
 		} else if r.isToolActuallyInstalled(tool) {
 			// Not in state but actually installed - show without version
@@ -91,6 +134,56 @@ func (r *Reporter) showToolsStatus() {
 			r.ui.Error("  ✗ %-20s (not installed)", tool.Name)
 		}
 	}
+
+	// Deprecated tools only show up while still installed
+	for _, tool := range r.toolsConfig.DeprecatedTools() {
+		if _, ok := r.state.Installed[tool.Name]; ok || r.isToolActuallyInstalled(tool) {
+			r.ui.Warning("  ⚠ %-20s (%s; 'devsetup uninstall --deprecated' removes it)", tool.Name, tool.DeprecationNote())
+		}
+	}
+}
+
+// currentTools returns the tools a machine should have: tools.yaml without deprecated tools
+func (r *Reporter) currentTools() []config.Tool {
+	var tools []config.Tool
+	for _, tool := range r.toolsConfig.Tools {
+		if !tool.Deprecated {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// showServicesStatus displays declared brew services
+// What: Running state and port health of each tool's service
+// Why: An installed postgresql that isn't running is the usual "connection refused" on day one
+// Returns: True if any service was shown
+func (r *Reporter) showServicesStatus() bool {
+	shown := false
+	for _, tool := range r.toolsConfig.Tools {
+		svc := tool.Service
+		if svc == nil {
+			continue
+		}
+		if !shown {
+			r.ui.Info("🔌 Services:")
+			shown = true
+		}
+
+		name := svc.ServiceName(tool.Name)
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		ok, msg := services.Check(ctx, name, svc.Port)
+		cancel()
+		switch {
+		case ok:
+			r.ui.Success("  ✓ %-20s %s", name, msg)
+		case svc.Manual:
+			r.ui.Info("  - %-20s %s (manual: devsetup services start %s)", name, msg, tool.Name)
+		default:
+			r.ui.Error("  ✗ %-20s %s", name, msg)
+		}
+	}
+	return shown
 }
 
 // showSetupStatus displays configured tasks
@@ -118,6 +211,9 @@ func (r *Reporter) showSetupStatus() {
 		} else if r.isTaskActuallyConfigured(task) {
 			// Not in state but actually configured (manually or externally)
 			r.ui.Success("  ✓ %s (verified)", task.Name)
+		} else if task.Manual != nil {
+			// Manual step the user hasn't marked done
+			r.ui.Warning("  ☐ %s (manual step pending)", task.Name)
 		} else {
 			// Not configured
 			r.ui.Error("  ✗ %s (not configured)", task.Name)
@@ -125,17 +221,49 @@ func (r *Reporter) showSetupStatus() {
 	}
 }
 
+// showManualSteps lists the manual steps not done yet with their instructions
+// What: One block per pending manual task, with how to mark it done
+// Why: Manual steps are the part of onboarding devsetup can't finish by itself; status is where people look
+// Returns: True if any step was shown
+func (r *Reporter) showManualSteps() bool {
+	pending := r.pendingManual()
+	if len(pending) == 0 {
+		return false
+	}
+	r.ui.Info("📝 Manual Steps (%d pending):", len(pending))
+	for _, task := range pending {
+		r.ui.Warning("  ☐ %s", task.Name)
+		for _, line := range setup.ManualInstructions(task) {
+			r.ui.Info("      %s", line)
+		}
+		r.ui.Info("      Done? devsetup manual done %s", task.Name)
+	}
+	return true
+}
+
+// pendingManual returns the manual tasks that are neither acknowledged nor verified
+func (r *Reporter) pendingManual() []config.SetupTask {
+	var pending []config.SetupTask
+	for _, task := range r.setupConfig.SetupTasks {
+		if task.Manual != nil && !r.state.Configured[task.Name] && !r.isTaskActuallyConfigured(task) {
+			pending = append(pending, task)
+		}
+	}
+	return pending
+}
+
 // showOverallProgress displays overall completion percentage
 // What: Shows overall progress based on actual verification, not just state
 // Why: Provides accurate progress percentage
 func (r *Reporter) showOverallProgress() {
-	totalTools := len(r.toolsConfig.Tools)
+	tools := r.currentTools()
+	totalTools := len(tools)
 	totalTasks := len(r.setupConfig.SetupTasks)
 	total := totalTools + totalTasks
 
 	// Count actual installed tools (state + verification)
 	installedCount := 0
-	for _, tool := range r.toolsConfig.Tools {
+	for _, tool := range tools {
 		if _, ok := r.state.Installed[tool.Name]; ok {
 			installedCount++
 		} else if r.isToolActuallyInstalled(tool) {
@@ -171,6 +299,9 @@ func (r *Reporter) showOverallProgress() {
 		if configuredCount < totalTasks {
 			r.ui.Info("   • Run 'devsetup setup' to configure remaining items")
 		}
+		if len(r.pendingManual()) > 0 {
+			r.ui.Info("   • Do the manual steps above, then 'devsetup manual done <task>'")
+		}
 		r.ui.Info("   • Run 'devsetup verify' to check everything works")
 	} else {
 		r.ui.Info("")
@@ -187,31 +318,6 @@ func (r *Reporter) formatToolInfo(toolState config.ToolState) string {
 	return fmt.Sprintf("%-30s", version)
 }
 
-// expandPath expands ~ and environment variables in a path
-// What: Converts ~/ to $HOME/ and expands $VAR and ${VAR} syntax
-// Why: Config files use ~ but Go doesn't expand it
-// Params: path - path that may contain ~ or env vars
-// Returns: Expanded absolute path
-func expandPath(path string) string {
-	// Expand environment variables first
-	path = os.ExpandEnv(path)
-
-	// Expand tilde
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			path = filepath.Join(home, path[2:])
-		}
-	} else if path == "~" {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			path = home
-		}
-	}
-
-	return path
-}
-
 // isToolActuallyInstalled runs the tool's check command to verify it exists
 // What: Executes the check command to see if tool is installed
 // Why: Provides fallback verification when state file is missing/inaccurate
@@ -222,8 +328,7 @@ func (r *Reporter) isToolActuallyInstalled(tool config.Tool) bool {
 		return false
 	}
 
-	cmd := exec.Command("sh", "-c", tool.Check)
-	return cmd.Run() == nil
+	return r.checks.Passes(context.Background(), r.toolsConfig.ShellFor(tool), tool.Check)
 }
 
 // isTaskActuallyConfigured runs verification checks to see if task is configured
@@ -232,14 +337,25 @@ func (r *Reporter) isToolActuallyInstalled(tool config.Tool) bool {
 // Params: task - SetupTask with verification checks
 // Returns: true if all verification checks pass
 func (r *Reporter) isTaskActuallyConfigured(task config.SetupTask) bool {
+	// A manual step's verify command confirms it on its own
+	if m := task.Manual; m != nil && m.Verify != "" {
+		if !r.checks.Passes(context.Background(), r.setupConfig.ShellFor(task), m.Verify) {
+			return false
+		}
+		if len(task.Verify) == 0 {
+			return true
+		}
+	}
+
 	// If no verification checks, can't verify
 	if len(task.Verify) == 0 {
 		return false
 	}
 
 	// All checks must pass
+	shell := r.setupConfig.ShellFor(task)
 	for _, check := range task.Verify {
-		if !r.runVerifyCheck(check) {
+		if !r.runVerifyCheck(shell, check) {
 			return false
 		}
 	}
@@ -250,11 +366,11 @@ func (r *Reporter) isTaskActuallyConfigured(task config.SetupTask) bool {
 // runVerifyCheck runs a single verification check
 // What: Executes one verification check (command, env var, file exists, file contains)
 // Why: Shared verification logic for setup tasks
-// Params: check - VerifyCheck configuration
+// Params: shell - the task's shell (SetupConfig.ShellFor) for command checks, check - VerifyCheck configuration
 // Returns: true if check passes
-func (r *Reporter) runVerifyCheck(check config.VerifyCheck) bool {
+func (r *Reporter) runVerifyCheck(shell string, check config.VerifyCheck) bool {
 	if check.Command != "" {
-		cmd := exec.Command("sh", "-c", check.Command)
+		cmd := exec.Command(shell, "-c", check.Command)
 		return cmd.Run() == nil
 	}
 
@@ -263,13 +379,13 @@ func (r *Reporter) runVerifyCheck(check config.VerifyCheck) bool {
 	}
 
 	if check.FileExists != "" {
-		path := expandPath(check.FileExists)
+		path := config.ExpandPath(check.FileExists)
 		_, err := os.Stat(path)
 		return err == nil
 	}
 
 	if check.FileContains != nil {
-		path := expandPath(check.FileContains.Path)
+		path := config.ExpandPath(check.FileContains.Path)
 		content, err := os.ReadFile(path)
 		if err != nil {
 			return false
@@ -277,6 +393,57 @@ func (r *Reporter) runVerifyCheck(check config.VerifyCheck) bool {
 		return strings.Contains(string(content), check.FileContains.Text)
 	}
 
+	if q := check.PostgresQuery; q != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		found, err := database.Seeded(ctx, database.Conn{}, q.Database, q.Query)
+		return err == nil && found
+	}
+
+	if j := check.JSONValue; j != nil {
+		return editor.HasValue(config.ExpandPath(j.File), j.Key, j.Equals)
+	}
+
+	if be := check.BrowserExtension; be != nil {
+		return browser.ExtensionInstalled(be.Browser, be.ID)
+	}
+
+	if jp := check.JetBrainsPlugin; jp != nil {
+		return jetbrains.HasPlugin(jp.IDE, jp.ID)
+	}
+
+	if tc := check.TLSCert; tc != nil {
+		return devtls.Check(config.ExpandPath(tc.File), tc.Domains, tc.MinValid, time.Now()) == nil
+	}
+
+	if ai := check.AIAuth; ai != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		return aitools.Check(ctx, ai) == nil
+	}
+
+	if gh := check.GitHook; gh != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		hook := gh.Hook
+		if hook == "" {
+			hook = "pre-commit"
+		}
+		return githooks.Probe(ctx, config.ExpandPath(gh.Repo), hook) == nil
+	}
+
+	if id := check.GitIdentity; id != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return gitid.Check(ctx, *id) == nil
+	}
+
+	if rc := check.RemoteCache; rc != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		return remotecache.Check(ctx, rc) == nil
+	}
+
 	// TODO: Implement TomlValue check
 
 	return true
@@ -0,0 +1,109 @@
+// File: internal/status/snapshot.go
+// Purpose: Compact, machine-readable environment status
+// Problem: The menu bar plugin and the serve endpoint both need status without the terminal rendering
+// Role: Computes progress counts, remaining items, and checksum drift in one pass
+// Usage: s := reporter.Snapshot()
+// Design choices: Same installed/configured rules as ShowStatus; drift is the cheap checksum comparison (no version commands);
+//                 tools with an active per-user exception are listed as excepted instead of drifted
+// Assumptions: Called often (menu bar refresh, HTTP polling), so it must stay fast
+
+package status
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// Snapshot summarizes progress and drift
+// What: Counts plus the names behind them
+// Why: Shared by the menu bar plugin and the serve/report JSON
+type Snapshot struct {
+	// ToolsDone and ToolsTotal count installed vs declared tools (deprecated tools left out)
+	ToolsDone  int `json:"tools_done"`
+	ToolsTotal int `json:"tools_total"`
+
+	// TasksDone and TasksTotal count configured vs declared setup tasks
+	TasksDone  int `json:"tasks_done"`
+	TasksTotal int `json:"tasks_total"`
+
+	// Missing lists tools and tasks that are not done yet ("tool: x", "task: y", "manual: z" for manual steps)
+	Missing []string `json:"missing"`
+
+	// Drifted lists tools whose binary changed since devsetup installed it
+	Drifted []string `json:"drifted"`
+
+	// Excepted lists tools whose drift is sanctioned by the per-user exceptions file
+	Excepted []string `json:"excepted"`
+
+	// LastRun is the most recent install or setup time (zero if never run)
+	LastRun time.Time `json:"last_run"`
+}
+
+// Complete reports whether every tool and task is done
+func (s Snapshot) Complete() bool {
+	return s.ToolsDone == s.ToolsTotal && s.TasksDone == s.TasksTotal
+}
+
+// Snapshot computes the compact status
+// What: Tool/task progress from state with live-check fallback, plus checksum drift of recorded binaries
+// Why: Single pass used by WriteMenubar and the serve endpoint
+// Returns: Snapshot (slices are non-nil so JSON renders [] rather than null)
+func (r *Reporter) Snapshot() Snapshot {
+	s := Snapshot{
+		ToolsTotal: len(r.currentTools()),
+		TasksTotal: len(r.setupConfig.SetupTasks),
+		Missing:    []string{},
+		Drifted:    []string{},
+		Excepted:   []string{},
+		LastRun:    r.state.LastInstall,
+	}
+	now := time.Now()
+	if r.state.LastSetup.After(s.LastRun) {
+		s.LastRun = r.state.LastSetup
+	}
+
+	for _, tool := range r.currentTools() {
+		toolState, recorded := r.state.Installed[tool.Name]
+		if !recorded && !r.isToolActuallyInstalled(tool) {
+			s.Missing = append(s.Missing, "tool: "+tool.Name)
+			continue
+		}
+		s.ToolsDone++
+
+		if recorded && toolState.Checksum != "" && filepath.IsAbs(toolState.Path) {
+			if sum, err := config.FileChecksum(toolState.Path); err != nil || sum != toolState.Checksum {
+				if r.excepted(tool.Name, now) {
+					s.Excepted = append(s.Excepted, tool.Name)
+				} else {
+					s.Drifted = append(s.Drifted, tool.Name)
+				}
+			}
+		}
+	}
+
+	for _, task := range r.setupConfig.SetupTasks {
+		if !r.state.Configured[task.Name] && !r.isTaskActuallyConfigured(task) {
+			kind := "task: "
+			if task.Manual != nil {
+				kind = "manual: "
+			}
+			s.Missing = append(s.Missing, kind+task.Name)
+			continue
+		}
+		s.TasksDone++
+	}
+
+	_ = r.checks.Save()
+	return s
+}
+
+// excepted reports whether a tool has an active skip_verify entry or version override
+func (r *Reporter) excepted(name string, now time.Time) bool {
+	if _, ok := r.exceptions.Skip(name, now); ok {
+		return true
+	}
+	_, ok := r.exceptions.Override(name, now)
+	return ok
+}
@@ -0,0 +1,252 @@
+// File: internal/support/bundle.go
+// Purpose: Builds the support bundle zip (devsetup support bundle)
+// Problem: Help tickets arrived as screenshots of the last terminal page; support then asked, one message at a
+//          time, for the macOS version, the logs, the state file, and which configs the machine ran
+// Role: Gathers recent command logs, state files, machine facts, config versions, and the last failure summary
+//       into one zip a developer can attach to a ticket
+// Usage: files, err := support.WriteBundle(ctx, "devsetup-support.zip", version)
+// Design choices: Everything text goes through redact and has the home directory replaced with ~; logs are limited
+//                 to the last week and their last maxLogBytes so a verbose Xcode install doesn't make a 500MB zip;
+//                 fact probes that fail are recorded as errors instead of failing the bundle
+// Assumptions: Logs and state live under the devsetup state dir; configs are read like the loaders read them
+
+package support
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/policy"
+	"github.com/rkinnovate/dev-setup/internal/redact"
+)
+
+// logMaxAge is how old a log may be and still go into the bundle
+const logMaxAge = 7 * 24 * time.Hour
+
+// maxLogBytes caps each log in the bundle (the end is kept)
+const maxLogBytes = 1 << 20
+
+// configFiles are the configs whose versions a bundle records
+var configFiles = []string{"configs/tools.yaml", "configs/setup.yaml", "configs/policies.yaml", "configs/hooks.yaml", "configs/defaults.yaml"}
+
+// Facts describes the machine and devsetup build
+type Facts struct {
+	CollectedAt  time.Time         `json:"collected_at"`
+	Version      string            `json:"devsetup_version"`
+	OS           string            `json:"os"`
+	Arch         string            `json:"arch"`
+	MacOSVersion string            `json:"macos_version,omitempty"`
+	Shell        string            `json:"shell,omitempty"`
+	Homebrew     string            `json:"homebrew,omitempty"`
+	BrewPrefix   string            `json:"brew_prefix,omitempty"`
+	DeveloperDir string            `json:"developer_dir,omitempty"`
+	Errors       map[string]string `json:"errors,omitempty"`
+}
+
+// ConfigVersion identifies the config content a run used
+type ConfigVersion struct {
+	// File is the config path or overlay file
+	File string `json:"file"`
+
+	// Source is disk, embedded, or overlay
+	Source string `json:"source"`
+
+	// SHA256 of the file content
+	SHA256 string `json:"sha256"`
+}
+
+// CollectFacts probes the machine for the facts support asks about first
+// Params: ctx - context for probe commands, version - running devsetup version
+// Returns: Facts; probes that fail are listed in Errors
+func CollectFacts(ctx context.Context, version string) Facts {
+	f := Facts{
+		CollectedAt: time.Now(),
+		Version:     version,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Shell:       os.Getenv("SHELL"),
+		Errors:      map[string]string{},
+	}
+	probe := func(name string, field *string, get func() (string, error)) {
+		value, err := get()
+		if err != nil {
+			f.Errors[name] = err.Error()
+			return
+		}
+		*field = value
+	}
+
+	probe("macos_version", &f.MacOSVersion, func() (string, error) { return policy.MacOSVersion(ctx) })
+	probe("homebrew", &f.Homebrew, func() (string, error) { return firstLine(ctx, "brew", "--version") })
+	probe("brew_prefix", &f.BrewPrefix, func() (string, error) { return firstLine(ctx, "brew", "--prefix") })
+	probe("developer_dir", &f.DeveloperDir, func() (string, error) { return firstLine(ctx, "xcode-select", "-p") })
+	return f
+}
+
+// ConfigVersions hashes the configs and overlays a run would load
+// Returns: One entry per config file (disk or embedded copy) and per configs.d overlay
+func ConfigVersions() []ConfigVersion {
+	var versions []ConfigVersion
+	for _, path := range configFiles {
+		data, embedded, err := config.ReadConfigFile(path)
+		if err != nil {
+			continue
+		}
+		source := "disk"
+		if embedded {
+			source = "embedded"
+		}
+		versions = append(versions, ConfigVersion{File: path, Source: source, SHA256: hashBytes(data)})
+	}
+
+	overlays, _ := filepath.Glob(filepath.Join(config.OverridesDir(), "*.y*ml"))
+	for _, path := range overlays {
+		if data, err := os.ReadFile(path); err == nil {
+			versions = append(versions, ConfigVersion{File: path, Source: "overlay", SHA256: hashBytes(data)})
+		}
+	}
+	return versions
+}
+
+// WriteBundle writes the support bundle zip
+// What: facts.json, configs.json, last-failure.json, state/*.json, and recent logs/*.log
+// Why: One attachment answers support's first round of questions
+// Params: ctx - context for fact probes, path - zip to create, version - running devsetup version
+// Returns: Names of the files added, error if the zip can't be written
+// Edge cases: Missing logs, state, or failure summary are simply left out
+func WriteBundle(ctx context.Context, path, version string) ([]string, error) {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = out.Close() }()
+
+	b := &bundle{zw: zip.NewWriter(out), home: homeDir()}
+	b.addJSON("facts.json", CollectFacts(ctx, version))
+	b.addJSON("configs.json", ConfigVersions())
+	if failure, err := LastFailure(); err == nil && failure != nil {
+		b.addJSON("last-failure.json", failure)
+	}
+
+	stateFiles, _ := filepath.Glob(filepath.Join(config.GetStateDir(), "*.json"))
+	for _, p := range stateFiles {
+		if filepath.Base(p) == filepath.Base(FailurePath()) {
+			continue
+		}
+		if data, err := os.ReadFile(p); err == nil {
+			b.add("state/"+filepath.Base(p), data)
+		}
+	}
+
+	logs, _ := filepath.Glob(filepath.Join(capture.Dir(), "*.log"))
+	for _, p := range logs {
+		info, err := os.Stat(p)
+		if err != nil || time.Since(info.ModTime()) > logMaxAge {
+			continue
+		}
+		if data, err := readTail(p, maxLogBytes); err == nil {
+			b.add("logs/"+filepath.Base(p), data)
+		}
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := b.zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.names, out.Close()
+}
+
+// bundle accumulates redacted entries into a zip, remembering the first error
+type bundle struct {
+	zw    *zip.Writer
+	home  string
+	names []string
+	err   error
+}
+
+// add writes one redacted entry
+func (b *bundle) add(name string, data []byte) {
+	if b.err != nil {
+		return
+	}
+	text := redact.String(string(data))
+	if b.home != "" {
+		text = strings.ReplaceAll(text, b.home, "~")
+	}
+	w, err := b.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err == nil {
+		_, err = io.WriteString(w, text)
+	}
+	if err != nil {
+		b.err = fmt.Errorf("failed to add %s: %w", name, err)
+		return
+	}
+	b.names = append(b.names, name)
+}
+
+// addJSON writes one value as indented JSON
+func (b *bundle) addJSON(name string, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.err = fmt.Errorf("failed to encode %s: %w", name, err)
+		return
+	}
+	b.add(name, data)
+}
+
+// readTail returns the last max bytes of a file
+func readTail(path string, max int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if over := info.Size() - max; over > 0 {
+		if _, err := f.Seek(over, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(f)
+}
+
+// firstLine runs a command and returns the first line of its output
+func firstLine(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return line, nil
+}
+
+// hashBytes returns the hex SHA256 of data
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// homeDir returns the home directory ("" if unknown)
+func homeDir() string {
+	home, _ := os.UserHomeDir()
+	return home
+}
@@ -0,0 +1,153 @@
+// File: internal/support/failure.go
+// Purpose: Persists a summary of the last failed install or setup run
+// Problem: By the time someone asked for help the failing output had scrolled away, and "it failed" was all support got
+// Role: Installer and setup record which tools/tasks failed and where their logs are; support bundle and
+//       report-issue read it back
+// Usage: support.RecordFailure(support.FailureSummary{Stage: "install", Error: err.Error(), Failures: fs})
+// Design choices: Only the latest failure is kept (one small JSON file, overwritten); a later successful run
+//                 leaves it in place so the failure can still be reported afterwards
+// Assumptions: Errors were already redacted by capture; they are redacted again before writing
+
+package support
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/redact"
+)
+
+// Failure is one tool or setup task that failed
+type Failure struct {
+	// Kind is tool or task
+	Kind string `json:"kind"`
+
+	// Name of the tool or task
+	Name string `json:"name"`
+
+	// Required is whether the failure stopped the run
+	Required bool `json:"required"`
+
+	// Error is the failure message
+	Error string `json:"error"`
+
+	// LogPath is the captured command output ("" if none)
+	LogPath string `json:"log_path,omitempty"`
+
+	// Excerpt is the few output lines most likely to explain the failure
+	Excerpt []string `json:"excerpt,omitempty"`
+
+	// Owner is who maintains the tool or task ("" = the platform team)
+	Owner string `json:"owner,omitempty"`
+}
+
+// FailureSummary describes the last failed run
+type FailureSummary struct {
+	// Stage is install or setup
+	Stage string `json:"stage"`
+
+	// At is when the run failed
+	At time.Time `json:"at"`
+
+	// Error is the error the run ended with
+	Error string `json:"error"`
+
+	// Failures are the individual tools/tasks that failed (optional ones included)
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// NewFailure builds a Failure from a tool or task error
+// Params: kind - tool or task, name - tool/task name, required - whether it is required, err - the failure
+// Returns: Failure carrying the captured log path and output excerpt when err wraps a capture.CommandError
+func NewFailure(kind, name string, required bool, err error) Failure {
+	f := Failure{Kind: kind, Name: name, Required: required, Error: err.Error()}
+	var ce *capture.CommandError
+	if errors.As(err, &ce) {
+		f.LogPath = ce.LogPath
+		f.Excerpt = ce.Excerpt
+	}
+	return f
+}
+
+// Contacts groups failures by owner
+// What: One line per owner naming what of theirs failed, in the order owners first appear
+// Why: Tells users who to ping for a broken internal tool instead of defaulting to the platform team
+// Params: failures - recorded failures
+// Returns: Lines like "#web-platform (web-platform): node, yarn"; empty when no failure has an owner
+func Contacts(failures []Failure) []string {
+	var owners []string
+	names := make(map[string][]string)
+	for _, f := range failures {
+		if f.Owner == "" {
+			continue
+		}
+		if _, ok := names[f.Owner]; !ok {
+			owners = append(owners, f.Owner)
+		}
+		names[f.Owner] = append(names[f.Owner], f.Name)
+	}
+	lines := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		lines = append(lines, fmt.Sprintf("%s: %s", owner, strings.Join(names[owner], ", ")))
+	}
+	return lines
+}
+
+// FailurePath returns the failure summary location
+// Returns: <state dir>/last-failure.json
+func FailurePath() string {
+	return filepath.Join(config.GetStateDir(), "last-failure.json")
+}
+
+// RecordFailure saves the summary as the last failure
+// Params: s - summary (At defaults to now)
+// Returns: Error if the file can't be written
+func RecordFailure(s FailureSummary) error {
+	if s.At.IsZero() {
+		s.At = time.Now()
+	}
+	s.Error = redact.String(s.Error)
+	for i := range s.Failures {
+		s.Failures[i].Error = redact.String(s.Failures[i].Error)
+		for j, line := range s.Failures[i].Excerpt {
+			s.Failures[i].Excerpt[j] = redact.String(line)
+		}
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(config.GetStateDir(), 0700); err != nil {
+		return err
+	}
+	tmp := FailurePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, FailurePath())
+}
+
+// LastFailure loads the last failure summary
+// Returns: Summary, nil without error if no run has failed yet
+func LastFailure() (*FailureSummary, error) {
+	data, err := os.ReadFile(FailurePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s FailureSummary
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FailurePath(), err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,118 @@
+// File: internal/support/issue.go
+// Purpose: Pre-filled GitHub issues for installer bugs (devsetup report-issue)
+// Problem: Reporting a failure meant opening GitHub, guessing what to include, and usually leaving out the version,
+//          the macOS release, and the error itself, so most installer bugs were never reported
+// Role: Builds the issue title and markdown body from the last failure summary and machine facts, and the
+//       new-issue URL that pre-fills them
+// Usage: title, body := support.IssueTitle(failure), support.IssueBody(facts, failure, bundlePath)
+//        url := support.IssueURL(repo, title, body)
+// Design choices: The bundle is referenced by path for the user to drag in (neither gh nor the URL can attach
+//                 files); bodies are shortened to keep the URL under GitHub's length limit
+// Assumptions: Issues go to a GitHub.com repository that accepts new-issue query parameters
+
+package support
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxIssueURL keeps pre-filled URLs below what GitHub and browsers accept
+const maxIssueURL = 8000
+
+// maxErrorChars caps each failure message in the body
+const maxErrorChars = 300
+
+// IssueTitle returns the issue title for a failure
+// Returns: e.g. "devsetup install failed: jq, node", or a generic title when nothing failed
+func IssueTitle(failure *FailureSummary) string {
+	if failure == nil {
+		return "devsetup problem report"
+	}
+	var names []string
+	for _, f := range failure.Failures {
+		names = append(names, f.Name)
+	}
+	if len(names) == 0 {
+		return fmt.Sprintf("devsetup %s failed", failure.Stage)
+	}
+	return fmt.Sprintf("devsetup %s failed: %s", failure.Stage, strings.Join(names, ", "))
+}
+
+// IssueBody returns the markdown issue body
+// Params: facts - machine facts, failure - last failure (nil if none), bundlePath - support bundle ("" if none)
+// Returns: Body with a description placeholder, the failure, environment, and bundle instructions
+// Edge cases: The home directory is replaced with ~ (issues may be public)
+func IssueBody(facts Facts, failure *FailureSummary, bundlePath string) string {
+	var b strings.Builder
+	b.WriteString("### What happened\n\n<!-- What were you doing? What did you expect? -->\n\n")
+
+	b.WriteString("### Failure\n\n")
+	if failure == nil {
+		b.WriteString("No failed install or setup run recorded.\n\n")
+	} else {
+		fmt.Fprintf(&b, "`devsetup %s` failed at %s:\n\n```\n%s\n```\n\n", failure.Stage, failure.At.Format("2006-01-02 15:04 MST"), shorten(failure.Error))
+		if len(failure.Failures) > 0 {
+			b.WriteString("| Kind | Name | Required | Owner | Error |\n|---|---|---|---|---|\n")
+			for _, f := range failure.Failures {
+				msg := strings.ReplaceAll(strings.ReplaceAll(shorten(f.Error), "|", `\|`), "\n", " ")
+				fmt.Fprintf(&b, "| %s | %s | %t | %s | %s |\n", f.Kind, f.Name, f.Required, f.Owner, msg)
+			}
+			b.WriteString("\n")
+			for _, f := range failure.Failures {
+				if len(f.Excerpt) > 0 {
+					fmt.Fprintf(&b, "`%s` output:\n\n```\n%s\n```\n\n", f.Name, strings.Join(f.Excerpt, "\n"))
+				}
+			}
+		}
+	}
+
+	b.WriteString("### Environment\n\n")
+	fmt.Fprintf(&b, "- devsetup: %s\n", facts.Version)
+	fmt.Fprintf(&b, "- macOS: %s (%s)\n", orUnknown(facts.MacOSVersion), facts.Arch)
+	fmt.Fprintf(&b, "- Homebrew: %s\n", orUnknown(facts.Homebrew))
+	fmt.Fprintf(&b, "- Shell: %s\n\n", orUnknown(facts.Shell))
+
+	if bundlePath != "" {
+		fmt.Fprintf(&b, "### Support bundle\n\n`%s` — drag it into this issue to attach it.\n", bundlePath)
+	}
+	if home := homeDir(); home != "" {
+		return strings.ReplaceAll(b.String(), home, "~")
+	}
+	return b.String()
+}
+
+// IssueURL returns the new-issue URL pre-filled with title and body
+// Params: repo - owner/name, title and body - issue content
+// Returns: URL no longer than maxIssueURL; an overlong body is cut with a pointer to the bundle
+func IssueURL(repo, title, body string) string {
+	build := func(body string) string {
+		q := url.Values{"title": {title}, "body": {body}}
+		return fmt.Sprintf("https://github.com/%s/issues/new?%s", repo, q.Encode())
+	}
+
+	u := build(body)
+	for len(u) > maxIssueURL && len(body) > 0 {
+		body = strings.ToValidUTF8(body[:len(body)*9/10], "")
+		u = build(body + "\n\n…(truncated; see the support bundle)")
+	}
+	return u
+}
+
+// shorten caps a message at maxErrorChars
+func shorten(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxErrorChars {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxErrorChars], "") + "…"
+}
+
+// orUnknown returns s, or "unknown" when empty
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
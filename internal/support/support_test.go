@@ -0,0 +1,120 @@
+package support
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rkinnovate/dev-setup/internal/capture"
+)
+
+func TestRecordFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if s, err := LastFailure(); err != nil || s != nil {
+		t.Fatalf("LastFailure() before any failure = %v, %v", s, err)
+	}
+
+	cmdErr := &capture.CommandError{Err: errors.New("exit status 1"), LogPath: "/logs/install-jq.log", Excerpt: []string{"Error: jq: no bottle"}}
+	f := NewFailure("tool", "jq", true, cmdErr)
+	if f.LogPath != "/logs/install-jq.log" || len(f.Excerpt) != 1 {
+		t.Errorf("NewFailure() = %+v, want captured log and excerpt", f)
+	}
+	if err := RecordFailure(FailureSummary{Stage: "install", Error: "required tool jq failed", Failures: []Failure{f}}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LastFailure()
+	if err != nil || s == nil {
+		t.Fatalf("LastFailure() = %v, %v", s, err)
+	}
+	if s.Stage != "install" || len(s.Failures) != 1 || s.Failures[0].Name != "jq" || s.At.IsZero() {
+		t.Errorf("LastFailure() = %+v", s)
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := os.MkdirAll(capture.Dir(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	logText := "installing into " + filepath.Join(home, "Library") + "\n"
+	if err := os.WriteFile(filepath.Join(capture.Dir(), "install-jq.log"), []byte(logText), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordFailure(FailureSummary{Stage: "setup", Error: "boom"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	names, err := WriteBundle(context.Background(), path, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"facts.json": true, "configs.json": true, "last-failure.json": true, "logs/install-jq.log": true}
+	for _, n := range names {
+		delete(want, n)
+	}
+	if len(want) != 0 {
+		t.Errorf("bundle %v is missing %v", names, want)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = zr.Close() }()
+	for _, f := range zr.File {
+		if f.Name != "logs/install-jq.log" {
+			continue
+		}
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		if strings.Contains(string(data), home) || !strings.Contains(string(data), "~/Library") {
+			t.Errorf("log not home-redacted: %q", data)
+		}
+	}
+}
+
+func TestContacts(t *testing.T) {
+	got := Contacts([]Failure{
+		{Name: "node", Owner: "#web-platform (web-platform)"},
+		{Name: "jq"},
+		{Name: "vpn", Owner: "@alice"},
+		{Name: "yarn", Owner: "#web-platform (web-platform)"},
+	})
+	want := []string{"#web-platform (web-platform): node, yarn", "@alice: vpn"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Contacts() = %q, want %q", got, want)
+	}
+}
+
+func TestIssueURL(t *testing.T) {
+	failure := &FailureSummary{Stage: "install", Error: "required tool jq failed", Failures: []Failure{
+		{Kind: "tool", Name: "jq", Required: true, Owner: "#cli-tools", Error: "exit status 1: curl: (6) | could not resolve"},
+	}}
+	title := IssueTitle(failure)
+	if title != "devsetup install failed: jq" {
+		t.Errorf("IssueTitle() = %q", title)
+	}
+
+	body := IssueBody(Facts{Version: "v1.2.0", Arch: "arm64", MacOSVersion: "14.6"}, failure, "~/devsetup-support.zip")
+	for _, want := range []string{"| tool | jq | true | #cli-tools | exit status 1: curl: (6) \\| could not resolve |", "- macOS: 14.6 (arm64)", "~/devsetup-support.zip"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("IssueBody() missing %q:\n%s", want, body)
+		}
+	}
+
+	u := IssueURL("rkinnovate/dev-setup", title, strings.Repeat("é", 10000))
+	if len(u) > maxIssueURL || !strings.HasPrefix(u, "https://github.com/rkinnovate/dev-setup/issues/new?") {
+		t.Errorf("IssueURL() length %d, prefix %.60s", len(u), u)
+	}
+}
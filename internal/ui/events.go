@@ -0,0 +1,181 @@
+// File: internal/ui/events.go
+// Purpose: UI implementation that emits structured progress events as JSON lines
+// Problem: Programmatic callers (API clients, onboarding portal) had to scrape colored terminal output
+// Role: Drop-in UI for installer/verifier/doctor when driven through the local API
+// Usage: eventUI := NewEventUI(w, flush); installer.NewToolInstaller(cfg, state, eventUI, false, version)
+// Design choices: One JSON object per line (NDJSON) so clients can stream-parse; messages redacted; safe for parallel installs
+// Assumptions: Writer is a streaming HTTP response or file; flush may be nil
+
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/redact"
+)
+
+// Event types emitted by EventUI
+const (
+	EventStage     = "stage"
+	EventTaskStart = "task_start"
+	EventTaskDone  = "task_complete"
+	EventTaskFail  = "task_failed"
+	EventTaskStop  = "task_cancelled"
+	EventMessage   = "message"
+	EventProgress  = "progress"
+	EventDiff      = "diff"
+	EventSummary   = "summary"
+	EventDone      = "done"
+)
+
+// Event is one progress update
+// What: Type plus the fields relevant to it (name, level/message, counters)
+// Why: Stable schema for API clients
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Name    string    `json:"name,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Current int       `json:"current,omitempty"`
+	Total   int       `json:"total,omitempty"`
+
+	// Warnings is set on the "summary" event: every warning of the operation, once each
+	Warnings []string `json:"warnings,omitempty"`
+
+	// OK and Result are set on the final "done" event of an operation
+	OK     *bool       `json:"ok,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+}
+
+// EventUI writes UI calls as NDJSON events
+type EventUI struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	flush func()
+
+	// warnings emitted so far, repeated by Summary
+	warnings warnings
+}
+
+// NewEventUI creates an event-emitting UI
+// Params: w - destination, flush - called after each event (e.g. http.Flusher.Flush), may be nil
+// Returns: EventUI
+// Example: eventUI := NewEventUI(w, w.(http.Flusher).Flush)
+func NewEventUI(w io.Writer, flush func()) *EventUI {
+	return &EventUI{enc: json.NewEncoder(w), flush: flush}
+}
+
+// Emit writes one event
+// What: Stamps the time, redacts the message, encodes, and flushes
+// Why: Also used by callers to send their own events (e.g. final results)
+// Params: e - event to write
+func (e *EventUI) Emit(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event.Time = time.Now().UTC()
+	event.Message = redact.String(event.Message)
+	_ = e.enc.Encode(event)
+	if e.flush != nil {
+		e.flush()
+	}
+}
+
+// PrintBanner is a no-op; banners are terminal decoration
+func (e *EventUI) PrintBanner() {}
+
+// StartStage emits a stage event
+func (e *EventUI) StartStage(name, estimatedTime string) {
+	e.Emit(Event{Type: EventStage, Name: name, Message: estimatedTime})
+}
+
+// StartTask emits a task_start event
+func (e *EventUI) StartTask(taskName string) {
+	e.Emit(Event{Type: EventTaskStart, Name: taskName})
+}
+
+// CompleteTask emits a task_complete event
+func (e *EventUI) CompleteTask(taskName string) {
+	e.Emit(Event{Type: EventTaskDone, Name: taskName})
+}
+
+// FailTask emits a task_failed event with the error message
+func (e *EventUI) FailTask(taskName string, err error) {
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	e.Emit(Event{Type: EventTaskFail, Name: taskName, Message: msg})
+}
+
+// CancelTask emits a task_cancelled event
+func (e *EventUI) CancelTask(taskName string) {
+	e.Emit(Event{Type: EventTaskStop, Name: taskName})
+}
+
+// Success emits a success message
+func (e *EventUI) Success(format string, args ...interface{}) {
+	e.Emit(Event{Type: EventMessage, Level: "success", Message: fmt.Sprintf(format, args...)})
+}
+
+// Error emits an error message
+func (e *EventUI) Error(format string, args ...interface{}) {
+	e.Emit(Event{Type: EventMessage, Level: "error", Message: fmt.Sprintf(format, args...)})
+}
+
+// Warning emits a warning message
+func (e *EventUI) Warning(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	e.Emit(Event{Type: EventMessage, Level: "warning", Message: msg})
+	e.warnings.add(msg)
+}
+
+// Info emits an info message; blank spacer lines are dropped
+func (e *EventUI) Info(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if msg == "" {
+		return
+	}
+	e.Emit(Event{Type: EventMessage, Level: "info", Message: msg})
+}
+
+// Diff emits a diff event with the unified diff as its message
+func (e *EventUI) Diff(diff string) {
+	if diff == "" {
+		return
+	}
+	e.Emit(Event{Type: EventDiff, Message: diff})
+}
+
+// PrintProgress emits a progress event
+func (e *EventUI) PrintProgress(current, total int, label string) {
+	e.Emit(Event{Type: EventProgress, Name: label, Current: current, Total: total})
+}
+
+// PrintElapsedTime is a no-op; event timestamps carry timing
+func (e *EventUI) PrintElapsedTime() {}
+
+// CollectWarnings returns the warnings emitted so far, once each
+func (e *EventUI) CollectWarnings() []string {
+	return e.warnings.all()
+}
+
+// Summary emits a summary event listing every warning; nothing when there were none
+func (e *EventUI) Summary() {
+	list := e.warnings.all()
+	if len(list) == 0 {
+		return
+	}
+	for i := range list {
+		list[i] = redact.String(list[i])
+	}
+	e.Emit(Event{Type: EventSummary, Warnings: list})
+}
+
+// Compile-time check that EventUI implements UI interface
+var _ UI = (*EventUI)(nil)
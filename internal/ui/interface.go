@@ -20,6 +20,7 @@ type UI interface {
 	StartTask(taskName string)
 	CompleteTask(taskName string)
 	FailTask(taskName string, err error)
+	CancelTask(taskName string)
 
 	// Messages
 	Success(format string, args ...interface{})
@@ -27,9 +28,16 @@ type UI interface {
 	Warning(format string, args ...interface{})
 	Info(format string, args ...interface{})
 
+	// Previews of file changes (unified diff text, e.g. from dotfiles.Diff)
+	Diff(diff string)
+
 	// Progress indicators
 	PrintProgress(current, total int, label string)
 	PrintElapsedTime()
+
+	// Closing summary: warnings shown so far, and a block replaying them at the end of a run
+	CollectWarnings() []string
+	Summary()
 }
 
 // Compile-time check that ProgressUI implements UI interface
@@ -3,18 +3,25 @@
 // Problem: Plain text output doesn't show installation progress clearly; developers want visual feedback
 // Role: Handles all terminal output with colors, progress bars, spinners, and structured formatting
 // Usage: Create ProgressUI instance, call StartStage/StartTask/Success/Error methods
-// Design choices: Uses ANSI colors for compatibility; supports both interactive and non-interactive terminals
+// Design choices: Uses ANSI colors for compatibility; supports both interactive and non-interactive terminals; all output is redacted;
+//                 messages are translated through the i18n catalog before formatting; boxes and bars fit the terminal
+//                 width (see width.go), and task names are truncated only on a terminal so logs keep them whole;
+//                 failed tasks show their command's output excerpt (any error with OutputExcerpt, see capture)
 // Assumptions: Terminal supports ANSI escape codes (standard on macOS); UTF-8 encoding
 
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/i18n"
+	"github.com/rkinnovate/dev-setup/internal/redact"
 )
 
 // Color codes for terminal output
@@ -40,10 +47,16 @@ const (
 // What: Manages all user-facing terminal output with colors and formatting
 // Why: Provides clear visual feedback during long-running installation processes
 type ProgressUI struct {
-	writer     io.Writer
-	mu         sync.Mutex
+	writer        io.Writer
+	mu            sync.Mutex
 	isInteractive bool
-	startTime  time.Time
+	startTime     time.Time
+
+	// term is the terminal output goes to, for width detection (nil when not a terminal)
+	term *os.File
+
+	// warnings shown so far, replayed by Summary
+	warnings warnings
 }
 
 // NewProgressUI creates a new ProgressUI instance
@@ -52,11 +65,34 @@ type ProgressUI struct {
 // Returns: Configured ProgressUI instance
 // Example: ui := NewProgressUI()
 func NewProgressUI() *ProgressUI {
-	return &ProgressUI{
-		writer:     os.Stdout,
+	p := &ProgressUI{
+		writer:        redact.NewWriter(os.Stdout),
 		isInteractive: isTerminal(os.Stdout),
-		startTime:  time.Now(),
+		startTime:     time.Now(),
+	}
+	if p.isInteractive {
+		p.term = os.Stdout
+	}
+	return p
+}
+
+// Interactive reports whether output goes to a terminal
+// Returns: false when stdout is piped or redirected
+func (p *ProgressUI) Interactive() bool {
+	return p.isInteractive
+}
+
+// columns returns the output width, rechecked on every call so terminal resizes apply
+func (p *ProgressUI) columns() int {
+	return terminalWidth(p.term)
+}
+
+// taskName fits a task name on one terminal line next to its marker (left whole when not a terminal)
+func (p *ProgressUI) taskName(name string) string {
+	if p.term == nil {
+		return name
 	}
+	return fit(name, p.columns()-6)
 }
 
 // PrintBanner prints the devsetup welcome banner
@@ -81,6 +117,10 @@ func (p *ProgressUI) PrintBanner() {
 ┃                                                    ┃
 ┗━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━┛
 `
+	if p.columns() < bannerWidth+2 {
+		// Too narrow for the art: one line instead of a wrapped mess
+		banner = "\n" + fit("devsetup · Zero to Productive in 5 Minutes", p.columns()) + "\n"
+	}
 	_, _ = fmt.Fprint(p.writer, colorCyan+banner+colorReset+"\n")
 }
 
@@ -93,12 +133,15 @@ func (p *ProgressUI) StartStage(name, estimatedTime string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	header := fmt.Sprintf("\n╔════════════════════════════════════════════════════════╗\n"+
-		"║ %s%-50s%s     ║\n"+
-		"║ %sEstimated time: %-38s%s ║\n"+
-		"╚════════════════════════════════════════════════════════╝\n",
-		colorBold+colorCyan, name, colorReset,
-		colorDim, estimatedTime, colorReset)
+	width := min(p.columns(), maxBoxWidth)
+	inner := width - 4 // "║ " and " ║"
+	rule := strings.Repeat("═", width-2)
+
+	header := fmt.Sprintf("\n╔%s╗\n║ %s%s%s ║\n║ %s%s%s ║\n╚%s╝\n",
+		rule,
+		colorBold+colorCyan, padRight(name, inner), colorReset,
+		colorDim, padRight(i18n.T("Estimated time:")+" "+estimatedTime, inner), colorReset,
+		rule)
 
 	_, _ = fmt.Fprint(p.writer, header)
 }
@@ -112,7 +155,7 @@ func (p *ProgressUI) StartTask(taskName string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	_, _ = fmt.Fprintf(p.writer, "  %s⚡%s %s...\n", colorYellow, colorReset, taskName)
+	_, _ = fmt.Fprintf(p.writer, "  %s⚡%s %s...\n", colorYellow, colorReset, p.taskName(taskName))
 }
 
 // CompleteTask marks a task as successfully completed
@@ -124,19 +167,54 @@ func (p *ProgressUI) CompleteTask(taskName string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	_, _ = fmt.Fprintf(p.writer, "  %s✓%s %s\n", colorGreen, colorReset, taskName)
+	_, _ = fmt.Fprintf(p.writer, "  %s✓%s %s\n", colorGreen, colorReset, p.taskName(taskName))
 }
 
 // FailTask marks a task as failed
-// What: Prints red X with task name and error
-// Why: Clear indication of failure for debugging
+// What: Prints red X with task name and error, then the relevant lines of the command's output under it
+// Why: Clear indication of failure for debugging; the error already names the full log for everything else
 // Params: taskName - task that failed, err - error that occurred
 // Example: ui.FailTask("Installing Homebrew", err)
+// Edge cases: The excerpt is skipped when it is just the line the error already quotes
 func (p *ProgressUI) FailTask(taskName string, err error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	_, _ = fmt.Fprintf(p.writer, "  %s✗%s %s: %v\n", colorRed, colorReset, taskName, err)
+	_, _ = fmt.Fprintf(p.writer, "  %s✗%s %s: %v\n", colorRed, colorReset, p.taskName(taskName), err)
+
+	var out outputExcerpter
+	if errors.As(err, &out) {
+		if lines := out.OutputExcerpt(); len(lines) > 1 {
+			for _, line := range lines {
+				_, _ = fmt.Fprintf(p.writer, "      %s│ %s%s\n", colorDim, p.excerptLine(line), colorReset)
+			}
+		}
+	}
+}
+
+// outputExcerpter is an error carrying the relevant lines of a failed command's output (capture.CommandError)
+type outputExcerpter interface {
+	OutputExcerpt() []string
+}
+
+// excerptLine fits an output line under a failed task (on a terminal only, like taskName)
+func (p *ProgressUI) excerptLine(line string) string {
+	if p.term == nil {
+		return line
+	}
+	return fit(line, p.columns()-8)
+}
+
+// CancelTask marks a task as cancelled
+// What: Prints dimmed ⊘ with task name
+// Why: Tasks stopped because a sibling failed are not failures themselves
+// Params: taskName - task that was cancelled
+// Example: ui.CancelTask("node")
+func (p *ProgressUI) CancelTask(taskName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, _ = fmt.Fprintf(p.writer, "  %s⊘ %s %s%s\n", colorDim, p.taskName(taskName), i18n.T("(cancelled)"), colorReset)
 }
 
 // Success prints a success message in green
@@ -148,7 +226,7 @@ func (p *ProgressUI) Success(format string, args ...interface{}) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	message := fmt.Sprintf(format, args...)
+	message := fmt.Sprintf(i18n.T(format), args...)
 	_, _ = fmt.Fprintf(p.writer, "%s%s%s\n", colorGreen, message, colorReset)
 }
 
@@ -161,7 +239,7 @@ func (p *ProgressUI) Error(format string, args ...interface{}) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	message := fmt.Sprintf(format, args...)
+	message := fmt.Sprintf(i18n.T(format), args...)
 	_, _ = fmt.Fprintf(p.writer, "%s%s%s\n", colorRed, message, colorReset)
 }
 
@@ -174,8 +252,9 @@ func (p *ProgressUI) Warning(format string, args ...interface{}) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	message := fmt.Sprintf(format, args...)
+	message := fmt.Sprintf(i18n.T(format), args...)
 	_, _ = fmt.Fprintf(p.writer, "%s%s%s\n", colorYellow, message, colorReset)
+	p.warnings.add(message)
 }
 
 // Info prints an informational message in default color
@@ -187,10 +266,42 @@ func (p *ProgressUI) Info(format string, args ...interface{}) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	message := fmt.Sprintf(format, args...)
+	message := fmt.Sprintf(i18n.T(format), args...)
 	_, _ = fmt.Fprintf(p.writer, "%s\n", message)
 }
 
+// Diff prints a unified diff with added lines in green and removed lines in red
+// What: File headers bold, hunk headers cyan, context lines dimmed; every line indented under the current task
+// Why: Changes to dotfiles and config files are reviewed before they're made (setup, dry runs, doctor --fix)
+// Params: diff - unified diff text (dotfiles.Diff)
+// Example: ui.Diff(dotfiles.Diff("~/.zshrc", old, new))
+// Edge cases: An empty diff prints nothing; lines aren't truncated, so a change is always shown whole
+func (p *ProgressUI) Diff(diff string) {
+	if diff == "" {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, _ = fmt.Fprintln(p.writer)
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		color := colorDim
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			color = colorBold
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		}
+		_, _ = fmt.Fprintf(p.writer, "    %s%s%s\n", color, line, colorReset)
+	}
+	_, _ = fmt.Fprintln(p.writer)
+}
+
 // PrintProgress prints a progress bar
 // What: Displays visual progress bar with percentage
 // Why: Shows completion progress for long operations
@@ -200,8 +311,12 @@ func (p *ProgressUI) PrintProgress(current, total int, label string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	// "  [bar] 100% label": the bar shrinks (down to 10) before the label is cut
+	columns := p.columns()
+	barWidth := min(max(columns-10-displayWidth(label), 10), 40)
+	label = fit(label, columns-10-barWidth)
+
 	percentage := float64(current) / float64(total) * 100
-	barWidth := 40
 	filledWidth := int(float64(barWidth) * float64(current) / float64(total))
 
 	bar := strings.Repeat("█", filledWidth) + strings.Repeat("░", barWidth-filledWidth)
@@ -222,7 +337,37 @@ func (p *ProgressUI) PrintElapsedTime() {
 	defer p.mu.Unlock()
 
 	elapsed := time.Since(p.startTime)
-	_, _ = fmt.Fprintf(p.writer, "\n%s⏱  Total time: %v%s\n", colorDim, elapsed.Round(time.Second), colorReset)
+	_, _ = fmt.Fprintf(p.writer, "\n%s%s%s\n", colorDim, fmt.Sprintf(i18n.T("⏱  Total time: %v"), elapsed.Round(time.Second)), colorReset)
+}
+
+// CollectWarnings returns the warnings shown so far
+// What: Each warning once, in the order first shown, without its leading ⚠️
+// Why: Callers can act on or report warnings (e.g. in a run record) besides the closing summary
+// Returns: Warning messages as shown (translated), empty if there were none
+func (p *ProgressUI) CollectWarnings() []string {
+	return p.warnings.all()
+}
+
+// Summary replays every warning shown so far in one closing block
+// What: Prints a yellow header with the count, then each warning as a bullet
+// Why: Warnings scroll away during a long install; the end of the run is where people look for what needs attention
+// Example: ui.Summary()
+// Edge cases: Prints nothing when there were no warnings
+func (p *ProgressUI) Summary() {
+	list := p.warnings.all()
+	if len(list) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	header := fmt.Sprintf(i18n.T("⚠️  %d warning(s) to review:"), len(list))
+	_, _ = fmt.Fprintf(p.writer, "\n%s%s%s\n", colorYellow, header, colorReset)
+	for _, message := range list {
+		_, _ = fmt.Fprintf(p.writer, "  %s•%s %s\n", colorYellow, colorReset, message)
+	}
+	_, _ = fmt.Fprintln(p.writer)
 }
 
 // isTerminal checks if output is an interactive terminal
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProgressUIDiff(t *testing.T) {
+	var out bytes.Buffer
+	p := &ProgressUI{writer: &out}
+
+	p.Diff("")
+	if out.Len() != 0 {
+		t.Fatalf("Diff(\"\") printed %q", out.String())
+	}
+
+	p.Diff("--- ~/.zshrc\n+++ ~/.zshrc (proposed)\n@@ -1 +1,2 @@\n a\n+b\n-c\n")
+	for _, want := range []string{
+		colorBold + "--- ~/.zshrc" + colorReset,
+		colorCyan + "@@ -1 +1,2 @@" + colorReset,
+		colorDim + " a" + colorReset,
+		colorGreen + "+b" + colorReset,
+		colorRed + "-c" + colorReset,
+	} {
+		if !strings.Contains(out.String(), "    "+want+"\n") {
+			t.Errorf("Diff() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// File: internal/ui/warnings.go
+// Purpose: Keeps the warnings a UI has shown so they can be replayed when a run ends
+// Problem: Warnings scrolled away during a long install; at the end nobody could tell what still needed attention
+// Role: ProgressUI and EventUI record every Warning here; CollectWarnings returns them and Summary replays them
+// Usage: w.add(message); list := w.all()
+// Design choices: Messages are kept as shown (translated, formatted) and listed once each, in the order first
+//                 shown, so a warning repeated per task doesn't flood the summary; decorating UIs (runlog, metrics)
+//                 pass through to the UI they wrap, which holds the list
+// Assumptions: A UI lives for one command, so the list never needs clearing
+
+package ui
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// warnings is the list of warnings a UI has shown
+type warnings struct {
+	mu   sync.Mutex
+	list []string
+}
+
+// add records a shown warning
+// Edge cases: Leading warning emoji are dropped (the summary block has its own); repeats are ignored
+func (w *warnings) add(message string) {
+	message = strings.TrimSpace(strings.TrimLeft(message, "⚠️ "))
+	if message == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !slices.Contains(w.list, message) {
+		w.list = append(w.list, message)
+	}
+}
+
+// all returns the recorded warnings in the order first shown
+func (w *warnings) all() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.list)
+}
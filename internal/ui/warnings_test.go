@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProgressUISummary(t *testing.T) {
+	var out bytes.Buffer
+	p := &ProgressUI{writer: &out}
+
+	p.Summary()
+	if out.Len() != 0 {
+		t.Fatalf("Summary() without warnings printed %q", out.String())
+	}
+
+	p.Warning("⚠️  Failed to load policies: %v", "missing file")
+	p.Info("unrelated")
+	p.Warning("Optional tool %s failed", "zed")
+	p.Warning("⚠️  Failed to load policies: %v", "missing file")
+
+	want := []string{"Failed to load policies: missing file", "Optional tool zed failed"}
+	if got := p.CollectWarnings(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("CollectWarnings() = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	p.Summary()
+	summary := out.String()
+	if !strings.Contains(summary, "2 warning(s) to review") {
+		t.Errorf("Summary() header missing:\n%s", summary)
+	}
+	for _, w := range want {
+		if strings.Count(summary, w) != 1 {
+			t.Errorf("Summary() should list %q once:\n%s", w, summary)
+		}
+	}
+}
+
+func TestEventUISummary(t *testing.T) {
+	var out bytes.Buffer
+	e := NewEventUI(&out, nil)
+	e.Warning("disk almost full")
+	out.Reset()
+
+	e.Summary()
+	var event Event
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("summary event: %v", err)
+	}
+	if event.Type != EventSummary || !reflect.DeepEqual(event.Warnings, []string{"disk almost full"}) {
+		t.Errorf("summary event = %+v", event)
+	}
+}
@@ -0,0 +1,133 @@
+// File: internal/ui/width.go
+// Purpose: Terminal width detection and width-aware text fitting for ProgressUI
+// Problem: The banner, stage boxes, and progress bar assumed an 80-column terminal; in a narrow split pane they
+//          wrapped into garbage, and a long stage or task name (or a translated label) pushed the box border out
+// Role: Reports the terminal's current width and measures, truncates, and pads text by display columns
+// Usage: width := terminalWidth(os.Stdout); line := fit(name, width-4)
+// Design choices: Width is asked for on every render (one ioctl), so resizing mid-install is picked up; COLUMNS
+//                 wins when set, as in other CLI tools; display width is a small heuristic (emoji and CJK are two
+//                 columns, combining marks and joiners zero) rather than a Unicode table dependency
+// Assumptions: macOS or Linux terminal (TIOCGWINSZ); output that isn't a terminal is sized to defaultWidth
+
+package ui
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"unicode"
+	"unsafe"
+)
+
+// Width limits for rendering
+const (
+	// defaultWidth is assumed when the width can't be detected (pipes, CI logs)
+	defaultWidth = 80
+	// minWidth is the narrowest layout rendered; narrower terminals wrap
+	minWidth = 20
+	// maxBoxWidth caps stage boxes so they don't stretch across wide terminals
+	maxBoxWidth = 58
+	// bannerWidth is the width of the ASCII-art banner
+	bannerWidth = 54
+)
+
+// ellipsis marks truncated text
+const ellipsis = "…"
+
+// winsize is the TIOCGWINSZ result
+type winsize struct {
+	Row, Col, Xpixel, Ypixel uint16
+}
+
+// terminalWidth returns the width of the terminal f is attached to
+// Params: f - output file (nil for non-terminal output)
+// Returns: COLUMNS if set, the terminal's width, or defaultWidth; never less than minWidth
+func terminalWidth(f *os.File) int {
+	width := defaultWidth
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		width = cols
+	} else if f != nil {
+		var ws winsize
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+		if errno == 0 && ws.Col > 0 {
+			width = int(ws.Col)
+		}
+	}
+	return max(width, minWidth)
+}
+
+// displayWidth returns how many terminal columns s takes
+// Edge cases: ANSI escape sequences take none
+func displayWidth(s string) int {
+	width := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			if r >= '@' && r <= '~' && r != '[' {
+				inEscape = false
+			}
+		case r == '\033':
+			inEscape = true
+		default:
+			width += runeWidth(r)
+		}
+	}
+	return width
+}
+
+// runeWidth returns a rune's column width
+func runeWidth(r rune) int {
+	switch {
+	case r == 0x200D || (r >= 0xFE00 && r <= 0xFE0F) || unicode.Is(unicode.Mn, r):
+		return 0 // joiners, variation selectors, combining marks
+	case r >= 0x1F000 && r <= 0x1FAFF, // emoji and pictographs
+		wideSymbol(r),
+		r >= 0x1100 && r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0xA4CF, // CJK
+		r >= 0xAC00 && r <= 0xD7A3, // Hangul syllables
+		r >= 0xF900 && r <= 0xFAFF, // CJK compatibility
+		r >= 0xFF00 && r <= 0xFF60, // fullwidth forms
+		r >= 0xFFE0 && r <= 0xFFE6:
+		return 2
+	}
+	return 1
+}
+
+// wideSymbol reports whether a dingbat/misc symbol renders as emoji (two columns) by default
+func wideSymbol(r rune) bool {
+	switch r {
+	case '⚡', '✅', '❌', '⏳', '⌛', '⭐', '⛔', '✨', '❓', '❗', '☕':
+		return true
+	}
+	return false
+}
+
+// fit truncates s to at most width columns, ending in an ellipsis when cut
+// Example: fit("homebrew-cli-tools", 10) → "homebrew-…"
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if displayWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + ellipsis
+}
+
+// padRight fits s into exactly width columns
+func padRight(s string, width int) string {
+	s = fit(s, width)
+	return s + strings.Repeat(" ", width-displayWidth(s))
+}
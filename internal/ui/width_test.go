@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"homebrew", 10, "homebrew"},
+		{"homebrew-cli-tools", 10, "homebrew-…"},
+		{"📦 Stage", 5, "📦 S…"},
+		{"Geschätzte Dauer:", 9, "Geschätz…"},
+		{"anything", 0, ""},
+	}
+	for _, tt := range tests {
+		got := fit(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("fit(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+		if displayWidth(got) > tt.width {
+			t.Errorf("fit(%q, %d) is %d columns wide", tt.in, tt.width, displayWidth(got))
+		}
+	}
+
+	if w := displayWidth(colorGreen + "✓ ok" + colorReset); w != 4 {
+		t.Errorf("displayWidth ignores escapes: got %d, want 4", w)
+	}
+	if w := displayWidth("⚠️ x"); w != 3 {
+		t.Errorf("displayWidth(⚠️ x) = %d, want 3", w)
+	}
+}
+
+func TestStageBoxFitsWidth(t *testing.T) {
+	for _, columns := range []string{"80", "40", "24"} {
+		t.Setenv("COLUMNS", columns)
+		var buf bytes.Buffer
+		p := &ProgressUI{writer: &buf}
+		p.StartStage("Stage 2: Full development stack with a very long name", "10 minutes")
+
+		var widths []int
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			widths = append(widths, displayWidth(line))
+		}
+		want := min(terminalWidth(nil), maxBoxWidth)
+		for i, w := range widths {
+			if w != want {
+				t.Errorf("COLUMNS=%s: box line %d is %d columns, want %d\n%s", columns, i, w, want, buf.String())
+			}
+		}
+	}
+}
+
+func TestProgressBarFitsWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "30")
+	var buf bytes.Buffer
+	p := &ProgressUI{writer: &buf}
+	p.PrintProgress(5, 10, "Downloading Homebrew bottles for every declared tool")
+
+	line := strings.Trim(buf.String(), "\r\n")
+	if w := displayWidth(line); w > 30 {
+		t.Errorf("progress line is %d columns in a 30-column terminal: %q", w, line)
+	}
+}
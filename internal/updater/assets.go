@@ -0,0 +1,172 @@
+// File: internal/updater/assets.go
+// Purpose: Concurrent download of a release's assets (binary, checksum, shell completions, man page)
+// Problem: Update fetched only the binary, one request at a time, never checked it against the published
+//          checksum, and left shell completions at whatever version first installed them
+// Role: Downloads every asset an update needs at once into a temp dir with one combined progress report,
+//       then installs the support files (completions, man page) where shells and man look for them
+// Usage: files, err := u.downloadAssets(ctx, assets, dir); installed, errs := installSupportFiles(files, home)
+// Design choices: One combined progress total (sum of asset sizes) instead of a bar per asset, since the terminal
+//                 UI draws a single progress line; support files are optional (a release without them still
+//                 updates, and a failure to install one never rolls back the binary)
+// Assumptions: Asset sizes from the GitHub API are accurate; completions are named as in supportAssets
+
+package updater
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rkinnovate/dev-setup/internal/download"
+)
+
+// supportAssets map optional release assets to where they are installed (relative to the home directory)
+var supportAssets = map[string]string{
+	"_devsetup":     ".local/share/zsh/site-functions/_devsetup",
+	"devsetup.bash": ".local/share/bash-completion/completions/devsetup",
+	"devsetup.fish": ".config/fish/completions/devsetup.fish",
+	"devsetup.1":    ".local/share/man/man1/devsetup.1",
+}
+
+// downloadAssets downloads assets concurrently into dir
+// What: One goroutine per asset, each with retry and resume; progress is reported as the sum over all assets
+// Why: The binary, its checksum, and the support files download in the time of the largest one
+// Params: ctx - cancels every download, assets - what to fetch, dir - destination directory
+// Returns: Asset name → downloaded path, and the first error (every download is finished or stopped by then)
+func (u *Updater) downloadAssets(ctx context.Context, assets []Asset, dir string) (map[string]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		done     = make([]int64, len(assets))
+		sizes    = make([]int64, len(assets))
+		paths    = make(map[string]string, len(assets))
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for i, asset := range assets {
+		sizes[i] = asset.Size
+	}
+	report := func() {
+		var sum, total int64
+		for i := range assets {
+			sum += done[i]
+			total += sizes[i]
+		}
+		if u.progress != nil {
+			u.progress(sum, total)
+		}
+	}
+
+	for i, asset := range assets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// No overall timeout: a large binary on a slow link may take minutes (stalls still fail and retry)
+			d := download.New(&http.Client{Transport: u.httpClient.Transport})
+			d.UserAgent = fmt.Sprintf("devsetup/%s", u.currentVersion)
+			d.Progress = func(n, total int64) {
+				mu.Lock()
+				defer mu.Unlock()
+				done[i] = n
+				if sizes[i] <= 0 && total > 0 {
+					sizes[i] = total
+				}
+				report()
+			}
+
+			path := filepath.Join(dir, asset.Name)
+			err := d.ToFile(ctx, asset.BrowserDownloadURL, path)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", asset.Name, err)
+					cancel()
+				}
+				return
+			}
+			paths[asset.Name] = path
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Finish the progress line even when the API's sizes were off
+	if u.progress != nil {
+		var sum int64
+		for _, n := range done {
+			sum += n
+		}
+		u.progress(sum, sum)
+	}
+	return paths, nil
+}
+
+// updateAssets lists the assets an update downloads
+// Returns: The platform binary, its .sha256 (nil if not published), and the support assets present in the release
+func updateAssets(release *ReleaseInfo) (binary, checksum *Asset, support []Asset) {
+	binary = findAssetForPlatform(release.Assets)
+	if binary == nil {
+		return nil, nil, nil
+	}
+	for i, a := range release.Assets {
+		if a.Name == binary.Name+".sha256" {
+			checksum = &release.Assets[i]
+		}
+		if _, ok := supportAssets[a.Name]; ok {
+			support = append(support, a)
+		}
+	}
+	return binary, checksum, support
+}
+
+// installSupportFiles copies downloaded support assets into place
+// Params: files - asset name → downloaded path (other assets are ignored), home - home directory
+// Returns: Installed paths, and one error per file that couldn't be installed
+func installSupportFiles(files map[string]string, home string) ([]string, []error) {
+	var (
+		installed []string
+		errs      []error
+	)
+	for name, rel := range supportAssets {
+		src, ok := files[name]
+		if !ok {
+			continue
+		}
+		dst := filepath.Join(home, rel)
+		data, err := os.ReadFile(src)
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(dst), 0755)
+		}
+		if err == nil {
+			err = os.WriteFile(dst, data, 0644)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		installed = append(installed, dst)
+	}
+	sort.Strings(installed)
+	return installed, errs
+}
+
+// parseChecksum reads the hash from a shasum-style line ("<hex>  <file>")
+// Returns: Lowercase hex checksum, error if the content is empty
+func parseChecksum(name string, data []byte) (string, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file %s", name)
+	}
+	return strings.ToLower(fields[0]), nil
+}
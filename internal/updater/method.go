@@ -0,0 +1,118 @@
+// File: internal/updater/method.go
+// Purpose: Detects how the running devsetup was installed, so updates go through the right channel
+// Problem: Update renamed the binary in place, which failed at rename time for root-owned directories like
+//          /usr/local/bin, and silently replaced Homebrew-managed binaries so brew's records no longer matched
+// Role: Classifies the executable as Homebrew-managed, in a system directory, or user-writable; Update refuses
+//       Homebrew installs (pointing to brew upgrade) and replaces system installs through sudo
+// Usage: inst, err := updater.DetectInstallation(); if inst.Method == updater.MethodHomebrew { ... inst.UpgradeCommand() }
+// Design choices: Writability is tested by creating a file in the binary's directory, not by reading mode bits,
+//                 so ACLs and read-only mounts are judged correctly; Homebrew is recognized by the Cellar path its
+//                 symlinks resolve to, which needs no brew call
+// Assumptions: sudo is available and prompts on the terminal for system installs
+
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rkinnovate/dev-setup/internal/prompt"
+)
+
+// InstallMethod is how the running binary was installed
+type InstallMethod string
+
+const (
+	// MethodUser is a binary in a directory the user can write (~/.local/bin); updated in place
+	MethodUser InstallMethod = "user"
+
+	// MethodHomebrew is a binary in Homebrew's Cellar; updated with brew upgrade
+	MethodHomebrew InstallMethod = "homebrew"
+
+	// MethodSystem is a binary in a directory only root can write (/usr/local/bin); updated through sudo
+	MethodSystem InstallMethod = "system"
+)
+
+// ErrManagedByHomebrew is returned by Update for Homebrew installs
+var ErrManagedByHomebrew = errors.New("devsetup is managed by Homebrew")
+
+// Installation describes the running binary
+type Installation struct {
+	// Path is the executable with symlinks resolved
+	Path string
+
+	// Method is how it was installed
+	Method InstallMethod
+
+	// Formula is the Homebrew formula (MethodHomebrew only)
+	Formula string
+}
+
+// DetectInstallation classifies the running executable
+// Returns: Installation, error if the executable can't be located
+func DetectInstallation() (*Installation, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve symlinks: %w", err)
+	}
+	return detectInstallation(exe), nil
+}
+
+// detectInstallation classifies an executable path
+// Params: path - executable with symlinks resolved
+// Returns: Homebrew for .../Cellar/<formula>/..., system when its directory isn't writable, user otherwise
+func detectInstallation(path string) *Installation {
+	inst := &Installation{Path: path, Method: MethodUser}
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i, part := range parts {
+		if part == "Cellar" && i+1 < len(parts) {
+			inst.Method, inst.Formula = MethodHomebrew, parts[i+1]
+			return inst
+		}
+	}
+	if !writable(filepath.Dir(path)) {
+		inst.Method = MethodSystem
+	}
+	return inst
+}
+
+// UpgradeCommand returns the command that updates a package-managed install
+// Returns: "brew upgrade <formula>" for Homebrew, "" otherwise
+func (i *Installation) UpgradeCommand() string {
+	if i.Method == MethodHomebrew {
+		return "brew upgrade " + i.Formula
+	}
+	return ""
+}
+
+// writable reports whether files can be created in dir
+func writable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".devsetup-update-*")
+	if err != nil {
+		return false
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return true
+}
+
+// sudoInstall copies a new binary over a root-owned one
+// What: sudo install -m 0755 (owned by root), prompting for the password on the terminal
+// Params: src - verified new binary, dst - current executable
+// Returns: Error if sudo is refused or the copy fails
+func sudoInstall(src, dst string) error {
+	cmd := exec.Command("sudo", "install", "-o", "0", "-g", "0", "-m", "0755", src, dst)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = prompt.Stdin(), os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo install to %s failed: %w", dst, err)
+	}
+	return nil
+}
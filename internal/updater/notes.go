@@ -0,0 +1,179 @@
+// File: internal/updater/notes.go
+// Purpose: Terminal rendering of release notes and lookup of past releases (devsetup changelog)
+// Problem: Release notes were shown as the first 500 characters of raw Markdown, cut mid-word, with ## and
+//          [text](url) noise and no way to read the rest or the notes of earlier releases
+// Role: Renders the Markdown GitHub release bodies use (headings, bullets, emphasis, code, links) as plain
+//       terminal lines, cut at a line budget with a pointer to the full notes; fetches releases by tag or as a list
+// Usage: fmt.Print(updater.RenderReleaseNotes(release, 15, true)); releases, err := u.Releases(5)
+// Design choices: A line-based renderer for the subset GitHub release notes use rather than a full Markdown
+//                 parser (no new dependency); links keep their URL in the text since terminals can't click
+//                 [text](url); the cut is by whole lines, never mid-line
+// Assumptions: Release bodies are GitHub-flavored Markdown; lines fit the terminal or wrap there
+
+package updater
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ANSI styles for rendered notes
+const (
+	styleBold  = "\033[1m"
+	styleDim   = "\033[2m"
+	styleCyan  = "\033[36m"
+	styleReset = "\033[0m"
+)
+
+var (
+	// mdLink matches [text](url)
+	mdLink = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+
+	// mdBold matches **text** and __text__
+	mdBold = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+
+	// mdEmphasis matches *text* and _text_ between word boundaries (snake_case is left alone)
+	mdEmphasis = regexp.MustCompile(`(^|[\s(])[*_]([^*_\s][^*_]*?)[*_]($|[\s).,!?:;])`)
+
+	// mdCode matches `code`
+	mdCode = regexp.MustCompile("`([^`]+)`")
+
+	// mdComment matches HTML comments (release templates leave them in)
+	mdComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	// mdBullet matches "- item", "* item", and "+ item", keeping the indent
+	mdBullet = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+
+	// mdHeading matches "# Heading" through "###### Heading"
+	mdHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+)
+
+// RenderReleaseNotes formats a release body for the terminal
+// What: Headings bold (top level underlined with ═), bullets as •, links as "text (url)", code fences indented
+// Why: Release notes read like the GitHub page instead of Markdown source
+// Params: release - release to render, maxLines - line budget (0 = no limit), color - emit ANSI styles
+// Returns: Rendered lines ending in a newline; past the budget a final "… N more lines, see <url>" line
+// Example: RenderReleaseNotes(&ReleaseInfo{Body: "## Fixes\n- faster *install*"}, 0, false) → "Fixes\n  • faster install\n"
+func RenderReleaseNotes(release *ReleaseInfo, maxLines int, color bool) string {
+	style := func(s, code string) string {
+		if !color || s == "" {
+			return s
+		}
+		return code + s + styleReset
+	}
+
+	body := mdComment.ReplaceAllString(strings.ReplaceAll(release.Body, "\r\n", "\n"), "")
+	var lines []string
+	inFence, blank := false, true
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			lines = append(lines, "    "+style(line, styleDim))
+			blank = false
+			continue
+		}
+		if trimmed == "" {
+			// Collapse runs of blank lines
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+
+		if m := mdHeading.FindStringSubmatch(trimmed); m != nil {
+			text := renderInline(m[2], style)
+			lines = append(lines, style(text, styleBold))
+			if len(m[1]) == 1 {
+				lines = append(lines, strings.Repeat("═", len([]rune(stripInline(m[2])))))
+			}
+			continue
+		}
+		if m := mdBullet.FindStringSubmatch(line); m != nil {
+			indent := strings.Repeat(" ", len(m[1])/2*2)
+			lines = append(lines, "  "+indent+"• "+renderInline(line[len(m[0]):], style))
+			continue
+		}
+		lines = append(lines, renderInline(trimmed, style))
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return "No release notes available.\n"
+	}
+
+	if maxLines > 0 && len(lines) > maxLines {
+		more := len(lines) - maxLines
+		lines = lines[:maxLines]
+		if lines[maxLines-1] != "" {
+			lines = append(lines, "")
+		}
+		note := fmt.Sprintf("… %d more lines", more)
+		if release.HTMLURL != "" {
+			note += ", see " + release.HTMLURL
+		}
+		lines = append(lines, style(note, styleDim))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderInline renders links, bold, emphasis markers, and code spans of one line
+func renderInline(s string, style func(string, string) string) string {
+	s = mdLink.ReplaceAllStringFunc(s, func(m string) string {
+		parts := mdLink.FindStringSubmatch(m)
+		if parts[1] == parts[2] {
+			return style(parts[2], styleCyan)
+		}
+		return parts[1] + " (" + style(parts[2], styleCyan) + ")"
+	})
+	s = mdBold.ReplaceAllStringFunc(s, func(m string) string {
+		return style(strings.Trim(m, "*_"), styleBold)
+	})
+	s = mdCode.ReplaceAllStringFunc(s, func(m string) string {
+		return style(strings.Trim(m, "`"), styleCyan)
+	})
+	return mdEmphasis.ReplaceAllString(s, "$1$2$3")
+}
+
+// stripInline returns a line's text without Markdown markers (for underline width)
+func stripInline(s string) string {
+	return renderInline(s, func(s, _ string) string { return s })
+}
+
+// Release fetches the release with a tag
+// Params: tag - version, with or without the leading v (e.g. "1.4.0" or "v1.4.0")
+// Returns: The release, error if it doesn't exist or GitHub can't be reached
+func (u *Updater) Release(tag string) (*ReleaseInfo, error) {
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+	release, err := u.provider().Release(tag)
+	if err != nil {
+		return nil, fmt.Errorf("release %s: %w", tag, err)
+	}
+	return release, nil
+}
+
+// Releases fetches the most recent published releases
+// Params: limit - how many (GitHub and GitLab cap a page at 100)
+// Returns: Releases newest first, drafts and prereleases left out
+func (u *Updater) Releases(limit int) ([]ReleaseInfo, error) {
+	all, err := u.provider().Releases(min(max(limit, 1), 100))
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]ReleaseInfo, 0, len(all))
+	for _, r := range all {
+		if !r.Draft && !r.Prerelease && len(releases) < limit {
+			releases = append(releases, r)
+		}
+	}
+	return releases, nil
+}
@@ -0,0 +1,342 @@
+// File: internal/updater/provider.go
+// Purpose: Release providers (GitHub, GitLab, static HTTPS index) behind one interface
+// Problem: Release lookups were GitHub API URLs built from hardcoded owner/repo constants, so a GitLab or
+//          file-server mirror of the releases couldn't be used without patching the binary
+// Role: Turns releases.yaml into a Provider that lists and fetches releases as ReleaseInfo; the updater, changelog,
+//       and self check go through it, and sync init resolves owner/repo shorthand to a clone URL on the same host
+// Usage: updater.SetSource(rc); release, err := updater.NewUpdater(version).CheckForUpdate(); url := updater.CloneURL("team/settings")
+// Design choices: ReleaseInfo stays GitHub-shaped (GitLab releases are converted, the index file uses the GitHub JSON
+//                 as is) so nothing past the provider knows where a release came from; the token is added by the
+//                 HTTP transport, only for the source's own host, so asset downloads from the mirror carry it and
+//                 redirects to storage hosts don't
+// Assumptions: Mirrors publish the same asset names as GitHub; GitLab is v15.4+ (releases/permalink/latest)
+
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+)
+
+// Provider is a host of devsetup releases
+type Provider interface {
+	// Latest returns the newest published release
+	Latest() (*ReleaseInfo, error)
+
+	// Release returns the release with a tag ("v1.4.0")
+	Release(tag string) (*ReleaseInfo, error)
+
+	// Releases returns up to limit releases, newest first
+	Releases(limit int) ([]ReleaseInfo, error)
+
+	// CloneURL returns the git URL of owner/name on the same host ("" if the host serves no git)
+	CloneURL(repo string) string
+}
+
+// source is the release source of new Updaters (releases.yaml)
+var source = config.DefaultReleasesConfig()
+
+// SetSource sets where Updaters look for releases
+// Params: rc - loaded releases.yaml (main sets it once at startup)
+func SetSource(rc *config.ReleasesConfig) {
+	source = rc
+}
+
+// CloneURL resolves owner/name shorthand on the release host
+// Params: repo - "owner/name" (anything else is returned unchanged)
+// Returns: Clone URL (e.g. "https://gitlab.example.com/team/settings.git"), or repo itself if it isn't shorthand or the host serves no git
+func CloneURL(repo string) string {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.ContainsAny(repo, ":@ ") || strings.HasPrefix(repo, ".") {
+		return repo
+	}
+	if u := newProvider(source, &api{client: http.DefaultClient}).CloneURL(repo); u != "" {
+		return u
+	}
+	return repo
+}
+
+// newProvider creates the provider for a release source
+// Params: rc - validated release source, a - HTTP access shared by the provider's requests
+func newProvider(rc *config.ReleasesConfig, a *api) Provider {
+	base := strings.TrimSuffix(rc.URL, "/")
+	switch rc.Provider {
+	case config.ProviderGitLab:
+		return &gitlabProvider{api: a, base: base, repo: rc.Repo}
+	case config.ProviderIndex:
+		return &indexProvider{api: a, url: rc.URL}
+	default:
+		if base == "" {
+			base = GitHubAPIURL
+		}
+		return &githubProvider{api: a, base: base, repo: rc.Repo}
+	}
+}
+
+// api performs the JSON requests of a provider
+type api struct {
+	client    *http.Client
+	userAgent string
+}
+
+// getJSON fetches url and decodes the JSON response into v
+// Returns: Error on network failure, non-200 status, or malformed JSON
+func (a *api) getJSON(url string, v any) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", a.userAgent)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch release info: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse release info: %w", err)
+	}
+	return nil
+}
+
+// githubProvider reads the GitHub REST API (github.com or GitHub Enterprise)
+type githubProvider struct {
+	api  *api
+	base string
+	repo string
+}
+
+func (p *githubProvider) Latest() (*ReleaseInfo, error) {
+	var release ReleaseInfo
+	if err := p.api.getJSON(fmt.Sprintf("%s/repos/%s/releases/latest", p.base, p.repo), &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (p *githubProvider) Release(tag string) (*ReleaseInfo, error) {
+	var release ReleaseInfo
+	if err := p.api.getJSON(fmt.Sprintf("%s/repos/%s/releases/tags/%s", p.base, p.repo, url.PathEscape(tag)), &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (p *githubProvider) Releases(limit int) ([]ReleaseInfo, error) {
+	var releases []ReleaseInfo
+	if err := p.api.getJSON(fmt.Sprintf("%s/repos/%s/releases?per_page=%d", p.base, p.repo, limit), &releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// CloneURL maps api.github.com to github.com and GitHub Enterprise's https://host/api/v3 to https://host
+func (p *githubProvider) CloneURL(repo string) string {
+	u, err := url.Parse(p.base)
+	if err != nil {
+		return ""
+	}
+	host := u.Host
+	if host == "api.github.com" {
+		host = "github.com"
+	}
+	return fmt.Sprintf("%s://%s/%s.git", u.Scheme, host, repo)
+}
+
+// gitlabProvider reads the GitLab REST API (v4)
+type gitlabProvider struct {
+	api  *api
+	base string
+	repo string
+}
+
+// gitlabRelease is a release as the GitLab API returns it
+type gitlabRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	ReleasedAt  time.Time `json:"released_at"`
+	Upcoming    bool      `json:"upcoming_release"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+	Assets struct {
+		Links []struct {
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+// info converts a GitLab release to the GitHub shape
+// Edge cases: Upcoming releases count as prereleases; asset sizes aren't published (0 = unknown)
+func (r *gitlabRelease) info() ReleaseInfo {
+	info := ReleaseInfo{
+		TagName:    r.TagName,
+		Name:       r.Name,
+		Body:       r.Description,
+		Prerelease: r.Upcoming,
+		CreatedAt:  r.ReleasedAt,
+		HTMLURL:    r.Links.Self,
+	}
+	if info.CreatedAt.IsZero() {
+		info.CreatedAt = r.CreatedAt
+	}
+	for _, link := range r.Assets.Links {
+		download := link.DirectAssetURL
+		if download == "" {
+			download = link.URL
+		}
+		info.Assets = append(info.Assets, Asset{Name: link.Name, BrowserDownloadURL: download})
+	}
+	return info
+}
+
+// releasesURL is the project's releases endpoint (the project path is URL-encoded as one segment)
+func (p *gitlabProvider) releasesURL() string {
+	return fmt.Sprintf("%s/api/v4/projects/%s/releases", p.base, url.PathEscape(p.repo))
+}
+
+func (p *gitlabProvider) Latest() (*ReleaseInfo, error) {
+	return p.get(p.releasesURL() + "/permalink/latest")
+}
+
+func (p *gitlabProvider) Release(tag string) (*ReleaseInfo, error) {
+	return p.get(p.releasesURL() + "/" + url.PathEscape(tag))
+}
+
+func (p *gitlabProvider) Releases(limit int) ([]ReleaseInfo, error) {
+	var raw []gitlabRelease
+	if err := p.api.getJSON(fmt.Sprintf("%s?per_page=%d", p.releasesURL(), limit), &raw); err != nil {
+		return nil, err
+	}
+	releases := make([]ReleaseInfo, len(raw))
+	for i := range raw {
+		releases[i] = raw[i].info()
+	}
+	return releases, nil
+}
+
+func (p *gitlabProvider) CloneURL(repo string) string {
+	return p.base + "/" + repo + ".git"
+}
+
+// get fetches one GitLab release
+func (p *gitlabProvider) get(url string) (*ReleaseInfo, error) {
+	var raw gitlabRelease
+	if err := p.api.getJSON(url, &raw); err != nil {
+		return nil, err
+	}
+	info := raw.info()
+	return &info, nil
+}
+
+// indexProvider reads a static JSON array of GitHub-shaped releases
+type indexProvider struct {
+	api *api
+	url string
+}
+
+// all fetches the index, newest first, with asset URLs made absolute
+func (p *indexProvider) all() ([]ReleaseInfo, error) {
+	var releases []ReleaseInfo
+	if err := p.api.getJSON(p.url, &releases); err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index URL: %w", err)
+	}
+	for i := range releases {
+		for j, asset := range releases[i].Assets {
+			if ref, err := url.Parse(asset.BrowserDownloadURL); err == nil {
+				releases[i].Assets[j].BrowserDownloadURL = base.ResolveReference(ref).String()
+			}
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].CreatedAt.After(releases[j].CreatedAt)
+	})
+	return releases, nil
+}
+
+func (p *indexProvider) Latest() (*ReleaseInfo, error) {
+	releases, err := p.all()
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range releases {
+		if !r.Draft && !r.Prerelease {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no published release in %s", p.url)
+}
+
+func (p *indexProvider) Release(tag string) (*ReleaseInfo, error) {
+	releases, err := p.all()
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range releases {
+		if r.TagName == tag {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s not in %s", tag, p.url)
+}
+
+func (p *indexProvider) Releases(limit int) ([]ReleaseInfo, error) {
+	releases, err := p.all()
+	if err != nil {
+		return nil, err
+	}
+	return releases[:min(limit, len(releases))], nil
+}
+
+// CloneURL is empty: a file server hosts no git repositories
+func (p *indexProvider) CloneURL(string) string {
+	return ""
+}
+
+// tokenTransport adds a private mirror's token to requests for its host
+type tokenTransport struct {
+	host   string
+	header string
+	value  string
+}
+
+// newTokenTransport returns the transport for a source with a token, nil without one
+// Edge cases: GitLab takes PRIVATE-TOKEN; GitHub and index servers take a bearer token
+func newTokenTransport(rc *config.ReleasesConfig) http.RoundTripper {
+	token := rc.Token()
+	u, err := url.Parse(rc.URL)
+	if token == "" || err != nil {
+		return nil
+	}
+	if rc.Provider == config.ProviderGitLab {
+		return &tokenTransport{host: u.Host, header: "PRIVATE-TOKEN", value: token}
+	}
+	return &tokenTransport{host: u.Host, header: "Authorization", value: "Bearer " + token}
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == t.host {
+		req = req.Clone(req.Context())
+		req.Header.Set(t.header, t.value)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
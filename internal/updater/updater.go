@@ -1,5 +1,5 @@
 // File: internal/updater/updater.go
-// Purpose: Self-update functionality for devsetup binary from GitHub releases
+// Purpose: Self-update functionality for devsetup binary from GitHub releases (or a mirror, see provider.go)
 // Problem: Need way to keep devsetup tool up-to-date without manual reinstall
 // Role: Checks for new releases on GitHub, downloads and replaces current binary
 // Usage: Called by `devsetup update` command or automatically on version check
@@ -9,16 +9,18 @@
 package updater
 
 import (
+	"context"
 	"crypto/sha256"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/download"
 )
 
 const (
@@ -40,6 +42,7 @@ type ReleaseInfo struct {
 	Draft      bool      `json:"draft"`
 	Prerelease bool      `json:"prerelease"`
 	CreatedAt  time.Time `json:"created_at"`
+	HTMLURL    string    `json:"html_url"`
 	Assets     []Asset   `json:"assets"`
 }
 
@@ -59,56 +62,56 @@ type Updater struct {
 	currentVersion string
 	owner          string
 	repo           string
+	source         *config.ReleasesConfig
 	httpClient     *http.Client
+	progress       download.ProgressFunc
 }
 
 // NewUpdater creates a new Updater instance
-// What: Constructor for Updater with GitHub repository information
+// What: Constructor for Updater with the release source set by SetSource (GitHub by default)
 // Why: Centralizes updater creation with timeout configuration
 // Params: currentVersion - current binary version
 // Returns: Configured Updater instance
 // Example: updater := NewUpdater("v0.4.0")
 func NewUpdater(currentVersion string) *Updater {
-	return &Updater{
+	u := &Updater{
 		currentVersion: currentVersion,
-		owner:          GitHubOwner,
-		repo:           GitHubRepo,
+		source:         source,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: newTokenTransport(source),
 		},
 	}
+	u.owner, u.repo, _ = strings.Cut(source.Repo, "/")
+	return u
+}
+
+// provider returns the release provider of the updater's source
+func (u *Updater) provider() Provider {
+	return newProvider(u.source, &api{client: u.httpClient, userAgent: fmt.Sprintf("devsetup/%s", u.currentVersion)})
+}
+
+// SetProgress reports download progress of Update
+// Params: fn - called with bytes downloaded and total size (-1 if unknown)
+func (u *Updater) SetProgress(fn download.ProgressFunc) {
+	u.progress = fn
+}
+
+// SetTimeout limits how long each GitHub API request may take
+// Params: d - timeout (the default is 30s; background checks use a few seconds so commands don't hang offline)
+func (u *Updater) SetTimeout(d time.Duration) {
+	u.httpClient.Timeout = d
 }
 
 // CheckForUpdate checks if a newer version is available
-// What: Queries GitHub API for latest release and compares with current version
+// What: Asks the release provider for the latest release and compares with current version
 // Why: Determines if update is available before downloading
 // Returns: ReleaseInfo pointer if update available, nil if current, error on failure
 // Example: release, err := updater.CheckForUpdate()
 func (u *Updater) CheckForUpdate() (*ReleaseInfo, error) {
-	// Get latest release from GitHub API
-	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", GitHubAPIURL, u.owner, u.repo)
-
-	req, err := http.NewRequest("GET", url, nil)
+	release, err := u.provider().Latest()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set user agent (GitHub API requires it)
-	req.Header.Set("User-Agent", fmt.Sprintf("devsetup/%s", u.currentVersion))
-
-	resp, err := u.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch release info: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
-	var release ReleaseInfo
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to parse release info: %w", err)
+		return nil, err
 	}
 
 	// Skip draft and prerelease versions
@@ -121,66 +124,98 @@ func (u *Updater) CheckForUpdate() (*ReleaseInfo, error) {
 		return nil, nil // Already on latest
 	}
 
-	return &release, nil
+	return release, nil
+}
+
+// UpdateResult reports what an update installed besides the binary
+type UpdateResult struct {
+	// SupportFiles are installed completions and man pages
+	SupportFiles []string
+
+	// Warnings describe support files that couldn't be installed (the binary was still updated)
+	Warnings []string
 }
 
 // Update performs the self-update operation
-// What: Downloads new binary, verifies it, and atomically replaces current binary
+// What: Downloads the binary, its checksum, and support files concurrently, verifies the binary, and atomically replaces the current one
 // Why: Updates devsetup to latest version safely
 // Params: release - ReleaseInfo containing download URL
-// Returns: Error if update failed, nil on success
-// Example: err := updater.Update(release)
-func (u *Updater) Update(release *ReleaseInfo) error {
-	// Get current executable path
-	currentExe, err := os.Executable()
+// Returns: Support files installed, error if the binary wasn't updated
+// Example: res, err := updater.Update(release)
+// Edge cases: Releases without a .sha256 asset are installed unverified, as before checksums were published; Homebrew installs return ErrManagedByHomebrew; system installs are replaced through sudo
+func (u *Updater) Update(release *ReleaseInfo) (*UpdateResult, error) {
+	inst, err := DetectInstallation()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return nil, err
 	}
-
-	// Resolve symlinks
-	currentExe, err = filepath.EvalSymlinks(currentExe)
-	if err != nil {
-		return fmt.Errorf("failed to resolve symlinks: %w", err)
+	if inst.Method == MethodHomebrew {
+		return nil, fmt.Errorf("%w: run %s", ErrManagedByHomebrew, inst.UpgradeCommand())
 	}
+	currentExe := inst.Path
 
 	// Find correct asset for current platform/architecture
-	asset := findAssetForPlatform(release.Assets)
-	if asset == nil {
-		return fmt.Errorf("no binary found for %s/%s", runtime.GOOS, runtime.GOARCH)
+	binary, checksum, support := updateAssets(release)
+	if binary == nil {
+		return nil, fmt.Errorf("no binary found for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	assets := append([]Asset{*binary}, support...)
+	if checksum != nil {
+		assets = append(assets, *checksum)
 	}
 
-	// Download new binary to temp file
-	tempFile, err := os.CreateTemp("", "devsetup-update-*")
+	// Download everything into a temp dir
+	tempDir, err := os.MkdirTemp("", "devsetup-update-*")
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
 	}
-	defer func() { _ = os.Remove(tempFile.Name()) }()
-	defer func() { _ = tempFile.Close() }()
+	defer func() { _ = os.RemoveAll(tempDir) }()
 
-	if err := u.downloadFile(tempFile, asset.BrowserDownloadURL); err != nil {
-		return fmt.Errorf("failed to download update: %w", err)
+	files, err := u.downloadAssets(context.Background(), assets, tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download update: %w", err)
+	}
+	newBinary := files[binary.Name]
+
+	if checksum != nil {
+		data, err := os.ReadFile(files[checksum.Name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checksum: %w", err)
+		}
+		expected, err := parseChecksum(checksum.Name, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := VerifyChecksum(newBinary, expected); err != nil {
+			return nil, fmt.Errorf("downloaded binary rejected: %w", err)
+		}
 	}
 
 	// Make new binary executable
-	if err := os.Chmod(tempFile.Name(), 0755); err != nil {
-		return fmt.Errorf("failed to make binary executable: %w", err)
+	if err := os.Chmod(newBinary, 0755); err != nil {
+		return nil, fmt.Errorf("failed to make binary executable: %w", err)
+	}
+
+	if inst.Method == MethodSystem {
+		if err := sudoInstall(newBinary, currentExe); err != nil {
+			return nil, err
+		}
+		return u.finishUpdate(files), nil
 	}
 
 	// Backup current binary
 	backupPath := currentExe + ".backup"
 	if err := os.Rename(currentExe, backupPath); err != nil {
-		return fmt.Errorf("failed to backup current binary: %w", err)
+		return nil, fmt.Errorf("failed to backup current binary: %w", err)
 	}
 
-	// Atomic replace: close temp file, then move it
-	_ = tempFile.Close()
-	if err := os.Rename(tempFile.Name(), currentExe); err != nil {
+	// Atomic replace
+	if err := os.Rename(newBinary, currentExe); err != nil {
 		// Restore backup on failure
 		if restoreErr := os.Rename(backupPath, currentExe); restoreErr != nil {
 			// Log but don't fail - original error is more important
 			fmt.Fprintf(os.Stderr, "Warning: failed to restore backup: %v\n", restoreErr)
 		}
-		return fmt.Errorf("failed to replace binary: %w", err)
+		return nil, fmt.Errorf("failed to replace binary: %w", err)
 	}
 
 	// Remove backup on success
@@ -189,27 +224,67 @@ func (u *Updater) Update(release *ReleaseInfo) error {
 		fmt.Fprintf(os.Stderr, "Warning: failed to remove backup: %v\n", err)
 	}
 
-	return nil
+	return u.finishUpdate(files), nil
 }
 
-// downloadFile downloads a file from URL to writer
-// What: HTTP download with progress (simplified for now)
-// Why: Downloads binary from GitHub releases
-// Params: dst - destination writer, url - download URL
-// Returns: Error if download failed
-func (u *Updater) downloadFile(dst io.Writer, url string) error {
-	resp, err := u.httpClient.Get(url)
+// finishUpdate installs the downloaded support files once the binary is replaced
+// Params: files - downloadAssets result
+// Returns: Installed files and warnings for those that failed
+func (u *Updater) finishUpdate(files map[string]string) *UpdateResult {
+	result := &UpdateResult{}
+	if home, err := os.UserHomeDir(); err == nil {
+		installed, errs := installSupportFiles(files, home)
+		result.SupportFiles = installed
+		for _, err := range errs {
+			result.Warnings = append(result.Warnings, err.Error())
+		}
+	}
+	return result
+}
+
+// ReleaseChecksum returns the published SHA256 of this platform's binary for the current version
+// What: Fetches the release tagged with the current version and reads its devsetup-{os}-{arch}.sha256 asset
+// Why: devsetup self check compares the running binary against what was released
+// Returns: Lowercase hex checksum, error for dev builds, missing releases, or missing checksum assets
+// Edge cases: Build metadata after "+" (e.g. "v1.2.0+abc1234") is dropped to find the tag
+func (u *Updater) ReleaseChecksum() (string, error) {
+	tag, _, _ := strings.Cut(u.currentVersion, "+")
+	if !strings.HasPrefix(tag, "v") {
+		return "", fmt.Errorf("%s is not a release build", u.currentVersion)
+	}
+
+	release, err := u.Release(tag)
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status %d", resp.StatusCode)
+	asset := findAssetForPlatform(release.Assets)
+	if asset == nil {
+		return "", fmt.Errorf("release %s has no binary for %s/%s", tag, runtime.GOOS, runtime.GOARCH)
 	}
+	for _, a := range release.Assets {
+		if a.Name != asset.Name+".sha256" {
+			continue
+		}
+		var buf strings.Builder
+		if err := u.downloadFile(&buf, a.BrowserDownloadURL); err != nil {
+			return "", fmt.Errorf("failed to download checksum: %w", err)
+		}
+		return parseChecksum(a.Name, []byte(buf.String()))
+	}
+	return "", fmt.Errorf("release %s has no %s.sha256", tag, asset.Name)
+}
 
-	_, err = io.Copy(dst, resp.Body)
-	return err
+// downloadFile downloads a file from URL to writer
+// What: HTTP download via the shared downloader (retry, range resume, progress)
+// Why: Downloads binary from GitHub releases without restarting from zero after a network blip
+// Params: dst - destination writer, url - download URL
+// Returns: Error if download failed
+func (u *Updater) downloadFile(dst io.Writer, url string) error {
+	d := download.New(u.httpClient)
+	d.UserAgent = fmt.Sprintf("devsetup/%s", u.currentVersion)
+	d.Progress = u.progress
+	return d.Copy(context.Background(), dst, url)
 }
 
 // findAssetForPlatform finds the correct binary asset for current platform
@@ -231,6 +306,13 @@ func findAssetForPlatform(assets []Asset) *Asset {
 	return nil
 }
 
+// IsNewerVersion reports whether a release tag is newer than a version
+// Params: newVer - release tag (e.g. "v0.5.0"), currentVer - running version
+// Returns: true if newVer is newer (see isNewerVersion)
+func IsNewerVersion(newVer, currentVer string) bool {
+	return newVer != "" && isNewerVersion(newVer, currentVer)
+}
+
 // isNewerVersion compares two semantic versions
 // What: Determines if newVer is newer than currentVer
 // Why: Decides whether update is needed
@@ -252,25 +334,6 @@ func isNewerVersion(newVer, currentVer string) bool {
 	return newVer > currentVer
 }
 
-// GetReleaseNotes formats release notes for display
-// What: Extracts and formats release notes from release body
-// Why: Shows user what's new in the update
-// Params: release - ReleaseInfo containing body text
-// Returns: Formatted release notes string
-func GetReleaseNotes(release *ReleaseInfo) string {
-	if release.Body == "" {
-		return "No release notes available."
-	}
-
-	// Simple formatting - take first 500 chars
-	notes := release.Body
-	if len(notes) > 500 {
-		notes = notes[:500] + "..."
-	}
-
-	return notes
-}
-
 // VerifyChecksum verifies downloaded file against expected checksum
 // What: Calculates SHA256 checksum and compares with expected value
 // Why: Ensures downloaded binary hasn't been tampered with
@@ -9,14 +9,18 @@
 package updater
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
 )
 
 func TestNewUpdater(t *testing.T) {
@@ -302,38 +306,35 @@ func TestIsNewerVersion_GitCommitHash(t *testing.T) {
 	}
 }
 
-func TestGetReleaseNotes(t *testing.T) {
-	tests := []struct {
-		body     string
-		expected string
-	}{
-		{
-			body:     "Bug fixes and improvements",
-			expected: "Bug fixes and improvements",
-		},
-		{
-			body:     "",
-			expected: "No release notes available.",
-		},
-		{
-			body:     string(make([]byte, 600)), // Long body
-			expected: string(make([]byte, 500)) + "...",
-		},
-	}
-
-	for _, tt := range tests {
-		release := &ReleaseInfo{Body: tt.body}
-		result := GetReleaseNotes(release)
-
-		if tt.body == "" {
-			if result != tt.expected {
-				t.Errorf("Expected empty body to return '%s', got '%s'", tt.expected, result)
-			}
-		} else if len(tt.body) > 500 {
-			if len(result) != 503 { // 500 + "..."
-				t.Errorf("Expected truncated body length 503, got %d", len(result))
-			}
-		}
+func TestRenderReleaseNotes(t *testing.T) {
+	release := &ReleaseInfo{
+		HTMLURL: "https://github.com/rkinnovate/dev-setup/releases/tag/v1.4.0",
+		Body: "<!-- generated -->\r\n# What's new\r\n\r\n\r\n## Fixes\n" +
+			"- **Faster** install for `brew` tools\n" +
+			"  * see [#42](https://github.com/rkinnovate/dev-setup/pull/42)\n" +
+			"- keeps snake_case and *emphasis*\n" +
+			"```\ndevsetup changelog\n```\n",
+	}
+
+	got := RenderReleaseNotes(release, 0, false)
+	want := "What's new\n" +
+		"══════════\n" +
+		"\n" +
+		"Fixes\n" +
+		"  • Faster install for brew tools\n" +
+		"    • see #42 (https://github.com/rkinnovate/dev-setup/pull/42)\n" +
+		"  • keeps snake_case and emphasis\n" +
+		"    devsetup changelog\n"
+	if got != want {
+		t.Errorf("RenderReleaseNotes() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = RenderReleaseNotes(release, 4, false)
+	if !strings.HasSuffix(got, "Fixes\n\n… 4 more lines, see "+release.HTMLURL+"\n") {
+		t.Errorf("RenderReleaseNotes() with budget = %q", got)
+	}
+	if got := RenderReleaseNotes(&ReleaseInfo{Body: "<!-- empty -->"}, 0, false); got != "No release notes available.\n" {
+		t.Errorf("RenderReleaseNotes() empty = %q", got)
 	}
 }
 
@@ -431,3 +432,134 @@ func TestDownloadFile_ServerError(t *testing.T) {
 		t.Error("Expected error for server error response, got nil")
 	}
 }
+
+func TestDownloadAssets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := w.Write([]byte(strings.TrimPrefix(r.URL.Path, "/"))); err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	binaryName := "devsetup-" + runtime.GOOS + "-" + runtime.GOARCH
+	release := &ReleaseInfo{Assets: []Asset{
+		{Name: binaryName, BrowserDownloadURL: server.URL + "/binary", Size: 6},
+		{Name: binaryName + ".sha256", BrowserDownloadURL: server.URL + "/sum", Size: 3},
+		{Name: "_devsetup", BrowserDownloadURL: server.URL + "/zsh", Size: 3},
+		{Name: "devsetup-linux-mips", BrowserDownloadURL: server.URL + "/other"},
+	}}
+	binary, checksum, support := updateAssets(release)
+	if binary == nil || checksum == nil || len(support) != 1 {
+		t.Fatalf("updateAssets() = %v, %v, %v", binary, checksum, support)
+	}
+
+	u := NewUpdater("v0.4.0")
+	u.httpClient = server.Client()
+	var last [2]int64
+	u.SetProgress(func(done, total int64) { last = [2]int64{done, total} })
+
+	files, err := u.downloadAssets(context.Background(), append([]Asset{*binary, *checksum}, support...), t.TempDir())
+	if err != nil {
+		t.Fatalf("downloadAssets() error = %v", err)
+	}
+	if data, _ := os.ReadFile(files[binaryName]); string(data) != "binary" {
+		t.Errorf("binary content = %q", data)
+	}
+	if last != [2]int64{12, 12} {
+		t.Errorf("final progress = %v, want [12 12]", last)
+	}
+
+	home := t.TempDir()
+	installed, errs := installSupportFiles(files, home)
+	if len(errs) > 0 || len(installed) != 1 || installed[0] != filepath.Join(home, ".local/share/zsh/site-functions/_devsetup") {
+		t.Errorf("installSupportFiles() = %v, %v", installed, errs)
+	}
+}
+
+func TestDetectInstallation(t *testing.T) {
+	brew := detectInstallation("/opt/homebrew/Cellar/devsetup/1.2.0/bin/devsetup")
+	if brew.Method != MethodHomebrew || brew.UpgradeCommand() != "brew upgrade devsetup" {
+		t.Errorf("Cellar path = %+v", brew)
+	}
+
+	dir := t.TempDir()
+	if user := detectInstallation(filepath.Join(dir, "devsetup")); user.Method != MethodUser || user.UpgradeCommand() != "" {
+		t.Errorf("writable dir = %+v", user)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can write anywhere")
+	}
+	readOnly := filepath.Join(dir, "bin")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+	if system := detectInstallation(filepath.Join(readOnly, "devsetup")); system.Method != MethodSystem {
+		t.Errorf("read-only dir = %+v", system)
+	}
+}
+
+func TestProviders(t *testing.T) {
+	var gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.Header.Get("PRIVATE-TOKEN")
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/tools%2Fdev-setup/releases/permalink/latest":
+			_, _ = w.Write([]byte(`{"tag_name": "v0.9.0", "description": "## Fixes", "released_at": "2026-03-02T09:00:00Z",
+				"_links": {"self": "https://gitlab.example.com/tools/dev-setup/-/releases/v0.9.0"},
+				"assets": {"links": [{"name": "devsetup-darwin-arm64", "url": "https://x/a", "direct_asset_url": "https://x/direct"}]}}`))
+		case "/releases.json":
+			_, _ = w.Write([]byte(`[
+				{"tag_name": "v0.8.0", "created_at": "2026-01-01T00:00:00Z", "assets": [{"name": "devsetup-darwin-arm64", "browser_download_url": "files/v0.8.0/devsetup-darwin-arm64"}]},
+				{"tag_name": "v1.0.0-rc1", "prerelease": true, "created_at": "2026-04-01T00:00:00Z"},
+				{"tag_name": "v0.9.0", "created_at": "2026-03-01T00:00:00Z"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("MIRROR_TOKEN", "s3cret")
+	gitlab := &config.ReleasesConfig{Provider: config.ProviderGitLab, URL: server.URL, Repo: "tools/dev-setup", TokenEnv: "MIRROR_TOKEN"}
+	u := NewUpdater("v0.8.0")
+	u.source = gitlab
+	u.httpClient.Transport = newTokenTransport(gitlab)
+	release, err := u.CheckForUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.TagName != "v0.9.0" || release.Body != "## Fixes" || release.Assets[0].BrowserDownloadURL != "https://x/direct" || release.CreatedAt.IsZero() {
+		t.Errorf("GitLab release = %+v", release)
+	}
+	if gotToken != "s3cret" {
+		t.Errorf("PRIVATE-TOKEN = %q", gotToken)
+	}
+
+	u.source = &config.ReleasesConfig{Provider: config.ProviderIndex, URL: server.URL + "/releases.json"}
+	if release, err := u.CheckForUpdate(); err != nil || release.TagName != "v0.9.0" {
+		t.Errorf("index CheckForUpdate() = %+v, %v (prerelease must be skipped)", release, err)
+	}
+	old, err := u.Release("0.8.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := server.URL + "/files/v0.8.0/devsetup-darwin-arm64"; old.Assets[0].BrowserDownloadURL != want {
+		t.Errorf("index asset URL = %s, want %s", old.Assets[0].BrowserDownloadURL, want)
+	}
+	if releases, err := u.Releases(5); err != nil || len(releases) != 2 || releases[0].TagName != "v0.9.0" {
+		t.Errorf("index Releases() = %+v, %v", releases, err)
+	}
+
+	for _, tt := range []struct {
+		rc   *config.ReleasesConfig
+		want string
+	}{
+		{config.DefaultReleasesConfig(), "https://github.com/team/settings.git"},
+		{&config.ReleasesConfig{Provider: config.ProviderGitHub, URL: "https://ghe.example.com/api/v3", Repo: "a/b"}, "https://ghe.example.com/team/settings.git"},
+		{gitlab, server.URL + "/team/settings.git"},
+	} {
+		if got := newProvider(tt.rc, &api{}).CloneURL("team/settings"); got != tt.want {
+			t.Errorf("%s CloneURL() = %s, want %s", tt.rc.Provider, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// File: internal/verify/report.go
+// Purpose: Renders verification results as table, JSON, or JUnit XML
+// Problem: CI dashboards and scripts can't parse the colored terminal output of verify
+// Role: Output formatters for VerifyResult selected via `devsetup verify --format`
+// Usage: WriteReport(os.Stdout, verifier.Run(), FormatJSON)
+// Design choices: Pure functions over VerifyResult; stdlib encoders only; durations in ms/seconds
+// Assumptions: Caller suppresses UI output when a machine-readable format is selected
+
+package verify
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Format selects the verify report output format
+type Format string
+
+const (
+	// FormatText is the default colored UI output (rendered by VerifyAll)
+	FormatText Format = "text"
+	// FormatTable is an aligned plain-text table with durations and remediation
+	FormatTable Format = "table"
+	// FormatJSON is machine-readable JSON
+	FormatJSON Format = "json"
+	// FormatJUnit is JUnit XML for CI test dashboards
+	FormatJUnit Format = "junit"
+)
+
+// ParseFormat validates a --format flag value
+// Params: s - flag value (text, table, json, junit)
+// Returns: Format and error if unknown
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatText, FormatTable, FormatJSON, FormatJUnit:
+		return Format(s), nil
+	case "":
+		return FormatText, nil
+	}
+	return "", fmt.Errorf("unknown format %q (expected text, table, json, or junit)", s)
+}
+
+// WriteReport renders a verification result in the requested format
+// What: Dispatches to the table, JSON, or JUnit renderer
+// Why: Single entry point for the verify command's non-text output
+// Params: w - destination, result - verification result, format - output format
+// Returns: Error if format is unsupported or writing fails
+// Example: err := WriteReport(os.Stdout, result, FormatJUnit)
+func WriteReport(w io.Writer, result *VerifyResult, format Format) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, result)
+	case FormatJSON:
+		return writeJSON(w, result)
+	case FormatJUnit:
+		return writeJUnit(w, result)
+	}
+	return fmt.Errorf("format %q is not a report format", format)
+}
+
+// writeTable renders results as an aligned text table
+func writeTable(w io.Writer, result *VerifyResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STATUS\tCATEGORY\tCHECK\tDURATION\tDETAIL")
+	for _, c := range result.Checks {
+		status := "PASS"
+		detail := ""
+		if c.Excepted {
+			status = "EXCEPT"
+			detail = c.Message
+		}
+		if c.Deprecated {
+			status = "DEPRECATED"
+			detail = c.Message + " → " + c.Remediation
+		}
+		if !c.Passed {
+			status = "FAIL"
+			detail = c.Message
+			if c.Remediation != "" {
+				detail += " → " + c.Remediation
+			}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%dms\t%s\n", status, c.Category, c.Name, c.Duration.Milliseconds(), detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	passed, total := result.Passed(), len(result.Checks)
+	_, err := fmt.Fprintf(w, "\n%d/%d checks passed (%s mode)\n", passed, total, result.Mode)
+	return err
+}
+
+// jsonReport is the JSON document emitted by --format json
+type jsonReport struct {
+	Mode     Mode        `json:"mode"`
+	Passed   bool        `json:"passed"`
+	Total    int         `json:"total"`
+	Failed   int         `json:"failed"`
+	Excepted int         `json:"excepted"`
+	Checks   []jsonCheck `json:"checks"`
+}
+
+// jsonCheck is one check in the JSON report
+type jsonCheck struct {
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Passed      bool   `json:"passed"`
+	Excepted    bool   `json:"excepted,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	Message     string `json:"message,omitempty"`
+	DurationMS  int64  `json:"duration_ms"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// writeJSON renders results as indented JSON
+func writeJSON(w io.Writer, result *VerifyResult) error {
+	report := jsonReport{
+		Mode:     result.Mode,
+		Passed:   len(result.Errors) == 0,
+		Total:    len(result.Checks),
+		Failed:   len(result.Checks) - result.Passed(),
+		Excepted: result.Excepted,
+		Checks:   make([]jsonCheck, 0, len(result.Checks)),
+	}
+	for _, c := range result.Checks {
+		report.Checks = append(report.Checks, jsonCheck{
+			Name:        c.Name,
+			Category:    c.Category,
+			Passed:      c.Passed,
+			Excepted:    c.Excepted,
+			Deprecated:  c.Deprecated,
+			Message:     c.Message,
+			DurationMS:  c.Duration.Milliseconds(),
+			Remediation: c.Remediation,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// JUnit XML schema (subset understood by Jenkins, GitLab, GitHub test reporters)
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Name    string       `xml:"name,attr"`
+	Tests   int          `xml:"tests,attr"`
+	Fails   int          `xml:"failures,attr"`
+	Skips   int          `xml:"skipped,attr"`
+	Time    string       `xml:"time,attr"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name  string      `xml:"name,attr"`
+	Tests int         `xml:"tests,attr"`
+	Fails int         `xml:"failures,attr"`
+	Skips int         `xml:"skipped,attr"`
+	Time  string      `xml:"time,attr"`
+	Cases []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// junitSkipped marks checks excepted by the per-user exceptions file
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnit renders results as JUnit XML with one testsuite per category
+func writeJUnit(w io.Writer, result *VerifyResult) error {
+	root := junitSuites{Name: "devsetup verify"}
+	suiteIndex := map[string]int{}
+	var suiteSeconds []float64
+	var totalSeconds float64
+
+	for _, c := range result.Checks {
+		idx, ok := suiteIndex[c.Category]
+		if !ok {
+			idx = len(root.Suites)
+			suiteIndex[c.Category] = idx
+			root.Suites = append(root.Suites, junitSuite{Name: c.Category})
+			suiteSeconds = append(suiteSeconds, 0)
+		}
+
+		tc := junitCase{
+			Name:      c.Name,
+			ClassName: "devsetup." + c.Category,
+			Time:      fmt.Sprintf("%.3f", c.Duration.Seconds()),
+		}
+		if !c.Passed {
+			body := c.Message
+			if c.Remediation != "" {
+				body += "\nRemediation: " + c.Remediation
+			}
+			tc.Failure = &junitFailure{Message: c.Message, Body: body}
+			root.Suites[idx].Fails++
+			root.Fails++
+		}
+		if c.Excepted {
+			tc.Skipped = &junitSkipped{Message: c.Message}
+			root.Suites[idx].Skips++
+			root.Skips++
+		}
+
+		root.Suites[idx].Tests++
+		root.Suites[idx].Cases = append(root.Suites[idx].Cases, tc)
+		root.Tests++
+		suiteSeconds[idx] += c.Duration.Seconds()
+		totalSeconds += c.Duration.Seconds()
+	}
+
+	// Times are sums of check durations; checks run in parallel so wall time is lower
+	for i := range root.Suites {
+		root.Suites[i].Time = fmt.Sprintf("%.3f", suiteSeconds[i])
+	}
+	root.Time = fmt.Sprintf("%.3f", totalSeconds)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,79 @@
+package verify
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sampleResult has a passing tool, a timed-out setup task, and an excepted tool
+func sampleResult() *VerifyResult {
+	return &VerifyResult{
+		Mode:        ModeStandard,
+		ToolsOK:     2,
+		SetupFailed: 1,
+		Excepted:    1,
+		Errors:      []string{"Task not configured: shell"},
+		Checks: []CheckResult{
+			{Name: "jq", Category: CategoryTool, Passed: true, Duration: 12 * time.Millisecond},
+			{Name: "shell", Category: CategorySetup, Message: "timed out after 15s", Duration: 15 * time.Second, Remediation: "Run 'devsetup setup' to configure shell"},
+			{Name: "node", Category: CategoryTool, Passed: true, Excepted: true, Message: "excepted: pinned by team"},
+		},
+	}
+}
+
+func TestWriteReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, sampleResult(), FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if report.Passed || report.Total != 3 || report.Failed != 1 || report.Excepted != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if c := report.Checks[1]; c.Passed || c.Message != "timed out after 15s" || c.DurationMS != 15000 {
+		t.Errorf("timed-out check = %+v", c)
+	}
+}
+
+func TestWriteReportJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, sampleResult(), FormatJUnit); err != nil {
+		t.Fatal(err)
+	}
+
+	var root junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &root); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if root.Tests != 3 || root.Fails != 1 || root.Skips != 1 || len(root.Suites) != 2 {
+		t.Fatalf("testsuites = %d tests, %d failures, %d skipped, %d suites", root.Tests, root.Fails, root.Skips, len(root.Suites))
+	}
+	failure := root.Suites[1].Cases[0].Failure
+	if failure == nil || !strings.Contains(failure.Body, "Remediation: Run 'devsetup setup'") {
+		t.Errorf("setup failure = %+v", failure)
+	}
+}
+
+func TestWriteReportTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, sampleResult(), FormatTable); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"FAIL", "timed out after 15s → Run 'devsetup setup'", "EXCEPT", "2/3 checks passed (standard mode)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("ParseFormat accepted an unknown format")
+	}
+}
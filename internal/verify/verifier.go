@@ -3,20 +3,73 @@
 // Problem: Need accurate verification without false positives
 // Role: Checks actual tool existence, versions, and configuration
 // Usage: Create Verifier, call VerifyAll() to check everything
-// Design choices: Real checks via shell commands; state comparison
+// Design choices: Real checks via shell commands; state comparison; quick/standard/deep modes; parallel checks;
+//                 per-user exceptions turn sanctioned deviations into passing "excepted" checks instead of drift
 // Assumptions: Tools and config files are in expected locations
 
 package verify
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/BurntSushi/toml"
+	"github.com/rkinnovate/dev-setup/internal/aitools"
+	"github.com/rkinnovate/dev-setup/internal/browser"
+	"github.com/rkinnovate/dev-setup/internal/checkcache"
 	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/database"
+	"github.com/rkinnovate/dev-setup/internal/devtls"
+	"github.com/rkinnovate/dev-setup/internal/editor"
+	"github.com/rkinnovate/dev-setup/internal/githooks"
+	"github.com/rkinnovate/dev-setup/internal/gitid"
+	"github.com/rkinnovate/dev-setup/internal/hooks"
+	"github.com/rkinnovate/dev-setup/internal/installer"
+	"github.com/rkinnovate/dev-setup/internal/jetbrains"
+	"github.com/rkinnovate/dev-setup/internal/policy"
+	"github.com/rkinnovate/dev-setup/internal/profiles"
+	"github.com/rkinnovate/dev-setup/internal/remotecache"
+	"github.com/rkinnovate/dev-setup/internal/services"
 	"github.com/rkinnovate/dev-setup/internal/ui"
+	"github.com/rkinnovate/dev-setup/internal/version"
+)
+
+// Mode selects how thorough verification is
+// What: Verification depth (quick, standard, deep)
+// Why: Standard verify shells out per tool; quick mode must finish instantly, deep mode finds drift
+type Mode string
+
+const (
+	// ModeQuick checks state and file/binary existence only (no shell-outs)
+	ModeQuick Mode = "quick"
+	// ModeStandard runs tool check commands and setup verify checks
+	ModeStandard Mode = "standard"
+	// ModeDeep adds version, checksum, submodule commit, and config drift checks
+	ModeDeep Mode = "deep"
+)
+
+// Check categories used in CheckResult.Category
+const (
+	CategoryTool   = "tool"
+	CategorySetup  = "setup"
+	CategoryDeep   = "deep"
+	CategoryPolicy = "policy"
+)
+
+const (
+	// defaultCheckTimeout bounds each individual check so one hung command can't stall verify
+	defaultCheckTimeout = 15 * time.Second
+	// deepCheckTimeout is longer because version probes run several commands
+	deepCheckTimeout = 30 * time.Second
 )
 
 // Verifier checks tool installation and configuration status
@@ -25,90 +78,168 @@ type Verifier struct {
 	setupConfig *config.SetupConfig
 	state       *config.State
 	ui          ui.UI
+	mode        Mode
+	workers     int
+	policies    *config.PolicyConfig
+	hooks       *hooks.Dispatcher
+	exceptions  *config.Exceptions
+	checks      *checkcache.Cache
 }
 
 // VerifyResult contains verification results
 type VerifyResult struct {
-	ToolsOK     int
-	ToolsFailed int
-	SetupOK     int
-	SetupFailed int
-	Errors      []string
+	Mode         Mode
+	ToolsOK      int
+	ToolsFailed  int
+	SetupOK      int
+	SetupFailed  int
+	DeepOK       int
+	DeepFailed   int
+	PolicyOK     int
+	PolicyFailed int
+	Excepted     int
+	Checks       []CheckResult
+	Errors       []string
+}
+
+// Passed returns the number of checks that passed
+func (r *VerifyResult) Passed() int {
+	return r.ToolsOK + r.SetupOK + r.DeepOK + r.PolicyOK
+}
+
+// DeprecatedInstalled returns how many deprecated tools are still installed
+func (r *VerifyResult) DeprecatedInstalled() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Deprecated {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckResult is the outcome of a single verification check
+// What: Name, category, tool or task checked (Subject), pass/fail, detail message, duration, and fix hint of one check
+// Why: Checks run in parallel; results are collected then reported in declaration order
+// Edge cases: Excepted checks passed only because of a per-user exception (Message holds the reason); Deprecated ones found a deprecated tool still installed
+type CheckResult struct {
+	Name        string
+	Category    string
+	Subject     string
+	Passed      bool
+	Excepted    bool
+	Deprecated  bool
+	Message     string
+	Duration    time.Duration
+	Remediation string
+}
+
+// check is a unit of verification work scheduled on the worker pool
+type check struct {
+	name        string
+	category    string
+	timeout     time.Duration
+	remediation string
+	run         func(ctx context.Context) (bool, string)
+
+	// subject is the tool or task name exceptions are matched against (empty = not exceptable)
+	subject string
+
+	// excepted marks a check governed by an exception; a pass is reported as excepted
+	excepted bool
+
+	// deprecated marks a deprecated tool's check; a pass with a message means it is still installed
+	deprecated bool
 }
 
 // NewVerifier creates a new verifier
-func NewVerifier(toolsConfig *config.ToolsConfig, setupConfig *config.SetupConfig, state *config.State, ui ui.UI) *Verifier {
+// What: Constructor for Verifier with configs, state, UI, and verification mode
+// Why: Centralized creation with all dependencies
+// Params: mode - ModeQuick, ModeStandard, or ModeDeep (empty defaults to standard)
+// Returns: Configured Verifier instance
+// Example: verifier := NewVerifier(toolsCfg, setupCfg, state, ui, ModeQuick)
+func NewVerifier(toolsConfig *config.ToolsConfig, setupConfig *config.SetupConfig, state *config.State, ui ui.UI, mode Mode) *Verifier {
+	if mode == "" {
+		mode = ModeStandard
+	}
+
+	workers := runtime.NumCPU()
+	if workers < 4 {
+		workers = 4
+	}
+
 	return &Verifier{
 		toolsConfig: toolsConfig,
 		setupConfig: setupConfig,
 		state:       state,
 		ui:          ui,
+		mode:        mode,
+		workers:     workers,
 	}
 }
 
-// expandPath expands ~ and environment variables in a path
-// What: Converts ~/ to $HOME/ and expands $VAR and ${VAR} syntax
-// Why: Config files use ~ but Go doesn't expand it
-// Params: path - path that may contain ~ or env vars
-// Returns: Expanded absolute path
-func expandPath(path string) string {
-	// Expand environment variables first
-	path = os.ExpandEnv(path)
+// SetPolicies enables compliance policy checks
+// What: Adds one check per policy to subsequent runs
+// Why: verify --policy reports org baseline compliance alongside tool checks
+// Params: policies - loaded policy configuration
+func (v *Verifier) SetPolicies(policies *config.PolicyConfig) {
+	v.policies = policies
+}
 
-	// Expand tilde
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			path = filepath.Join(home, path[2:])
-		}
-	} else if path == "~" {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			path = home
-		}
-	}
+// SetExceptions honors a per-user exceptions file
+// What: skip_verify entries pass as excepted; overrides replace the recorded version in deep checks
+// Why: Sanctioned deviations should not be reported as drift
+// Params: exceptions - loaded exceptions (nil disables)
+func (v *Verifier) SetExceptions(exceptions *config.Exceptions) {
+	v.exceptions = exceptions
+}
 
-	return path
+// SetCheckCache reuses recent tool check results in quick and standard modes
+// What: Tool check commands answer from the cache when fresh; deep mode always runs them
+// Why: Standard verify on large configs spent most of its time re-running unchanged checks
+// Params: cache - check cache (nil runs every check)
+func (v *Verifier) SetCheckCache(cache *checkcache.Cache) {
+	v.checks = cache
 }
 
 // VerifyAll verifies all tools and setup tasks
+// What: Runs all checks for the current mode and prints them via UI
+// Why: Single entry point for verify command's default text output
+// Returns: VerifyResult (always non-nil) and error if any check failed
 func (v *Verifier) VerifyAll() (*VerifyResult, error) {
-	v.ui.Info("🔍 Verifying environment...")
+	v.ui.Info("🔍 Verifying environment (%s mode)...", v.mode)
 	v.ui.Info("")
 
-	result := &VerifyResult{}
+	result := v.Run()
+	results := result.Checks
+	v.FireHooks(result)
 
-	// Verify tools
-	v.ui.Info("📦 Checking installed tools...")
-	for _, tool := range v.toolsConfig.Tools {
-		if v.verifyTool(tool) {
-			result.ToolsOK++
-			v.ui.Success("  ✓ %s", tool.Name)
-		} else {
-			result.ToolsFailed++
-			result.Errors = append(result.Errors, fmt.Sprintf("Tool not installed: %s", tool.Name))
-			v.ui.Error("  ✗ %s (not installed)", tool.Name)
-		}
+	for _, e := range v.exceptions.Expired(time.Now()) {
+		v.ui.Warning("⚠️  Exception for %s expired on %s; it is verified normally again (edit %s)", e.Name, e.Expires, config.ExceptionsPath())
 	}
 
+	v.printSection("📦 Checking installed tools...", CategoryTool, results)
 	v.ui.Info("")
-	v.ui.Info("⚙️  Checking configured tasks...")
-	for _, task := range v.setupConfig.SetupTasks {
-		if v.verifySetupTask(task) {
-			result.SetupOK++
-			v.ui.Success("  ✓ %s", task.Name)
-		} else {
-			result.SetupFailed++
-			result.Errors = append(result.Errors, fmt.Sprintf("Task not configured: %s", task.Name))
-			v.ui.Error("  ✗ %s (not configured)", task.Name)
-		}
-	}
-
+	v.printSection("⚙️  Checking configured tasks...", CategorySetup, results)
 	v.ui.Info("")
+	if v.mode == ModeDeep {
+		v.printSection("🔬 Checking for drift...", CategoryDeep, results)
+		v.ui.Info("")
+	}
+	if v.policies != nil {
+		v.printSection("🛡️  Checking compliance policies...", CategoryPolicy, results)
+		v.ui.Info("")
+	}
 
 	// Summary
-	total := result.ToolsOK + result.ToolsFailed + result.SetupOK + result.SetupFailed
-	passed := result.ToolsOK + result.SetupOK
+	total := len(results)
+	passed := result.Passed()
+	if result.Excepted > 0 {
+		v.ui.Info("~ %d check(s) excepted by %s", result.Excepted, config.ExceptionsPath())
+	}
+	if n := result.DeprecatedInstalled(); n > 0 {
+		v.ui.Warning("⚠️  %d deprecated tool(s) still installed; remove them with 'devsetup uninstall --deprecated'", n)
+	}
 
 	if len(result.Errors) == 0 {
 		v.ui.Success("✅ Verification PASSED (%d/%d checks)", passed, total)
@@ -122,37 +253,423 @@ func (v *Verifier) VerifyAll() (*VerifyResult, error) {
 	return result, fmt.Errorf("verification failed with %d errors", len(result.Errors))
 }
 
+// SetHooks enables verify_failed and verify_drift event hooks
+// Params: dispatcher - hooks dispatcher (nil disables hooks)
+func (v *Verifier) SetHooks(dispatcher *hooks.Dispatcher) {
+	v.hooks = dispatcher
+}
+
+// FireHooks fires verify_failed and verify_drift for a result
+// What: verify_failed when any check failed; verify_drift with drifted check names when deep checks failed
+// Why: Called by VerifyAll, and by callers using Run directly (machine-readable formats)
+// Params: result - verification result
+func (v *Verifier) FireHooks(result *VerifyResult) {
+	if len(result.Errors) == 0 {
+		return
+	}
+
+	total := len(result.Checks)
+	v.hooks.Fire(config.EventVerifyFailed, map[string]string{
+		"mode":   string(result.Mode),
+		"failed": strconv.Itoa(total - result.Passed()),
+		"total":  strconv.Itoa(total),
+	})
+
+	if result.DeepFailed > 0 {
+		var drifted []string
+		for _, c := range result.Checks {
+			if c.Category == CategoryDeep && !c.Passed {
+				drifted = append(drifted, c.Name)
+			}
+		}
+		v.hooks.Fire(config.EventVerifyDrift, map[string]string{"drifted": strings.Join(drifted, ",")})
+	}
+}
+
+// Run executes all checks without printing anything
+// What: Builds the check list for the current mode, runs it in parallel, tallies results
+// Why: Machine-readable formats (JSON, JUnit) must not be mixed with UI output
+// Returns: VerifyResult with per-check results in declaration order
+func (v *Verifier) Run() *VerifyResult {
+	results := v.runChecks(v.buildChecks())
+	_ = v.checks.Save()
+
+	result := &VerifyResult{Mode: v.mode, Checks: results}
+	for _, r := range results {
+		if r.Excepted {
+			result.Excepted++
+		}
+		switch r.Category {
+		case CategoryTool:
+			if r.Passed {
+				result.ToolsOK++
+			} else {
+				result.ToolsFailed++
+				result.Errors = append(result.Errors, fmt.Sprintf("Tool not installed: %s", r.Name))
+			}
+		case CategorySetup:
+			if r.Passed {
+				result.SetupOK++
+			} else {
+				result.SetupFailed++
+				result.Errors = append(result.Errors, fmt.Sprintf("Task not configured: %s", r.Name))
+			}
+		case CategoryDeep:
+			if r.Passed {
+				result.DeepOK++
+			} else {
+				result.DeepFailed++
+				result.Errors = append(result.Errors, fmt.Sprintf("Drift detected: %s (%s)", r.Name, r.Message))
+			}
+		case CategoryPolicy:
+			if r.Passed {
+				result.PolicyOK++
+			} else {
+				result.PolicyFailed++
+				result.Errors = append(result.Errors, fmt.Sprintf("Policy not compliant: %s (%s)", r.Name, r.Message))
+			}
+		}
+	}
+
+	return result
+}
+
+// printSection prints results of one category under a heading
+// What: Prints ✓/✗ line per check in the given category
+// Why: Keep output grouped and ordered even though checks ran concurrently
+// Params: heading - section title, category - category to print, results - all results
+func (v *Verifier) printSection(heading, category string, results []CheckResult) {
+	v.ui.Info("%s", heading)
+	for _, r := range results {
+		if r.Category != category {
+			continue
+		}
+		switch {
+		case r.Excepted:
+			v.ui.Warning("  ~ %s (%s)", r.Name, r.Message)
+		case r.Deprecated:
+			v.ui.Warning("  ⚠ %s (%s)", r.Name, r.Message)
+		case r.Passed:
+			v.ui.Success("  ✓ %s", r.Name)
+		default:
+			v.ui.Error("  ✗ %s (%s)", r.Name, r.Message)
+		}
+	}
+}
+
+// buildChecks assembles the checks for the configured mode
+// What: Creates one check per tool and setup task, plus deep drift checks
+// Why: Separates "what to check" from "how to run it" so checks can be parallelized
+// Returns: Ordered slice of checks
+func (v *Verifier) buildChecks() []check {
+	var checks []check
+
+	for _, tool := range v.toolsConfig.Tools {
+		t := tool
+		if t.Deprecated {
+			checks = append(checks, v.deprecatedCheck(t))
+			continue
+		}
+		c := check{
+			name:        t.Name,
+			category:    CategoryTool,
+			timeout:     defaultCheckTimeout,
+			remediation: toolRemediation(t),
+			subject:     t.Name,
+		}
+		if v.mode == ModeQuick {
+			c.run = func(ctx context.Context) (bool, string) { return v.quickVerifyTool(t) }
+		} else {
+			c.run = func(ctx context.Context) (bool, string) { return v.verifyTool(ctx, t) }
+		}
+		checks = append(checks, c)
+
+		// Declared services must be running and listening (quick mode doesn't shell out)
+		if svc := t.Service; svc != nil && !svc.Manual && v.mode != ModeQuick {
+			name := svc.ServiceName(t.Name)
+			checks = append(checks, check{
+				name:        t.Name + " service",
+				category:    CategoryTool,
+				timeout:     defaultCheckTimeout,
+				remediation: fmt.Sprintf("Run 'devsetup services start %s' (logs: brew services info %s)", t.Name, name),
+				subject:     t.Name,
+				run:         func(ctx context.Context) (bool, string) { return services.Check(ctx, name, svc.Port) },
+			})
+		}
+	}
+
+	for _, task := range v.setupConfig.SetupTasks {
+		t := task
+		c := check{
+			name:        t.Name,
+			category:    CategorySetup,
+			timeout:     defaultCheckTimeout,
+			remediation: "Run 'devsetup setup' to configure " + t.Name,
+			subject:     t.Name,
+		}
+		if v.mode == ModeQuick {
+			c.run = func(ctx context.Context) (bool, string) { return v.quickVerifySetupTask(t) }
+		} else {
+			c.run = func(ctx context.Context) (bool, string) { return v.verifySetupTask(ctx, t) }
+		}
+		checks = append(checks, c)
+	}
+
+	if v.mode == ModeDeep {
+		checks = append(checks, v.buildDeepChecks()...)
+	}
+
+	if v.policies != nil {
+		checks = append(checks, v.buildPolicyChecks()...)
+	}
+
+	return v.applySkips(checks)
+}
+
+// applySkips replaces checks of skipped tools and tasks with passing excepted checks
+// What: Any check whose subject has an active skip_verify entry passes with the exception reason
+// Why: The check (and any command it would run) is skipped, but still shows up in reports
+// Params: checks - built checks
+// Returns: Same checks with skipped ones replaced
+func (v *Verifier) applySkips(checks []check) []check {
+	now := time.Now()
+	for i, c := range checks {
+		if c.subject == "" {
+			continue
+		}
+		if e, ok := v.exceptions.Skip(c.subject, now); ok {
+			msg := "excepted: " + e.Describe()
+			checks[i].run = func(ctx context.Context) (bool, string) { return true, msg }
+			checks[i].excepted = true
+		}
+	}
+	return checks
+}
+
+// buildPolicyChecks creates one check per compliance policy
+// What: Wraps policy.Evaluate; recommended policies never fail verification
+// Why: verify --policy reports compliance in every output format
+// Returns: Slice of policy checks
+func (v *Verifier) buildPolicyChecks() []check {
+	var checks []check
+	for _, p := range v.policies.Policies {
+		pol := p
+		checks = append(checks, check{
+			name:     pol.Name,
+			category: CategoryPolicy,
+			timeout:  defaultCheckTimeout,
+			run: func(ctx context.Context) (bool, string) {
+				r := policy.Evaluate(ctx, pol)
+				if !r.Compliant && !pol.IsRequired() {
+					return true, "recommended: " + r.Detail
+				}
+				return r.Compliant, r.Detail
+			},
+			remediation: "Run 'devsetup doctor --fix' for guided remediation",
+		})
+	}
+
+	if len(v.policies.Denied) > 0 {
+		checks = append(checks, check{
+			name:        "denied software",
+			category:    CategoryPolicy,
+			timeout:     defaultCheckTimeout,
+			remediation: "Run 'devsetup doctor --fix' to uninstall denied software",
+			run: func(ctx context.Context) (bool, string) {
+				matches, err := policy.FindDenied(ctx, v.policies)
+				if err != nil {
+					return false, err.Error()
+				}
+				if len(matches) == 0 {
+					return true, ""
+				}
+				names := make([]string, 0, len(matches))
+				for _, m := range matches {
+					names = append(names, fmt.Sprintf("%s %s", m.Kind, m.Name))
+				}
+				return false, "installed: " + strings.Join(names, ", ")
+			},
+		})
+	}
+	return checks
+}
+
+// runChecks executes checks on a bounded worker pool
+// What: Runs each check with its own timeout, at most v.workers at a time
+// Why: Verify used to shell out serially per tool, taking minutes on large configs
+// Params: checks - checks to run
+// Returns: Results in the same order as checks
+func (v *Verifier) runChecks(checks []check) []CheckResult {
+	results := make([]CheckResult, len(checks))
+	sem := make(chan struct{}, v.workers)
+	var wg sync.WaitGroup
+
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			passed, msg := c.run(ctx)
+			if !passed && ctx.Err() == context.DeadlineExceeded {
+				msg = fmt.Sprintf("timed out after %v", c.timeout)
+			}
+
+			result := CheckResult{
+				Name:     c.name,
+				Category: c.category,
+				Subject:  c.subject,
+				Passed:   passed,
+				Message:  msg,
+				Duration: time.Since(start),
+				Excepted: passed && c.excepted,
+			}
+			if c.deprecated && passed && msg != "" {
+				result.Deprecated = true
+				result.Remediation = c.remediation
+			}
+			if !passed {
+				result.Remediation = c.remediation
+			}
+			results[i] = result
+		}(i, c)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// toolRemediation builds the fix hint for a missing tool
+// Params: tool - Tool that failed verification
+// Returns: Human-readable remediation hint
+func toolRemediation(tool config.Tool) string {
+	if tool.Install.Command == "" {
+		return "Run 'devsetup install'"
+	}
+	return fmt.Sprintf("Run 'devsetup install' or install manually: %s", tool.Install.Command)
+}
+
+// deprecatedCheck builds the check of a deprecated tool
+// What: Passes either way; a tool still installed passes with a message and is reported as deprecated
+// Why: New machines correctly lack the tool, and machines that have it should move off it without failing verify
+// Params: tool - deprecated tool
+// Returns: Tool check with the uninstall hint as remediation
+func (v *Verifier) deprecatedCheck(tool config.Tool) check {
+	return check{
+		name:        tool.Name,
+		category:    CategoryTool,
+		timeout:     defaultCheckTimeout,
+		remediation: "Run 'devsetup uninstall --deprecated'",
+		subject:     tool.Name,
+		deprecated:  true,
+		run: func(ctx context.Context) (bool, string) {
+			var installed bool
+			if v.mode == ModeQuick || tool.Check == "" {
+				installed, _ = v.quickVerifyTool(tool)
+			} else {
+				installed, _ = v.verifyTool(ctx, tool)
+			}
+			if !installed {
+				return true, ""
+			}
+			return true, tool.DeprecationNote() + ", still installed"
+		},
+	}
+}
+
+// quickVerifyTool checks a tool using state and filesystem only
+// What: Confirms tool is recorded in state and its recorded binary still exists
+// Why: Quick mode must not shell out
+// Params: tool - Tool to check
+// Returns: pass/fail and failure message
+func (v *Verifier) quickVerifyTool(tool config.Tool) (bool, string) {
+	toolState, ok := v.state.Installed[tool.Name]
+	if !ok {
+		return false, "not recorded in state"
+	}
+
+	// Tools without a resolvable binary (e.g. homebrew) are trusted from state
+	if !filepath.IsAbs(toolState.Path) {
+		return true, ""
+	}
+
+	if _, err := os.Stat(toolState.Path); err != nil {
+		return false, fmt.Sprintf("binary missing: %s", toolState.Path)
+	}
+	return true, ""
+}
+
+// quickVerifySetupTask checks a setup task without running commands
+// What: Runs only file/env verify checks; falls back to state when none exist
+// Why: Quick mode must not shell out
+// Params: task - SetupTask to check
+// Returns: pass/fail and failure message
+func (v *Verifier) quickVerifySetupTask(task config.SetupTask) (bool, string) {
+	ran := 0
+	for _, c := range task.Verify {
+		if c.Command != "" || c.TomlValue != nil || c.Profile != "" || c.PostgresQuery != nil || c.RemoteCache != nil || c.GitHook != nil || c.AIAuth != nil || c.GitIdentity != nil {
+			continue
+		}
+		ran++
+		if !v.runVerifyCheck(context.Background(), v.setupConfig.ShellFor(task), c) {
+			return false, "not configured"
+		}
+	}
+
+	if ran == 0 && !config.IsTaskConfigured(v.state, task.Name) {
+		return false, "not recorded in state"
+	}
+	return true, ""
+}
+
 // verifyTool checks if a tool is installed
-func (v *Verifier) verifyTool(tool config.Tool) bool {
+func (v *Verifier) verifyTool(ctx context.Context, tool config.Tool) (bool, string) {
 	if tool.Check == "" {
-		return true // No check specified
+		return true, "" // No check specified
 	}
 
-	cmd := exec.Command("sh", "-c", tool.Check)
-	return cmd.Run() == nil
+	// Deep verification never trusts cached results
+	checks := v.checks
+	if v.mode == ModeDeep {
+		checks = nil
+	}
+	if !checks.Passes(ctx, v.toolsConfig.ShellFor(tool), tool.Check) {
+		return false, "not installed"
+	}
+	return true, ""
 }
 
 // verifySetupTask checks if a setup task is configured
-func (v *Verifier) verifySetupTask(task config.SetupTask) bool {
+func (v *Verifier) verifySetupTask(ctx context.Context, task config.SetupTask) (bool, string) {
 	if len(task.Verify) == 0 {
 		// No verification specified, check state
-		return config.IsTaskConfigured(v.state, task.Name)
+		if !config.IsTaskConfigured(v.state, task.Name) {
+			return false, "not configured"
+		}
+		return true, ""
 	}
 
 	// Run all verification checks
+	shell := v.setupConfig.ShellFor(task)
 	for _, check := range task.Verify {
-		if !v.runVerifyCheck(check) {
-			return false
+		if !v.runVerifyCheck(ctx, shell, check) {
+			return false, "not configured"
 		}
 	}
 
-	return true
+	return true, ""
 }
 
 // runVerifyCheck runs a single verification check
-func (v *Verifier) runVerifyCheck(check config.VerifyCheck) bool {
+// Params: ctx - bounds the check, shell - the task's shell (SetupConfig.ShellFor) for command checks, check - the check
+func (v *Verifier) runVerifyCheck(ctx context.Context, shell string, check config.VerifyCheck) bool {
 	if check.Command != "" {
-		cmd := exec.Command("sh", "-c", check.Command)
+		cmd := exec.CommandContext(ctx, shell, "-c", check.Command)
 		return cmd.Run() == nil
 	}
 
@@ -161,13 +678,13 @@ func (v *Verifier) runVerifyCheck(check config.VerifyCheck) bool {
 	}
 
 	if check.FileExists != "" {
-		path := expandPath(check.FileExists)
+		path := config.ExpandPath(check.FileExists)
 		_, err := os.Stat(path)
 		return err == nil
 	}
 
 	if check.FileContains != nil {
-		path := expandPath(check.FileContains.Path)
+		path := config.ExpandPath(check.FileContains.Path)
 		content, err := os.ReadFile(path)
 		if err != nil {
 			return false
@@ -175,7 +692,279 @@ func (v *Verifier) runVerifyCheck(check config.VerifyCheck) bool {
 		return strings.Contains(string(content), check.FileContains.Text)
 	}
 
-	// TODO: Implement TomlValue check
+	if check.Profile != "" {
+		installed, err := profiles.Installed(ctx)
+		return err == nil && installed[check.Profile]
+	}
+
+	if q := check.PostgresQuery; q != nil {
+		found, err := database.Seeded(ctx, database.Conn{}, q.Database, q.Query)
+		return err == nil && found
+	}
+
+	if j := check.JSONValue; j != nil {
+		return editor.HasValue(config.ExpandPath(j.File), j.Key, j.Equals)
+	}
+
+	if be := check.BrowserExtension; be != nil {
+		return browser.ExtensionInstalled(be.Browser, be.ID)
+	}
+
+	if jp := check.JetBrainsPlugin; jp != nil {
+		return jetbrains.HasPlugin(jp.IDE, jp.ID)
+	}
+
+	if tc := check.TLSCert; tc != nil {
+		return devtls.Check(config.ExpandPath(tc.File), tc.Domains, tc.MinValid, time.Now()) == nil
+	}
+
+	if ai := check.AIAuth; ai != nil {
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		return aitools.Check(ctx, ai) == nil
+	}
+
+	if gh := check.GitHook; gh != nil {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		hook := gh.Hook
+		if hook == "" {
+			hook = "pre-commit"
+		}
+		return githooks.Probe(ctx, config.ExpandPath(gh.Repo), hook) == nil
+	}
+
+	if id := check.GitIdentity; id != nil {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		return gitid.Check(ctx, *id) == nil
+	}
+
+	if rc := check.RemoteCache; rc != nil {
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		return remotecache.Check(ctx, rc) == nil
+	}
+
+	// TomlValue is only enforced in deep mode (see checkTomlValue)
 
 	return true
 }
+
+// buildDeepChecks creates drift checks for deep mode
+// What: Version and checksum drift per recorded tool, submodule commits, TOML values
+// Why: Standard verify only proves existence; deep verify proves nothing changed underneath
+// Returns: Slice of deep checks
+func (v *Verifier) buildDeepChecks() []check {
+	var checks []check
+
+	now := time.Now()
+	for _, tool := range v.toolsConfig.Tools {
+		toolState, ok := v.state.Installed[tool.Name]
+		if !ok {
+			continue
+		}
+		t, ts, shell := tool, toolState, v.toolsConfig.ShellFor(tool)
+		override, overridden := v.exceptions.Override(t.Name, now)
+
+		if overridden {
+			// The sanctioned version replaces the recorded one; the binary is expected to differ
+			checks = append(checks, check{
+				name:        t.Name + " version",
+				category:    CategoryDeep,
+				timeout:     deepCheckTimeout,
+				remediation: fmt.Sprintf("Install %s %s or update the override in %s", t.Name, override.Version, config.ExceptionsPath()),
+				subject:     t.Name,
+				excepted:    true,
+				run:         func(ctx context.Context) (bool, string) { return checkToolOverride(ctx, t, shell, override) },
+			})
+			continue
+		}
+
+		// Self-updating apps change version and binary on their own; only pinned tools are held to the recording
+		if t.VersionPolicy == config.VersionPolicyAny {
+			continue
+		}
+
+		if ts.Version != "" && ts.Version != "unknown" {
+			checks = append(checks, check{
+				name:        t.Name + " version",
+				category:    CategoryDeep,
+				timeout:     deepCheckTimeout,
+				remediation: "Run 'devsetup install' to re-record " + t.Name + " if the upgrade was intended",
+				subject:     t.Name,
+				run:         func(ctx context.Context) (bool, string) { return checkToolVersion(ctx, t, shell, ts) },
+			})
+		}
+
+		if ts.Checksum != "" && filepath.IsAbs(ts.Path) && t.VersionPolicy != config.VersionPolicyMinimum {
+			checks = append(checks, check{
+				name:        t.Name + " checksum",
+				category:    CategoryDeep,
+				timeout:     deepCheckTimeout,
+				remediation: "Reinstall " + t.Name + " or run 'devsetup install' to re-record it",
+				subject:     t.Name,
+				run:         func(ctx context.Context) (bool, string) { return checkToolChecksum(ts) },
+			})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckTimeout)
+	subs := listSubmodules(ctx)
+	cancel()
+	for _, sub := range subs {
+		s := sub
+		checks = append(checks, check{
+			name:        "submodule " + s.path,
+			category:    CategoryDeep,
+			timeout:     defaultCheckTimeout,
+			remediation: "Run 'git submodule update --init --recursive'",
+			run:         func(ctx context.Context) (bool, string) { return s.check() },
+		})
+	}
+
+	for _, task := range v.setupConfig.SetupTasks {
+		for _, vc := range task.Verify {
+			if vc.TomlValue == nil {
+				continue
+			}
+			tv := vc.TomlValue
+			checks = append(checks, check{
+				name:        fmt.Sprintf("%s [%s].%s", task.Name, tv.Section, tv.Key),
+				category:    CategoryDeep,
+				timeout:     defaultCheckTimeout,
+				remediation: fmt.Sprintf("Set %s = %v under [%s] in %s", tv.Key, tv.Equals, tv.Section, tv.File),
+				subject:     task.Name,
+				run:         func(ctx context.Context) (bool, string) { return checkTomlValue(tv) },
+			})
+		}
+	}
+
+	return checks
+}
+
+// checkToolVersion compares the live tool version against the recorded one
+// Params: ctx - the check's timeout, tool - Tool config, shell - shell its version command runs under, toolState - recorded state
+// Returns: pass/fail and drift message
+// Edge cases: version_policy minimum passes any version at or above the recorded one
+func checkToolVersion(ctx context.Context, tool config.Tool, shell string, toolState config.ToolState) (bool, string) {
+	current, _ := installer.GetToolInfo(ctx, tool, shell)
+	if tool.VersionPolicy == config.VersionPolicyMinimum {
+		found, recorded := version.Normalize(current, tool.VersionPattern), version.Normalize(toolState.Version, tool.VersionPattern)
+		if found == "" || version.Compare(found, recorded) < 0 {
+			return false, fmt.Sprintf("recorded minimum %q, found %q", toolState.Version, current)
+		}
+		return true, ""
+	}
+	if !version.Equal(current, toolState.Version, tool.VersionPattern) {
+		return false, fmt.Sprintf("recorded %q, found %q", toolState.Version, current)
+	}
+	return true, ""
+}
+
+// checkToolOverride compares the live tool version against a sanctioned local override
+// Params: ctx - the check's timeout, tool - Tool config, shell - shell its version command runs under, override - active override entry
+// Returns: pass/fail and message (the exception reason on pass)
+func checkToolOverride(ctx context.Context, tool config.Tool, shell string, override config.Exception) (bool, string) {
+	current, _ := installer.GetToolInfo(ctx, tool, shell)
+	if !version.Equal(current, override.Version, tool.VersionPattern) {
+		return false, fmt.Sprintf("override %q, found %q", override.Version, current)
+	}
+	return true, fmt.Sprintf("excepted: override %s (%s)", override.Version, override.Describe())
+}
+
+// checkToolChecksum compares the binary's SHA256 against the one recorded at install
+// Params: toolState - recorded state with path and checksum
+// Returns: pass/fail and drift message
+func checkToolChecksum(toolState config.ToolState) (bool, string) {
+	sum, err := config.FileChecksum(toolState.Path)
+	if err != nil {
+		return false, err.Error()
+	}
+	if sum != toolState.Checksum {
+		return false, "binary changed since install"
+	}
+	return true, ""
+}
+
+// submodule is a git submodule entry parsed from `git submodule status`
+type submodule struct {
+	path   string
+	prefix byte
+	commit string
+}
+
+// check reports whether the submodule is initialized and at the pinned commit
+func (s submodule) check() (bool, string) {
+	switch s.prefix {
+	case '-':
+		return false, "not initialized (run 'git submodule update --init')"
+	case '+':
+		return false, fmt.Sprintf("checked out %s, differs from pinned commit", s.commit)
+	case 'U':
+		return false, "merge conflicts"
+	}
+	return true, ""
+}
+
+// listSubmodules lists submodules of the repository in the working directory
+// What: Parses `git submodule status` output
+// Why: External scripts are version-locked via submodules; deep verify detects drift
+// Params: ctx - bounds the git call
+// Returns: Parsed submodules, empty when not in a checkout with .gitmodules (or git doesn't answer in time)
+func listSubmodules(ctx context.Context) []submodule {
+	if _, err := os.Stat(".gitmodules"); err != nil {
+		return nil
+	}
+
+	output, err := exec.CommandContext(ctx, "git", "submodule", "status").Output()
+	if err != nil {
+		return nil
+	}
+
+	var subs []submodule
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 2 {
+			continue
+		}
+		fields := strings.Fields(line[1:])
+		if len(fields) < 2 {
+			continue
+		}
+		subs = append(subs, submodule{path: fields[1], prefix: line[0], commit: fields[0]})
+	}
+	return subs
+}
+
+// checkTomlValue verifies a TOML key holds the expected value
+// What: Decodes TOML file and compares [section].key against expected
+// Why: Detects config drift in TOML-configured tools (e.g. starship.toml)
+// Params: tv - TOML value check
+// Returns: pass/fail and drift message
+func checkTomlValue(tv *config.TomlValueCheck) (bool, string) {
+	var doc map[string]interface{}
+	if _, err := toml.DecodeFile(config.ExpandPath(tv.File), &doc); err != nil {
+		return false, fmt.Sprintf("failed to read %s: %v", tv.File, err)
+	}
+
+	table := doc
+	if tv.Section != "" {
+		section, ok := doc[tv.Section].(map[string]interface{})
+		if !ok {
+			return false, fmt.Sprintf("section [%s] missing", tv.Section)
+		}
+		table = section
+	}
+
+	actual, ok := table[tv.Key]
+	if !ok {
+		return false, fmt.Sprintf("key %s missing", tv.Key)
+	}
+
+	if fmt.Sprint(actual) != fmt.Sprint(tv.Equals) {
+		return false, fmt.Sprintf("expected %v, found %v", tv.Equals, actual)
+	}
+	return true, ""
+}
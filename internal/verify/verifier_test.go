@@ -0,0 +1,123 @@
+package verify
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+)
+
+func TestRunChecksTimeout(t *testing.T) {
+	tool := config.Tool{Name: "stuck", Check: "sleep 30"}
+	v := NewVerifier(&config.ToolsConfig{Tools: []config.Tool{tool}}, &config.SetupConfig{}, &config.State{}, ui.NewEventUI(io.Discard, nil), ModeStandard)
+
+	start := time.Now()
+	results := v.runChecks([]check{{
+		name:     tool.Name,
+		category: CategoryTool,
+		timeout:  200 * time.Millisecond,
+		run:      func(ctx context.Context) (bool, string) { return v.verifyTool(ctx, tool) },
+	}})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("hung check took %s", elapsed)
+	}
+	if r := results[0]; r.Passed || r.Message != "timed out after 200ms" {
+		t.Errorf("result = %+v, want a timed-out failure", r)
+	}
+}
+
+func TestQuickModeRunsNoCommands(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	tool := config.Tool{Name: "jq", Check: "touch " + marker}
+	task := config.SetupTask{Name: "shell", Verify: []config.VerifyCheck{{Command: "touch " + marker}}}
+	state := &config.State{Installed: map[string]config.ToolState{"jq": {Path: "jq"}}}
+	v := NewVerifier(&config.ToolsConfig{Tools: []config.Tool{tool}}, &config.SetupConfig{SetupTasks: []config.SetupTask{task}}, state, ui.NewEventUI(io.Discard, nil), ModeQuick)
+
+	result := v.Run()
+	if result.ToolsOK != 1 {
+		t.Errorf("ToolsOK = %d, want 1 (trusted from state)", result.ToolsOK)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("quick verify ran a check command")
+	}
+}
+
+func TestSetupVerifyUsesTaskShell(t *testing.T) {
+	task := config.SetupTask{Name: "shell", Shell: config.ShellBash, Verify: []config.VerifyCheck{{Command: `[ "$0" = bash ]`}}}
+	v := NewVerifier(&config.ToolsConfig{}, &config.SetupConfig{SetupTasks: []config.SetupTask{task}}, &config.State{}, ui.NewEventUI(io.Discard, nil), ModeStandard)
+
+	if passed, msg := v.verifySetupTask(context.Background(), task); !passed {
+		t.Errorf("verifySetupTask() = %v, %q; want the bash check to pass under bash", passed, msg)
+	}
+	task.Shell = config.ShellSh
+	if passed, _ := v.verifySetupTask(context.Background(), task); passed {
+		t.Error("verifySetupTask() passed a bash-only check under sh")
+	}
+}
+
+func TestCheckToolVersionTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	tool := config.Tool{Name: "stuck", VersionCommand: "sleep 30; echo 1.0"}
+	start := time.Now()
+	passed, msg := checkToolVersion(ctx, tool, config.ShellSh, config.ToolState{Version: "1.0"})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("version probe took %s, want it killed at the deadline", elapsed)
+	}
+	if passed || !strings.Contains(msg, `found "unknown"`) {
+		t.Errorf("checkToolVersion() = %v, %q; want a drift failure", passed, msg)
+	}
+}
+
+func TestListSubmodulesTimeout(t *testing.T) {
+	// A git that never answers, in a checkout with submodules
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\nexec sleep 30\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".gitmodules", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if subs := listSubmodules(ctx); len(subs) != 0 {
+		t.Errorf("listSubmodules() = %v, want none", subs)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("git submodule status took %s, want it killed at the deadline", elapsed)
+	}
+}
+
+func TestParseSubmoduleStatus(t *testing.T) {
+	bin := t.TempDir()
+	status := "-1111111 scripts/a\n+2222222 scripts/b (heads/main)\n 3333333 scripts/c (v1.0)\n"
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\ncat <<'EOF'\n"+status+"EOF\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".gitmodules", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subs := listSubmodules(context.Background())
+	if len(subs) != 3 {
+		t.Fatalf("listSubmodules() = %v, want 3", subs)
+	}
+	for i, want := range []bool{false, false, true} {
+		if passed, msg := subs[i].check(); passed != want {
+			t.Errorf("%s: check() = %v (%s), want %v", subs[i].path, passed, msg, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// File: internal/version/version.go
+// Purpose: Dotted version string comparison and extraction from --version output
+// Problem: Lexicographic comparison gets "14.10" < "14.9" wrong, and comparing raw `--version` output mismatched
+//          on formats like "git version 2.43.0 (Apple Git-146)" versus a plain "2.43.0"
+// Role: Shared helper for OS minimum checks, recorded tool versions, and other version gates
+// Usage: if version.Compare(current, minimum) < 0 { ... }; v := version.Normalize(output, tool.VersionPattern)
+// Design choices: Numeric comparison per dot-separated component; non-numeric suffixes ignored by Compare but kept
+//                 by Normalize so a pre-release never equals its release; tools whose output defeats the default
+//                 extraction declare a regex (first capture group wins)
+// Assumptions: Versions look like "14.2.1" or "v0.5.0"; missing components count as zero
+
+package version
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// dottedPattern finds the first dotted version, with an optional pre-release suffix ("1.2.0-beta.1")
+var dottedPattern = regexp.MustCompile(`\d+(?:\.\d+)+(?:-[0-9A-Za-z]+(?:\.[0-9A-Za-z]+)*)?`)
+
+// patterns caches compiled per-tool patterns
+var patterns sync.Map
+
+// Compare compares two dotted version strings numerically
+// What: Compares component by component ("14.10" > "14.9")
+// Why: String comparison is wrong for multi-digit components
+// Params: a, b - version strings, optional "v" prefix
+// Returns: -1 if a < b, 0 if equal, 1 if a > b
+// Example: Compare("14.2.1", "14.0") == 1
+// Edge cases: "14" == "14.0"; pre-release suffixes ("1.2.0-beta") compare by numeric part only
+func Compare(a, b string) int {
+	ap := components(a)
+	bp := components(b)
+
+	for i := 0; i < len(ap) || i < len(bp); i++ {
+		var x, y int
+		if i < len(ap) {
+			x = ap[i]
+		}
+		if i < len(bp) {
+			y = bp[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Find returns the first version-looking word in s
+// What: Picks "0.150.2" out of "zed 0.150.2" or "Docker version 27.0.3, build 7d4bcd8"
+// Why: Tools print their version inside a sentence; Compare needs just the number
+// Params: s - --version output
+// Returns: First word starting with a digit (or v + digit), trailing punctuation trimmed; "" if none
+// Example: Find("git version 2.43.0 (Apple Git-146)") == "2.43.0"
+func Find(s string) string {
+	for _, word := range strings.Fields(s) {
+		w := strings.TrimPrefix(word, "v")
+		if w != "" && w[0] >= '0' && w[0] <= '9' {
+			return strings.TrimRight(w, ",;:)")
+		}
+	}
+	return ""
+}
+
+// Normalize extracts the version from a tool's --version output
+// What: Applies the tool's pattern (first capture group, else the whole match) or the default extraction: the first dotted version, else Find
+// Why: State records and comparisons use "2.43.0", not "git version 2.43.0 (Apple Git-146)"
+// Params: output - --version output (may be several lines), pattern - per-tool regex ("" for the default)
+// Returns: Version without a "v" prefix, or the trimmed first line when nothing matches
+// Example: Normalize("go version go1.22.1 darwin/arm64", "") == "1.22.1"
+// Edge cases: An invalid pattern falls back to the default extraction (patterns are validated when tools.yaml loads)
+func Normalize(output, pattern string) string {
+	output = strings.TrimSpace(output)
+	if pattern != "" {
+		if re, err := compile(pattern); err == nil {
+			if m := re.FindStringSubmatch(output); m != nil {
+				v := m[0]
+				if len(m) > 1 {
+					v = m[1]
+				}
+				return strings.TrimPrefix(strings.TrimSpace(v), "v")
+			}
+		}
+	}
+	if v := dottedPattern.FindString(output); v != "" {
+		return v
+	}
+	first, _, _ := strings.Cut(output, "\n")
+	if v := Find(first); v != "" {
+		return v
+	}
+	return strings.TrimSpace(first)
+}
+
+// Equal reports whether two recorded or probed versions are the same release
+// What: Normalizes both sides, then compares numerically with pre-release suffixes required to match
+// Why: A state recorded before normalization ("git version 2.43.0 (Apple Git-146)") still matches "2.43.0"
+// Params: a, b - versions or raw --version output, pattern - per-tool regex ("" for the default)
+// Returns: True for the same release ("2.43" equals "2.43.0"; "1.2.0-beta.1" does not equal "1.2.0")
+func Equal(a, b, pattern string) bool {
+	na, nb := Normalize(a, pattern), Normalize(b, pattern)
+	if na == nb {
+		return true
+	}
+	if na == "" || nb == "" || !isNumeric(na) || !isNumeric(nb) {
+		return false
+	}
+	return Compare(na, nb) == 0 && suffix(na) == suffix(nb)
+}
+
+// ValidatePattern checks a per-tool version pattern
+// Returns: Error if the regex doesn't compile
+func ValidatePattern(pattern string) error {
+	_, err := compile(pattern)
+	return err
+}
+
+// compile compiles a pattern once
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// suffix returns the pre-release or build suffix of a version ("-beta.1"), "" if none
+func suffix(v string) string {
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		return v[i:]
+	}
+	return ""
+}
+
+// isNumeric reports whether a version starts with a digit (so Compare is meaningful)
+func isNumeric(v string) bool {
+	return v[0] >= '0' && v[0] <= '9'
+}
+
+// components splits a version into numeric components
+// Params: v - version string
+// Returns: Numeric value of each dot-separated component (leading digits only)
+func components(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+ "); i >= 0 {
+		v = v[:i]
+	}
+
+	var parts []int
+	for _, field := range strings.Split(v, ".") {
+		end := 0
+		for end < len(field) && field[end] >= '0' && field[end] <= '9' {
+			end++
+		}
+		n, _ := strconv.Atoi(field[:end])
+		parts = append(parts, n)
+	}
+	return parts
+}
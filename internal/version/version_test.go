@@ -0,0 +1,93 @@
+// File: internal/version/version_test.go
+// Purpose: Unit tests for dotted version comparison
+// Problem: Version gates (OS minimum, tool pins) must compare numerically
+// Role: Test suite for Compare, Find, Normalize, and Equal
+// Usage: Run with `go test ./internal/version`
+// Design choices: Table-driven tests covering multi-digit, prefix, and suffix cases
+// Assumptions: None
+
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"14.2.1", "14.0", 1},
+		{"14.10", "14.9", 1},
+		{"14", "14.0", 0},
+		{"13.6.7", "14.0", -1},
+		{"v0.5.0", "0.5.0", 0},
+		{"1.2.0-beta.1", "1.2.0", 0},
+		{"2.43.0", "2.43.1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{"zed 0.150.2", "0.150.2"},
+		{"git version 2.43.0 (Apple Git-146)", "2.43.0"},
+		{"Docker version 27.0.3, build 7d4bcd8", "27.0.3"},
+		{"v20.11.1", "20.11.1"},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Find(tt.in); got != tt.expected {
+			t.Errorf("Find(%q) = %q, want %q", tt.in, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, pattern, expected string
+	}{
+		{"git version 2.43.0 (Apple Git-146)", "", "2.43.0"},
+		{"go version go1.22.1 darwin/arm64", "", "1.22.1"},
+		{"jq-1.7.1", "", "1.7.1"},
+		{"v20.11.1\n", "", "20.11.1"},
+		{"1.2.0-beta.1", "", "1.2.0-beta.1"},
+		{"18", "", "18"},
+		{"Python 3.12.2\nbuilt with clang 15.0.0", "", "3.12.2"},
+		{"psql (PostgreSQL) 16.2 (Homebrew)", `PostgreSQL\) (\S+)`, "16.2"},
+		{"rustc 1.77.0 (aedd173a2 2024-03-17)", `rustc v?(\S+)`, "1.77.0"},
+		{"no version here", "", "no version here"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.in, tt.pattern); got != tt.expected {
+			t.Errorf("Normalize(%q, %q) = %q, want %q", tt.in, tt.pattern, got, tt.expected)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"git version 2.43.0 (Apple Git-146)", "2.43.0", true},
+		{"2.43", "2.43.0", true},
+		{"v20.11.1", "20.11.1", true},
+		{"1.2.0-beta.1", "1.2.0", false},
+		{"2.43.0", "2.44.0", false},
+		{"unknown", "2.43.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b, ""); got != tt.expected {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
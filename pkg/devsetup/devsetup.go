@@ -0,0 +1,244 @@
+// File: pkg/devsetup/devsetup.go
+// Purpose: Public Go API for embedding the devsetup engine
+// Problem: Everything lived under internal/, so other Go tools had to shell out to the CLI and scrape output
+// Role: Re-exports config loaders, state, installer, setup executor, verifier, doctor, and UI types
+// Usage: cfg, _ := devsetup.LoadToolsConfig("configs/tools.yaml");
+//        tasks, err := devsetup.NewInstaller(cfg, state, devsetup.NewEventUI(w, nil), devsetup.InstallOptions{}).InstallAll()
+// Design choices: Type aliases keep one implementation (no wrapper drift); constructors take option structs
+//                 so new settings don't break callers; embedded default configs registered on import
+// Assumptions: No compatibility guarantee - the aliased types are the engine's own, so their fields and methods
+//              change with it (InstallAll gained []TaskResult); callers pin a devsetup version
+
+// Package devsetup is the public API of the devsetup engine.
+//
+// It exposes the same building blocks the devsetup CLI uses: configuration
+// loaders, persistent state, the tool installer, the setup executor, the
+// verifier, and doctor diagnostics. Progress is reported through the UI
+// interface; use NewEventUI for machine-readable NDJSON events or
+// NewProgressUI for the CLI's terminal output.
+//
+// The API is not yet stable: most types are aliases of the engine's internal
+// types and change with them between releases, so pin the module version.
+package devsetup
+
+import (
+	"io"
+
+	"github.com/rkinnovate/dev-setup/configs"
+	"github.com/rkinnovate/dev-setup/internal/config"
+	"github.com/rkinnovate/dev-setup/internal/doctor"
+	"github.com/rkinnovate/dev-setup/internal/hooks"
+	"github.com/rkinnovate/dev-setup/internal/installer"
+	"github.com/rkinnovate/dev-setup/internal/setup"
+	"github.com/rkinnovate/dev-setup/internal/ui"
+	"github.com/rkinnovate/dev-setup/internal/verify"
+)
+
+func init() {
+	// Same fallback as the CLI: configs missing on disk come from the embedded defaults
+	config.SetEmbeddedFS(configs.ConfigFS)
+}
+
+// Configuration and state types
+type (
+	// ToolsConfig is the parsed tools.yaml
+	ToolsConfig = config.ToolsConfig
+	// Tool is one tool declaration
+	Tool = config.Tool
+	// ToolInstall is how a tool is installed (Tool.Install)
+	ToolInstall = config.ToolInstall
+	// SetupConfig is the parsed setup.yaml
+	SetupConfig = config.SetupConfig
+	// SetupTask is one setup task declaration
+	SetupTask = config.SetupTask
+	// PolicyConfig is the parsed policies.yaml
+	PolicyConfig = config.PolicyConfig
+	// HooksConfig is the parsed hooks.yaml
+	HooksConfig = config.HooksConfig
+	// Exceptions is the per-user verify exceptions file (~/.config/devsetup/exceptions.yaml)
+	Exceptions = config.Exceptions
+	// State is the persisted install/setup state (state.json)
+	State = config.State
+	// ToolState is the recorded state of one installed tool
+	ToolState = config.ToolState
+)
+
+// Engine types
+type (
+	// Installer installs tools from a ToolsConfig
+	Installer = installer.ToolInstaller
+	// TaskResult is the outcome of one tool in an install run (returned by Installer.InstallAll)
+	TaskResult = installer.TaskResult
+	// Executor runs setup tasks from a SetupConfig
+	Executor = setup.SetupExecutor
+	// Verifier checks tools, setup tasks, drift, and policies
+	Verifier = verify.Verifier
+	// VerifyResult is the outcome of a verification run
+	VerifyResult = verify.VerifyResult
+	// CheckResult is the outcome of one verification check
+	CheckResult = verify.CheckResult
+	// VerifyMode selects verification depth
+	VerifyMode = verify.Mode
+	// Doctor runs environment diagnostics
+	Doctor = doctor.Doctor
+	// Finding is one doctor diagnostic result
+	Finding = doctor.Finding
+	// Hooks dispatches lifecycle events (pass to Installer/Executor/Verifier SetHooks)
+	Hooks = hooks.Dispatcher
+)
+
+// UI types
+type (
+	// UI receives progress and messages from the engine
+	UI = ui.UI
+	// Event is one progress event emitted by EventUI
+	Event = ui.Event
+)
+
+// Verification modes
+const (
+	VerifyQuick    = verify.ModeQuick
+	VerifyStandard = verify.ModeStandard
+	VerifyDeep     = verify.ModeDeep
+)
+
+// InstallOptions configures NewInstaller
+type InstallOptions struct {
+	// DryRun reports what would be installed without installing
+	DryRun bool
+
+	// Version is recorded in state.json as the installing version
+	Version string
+
+	// Policies enforces the software denylist (optional)
+	Policies *PolicyConfig
+
+	// AssumeYes installs Rosetta 2 without asking when Intel-only tools need it (otherwise asked on stdin)
+	AssumeYes bool
+}
+
+// SetupOptions configures NewExecutor
+type SetupOptions struct {
+	// DryRun reports what would be configured without configuring
+	DryRun bool
+
+	// ToolsConfig lets setup tasks depend on tools ("tool:<name>"); optional
+	ToolsConfig *ToolsConfig
+
+	// AssumeYes applies shell dotfile changes without asking (otherwise each change is confirmed on stdin)
+	AssumeYes bool
+}
+
+// VerifyOptions configures NewVerifier
+type VerifyOptions struct {
+	// Mode is VerifyQuick, VerifyStandard (default), or VerifyDeep
+	Mode VerifyMode
+
+	// Policies adds compliance policy checks (optional)
+	Policies *PolicyConfig
+
+	// Exceptions are sanctioned per-user deviations reported as excepted, not failed (optional)
+	Exceptions *Exceptions
+}
+
+// LoadToolsConfig loads tools.yaml (disk first, embedded default as fallback)
+// Params: path - path to tools.yaml
+// Returns: Parsed and validated config
+func LoadToolsConfig(path string) (*ToolsConfig, error) {
+	return config.LoadToolsConfig(path)
+}
+
+// LoadSetupConfig loads setup.yaml (disk first, embedded default as fallback)
+// Params: path - path to setup.yaml
+// Returns: Parsed and validated config
+func LoadSetupConfig(path string) (*SetupConfig, error) {
+	return config.LoadSetupConfig(path)
+}
+
+// LoadPolicyConfig loads policies.yaml (disk first, embedded default as fallback)
+// Params: path - path to policies.yaml
+// Returns: Parsed and validated config
+func LoadPolicyConfig(path string) (*PolicyConfig, error) {
+	return config.LoadPolicyConfig(path)
+}
+
+// LoadHooksConfig loads hooks.yaml (disk first, embedded default as fallback)
+// Params: path - path to hooks.yaml
+// Returns: Parsed and validated config
+func LoadHooksConfig(path string) (*HooksConfig, error) {
+	return config.LoadHooksConfig(path)
+}
+
+// LoadExceptions loads the per-user verify exceptions file
+// Returns: Parsed and validated exceptions (empty when the file doesn't exist)
+func LoadExceptions() (*Exceptions, error) {
+	return config.LoadExceptions()
+}
+
+// NewHooks creates a lifecycle event dispatcher
+// Params: cfg - hooks config, u - receives hook failure warnings
+func NewHooks(cfg *HooksConfig, u UI) *Hooks {
+	return hooks.NewDispatcher(cfg, u)
+}
+
+// LoadState reads state.json (empty state if it doesn't exist yet)
+func LoadState() (*State, error) {
+	return config.LoadState()
+}
+
+// SaveState writes state.json
+func SaveState(state *State) error {
+	return config.SaveState(state)
+}
+
+// NewInstaller creates a tool installer
+// Params: tools - tools config, state - state to update, u - progress receiver, opts - options
+// Returns: Installer; call InstallAll to run
+// Example: tasks, err := devsetup.NewInstaller(tools, state, u, devsetup.InstallOptions{DryRun: true}).InstallAll()
+func NewInstaller(tools *ToolsConfig, state *State, u UI, opts InstallOptions) *Installer {
+	ti := installer.NewToolInstaller(tools, state, u, opts.DryRun, opts.Version)
+	if opts.Policies != nil {
+		ti.SetPolicies(opts.Policies)
+	}
+	ti.SetAssumeYes(opts.AssumeYes)
+	return ti
+}
+
+// NewExecutor creates a setup task executor
+// Params: setupCfg - setup config, state - state to update, u - progress receiver, opts - options
+// Returns: Executor; call SetupAll to run (prompt tasks read from stdin)
+func NewExecutor(setupCfg *SetupConfig, state *State, u UI, opts SetupOptions) *Executor {
+	se := setup.NewSetupExecutor(setupCfg, opts.ToolsConfig, state, u, opts.DryRun)
+	se.SetAssumeYes(opts.AssumeYes)
+	return se
+}
+
+// NewVerifier creates a verifier
+// Params: tools, setupCfg - configs, state - recorded state, u - progress receiver (VerifyAll only), opts - options
+// Returns: Verifier; Run returns results without output, VerifyAll also reports through u
+func NewVerifier(tools *ToolsConfig, setupCfg *SetupConfig, state *State, u UI, opts VerifyOptions) *Verifier {
+	v := verify.NewVerifier(tools, setupCfg, state, u, opts.Mode)
+	if opts.Policies != nil {
+		v.SetPolicies(opts.Policies)
+	}
+	v.SetExceptions(opts.Exceptions)
+	return v
+}
+
+// NewDoctor creates a diagnostics runner (fixes are never applied through the API)
+// Params: policies - policy config (optional), u - progress receiver
+// Returns: Doctor; call Run for findings
+func NewDoctor(policies *PolicyConfig, u UI) *Doctor {
+	return doctor.NewDoctor(policies, u, false)
+}
+
+// NewEventUI creates a UI that writes progress as NDJSON events
+// Params: w - destination, flush - called after each event (optional)
+func NewEventUI(w io.Writer, flush func()) UI {
+	return ui.NewEventUI(w, flush)
+}
+
+// NewProgressUI creates the CLI's colored terminal UI
+func NewProgressUI() UI {
+	return ui.NewProgressUI()
+}
@@ -0,0 +1,27 @@
+package devsetup_test
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rkinnovate/dev-setup/pkg/devsetup"
+)
+
+func Example() {
+	// A dry run reports what it would install without changing anything
+	tools := &devsetup.ToolsConfig{Tools: []devsetup.Tool{
+		{Name: "jq", Check: "false", Install: devsetup.ToolInstall{Command: "brew install jq"}},
+	}}
+	installer := devsetup.NewInstaller(tools, &devsetup.State{}, devsetup.NewEventUI(io.Discard, nil), devsetup.InstallOptions{DryRun: true})
+
+	tasks, err := installer.InstallAll()
+	if err != nil {
+		fmt.Println("install failed:", err)
+		return
+	}
+	for _, task := range tasks {
+		fmt.Println(task.Name, task.Outcome)
+	}
+	// Output:
+	// jq ok
+}